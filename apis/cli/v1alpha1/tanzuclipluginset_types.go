@@ -0,0 +1,50 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+)
+
+// PluginSetEntry is a plugin which must be installed.
+type PluginSetEntry struct {
+	// Name is the name of the plugin.
+	Name string `json:"name"`
+	// Target specifies the target of the plugin.
+	Target configtypes.Target `json:"target,omitempty"`
+	// Version is the version of the plugin which must be installed.
+	// The latest version of the plugin is installed if not specified.
+	Version string `json:"version,omitempty"`
+}
+
+// TanzuCLIPluginSetSpec defines the desired state of TanzuCLIPluginSet.
+type TanzuCLIPluginSetSpec struct {
+	// Plugins is the list of standalone plugins which must be installed by
+	// the CLI syncing its plugins from the cluster.
+	Plugins []PluginSetEntry `json:"plugins"`
+}
+
+//+kubebuilder:object:root=true
+
+// TanzuCLIPluginSet denotes a set of Tanzu cli plugins which must be installed.
+type TanzuCLIPluginSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              TanzuCLIPluginSetSpec `json:"spec"`
+}
+
+//+kubebuilder:object:root=true
+
+// TanzuCLIPluginSetList contains a list of TanzuCLIPluginSet
+type TanzuCLIPluginSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TanzuCLIPluginSet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TanzuCLIPluginSet{}, &TanzuCLIPluginSetList{})
+}
@@ -133,3 +133,96 @@ func (in *CLIPluginSpec) DeepCopy() *CLIPluginSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginSetEntry) DeepCopyInto(out *PluginSetEntry) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginSetEntry.
+func (in *PluginSetEntry) DeepCopy() *PluginSetEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginSetEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TanzuCLIPluginSet) DeepCopyInto(out *TanzuCLIPluginSet) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TanzuCLIPluginSet.
+func (in *TanzuCLIPluginSet) DeepCopy() *TanzuCLIPluginSet {
+	if in == nil {
+		return nil
+	}
+	out := new(TanzuCLIPluginSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TanzuCLIPluginSet) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TanzuCLIPluginSetList) DeepCopyInto(out *TanzuCLIPluginSetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TanzuCLIPluginSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TanzuCLIPluginSetList.
+func (in *TanzuCLIPluginSetList) DeepCopy() *TanzuCLIPluginSetList {
+	if in == nil {
+		return nil
+	}
+	out := new(TanzuCLIPluginSetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TanzuCLIPluginSetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TanzuCLIPluginSetSpec) DeepCopyInto(out *TanzuCLIPluginSetSpec) {
+	*out = *in
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make([]PluginSetEntry, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TanzuCLIPluginSetSpec.
+func (in *TanzuCLIPluginSetSpec) DeepCopy() *TanzuCLIPluginSetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TanzuCLIPluginSetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -38,10 +38,7 @@ func AddPlugin(name, description string, dryRun bool) error {
 		return errors.New("plugin description is required")
 	}
 
-	data := struct {
-		PluginName  string
-		Description string
-	}{
+	data := &pluginData{
 		PluginName:  name,
 		Description: description,
 	}
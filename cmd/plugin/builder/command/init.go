@@ -8,15 +8,56 @@ import (
 	"os/exec"
 	"strings"
 
-	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/template"
+	"github.com/pkg/errors"
+
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/template"
 )
 
 const (
 	github = "github"
 )
 
-func Initialize(name, repoType string, dryRun bool) error {
+// targetConstants are the names of the target constants of the plugin runtime
+var targetConstants = map[configtypes.Target]string{
+	configtypes.TargetGlobal:     "TargetGlobal",
+	configtypes.TargetK8s:        "TargetK8s",
+	configtypes.TargetTMC:        "TargetTMC",
+	configtypes.TargetOperations: "TargetOperations",
+}
+
+// pluginData is the data of the plugin templates
+type pluginData struct {
+	PluginName  string
+	Description string
+	// Target and TargetConstant are empty if the target of the plugin is not known
+	Target         string
+	TargetConstant string
+}
+
+// Initialize generates the scaffolding of a new plugin repository. If the target is
+// specified, the repository is also initialized with a plugin of the same name for
+// this target, ready to be built, tested and published.
+func Initialize(name, repoType, pluginTarget, description string, dryRun bool) error {
+	var plugin *pluginData
+	if pluginTarget != "" {
+		if !configtypes.IsValidTarget(pluginTarget, true, false) {
+			return errors.Errorf("invalid target %q for the plugin, the supported targets are: global, kubernetes, mission-control, operations", pluginTarget)
+		}
+		if description == "" {
+			return errors.New("plugin description is required")
+		}
+		t := configtypes.StringToTarget(pluginTarget)
+		plugin = &pluginData{
+			PluginName:     name,
+			Description:    description,
+			Target:         string(t),
+			TargetConstant: targetConstants[t],
+		}
+	}
+
 	data := struct {
 		RepositoryName string
 	}{
@@ -34,6 +75,13 @@ func Initialize(name, repoType string, dryRun bool) error {
 			return err
 		}
 	}
+	if plugin != nil {
+		for _, target := range template.InitPluginTargets {
+			if err := target.Run(name, plugin, dryRun); err != nil {
+				return err
+			}
+		}
+	}
 
 	if dryRun {
 		return nil
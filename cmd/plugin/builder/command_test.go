@@ -57,3 +57,51 @@ func Test_BuilderInitAndAddPlugin(t *testing.T) {
 	err = cmd.Execute()
 	assert.Nil(err)
 }
+
+func Test_BuilderInitWithPlugin(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := os.MkdirTemp("", "core")
+	if err != nil {
+		t.Error(err)
+	}
+	defer os.RemoveAll(dir)
+	err = os.Chdir(dir)
+	assert.Nil(err)
+
+	var stdout, stderr bytes.Buffer
+
+	// Assert an invalid target is rejected
+	args := []string{"testplugin", "--repo-type", "github", "--target", "invalid", "--description", "something"}
+	cmd := NewInitCmd()
+	cmd.SetArgs(args)
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	err = cmd.Execute()
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "invalid target")
+
+	// Assert repo creation with the plugin
+	args = []string{"testplugin", "--repo-type", "github", "--target", "k8s", "--description", "something"}
+	cmd = NewInitCmd()
+	cmd.SetArgs(args)
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	err = cmd.Execute()
+	assert.Nil(err)
+
+	pluginDir := filepath.Join(dir, "testplugin", "cmd", "plugin", "testplugin")
+	b, err := os.ReadFile(filepath.Join(pluginDir, "main.go"))
+	assert.Nil(err)
+	assert.Contains(string(b), "Target:      types.TargetK8s,")
+	assert.NotContains(string(b), "FIXME")
+
+	b, err = os.ReadFile(filepath.Join(pluginDir, "metadata.yaml"))
+	assert.Nil(err)
+	assert.Equal("name: testplugin\ntarget: kubernetes\n", string(b))
+
+	_, err = os.Stat(filepath.Join(pluginDir, "test", "main.go"))
+	assert.Nil(err)
+	_, err = os.Stat(filepath.Join(dir, "testplugin", "Makefile"))
+	assert.Nil(err)
+}
@@ -9,6 +9,9 @@ import (
 
 	"github.com/google/go-containerregistry/cmd/crane/cmd"
 	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
 )
 
 // CraneOptions implements the CraneWrapper interface by using `crane` library
@@ -30,3 +33,31 @@ func (co *CraneOptions) PushImage(pluginTarFilePath, image string) error {
 	cranePushCmd := cmd.NewCmdPush(&[]crane.Option{})
 	return cranePushCmd.RunE(cranePushCmd, []string{pluginTarFilePath, image})
 }
+
+// PushImageIndex publish a multi-arch image index referencing the platform images
+func (co *CraneOptions) PushImageIndex(indexImage string, platformImages []carvelhelpers.PlatformImage) error {
+	return carvelhelpers.PushImageIndex(indexImage, platformImages)
+}
+
+// GetImageDigest returns the digest of the image published in the remote container registry
+func (co *CraneOptions) GetImageDigest(image string) (string, error) {
+	return crane.Digest(image)
+}
+
+// GetTarImageDigest returns the digest the image of the tar file has once published
+func (co *CraneOptions) GetTarImageDigest(pluginTarFilePath string) (string, error) {
+	img, err := tarball.ImageFromPath(pluginTarFilePath, nil)
+	if err != nil {
+		return "", err
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		return "", err
+	}
+	return digest.String(), nil
+}
+
+// CopyImage copies the image, or image index, from one remote container registry to another
+func (co *CraneOptions) CopyImage(sourceImage, destImage string) error {
+	return crane.Copy(sourceImage, destImage)
+}
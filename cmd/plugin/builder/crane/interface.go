@@ -4,12 +4,22 @@
 // Package crane implements helper function for crane library
 package crane
 
+import "github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+
 // CraneWrapper defines the crane command wrapper functions
 type CraneWrapper interface {
 	// SaveImage image as an tar file
 	SaveImage(image, pluginTarFilePath string) error
 	// PushImage publish the tar file to remote container registry
 	PushImage(pluginTarFilePath, image string) error
+	// PushImageIndex publish a multi-arch image index referencing the platform images
+	PushImageIndex(indexImage string, platformImages []carvelhelpers.PlatformImage) error
+	// GetImageDigest returns the digest of the image published in the remote container registry
+	GetImageDigest(image string) (string, error)
+	// GetTarImageDigest returns the digest the image of the tar file has once published
+	GetTarImageDigest(pluginTarFilePath string) (string, error)
+	// CopyImage copies the image, or image index, from one remote container registry to another
+	CopyImage(sourceImage, destImage string) error
 }
 
 // NewCraneWrapper creates new CraneWrapper instance
@@ -5,11 +5,14 @@
 package helpers
 
 import (
+	"context"
 	"crypto/sha256"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -86,6 +89,39 @@ func ValidatePluginBinary(pluginBinaryFilePath, threadID string) (bool, error) {
 	return !fileEmpty, nil
 }
 
+// ValidatePluginBinarySize returns an error if the plugin binary file is larger than maxSize bytes
+func ValidatePluginBinarySize(pluginBinaryFilePath string, maxSize int64) error {
+	info, err := os.Stat(pluginBinaryFilePath)
+	if err != nil {
+		return err
+	}
+	if info.Size() > maxSize {
+		return errors.Errorf("plugin binary %q is %d bytes which exceeds the maximum size of %d bytes", pluginBinaryFilePath, info.Size(), maxSize)
+	}
+	return nil
+}
+
+// ValidatePluginStartupTime runs the `info` command of the plugin binary and returns an error
+// if the command fails or does not complete within maxStartupTime
+func ValidatePluginStartupTime(pluginBinaryFilePath string, maxStartupTime time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), maxStartupTime)
+	defer cancel()
+
+	start := time.Now()
+	cmd := exec.CommandContext(ctx, pluginBinaryFilePath, "info")
+	// Do not wait for the output of the processes started by the plugin once it is killed
+	cmd.WaitDelay = time.Second
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return errors.Errorf("'%s info' did not complete within the startup time budget of %v", pluginBinaryFilePath, maxStartupTime)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "'%s info' failed: %s", pluginBinaryFilePath, string(output))
+	}
+	log.V(4).Infof("'%s info' completed in %v", pluginBinaryFilePath, time.Since(start))
+	return nil
+}
+
 // GetNumberOfIndividualPluginBinariesFromManifest returns the number of plugin binaries
 // into consideration based on the plugin manifest file
 // This includes plugin binaries for all supported os architectures as well as
@@ -5,7 +5,10 @@ package helpers
 
 import (
 	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -59,3 +62,41 @@ func TestEmptyValidatePluginBinary(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, false, valid)
 }
+
+func TestValidatePluginBinarySize(t *testing.T) {
+	pluginBinary := filepath.Join(t.TempDir(), "plugin")
+	err := os.WriteFile(pluginBinary, []byte("Some data"), 0755)
+	assert.Nil(t, err)
+
+	assert.Nil(t, ValidatePluginBinarySize(pluginBinary, 9))
+
+	err = ValidatePluginBinarySize(pluginBinary, 8)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "is 9 bytes which exceeds the maximum size of 8 bytes")
+}
+
+func TestValidatePluginStartupTime(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test plugin binaries are shell scripts")
+	}
+	dir := t.TempDir()
+
+	fastPlugin := filepath.Join(dir, "fast")
+	err := os.WriteFile(fastPlugin, []byte("#!/bin/sh\necho '{\"name\": \"fast\"}'\n"), 0755)
+	assert.Nil(t, err)
+	assert.Nil(t, ValidatePluginStartupTime(fastPlugin, 10*time.Second))
+
+	slowPlugin := filepath.Join(dir, "slow")
+	err = os.WriteFile(slowPlugin, []byte("#!/bin/sh\nexec sleep 5\n"), 0755)
+	assert.Nil(t, err)
+	err = ValidatePluginStartupTime(slowPlugin, 100*time.Millisecond)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "did not complete within the startup time budget of 100ms")
+
+	failingPlugin := filepath.Join(dir, "failing")
+	err = os.WriteFile(failingPlugin, []byte("#!/bin/sh\necho 'unknown command'\nexit 1\n"), 0755)
+	assert.Nil(t, err)
+	err = ValidatePluginStartupTime(failingPlugin, 10*time.Second)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unknown command")
+}
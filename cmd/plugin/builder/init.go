@@ -16,10 +16,16 @@ const desc = `Initialize a new plugin repository with scaffolding for:
 * Tanzu Framework CLI integration
 * GolangCI linting config
 * GitHub or GitLab CI config
-* A Makefile`
+* A Makefile
+
+When a target is specified, the repository is also initialized with a plugin of the
+same name for this target, with a main.go wired to the plugin runtime, a test plugin
+and the plugin metadata, ready to be built for all the supported os-arch and published
+with the Makefile.`
 
 var (
 	repoType string
+	target   string
 )
 
 // NewInitCmd initializes a repository.
@@ -28,6 +34,12 @@ func NewInitCmd() *cobra.Command {
 		Use:   "init REPO_NAME",
 		Short: "Initialize a new plugin repository",
 		Long:  desc,
+		Example: `
+    # Initialize a new plugin repository
+    tanzu builder init my-plugins --repo-type github
+
+    # Initialize a new plugin repository with a 'foo' plugin for the kubernetes target
+    tanzu builder init foo --target k8s --description "foo plugin" --repo-type github`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var err error
 			if repoType == "" {
@@ -36,13 +48,21 @@ func NewInitCmd() *cobra.Command {
 					return err
 				}
 			}
-			return command.Initialize(args[0], repoType, dryRun)
+			if target != "" && description == "" {
+				description, err = askDescription()
+				if err != nil {
+					return err
+				}
+			}
+			return command.Initialize(args[0], repoType, target, description, dryRun)
 		},
 		Args: cobra.ExactArgs(1),
 	}
 
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print generated files to stdout")
 	cmd.Flags().StringVar(&repoType, "repo-type", "", "Type of repository: github or gitlab")
+	cmd.Flags().StringVar(&target, "target", "", "Target of the plugin to initialize the repository with: global, kubernetes|k8s, mission-control|tmc or operations")
+	cmd.Flags().StringVar(&description, "description", "", "Description of the plugin, required when the target is specified")
 
 	return cmd
 }
@@ -8,6 +8,8 @@ import (
 
 	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/inventory"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cosignhelper"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/registry"
 )
 
 // newInventoryCmd creates a new command for inventory operations.
@@ -19,6 +21,10 @@ func newInventoryCmd() *cobra.Command {
 
 	inventoryCmd.AddCommand(
 		newInventoryInitCmd(),
+		newInventoryValidateCmd(),
+		newInventoryLintCmd(),
+		newInventoryVerifyCmd(),
+		newInventorySignCmd(),
 		newInventoryPluginCmd(),
 		newInventoryPluginGroupCmd(),
 	)
@@ -29,6 +35,7 @@ func newInventoryCmd() *cobra.Command {
 type inventoryInitFlags struct {
 	Repository        string
 	InventoryImageTag string
+	InventoryDBFile   string
 	Override          bool
 }
 
@@ -37,12 +44,13 @@ func newInventoryInitCmd() *cobra.Command {
 
 	var pluginInventoryInitCmd = &cobra.Command{
 		Use:     "init",
-		Short:   "Initialize empty plugin inventory database and publish it to the remote repository",
+		Short:   "Initialize empty plugin inventory database and publish it to the remote repository or create it locally",
 		Example: ``,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			iiOptions := inventory.InventoryInitOptions{
 				Repository:          piiFlags.Repository,
 				InventoryImageTag:   piiFlags.InventoryImageTag,
+				InventoryDBFile:     piiFlags.InventoryDBFile,
 				Override:            piiFlags.Override,
 				ImageOperationsImpl: carvelhelpers.NewImageOperationsImpl(),
 			}
@@ -52,8 +60,163 @@ func newInventoryInitCmd() *cobra.Command {
 
 	pluginInventoryInitCmd.Flags().StringVarP(&piiFlags.Repository, "repository", "", "", "repository to publish plugin inventory image")
 	pluginInventoryInitCmd.Flags().StringVarP(&piiFlags.InventoryImageTag, "plugin-inventory-image-tag", "", "latest", "tag to which plugin inventory image needs to be published")
+	pluginInventoryInitCmd.Flags().StringVarP(&piiFlags.InventoryDBFile, "plugin-inventory-db-file", "", "", "local file to create the inventory database instead of publishing it")
 	pluginInventoryInitCmd.Flags().BoolVarP(&piiFlags.Override, "override", "", false, "override the inventory database image if already exists")
-	_ = pluginInventoryInitCmd.MarkFlagRequired("repository")
+	pluginInventoryInitCmd.MarkFlagsMutuallyExclusive("repository", "plugin-inventory-db-file")
 
 	return pluginInventoryInitCmd
 }
+
+type inventoryValidateFlags struct {
+	Repository        string
+	InventoryImageTag string
+	InventoryDBFile   string
+}
+
+func newInventoryValidateCmd() *cobra.Command {
+	var ivFlags = &inventoryValidateFlags{}
+
+	var pluginInventoryValidateCmd = &cobra.Command{
+		Use:          "validate",
+		Short:        "Validate the entries of the plugin inventory database available on the remote repository or locally",
+		SilenceUsage: true,
+		Example:      ``,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ivOptions := inventory.InventoryValidateOptions{
+				Repository:          ivFlags.Repository,
+				InventoryImageTag:   ivFlags.InventoryImageTag,
+				InventoryDBFile:     ivFlags.InventoryDBFile,
+				ImageOperationsImpl: carvelhelpers.NewImageOperationsImpl(),
+			}
+			return ivOptions.ValidateInventory()
+		},
+	}
+
+	pluginInventoryValidateCmd.Flags().StringVarP(&ivFlags.Repository, "repository", "", "", "repository of the plugin inventory image")
+	pluginInventoryValidateCmd.Flags().StringVarP(&ivFlags.InventoryImageTag, "plugin-inventory-image-tag", "", "latest", "tag of the plugin inventory image")
+	pluginInventoryValidateCmd.Flags().StringVarP(&ivFlags.InventoryDBFile, "plugin-inventory-db-file", "", "", "local file for the inventory database")
+	pluginInventoryValidateCmd.MarkFlagsMutuallyExclusive("repository", "plugin-inventory-db-file")
+
+	return pluginInventoryValidateCmd
+}
+
+type inventoryLintFlags struct {
+	Repository        string
+	InventoryImageTag string
+	InventoryDBFile   string
+}
+
+func newInventoryLintCmd() *cobra.Command {
+	var ilFlags = &inventoryLintFlags{}
+
+	var pluginInventoryLintCmd = &cobra.Command{
+		Use:          "lint",
+		Short:        "Check the entries of the plugin inventory database follow the naming, versioning and layout conventions",
+		Long:         "Check the entries of the plugin inventory database available on the remote repository or locally follow the naming, versioning, description and image layout conventions and are unique. The command fails if any problem is found, so it can be used to gate publishing pipelines.",
+		SilenceUsage: true,
+		Example:      ``,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ilOptions := inventory.InventoryLintOptions{
+				Repository:          ilFlags.Repository,
+				InventoryImageTag:   ilFlags.InventoryImageTag,
+				InventoryDBFile:     ilFlags.InventoryDBFile,
+				ImageOperationsImpl: carvelhelpers.NewImageOperationsImpl(),
+			}
+			return ilOptions.LintInventory()
+		},
+	}
+
+	pluginInventoryLintCmd.Flags().StringVarP(&ilFlags.Repository, "repository", "", "", "repository of the plugin inventory image")
+	pluginInventoryLintCmd.Flags().StringVarP(&ilFlags.InventoryImageTag, "plugin-inventory-image-tag", "", "latest", "tag of the plugin inventory image")
+	pluginInventoryLintCmd.Flags().StringVarP(&ilFlags.InventoryDBFile, "plugin-inventory-db-file", "", "", "local file for the inventory database")
+	pluginInventoryLintCmd.MarkFlagsMutuallyExclusive("repository", "plugin-inventory-db-file")
+
+	return pluginInventoryLintCmd
+}
+
+type inventoryVerifyFlags struct {
+	Repository        string
+	InventoryImageTag string
+	InventoryDBFile   string
+}
+
+func newInventoryVerifyCmd() *cobra.Command {
+	var ivFlags = &inventoryVerifyFlags{}
+
+	var pluginInventoryVerifyCmd = &cobra.Command{
+		Use:          "verify",
+		Short:        "Verify the plugin inventory database can be published, including that the URIs of its artifacts can be reached",
+		Long:         "Verify the plugin inventory database available on the remote repository or locally can be published: the versions are semantic versions, the recommended versions are available, no artifact is shared by several plugin versions or platforms, the URIs of the artifacts can be reached and the plugin-groups only refer to plugin versions of the inventory. The command fails if any problem is found, so it can be run before publishing the inventory.",
+		SilenceUsage: true,
+		Example:      ``,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ivOptions := inventory.InventoryVerifyOptions{
+				Repository:          ivFlags.Repository,
+				InventoryImageTag:   ivFlags.InventoryImageTag,
+				InventoryDBFile:     ivFlags.InventoryDBFile,
+				ImageOperationsImpl: carvelhelpers.NewImageOperationsImpl(),
+			}
+			return ivOptions.VerifyInventory()
+		},
+	}
+
+	pluginInventoryVerifyCmd.Flags().StringVarP(&ivFlags.Repository, "repository", "", "", "repository of the plugin inventory image")
+	pluginInventoryVerifyCmd.Flags().StringVarP(&ivFlags.InventoryImageTag, "plugin-inventory-image-tag", "", "latest", "tag of the plugin inventory image")
+	pluginInventoryVerifyCmd.Flags().StringVarP(&ivFlags.InventoryDBFile, "plugin-inventory-db-file", "", "", "local file for the inventory database")
+	pluginInventoryVerifyCmd.MarkFlagsMutuallyExclusive("repository", "plugin-inventory-db-file")
+
+	return pluginInventoryVerifyCmd
+}
+
+type inventorySignFlags struct {
+	Repository        string
+	InventoryImageTag string
+	Key               string
+}
+
+func newInventorySignCmd() *cobra.Command {
+	var isFlags = &inventorySignFlags{}
+
+	var pluginInventorySignCmd = &cobra.Command{
+		Use:          "sign",
+		Short:        "Sign the plugin inventory database image available on the remote repository",
+		Long:         "Sign the plugin inventory database image and publish the cosign signature to the remote repository. The password of the private key is read from the COSIGN_PASSWORD environment variable.",
+		SilenceUsage: true,
+		Example:      ``,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			isOptions := inventory.InventorySignOptions{
+				Repository:          isFlags.Repository,
+				InventoryImageTag:   isFlags.InventoryImageTag,
+				ImageOperationsImpl: carvelhelpers.NewImageOperationsImpl(),
+			}
+			registryOpts, err := getCosignRegistryOptions(isFlags.Repository)
+			if err != nil {
+				return err
+			}
+			isOptions.CosignSigner = cosignhelper.NewCosignSigner(isFlags.Key, registryOpts)
+			return isOptions.SignInventory()
+		},
+	}
+
+	pluginInventorySignCmd.Flags().StringVarP(&isFlags.Repository, "repository", "", "", "repository of the plugin inventory image")
+	pluginInventorySignCmd.Flags().StringVarP(&isFlags.InventoryImageTag, "plugin-inventory-image-tag", "", "latest", "tag of the plugin inventory image")
+	pluginInventorySignCmd.Flags().StringVarP(&isFlags.Key, "key", "", "", "path to the cosign private key or KMS URI used to sign the image")
+	_ = pluginInventorySignCmd.MarkFlagRequired("repository")
+	_ = pluginInventorySignCmd.MarkFlagRequired("key")
+
+	return pluginInventorySignCmd
+}
+
+// getCosignRegistryOptions returns the registry options to access the repository
+// by including the custom certificate configuration if any
+func getCosignRegistryOptions(repository string) (*cosignhelper.RegistryOptions, error) {
+	regCertOptions, err := registry.GetRegistryCertOptionsForImage(repository)
+	if err != nil {
+		return nil, err
+	}
+	return &cosignhelper.RegistryOptions{
+		CACertPaths:    regCertOptions.CACertPaths,
+		SkipCertVerify: regCertOptions.SkipCertVerify,
+		AllowInsecure:  regCertOptions.Insecure,
+	}, nil
+}
@@ -4,10 +4,14 @@
 package inventory
 
 import (
+	"fmt"
 	"path/filepath"
 
 	"github.com/pkg/errors"
 
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/helpers"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
 )
@@ -25,5 +29,42 @@ func inventoryDBUpload(imageOperationsImpl carvelhelpers.ImageOperationsImpl, pl
 	if err != nil {
 		return errors.Wrapf(err, "error while publishing inventory database to the repository as image: %q", pluginInventoryDBImage)
 	}
+	PublishInventoryDBChunks(imageOperationsImpl, pluginInventoryDBImage, dbFile)
 	return nil
 }
+
+// PublishInventoryDBChunks publishes the chunks of the inventory database just published as
+// the image, so that the CLI refreshing its cached inventory only downloads the chunks which
+// changed since. As the CLI downloads the image itself when the chunks are unavailable,
+// failing to publish them is not an error.
+func PublishInventoryDBChunks(imageOperationsImpl carvelhelpers.ImageOperationsImpl, pluginInventoryDBImage, dbFile string) {
+	algorithm, hex, err := imageOperationsImpl.GetImageDigest(pluginInventoryDBImage)
+	if err != nil || hex == "" {
+		log.Warningf("unable to publish the chunks of the plugin inventory database, the digest of %q is unknown", pluginInventoryDBImage)
+		return
+	}
+	chunksImage, err := carvelhelpers.ChunksImageForImage(carvelhelpers.ImageWithDigest(pluginInventoryDBImage, algorithm, hex))
+	if err == nil {
+		err = carvelhelpers.PushFilesInChunks(chunksImage, []string{dbFile}, carvelhelpers.DefaultChunkSize)
+	}
+	if err != nil {
+		log.Warningf("unable to publish the chunks of the plugin inventory database: %v", err)
+		return
+	}
+	log.Infof("published the chunks of the plugin inventory database at: %q", chunksImage)
+}
+
+// getInventoryDBFileToRead returns the local inventory database file if specified, otherwise it
+// downloads the inventory database published on the repository to the specified directory
+func getInventoryDBFileToRead(imageOperationsImpl carvelhelpers.ImageOperationsImpl, repository, inventoryImageTag, inventoryDBFile, dir string) (string, error) {
+	if inventoryDBFile != "" {
+		log.Infof("using local plugin inventory database file: %q", inventoryDBFile)
+		return inventoryDBFile, nil
+	}
+	if repository == "" {
+		return "", errors.New("either the repository or the local inventory database file must be specified")
+	}
+	pluginInventoryDBImage := fmt.Sprintf("%s/%s:%s", repository, helpers.PluginInventoryDBImageName, inventoryImageTag)
+	log.Infof("pulling plugin inventory database from: %q", pluginInventoryDBImage)
+	return inventoryDBDownload(imageOperationsImpl, pluginInventoryDBImage, dir)
+}
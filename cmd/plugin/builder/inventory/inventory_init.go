@@ -4,6 +4,7 @@
 package inventory
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -21,13 +22,23 @@ import (
 type InventoryInitOptions struct {
 	Repository        string
 	InventoryImageTag string
+	InventoryDBFile   string
 	Override          bool
 
 	ImageOperationsImpl carvelhelpers.ImageOperationsImpl
 }
 
-// InitializeInventory initializes the repository with the empty inventory database
+// InitializeInventory initializes the repository with the empty inventory database.
+// If a local inventory database file is specified, the empty database is created
+// at this location instead of being published to the repository.
 func (iio *InventoryInitOptions) InitializeInventory() error {
+	if iio.InventoryDBFile != "" {
+		return iio.initializeLocalInventory()
+	}
+	if iio.Repository == "" {
+		return errors.New("either the repository or the local inventory database file must be specified")
+	}
+
 	// create plugin inventory database image path
 	pluginInventoryDBImage := fmt.Sprintf("%s/%s:%s", iio.Repository, helpers.PluginInventoryDBImageName, iio.InventoryImageTag)
 
@@ -42,7 +53,7 @@ func (iio *InventoryInitOptions) InitializeInventory() error {
 	// Create plugin inventory database
 	dbFile := filepath.Join(os.TempDir(), plugininventory.SQliteDBFileName)
 	_ = os.Remove(dbFile)
-	err := plugininventory.NewSQLiteInventory(dbFile, "").CreateSchema()
+	err := plugininventory.NewSQLiteInventory(dbFile, "").CreateSchema(context.Background())
 	if err != nil {
 		return errors.Wrap(err, "error while creating database")
 	}
@@ -54,7 +65,27 @@ func (iio *InventoryInitOptions) InitializeInventory() error {
 	if err != nil {
 		return errors.Wrapf(err, "error while publishing database to the repository as image: %q", pluginInventoryDBImage)
 	}
+	PublishInventoryDBChunks(iio.ImageOperationsImpl, pluginInventoryDBImage, dbFile)
 	log.Infof("successfully published plugin inventory database")
 
 	return nil
 }
+
+// initializeLocalInventory creates the empty inventory database at the location of the local inventory database file
+func (iio *InventoryInitOptions) initializeLocalInventory() error {
+	if _, err := os.Stat(iio.InventoryDBFile); err == nil {
+		if !iio.Override {
+			return errors.Errorf("%q file already exists. Use `--override` flag to override the content", iio.InventoryDBFile)
+		}
+		if err := os.Remove(iio.InventoryDBFile); err != nil {
+			return errors.Wrapf(err, "unable to remove the existing database %q", iio.InventoryDBFile)
+		}
+	}
+
+	err := plugininventory.NewSQLiteInventory(iio.InventoryDBFile, "").CreateSchema(context.Background())
+	if err != nil {
+		return errors.Wrap(err, "error while creating database")
+	}
+	log.Infof("successfully created plugin inventory database at: %q", iio.InventoryDBFile)
+	return nil
+}
@@ -4,8 +4,11 @@
 package inventory
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -13,6 +16,7 @@ import (
 
 	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/helpers"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/fakes"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
 )
 
 func TestInventorySuite(t *testing.T) {
@@ -59,5 +63,34 @@ var _ = Describe("Unit tests for inventory init", func() {
 			Expect(err.Error()).To(ContainSubstring("error while publishing database to the repository as image"))
 			Expect(err.Error()).To(ContainSubstring(fmt.Sprintf("%s/%s:%s", iip.Repository, helpers.PluginInventoryDBImageName, iip.InventoryImageTag)))
 		})
+
+		var _ = It("when the local inventory database file is created", func() {
+			dir, err := os.MkdirTemp("", "")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			localIIP := InventoryInitOptions{InventoryDBFile: filepath.Join(dir, plugininventory.SQliteDBFileName)}
+			err = localIIP.InitializeInventory()
+			Expect(err).NotTo(HaveOccurred())
+
+			plugins, err := plugininventory.NewSQLiteInventory(localIIP.InventoryDBFile, "").GetAllPlugins(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(plugins).To(BeEmpty())
+
+			// The existing database is only replaced with the override option
+			err = localIIP.InitializeInventory()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("file already exists. Use `--override` flag to override the content"))
+
+			localIIP.Override = true
+			err = localIIP.InitializeInventory()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		var _ = It("when neither the repository nor the local inventory database file is specified", func() {
+			err := (&InventoryInitOptions{}).InitializeInventory()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("either the repository or the local inventory database file must be specified"))
+		})
 	})
 })
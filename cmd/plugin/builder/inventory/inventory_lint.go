@@ -0,0 +1,227 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+)
+
+const (
+	// minDescriptionLength is the minimum length of the description of the plugins and plugin-groups
+	minDescriptionLength = 10
+	// maxDescriptionLength is the maximum length of the description of the plugins and plugin-groups
+	maxDescriptionLength = 200
+)
+
+var (
+	// nameRegex matches the names of the plugins, plugin-groups, vendors and publishers,
+	// e.g. `isolated-cluster`: lower case alphanumeric words separated by dashes
+	nameRegex = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	// digestRegex matches the SHA256 digests of the plugin binaries
+	digestRegex = regexp.MustCompile(`^[a-f0-9]{64}$`)
+)
+
+// InventoryLintOptions defines options for linting the inventory database
+type InventoryLintOptions struct {
+	Repository        string
+	InventoryImageTag string
+	InventoryDBFile   string
+
+	ImageOperationsImpl carvelhelpers.ImageOperationsImpl
+}
+
+// LintInventory checks that the entries of the inventory database are valid and follow
+// the conventions of the plugin inventories and returns all the problems found
+func (ilo *InventoryLintOptions) LintInventory() error {
+	tempDir, err := os.MkdirTemp("", "")
+	if err != nil {
+		return errors.Wrap(err, "unable to create temporary directory")
+	}
+	defer os.RemoveAll(tempDir)
+
+	dbFile, err := getInventoryDBFileToRead(ilo.ImageOperationsImpl, ilo.Repository, ilo.InventoryImageTag, ilo.InventoryDBFile, tempDir)
+	if err != nil {
+		return err
+	}
+
+	db := plugininventory.NewSQLiteInventory(dbFile, "")
+	plugins, err := db.GetPlugins(context.Background(), &plugininventory.PluginInventoryFilter{IncludeHidden: true})
+	if err != nil {
+		return errors.Wrap(err, "error while reading the plugins of the inventory database")
+	}
+	groups, err := db.GetPluginGroups(context.Background(), plugininventory.PluginGroupFilter{IncludeHidden: true})
+	if err != nil {
+		return errors.Wrap(err, "error while reading the plugin groups of the inventory database")
+	}
+
+	var errs []error
+	for _, p := range plugins {
+		errs = append(errs, validatePluginEntry(p)...)
+		errs = append(errs, lintPluginEntry(p)...)
+	}
+	for _, pg := range groups {
+		errs = append(errs, validatePluginGroup(pg, plugins)...)
+		errs = append(errs, lintPluginGroup(pg)...)
+	}
+	uniquenessErrs, err := lintUniqueness(db, plugins)
+	if err != nil {
+		return err
+	}
+	errs = append(errs, uniquenessErrs...)
+
+	if len(errs) > 0 {
+		return errors.Wrapf(kerrors.NewAggregate(errs), "%d problems found in the plugin inventory database", len(errs))
+	}
+
+	log.Infof("successfully linted %d plugins and %d plugin groups", len(plugins), len(groups))
+	return nil
+}
+
+// lintPluginEntry returns the violations of the conventions by the plugin entry
+func lintPluginEntry(p *plugininventory.PluginInventoryEntry) []error {
+	id := fmt.Sprintf("plugin '%s_%s'", p.Name, p.Target)
+	errs := lintNames(id, map[string]string{"name": p.Name, "vendor": p.Vendor, "publisher": p.Publisher})
+	if err := lintDescription(p.Description); err != nil {
+		errs = append(errs, errors.Wrap(err, id))
+	}
+	for _, version := range sortedKeys(p.Artifacts) {
+		if err := lintVersion(version); err != nil {
+			errs = append(errs, errors.Wrap(err, id))
+		}
+		artifacts := p.Artifacts[version]
+		for i := range artifacts {
+			if err := lintArtifact(p, version, &artifacts[i]); err != nil {
+				errs = append(errs, errors.Wrapf(err, "%s: version %q", id, version))
+			}
+		}
+	}
+	return errs
+}
+
+// lintArtifact returns an error if the digest or the image of the artifact do not follow the conventions
+func lintArtifact(p *plugininventory.PluginInventoryEntry, version string, a *distribution.Artifact) error {
+	if a.URI != "" {
+		// The layout of the URIs of the binaries published outside of a registry is not enforced
+		return nil
+	}
+
+	// The images are stored relative to the repository of the inventory
+	image := strings.TrimPrefix(a.Image, "/")
+	expectedImage := fmt.Sprintf("%s/%s/%s/%s/%s/%s:%s", p.Vendor, p.Publisher, a.OS, a.Arch, p.Target, p.Name, version)
+	if a.IsMultiArch() {
+		expectedImage = fmt.Sprintf("%s/%s/%s/%s:%s", p.Vendor, p.Publisher, p.Target, p.Name, version)
+	} else if !digestRegex.MatchString(a.Digest) {
+		return errors.Errorf("the digest %q of the artifact for %s/%s is not a SHA256 digest", a.Digest, a.OS, a.Arch)
+	} else if i := strings.Index(image, "@sha256:"); i >= 0 && digestRegex.MatchString(image[i+len("@sha256:"):]) {
+		// The platform images of a multi-arch image index are referred to by digest
+		expectedImage = fmt.Sprintf("%s/%s/%s/%s%s", p.Vendor, p.Publisher, p.Target, p.Name, image[i:])
+	}
+	if image != expectedImage {
+		return errors.Errorf("the image %q of the artifact for %s/%s does not follow the %q layout", image, a.OS, a.Arch, expectedImage)
+	}
+	return nil
+}
+
+// lintPluginGroup returns the violations of the conventions by the plugin group
+func lintPluginGroup(pg *plugininventory.PluginGroup) []error {
+	id := fmt.Sprintf("plugin group '%s'", plugininventory.PluginGroupToID(pg))
+	errs := lintNames(id, map[string]string{"name": pg.Name, "vendor": pg.Vendor, "publisher": pg.Publisher})
+	if err := lintDescription(pg.Description); err != nil {
+		errs = append(errs, errors.Wrap(err, id))
+	}
+	for _, version := range sortedKeys(pg.Versions) {
+		if err := lintVersion(version); err != nil {
+			errs = append(errs, errors.Wrap(err, id))
+		}
+	}
+	return errs
+}
+
+// lintNames returns an error for every name which does not follow the naming conventions
+func lintNames(id string, names map[string]string) []error {
+	var errs []error
+	for _, kind := range sortedKeys(names) {
+		name := names[kind]
+		if !nameRegex.MatchString(name) {
+			errs = append(errs, errors.Errorf("%s: the %s %q must only contain lower case alphanumeric characters separated by dashes", id, kind, name))
+		}
+	}
+	return errs
+}
+
+// lintDescription returns an error if the description is too short or too long
+func lintDescription(description string) error {
+	if strings.TrimSpace(description) != description {
+		return errors.Errorf("the description %q has leading or trailing spaces", description)
+	}
+	if len(description) < minDescriptionLength || len(description) > maxDescriptionLength {
+		return errors.Errorf("the description %q must be between %d and %d characters long", description, minDescriptionLength, maxDescriptionLength)
+	}
+	return nil
+}
+
+// lintVersion returns an error if the version is not a semantic version prefixed with `v`, e.g. v1.2.3
+func lintVersion(version string) error {
+	if !strings.HasPrefix(version, "v") {
+		return errors.Errorf("the version %q must be prefixed with 'v'", version)
+	}
+	return nil
+}
+
+// lintUniqueness returns an error for every plugin published by several vendors or publishers.
+// The entries of a plugin are merged by name and target when read from the inventory database,
+// so the plugin is published by other vendors or publishers if the entry of its vendor and
+// publisher does not have all its artifacts.
+func lintUniqueness(db plugininventory.PluginInventory, plugins []*plugininventory.PluginInventoryEntry) ([]error, error) {
+	var errs []error
+	for _, p := range plugins {
+		vendorPlugins, err := db.GetPlugins(context.Background(), &plugininventory.PluginInventoryFilter{
+			Name:          p.Name,
+			Target:        p.Target,
+			Vendor:        p.Vendor,
+			Publisher:     p.Publisher,
+			IncludeHidden: true,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "error while reading the plugins of the inventory database")
+		}
+		if len(vendorPlugins) != 1 || countArtifacts(vendorPlugins[0]) != countArtifacts(p) {
+			errs = append(errs, errors.Errorf("plugin '%s_%s': the plugin is published by several vendors or publishers", p.Name, p.Target))
+		}
+	}
+	return errs, nil
+}
+
+// countArtifacts returns the number of artifacts of all the versions of the plugin
+func countArtifacts(p *plugininventory.PluginInventoryEntry) int {
+	count := 0
+	for _, artifacts := range p.Artifacts {
+		count += len(artifacts)
+	}
+	return count
+}
+
+// sortedKeys returns the keys of the map in order, so that the problems are always reported in the same order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
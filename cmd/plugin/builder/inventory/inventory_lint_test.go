@@ -0,0 +1,115 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+)
+
+var _ = Describe("Unit tests for inventory lint", func() {
+	var dir, dbFile string
+	var db plugininventory.PluginInventory
+	digest := strings.Repeat("0123456789abcdef", 4)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "")
+		Expect(err).NotTo(HaveOccurred())
+		dbFile = filepath.Join(dir, plugininventory.SQliteDBFileName)
+		db = plugininventory.NewSQLiteInventory(dbFile, "")
+		Expect(db.CreateSchema(context.Background())).To(Succeed())
+	})
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	insertPlugin := func(name, vendor, description, version string, artifact distribution.Artifact) {
+		err := db.InsertPlugin(context.Background(), &plugininventory.PluginInventoryEntry{
+			Name:        name,
+			Target:      types.TargetK8s,
+			Description: description,
+			Publisher:   "tkg",
+			Vendor:      vendor,
+			Artifacts:   distribution.Artifacts{version: []distribution.Artifact{artifact}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	insertPluginGroup := func(name, description string) {
+		err := db.InsertPluginGroup(context.Background(), &plugininventory.PluginGroup{
+			Vendor:      "vmware",
+			Publisher:   "tkg",
+			Name:        name,
+			Description: description,
+			Versions: map[string][]*plugininventory.PluginGroupPluginEntry{
+				"v1.0.0": {
+					{PluginIdentifier: plugininventory.PluginIdentifier{Name: "foo", Target: types.TargetK8s, Version: "v0.0.1"}},
+				},
+			},
+		}, false)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	var _ = Context("tests for the inventory lint function", func() {
+		var _ = It("when the local inventory database follows the conventions", func() {
+			insertPlugin("foo", "vmware", "foo plugin description", "v0.0.1", distribution.Artifact{OS: "linux", Arch: "amd64", Digest: digest, Image: "vmware/tkg/linux/amd64/kubernetes/foo:v0.0.1"})
+			insertPlugin("foo", "vmware", "foo plugin description", "v0.0.1", distribution.Artifact{OS: distribution.ArtifactPlatformMultiArch, Arch: distribution.ArtifactPlatformMultiArch, Image: "vmware/tkg/kubernetes/foo:v0.0.1"})
+			insertPlugin("foo", "vmware", "foo plugin description", "v0.0.1", distribution.Artifact{OS: "darwin", Arch: "arm64", Digest: digest, Image: "vmware/tkg/kubernetes/foo@sha256:" + digest})
+			insertPluginGroup("default", "default plugin group")
+
+			ilo := InventoryLintOptions{InventoryDBFile: dbFile}
+			Expect(ilo.LintInventory()).To(Succeed())
+		})
+
+		var _ = It("when the local inventory database does not follow the conventions", func() {
+			insertPlugin("foo", "vmware", "foo plugin description", "v0.0.1", distribution.Artifact{OS: "linux", Arch: "amd64", Digest: "fake-digest", Image: "vmware/tkg/linux/amd64/kubernetes/foo:v0.0.1"})
+			insertPlugin("foo", "other", "foo plugin description", "v0.0.2", distribution.Artifact{OS: "linux", Arch: "amd64", Digest: digest, Image: "other/tkg/linux/amd64/kubernetes/foo:v0.0.2"})
+			insertPlugin("Bar_Plugin", "vmware", "bar", "0.0.1", distribution.Artifact{OS: "linux", Arch: "amd64", Digest: digest, Image: "bar:v0.0.1"})
+			insertPluginGroup("default", " default plugin group")
+			insertPluginGroup("Default", "default plugin group")
+
+			ilo := InventoryLintOptions{InventoryDBFile: dbFile}
+			err := ilo.LintInventory()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("problems found in the plugin inventory database"))
+			Expect(err.Error()).To(ContainSubstring("the digest \"fake-digest\" of the artifact for linux/amd64 is not a SHA256 digest"))
+			Expect(err.Error()).To(ContainSubstring("plugin 'foo_kubernetes': the plugin is published by several vendors or publishers"))
+			Expect(err.Error()).To(ContainSubstring("the name \"Bar_Plugin\" must only contain lower case alphanumeric characters separated by dashes"))
+			Expect(err.Error()).To(ContainSubstring("the description \"bar\" must be between 10 and 200 characters long"))
+			Expect(err.Error()).To(ContainSubstring("the version \"0.0.1\" must be prefixed with 'v'"))
+			Expect(err.Error()).To(ContainSubstring("does not follow the \"vmware/tkg/linux/amd64/kubernetes/Bar_Plugin:0.0.1\" layout"))
+			Expect(err.Error()).To(ContainSubstring("the description \" default plugin group\" has leading or trailing spaces"))
+			Expect(err.Error()).To(ContainSubstring("plugin group 'vmware-tkg/Default': the name \"Default\" must only contain lower case alphanumeric characters separated by dashes"))
+		})
+
+		var _ = It("when the names of an entry do not follow the conventions", func() {
+			insertPlugin("Foo", "VMware", "foo plugin description", "v0.0.1", distribution.Artifact{OS: "linux", Arch: "amd64", Digest: digest, Image: "VMware/tkg/linux/amd64/kubernetes/Foo:v0.0.1"})
+
+			ilo := InventoryLintOptions{InventoryDBFile: dbFile}
+			err := ilo.LintInventory()
+			Expect(err).To(HaveOccurred())
+			// The problems are reported in the same order every time
+			Expect(strings.Index(err.Error(), "the name \"Foo\"")).To(BeNumerically("<", strings.Index(err.Error(), "the vendor \"VMware\"")))
+			for i := 0; i < 10; i++ {
+				Expect(ilo.LintInventory().Error()).To(Equal(err.Error()))
+			}
+		})
+
+		var _ = It("when the inventory database is neither specified locally nor in a repository", func() {
+			ilo := InventoryLintOptions{}
+			Expect(ilo.LintInventory()).NotTo(Succeed())
+		})
+	})
+})
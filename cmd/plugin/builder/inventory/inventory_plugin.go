@@ -5,10 +5,13 @@
 package inventory
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
@@ -24,6 +27,15 @@ import (
 	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
 )
 
+// getImageIndexPlatformImages returns the platform images of a multi-arch image index
+var getImageIndexPlatformImages = carvelhelpers.GetImageIndexPlatformImages
+
+// imageIndexReader is implemented by the image operations which read the platform images
+// of the multi-arch image indexes themselves, e.g. from the local plugin packages
+type imageIndexReader interface {
+	GetImageIndexPlatformImages(indexImage string) ([]carvelhelpers.PlatformImage, error)
+}
+
 // InventoryPluginUpdateOptions defines options for inserting plugin to the inventory database
 type InventoryPluginUpdateOptions struct {
 	Repository        string
@@ -34,6 +46,12 @@ type InventoryPluginUpdateOptions struct {
 	InventoryDBFile   string
 	DeactivatePlugins bool
 	ValidateOnly      bool
+	// MultiArch adds an artifact per plugin version referring to the multi-arch image
+	// index of the version along with the artifacts of the platforms of the image index
+	MultiArch bool
+	// SkipExisting only adds the artifacts which are not already in the inventory
+	// database so that the same plugins can be added again without error
+	SkipExisting bool
 
 	ImageOperationsImpl carvelhelpers.ImageOperationsImpl
 }
@@ -41,9 +59,16 @@ type InventoryPluginUpdateOptions struct {
 // PluginAdd add plugin entry to the inventory database by downloading the database from the repository, updating it locally
 // and publishing the inventory database as OCI image on the remote repository
 func (ipuo *InventoryPluginUpdateOptions) PluginAdd() error {
+	releasedAt := time.Now()
 	pluginAddFunc := func(dbFile string, entry *plugininventory.PluginInventoryEntry) error {
 		db := plugininventory.NewSQLiteInventory(dbFile, "")
-		err := db.InsertPlugin(entry)
+		setPluginReleaseTime(entry, releasedAt)
+		if ipuo.SkipExisting {
+			if err := removeExistingArtifacts(db, entry); err != nil {
+				return err
+			}
+		}
+		err := db.InsertPlugin(context.Background(), entry)
 		if err != nil {
 			return errors.Wrapf(err, "error while inserting plugin '%s_%s'", entry.Name, entry.Target)
 		}
@@ -52,13 +77,66 @@ func (ipuo *InventoryPluginUpdateOptions) PluginAdd() error {
 	return ipuo.genericInventoryUpdater(pluginAddFunc)
 }
 
+// setPluginReleaseTime records the time at which the versions of the plugin are published to the inventory
+func setPluginReleaseTime(entry *plugininventory.PluginInventoryEntry, releasedAt time.Time) {
+	entry.ReleasedAt = make(map[string]time.Time, len(entry.Artifacts))
+	for version := range entry.Artifacts {
+		entry.ReleasedAt[version] = releasedAt
+	}
+}
+
+// removeExistingArtifacts removes the artifacts of the entry which are already in the inventory database.
+// The versions already in the inventory keep the release time they were published with. An error is
+// returned if an artifact already in the inventory database has another digest, as the plugin binary
+// of a released version must not be overwritten.
+func removeExistingArtifacts(db plugininventory.PluginInventory, entry *plugininventory.PluginInventoryEntry) error {
+	existingEntries, err := db.GetPlugins(context.Background(), &plugininventory.PluginInventoryFilter{Name: entry.Name, Target: entry.Target, IncludeHidden: true})
+	if err != nil {
+		return errors.Wrapf(err, "error while reading plugin '%s_%s'", entry.Name, entry.Target)
+	}
+	for _, existingEntry := range existingEntries {
+		for version, existingArtifacts := range existingEntry.Artifacts {
+			var artifacts distribution.ArtifactList
+			for _, a := range entry.Artifacts[version] {
+				existing := findPlatformArtifact(existingArtifacts, a.OS, a.Arch)
+				if existing == nil {
+					artifacts = append(artifacts, a)
+					continue
+				}
+				if existing.Digest != "" && existing.Digest != a.Digest {
+					return errors.Errorf("plugin '%s_%s' version %q for %s/%s is already in the inventory database with digest %s, it cannot be overwritten with digest %s", entry.Name, entry.Target, version, a.OS, a.Arch, existing.Digest, a.Digest)
+				}
+			}
+			if len(artifacts) == 0 {
+				delete(entry.Artifacts, version)
+				continue
+			}
+			entry.Artifacts[version] = artifacts
+			if existingReleasedAt, found := existingEntry.ReleasedAt[version]; found {
+				entry.ReleasedAt[version] = existingReleasedAt
+			}
+		}
+	}
+	return nil
+}
+
+// findPlatformArtifact returns the artifact of the platform, or nil if there is none
+func findPlatformArtifact(artifacts distribution.ArtifactList, os, arch string) *distribution.Artifact {
+	for i := range artifacts {
+		if artifacts[i].OS == os && artifacts[i].Arch == arch {
+			return &artifacts[i]
+		}
+	}
+	return nil
+}
+
 // UpdatePluginActivationState updates plugin entry in the inventory database by downloading the
 // database from the repository, updating it locally and publishing the inventory database
 // as OCI image on the remote repository
 func (ipuo *InventoryPluginUpdateOptions) UpdatePluginActivationState() error {
 	activateDeactivateFunc := func(dbFile string, entry *plugininventory.PluginInventoryEntry) error {
 		db := plugininventory.NewSQLiteInventory(dbFile, "")
-		err := db.UpdatePluginActivationState(entry)
+		err := db.UpdatePluginActivationState(context.Background(), entry)
 		if err != nil {
 			return errors.Wrapf(err, "error while updating plugin '%s_%s'", entry.Name, entry.Target)
 		}
@@ -98,6 +176,21 @@ func (ipuo *InventoryPluginUpdateOptions) preparePluginInventoryEntriesFromManif
 
 	var pluginInventoryEntries []*plugininventory.PluginInventoryEntry
 
+	if ipuo.MultiArch {
+		for i := range pluginManifest.Plugins {
+			pluginInventoryEntry, err := ipuo.prepareMultiArchPluginInventoryEntry(pluginManifest.Plugins[i])
+			if err != nil {
+				return nil, err
+			}
+			pluginInventoryEntry.ReleaseNotes = getPluginReleaseNotes(pluginManifest.Plugins[i])
+			pluginInventoryEntry.MinCLIVersions = pluginManifest.Plugins[i].MinCLIVersions
+			pluginInventoryEntry.Licenses = getPluginLicenses(pluginManifest.Plugins[i])
+			setPluginDeprecation(pluginInventoryEntry, pluginManifest.Plugins[i])
+			pluginInventoryEntries = append(pluginInventoryEntries, pluginInventoryEntry)
+		}
+		return pluginInventoryEntries, nil
+	}
+
 	pluginBinaryDigestMap := map[string]string{}
 	if !ipuo.ValidateOnly {
 		pluginBinaryDigestMap, err = ipuo.fetchPluginBinaryDigest(pluginManifest)
@@ -117,6 +210,12 @@ func (ipuo *InventoryPluginUpdateOptions) preparePluginInventoryEntriesFromManif
 				}
 			}
 		}
+		if pluginInventoryEntry != nil {
+			pluginInventoryEntry.ReleaseNotes = getPluginReleaseNotes(pluginManifest.Plugins[i])
+			pluginInventoryEntry.MinCLIVersions = pluginManifest.Plugins[i].MinCLIVersions
+			pluginInventoryEntry.Licenses = getPluginLicenses(pluginManifest.Plugins[i])
+			setPluginDeprecation(pluginInventoryEntry, pluginManifest.Plugins[i])
+		}
 
 		pluginInventoryEntries = append(pluginInventoryEntries, pluginInventoryEntry)
 	}
@@ -124,6 +223,116 @@ func (ipuo *InventoryPluginUpdateOptions) preparePluginInventoryEntriesFromManif
 	return pluginInventoryEntries, nil
 }
 
+// getPluginReleaseNotes returns the release notes of the plugin versions specified in the plugin manifest
+func getPluginReleaseNotes(plugin cli.Plugin) map[string]plugininventory.PluginReleaseNotes {
+	if len(plugin.ReleaseNotes) == 0 {
+		return nil
+	}
+	releaseNotes := make(map[string]plugininventory.PluginReleaseNotes, len(plugin.ReleaseNotes))
+	for version, notes := range plugin.ReleaseNotes {
+		releaseNotes[version] = plugininventory.PluginReleaseNotes{
+			ChangelogURL: notes.ChangelogURL,
+			Notes:        notes.Notes,
+		}
+	}
+	return releaseNotes
+}
+
+// getPluginLicenses returns the licenses of the plugin versions specified in the plugin manifest
+func getPluginLicenses(plugin cli.Plugin) map[string]plugininventory.PluginLicense {
+	if len(plugin.Licenses) == 0 {
+		return nil
+	}
+	licenses := make(map[string]plugininventory.PluginLicense, len(plugin.Licenses))
+	for version, license := range plugin.Licenses {
+		licenses[version] = plugininventory.PluginLicense{
+			License: license.License,
+			SBOMURI: license.SBOMURI,
+		}
+	}
+	return licenses
+}
+
+// setPluginDeprecation sets the deprecation of the plugin declared in the manifest to its inventory entry
+func setPluginDeprecation(pluginInventoryEntry *plugininventory.PluginInventoryEntry, plugin cli.Plugin) {
+	pluginInventoryEntry.Deprecated = plugin.Deprecated
+	pluginInventoryEntry.DeprecationMessage = plugin.DeprecationMessage
+	pluginInventoryEntry.ReplacedBy = plugin.ReplacedBy
+}
+
+// prepareMultiArchPluginInventoryEntry returns the plugin inventory entry with an artifact per version
+// referring to the multi-arch image index of the version. An artifact is also added for every platform
+// of the image index, referring to the platform image by digest, so the binaries can be verified.
+func (ipuo *InventoryPluginUpdateOptions) prepareMultiArchPluginInventoryEntry(plugin cli.Plugin) (*plugininventory.PluginInventoryEntry, error) {
+	pluginInventoryEntry := &plugininventory.PluginInventoryEntry{
+		Name:        plugin.Name,
+		Target:      configtypes.Target(plugin.Target),
+		Description: plugin.Description,
+		Publisher:   ipuo.Publisher,
+		Vendor:      ipuo.Vendor,
+		Artifacts:   make(map[string]distribution.ArtifactList),
+		Hidden:      ipuo.DeactivatePlugins,
+	}
+	for _, version := range plugin.Versions {
+		imageBasePath := fmt.Sprintf("%s/%s/%s/%s", ipuo.Vendor, ipuo.Publisher, plugin.Target, plugin.Name)
+		indexImageBasePath := fmt.Sprintf("%s:%s", imageBasePath, version)
+		artifacts := distribution.ArtifactList{
+			{
+				OS:    distribution.ArtifactPlatformMultiArch,
+				Arch:  distribution.ArtifactPlatformMultiArch,
+				Image: indexImageBasePath,
+			},
+		}
+		if !ipuo.ValidateOnly {
+			indexImage := fmt.Sprintf("%s/%s", ipuo.Repository, indexImageBasePath)
+			log.Infof("verifying plugin image index: '%s'", indexImage)
+			if err := ipuo.ImageOperationsImpl.ResolveImage(indexImage); err != nil {
+				return nil, errors.Wrapf(err, "error while resolving the plugin image index %q", indexImage)
+			}
+			platformArtifacts, err := ipuo.preparePlatformArtifacts(plugin, indexImage, imageBasePath)
+			if err != nil {
+				return nil, err
+			}
+			artifacts = append(artifacts, platformArtifacts...)
+		}
+		pluginInventoryEntry.Artifacts[version] = artifacts
+	}
+	return pluginInventoryEntry, nil
+}
+
+// preparePlatformArtifacts returns an artifact for every platform of the plugin image index with
+// the digest of the plugin binary of the platform. The platform images are referred to by digest.
+func (ipuo *InventoryPluginUpdateOptions) preparePlatformArtifacts(plugin cli.Plugin, indexImage, imageBasePath string) (distribution.ArtifactList, error) {
+	getPlatformImages := getImageIndexPlatformImages
+	if r, ok := ipuo.ImageOperationsImpl.(imageIndexReader); ok {
+		getPlatformImages = r.GetImageIndexPlatformImages
+	}
+	platformImages, err := getPlatformImages(indexImage)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error while reading the platforms of the plugin image index %q", indexImage)
+	}
+	var artifacts distribution.ArtifactList
+	for _, pi := range platformImages {
+		osArch := cli.Arch(pi.OS + "_" + pi.Arch)
+		digest, err := ipuo.ImageOperationsImpl.GetFileDigestFromImage(pi.Image, cli.MakeArtifactName(plugin.Name, osArch))
+		if err != nil {
+			return nil, errors.Wrapf(err, "error while getting the plugin binary digest from the image %q", pi.Image)
+		}
+		// The platform images are stored relative to the repository as the other images
+		image := strings.TrimPrefix(pi.Image, ipuo.Repository+"/")
+		if i := strings.LastIndex(pi.Image, "@"); i >= 0 {
+			image = imageBasePath + pi.Image[i:]
+		}
+		artifacts = append(artifacts, distribution.Artifact{
+			OS:     pi.OS,
+			Arch:   pi.Arch,
+			Image:  image,
+			Digest: digest,
+		})
+	}
+	return artifacts, nil
+}
+
 func (ipuo *InventoryPluginUpdateOptions) fetchPluginBinaryDigest(pluginManifest *cli.Manifest) (map[string]string, error) {
 	pluginBinaryDigestMap := map[string]string{}
 
@@ -300,6 +509,7 @@ func (ipuo *InventoryPluginUpdateOptions) putInventoryDBFile(dbFile string) erro
 	if err != nil {
 		return errors.Wrapf(err, "error while publishing inventory database to the repository as image: %q", pluginInventoryDBImage)
 	}
+	PublishInventoryDBChunks(ipuo.ImageOperationsImpl, pluginInventoryDBImage, dbFile)
 	log.Infof("successfully published plugin inventory database at: %q", pluginInventoryDBImage)
 	return nil
 }
@@ -0,0 +1,196 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/helpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/airgapped"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+)
+
+// InventoryPluginVersionsDeactivateOptions defines options for deactivating a range of
+// plugin versions in the inventory database and the inventory metadata database
+type InventoryPluginVersionsDeactivateOptions struct {
+	Repository        string
+	InventoryImageTag string
+	InventoryDBFile   string
+	// InventoryMetadataDBFile is the local inventory metadata database to update
+	// when the local inventory database is specified
+	InventoryMetadataDBFile string
+	Publisher               string
+	Vendor                  string
+	PluginName              string
+	// Target of the plugin, all the targets if empty
+	Target string
+	// Versions is the semver constraint of the versions to deactivate, e.g. ">= v1.2.0, < v1.2.5"
+	Versions string
+
+	ImageOperationsImpl carvelhelpers.ImageOperationsImpl
+}
+
+// DeactivatePluginVersions marks the plugin versions matching the version constraint as hidden in the
+// inventory database and removes them from the inventory metadata database, if any, in a single operation.
+// The inventory databases are downloaded from the repository and published once updated unless local
+// database files are specified. The number of updated rows of each plugin version is reported.
+func (ipvdo *InventoryPluginVersionsDeactivateOptions) DeactivatePluginVersions() error {
+	constraint, err := semver.NewConstraint(ipvdo.Versions)
+	if err != nil {
+		return errors.Wrapf(err, "invalid version constraint %q", ipvdo.Versions)
+	}
+
+	tempDir, err := os.MkdirTemp("", "")
+	if err != nil {
+		return errors.Wrap(err, "unable to create temporary directory")
+	}
+	defer os.RemoveAll(tempDir)
+
+	dbFile, err := getInventoryDBFileToRead(ipvdo.ImageOperationsImpl, ipvdo.Repository, ipvdo.InventoryImageTag, ipvdo.InventoryDBFile, tempDir)
+	if err != nil {
+		return err
+	}
+	metadataDBFile, err := ipvdo.getInventoryMetadataDBFile(filepath.Join(tempDir, "metadata"))
+	if err != nil {
+		return err
+	}
+
+	var target configtypes.Target
+	if ipvdo.Target != "" {
+		target = configtypes.StringToTarget(ipvdo.Target)
+	}
+	db := plugininventory.NewSQLiteInventory(dbFile, "")
+	plugins, err := db.GetPlugins(context.Background(), &plugininventory.PluginInventoryFilter{
+		Name:          ipvdo.PluginName,
+		Target:        target,
+		Publisher:     ipvdo.Publisher,
+		Vendor:        ipvdo.Vendor,
+		IncludeHidden: true,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "error while reading plugin %q", ipvdo.PluginName)
+	}
+
+	var deactivated []*plugininventory.PluginIdentifier
+	updatedRows := 0
+	for _, p := range plugins {
+		entry := &plugininventory.PluginInventoryEntry{
+			Name:      p.Name,
+			Target:    p.Target,
+			Publisher: p.Publisher,
+			Vendor:    p.Vendor,
+			Hidden:    true,
+			Artifacts: distribution.Artifacts{},
+		}
+		for version, artifacts := range p.Artifacts {
+			v, err := semver.NewVersion(version)
+			if err != nil || !constraint.Check(v) {
+				continue
+			}
+			entry.Artifacts[version] = artifacts
+		}
+		if len(entry.Artifacts) == 0 {
+			continue
+		}
+		if err := db.UpdatePluginActivationState(context.Background(), entry); err != nil {
+			return errors.Wrapf(err, "error while updating plugin '%s_%s'", p.Name, p.Target)
+		}
+
+		versions := make([]string, 0, len(entry.Artifacts))
+		for version := range entry.Artifacts {
+			versions = append(versions, version)
+		}
+		sort.Strings(versions)
+		for _, version := range versions {
+			log.Infof("deactivated plugin '%s_%s:%s' (%d rows updated)", p.Name, p.Target, version, len(entry.Artifacts[version]))
+			updatedRows += len(entry.Artifacts[version])
+			deactivated = append(deactivated, &plugininventory.PluginIdentifier{Name: p.Name, Target: p.Target, Version: version})
+		}
+	}
+	if len(deactivated) == 0 {
+		return errors.Errorf("no version of plugin %q matching %q found in the inventory database", ipvdo.PluginName, ipvdo.Versions)
+	}
+
+	if metadataDBFile != "" {
+		mdb := plugininventory.NewSQLiteInventoryMetadata(metadataDBFile)
+		for _, pi := range deactivated {
+			if err := mdb.DeletePluginIdentifier(pi); err != nil {
+				return err
+			}
+		}
+		log.Infof("removed %d plugin versions from the inventory metadata database", len(deactivated))
+	}
+
+	if err := ipvdo.putInventoryDBFiles(dbFile, metadataDBFile); err != nil {
+		return err
+	}
+	log.Infof("deactivated %d plugin versions, %d rows updated in the inventory database", len(deactivated), updatedRows)
+	return nil
+}
+
+func (ipvdo *InventoryPluginVersionsDeactivateOptions) getPluginInventoryDBImagePath() string {
+	return fmt.Sprintf("%s/%s:%s", ipvdo.Repository, helpers.PluginInventoryDBImageName, ipvdo.InventoryImageTag)
+}
+
+// getInventoryMetadataDBFile returns the local inventory metadata database file if the local inventory
+// database file is specified, otherwise it downloads the inventory metadata database of the repository
+// to the specified directory. An empty file is returned if there is no inventory metadata database.
+func (ipvdo *InventoryPluginVersionsDeactivateOptions) getInventoryMetadataDBFile(dir string) (string, error) {
+	if ipvdo.InventoryDBFile != "" {
+		return ipvdo.InventoryMetadataDBFile, nil
+	}
+	metadataImage, err := airgapped.GetPluginInventoryMetadataImage(ipvdo.getPluginInventoryDBImagePath())
+	if err != nil {
+		return "", err
+	}
+	if err := ipvdo.ImageOperationsImpl.ResolveImage(metadataImage); err != nil {
+		log.Infof("no plugin inventory metadata database found at %q, skipping its update", metadataImage)
+		return "", nil
+	}
+	log.Infof("pulling plugin inventory metadata database from: %q", metadataImage)
+	if err := ipvdo.ImageOperationsImpl.DownloadImageAndSaveFilesToDir(metadataImage, dir); err != nil {
+		return "", errors.Wrapf(err, "error while pulling database from the image: %q", metadataImage)
+	}
+	return filepath.Join(dir, plugininventory.SQliteInventoryMetadataDBFileName), nil
+}
+
+// putInventoryDBFiles publishes the updated inventory database and inventory metadata
+// database to the repository unless the local inventory database file is specified
+func (ipvdo *InventoryPluginVersionsDeactivateOptions) putInventoryDBFiles(dbFile, metadataDBFile string) error {
+	if ipvdo.InventoryDBFile != "" {
+		log.Infof("successfully updated plugin inventory database file at: %q", ipvdo.InventoryDBFile)
+		return nil
+	}
+
+	pluginInventoryDBImage := ipvdo.getPluginInventoryDBImagePath()
+	log.Infof("publishing plugin inventory database at: %q", pluginInventoryDBImage)
+	if err := inventoryDBUpload(ipvdo.ImageOperationsImpl, pluginInventoryDBImage, dbFile); err != nil {
+		return err
+	}
+	if metadataDBFile == "" {
+		return nil
+	}
+
+	metadataImage, err := airgapped.GetPluginInventoryMetadataImage(pluginInventoryDBImage)
+	if err != nil {
+		return err
+	}
+	log.Infof("publishing plugin inventory metadata database at: %q", metadataImage)
+	if err := ipvdo.ImageOperationsImpl.PushImage(metadataImage, []string{metadataDBFile}); err != nil {
+		return errors.Wrapf(err, "error while publishing inventory metadata database to the repository as image: %q", metadataImage)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/fakes"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+)
+
+var _ = Describe("Unit tests for inventory plugin deactivate-versions", func() {
+	var (
+		dir, dbFile, metadataDBFile string
+		fakeImageOperations         *fakes.ImageOperationsImpl
+		ipvdo                       *InventoryPluginVersionsDeactivateOptions
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "")
+		Expect(err).NotTo(HaveOccurred())
+		dbFile = filepath.Join(dir, plugininventory.SQliteDBFileName)
+		db := plugininventory.NewSQLiteInventory(dbFile, "")
+		Expect(db.CreateSchema(context.Background())).To(Succeed())
+		metadataDBFile = filepath.Join(dir, plugininventory.SQliteInventoryMetadataDBFileName)
+		mdb := plugininventory.NewSQLiteInventoryMetadata(metadataDBFile)
+		Expect(mdb.CreateInventoryMetadataDBSchema()).To(Succeed())
+
+		for _, version := range []string{"v1.1.0", "v1.2.0", "v1.2.4", "v1.2.5"} {
+			err := db.InsertPlugin(context.Background(), &plugininventory.PluginInventoryEntry{
+				Name:        "foo",
+				Target:      types.TargetK8s,
+				Description: "Foo plugin",
+				Publisher:   "fakepublisher",
+				Vendor:      "fakevendor",
+				Artifacts: distribution.Artifacts{
+					version: []distribution.Artifact{
+						{OS: "darwin", Arch: "amd64", Digest: "fake-digest", Image: "fake-uri"},
+						{OS: "linux", Arch: "amd64", Digest: "fake-digest", Image: "fake-uri"},
+					},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mdb.InsertPluginIdentifier(&plugininventory.PluginIdentifier{Name: "foo", Target: types.TargetK8s, Version: version})).To(Succeed())
+		}
+
+		fakeImageOperations = &fakes.ImageOperationsImpl{}
+		ipvdo = &InventoryPluginVersionsDeactivateOptions{
+			InventoryDBFile:         dbFile,
+			InventoryMetadataDBFile: metadataDBFile,
+			Vendor:                  "fakevendor",
+			Publisher:               "fakepublisher",
+			PluginName:              "foo",
+			Versions:                ">= v1.2.0, < v1.2.5",
+			ImageOperationsImpl:     fakeImageOperations,
+		}
+	})
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	getActiveVersions := func(file string) []string {
+		plugins, err := plugininventory.NewSQLiteInventory(file, "").GetPlugins(context.Background(), &plugininventory.PluginInventoryFilter{Name: "foo"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plugins).To(HaveLen(1))
+		var versions []string
+		for version := range plugins[0].Artifacts {
+			versions = append(versions, version)
+		}
+		return versions
+	}
+
+	// isAvailable returns true if the plugin version is still in the inventory metadata database
+	isAvailable := func(file, version string) bool {
+		err := plugininventory.NewSQLiteInventoryMetadata(file).InsertPluginIdentifier(&plugininventory.PluginIdentifier{Name: "foo", Target: types.TargetK8s, Version: version})
+		return err != nil
+	}
+
+	var _ = It("when the version constraint is invalid", func() {
+		ipvdo.Versions = "not-a-constraint"
+		err := ipvdo.DeactivatePluginVersions()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid version constraint"))
+	})
+
+	var _ = It("when no plugin version matches the version constraint", func() {
+		ipvdo.Versions = ">= v2.0.0"
+		err := ipvdo.DeactivatePluginVersions()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("no version of plugin \"foo\" matching \">= v2.0.0\" found"))
+	})
+
+	var _ = It("when local inventory database files are specified", func() {
+		Expect(ipvdo.DeactivatePluginVersions()).To(Succeed())
+
+		Expect(getActiveVersions(dbFile)).To(ConsistOf("v1.1.0", "v1.2.5"))
+		Expect(isAvailable(metadataDBFile, "v1.1.0")).To(BeTrue())
+		Expect(isAvailable(metadataDBFile, "v1.2.0")).To(BeFalse())
+		Expect(isAvailable(metadataDBFile, "v1.2.4")).To(BeFalse())
+		Expect(isAvailable(metadataDBFile, "v1.2.5")).To(BeTrue())
+		Expect(fakeImageOperations.PushImageCallCount()).To(Equal(0))
+	})
+
+	var _ = It("when the inventory databases are published on the repository", func() {
+		ipvdo.InventoryDBFile = ""
+		ipvdo.InventoryMetadataDBFile = ""
+		ipvdo.Repository = "test-repo.com"
+		ipvdo.InventoryImageTag = "latest"
+		fakeImageOperations.ResolveImageReturns(nil)
+		fakeImageOperations.DownloadImageAndSaveFilesToDirStub = func(image, path string) error {
+			if filepath.Base(path) == "metadata" {
+				Expect(os.MkdirAll(path, 0755)).To(Succeed())
+				return utils.CopyFile(metadataDBFile, filepath.Join(path, plugininventory.SQliteInventoryMetadataDBFileName))
+			}
+			return utils.CopyFile(dbFile, filepath.Join(path, plugininventory.SQliteDBFileName))
+		}
+		publishedDir := filepath.Join(dir, "published")
+		Expect(os.MkdirAll(publishedDir, 0755)).To(Succeed())
+		fakeImageOperations.PushImageStub = func(_ string, files []string) error {
+			return utils.CopyFile(files[0], filepath.Join(publishedDir, filepath.Base(files[0])))
+		}
+
+		Expect(ipvdo.DeactivatePluginVersions()).To(Succeed())
+
+		Expect(fakeImageOperations.PushImageCallCount()).To(Equal(2))
+		image, _ := fakeImageOperations.PushImageArgsForCall(0)
+		Expect(image).To(Equal("test-repo.com/plugin-inventory:latest"))
+		image, _ = fakeImageOperations.PushImageArgsForCall(1)
+		Expect(image).To(Equal("test-repo.com/plugin-inventory-metadata:latest"))
+
+		Expect(getActiveVersions(filepath.Join(publishedDir, plugininventory.SQliteDBFileName))).To(ConsistOf("v1.1.0", "v1.2.5"))
+		publishedMetadataDBFile := filepath.Join(publishedDir, plugininventory.SQliteInventoryMetadataDBFileName)
+		Expect(isAvailable(publishedMetadataDBFile, "v1.2.0")).To(BeFalse())
+		Expect(isAvailable(publishedMetadataDBFile, "v1.2.5")).To(BeTrue())
+	})
+
+	var _ = It("when the repository has no inventory metadata database", func() {
+		ipvdo.InventoryDBFile = ""
+		ipvdo.InventoryMetadataDBFile = ""
+		ipvdo.Repository = "test-repo.com"
+		ipvdo.InventoryImageTag = "latest"
+		fakeImageOperations.ResolveImageReturns(errors.New("image not found"))
+		fakeImageOperations.DownloadImageAndSaveFilesToDirStub = func(_, path string) error {
+			return utils.CopyFile(dbFile, filepath.Join(path, plugininventory.SQliteDBFileName))
+		}
+
+		Expect(ipvdo.DeactivatePluginVersions()).To(Succeed())
+		Expect(fakeImageOperations.PushImageCallCount()).To(Equal(1))
+	})
+})
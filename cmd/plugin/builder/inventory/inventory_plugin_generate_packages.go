@@ -0,0 +1,417 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+)
+
+// Package formats supported by the package generation
+const (
+	PackageFormatHomebrew   = "homebrew"
+	PackageFormatChocolatey = "chocolatey"
+	PackageFormatAsdf       = "asdf"
+)
+
+// PackageFormats are the package formats supported by the package generation
+var PackageFormats = []string{PackageFormatHomebrew, PackageFormatChocolatey, PackageFormatAsdf}
+
+// InventoryPluginGeneratePackagesOptions defines options for generating package manager
+// packages for plugins of the inventory database
+type InventoryPluginGeneratePackagesOptions struct {
+	Repository        string
+	InventoryImageTag string
+	InventoryDBFile   string
+	// Plugins are the plugins to generate packages for, as `name:target`
+	Plugins []string
+	// Version is the version of the plugins to package, `latest` for their recommended version.
+	// The asdf plugin definitions provide all the versions of the plugins.
+	Version string
+	Format  string
+	// DownloadURLTemplate is the template of the URL from which the package managers download a plugin
+	// binary, e.g. `https://downloads.example.com/{{ .Name }}/{{ .Version }}/{{ .FileName }}`
+	DownloadURLTemplate string
+	OutputDir           string
+
+	ImageOperationsImpl carvelhelpers.ImageOperationsImpl
+}
+
+// pluginPackage is the data used to render the package of a plugin
+type pluginPackage struct {
+	// PackageName is the name of the package, `tanzu-plugin-<name>-<target>`
+	PackageName string
+	// ClassName is the Ruby class name of the Homebrew formula
+	ClassName   string
+	Name        string
+	Target      string
+	Description string
+	Vendor      string
+	Version     string
+	// Versions are all the versions of the plugin, from the oldest to the newest
+	Versions []string
+	// Binaries are the plugin binaries of Version
+	Binaries []pluginPackageBinary
+	// AllBinaries are the plugin binaries of all the versions
+	AllBinaries []pluginPackageBinary
+}
+
+// pluginPackageBinary is a plugin binary the package managers download. Digest is the
+// sha256 of the binary found in the inventory database, which the package managers verify.
+type pluginPackageBinary struct {
+	Name     string
+	Target   string
+	Version  string
+	OS       string
+	Arch     string
+	Digest   string
+	FileName string
+	URL      string
+}
+
+// GeneratePackages generates the packages of the specified format for the plugins in the output directory.
+// The packages download the plugin binaries from the download URL, verify them against the digests of the
+// inventory database and install them with the Tanzu CLI from a local source, so that the plugins are
+// installed the same way as when installed from a plugin repository.
+func (igpo *InventoryPluginGeneratePackagesOptions) GeneratePackages() error {
+	templates, ok := packageTemplates[igpo.Format]
+	if !ok {
+		return errors.Errorf("invalid package format %q, it must be one of: %s", igpo.Format, strings.Join(PackageFormats, ", "))
+	}
+	urlTemplate, err := template.New("download-url").Option("missingkey=error").Parse(igpo.DownloadURLTemplate)
+	if err != nil {
+		return errors.Wrap(err, "invalid download URL template")
+	}
+
+	tempDir, err := os.MkdirTemp("", "")
+	if err != nil {
+		return errors.Wrap(err, "unable to create temporary directory")
+	}
+	defer os.RemoveAll(tempDir)
+
+	dbFile, err := getInventoryDBFileToRead(igpo.ImageOperationsImpl, igpo.Repository, igpo.InventoryImageTag, igpo.InventoryDBFile, tempDir)
+	if err != nil {
+		return err
+	}
+	db := plugininventory.NewSQLiteInventory(dbFile, "")
+
+	for _, plugin := range igpo.Plugins {
+		pkg, err := igpo.getPluginPackage(db, plugin, urlTemplate)
+		if err != nil {
+			return err
+		}
+		for fileName, tmpl := range templates {
+			if err := writePackageFile(filepath.Join(igpo.OutputDir, strings.ReplaceAll(fileName, "PACKAGE", pkg.PackageName)), tmpl, pkg); err != nil {
+				return err
+			}
+		}
+		log.Infof("generated %s package %q for plugin 'name:%s' 'target:%s' 'version:%s'", igpo.Format, pkg.PackageName, pkg.Name, pkg.Target, pkg.Version)
+	}
+	return nil
+}
+
+// getPluginPackage returns the data of the package of the plugin specified as `name:target`
+func (igpo *InventoryPluginGeneratePackagesOptions) getPluginPackage(db plugininventory.PluginInventory, plugin string, urlTemplate *template.Template) (*pluginPackage, error) {
+	name, target, found := strings.Cut(plugin, ":")
+	if !found || name == "" || !configtypes.IsValidTarget(target, true, false) {
+		return nil, errors.Errorf("invalid plugin %q, it must be specified as 'name:target'", plugin)
+	}
+	target = string(configtypes.StringToTarget(target))
+
+	entries, err := db.GetPlugins(context.Background(), &plugininventory.PluginInventoryFilter{Name: name, Target: configtypes.Target(target)})
+	if err != nil {
+		return nil, errors.Wrap(err, "error while reading the plugins of the inventory database")
+	}
+	if len(entries) == 0 {
+		return nil, errors.Errorf("plugin 'name:%s' 'target:%s' is not present in the database", name, target)
+	}
+	entry := entries[0]
+
+	version := igpo.Version
+	if version == "" || version == cli.VersionLatest {
+		version = entry.RecommendedVersion
+	}
+	if _, exists := entry.Artifacts[version]; !exists {
+		return nil, errors.Errorf("version %q of plugin 'name:%s' 'target:%s' is not present in the database", version, name, target)
+	}
+
+	pkg := &pluginPackage{
+		PackageName: "tanzu-plugin-" + name + "-" + target,
+		Name:        name,
+		Target:      target,
+		Description: entry.Description,
+		Vendor:      entry.Vendor,
+		Version:     version,
+	}
+	pkg.ClassName = homebrewClassName(pkg.PackageName)
+
+	for v := range entry.Artifacts {
+		pkg.Versions = append(pkg.Versions, v)
+	}
+	if err := utils.SortVersions(pkg.Versions); err != nil {
+		return nil, errors.Wrapf(err, "error parsing the versions of plugin 'name:%s' 'target:%s'", name, target)
+	}
+	for _, v := range pkg.Versions {
+		for _, a := range entry.Artifacts[v] {
+			// Multi-arch artifacts have no digest to verify the binaries against
+			if a.IsMultiArch() || a.Digest == "" {
+				continue
+			}
+			b := pluginPackageBinary{
+				Name:     name,
+				Target:   target,
+				Version:  v,
+				OS:       a.OS,
+				Arch:     a.Arch,
+				Digest:   a.Digest,
+				FileName: cli.MakeArtifactName(name, cli.Arch(a.OS+"_"+a.Arch)),
+			}
+			buf := &bytes.Buffer{}
+			if err := urlTemplate.Execute(buf, b); err != nil {
+				return nil, errors.Wrap(err, "error while generating the download URL of the plugin binary")
+			}
+			b.URL = buf.String()
+			pkg.AllBinaries = append(pkg.AllBinaries, b)
+			if v == version {
+				pkg.Binaries = append(pkg.Binaries, b)
+			}
+		}
+	}
+	sort.SliceStable(pkg.Binaries, func(i, j int) bool {
+		return pkg.Binaries[i].OS+pkg.Binaries[i].Arch < pkg.Binaries[j].OS+pkg.Binaries[j].Arch
+	})
+	if len(pkg.Binaries) == 0 {
+		return nil, errors.Errorf("version %q of plugin 'name:%s' 'target:%s' has no binary with a digest", version, name, target)
+	}
+	return pkg, nil
+}
+
+// homebrewClassName returns the Ruby class name Homebrew expects for the formula name
+func homebrewClassName(formulaName string) string {
+	var className string
+	for _, part := range strings.Split(formulaName, "-") {
+		if part != "" {
+			className += strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return className
+}
+
+// binariesForOS returns the plugin binaries for the OS
+func binariesForOS(binaries []pluginPackageBinary, os string) []pluginPackageBinary {
+	var osBinaries []pluginPackageBinary
+	for _, b := range binaries {
+		if b.OS == os {
+			osBinaries = append(osBinaries, b)
+		}
+	}
+	return osBinaries
+}
+
+// writePackageFile renders the template of a package file
+func writePackageFile(path, tmpl string, pkg *pluginPackage) error {
+	t, err := template.New(filepath.Base(path)).Funcs(template.FuncMap{
+		"trimv": func(v string) string { return strings.TrimPrefix(v, "v") },
+		"join":  strings.Join,
+		"list":  func(values ...string) []string { return values },
+		"forOS": binariesForOS,
+	}).Parse(tmpl)
+	if err != nil {
+		return err
+	}
+	buf := &bytes.Buffer{}
+	if err := t.Execute(buf, pkg); err != nil {
+		return errors.Wrapf(err, "error while generating %q", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// The scripts of the asdf plugin definitions must be executable
+	mode := os.FileMode(0644)
+	if filepath.Base(filepath.Dir(path)) == "bin" {
+		mode = 0755
+	}
+	if err := os.WriteFile(path, buf.Bytes(), mode); err != nil {
+		return errors.Wrapf(err, "error while writing %q", path)
+	}
+	return nil
+}
+
+// packageTemplates are the templates of the files of the packages of each format, by file path.
+// PACKAGE in the file path is replaced by the name of the package.
+var packageTemplates = map[string]map[string]string{
+	PackageFormatHomebrew: {
+		"PACKAGE.rb": homebrewFormulaTemplate,
+	},
+	PackageFormatChocolatey: {
+		"PACKAGE/PACKAGE.nuspec":                chocolateyNuspecTemplate,
+		"PACKAGE/tools/chocolateyinstall.ps1":   chocolateyInstallTemplate,
+		"PACKAGE/tools/chocolateyuninstall.ps1": chocolateyUninstallTemplate,
+	},
+	PackageFormatAsdf: {
+		"asdf-PACKAGE/bin/list-all": asdfListAllTemplate,
+		"asdf-PACKAGE/bin/download": asdfDownloadTemplate,
+		"asdf-PACKAGE/bin/install":  asdfInstallTemplate,
+	},
+}
+
+// The plugins are installed with `tanzu plugin install --local-source` from a directory containing a
+// plugin_manifest.yaml file and the binary at <target>/<name>/<version>/tanzu-<name>-<os>_<arch>
+const (
+	homebrewFormulaTemplate = `# Generated from the plugin inventory by 'tanzu builder inventory plugin generate-packages'
+class {{ .ClassName }} < Formula
+  desc {{ printf "%q" .Description }}
+  homepage "https://github.com/vmware-tanzu/tanzu-cli"
+  version "{{ .Version }}"
+
+  depends_on "tanzu-cli"
+{{ range $os := list "darwin" "linux" }}{{ $binaries := forOS $.Binaries $os }}{{ if $binaries }}
+  on_{{ if eq $os "darwin" }}macos{{ else }}linux{{ end }} do
+{{- range $binaries }}
+    on_{{ if eq .Arch "arm64" }}arm{{ else }}intel{{ end }} do
+      url "{{ .URL }}"
+      sha256 "{{ .Digest }}"
+    end
+{{- end }}
+  end
+{{ end }}{{ end }}
+  def install
+    os = OS.mac? ? "darwin" : "linux"
+    arch = Hardware::CPU.arm? ? "arm64" : "amd64"
+    (libexec/"{{ .Target }}/{{ .Name }}/{{ .Version }}").install Dir["*"].first => "tanzu-{{ .Name }}-#{os}_#{arch}"
+    (libexec/"plugin_manifest.yaml").write <<~EOS
+      plugins:
+        - name: {{ .Name }}
+          target: {{ .Target }}
+          description: {{ printf "%q" .Description }}
+          versions:
+            - {{ .Version }}
+    EOS
+  end
+
+  def post_install
+    system Formula["tanzu-cli"].opt_bin/"tanzu", "plugin", "install", "{{ .Name }}", "--target", "{{ .Target }}", "--local-source", libexec
+  end
+end
+`
+
+	chocolateyNuspecTemplate = `<?xml version="1.0" encoding="utf-8"?>
+<!-- Generated from the plugin inventory by 'tanzu builder inventory plugin generate-packages' -->
+<package xmlns="http://schemas.microsoft.com/packaging/2015/06/nuspec.xsd">
+  <metadata>
+    <id>{{ .PackageName }}</id>
+    <version>{{ trimv .Version }}</version>
+    <title>Tanzu CLI plugin {{ .Name }} ({{ .Target }})</title>
+    <authors>{{ html .Vendor }}</authors>
+    <projectUrl>https://github.com/vmware-tanzu/tanzu-cli</projectUrl>
+    <description>{{ html .Description }}</description>
+    <tags>tanzu tanzu-cli-plugin</tags>
+    <dependencies>
+      <dependency id="tanzu-cli" />
+    </dependencies>
+  </metadata>
+  <files>
+    <file src="tools\**" target="tools" />
+  </files>
+</package>
+`
+
+	chocolateyInstallTemplate = `# Generated from the plugin inventory by 'tanzu builder inventory plugin generate-packages'
+$ErrorActionPreference = 'Stop'
+
+$arch = if ($env:PROCESSOR_ARCHITECTURE -eq 'ARM64') { 'arm64' } else { 'amd64' }
+{{- range forOS .Binaries "windows" }}
+if ($arch -eq '{{ .Arch }}') { $url = '{{ .URL }}'; $checksum = '{{ .Digest }}' }
+{{- end }}
+if (-not $url) { throw "Plugin {{ .Name }} {{ .Version }} is not available for windows/$arch" }
+
+$localSource = Join-Path $env:ChocolateyPackageFolder 'plugin'
+$pluginDir = Join-Path $localSource '{{ .Target }}\{{ .Name }}\{{ .Version }}'
+New-Item -ItemType Directory -Force -Path $pluginDir | Out-Null
+Get-ChocolateyWebFile -PackageName $env:ChocolateyPackageName -FileFullPath (Join-Path $pluginDir "tanzu-{{ .Name }}-windows_$arch.exe") -Url64bit $url -Checksum64 $checksum -ChecksumType64 'sha256'
+Set-Content -Path (Join-Path $localSource 'plugin_manifest.yaml') -Value @"
+plugins:
+  - name: {{ .Name }}
+    target: {{ .Target }}
+    versions:
+      - {{ .Version }}
+"@
+
+& tanzu plugin install {{ .Name }} --target {{ .Target }} --local-source $localSource
+if ($LASTEXITCODE -ne 0) { throw "Unable to install plugin {{ .Name }} {{ .Version }}" }
+`
+
+	chocolateyUninstallTemplate = `# Generated from the plugin inventory by 'tanzu builder inventory plugin generate-packages'
+$ErrorActionPreference = 'Stop'
+
+& tanzu plugin delete {{ .Name }} --target {{ .Target }} --yes
+if ($LASTEXITCODE -ne 0) { throw "Unable to uninstall plugin {{ .Name }}" }
+`
+
+	asdfListAllTemplate = `#!/usr/bin/env bash
+# Generated from the plugin inventory by 'tanzu builder inventory plugin generate-packages'
+echo "{{ join .Versions " " }}"
+`
+
+	asdfDownloadTemplate = `#!/usr/bin/env bash
+# Generated from the plugin inventory by 'tanzu builder inventory plugin generate-packages'
+set -euo pipefail
+
+os=$(uname -s | tr '[:upper:]' '[:lower:]')
+case "$(uname -m)" in
+  x86_64 | amd64) arch=amd64 ;;
+  aarch64 | arm64) arch=arm64 ;;
+  *) arch=$(uname -m) ;;
+esac
+
+case "${ASDF_INSTALL_VERSION}/${os}/${arch}" in
+{{- range .AllBinaries }}{{ if ne .OS "windows" }}
+  {{ .Version }}/{{ .OS }}/{{ .Arch }}) url='{{ .URL }}'; digest='{{ .Digest }}' ;;
+{{- end }}{{ end }}
+  *) echo "Plugin {{ .Name }} ${ASDF_INSTALL_VERSION} is not available for ${os}/${arch}" >&2; exit 1 ;;
+esac
+
+binary="${ASDF_DOWNLOAD_PATH}/tanzu-{{ .Name }}-${os}_${arch}"
+curl -fsSL -o "${binary}" "${url}"
+actual=$( (sha256sum "${binary}" 2>/dev/null || shasum -a 256 "${binary}") | cut -d' ' -f1)
+if [ "${actual}" != "${digest}" ]; then
+  echo "The digest of ${url} is ${actual} instead of ${digest}" >&2
+  exit 1
+fi
+chmod +x "${binary}"
+`
+
+	asdfInstallTemplate = `#!/usr/bin/env bash
+# Generated from the plugin inventory by 'tanzu builder inventory plugin generate-packages'
+set -euo pipefail
+
+plugin_dir="${ASDF_INSTALL_PATH}/{{ .Target }}/{{ .Name }}/${ASDF_INSTALL_VERSION}"
+mkdir -p "${plugin_dir}"
+cp "${ASDF_DOWNLOAD_PATH}"/tanzu-{{ .Name }}-* "${plugin_dir}/"
+cat >"${ASDF_INSTALL_PATH}/plugin_manifest.yaml" <<EOF
+plugins:
+  - name: {{ .Name }}
+    target: {{ .Target }}
+    versions:
+      - ${ASDF_INSTALL_VERSION}
+EOF
+
+tanzu plugin install {{ .Name }} --target {{ .Target }} --local-source "${ASDF_INSTALL_PATH}"
+`
+)
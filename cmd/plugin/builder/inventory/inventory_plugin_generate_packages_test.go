@@ -0,0 +1,164 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+)
+
+var _ = Describe("Unit tests for inventory plugin generate-packages", func() {
+	var (
+		tmpDir    string
+		outputDir string
+		igpo      InventoryPluginGeneratePackagesOptions
+	)
+
+	readFile := func(path string) string {
+		b, err := os.ReadFile(filepath.Join(outputDir, path))
+		Expect(err).ToNot(HaveOccurred())
+		return string(b)
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "")
+		Expect(err).ToNot(HaveOccurred())
+		outputDir = filepath.Join(tmpDir, "packages")
+
+		dbFile := filepath.Join(tmpDir, plugininventory.SQliteDBFileName)
+		db := plugininventory.NewSQLiteInventory(dbFile, "")
+		Expect(db.CreateSchema(context.Background())).To(Succeed())
+		artifacts := func(digest string) distribution.ArtifactList {
+			return distribution.ArtifactList{
+				{OS: "darwin", Arch: "amd64", Digest: digest + "-darwin-amd64", Image: "vmware/tkg/darwin/amd64/kubernetes/foo"},
+				{OS: "darwin", Arch: "arm64", Digest: digest + "-darwin-arm64", Image: "vmware/tkg/darwin/arm64/kubernetes/foo"},
+				{OS: "linux", Arch: "amd64", Digest: digest + "-linux-amd64", Image: "vmware/tkg/linux/amd64/kubernetes/foo"},
+				{OS: "windows", Arch: "amd64", Digest: digest + "-windows-amd64", Image: "vmware/tkg/windows/amd64/kubernetes/foo"},
+			}
+		}
+		Expect(db.InsertPlugin(context.Background(), &plugininventory.PluginInventoryEntry{
+			Name:        "foo",
+			Target:      types.TargetK8s,
+			Description: `Foo "plugin"`,
+			Publisher:   "tkg",
+			Vendor:      "vmware",
+			Artifacts:   distribution.Artifacts{"v1.0.0": artifacts("digest100"), "v1.1.0": artifacts("digest110")},
+		})).To(Succeed())
+
+		igpo = InventoryPluginGeneratePackagesOptions{
+			InventoryDBFile:     dbFile,
+			Plugins:             []string{"foo:k8s"},
+			Version:             "latest",
+			DownloadURLTemplate: "https://downloads.example.com/{{ .Name }}/{{ .Version }}/{{ .FileName }}",
+			OutputDir:           outputDir,
+		}
+	})
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	It("generates the Homebrew formula of the recommended version", func() {
+		igpo.Format = PackageFormatHomebrew
+		Expect(igpo.GeneratePackages()).To(Succeed())
+
+		formula := readFile("tanzu-plugin-foo-kubernetes.rb")
+		Expect(formula).To(ContainSubstring("class TanzuPluginFooKubernetes < Formula"))
+		Expect(formula).To(ContainSubstring(`desc "Foo \"plugin\""`))
+		Expect(formula).To(ContainSubstring(`version "v1.1.0"`))
+		Expect(formula).To(ContainSubstring(`url "https://downloads.example.com/foo/v1.1.0/tanzu-foo-darwin_arm64"`))
+		Expect(formula).To(ContainSubstring(`sha256 "digest110-darwin-arm64"`))
+		Expect(formula).To(ContainSubstring(`sha256 "digest110-linux-amd64"`))
+		Expect(formula).ToNot(ContainSubstring("windows"))
+		Expect(formula).ToNot(ContainSubstring("digest100"))
+		Expect(formula).To(ContainSubstring(`"plugin", "install", "foo", "--target", "kubernetes", "--local-source", libexec`))
+	})
+
+	It("generates the Chocolatey package of the specified version", func() {
+		igpo.Format = PackageFormatChocolatey
+		igpo.Version = "v1.0.0"
+		Expect(igpo.GeneratePackages()).To(Succeed())
+
+		nuspec := readFile("tanzu-plugin-foo-kubernetes/tanzu-plugin-foo-kubernetes.nuspec")
+		Expect(nuspec).To(ContainSubstring("<id>tanzu-plugin-foo-kubernetes</id>"))
+		Expect(nuspec).To(ContainSubstring("<version>1.0.0</version>"))
+		Expect(nuspec).To(ContainSubstring("<description>Foo &#34;plugin&#34;</description>"))
+
+		install := readFile("tanzu-plugin-foo-kubernetes/tools/chocolateyinstall.ps1")
+		Expect(install).To(ContainSubstring("if ($arch -eq 'amd64') { $url = 'https://downloads.example.com/foo/v1.0.0/tanzu-foo-windows_amd64.exe'; $checksum = 'digest100-windows-amd64' }"))
+		Expect(install).ToNot(ContainSubstring("darwin"))
+		Expect(install).To(ContainSubstring("tanzu plugin install foo --target kubernetes --local-source $localSource"))
+
+		uninstall := readFile("tanzu-plugin-foo-kubernetes/tools/chocolateyuninstall.ps1")
+		Expect(uninstall).To(ContainSubstring("tanzu plugin delete foo --target kubernetes --yes"))
+	})
+
+	It("generates the asdf plugin definition with all the versions", func() {
+		igpo.Format = PackageFormatAsdf
+		Expect(igpo.GeneratePackages()).To(Succeed())
+
+		Expect(readFile("asdf-tanzu-plugin-foo-kubernetes/bin/list-all")).To(ContainSubstring(`echo "v1.0.0 v1.1.0"`))
+		download := readFile("asdf-tanzu-plugin-foo-kubernetes/bin/download")
+		Expect(download).To(ContainSubstring("v1.0.0/linux/amd64) url='https://downloads.example.com/foo/v1.0.0/tanzu-foo-linux_amd64'; digest='digest100-linux-amd64' ;;"))
+		Expect(download).To(ContainSubstring("v1.1.0/darwin/arm64) url='https://downloads.example.com/foo/v1.1.0/tanzu-foo-darwin_arm64'; digest='digest110-darwin-arm64' ;;"))
+		Expect(download).ToNot(ContainSubstring("windows"))
+		Expect(readFile("asdf-tanzu-plugin-foo-kubernetes/bin/install")).To(ContainSubstring(`tanzu plugin install foo --target kubernetes --local-source "${ASDF_INSTALL_PATH}"`))
+
+		for _, script := range []string{"list-all", "download", "install"} {
+			path := filepath.Join(outputDir, "asdf-tanzu-plugin-foo-kubernetes", "bin", script)
+			fi, err := os.Stat(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fi.Mode().Perm() & 0100).ToNot(BeZero())
+			// The scripts must be valid bash scripts
+			Expect(exec.Command("bash", "-n", path).Run()).To(Succeed())
+		}
+	})
+
+	It("fails when the package format is invalid", func() {
+		igpo.Format = "snap"
+		err := igpo.GeneratePackages()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(`invalid package format "snap", it must be one of: homebrew, chocolatey, asdf`))
+	})
+
+	It("fails when the plugin is not specified as name:target", func() {
+		igpo.Format = PackageFormatHomebrew
+		igpo.Plugins = []string{"foo"}
+		err := igpo.GeneratePackages()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(`invalid plugin "foo", it must be specified as 'name:target'`))
+	})
+
+	It("fails when the plugin or the version is not in the database", func() {
+		igpo.Format = PackageFormatHomebrew
+		igpo.Plugins = []string{"bar:global"}
+		err := igpo.GeneratePackages()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("plugin 'name:bar' 'target:global' is not present in the database"))
+
+		igpo.Plugins = []string{"foo:kubernetes"}
+		igpo.Version = "v2.0.0"
+		err = igpo.GeneratePackages()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(`version "v2.0.0" of plugin 'name:foo' 'target:kubernetes' is not present in the database`))
+	})
+
+	It("fails when the download URL template is invalid", func() {
+		igpo.Format = PackageFormatHomebrew
+		igpo.DownloadURLTemplate = "https://downloads.example.com/{{ .Unknown }}"
+		err := igpo.GeneratePackages()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("error while generating the download URL of the plugin binary"))
+	})
+})
@@ -5,6 +5,7 @@
 package inventory
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
@@ -31,6 +32,9 @@ type InventoryPluginGroupUpdateOptions struct {
 	InventoryDBFile         string
 	DeactivatePluginGroup   bool
 	Override                bool
+	// SkipExisting does not add the plugin-group version again if it is already in the inventory
+	// database with the same plugins so that the same plugin-group can be added again without error
+	SkipExisting bool
 
 	ImageOperationsImpl carvelhelpers.ImageOperationsImpl
 }
@@ -50,10 +54,21 @@ func (ipuo *InventoryPluginGroupUpdateOptions) PluginGroupAdd() error {
 		return errors.Wrapf(err, "error while reading plugin group")
 	}
 
+	db := plugininventory.NewSQLiteInventory(dbFile, "")
+	if ipuo.SkipExisting && !ipuo.Override {
+		exists, err := PluginGroupVersionExists(db, pg, ipuo.GroupVersion)
+		if err != nil {
+			return err
+		}
+		if exists {
+			log.Infof("plugin group '%s:%s' is already in the inventory database, skipping", plugininventory.PluginGroupToID(pg), ipuo.GroupVersion)
+			return ipuo.putInventoryDBFile(dbFile)
+		}
+	}
+
 	// Insert PluginGroup to the database
 	log.Info("updating plugin inventory database with plugin group entry")
-	db := plugininventory.NewSQLiteInventory(dbFile, "")
-	err = db.InsertPluginGroup(pg, ipuo.Override)
+	err = db.InsertPluginGroup(context.Background(), pg, ipuo.Override)
 	if err != nil {
 		return errors.Wrapf(err, "error while inserting plugin group '%s'", pg.Name)
 	}
@@ -61,6 +76,49 @@ func (ipuo *InventoryPluginGroupUpdateOptions) PluginGroupAdd() error {
 	return ipuo.putInventoryDBFile(dbFile)
 }
 
+// PluginGroupVersionExists returns true if the version of the plugin-group is already in the inventory
+// database with the same plugins, and an error if it is already in the inventory with other plugins
+func PluginGroupVersionExists(db plugininventory.PluginInventory, pg *plugininventory.PluginGroup, version string) (bool, error) {
+	existingGroups, err := db.GetPluginGroups(context.Background(), plugininventory.PluginGroupFilter{Vendor: pg.Vendor, Publisher: pg.Publisher, Name: pg.Name, Version: version, IncludeHidden: true})
+	if err != nil {
+		return false, errors.Wrapf(err, "error while reading plugin group '%s'", plugininventory.PluginGroupToID(pg))
+	}
+	if len(existingGroups) == 0 {
+		return false, nil
+	}
+	existingPlugins, exists := existingGroups[0].Versions[version]
+	if !exists {
+		return false, nil
+	}
+	if !samePluginGroupPlugins(existingPlugins, pg.Versions[version]) {
+		return false, errors.Errorf("plugin group '%s:%s' is already in the inventory database with different plugins", plugininventory.PluginGroupToID(pg), version)
+	}
+	return true, nil
+}
+
+// samePluginGroupPlugins returns true if the plugin entries are the same, regardless of their order
+func samePluginGroupPlugins(plugins, otherPlugins []*plugininventory.PluginGroupPluginEntry) bool {
+	if len(plugins) != len(otherPlugins) {
+		return false
+	}
+	// The targets of the plugin-group manifests are not normalized, e.g. k8s instead of kubernetes
+	normalized := func(p *plugininventory.PluginGroupPluginEntry) plugininventory.PluginGroupPluginEntry {
+		entry := *p
+		entry.Target = types.StringToTarget(string(p.Target))
+		return entry
+	}
+	entries := make(map[plugininventory.PluginGroupPluginEntry]bool, len(plugins))
+	for _, p := range plugins {
+		entries[normalized(p)] = true
+	}
+	for _, p := range otherPlugins {
+		if !entries[normalized(p)] {
+			return false
+		}
+	}
+	return true
+}
+
 func (ipuo *InventoryPluginGroupUpdateOptions) getPluginGroupFromManifest() (*plugininventory.PluginGroup, error) {
 	pg := plugininventory.PluginGroup{
 		Vendor:      ipuo.Vendor,
@@ -119,7 +177,7 @@ func (ipuo *InventoryPluginGroupUpdateOptions) UpdatePluginGroupActivationState(
 	// Insert PluginGroup to the database
 	log.Info("updating plugin inventory database with plugin group entry")
 	db := plugininventory.NewSQLiteInventory(dbFile, "")
-	err = db.UpdatePluginGroupActivationState(pg)
+	err = db.UpdatePluginGroupActivationState(context.Background(), pg)
 	if err != nil {
 		return errors.Wrapf(err, "error while updating activation state of plugin group '%s'", pg.Name)
 	}
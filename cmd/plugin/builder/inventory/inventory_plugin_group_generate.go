@@ -0,0 +1,132 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+)
+
+// InventoryPluginGroupGenerateOptions defines options for generating a plugin-group manifest from a template
+type InventoryPluginGroupGenerateOptions struct {
+	Repository        string
+	InventoryImageTag string
+	InventoryDBFile   string
+	TemplateFile      string
+	// Variables are the values of the variables used in the template, e.g. `{{ .tkgVersion }}`
+	Variables  map[string]string
+	OutputFile string
+
+	ImageOperationsImpl carvelhelpers.ImageOperationsImpl
+}
+
+// GeneratePluginGroupManifest generates the plugin-group manifest by substituting the variables of the
+// template and by resolving the `latest` plugin versions to the latest versions found in the inventory
+// database. An error is returned if any plugin of the plugin-group is not in the inventory database.
+func (ipgo *InventoryPluginGroupGenerateOptions) GeneratePluginGroupManifest() error {
+	pluginGroupManifest, err := ipgo.renderTemplate()
+	if err != nil {
+		return err
+	}
+
+	tempDir, err := os.MkdirTemp("", "")
+	if err != nil {
+		return errors.Wrap(err, "unable to create temporary directory")
+	}
+	defer os.RemoveAll(tempDir)
+
+	dbFile, err := getInventoryDBFileToRead(ipgo.ImageOperationsImpl, ipgo.Repository, ipgo.InventoryImageTag, ipgo.InventoryDBFile, tempDir)
+	if err != nil {
+		return err
+	}
+
+	if err := resolvePluginGroupManifestVersions(plugininventory.NewSQLiteInventory(dbFile, ""), pluginGroupManifest); err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(pluginGroupManifest)
+	if err != nil {
+		return errors.Wrap(err, "error while generating the plugin-group manifest")
+	}
+	if err := os.WriteFile(ipgo.OutputFile, data, 0644); err != nil {
+		return errors.Wrapf(err, "error while writing the plugin-group manifest %q", ipgo.OutputFile)
+	}
+	log.Infof("successfully generated plugin-group manifest at: %q", ipgo.OutputFile)
+	return nil
+}
+
+// renderTemplate substitutes the variables of the template and returns the resulting plugin-group manifest
+func (ipgo *InventoryPluginGroupGenerateOptions) renderTemplate() (*cli.PluginGroupManifest, error) {
+	content, err := os.ReadFile(ipgo.TemplateFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to read the plugin-group manifest template")
+	}
+	// Fail instead of generating an empty value if a variable is not specified
+	tmpl, err := template.New("plugin-group").Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid plugin-group manifest template")
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, ipgo.Variables); err != nil {
+		return nil, errors.Wrap(err, "error while substituting the variables of the plugin-group manifest template")
+	}
+
+	pluginGroupManifest := &cli.PluginGroupManifest{}
+	if err := yaml.Unmarshal(buf.Bytes(), pluginGroupManifest); err != nil {
+		return nil, errors.Wrap(err, "fail to read the plugin-group manifest template")
+	}
+	return pluginGroupManifest, nil
+}
+
+// resolvePluginGroupManifestVersions replaces the `latest` plugin versions of the manifest
+// with the latest versions found in the inventory and verifies all the plugins exist
+func resolvePluginGroupManifestVersions(db plugininventory.PluginInventory, pluginGroupManifest *cli.PluginGroupManifest) error {
+	// Allow including deactivated plugins if the TANZU_CLI_INCLUDE_DEACTIVATED_PLUGINS_TEST_ONLY is properly set
+	includeHidden, _ := strconv.ParseBool(os.Getenv(constants.ConfigVariableIncludeDeactivatedPluginsForTesting))
+
+	var errs []error
+	for i := range pluginGroupManifest.Plugins {
+		p := &pluginGroupManifest.Plugins[i]
+		p.Version = strings.TrimSpace(p.Version)
+		if p.Version == "" {
+			errs = append(errs, errors.Errorf("plugin version cannot be empty for plugin %q, target %q", p.Name, p.Target))
+			continue
+		}
+
+		plugins, err := db.GetPlugins(context.Background(), &plugininventory.PluginInventoryFilter{
+			Name:          p.Name,
+			Target:        types.Target(p.Target),
+			Version:       p.Version,
+			IncludeHidden: includeHidden,
+		})
+		if err != nil {
+			return errors.Wrap(err, "error while verifying existence of the plugin in the database")
+		}
+		if len(plugins) == 0 {
+			errs = append(errs, errors.Errorf("specified plugin 'name:%s', 'target:%s', 'version:%s' is not present in the database", p.Name, p.Target, p.Version))
+			continue
+		}
+		if p.Version == cli.VersionLatest {
+			p.Version = plugins[0].RecommendedVersion
+			log.Infof("using version %q of plugin 'name:%s', 'target:%s'", p.Version, p.Name, p.Target)
+		}
+	}
+	return kerrors.NewAggregate(errs)
+}
@@ -0,0 +1,107 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+
+	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/helpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+)
+
+const pluginGroupManifestTemplate = `plugins:
+- name: foo
+  target: kubernetes
+  version: latest
+- name: bar
+  target: global
+  isContextScoped: true
+  version: {{ .barVersion }}
+`
+
+var _ = Describe("Unit tests for inventory plugin-group generate", func() {
+	var dir string
+	var ipggo InventoryPluginGroupGenerateOptions
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		dbFile := filepath.Join(dir, plugininventory.SQliteDBFileName)
+		db := plugininventory.NewSQLiteInventory(dbFile, "")
+		Expect(db.CreateSchema(context.Background())).To(Succeed())
+		artifacts := []distribution.Artifact{{OS: "linux", Arch: "amd64", Digest: "fake-digest", Image: "fake-uri"}}
+		Expect(db.InsertPlugin(context.Background(), &plugininventory.PluginInventoryEntry{
+			Name:        "foo",
+			Target:      types.TargetK8s,
+			Description: "foo plugin",
+			Publisher:   "tkg",
+			Vendor:      "vmware",
+			Artifacts:   distribution.Artifacts{"v0.0.1": artifacts, "v0.0.2": artifacts},
+		})).To(Succeed())
+		Expect(db.InsertPlugin(context.Background(), &plugininventory.PluginInventoryEntry{
+			Name:        "bar",
+			Target:      types.TargetGlobal,
+			Description: "bar plugin",
+			Publisher:   "tkg",
+			Vendor:      "vmware",
+			Artifacts:   distribution.Artifacts{"v1.0.0": artifacts},
+		})).To(Succeed())
+
+		templateFile := filepath.Join(dir, "plugin_group_manifest.yaml.tmpl")
+		Expect(os.WriteFile(templateFile, []byte(pluginGroupManifestTemplate), 0644)).To(Succeed())
+
+		ipggo = InventoryPluginGroupGenerateOptions{
+			InventoryDBFile: dbFile,
+			TemplateFile:    templateFile,
+			Variables:       map[string]string{"barVersion": "v1.0.0"},
+			OutputFile:      filepath.Join(dir, "plugin_group_manifest.yaml"),
+		}
+	})
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	var _ = Context("tests for the plugin-group generate function", func() {
+		var _ = It("when the plugin-group manifest is generated", func() {
+			Expect(ipggo.GeneratePluginGroupManifest()).To(Succeed())
+
+			manifest, err := helpers.ReadPluginGroupManifest(ipggo.OutputFile)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(manifest.Plugins).To(HaveLen(2))
+			Expect(manifest.Plugins[0].Name).To(Equal("foo"))
+			Expect(manifest.Plugins[0].Version).To(Equal("v0.0.2"))
+			Expect(manifest.Plugins[1].Name).To(Equal("bar"))
+			Expect(manifest.Plugins[1].Version).To(Equal("v1.0.0"))
+			Expect(manifest.Plugins[1].IsContextScoped).To(BeTrue())
+		})
+
+		var _ = It("when a variable of the template is not specified", func() {
+			ipggo.Variables = nil
+
+			err := ipggo.GeneratePluginGroupManifest()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("error while substituting the variables of the plugin-group manifest template"))
+			Expect(ipggo.OutputFile).NotTo(BeAnExistingFile())
+		})
+
+		var _ = It("when a plugin of the plugin-group is not in the inventory database", func() {
+			ipggo.Variables["barVersion"] = "v2.0.0"
+
+			err := ipggo.GeneratePluginGroupManifest()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("specified plugin 'name:bar', 'target:global', 'version:v2.0.0' is not present in the database"))
+			Expect(ipggo.OutputFile).NotTo(BeAnExistingFile())
+		})
+	})
+})
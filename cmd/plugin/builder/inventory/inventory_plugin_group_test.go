@@ -4,6 +4,7 @@
 package inventory
 
 import (
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
@@ -30,7 +31,7 @@ var _ = Describe("Unit tests for inventory plugin-group add", func() {
 	pullDBImageStub := func(_, path string) error {
 		dbFile := filepath.Join(path, plugininventory.SQliteDBFileName)
 		db := plugininventory.NewSQLiteInventory(dbFile, "")
-		err := db.CreateSchema()
+		err := db.CreateSchema(context.Background())
 		Expect(err).ToNot(HaveOccurred())
 		referencedDBFile = dbFile
 		return nil
@@ -91,9 +92,9 @@ var _ = Describe("Unit tests for inventory plugin-group add", func() {
 			Hidden:      false,
 			Artifacts:   artifactsBar,
 		}
-		err = db.InsertPlugin(entryFoo)
+		err = db.InsertPlugin(context.Background(), entryFoo)
 		Expect(err).ToNot(HaveOccurred())
-		err = db.InsertPlugin(entryBar)
+		err = db.InsertPlugin(context.Background(), entryBar)
 		Expect(err).ToNot(HaveOccurred())
 		return nil
 	}
@@ -130,7 +131,7 @@ var _ = Describe("Unit tests for inventory plugin-group add", func() {
 				},
 			},
 		}
-		err = db.InsertPluginGroup(&pgEntry, false)
+		err = db.InsertPluginGroup(context.Background(), &pgEntry, false)
 		Expect(err).ToNot(HaveOccurred())
 		return nil
 	}
@@ -198,7 +199,7 @@ var _ = Describe("Unit tests for inventory plugin-group add", func() {
 
 			// verify that the local db file was updated correctly before publishing the database to remote repository
 			db := plugininventory.NewSQLiteInventory(referencedDBFile, "")
-			pgEntries, err := db.GetPluginGroups(plugininventory.PluginGroupFilter{})
+			pgEntries, err := db.GetPluginGroups(context.Background(), plugininventory.PluginGroupFilter{})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(pgEntries).NotTo(BeNil())
 			Expect(len(pgEntries)).To(Equal(1))
@@ -237,7 +238,7 @@ var _ = Describe("Unit tests for inventory plugin-group add", func() {
 
 			// verify that the local db file was updated correctly before publishing the database to remote repository
 			db := plugininventory.NewSQLiteInventory(referencedDBFile, "")
-			pgEntries, err := db.GetPluginGroups(plugininventory.PluginGroupFilter{})
+			pgEntries, err := db.GetPluginGroups(context.Background(), plugininventory.PluginGroupFilter{})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(pgEntries).NotTo(BeNil())
 			Expect(len(pgEntries)).To(Equal(1))
@@ -254,6 +255,42 @@ var _ = Describe("Unit tests for inventory plugin-group add", func() {
 			Expect(len(plugins)).To(Equal(2))
 		})
 
+		var _ = It("when specified plugin-group already exist in the inventory database with other plugins and skip-existing is provided, adding plugin group should throw error", func() {
+			fakeImgpkgWrapper.ResolveImageReturns(nil)
+			fakeImgpkgWrapper.PushImageReturns(nil)
+			fakeImgpkgWrapper.DownloadImageAndSaveFilesToDirCalls(pullDBImageStubWithPluginGroups)
+
+			ipgu.SkipExisting = true
+			err := ipgu.PluginGroupAdd()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("plugin group 'fakevendor-fakepublisher/default:v1.0.0' is already in the inventory database with different plugins"))
+		})
+
+		var _ = It("when specified plugin-group already exist in the inventory database with the same plugins and skip-existing is provided, adding plugin group should be skipped", func() {
+			fakeImgpkgWrapper.ResolveImageReturns(nil)
+			fakeImgpkgWrapper.PushImageReturns(nil)
+			fakeImgpkgWrapper.DownloadImageAndSaveFilesToDirCalls(pullDBImageStubWithPlugins)
+			Expect(ipgu.PluginGroupAdd()).To(Succeed())
+
+			publishedDBFile := filepath.Join(GinkgoT().TempDir(), plugininventory.SQliteDBFileName)
+			Expect(utils.CopyFile(referencedDBFile, publishedDBFile)).To(Succeed())
+			fakeImgpkgWrapper.DownloadImageAndSaveFilesToDirCalls(func(_, path string) error {
+				referencedDBFile = filepath.Join(path, plugininventory.SQliteDBFileName)
+				return utils.CopyFile(publishedDBFile, referencedDBFile)
+			})
+
+			ipgu.SkipExisting = true
+			ipgu.Description = "Updated desc for plugin"
+			Expect(ipgu.PluginGroupAdd()).To(Succeed())
+
+			db := plugininventory.NewSQLiteInventory(referencedDBFile, "")
+			pgEntries, err := db.GetPluginGroups(context.Background(), plugininventory.PluginGroupFilter{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(pgEntries)).To(Equal(1))
+			Expect(pgEntries[0].Description).To(Equal("Desc for plugin"))
+			Expect(len(pgEntries[0].Versions["v1.0.0"])).To(Equal(2))
+		})
+
 		var _ = It("when inventory database cannot be published from the repository", func() {
 			fakeImgpkgWrapper.ResolveImageReturns(nil)
 			fakeImgpkgWrapper.PushImageReturns(errors.New("unable to publish image"))
@@ -315,7 +352,7 @@ var _ = Describe("Unit tests for inventory plugin-group add", func() {
 
 			// verify that the local db file was updated correctly before publishing the database to remote repository
 			db := plugininventory.NewSQLiteInventory(referencedDBFile, "")
-			pgEntries, err := db.GetPluginGroups(plugininventory.PluginGroupFilter{IncludeHidden: false})
+			pgEntries, err := db.GetPluginGroups(context.Background(), plugininventory.PluginGroupFilter{IncludeHidden: false})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(pgEntries).NotTo(BeNil())
 			Expect(len(pgEntries)).To(Equal(1))
@@ -341,7 +378,7 @@ var _ = Describe("Unit tests for inventory plugin-group add", func() {
 
 			// verify that the local db file was updated correctly before publishing the database to remote repository
 			db := plugininventory.NewSQLiteInventory(referencedDBFile, "")
-			pgEntries, err := db.GetPluginGroups(plugininventory.PluginGroupFilter{})
+			pgEntries, err := db.GetPluginGroups(context.Background(), plugininventory.PluginGroupFilter{})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(pgEntries).NotTo(BeNil())
 			Expect(len(pgEntries)).To(Equal(1))
@@ -0,0 +1,296 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/artifact"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+)
+
+// krewPluginKind is the kind of the krew plugin manifests
+const krewPluginKind = "Plugin"
+
+// krewPlugin is a plugin manifest of a krew plugin index
+type krewPlugin struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Name string `yaml:"name"`
+	} `yaml:"metadata"`
+	Spec struct {
+		Version          string         `yaml:"version"`
+		ShortDescription string         `yaml:"shortDescription"`
+		Platforms        []krewPlatform `yaml:"platforms"`
+	} `yaml:"spec"`
+}
+
+// krewPlatform is the archive of a krew plugin for the platforms matching its selector
+type krewPlatform struct {
+	Selector struct {
+		MatchLabels map[string]string `yaml:"matchLabels"`
+	} `yaml:"selector"`
+	URI    string `yaml:"uri"`
+	Sha256 string `yaml:"sha256"`
+	Bin    string `yaml:"bin"`
+	Files  []struct {
+		From string `yaml:"from"`
+		To   string `yaml:"to"`
+	} `yaml:"files"`
+}
+
+// InventoryPluginImportKrewOptions defines options for importing the plugins of krew plugin index
+// manifests to the inventory database
+type InventoryPluginImportKrewOptions struct {
+	Repository        string
+	InventoryImageTag string
+	// KrewManifests is a krew plugin manifest file or a directory of krew plugin
+	// manifests, e.g. the `plugins` directory of a krew plugin index
+	KrewManifests     string
+	Target            string
+	Publisher         string
+	Vendor            string
+	InventoryDBFile   string
+	DeactivatePlugins bool
+
+	ImageOperationsImpl carvelhelpers.ImageOperationsImpl
+}
+
+// ImportKrewPlugins publishes the plugin binaries of the krew plugin manifests to the repository as plugin
+// images and adds the plugins to the inventory database. The archive of each platform supported by the
+// CLI is downloaded and verified against the digest of the manifest, then the plugin binary is extracted
+// from the archive and published the same way as the binaries of the plugins built by the builder plugin.
+func (iko *InventoryPluginImportKrewOptions) ImportKrewPlugins() error {
+	if !configtypes.IsValidTarget(iko.Target, true, false) {
+		return errors.Errorf("invalid target %q for the krew plugins", iko.Target)
+	}
+	iko.Target = string(configtypes.StringToTarget(iko.Target))
+
+	krewPlugins, err := readKrewManifests(iko.KrewManifests)
+	if err != nil {
+		return err
+	}
+
+	var pluginInventoryEntries []*plugininventory.PluginInventoryEntry
+	for i := range krewPlugins {
+		entry, err := iko.importKrewPlugin(krewPlugins[i])
+		if err != nil {
+			return errors.Wrapf(err, "error while importing krew plugin '%s'", krewPlugins[i].Metadata.Name)
+		}
+		pluginInventoryEntries = append(pluginInventoryEntries, entry)
+	}
+
+	// The inventory database is read and published the same way as when adding plugins from a plugin manifest
+	ipuo := &InventoryPluginUpdateOptions{
+		Repository:          iko.Repository,
+		InventoryImageTag:   iko.InventoryImageTag,
+		InventoryDBFile:     iko.InventoryDBFile,
+		ImageOperationsImpl: iko.ImageOperationsImpl,
+	}
+	dbFile, err := ipuo.getInventoryDBFile()
+	if err != nil {
+		return err
+	}
+	if err := plugininventory.NewSQLiteInventory(dbFile, "").InsertPlugins(context.Background(), pluginInventoryEntries); err != nil {
+		return errors.Wrap(err, "error while inserting the krew plugins")
+	}
+	return ipuo.putInventoryDBFile(dbFile)
+}
+
+// readKrewManifests reads the krew plugin manifest file, or all the krew plugin manifests of the directory
+func readKrewManifests(krewManifests string) ([]*krewPlugin, error) {
+	fi, err := os.Stat(krewManifests)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to read the krew plugin manifests")
+	}
+	files := []string{krewManifests}
+	if fi.IsDir() {
+		files, err = filepath.Glob(filepath.Join(krewManifests, "*.yaml"))
+		if err != nil {
+			return nil, errors.Wrap(err, "fail to read the krew plugin manifests")
+		}
+	}
+
+	var krewPlugins []*krewPlugin
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrap(err, "fail to read the krew plugin manifest file")
+		}
+		p := &krewPlugin{}
+		if err := yaml.Unmarshal(data, p); err != nil {
+			return nil, errors.Wrapf(err, "fail to read the krew plugin manifest file %q", file)
+		}
+		if p.Kind != krewPluginKind || p.Metadata.Name == "" || p.Spec.Version == "" {
+			return nil, errors.Errorf("%q is not a valid krew plugin manifest", file)
+		}
+		krewPlugins = append(krewPlugins, p)
+	}
+	if len(krewPlugins) == 0 {
+		return nil, errors.Errorf("no krew plugin manifest found in %q", krewManifests)
+	}
+	return krewPlugins, nil
+}
+
+// importKrewPlugin publishes the binaries of the krew plugin for all the platforms supported by
+// the CLI and returns the plugin inventory entry referring to them
+func (iko *InventoryPluginImportKrewOptions) importKrewPlugin(p *krewPlugin) (*plugininventory.PluginInventoryEntry, error) {
+	var artifacts distribution.ArtifactList
+	for _, osArch := range cli.AllOSArch {
+		platform := findKrewPlatform(p.Spec.Platforms, osArch)
+		if platform == nil {
+			log.Infof("skipping unavailable krew plugin '%s' for os/arch: %s", p.Metadata.Name, osArch.String())
+			continue
+		}
+		a, err := iko.publishKrewPluginBinary(p, platform, osArch)
+		if err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, *a)
+	}
+	if len(artifacts) == 0 {
+		return nil, errors.New("the plugin is not available for any platform supported by the CLI")
+	}
+
+	return &plugininventory.PluginInventoryEntry{
+		Name:        p.Metadata.Name,
+		Target:      configtypes.Target(iko.Target),
+		Description: p.Spec.ShortDescription,
+		Publisher:   iko.Publisher,
+		Vendor:      iko.Vendor,
+		Artifacts:   distribution.Artifacts{p.Spec.Version: artifacts},
+		Hidden:      iko.DeactivatePlugins,
+	}, nil
+}
+
+// findKrewPlatform returns the first platform of the krew plugin whose selector matches the os/arch
+func findKrewPlatform(platforms []krewPlatform, osArch cli.Arch) *krewPlatform {
+	for i := range platforms {
+		labels := platforms[i].Selector.MatchLabels
+		if (labels["os"] == "" || labels["os"] == osArch.OS()) && (labels["arch"] == "" || labels["arch"] == osArch.Arch()) {
+			return &platforms[i]
+		}
+	}
+	return nil
+}
+
+// publishKrewPluginBinary downloads and verifies the archive of the krew plugin platform, then publishes the
+// plugin binary it contains as the plugin image of the os/arch and returns the corresponding artifact
+func (iko *InventoryPluginImportKrewOptions) publishKrewPluginBinary(p *krewPlugin, platform *krewPlatform, osArch cli.Arch) (*distribution.Artifact, error) {
+	uriArtifact, err := artifact.NewURIArtifact(platform.URI)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := uriArtifact.Fetch()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error while downloading the archive %q", platform.URI)
+	}
+	if digest := fmt.Sprintf("%x", sha256.Sum256(archive)); !strings.EqualFold(digest, platform.Sha256) {
+		return nil, errors.Errorf("the digest of the archive %q is %q instead of %q", platform.URI, digest, platform.Sha256)
+	}
+	binary, err := extractKrewPluginBinary(archive, platform)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error while extracting %q from the archive %q", platform.Bin, platform.URI)
+	}
+
+	tempDir, err := os.MkdirTemp("", "")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create temporary directory")
+	}
+	defer os.RemoveAll(tempDir)
+	binaryFile := filepath.Join(tempDir, cli.MakeArtifactName(p.Metadata.Name, osArch))
+	if err := os.WriteFile(binaryFile, binary, 0755); err != nil {
+		return nil, err
+	}
+
+	pluginImageBasePath := fmt.Sprintf("%s/%s/%s/%s/%s/%s:%s", iko.Vendor, iko.Publisher, osArch.OS(), osArch.Arch(), iko.Target, p.Metadata.Name, p.Spec.Version)
+	pluginImage := fmt.Sprintf("%s/%s", iko.Repository, pluginImageBasePath)
+	log.Infof("publishing krew plugin 'name:%s' 'os:%s' 'arch:%s' 'version:%s' at '%s'", p.Metadata.Name, osArch.OS(), osArch.Arch(), p.Spec.Version, pluginImage)
+	if err := iko.ImageOperationsImpl.PushImage(pluginImage, []string{binaryFile}); err != nil {
+		return nil, errors.Wrapf(err, "error while publishing the plugin image %q", pluginImage)
+	}
+
+	return &distribution.Artifact{
+		OS:     osArch.OS(),
+		Arch:   osArch.Arch(),
+		Digest: fmt.Sprintf("%x", sha256.Sum256(binary)),
+		Image:  pluginImageBasePath,
+	}, nil
+}
+
+// extractKrewPluginBinary returns the plugin binary of the tar.gz or zip archive of the krew plugin platform.
+// The binary is the file installed as the `bin` of the platform according to its `files` operations, or the
+// file at the `bin` path of the archive if the platform does not specify any.
+func extractKrewPluginBinary(archive []byte, platform *krewPlatform) ([]byte, error) {
+	isBinary := func(name string) bool {
+		name = path.Clean(strings.TrimPrefix(name, "./"))
+		if len(platform.Files) == 0 {
+			return name == path.Clean(platform.Bin)
+		}
+		for _, f := range platform.Files {
+			if matched, _ := path.Match(path.Clean(f.From), name); matched && path.Join(f.To, path.Base(name)) == path.Clean(platform.Bin) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if bytes.HasPrefix(archive, []byte("PK\x03\x04")) {
+		zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() || !isBinary(f.Name) {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			return io.ReadAll(rc)
+		}
+		return nil, errors.New("file not found in the archive")
+	}
+
+	gzr, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, errors.Wrap(err, "the archive is neither a tar.gz nor a zip archive")
+	}
+	defer gzr.Close()
+	tr := tar.NewReader(gzr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, errors.New("file not found in the archive")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag == tar.TypeReg && isBinary(hdr.Name) {
+			return io.ReadAll(tr)
+		}
+	}
+}
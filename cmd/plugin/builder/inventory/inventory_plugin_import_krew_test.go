@@ -0,0 +1,211 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/fakes"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+)
+
+const krewManifestTemplate = `apiVersion: krew.googlecontainertools.github.com/v1alpha2
+kind: Plugin
+metadata:
+  name: ctx
+spec:
+  version: v0.9.5
+  shortDescription: Switch between contexts in your kubeconfig
+  platforms:
+  - selector:
+      matchLabels:
+        os: linux
+        arch: amd64
+    uri: %s
+    sha256: %s
+    bin: kubectx
+    files:
+    - from: kubectx-*/kubectx
+      to: .
+  - selector:
+      matchLabels:
+        os: darwin
+    uri: %s
+    sha256: %s
+    bin: kubectx
+    files:
+    - from: kubectx-*/kubectx
+      to: .
+  - selector:
+      matchLabels:
+        os: windows
+        arch: amd64
+    uri: %s
+    sha256: %s
+    bin: kubectx.exe
+`
+
+// createKrewTarGzArchive creates a tar.gz archive with the files and returns its path and digest
+func createKrewTarGzArchive(dir string, files map[string]string) (string, string) {
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	for name, content := range files {
+		Expect(tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg})).To(Succeed())
+		_, err := tw.Write([]byte(content))
+		Expect(err).ToNot(HaveOccurred())
+	}
+	Expect(tw.Close()).To(Succeed())
+	Expect(gzw.Close()).To(Succeed())
+	return writeKrewArchive(filepath.Join(dir, "ctx.tar.gz"), buf.Bytes())
+}
+
+// createKrewZipArchive creates a zip archive with the files and returns its path and digest
+func createKrewZipArchive(dir string, files map[string]string) (string, string) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = w.Write([]byte(content))
+		Expect(err).ToNot(HaveOccurred())
+	}
+	Expect(zw.Close()).To(Succeed())
+	return writeKrewArchive(filepath.Join(dir, "ctx.zip"), buf.Bytes())
+}
+
+func writeKrewArchive(path string, b []byte) (string, string) {
+	Expect(os.WriteFile(path, b, 0644)).To(Succeed())
+	return path, fmt.Sprintf("%x", sha256.Sum256(b))
+}
+
+var _ = Describe("Unit tests for inventory plugin import-krew", func() {
+	var (
+		tmpDir            string
+		dbFile            string
+		manifestFile      string
+		fakeImgpkgWrapper *fakes.ImageOperationsImpl
+		iko               InventoryPluginImportKrewOptions
+		tarPath, tarSum   string
+		zipPath, zipSum   string
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "")
+		Expect(err).ToNot(HaveOccurred())
+
+		dbFile = filepath.Join(tmpDir, plugininventory.SQliteDBFileName)
+		Expect(plugininventory.NewSQLiteInventory(dbFile, "").CreateSchema(context.Background())).To(Succeed())
+
+		tarPath, tarSum = createKrewTarGzArchive(tmpDir, map[string]string{"kubectx-0.9.5/kubectx": "unix binary", "kubectx-0.9.5/LICENSE": "license"})
+		zipPath, zipSum = createKrewZipArchive(tmpDir, map[string]string{"kubectx.exe": "windows binary", "LICENSE": "license"})
+		manifestFile = filepath.Join(tmpDir, "ctx.yaml")
+		Expect(os.WriteFile(manifestFile, []byte(fmt.Sprintf(krewManifestTemplate, tarPath, tarSum, tarPath, tarSum, zipPath, zipSum)), 0644)).To(Succeed())
+
+		fakeImgpkgWrapper = &fakes.ImageOperationsImpl{}
+		iko = InventoryPluginImportKrewOptions{
+			Repository:          "test-repo.com",
+			InventoryImageTag:   "latest",
+			KrewManifests:       tmpDir,
+			Target:              "kubernetes",
+			Vendor:              "fakevendor",
+			Publisher:           "fakepublisher",
+			InventoryDBFile:     dbFile,
+			ImageOperationsImpl: fakeImgpkgWrapper,
+		}
+	})
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	It("imports the plugin for all the platforms of the krew manifests", func() {
+		err := iko.ImportKrewPlugins()
+		Expect(err).NotTo(HaveOccurred())
+
+		// linux/amd64, darwin/amd64, darwin/arm64 and windows/amd64
+		Expect(fakeImgpkgWrapper.PushImageCallCount()).To(Equal(4))
+		image, files := fakeImgpkgWrapper.PushImageArgsForCall(0)
+		Expect(image).To(Equal("test-repo.com/fakevendor/fakepublisher/linux/amd64/kubernetes/ctx:v0.9.5"))
+		Expect(files).To(HaveLen(1))
+		Expect(filepath.Base(files[0])).To(Equal("tanzu-ctx-linux_amd64"))
+
+		entries, err := plugininventory.NewSQLiteInventory(dbFile, "").GetAllPlugins(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Name).To(Equal("ctx"))
+		Expect(entries[0].Target).To(Equal(types.TargetK8s))
+		Expect(entries[0].Description).To(Equal("Switch between contexts in your kubeconfig"))
+		Expect(entries[0].Vendor).To(Equal("fakevendor"))
+		Expect(entries[0].Publisher).To(Equal("fakepublisher"))
+
+		artifacts := entries[0].Artifacts["v0.9.5"]
+		Expect(artifacts).To(HaveLen(4))
+		for _, a := range artifacts {
+			expectedBinary := "unix binary"
+			if a.OS == "windows" {
+				expectedBinary = "windows binary"
+			}
+			Expect(a.Digest).To(Equal(fmt.Sprintf("%x", sha256.Sum256([]byte(expectedBinary)))))
+			Expect(a.Image).To(HaveSuffix(fmt.Sprintf("fakevendor/fakepublisher/%s/%s/kubernetes/ctx:v0.9.5", a.OS, a.Arch)))
+		}
+	})
+
+	It("fails when the digest of an archive does not match the krew manifest", func() {
+		Expect(os.WriteFile(manifestFile, []byte(fmt.Sprintf(krewManifestTemplate, tarPath, tarSum, tarPath, tarSum, zipPath, tarSum)), 0644)).To(Succeed())
+
+		err := iko.ImportKrewPlugins()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("error while importing krew plugin 'ctx'"))
+		Expect(err.Error()).To(ContainSubstring(fmt.Sprintf("the digest of the archive %q is %q instead of %q", zipPath, zipSum, tarSum)))
+	})
+
+	It("fails when the binary is not in the archive", func() {
+		tarPath, tarSum = createKrewTarGzArchive(tmpDir, map[string]string{"LICENSE": "license"})
+		Expect(os.WriteFile(manifestFile, []byte(fmt.Sprintf(krewManifestTemplate, tarPath, tarSum, tarPath, tarSum, zipPath, zipSum)), 0644)).To(Succeed())
+
+		err := iko.ImportKrewPlugins()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("error while extracting \"kubectx\""))
+		Expect(err.Error()).To(ContainSubstring("file not found in the archive"))
+	})
+
+	It("fails when publishing a plugin image fails", func() {
+		fakeImgpkgWrapper.PushImageReturns(fmt.Errorf("push failed"))
+
+		err := iko.ImportKrewPlugins()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("error while publishing the plugin image"))
+		Expect(err.Error()).To(ContainSubstring("push failed"))
+	})
+
+	It("fails when the file is not a krew plugin manifest", func() {
+		Expect(os.WriteFile(manifestFile, []byte("plugins:\n- name: foo\n"), 0644)).To(Succeed())
+
+		err := iko.ImportKrewPlugins()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("is not a valid krew plugin manifest"))
+	})
+
+	It("fails when the target is invalid", func() {
+		iko.Target = "invalid"
+
+		err := iko.ImportKrewPlugins()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(`invalid target "invalid" for the krew plugins`))
+	})
+})
@@ -4,15 +4,19 @@
 package inventory
 
 import (
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
 
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/fakes"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
@@ -40,7 +44,7 @@ var _ = Describe("Unit tests for inventory plugin add", func() {
 	pullDBImageStub := func(_, path string) error {
 		dbFile := filepath.Join(path, plugininventory.SQliteDBFileName)
 		db := plugininventory.NewSQLiteInventory(dbFile, "")
-		err := db.CreateSchema()
+		err := db.CreateSchema(context.Background())
 		Expect(err).ToNot(HaveOccurred())
 		referencedDBFile = dbFile
 		return nil
@@ -51,7 +55,7 @@ var _ = Describe("Unit tests for inventory plugin add", func() {
 	pullDBImageStubWithPlugins := func(_, path string) error {
 		dbFile := filepath.Join(path, plugininventory.SQliteDBFileName)
 		db := plugininventory.NewSQLiteInventory(dbFile, "")
-		err := db.CreateSchema()
+		err := db.CreateSchema(context.Background())
 		Expect(err).ToNot(HaveOccurred())
 		artifacts := make(map[string]distribution.ArtifactList)
 		artifacts["v0.0.2"] = []distribution.Artifact{
@@ -77,7 +81,7 @@ var _ = Describe("Unit tests for inventory plugin add", func() {
 			Hidden:      false,
 			Artifacts:   artifacts,
 		}
-		err = db.InsertPlugin(entry)
+		err = db.InsertPlugin(context.Background(), entry)
 		Expect(err).ToNot(HaveOccurred())
 		referencedDBFile = dbFile
 		return nil
@@ -133,7 +137,7 @@ var _ = Describe("Unit tests for inventory plugin add", func() {
 
 			// verify that the local db file was updated before publishing the database to remote repository
 			db := plugininventory.NewSQLiteInventory(referencedDBFile, "")
-			pluginInventoryEntries, err := db.GetAllPlugins()
+			pluginInventoryEntries, err := db.GetAllPlugins(context.Background())
 			Expect(err).NotTo(HaveOccurred())
 			Expect(pluginInventoryEntries).NotTo(BeNil())
 			Expect(len(pluginInventoryEntries)).To(Equal(1))
@@ -144,6 +148,15 @@ var _ = Describe("Unit tests for inventory plugin add", func() {
 			Expect(pluginInventoryEntries[0].Publisher).To(Equal("fakepublisher"))
 			Expect(pluginInventoryEntries[0].Vendor).To(Equal("fakevendor"))
 			Expect(pluginInventoryEntries[0].Artifacts["v0.0.2"]).NotTo(BeNil())
+			Expect(pluginInventoryEntries[0].ReleaseNotes["v0.0.2"].ChangelogURL).To(Equal("https://example.com/foo/v0.0.2"))
+			Expect(pluginInventoryEntries[0].ReleaseNotes["v0.0.2"].Notes).To(Equal("Add the bar command"))
+			Expect(pluginInventoryEntries[0].MinCLIVersions).To(Equal(map[string]string{"v0.0.2": "v1.1.0"}))
+			Expect(pluginInventoryEntries[0].Licenses["v0.0.2"].License).To(Equal("Apache-2.0"))
+			Expect(pluginInventoryEntries[0].Licenses["v0.0.2"].SBOMURI).To(Equal("https://example.com/foo/v0.0.2/sbom.spdx.json"))
+			Expect(pluginInventoryEntries[0].ReleasedAt["v0.0.2"]).To(BeTemporally("~", time.Now(), time.Minute))
+			Expect(pluginInventoryEntries[0].Deprecated).To(BeTrue())
+			Expect(pluginInventoryEntries[0].DeprecationMessage).To(Equal("Use the bar plugin"))
+			Expect(pluginInventoryEntries[0].ReplacedBy).To(Equal("bar"))
 		})
 
 		var _ = It("when all configuration are correct and inserting plugin with DeactivatePlugins=true", func() {
@@ -158,7 +171,7 @@ var _ = Describe("Unit tests for inventory plugin add", func() {
 
 			// verify that the local db file was updated before publishing the database to remote repository
 			db := plugininventory.NewSQLiteInventory(referencedDBFile, "")
-			pluginInventoryEntries, err := db.GetPlugins(&plugininventory.PluginInventoryFilter{IncludeHidden: true})
+			pluginInventoryEntries, err := db.GetPlugins(context.Background(), &plugininventory.PluginInventoryFilter{IncludeHidden: true})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(pluginInventoryEntries).NotTo(BeNil())
 			Expect(len(pluginInventoryEntries)).To(Equal(1))
@@ -168,6 +181,77 @@ var _ = Describe("Unit tests for inventory plugin add", func() {
 			Expect(pluginInventoryEntries[0].Hidden).To(Equal(true))
 			Expect(pluginInventoryEntries[0].Artifacts["v0.0.2"]).NotTo(BeNil())
 		})
+		var _ = It("when inserting plugins published as multi-arch image indexes", func() {
+			fakeImgpkgWrapper.ResolveImageReturns(nil)
+			fakeImgpkgWrapper.PushImageReturns(nil)
+			fakeImgpkgWrapper.DownloadImageAndSaveFilesToDirCalls(pullDBImageStub)
+			fakeImgpkgWrapper.GetFileDigestFromImageCalls(func(image, fileName string) (string, error) {
+				return "digest-" + fileName, nil
+			})
+			getImageIndexPlatformImages = func(indexImage string) ([]carvelhelpers.PlatformImage, error) {
+				repo := strings.TrimSuffix(indexImage, ":v0.0.2")
+				return []carvelhelpers.PlatformImage{
+					{Image: repo + "@sha256:1111", OS: "linux", Arch: "amd64"},
+					{Image: repo + "@sha256:2222", OS: "darwin", Arch: "arm64"},
+				}, nil
+			}
+			defer func() { getImageIndexPlatformImages = carvelhelpers.GetImageIndexPlatformImages }()
+
+			iip.DeactivatePlugins = false
+			iip.MultiArch = true
+			defer func() { iip.MultiArch = false }()
+			err := iip.PluginAdd()
+			Expect(err).NotTo(HaveOccurred())
+
+			db := plugininventory.NewSQLiteInventory(referencedDBFile, "")
+			pluginInventoryEntries, err := db.GetAllPlugins(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(pluginInventoryEntries)).To(Equal(1))
+			artifacts := pluginInventoryEntries[0].Artifacts["v0.0.2"]
+			Expect(artifacts).To(HaveLen(3))
+			for _, a := range artifacts {
+				switch a.OS {
+				case distribution.ArtifactPlatformMultiArch:
+					Expect(a.IsMultiArch()).To(BeTrue())
+					Expect(a.Image).To(HaveSuffix("fakevendor/fakepublisher/global/foo:v0.0.2"))
+					Expect(a.Digest).To(BeEmpty())
+				case "linux":
+					Expect(a.Image).To(HaveSuffix("fakevendor/fakepublisher/global/foo@sha256:1111"))
+					Expect(a.Digest).To(Equal("digest-tanzu-foo-linux_amd64"))
+				case "darwin":
+					Expect(a.Image).To(HaveSuffix("fakevendor/fakepublisher/global/foo@sha256:2222"))
+					Expect(a.Digest).To(Equal("digest-tanzu-foo-darwin_arm64"))
+				default:
+					Fail("unexpected artifact for " + a.OS)
+				}
+			}
+		})
+
+		var _ = It("when the platforms of the multi-arch image index of a plugin cannot be read", func() {
+			fakeImgpkgWrapper.ResolveImageReturns(nil)
+			fakeImgpkgWrapper.DownloadImageAndSaveFilesToDirCalls(pullDBImageStub)
+			getImageIndexPlatformImages = func(indexImage string) ([]carvelhelpers.PlatformImage, error) {
+				return nil, errors.New("unable to fetch image index")
+			}
+			defer func() { getImageIndexPlatformImages = carvelhelpers.GetImageIndexPlatformImages }()
+
+			iip.MultiArch = true
+			defer func() { iip.MultiArch = false }()
+			err := iip.PluginAdd()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("error while reading the platforms of the plugin image index"))
+		})
+
+		var _ = It("when the multi-arch image index of a plugin does not exist", func() {
+			fakeImgpkgWrapper.ResolveImageReturns(errors.New("image index not found"))
+			fakeImgpkgWrapper.DownloadImageAndSaveFilesToDirCalls(pullDBImageStub)
+
+			iip.MultiArch = true
+			defer func() { iip.MultiArch = false }()
+			err := iip.PluginAdd()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("error while resolving the plugin image index"))
+		})
 	})
 
 	var _ = Context("tests for the inventory plugin UpdatePluginActivationState function", func() {
@@ -196,7 +280,7 @@ var _ = Describe("Unit tests for inventory plugin add", func() {
 
 			// verify that the local db file was updated before publishing the database to remote repository
 			db := plugininventory.NewSQLiteInventory(referencedDBFile, "")
-			pluginInventoryEntries, err := db.GetAllPlugins()
+			pluginInventoryEntries, err := db.GetAllPlugins(context.Background())
 			Expect(err).NotTo(HaveOccurred())
 			Expect(pluginInventoryEntries).NotTo(BeNil())
 			Expect(len(pluginInventoryEntries)).To(Equal(1))
@@ -219,7 +303,7 @@ var _ = Describe("Unit tests for inventory plugin add", func() {
 
 			// verify that the local db file was updated before publishing the database to remote repository
 			db := plugininventory.NewSQLiteInventory(referencedDBFile, "")
-			pluginInventoryEntries, err := db.GetPlugins(&plugininventory.PluginInventoryFilter{IncludeHidden: true})
+			pluginInventoryEntries, err := db.GetPlugins(context.Background(), &plugininventory.PluginInventoryFilter{IncludeHidden: true})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(pluginInventoryEntries).NotTo(BeNil())
 			Expect(len(pluginInventoryEntries)).To(Equal(1))
@@ -240,6 +324,19 @@ plugins:
       description: Foo plugin
       versions:
         - v0.0.2
+      releaseNotes:
+        v0.0.2:
+          changelogURL: https://example.com/foo/v0.0.2
+          notes: Add the bar command
+      minCLIVersions:
+        v0.0.2: v1.1.0
+      licenses:
+        v0.0.2:
+          license: Apache-2.0
+          sbomURI: https://example.com/foo/v0.0.2/sbom.spdx.json
+      deprecated: true
+      deprecationMessage: Use the bar plugin
+      replacedBy: bar
 `
 	tempManifestFile := filepath.Join(os.TempDir(), "plugin_manifets.yaml")
 	return filepath.Join(os.TempDir(), "plugin_manifets.yaml"), utils.SaveFile(tempManifestFile, []byte(manifestBytes))
@@ -0,0 +1,62 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/helpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cosignhelper"
+)
+
+// InventorySignOptions defines options for signing the inventory database image
+type InventorySignOptions struct {
+	Repository        string
+	InventoryImageTag string
+
+	CosignSigner        cosignhelper.CosignSigner
+	ImageOperationsImpl carvelhelpers.ImageOperationsImpl
+}
+
+// SignInventory signs the inventory database image available on the repository and
+// publishes the signature to the repository, so that the CLI can verify the image.
+// The image holding the chunks of the inventory database, if any, is signed as well.
+func (iso *InventorySignOptions) SignInventory() error {
+	pluginInventoryDBImage := fmt.Sprintf("%s/%s:%s", iso.Repository, helpers.PluginInventoryDBImageName, iso.InventoryImageTag)
+	images := []string{pluginInventoryDBImage}
+	if chunksImage := iso.getInventoryDBChunksImage(pluginInventoryDBImage); chunksImage != "" {
+		images = append(images, chunksImage)
+	}
+
+	log.Infof("signing plugin inventory database image: %q", pluginInventoryDBImage)
+	err := iso.CosignSigner.Sign(context.Background(), images)
+	if err != nil {
+		return errors.Wrapf(err, "error while signing the plugin inventory database image: %q", pluginInventoryDBImage)
+	}
+	log.Infof("successfully signed plugin inventory database image")
+	return nil
+}
+
+// getInventoryDBChunksImage returns the image holding the chunks of the inventory database
+// published as the image, or an empty string if the chunks were not published
+func (iso *InventorySignOptions) getInventoryDBChunksImage(pluginInventoryDBImage string) string {
+	if iso.ImageOperationsImpl == nil {
+		return ""
+	}
+	algorithm, hex, err := iso.ImageOperationsImpl.GetImageDigest(pluginInventoryDBImage)
+	if err != nil || hex == "" {
+		return ""
+	}
+	chunksImage, err := carvelhelpers.ChunksImageForImage(carvelhelpers.ImageWithDigest(pluginInventoryDBImage, algorithm, hex))
+	if err != nil || iso.ImageOperationsImpl.ResolveImage(chunksImage) != nil {
+		return ""
+	}
+	return chunksImage
+}
@@ -0,0 +1,57 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/fakes"
+)
+
+var _ = Describe("Unit tests for inventory sign", func() {
+	fakeCosignSigner := &fakes.CosignSignerFake{}
+	iso := InventorySignOptions{
+		Repository:        "test-repo.com",
+		InventoryImageTag: "latest",
+		CosignSigner:      fakeCosignSigner,
+	}
+
+	var _ = Context("tests for the inventory sign function", func() {
+		var _ = It("when the inventory database image is signed", func() {
+			fakeCosignSigner.SignReturns(nil)
+
+			err := iso.SignInventory()
+			Expect(err).NotTo(HaveOccurred())
+			_, images := fakeCosignSigner.SignArgsForCall(fakeCosignSigner.SignCallCount() - 1)
+			Expect(images).To(Equal([]string{"test-repo.com/plugin-inventory:latest"}))
+		})
+
+		var _ = It("when the chunks of the inventory database are published, their image is signed as well", func() {
+			fakeCosignSigner.SignReturns(nil)
+			fakeImageOperations := &fakes.ImageOperationsImpl{}
+			fakeImageOperations.GetImageDigestReturns("sha256", "1234", nil)
+			fakeImageOperations.ResolveImageReturns(nil)
+			isoWithChunks := iso
+			isoWithChunks.ImageOperationsImpl = fakeImageOperations
+
+			err := isoWithChunks.SignInventory()
+			Expect(err).NotTo(HaveOccurred())
+			_, images := fakeCosignSigner.SignArgsForCall(fakeCosignSigner.SignCallCount() - 1)
+			Expect(images).To(Equal([]string{"test-repo.com/plugin-inventory:latest", "test-repo.com/plugin-inventory-chunks:sha256-1234"}))
+			Expect(fakeImageOperations.ResolveImageArgsForCall(0)).To(Equal("test-repo.com/plugin-inventory-chunks:sha256-1234"))
+		})
+
+		var _ = It("when signing the inventory database image fails", func() {
+			fakeCosignSigner.SignReturns(errors.New("invalid private key"))
+
+			err := iso.SignInventory()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid private key"))
+			Expect(err.Error()).To(ContainSubstring("error while signing the plugin inventory database image"))
+		})
+	})
+})
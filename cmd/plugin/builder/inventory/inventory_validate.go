@@ -0,0 +1,162 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+)
+
+// InventoryValidateOptions defines options for validating the entries of the inventory database
+type InventoryValidateOptions struct {
+	Repository        string
+	InventoryImageTag string
+	InventoryDBFile   string
+
+	ImageOperationsImpl carvelhelpers.ImageOperationsImpl
+}
+
+// ValidateInventory validates the entries of the inventory database available on the repository
+// or of the local inventory database file and returns all the problems found
+func (ivo *InventoryValidateOptions) ValidateInventory() error {
+	tempDir, err := os.MkdirTemp("", "")
+	if err != nil {
+		return errors.Wrap(err, "unable to create temporary directory")
+	}
+	defer os.RemoveAll(tempDir)
+
+	dbFile, err := getInventoryDBFileToRead(ivo.ImageOperationsImpl, ivo.Repository, ivo.InventoryImageTag, ivo.InventoryDBFile, tempDir)
+	if err != nil {
+		return err
+	}
+
+	db := plugininventory.NewSQLiteInventory(dbFile, "")
+	plugins, err := db.GetPlugins(context.Background(), &plugininventory.PluginInventoryFilter{IncludeHidden: true})
+	if err != nil {
+		return errors.Wrap(err, "error while reading the plugins of the inventory database")
+	}
+	groups, err := db.GetPluginGroups(context.Background(), plugininventory.PluginGroupFilter{IncludeHidden: true})
+	if err != nil {
+		return errors.Wrap(err, "error while reading the plugin groups of the inventory database")
+	}
+
+	var errs []error
+	for _, p := range plugins {
+		errs = append(errs, validatePluginEntry(p)...)
+	}
+	for _, pg := range groups {
+		errs = append(errs, validatePluginGroup(pg, plugins)...)
+	}
+	if len(errs) > 0 {
+		return errors.Wrap(kerrors.NewAggregate(errs), "invalid plugin inventory database")
+	}
+
+	log.Infof("successfully validated %d plugins and %d plugin groups", len(plugins), len(groups))
+	return nil
+}
+
+// validatePluginEntry returns the problems found in the plugin entry
+func validatePluginEntry(p *plugininventory.PluginInventoryEntry) []error {
+	var errs []error
+	id := fmt.Sprintf("plugin '%s_%s'", p.Name, p.Target)
+	if !configtypes.IsValidTarget(string(p.Target), true, false) {
+		errs = append(errs, errors.Errorf("%s: invalid target %q", id, p.Target))
+	}
+	if p.RecommendedVersion != "" {
+		if _, exists := p.Artifacts[p.RecommendedVersion]; !exists {
+			errs = append(errs, errors.Errorf("%s: the recommended version %q is not available", id, p.RecommendedVersion))
+		}
+	}
+	for version, artifacts := range p.Artifacts {
+		if _, err := semver.NewVersion(version); err != nil {
+			errs = append(errs, errors.Errorf("%s: invalid version %q", id, version))
+		}
+		for i := range artifacts {
+			if err := validateArtifact(&artifacts[i]); err != nil {
+				errs = append(errs, errors.Wrapf(err, "%s: version %q", id, version))
+			}
+		}
+	}
+	return errs
+}
+
+// validateArtifact returns an error if the artifact cannot be installed
+func validateArtifact(a *distribution.Artifact) error {
+	// The images are stored relative to the repository of the inventory so an
+	// artifact without image is read as the repository path followed by a slash
+	if (a.Image == "" || strings.HasSuffix(a.Image, "/")) && a.URI == "" {
+		return errors.Errorf("the artifact for %s/%s has no image nor URI", a.OS, a.Arch)
+	}
+	if a.IsMultiArch() {
+		return nil
+	}
+	if !isKnownPlatform(a.OS, a.Arch) {
+		return errors.Errorf("the artifact has an unknown platform %s/%s", a.OS, a.Arch)
+	}
+	if a.Digest == "" {
+		return errors.Errorf("the artifact for %s/%s has no digest", a.OS, a.Arch)
+	}
+	return nil
+}
+
+// isKnownPlatform returns true if plugins can be built for the OS and architecture
+func isKnownPlatform(os, arch string) bool {
+	for _, osArch := range cli.AllOSArch {
+		if osArch.OS() == os && osArch.Arch() == arch {
+			return true
+		}
+	}
+	return false
+}
+
+// validatePluginGroup returns the problems found in the plugin group
+func validatePluginGroup(pg *plugininventory.PluginGroup, plugins []*plugininventory.PluginInventoryEntry) []error {
+	var errs []error
+	id := fmt.Sprintf("plugin group '%s'", plugininventory.PluginGroupToID(pg))
+	if pg.RecommendedVersion != "" {
+		if _, exists := pg.Versions[pg.RecommendedVersion]; !exists {
+			errs = append(errs, errors.Errorf("%s: the recommended version %q is not available", id, pg.RecommendedVersion))
+		}
+	}
+	for version, entries := range pg.Versions {
+		if _, err := semver.NewVersion(version); err != nil {
+			errs = append(errs, errors.Errorf("%s: invalid version %q", id, version))
+		}
+		for _, entry := range entries {
+			if !pluginVersionExists(plugins, &entry.PluginIdentifier) {
+				errs = append(errs, errors.Errorf("%s: version %q refers to the unavailable plugin '%s_%s' version %q", id, version, entry.Name, entry.Target, entry.Version))
+			}
+		}
+	}
+	return errs
+}
+
+// pluginVersionExists returns true if the version of the plugin is in the inventory. The same
+// plugin can have several entries, one for each of its vendors and publishers, so the version
+// is looked for in all the entries of the plugin rather than only in the first one.
+func pluginVersionExists(plugins []*plugininventory.PluginInventoryEntry, pi *plugininventory.PluginIdentifier) bool {
+	for _, p := range plugins {
+		if p.Name != pi.Name || p.Target != pi.Target {
+			continue
+		}
+		if _, exists := p.Artifacts[pi.Version]; exists {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/fakes"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+)
+
+var _ = Describe("Unit tests for inventory validate", func() {
+	var dir, dbFile string
+	var db plugininventory.PluginInventory
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "")
+		Expect(err).NotTo(HaveOccurred())
+		dbFile = filepath.Join(dir, plugininventory.SQliteDBFileName)
+		db = plugininventory.NewSQLiteInventory(dbFile, "")
+		Expect(db.CreateSchema(context.Background())).To(Succeed())
+	})
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	insertPlugin := func(artifact distribution.Artifact) {
+		err := db.InsertPlugin(context.Background(), &plugininventory.PluginInventoryEntry{
+			Name:        "foo",
+			Target:      types.TargetK8s,
+			Description: "foo plugin",
+			Publisher:   "tkg",
+			Vendor:      "vmware",
+			Artifacts:   distribution.Artifacts{"v0.0.1": []distribution.Artifact{artifact}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	insertPluginGroup := func(pluginVersion string) {
+		err := db.InsertPluginGroup(context.Background(), &plugininventory.PluginGroup{
+			Vendor:      "vmware",
+			Publisher:   "tkg",
+			Name:        "default",
+			Description: "default group",
+			Versions: map[string][]*plugininventory.PluginGroupPluginEntry{
+				"v1.0.0": {
+					{PluginIdentifier: plugininventory.PluginIdentifier{Name: "foo", Target: types.TargetK8s, Version: pluginVersion}},
+				},
+			},
+		}, false)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	var _ = Context("tests for the inventory validate function", func() {
+		var _ = It("when the local inventory database is valid", func() {
+			insertPlugin(distribution.Artifact{OS: "linux", Arch: "amd64", Digest: "fake-digest", Image: "fake-uri"})
+			insertPlugin(distribution.Artifact{OS: distribution.ArtifactPlatformMultiArch, Arch: distribution.ArtifactPlatformMultiArch, Image: "fake-index"})
+			insertPluginGroup("v0.0.1")
+
+			ivo := InventoryValidateOptions{InventoryDBFile: dbFile}
+			Expect(ivo.ValidateInventory()).To(Succeed())
+		})
+
+		var _ = It("when the local inventory database has invalid entries", func() {
+			insertPlugin(distribution.Artifact{OS: "linux", Arch: "amd64", Image: "fake-uri"})
+			insertPlugin(distribution.Artifact{OS: "plan9", Arch: "amd64", Digest: "fake-digest", Image: "fake-uri"})
+			insertPlugin(distribution.Artifact{OS: "darwin", Arch: "amd64", Digest: "fake-digest"})
+			os.Setenv(constants.SkipPluginGroupVerificationOnPublish, "true")
+			insertPluginGroup("v0.0.2")
+			os.Unsetenv(constants.SkipPluginGroupVerificationOnPublish)
+
+			ivo := InventoryValidateOptions{InventoryDBFile: dbFile}
+			err := ivo.ValidateInventory()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("the artifact for linux/amd64 has no digest"))
+			Expect(err.Error()).To(ContainSubstring("the artifact has an unknown platform plan9/amd64"))
+			Expect(err.Error()).To(ContainSubstring("the artifact for darwin/amd64 has no image nor URI"))
+			Expect(err.Error()).To(ContainSubstring("refers to the unavailable plugin 'foo_kubernetes' version \"v0.0.2\""))
+		})
+
+		var _ = It("when the inventory database is downloaded from the repository", func() {
+			insertPlugin(distribution.Artifact{OS: "linux", Arch: "amd64", Digest: "fake-digest", Image: "fake-uri"})
+
+			fakeImgpkgWrapper := &fakes.ImageOperationsImpl{}
+			fakeImgpkgWrapper.DownloadImageAndSaveFilesToDirStub = func(_, path string) error {
+				content, err := os.ReadFile(dbFile)
+				if err != nil {
+					return err
+				}
+				return os.WriteFile(filepath.Join(path, plugininventory.SQliteDBFileName), content, 0644)
+			}
+			ivo := InventoryValidateOptions{
+				Repository:          "test-repo.com",
+				InventoryImageTag:   "latest",
+				ImageOperationsImpl: fakeImgpkgWrapper,
+			}
+			Expect(ivo.ValidateInventory()).To(Succeed())
+			image, _ := fakeImgpkgWrapper.DownloadImageAndSaveFilesToDirArgsForCall(0)
+			Expect(image).To(Equal("test-repo.com/plugin-inventory:latest"))
+
+			fakeImgpkgWrapper.DownloadImageAndSaveFilesToDirStub = nil
+			fakeImgpkgWrapper.DownloadImageAndSaveFilesToDirReturns(errors.New("image not found"))
+			err := ivo.ValidateInventory()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("error while pulling database from the image"))
+		})
+
+		var _ = It("when the plugin versions of a group are in other entries of the plugin", func() {
+			plugins := []*plugininventory.PluginInventoryEntry{
+				{Name: "foo", Target: types.TargetK8s, Vendor: "vmware", Publisher: "tkg", Artifacts: distribution.Artifacts{"v0.0.1": nil}},
+				{Name: "foo", Target: types.TargetK8s, Vendor: "other", Publisher: "tkg", Artifacts: distribution.Artifacts{"v0.0.2": nil}},
+			}
+			Expect(pluginVersionExists(plugins, &plugininventory.PluginIdentifier{Name: "foo", Target: types.TargetK8s, Version: "v0.0.1"})).To(BeTrue())
+			Expect(pluginVersionExists(plugins, &plugininventory.PluginIdentifier{Name: "foo", Target: types.TargetK8s, Version: "v0.0.2"})).To(BeTrue())
+			Expect(pluginVersionExists(plugins, &plugininventory.PluginIdentifier{Name: "foo", Target: types.TargetK8s, Version: "v0.0.3"})).To(BeFalse())
+			Expect(pluginVersionExists(plugins, &plugininventory.PluginIdentifier{Name: "foo", Target: types.TargetTMC, Version: "v0.0.1"})).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,49 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+)
+
+// InventoryVerifyOptions defines options for verifying the inventory database before it is published
+type InventoryVerifyOptions struct {
+	Repository        string
+	InventoryImageTag string
+	InventoryDBFile   string
+
+	ImageOperationsImpl carvelhelpers.ImageOperationsImpl
+}
+
+// VerifyInventory verifies the inventory database available on the repository or the local
+// inventory database file with the validation of the plugin inventories, which also checks
+// the URIs of the artifacts can be reached, and returns all the problems found
+func (ivo *InventoryVerifyOptions) VerifyInventory() error {
+	tempDir, err := os.MkdirTemp("", "")
+	if err != nil {
+		return errors.Wrap(err, "unable to create temporary directory")
+	}
+	defer os.RemoveAll(tempDir)
+
+	dbFile, err := getInventoryDBFileToRead(ivo.ImageOperationsImpl, ivo.Repository, ivo.InventoryImageTag, ivo.InventoryDBFile, tempDir)
+	if err != nil {
+		return err
+	}
+
+	db := plugininventory.NewSQLiteInventory(dbFile, ivo.Repository)
+	if err := db.Validate(context.Background()); err != nil {
+		return errors.Wrap(err, "invalid plugin inventory database")
+	}
+
+	log.Infof("successfully verified the plugin inventory database")
+	return nil
+}
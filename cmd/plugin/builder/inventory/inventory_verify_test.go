@@ -0,0 +1,95 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/fakes"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+)
+
+var _ = Describe("Unit tests for inventory verify", func() {
+	var dir, dbFile string
+	var db plugininventory.PluginInventory
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "")
+		Expect(err).NotTo(HaveOccurred())
+		dbFile = filepath.Join(dir, plugininventory.SQliteDBFileName)
+		db = plugininventory.NewSQLiteInventory(dbFile, "")
+		Expect(db.CreateSchema(context.Background())).To(Succeed())
+	})
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	insertPlugin := func(version string, artifact distribution.Artifact) {
+		err := db.InsertPlugin(context.Background(), &plugininventory.PluginInventoryEntry{
+			Name:        "foo",
+			Target:      types.TargetK8s,
+			Description: "foo plugin",
+			Publisher:   "tkg",
+			Vendor:      "vmware",
+			Artifacts:   distribution.Artifacts{version: []distribution.Artifact{artifact}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	var _ = Context("tests for the inventory verify function", func() {
+		var _ = It("when the local inventory database is valid", func() {
+			binary := filepath.Join(dir, "foo")
+			Expect(os.WriteFile(binary, []byte("binary"), 0o600)).To(Succeed())
+			insertPlugin("v0.0.1", distribution.Artifact{OS: "linux", Arch: "amd64", Digest: "fake-digest", Image: "vmware/tkg/linux/amd64/k8s/foo:v0.0.1"})
+			insertPlugin("v0.0.2", distribution.Artifact{OS: "linux", Arch: "amd64", Digest: "fake-digest", URI: "file://" + binary})
+
+			ivo := InventoryVerifyOptions{InventoryDBFile: dbFile}
+			Expect(ivo.VerifyInventory()).To(Succeed())
+		})
+
+		var _ = It("when the local inventory database has invalid entries", func() {
+			insertPlugin("v0.0.1", distribution.Artifact{OS: "linux", Arch: "amd64", Digest: "fake-digest", Image: "vmware/tkg/linux/amd64/k8s/foo:v0.0.1"})
+			insertPlugin("v0.0.2", distribution.Artifact{OS: "linux", Arch: "amd64", Digest: "fake-digest", Image: "vmware/tkg/linux/amd64/k8s/foo:v0.0.1"})
+			insertPlugin("latest", distribution.Artifact{OS: "linux", Arch: "amd64", Digest: "fake-digest", URI: "file://" + filepath.Join(dir, "missing")})
+
+			ivo := InventoryVerifyOptions{InventoryDBFile: dbFile}
+			err := ivo.VerifyInventory()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid plugin inventory database"))
+			Expect(err.Error()).To(ContainSubstring(`invalid version "latest"`))
+			Expect(err.Error()).To(ContainSubstring(`the artifact "/vmware/tkg/linux/amd64/k8s/foo:v0.0.1" is also the artifact of the plugin 'foo_kubernetes' version "v0.0.1" for linux/amd64`))
+			Expect(err.Error()).To(ContainSubstring("does not point to an existing file"))
+		})
+
+		var _ = It("when the inventory database is downloaded from the repository", func() {
+			insertPlugin("v0.0.1", distribution.Artifact{OS: "linux", Arch: "amd64", Digest: "fake-digest", Image: "vmware/tkg/linux/amd64/k8s/foo:v0.0.1"})
+
+			fakeImgpkgWrapper := &fakes.ImageOperationsImpl{}
+			fakeImgpkgWrapper.DownloadImageAndSaveFilesToDirStub = func(_, path string) error {
+				content, err := os.ReadFile(dbFile)
+				if err != nil {
+					return err
+				}
+				return os.WriteFile(filepath.Join(path, plugininventory.SQliteDBFileName), content, 0644)
+			}
+			ivo := InventoryVerifyOptions{
+				Repository:          "test-repo.com",
+				InventoryImageTag:   "latest",
+				ImageOperationsImpl: fakeImgpkgWrapper,
+			}
+			Expect(ivo.VerifyInventory()).To(Succeed())
+			image, _ := fakeImgpkgWrapper.DownloadImageAndSaveFilesToDirArgsForCall(0)
+			Expect(image).To(Equal("test-repo.com/plugin-inventory:latest"))
+		})
+	})
+})
@@ -4,6 +4,8 @@
 package main
 
 import (
+	"strings"
+
 	"github.com/spf13/cobra"
 
 	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/inventory"
@@ -21,6 +23,9 @@ func newInventoryPluginCmd() *cobra.Command {
 		newInventoryPluginAddCmd(),
 		newInventoryPluginActivateCmd(),
 		newInventoryPluginDeactivateCmd(),
+		newInventoryPluginDeactivateVersionsCmd(),
+		newInventoryPluginImportKrewCmd(),
+		newInventoryPluginGeneratePackagesCmd(),
 	)
 
 	return inventoryPluginCmd
@@ -35,6 +40,7 @@ type inventoryPluginAddFlags struct {
 	InventoryDBFile   string
 	DeactivatePlugins bool
 	ValidateOnly      bool
+	MultiArch         bool
 }
 
 func newInventoryPluginAddCmd() *cobra.Command {
@@ -55,6 +61,7 @@ func newInventoryPluginAddCmd() *cobra.Command {
 				DeactivatePlugins:   ipaFlags.DeactivatePlugins,
 				InventoryDBFile:     ipaFlags.InventoryDBFile,
 				ValidateOnly:        ipaFlags.ValidateOnly,
+				MultiArch:           ipaFlags.MultiArch,
 				ImageOperationsImpl: carvelhelpers.NewImageOperationsImpl(),
 			}
 			return paOptions.PluginAdd()
@@ -69,6 +76,7 @@ func newInventoryPluginAddCmd() *cobra.Command {
 	pluginAddCmd.Flags().StringVarP(&ipaFlags.InventoryDBFile, "plugin-inventory-db-file", "", "", "local file for the inventory database")
 	pluginAddCmd.Flags().BoolVarP(&ipaFlags.DeactivatePlugins, "deactivate", "", false, "mark plugins as deactivated")
 	pluginAddCmd.Flags().BoolVarP(&ipaFlags.ValidateOnly, "validate", "", false, "validate whether plugins already exists in the plugin inventory or not")
+	pluginAddCmd.Flags().BoolVarP(&ipaFlags.MultiArch, "multi-arch", "", false, "add the plugins published as multi-arch image indexes")
 
 	_ = pluginAddCmd.MarkFlagRequired("repository")
 	_ = pluginAddCmd.MarkFlagRequired("vendor")
@@ -148,3 +156,181 @@ func getActivateDeactivateBaseCmd() (*cobra.Command, *inventoryPluginActivateDea
 
 	return activateDeactivateCmd, flags
 }
+
+type inventoryPluginDeactivateVersionsFlags struct {
+	Repository              string
+	InventoryImageTag       string
+	InventoryDBFile         string
+	InventoryMetadataDBFile string
+	Publisher               string
+	Vendor                  string
+	PluginName              string
+	Target                  string
+	Versions                string
+}
+
+func newInventoryPluginDeactivateVersionsCmd() *cobra.Command {
+	var flags = &inventoryPluginDeactivateVersionsFlags{}
+
+	var pluginDeactivateVersionsCmd = &cobra.Command{
+		Use:   "deactivate-versions",
+		Short: "Deactivate a range of plugin versions in the inventory database and the inventory metadata database",
+		Long: `Deactivate all the versions of a plugin matching a semver constraint in the inventory database and
+remove them from the inventory metadata database, if the repository has one, in a single operation.
+The number of rows updated for each plugin version is reported.`,
+		SilenceUsage: true,
+		Example: `
+    # Deactivate the versions v1.2.0 to v1.2.4 of the 'foo' plugin for all targets
+    tanzu builder inventory plugin deactivate-versions --repository localhost:5002/test/v1/tanzu-cli/plugins --vendor vmware --publisher tkg --name foo --versions ">= v1.2.0, < v1.2.5"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ipvdOptions := inventory.InventoryPluginVersionsDeactivateOptions{
+				Repository:              flags.Repository,
+				InventoryImageTag:       flags.InventoryImageTag,
+				InventoryDBFile:         flags.InventoryDBFile,
+				InventoryMetadataDBFile: flags.InventoryMetadataDBFile,
+				Vendor:                  flags.Vendor,
+				Publisher:               flags.Publisher,
+				PluginName:              flags.PluginName,
+				Target:                  flags.Target,
+				Versions:                flags.Versions,
+				ImageOperationsImpl:     carvelhelpers.NewImageOperationsImpl(),
+			}
+			return ipvdOptions.DeactivatePluginVersions()
+		},
+	}
+
+	pluginDeactivateVersionsCmd.Flags().StringVarP(&flags.Repository, "repository", "", "", "repository to publish plugin inventory image")
+	pluginDeactivateVersionsCmd.Flags().StringVarP(&flags.InventoryImageTag, "plugin-inventory-image-tag", "", "latest", "tag to which plugin inventory image needs to be published")
+	pluginDeactivateVersionsCmd.Flags().StringVarP(&flags.InventoryDBFile, "plugin-inventory-db-file", "", "", "local file for the inventory database")
+	pluginDeactivateVersionsCmd.Flags().StringVarP(&flags.InventoryMetadataDBFile, "plugin-inventory-metadata-db-file", "", "", "local file for the inventory metadata database (optional)")
+	pluginDeactivateVersionsCmd.Flags().StringVarP(&flags.Vendor, "vendor", "", "", "name of the vendor")
+	pluginDeactivateVersionsCmd.Flags().StringVarP(&flags.Publisher, "publisher", "", "", "name of the publisher")
+	pluginDeactivateVersionsCmd.Flags().StringVarP(&flags.PluginName, "name", "", "", "name of the plugin")
+	pluginDeactivateVersionsCmd.Flags().StringVarP(&flags.Target, "target", "", "", "target of the plugin, all the targets if not specified")
+	pluginDeactivateVersionsCmd.Flags().StringVarP(&flags.Versions, "versions", "", "", "semver constraint of the plugin versions to deactivate, e.g. \">= v1.2.0, < v1.2.5\"")
+
+	_ = pluginDeactivateVersionsCmd.MarkFlagRequired("vendor")
+	_ = pluginDeactivateVersionsCmd.MarkFlagRequired("publisher")
+	_ = pluginDeactivateVersionsCmd.MarkFlagRequired("name")
+	_ = pluginDeactivateVersionsCmd.MarkFlagRequired("versions")
+	pluginDeactivateVersionsCmd.MarkFlagsMutuallyExclusive("repository", "plugin-inventory-db-file")
+	pluginDeactivateVersionsCmd.MarkFlagsMutuallyExclusive("repository", "plugin-inventory-metadata-db-file")
+
+	return pluginDeactivateVersionsCmd
+}
+
+type inventoryPluginImportKrewFlags struct {
+	Repository        string
+	InventoryImageTag string
+	KrewManifests     string
+	Target            string
+	Publisher         string
+	Vendor            string
+	InventoryDBFile   string
+	DeactivatePlugins bool
+}
+
+func newInventoryPluginImportKrewCmd() *cobra.Command {
+	var flags = &inventoryPluginImportKrewFlags{}
+
+	var pluginImportKrewCmd = &cobra.Command{
+		Use:   "import-krew",
+		Short: "Import the plugins of krew plugin index manifests to the inventory database available on the remote repository",
+		Long: `Import the plugins of krew plugin index manifests to the inventory database available on the remote repository.
+The archive of each platform supported by the CLI is downloaded and verified, then the plugin binary it contains
+is published to the repository as a plugin image and the plugin is added to the inventory database.`,
+		SilenceUsage: true,
+		Example: `
+    # Import all the plugins of a krew plugin index as kubernetes plugins
+    tanzu builder inventory plugin import-krew --repository localhost:5002/test/v1/tanzu-cli/plugins --vendor vmware --publisher krew --krew-manifests ./krew-index/plugins`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ikOptions := inventory.InventoryPluginImportKrewOptions{
+				Repository:          flags.Repository,
+				InventoryImageTag:   flags.InventoryImageTag,
+				KrewManifests:       flags.KrewManifests,
+				Target:              flags.Target,
+				Vendor:              flags.Vendor,
+				Publisher:           flags.Publisher,
+				InventoryDBFile:     flags.InventoryDBFile,
+				DeactivatePlugins:   flags.DeactivatePlugins,
+				ImageOperationsImpl: carvelhelpers.NewImageOperationsImpl(),
+			}
+			return ikOptions.ImportKrewPlugins()
+		},
+	}
+
+	pluginImportKrewCmd.Flags().StringVarP(&flags.Repository, "repository", "", "", "repository to publish the plugin images and the plugin inventory image")
+	pluginImportKrewCmd.Flags().StringVarP(&flags.InventoryImageTag, "plugin-inventory-image-tag", "", "latest", "tag to which plugin inventory image needs to be published")
+	pluginImportKrewCmd.Flags().StringVarP(&flags.KrewManifests, "krew-manifests", "", "", "krew plugin manifest file or directory of krew plugin manifests to import")
+	pluginImportKrewCmd.Flags().StringVarP(&flags.Target, "target", "", "kubernetes", "target of the imported plugins")
+	pluginImportKrewCmd.Flags().StringVarP(&flags.Vendor, "vendor", "", "", "name of the vendor")
+	pluginImportKrewCmd.Flags().StringVarP(&flags.Publisher, "publisher", "", "", "name of the publisher")
+	pluginImportKrewCmd.Flags().StringVarP(&flags.InventoryDBFile, "plugin-inventory-db-file", "", "", "local file for the inventory database")
+	pluginImportKrewCmd.Flags().BoolVarP(&flags.DeactivatePlugins, "deactivate", "", false, "mark plugins as deactivated")
+
+	_ = pluginImportKrewCmd.MarkFlagRequired("repository")
+	_ = pluginImportKrewCmd.MarkFlagRequired("vendor")
+	_ = pluginImportKrewCmd.MarkFlagRequired("publisher")
+	_ = pluginImportKrewCmd.MarkFlagRequired("krew-manifests")
+
+	return pluginImportKrewCmd
+}
+
+type inventoryPluginGeneratePackagesFlags struct {
+	Repository          string
+	InventoryImageTag   string
+	InventoryDBFile     string
+	Plugins             []string
+	Version             string
+	Format              string
+	DownloadURLTemplate string
+	OutputDir           string
+}
+
+func newInventoryPluginGeneratePackagesCmd() *cobra.Command {
+	var flags = &inventoryPluginGeneratePackagesFlags{}
+
+	var pluginGeneratePackagesCmd = &cobra.Command{
+		Use:   "generate-packages",
+		Short: "Generate Homebrew formulae, Chocolatey packages or asdf plugin definitions for plugins of the inventory database",
+		Long: `Generate Homebrew formulae, Chocolatey packages or asdf plugin definitions for plugins of the inventory database,
+to distribute the plugins through existing package managers. The packages download the plugin binaries from
+the download URL, verify them against the digests of the inventory database and install them with the Tanzu CLI.
+The download URL is a template which can use the {{ .Name }}, {{ .Target }}, {{ .Version }}, {{ .OS }},
+{{ .Arch }}, {{ .Digest }} and {{ .FileName }} fields of each plugin binary.`,
+		SilenceUsage: true,
+		Example: `
+    # Generate the Homebrew formulae of the latest versions of the 'foo' and 'bar' plugins
+    tanzu builder inventory plugin generate-packages --repository project-stg.registry.vmware.com/test/v1/tanzu-cli/plugins --format homebrew --plugin foo:kubernetes --plugin bar:global --download-url "https://downloads.example.com/tanzu-plugins/{{ .Name }}/{{ .Version }}/{{ .FileName }}" --output-dir ./packages`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			igpOptions := inventory.InventoryPluginGeneratePackagesOptions{
+				Repository:          flags.Repository,
+				InventoryImageTag:   flags.InventoryImageTag,
+				InventoryDBFile:     flags.InventoryDBFile,
+				Plugins:             flags.Plugins,
+				Version:             flags.Version,
+				Format:              flags.Format,
+				DownloadURLTemplate: flags.DownloadURLTemplate,
+				OutputDir:           flags.OutputDir,
+				ImageOperationsImpl: carvelhelpers.NewImageOperationsImpl(),
+			}
+			return igpOptions.GeneratePackages()
+		},
+	}
+
+	pluginGeneratePackagesCmd.Flags().StringVarP(&flags.Repository, "repository", "", "", "repository of the plugin inventory image")
+	pluginGeneratePackagesCmd.Flags().StringVarP(&flags.InventoryImageTag, "plugin-inventory-image-tag", "", "latest", "tag of the plugin inventory image")
+	pluginGeneratePackagesCmd.Flags().StringVarP(&flags.InventoryDBFile, "plugin-inventory-db-file", "", "", "local file for the inventory database")
+	pluginGeneratePackagesCmd.Flags().StringArrayVarP(&flags.Plugins, "plugin", "", nil, "plugin to generate a package for, as 'name:target' (can be specified multiple times)")
+	pluginGeneratePackagesCmd.Flags().StringVarP(&flags.Version, "version", "", "latest", "version of the plugins to package, the asdf plugin definitions provide all the versions")
+	pluginGeneratePackagesCmd.Flags().StringVarP(&flags.Format, "format", "", "", "format of the packages ("+strings.Join(inventory.PackageFormats, "|")+")")
+	pluginGeneratePackagesCmd.Flags().StringVarP(&flags.DownloadURLTemplate, "download-url", "", "", "template of the URL from which the package managers download the plugin binaries")
+	pluginGeneratePackagesCmd.Flags().StringVarP(&flags.OutputDir, "output-dir", "", ".", "directory in which the packages are generated")
+
+	_ = pluginGeneratePackagesCmd.MarkFlagRequired("plugin")
+	_ = pluginGeneratePackagesCmd.MarkFlagRequired("format")
+	_ = pluginGeneratePackagesCmd.MarkFlagRequired("download-url")
+	pluginGeneratePackagesCmd.MarkFlagsMutuallyExclusive("repository", "plugin-inventory-db-file")
+
+	return pluginGeneratePackagesCmd
+}
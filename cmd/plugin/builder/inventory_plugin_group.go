@@ -21,6 +21,7 @@ func newInventoryPluginGroupCmd() *cobra.Command {
 		newInventoryPluginGroupAddCmd(),
 		newInventoryPluginGroupActivateCmd(),
 		newInventoryPluginGroupDeactivateCmd(),
+		newInventoryPluginGroupGenerateCmd(),
 	)
 
 	return inventoryPluginCmd
@@ -164,3 +165,54 @@ func getPluginGroupActivateDeactivateBaseCmd() (*cobra.Command, *inventoryPlugin
 
 	return activateDeactivateCmd, flags
 }
+
+type inventoryPluginGroupGenerateFlags struct {
+	Repository        string
+	InventoryImageTag string
+	InventoryDBFile   string
+	TemplateFile      string
+	Variables         map[string]string
+	OutputFile        string
+}
+
+func newInventoryPluginGroupGenerateCmd() *cobra.Command {
+	var ipggFlags = &inventoryPluginGroupGenerateFlags{}
+
+	var pluginGroupGenerateCmd = &cobra.Command{
+		Use:   "generate",
+		Short: "Generate a plugin-group manifest from a template",
+		Long: `Generate a plugin-group manifest from a template by substituting the variables of the template
+and by resolving the 'latest' plugin versions to the latest versions found in the inventory database.
+The generation fails if any plugin of the plugin-group is not in the inventory database.`,
+		SilenceUsage: true,
+		Example: `
+    # Generate the plugin-group manifest from a template using the '{{ .tkgVersion }}' variable
+    tanzu builder inventory plugin-group generate --template ./plugin_group_manifest.yaml.tmpl --var tkgVersion=v2.3.0 --repository localhost:5002/test/v1/tanzu-cli/plugins --output ./artifacts/plugins/plugin_group_manifest.yaml`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pggOptions := inventory.InventoryPluginGroupGenerateOptions{
+				Repository:          ipggFlags.Repository,
+				InventoryImageTag:   ipggFlags.InventoryImageTag,
+				InventoryDBFile:     ipggFlags.InventoryDBFile,
+				TemplateFile:        ipggFlags.TemplateFile,
+				Variables:           ipggFlags.Variables,
+				OutputFile:          ipggFlags.OutputFile,
+				ImageOperationsImpl: carvelhelpers.NewImageOperationsImpl(),
+			}
+			return pggOptions.GeneratePluginGroupManifest()
+		},
+	}
+
+	pluginGroupGenerateCmd.Flags().StringVarP(&ipggFlags.Repository, "repository", "", "", "repository of the plugin inventory image")
+	pluginGroupGenerateCmd.Flags().StringVarP(&ipggFlags.InventoryImageTag, "plugin-inventory-image-tag", "", "latest", "tag of the plugin inventory image")
+	pluginGroupGenerateCmd.Flags().StringVarP(&ipggFlags.InventoryDBFile, "plugin-inventory-db-file", "", "", "local file for the inventory database")
+	pluginGroupGenerateCmd.Flags().StringVarP(&ipggFlags.TemplateFile, "template", "", "", "template of the plugin-group manifest")
+	pluginGroupGenerateCmd.Flags().StringToStringVarP(&ipggFlags.Variables, "var", "", nil, "value of a variable of the template in the form 'name=value', can be specified multiple times")
+	pluginGroupGenerateCmd.Flags().StringVarP(&ipggFlags.OutputFile, "output", "", "", "file to write the generated plugin-group manifest to")
+	pluginGroupGenerateCmd.MarkFlagsMutuallyExclusive("repository", "plugin-inventory-db-file")
+	pluginGroupGenerateCmd.MarkFlagsOneRequired("repository", "plugin-inventory-db-file")
+
+	_ = pluginGroupGenerateCmd.MarkFlagRequired("template")
+	_ = pluginGroupGenerateCmd.MarkFlagRequired("output")
+
+	return pluginGroupGenerateCmd
+}
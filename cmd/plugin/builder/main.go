@@ -30,6 +30,8 @@ func main() {
 		NewInitCmd(),
 		NewPluginCmd(),
 		newInventoryCmd(),
+		newPublishCmd(),
+		newPromoteCmd(),
 	)
 
 	if err := p.Execute(); err != nil {
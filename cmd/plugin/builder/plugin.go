@@ -5,9 +5,11 @@ package main
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/command"
 	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/crane"
@@ -46,6 +48,8 @@ type pluginBuildPackageFlags struct {
 	BinaryArtifactDir  string
 	PackageArtifactDir string
 	localOCIRepository string
+	MaxBinarySize      string
+	MaxStartupTime     time.Duration
 }
 
 type pluginPublishPackageFlags struct {
@@ -54,6 +58,7 @@ type pluginPublishPackageFlags struct {
 	Publisher          string
 	Vendor             string
 	DryRun             bool
+	MultiArch          bool
 }
 
 func newPluginBuildCmd() *cobra.Command {
@@ -114,6 +119,15 @@ func newPluginBuildPackageCmd() *cobra.Command {
 		Long:         "Build plugin packages OCI image as tar.gz file that can be published to any repository",
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			var maxBinarySize resource.Quantity
+			if pbpFlags.MaxBinarySize != "" {
+				var err error
+				maxBinarySize, err = resource.ParseQuantity(pbpFlags.MaxBinarySize)
+				if err != nil {
+					return errors.Wrapf(err, "invalid maximum plugin binary size %q", pbpFlags.MaxBinarySize)
+				}
+			}
+
 			if pbpFlags.localOCIRepository == "" {
 				registryPort, shutdownServerFunc, err := registry.ServeLocalRegistry("")
 				if err != nil {
@@ -127,6 +141,8 @@ func newPluginBuildPackageCmd() *cobra.Command {
 				BinaryArtifactDir:  pbpFlags.BinaryArtifactDir,
 				PackageArtifactDir: pbpFlags.PackageArtifactDir,
 				LocalOCIRegistry:   pbpFlags.localOCIRepository,
+				MaxBinarySize:      maxBinarySize.Value(),
+				MaxStartupTime:     pbpFlags.MaxStartupTime,
 				CraneOptions:       crane.NewCraneWrapper(),
 			}
 			return bppArgs.BuildPluginPackages()
@@ -136,6 +152,8 @@ func newPluginBuildPackageCmd() *cobra.Command {
 	pluginBuildPackageCmd.Flags().StringVarP(&pbpFlags.BinaryArtifactDir, "binary-artifacts", "", "./artifacts/plugins", "plugin binary artifact directory")
 	pluginBuildPackageCmd.Flags().StringVarP(&pbpFlags.PackageArtifactDir, "package-artifacts", "", "./artifacts/packages", "plugin package artifacts directory")
 	pluginBuildPackageCmd.Flags().StringVarP(&pbpFlags.localOCIRepository, "oci-registry", "", "", "local oci-registry to use for generating packages (optional)")
+	pluginBuildPackageCmd.Flags().StringVarP(&pbpFlags.MaxBinarySize, "max-binary-size", "", "", "reject the plugin binaries larger than this size, e.g. 80Mi (optional)")
+	pluginBuildPackageCmd.Flags().DurationVarP(&pbpFlags.MaxStartupTime, "max-startup-time", "", 0, "reject the plugin binaries of the current platform whose 'info' command takes longer than this duration, e.g. 2s (optional)")
 
 	return pluginBuildPackageCmd
 }
@@ -155,6 +173,7 @@ func newPluginPublishPackageCmd() *cobra.Command {
 				Vendor:             pppFlags.Vendor,
 				Repository:         pppFlags.Repository,
 				DryRun:             pppFlags.DryRun,
+				MultiArch:          pppFlags.MultiArch,
 				CraneOptions:       crane.NewCraneWrapper(),
 			}
 			return bppArgs.PublishPluginPackages()
@@ -166,6 +185,7 @@ func newPluginPublishPackageCmd() *cobra.Command {
 	pluginBuildPackageCmd.Flags().StringVarP(&pppFlags.Vendor, "vendor", "", "", "name of the vendor")
 	pluginBuildPackageCmd.Flags().StringVarP(&pppFlags.Publisher, "publisher", "", "", "name of the publisher")
 	pluginBuildPackageCmd.Flags().BoolVarP(&pppFlags.DryRun, "dry-run", "", false, "show commands without publishing plugin packages")
+	pluginBuildPackageCmd.Flags().BoolVarP(&pppFlags.MultiArch, "multi-arch", "", false, "also publish a multi-arch image index for every plugin version")
 
 	_ = pluginBuildPackageCmd.MarkFlagRequired("repository")
 	_ = pluginBuildPackageCmd.MarkFlagRequired("vendor")
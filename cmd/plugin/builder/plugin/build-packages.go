@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -25,7 +27,12 @@ type BuildPluginPackageOptions struct {
 	BinaryArtifactDir  string
 	PackageArtifactDir string
 	LocalOCIRegistry   string
-	CraneOptions       crane.CraneWrapper
+	// MaxBinarySize is the maximum size of the plugin binaries in bytes, no limit if zero
+	MaxBinarySize int64
+	// MaxStartupTime is the maximum time the `info` command of the plugin binaries can take
+	// to complete, no limit if zero. Only the binaries of the current platform are checked.
+	MaxStartupTime time.Duration
+	CraneOptions   crane.CraneWrapper
 
 	pluginManifestFile string
 }
@@ -122,6 +129,10 @@ func (bpo *BuildPluginPackageOptions) generatePluginPackage(pluginBinaryFilePath
 		return fmt.Errorf("invalid plugin binary :%v", pluginBinaryFilePath)
 	}
 
+	if err := bpo.validatePluginBinaryGates(pluginBinaryFilePath, osArch, threadID); err != nil {
+		return err
+	}
+
 	pluginTarFilePath := filepath.Join(bpo.PackageArtifactDir, helpers.GetPluginArchiveRelativePath(p, osArch, version))
 	image := fmt.Sprintf("%s/plugins/%s/%s/%s:%s", bpo.LocalOCIRegistry, osArch.OS(), osArch.Arch(), p.Name, version)
 
@@ -140,3 +151,22 @@ func (bpo *BuildPluginPackageOptions) generatePluginPackage(pluginBinaryFilePath
 	log.Infof("%s Generated plugin package at %q", threadID, pluginTarFilePath)
 	return nil
 }
+
+// validatePluginBinaryGates rejects the plugin binary if it exceeds the size or startup time limits
+func (bpo *BuildPluginPackageOptions) validatePluginBinaryGates(pluginBinaryFilePath string, osArch cli.Arch, threadID string) error {
+	if bpo.MaxBinarySize > 0 {
+		if err := helpers.ValidatePluginBinarySize(pluginBinaryFilePath, bpo.MaxBinarySize); err != nil {
+			return err
+		}
+	}
+	if bpo.MaxStartupTime > 0 {
+		if osArch.OS() != runtime.GOOS || osArch.Arch() != runtime.GOARCH {
+			log.Infof("%s Skipping startup time validation of plugin binary %q built for another platform", threadID, pluginBinaryFilePath)
+			return nil
+		}
+		if err := helpers.ValidatePluginStartupTime(pluginBinaryFilePath, bpo.MaxStartupTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
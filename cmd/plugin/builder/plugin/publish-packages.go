@@ -5,7 +5,6 @@ package plugin
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"sync"
 
@@ -15,6 +14,7 @@ import (
 
 	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/crane"
 	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/helpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
 )
@@ -25,6 +25,7 @@ type PublishPluginPackageOptions struct {
 	Vendor             string
 	Repository         string
 	DryRun             bool
+	MultiArch          bool
 	CraneOptions       crane.CraneWrapper
 
 	pluginManifestFile string
@@ -81,27 +82,74 @@ func (ppo *PublishPluginPackageOptions) PublishPluginPackages() error {
 	wg.Wait()
 	close(fatalErrors)
 
-	hasFailed := false
+	failures := 0
 	for err := range fatalErrors {
-		hasFailed = true
+		failures++
 		log.Errorf("%s - publishing plugin package for %q failed - %v", err.ID, err.Path, err.Err)
 	}
-	if hasFailed {
-		os.Exit(1)
+	// The plugin packages already published are skipped when publishing again
+	// so the publishing can simply be retried to publish the remaining ones
+	if failures > 0 {
+		return errors.Errorf("publishing of %d plugin packages failed", failures)
 	}
 
+	if ppo.MultiArch {
+		return ppo.publishPluginImageIndexes(pluginManifest)
+	}
+	return nil
+}
+
+// publishPluginImageIndexes publishes a multi-arch image index for every plugin version
+// referencing the images of all the platforms published for the version
+func (ppo *PublishPluginPackageOptions) publishPluginImageIndexes(pluginManifest *cli.Manifest) error {
+	for i := range pluginManifest.Plugins {
+		p := pluginManifest.Plugins[i]
+		for _, version := range p.Versions {
+			var platformImages []carvelhelpers.PlatformImage
+			for _, osArch := range cli.AllOSArch {
+				if !utils.PathExists(filepath.Join(ppo.PackageArtifactDir, helpers.GetPluginArchiveRelativePath(p, osArch, version))) {
+					continue
+				}
+				platformImages = append(platformImages, carvelhelpers.PlatformImage{
+					Image: ppo.getPluginImage(p, osArch, version),
+					OS:    osArch.OS(),
+					Arch:  osArch.Arch(),
+				})
+			}
+			if len(platformImages) == 0 {
+				continue
+			}
+
+			indexImage := fmt.Sprintf("%s/%s/%s/%s/%s:%s", ppo.Repository, ppo.Vendor, ppo.Publisher, p.Target, p.Name, version)
+			if ppo.DryRun {
+				log.Infof("publishing image index '%s' for %d platforms", indexImage, len(platformImages))
+				continue
+			}
+			log.Infof("publishing image index for plugin 'name:%s' 'target:%s' 'version:%s'", p.Name, p.Target, version)
+			if err := ppo.CraneOptions.PushImageIndex(indexImage, platformImages); err != nil {
+				return errors.Wrapf(err, "unable to publish image index for plugin (name:%s, target:%s, version:%s)", p.Name, p.Target, version)
+			}
+			log.Infof("published image index at '%s'", indexImage)
+		}
+	}
 	return nil
 }
 
+func (ppo *PublishPluginPackageOptions) getPluginImage(p cli.Plugin, osArch cli.Arch, version string) string {
+	return fmt.Sprintf("%s/%s/%s/%s/%s/%s/%s:%s", ppo.Repository, ppo.Vendor, ppo.Publisher, osArch.OS(), osArch.Arch(), p.Target, p.Name, version)
+}
+
 func (ppo *PublishPluginPackageOptions) publishPluginPackage(pluginTarFilePath string, p cli.Plugin, osArch cli.Arch, version, threadID string) error {
 	if !utils.PathExists(pluginTarFilePath) {
 		return nil
 	}
 
-	imageToPush := fmt.Sprintf("%s/%s/%s/%s/%s/%s/%s:%s", ppo.Repository, ppo.Vendor, ppo.Publisher, osArch.OS(), osArch.Arch(), p.Target, p.Name, version)
+	imageToPush := ppo.getPluginImage(p, osArch, version)
 
 	if ppo.DryRun {
 		log.Infof("%s command: 'crane push %s %s'", threadID, pluginTarFilePath, imageToPush)
+	} else if ppo.isPublished(pluginTarFilePath, imageToPush) {
+		log.Infof("%s skipping plugin 'name:%s' 'target:%s' 'os:%s' 'arch:%s' 'version:%s' already published at '%s'", threadID, p.Name, p.Target, osArch.OS(), osArch.Arch(), version, imageToPush)
 	} else {
 		log.Infof("%s publishing plugin 'name:%s' 'target:%s' 'os:%s' 'arch:%s' 'version:%s'", threadID, p.Name, p.Target, osArch.OS(), osArch.Arch(), version)
 		err := ppo.CraneOptions.PushImage(pluginTarFilePath, imageToPush)
@@ -112,3 +160,17 @@ func (ppo *PublishPluginPackageOptions) publishPluginPackage(pluginTarFilePath s
 	}
 	return nil
 }
+
+// isPublished returns true if the image is already published with the same digest as the
+// image of the plugin package. The image is considered not published if any digest is unknown.
+func (ppo *PublishPluginPackageOptions) isPublished(pluginTarFilePath, image string) bool {
+	publishedDigest, err := ppo.CraneOptions.GetImageDigest(image)
+	if err != nil {
+		return false
+	}
+	digest, err := ppo.CraneOptions.GetTarImageDigest(pluginTarFilePath)
+	if err != nil {
+		return false
+	}
+	return digest == publishedDigest
+}
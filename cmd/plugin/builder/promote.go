@@ -0,0 +1,90 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/crane"
+	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/publish"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cosignhelper"
+)
+
+type promoteFlags struct {
+	SourceRepository        string
+	SourceInventoryImageTag string
+	Repository              string
+	InventoryImageTag       string
+	PluginName              string
+	PluginTarget            string
+	PluginVersion           string
+	PluginGroupID           string
+	Key                     string
+}
+
+// newPromoteCmd creates a new command to promote plugins from a repository to another
+func newPromoteCmd() *cobra.Command {
+	var pFlags = &promoteFlags{}
+
+	var promoteCmd = &cobra.Command{
+		Use:   "promote",
+		Short: "Promote a plugin version or a plugin-group version from a staging repository to the repository",
+		Long: `Promote a plugin version, or a plugin-group version and its plugins, from a source repository, e.g. a
+staging repository, to the repository. The plugin images are copied by digest so that the promoted plugin
+binaries are byte-identical to the ones tested from the source repository, then the plugins and the
+plugin-group are added to the plugin inventory database and the plugin inventory metadata database, if
+any, of the repository and the plugin inventory database image is signed. Promoting the same plugins
+again is a no-op so the command can safely be run again if it fails.`,
+		SilenceUsage: true,
+		Example: `
+    # Promote the version v1.0.0 of the 'foo' plugin from the staging repository
+    tanzu builder promote --source-repository localhost:5002/staging/tanzu-cli/plugins --repository localhost:5002/test/v1/tanzu-cli/plugins --plugin foo --version v1.0.0
+
+    # Promote the 'vmware-tkg/default:v1.0.0' plugin-group and its plugins from the staging repository and sign the plugin inventory
+    COSIGN_PASSWORD=<password> tanzu builder promote --source-repository localhost:5002/staging/tanzu-cli/plugins --repository localhost:5002/test/v1/tanzu-cli/plugins \
+        --plugin-group vmware-tkg/default:v1.0.0 --key ./cosign.key`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pro := &publish.PromoteOptions{
+				SourceRepository:        pFlags.SourceRepository,
+				SourceInventoryImageTag: pFlags.SourceInventoryImageTag,
+				Repository:              pFlags.Repository,
+				InventoryImageTag:       pFlags.InventoryImageTag,
+				PluginName:              pFlags.PluginName,
+				PluginTarget:            pFlags.PluginTarget,
+				PluginVersion:           pFlags.PluginVersion,
+				PluginGroupID:           pFlags.PluginGroupID,
+				ImageOperationsImpl:     carvelhelpers.NewImageOperationsImpl(),
+				CraneOptions:            crane.NewCraneWrapper(),
+			}
+			if pFlags.Key != "" {
+				registryOpts, err := getCosignRegistryOptions(pFlags.Repository)
+				if err != nil {
+					return err
+				}
+				pro.CosignSigner = cosignhelper.NewCosignSigner(pFlags.Key, registryOpts)
+			}
+			return pro.Promote()
+		},
+	}
+
+	promoteCmd.Flags().StringVarP(&pFlags.SourceRepository, "source-repository", "", "", "repository to promote the plugins from, e.g. a staging repository")
+	promoteCmd.Flags().StringVarP(&pFlags.SourceInventoryImageTag, "source-plugin-inventory-image-tag", "", "latest", "tag of the plugin inventory image of the source repository")
+	promoteCmd.Flags().StringVarP(&pFlags.Repository, "repository", "", "", "repository to promote the plugins to")
+	promoteCmd.Flags().StringVarP(&pFlags.InventoryImageTag, "plugin-inventory-image-tag", "", "latest", "tag to which plugin inventory image needs to be published")
+	promoteCmd.Flags().StringVarP(&pFlags.PluginName, "plugin", "", "", "name of the plugin to promote")
+	promoteCmd.Flags().StringVarP(&pFlags.PluginTarget, "target", "", "", "target of the plugin to promote, all the targets if not specified")
+	promoteCmd.Flags().StringVarP(&pFlags.PluginVersion, "version", "", "", "version of the plugin to promote")
+	promoteCmd.Flags().StringVarP(&pFlags.PluginGroupID, "plugin-group", "", "", "plugin-group version to promote with its plugins, e.g. vmware-tkg/default:v1.0.0")
+	promoteCmd.Flags().StringVarP(&pFlags.Key, "key", "", "", "path to the cosign private key or KMS URI used to sign the plugin inventory image (optional)")
+
+	_ = promoteCmd.MarkFlagRequired("source-repository")
+	_ = promoteCmd.MarkFlagRequired("repository")
+	promoteCmd.MarkFlagsRequiredTogether("plugin", "version")
+	promoteCmd.MarkFlagsOneRequired("plugin", "plugin-group")
+	promoteCmd.MarkFlagsMutuallyExclusive("plugin", "plugin-group")
+	promoteCmd.MarkFlagsMutuallyExclusive("target", "plugin-group")
+
+	return promoteCmd
+}
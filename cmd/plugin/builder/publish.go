@@ -0,0 +1,114 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/crane"
+	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/publish"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cosignhelper"
+)
+
+type publishFlags struct {
+	PackageArtifactDir      string
+	Repository              string
+	InventoryImageTag       string
+	Publisher               string
+	Vendor                  string
+	PluginGroupManifestFile string
+	GroupName               string
+	GroupVersion            string
+	GroupDescription        string
+	Key                     string
+	MultiArch               bool
+	MirrorRepositories      []string
+	DryRun                  bool
+}
+
+// newPublishCmd creates a new command to publish plugins in a single operation
+func newPublishCmd() *cobra.Command {
+	var pFlags = &publishFlags{}
+
+	var publishCmd = &cobra.Command{
+		Use:   "publish",
+		Short: "Publish plugin packages and update the plugin inventory of the repository",
+		Long: `Publish the plugin packages to the repository, add the plugins and the plugin-group to the plugin
+inventory database, update the plugin inventory metadata database if the repository has one and sign
+the plugin inventory database image. Publishing the same plugins again is a no-op so the command can
+safely be run again if it fails.
+
+When mirror repositories are specified, the plugins are published to all the repositories with
+all-or-nothing semantics: no plugin inventory is updated unless the plugin packages are published
+to all the repositories, and the plugin inventories already updated are restored if the update or
+the signing of another one fails.`,
+		SilenceUsage: true,
+		Example: `
+    # Publish the plugin packages and add the plugins to the plugin inventory
+    tanzu builder publish --package-artifacts ./artifacts/packages --repository localhost:5002/test/v1/tanzu-cli/plugins --vendor vmware --publisher tkg
+
+    # Publish the plugin packages, add the plugins to a plugin-group and sign the plugin inventory
+    COSIGN_PASSWORD=<password> tanzu builder publish --package-artifacts ./artifacts/packages --repository localhost:5002/test/v1/tanzu-cli/plugins --vendor vmware --publisher tkg \
+        --plugin-group-manifest ./artifacts/plugins/plugin_group_manifest.yaml --name default --version v1.0.0 --description "Plugins required by Tanzu Kubernetes Grid" \
+        --key ./cosign.key
+
+    # Publish the plugin packages to the repository and its disaster recovery mirror
+    tanzu builder publish --package-artifacts ./artifacts/packages --repository localhost:5002/test/v1/tanzu-cli/plugins --vendor vmware --publisher tkg \
+        --mirror-repository localhost:5003/test/v1/tanzu-cli/plugins
+
+    # Report the changes the publish would make to the plugin inventory without publishing anything
+    tanzu builder publish --package-artifacts ./artifacts/packages --repository localhost:5002/test/v1/tanzu-cli/plugins --vendor vmware --publisher tkg --dry-run`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			po := &publish.PublishOptions{
+				PackageArtifactDir:      pFlags.PackageArtifactDir,
+				Repository:              pFlags.Repository,
+				InventoryImageTag:       pFlags.InventoryImageTag,
+				Publisher:               pFlags.Publisher,
+				Vendor:                  pFlags.Vendor,
+				PluginGroupManifestFile: pFlags.PluginGroupManifestFile,
+				GroupName:               pFlags.GroupName,
+				GroupVersion:            pFlags.GroupVersion,
+				GroupDescription:        pFlags.GroupDescription,
+				MultiArch:               pFlags.MultiArch,
+				MirrorRepositories:      pFlags.MirrorRepositories,
+				DryRun:                  pFlags.DryRun,
+				ImageOperationsImpl:     carvelhelpers.NewImageOperationsImpl(),
+				CraneOptions:            crane.NewCraneWrapper(),
+			}
+			if pFlags.Key != "" {
+				// The repositories are signed with their own registry options, e.g. the mirrors in other registries
+				po.NewCosignSigner = func(repository string) (cosignhelper.CosignSigner, error) {
+					registryOpts, err := getCosignRegistryOptions(repository)
+					if err != nil {
+						return nil, err
+					}
+					return cosignhelper.NewCosignSigner(pFlags.Key, registryOpts), nil
+				}
+			}
+			return po.Publish()
+		},
+	}
+
+	publishCmd.Flags().StringVarP(&pFlags.PackageArtifactDir, "package-artifacts", "", "./artifacts/packages", "plugin package artifacts directory")
+	publishCmd.Flags().StringVarP(&pFlags.Repository, "repository", "", "", "repository to publish plugins and plugin inventory image")
+	publishCmd.Flags().StringVarP(&pFlags.InventoryImageTag, "plugin-inventory-image-tag", "", "latest", "tag to which plugin inventory image needs to be published")
+	publishCmd.Flags().StringVarP(&pFlags.Vendor, "vendor", "", "", "name of the vendor")
+	publishCmd.Flags().StringVarP(&pFlags.Publisher, "publisher", "", "", "name of the publisher")
+	publishCmd.Flags().StringVarP(&pFlags.PluginGroupManifestFile, "plugin-group-manifest", "", "", "manifest file specifying the plugins of the plugin-group (optional)")
+	publishCmd.Flags().StringVarP(&pFlags.GroupName, "name", "", "", "name of the plugin-group")
+	publishCmd.Flags().StringVarP(&pFlags.GroupVersion, "version", "", "", "version of the plugin-group")
+	publishCmd.Flags().StringVarP(&pFlags.GroupDescription, "description", "", "", "a description for the plugin-group")
+	publishCmd.Flags().StringVarP(&pFlags.Key, "key", "", "", "path to the cosign private key or KMS URI used to sign the plugin inventory image (optional)")
+	publishCmd.Flags().BoolVarP(&pFlags.MultiArch, "multi-arch", "", false, "also publish a multi-arch image index for every plugin version and add it to the inventory")
+	publishCmd.Flags().StringSliceVarP(&pFlags.MirrorRepositories, "mirror-repository", "", nil, "repository to which the plugins are also published, can be specified multiple times (optional)")
+	publishCmd.Flags().BoolVarP(&pFlags.DryRun, "dry-run", "", false, "report the changes to the plugin inventory of the repository without publishing anything, failing if released plugin binaries would be overwritten")
+
+	_ = publishCmd.MarkFlagRequired("repository")
+	_ = publishCmd.MarkFlagRequired("vendor")
+	_ = publishCmd.MarkFlagRequired("publisher")
+	publishCmd.MarkFlagsRequiredTogether("plugin-group-manifest", "name", "version")
+
+	return publishCmd
+}
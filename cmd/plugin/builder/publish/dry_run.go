@@ -0,0 +1,254 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package publish
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/helpers"
+	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/inventory"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+)
+
+// getFileDigestFromImageTar is used to compute the digests of the plugin binaries of the local
+// plugin packages. It is a variable so that the tests can replace it.
+var getFileDigestFromImageTar = carvelhelpers.GetFileDigestFromImageTar
+
+// localPackageImageOperations resolves the plugin images to the local plugin packages so that
+// the inventory database can be updated as if the plugin packages had been published
+type localPackageImageOperations struct {
+	carvelhelpers.ImageOperationsImpl
+	// packageFiles maps the plugin images to the plugin package files
+	packageFiles map[string]string
+	// indexImages maps the multi-arch image indexes which would be published to their platform images
+	indexImages map[string][]carvelhelpers.PlatformImage
+}
+
+// ResolveImage succeeds for the image indexes which would be published
+func (l *localPackageImageOperations) ResolveImage(image string) error {
+	if _, exists := l.indexImages[image]; exists {
+		return nil
+	}
+	return l.ImageOperationsImpl.ResolveImage(image)
+}
+
+// GetImageIndexPlatformImages returns the platform images of the image index which would be published.
+// As they are not published yet, the platform images are referred to by tag rather than by digest.
+func (l *localPackageImageOperations) GetImageIndexPlatformImages(indexImage string) ([]carvelhelpers.PlatformImage, error) {
+	platformImages, exists := l.indexImages[indexImage]
+	if !exists {
+		return nil, errors.Errorf("no plugin packages found for image index %q", indexImage)
+	}
+	return platformImages, nil
+}
+
+// GetFileDigestFromImage returns the digest of the plugin binary of the local plugin package of the image
+func (l *localPackageImageOperations) GetFileDigestFromImage(image, fileName string) (string, error) {
+	packageFile, exists := l.packageFiles[image]
+	if !exists {
+		return "", errors.Errorf("no plugin package found for image %q", image)
+	}
+	return getFileDigestFromImageTar(packageFile, fileName)
+}
+
+// newLocalPackageImageOperations returns the image operations resolving the plugin images to the plugin packages
+func (po *PublishOptions) newLocalPackageImageOperations() (*localPackageImageOperations, error) {
+	pluginManifest, err := helpers.ReadPluginManifest(filepath.Join(po.PackageArtifactDir, cli.PluginManifestFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	l := &localPackageImageOperations{
+		ImageOperationsImpl: po.ImageOperationsImpl,
+		packageFiles:        map[string]string{},
+		indexImages:         map[string][]carvelhelpers.PlatformImage{},
+	}
+	for _, p := range pluginManifest.Plugins {
+		for _, version := range p.Versions {
+			for _, osArch := range cli.AllOSArch {
+				packageFile := filepath.Join(po.PackageArtifactDir, helpers.GetPluginArchiveRelativePath(p, osArch, version))
+				if !utils.PathExists(packageFile) {
+					continue
+				}
+				image := fmt.Sprintf("%s/%s/%s/%s/%s/%s/%s:%s", po.Repository, po.Vendor, po.Publisher, osArch.OS(), osArch.Arch(), p.Target, p.Name, version)
+				l.packageFiles[image] = packageFile
+				if po.MultiArch {
+					indexImage := fmt.Sprintf("%s/%s/%s/%s/%s:%s", po.Repository, po.Vendor, po.Publisher, p.Target, p.Name, version)
+					l.indexImages[indexImage] = append(l.indexImages[indexImage], carvelhelpers.PlatformImage{Image: image, OS: osArch.OS(), Arch: osArch.Arch()})
+				}
+			}
+		}
+	}
+	return l, nil
+}
+
+// dryRun reports the changes the publish would make to the inventory database of the repository
+// without publishing anything. An error is returned if the publish would overwrite the binaries
+// of plugin versions which are already in the inventory database.
+func (po *PublishOptions) dryRun() error {
+	tempDir, err := os.MkdirTemp("", "")
+	if err != nil {
+		return errors.Wrap(err, "unable to create temporary directory")
+	}
+	defer os.RemoveAll(tempDir)
+
+	dbFile, err := po.getInventoryDBFile(tempDir)
+	if err != nil {
+		return err
+	}
+	currentDBFile := filepath.Join(tempDir, "current_"+plugininventory.SQliteDBFileName)
+	if err := utils.CopyFile(dbFile, currentDBFile); err != nil {
+		return errors.Wrap(err, "unable to copy the inventory database")
+	}
+
+	localImageOperations, err := po.newLocalPackageImageOperations()
+	if err != nil {
+		return err
+	}
+
+	// The plugins which are already in the inventory database are not added again by the publish
+	// so the plugins of the packages are added to an empty database to compare their digests
+	packagesDBFile := filepath.Join(tempDir, "packages_"+plugininventory.SQliteDBFileName)
+	iio := &inventory.InventoryInitOptions{InventoryDBFile: packagesDBFile}
+	if err := iio.InitializeInventory(); err != nil {
+		return err
+	}
+	ipuo := po.getInventoryPluginUpdateOptions(packagesDBFile, localImageOperations)
+	ipuo.SkipExisting = false
+	if err := ipuo.PluginAdd(); err != nil {
+		return err
+	}
+	// The publish fails on the first overwritten plugin binary, all of them are reported beforehand
+	overwrites, err := reportOverwrites(currentDBFile, packagesDBFile)
+	if err != nil {
+		return err
+	}
+	if overwrites > 0 {
+		return errors.Errorf("publishing would overwrite %d plugin binaries already in the inventory database of %q", overwrites, po.Repository)
+	}
+
+	if err := po.updateInventoryDB(dbFile, localImageOperations); err != nil {
+		return err
+	}
+	if err := reportInventoryChanges(currentDBFile, dbFile); err != nil {
+		return err
+	}
+	log.Infof("dry run completed, nothing was published to %q", po.Repository)
+	return nil
+}
+
+// reportOverwrites logs the plugin binaries of the packages which would overwrite the ones
+// in the inventory database and returns their number
+func reportOverwrites(currentDBFile, packagesDBFile string) (int, error) {
+	currentArtifacts, err := getInventoryArtifacts(currentDBFile)
+	if err != nil {
+		return 0, err
+	}
+	packagesArtifacts, err := getInventoryArtifacts(packagesDBFile)
+	if err != nil {
+		return 0, err
+	}
+	overwrites := 0
+	for _, id := range sortedKeys(packagesArtifacts) {
+		current, exists := currentArtifacts[id]
+		if !exists || current.Digest == "" || current.Digest == packagesArtifacts[id].Digest {
+			continue
+		}
+		log.Warningf("overwritten plugin binary: %s (digest %s would be replaced by %s)", id, current.Digest, packagesArtifacts[id].Digest)
+		overwrites++
+	}
+	return overwrites, nil
+}
+
+// reportInventoryChanges logs the plugin artifacts and the plugin-group versions added by the publish
+func reportInventoryChanges(currentDBFile, updatedDBFile string) error {
+	currentArtifacts, err := getInventoryArtifacts(currentDBFile)
+	if err != nil {
+		return err
+	}
+	updatedArtifacts, err := getInventoryArtifacts(updatedDBFile)
+	if err != nil {
+		return err
+	}
+	for _, id := range sortedKeys(updatedArtifacts) {
+		if _, exists := currentArtifacts[id]; !exists {
+			log.Infof("new plugin artifact: %s", id)
+		}
+	}
+
+	currentGroups, err := getInventoryGroupVersions(currentDBFile)
+	if err != nil {
+		return err
+	}
+	updatedGroups, err := getInventoryGroupVersions(updatedDBFile)
+	if err != nil {
+		return err
+	}
+	for _, id := range sortedKeys(updatedGroups) {
+		if _, exists := currentGroups[id]; !exists {
+			log.Infof("new plugin-group version: %s (plugins: %s)", id, updatedGroups[id])
+		}
+	}
+	return nil
+}
+
+// getInventoryArtifacts returns the artifacts of the inventory database by plugin name, target, version and platform
+func getInventoryArtifacts(dbFile string) (map[string]distribution.Artifact, error) {
+	plugins, err := plugininventory.NewSQLiteInventory(dbFile, "").GetPlugins(context.Background(), &plugininventory.PluginInventoryFilter{IncludeHidden: true})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error while reading the plugins of the inventory database %q", dbFile)
+	}
+	artifacts := map[string]distribution.Artifact{}
+	for _, p := range plugins {
+		for version, artifactList := range p.Artifacts {
+			for _, a := range artifactList {
+				artifacts[fmt.Sprintf("'%s_%s:%s' %s/%s", p.Name, p.Target, version, a.OS, a.Arch)] = a
+			}
+		}
+	}
+	return artifacts, nil
+}
+
+// getInventoryGroupVersions returns the plugins of the plugin-group versions of the inventory database
+func getInventoryGroupVersions(dbFile string) (map[string]string, error) {
+	groups, err := plugininventory.NewSQLiteInventory(dbFile, "").GetPluginGroups(context.Background(), plugininventory.PluginGroupFilter{IncludeHidden: true})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error while reading the plugin groups of the inventory database %q", dbFile)
+	}
+	groupVersions := map[string]string{}
+	for _, pg := range groups {
+		for version, plugins := range pg.Versions {
+			var pluginIDs []string
+			for _, p := range plugins {
+				pluginIDs = append(pluginIDs, fmt.Sprintf("%s_%s:%s", p.Name, p.Target, p.Version))
+			}
+			sort.Strings(pluginIDs)
+			groupVersions[fmt.Sprintf("'%s:%s'", plugininventory.PluginGroupToID(pg), version)] = strings.Join(pluginIDs, ", ")
+		}
+	}
+	return groupVersions, nil
+}
+
+// sortedKeys returns the keys of the map in order so that the report is stable
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,326 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package publish
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/crane"
+	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/helpers"
+	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/inventory"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cosignhelper"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+)
+
+// PromoteOptions defines options for promoting a plugin version, or a plugin-group version and
+// its plugins, from a source repository, e.g. a staging repository, to the repository
+type PromoteOptions struct {
+	SourceRepository        string
+	SourceInventoryImageTag string
+	Repository              string
+	InventoryImageTag       string
+	// PluginName, PluginTarget and PluginVersion identify the plugin version to promote.
+	// The plugin is promoted for all its targets if PluginTarget is empty.
+	PluginName    string
+	PluginTarget  string
+	PluginVersion string
+	// PluginGroupID identifies the plugin-group version to promote, e.g. vmware-tkg/default:v1.0.0
+	PluginGroupID string
+
+	ImageOperationsImpl carvelhelpers.ImageOperationsImpl
+	CraneOptions        crane.CraneWrapper
+	// CosignSigner signs the inventory database image once published. The image is not signed if nil.
+	CosignSigner cosignhelper.CosignSigner
+}
+
+// Promote copies the plugin images from the source repository to the repository by digest, so that
+// the promoted plugin binaries are byte-identical to the ones of the source repository, then adds
+// the plugins and the plugin-group to the inventory database and the inventory metadata database,
+// if any, of the repository and signs the inventory database image.
+// Promoting the same plugins again is a no-op so a failed promotion can simply be retried.
+func (pro *PromoteOptions) Promote() error {
+	tempDir, err := os.MkdirTemp("", "")
+	if err != nil {
+		return errors.Wrap(err, "unable to create temporary directory")
+	}
+	defer os.RemoveAll(tempDir)
+
+	sourceDir := filepath.Join(tempDir, "source")
+	sourceDBImage := fmt.Sprintf("%s/%s:%s", pro.SourceRepository, helpers.PluginInventoryDBImageName, pro.SourceInventoryImageTag)
+	log.Infof("pulling plugin inventory database from: %q", sourceDBImage)
+	if err := pro.ImageOperationsImpl.DownloadImageAndSaveFilesToDir(sourceDBImage, sourceDir); err != nil {
+		return errors.Wrapf(err, "error while pulling database from the image: %q", sourceDBImage)
+	}
+	plugins, group, err := pro.getEntriesToPromote(filepath.Join(sourceDir, plugininventory.SQliteDBFileName))
+	if err != nil {
+		return err
+	}
+
+	var pluginIDs []*plugininventory.PluginIdentifier
+	for _, p := range plugins {
+		for version := range p.Artifacts {
+			pluginIDs = append(pluginIDs, &plugininventory.PluginIdentifier{Name: p.Name, Target: p.Target, Version: version})
+		}
+	}
+	var groupIDs []*plugininventory.PluginGroupIdentifier
+	if group != nil {
+		for version := range group.Versions {
+			groupIDs = append(groupIDs, &plugininventory.PluginGroupIdentifier{Vendor: group.Vendor, Publisher: group.Publisher, Name: group.Name, Version: version})
+		}
+	}
+
+	po := &PublishOptions{
+		Repository:          pro.Repository,
+		InventoryImageTag:   pro.InventoryImageTag,
+		ImageOperationsImpl: pro.ImageOperationsImpl,
+		CraneOptions:        pro.CraneOptions,
+		CosignSigner:        pro.CosignSigner,
+	}
+	targetDir := filepath.Join(tempDir, "target")
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		return errors.Wrap(err, "unable to create temporary directory")
+	}
+	dbFile, err := po.getInventoryDBFile(targetDir)
+	if err != nil {
+		return err
+	}
+
+	// The conflicts with the repository are checked before copying any image so that
+	// a conflicting promotion leaves the repository untouched
+	db := plugininventory.NewSQLiteInventory(dbFile, "")
+	pluginsToAdd, groupToAdd, err := getPromotedEntriesToAdd(db, plugins, group)
+	if err != nil {
+		return err
+	}
+	for _, p := range pluginsToAdd {
+		for _, artifacts := range p.Artifacts {
+			for _, a := range artifacts {
+				// Artifacts downloaded from a URI are not stored in the source repository,
+				// they are promoted with the same URI
+				if a.Image == "" {
+					continue
+				}
+				if err := pro.copyImage(a.Image); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if err := addPromotedEntries(db, pluginsToAdd, groupToAdd); err != nil {
+		return err
+	}
+	ivo := &inventory.InventoryValidateOptions{InventoryDBFile: dbFile}
+	if err := ivo.ValidateInventory(); err != nil {
+		return err
+	}
+	if err := po.publishInventoryDB(dbFile); err != nil {
+		return err
+	}
+
+	if err := po.updateInventoryMetadataDB(filepath.Join(targetDir, "metadata"), pluginIDs, groupIDs); err != nil {
+		return err
+	}
+	if err := po.signInventory(); err != nil {
+		return err
+	}
+
+	log.Infof("successfully promoted plugins from %q to %q", pro.SourceRepository, pro.Repository)
+	return nil
+}
+
+// getEntriesToPromote returns the plugin versions to promote and the plugin-group version to promote,
+// if any, from the source inventory database. The entries only contain the versions to promote.
+func (pro *PromoteOptions) getEntriesToPromote(sourceDBFile string) ([]*plugininventory.PluginInventoryEntry, *plugininventory.PluginGroup, error) {
+	db := plugininventory.NewSQLiteInventory(sourceDBFile, "")
+	if pro.PluginGroupID == "" {
+		var target configtypes.Target
+		if pro.PluginTarget != "" {
+			target = configtypes.StringToTarget(pro.PluginTarget)
+		}
+		plugins, err := getPluginVersion(db, pro.PluginName, target, pro.PluginVersion)
+		if err != nil {
+			return nil, nil, err
+		}
+		return plugins, nil, nil
+	}
+
+	pgi := plugininventory.PluginGroupIdentifierFromID(pro.PluginGroupID)
+	if pgi == nil || pgi.Version == "" {
+		return nil, nil, errors.Errorf("invalid plugin-group %q, the expected format is vendor-publisher/name:version", pro.PluginGroupID)
+	}
+	groups, err := db.GetPluginGroups(context.Background(), plugininventory.PluginGroupFilter{Vendor: pgi.Vendor, Publisher: pgi.Publisher, Name: pgi.Name, Version: pgi.Version, IncludeHidden: true})
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "error while reading plugin-group %q", pro.PluginGroupID)
+	}
+	if len(groups) == 0 || len(groups[0].Versions[pgi.Version]) == 0 {
+		return nil, nil, errors.Errorf("plugin-group %q not found in the inventory database of %q", pro.PluginGroupID, pro.SourceRepository)
+	}
+	group := groups[0]
+	group.Versions = map[string][]*plugininventory.PluginGroupPluginEntry{pgi.Version: group.Versions[pgi.Version]}
+
+	var plugins []*plugininventory.PluginInventoryEntry
+	for _, pe := range group.Versions[pgi.Version] {
+		entries, err := getPluginVersion(db, pe.Name, pe.Target, pe.Version)
+		if err != nil {
+			return nil, nil, err
+		}
+		plugins = append(plugins, entries...)
+	}
+	return plugins, group, nil
+}
+
+// getPluginVersion returns the entries of the plugin only containing the specified version
+func getPluginVersion(db plugininventory.PluginInventory, name string, target configtypes.Target, version string) ([]*plugininventory.PluginInventoryEntry, error) {
+	plugins, err := db.GetPlugins(context.Background(), &plugininventory.PluginInventoryFilter{Name: name, Target: target, Version: version, IncludeHidden: true})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error while reading plugin %q", name)
+	}
+	var entries []*plugininventory.PluginInventoryEntry
+	for _, p := range plugins {
+		if len(p.Artifacts[version]) == 0 {
+			continue
+		}
+		// The inventory database is read without URI prefix, so the images are relative to the repository once trimmed
+		artifacts := p.Artifacts[version]
+		for i := range artifacts {
+			artifacts[i].Image = strings.TrimPrefix(artifacts[i].Image, "/")
+		}
+		p.Artifacts = distribution.Artifacts{version: artifacts}
+		p.RecommendedVersion = ""
+		entries = append(entries, p)
+	}
+	if len(entries) == 0 {
+		return nil, errors.Errorf("plugin '%s:%s' not found in the source inventory database", name, version)
+	}
+	return entries, nil
+}
+
+// copyImage copies the plugin image from the source repository to the repository by digest
+// and verifies the copied image has the same digest. Images already copied are skipped.
+func (pro *PromoteOptions) copyImage(image string) error {
+	sourceImage := fmt.Sprintf("%s/%s", pro.SourceRepository, image)
+	destImage := fmt.Sprintf("%s/%s", pro.Repository, image)
+
+	digest, err := pro.CraneOptions.GetImageDigest(sourceImage)
+	if err != nil {
+		return errors.Wrapf(err, "unable to resolve the digest of the image %q", sourceImage)
+	}
+	if destDigest, err := pro.CraneOptions.GetImageDigest(destImage); err == nil && destDigest == digest {
+		log.Infof("skipping image %q already promoted", destImage)
+		return nil
+	}
+
+	algorithm, hex, _ := strings.Cut(digest, ":")
+	log.Infof("promoting image %q to %q", carvelhelpers.ImageWithDigest(sourceImage, algorithm, hex), destImage)
+	if err := pro.CraneOptions.CopyImage(carvelhelpers.ImageWithDigest(sourceImage, algorithm, hex), destImage); err != nil {
+		return errors.Wrapf(err, "unable to copy the image %q to %q", sourceImage, destImage)
+	}
+
+	destDigest, err := pro.CraneOptions.GetImageDigest(destImage)
+	if err != nil {
+		return errors.Wrapf(err, "unable to resolve the digest of the image %q", destImage)
+	}
+	if destDigest != digest {
+		return errors.Errorf("the digest %s of the promoted image %q does not match the digest %s of the image %q", destDigest, destImage, digest, sourceImage)
+	}
+	return nil
+}
+
+// getPromotedEntriesToAdd returns the promoted plugins and plugin-group which are not in the inventory
+// database yet. Plugin binaries already in the inventory database are skipped if they have the same digest
+// and a plugin-group version already in the inventory database is skipped if it has the same plugins.
+// An error listing all the conflicts is returned otherwise, so that released plugin binaries and
+// plugin-groups are never overwritten.
+func getPromotedEntriesToAdd(db plugininventory.PluginInventory, plugins []*plugininventory.PluginInventoryEntry, group *plugininventory.PluginGroup) ([]*plugininventory.PluginInventoryEntry, *plugininventory.PluginGroup, error) {
+	var errs []error
+	var toAdd []*plugininventory.PluginInventoryEntry
+	for _, p := range plugins {
+		existing, err := db.GetPlugins(context.Background(), &plugininventory.PluginInventoryFilter{Name: p.Name, Target: p.Target, IncludeHidden: true})
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "error while reading plugin '%s_%s'", p.Name, p.Target)
+		}
+		entry := *p
+		entry.Artifacts = distribution.Artifacts{}
+		for version, artifacts := range p.Artifacts {
+			var artifactsToAdd distribution.ArtifactList
+			for _, a := range artifacts {
+				existingArtifact := findArtifact(existing, version, a.OS, a.Arch)
+				if existingArtifact == nil {
+					artifactsToAdd = append(artifactsToAdd, a)
+					continue
+				}
+				if existingArtifact.Digest != a.Digest {
+					errs = append(errs, errors.Errorf("plugin '%s_%s:%s' for %s/%s already exists with a different digest", p.Name, p.Target, version, a.OS, a.Arch))
+					continue
+				}
+				// A URI artifact is not copied, so the repository must point to the same location
+				if a.Image == "" && existingArtifact.URI != a.URI {
+					errs = append(errs, errors.Errorf("plugin '%s_%s:%s' for %s/%s already exists with a different URI", p.Name, p.Target, version, a.OS, a.Arch))
+				}
+			}
+			if len(artifactsToAdd) > 0 {
+				entry.Artifacts[version] = artifactsToAdd
+			}
+		}
+		if len(entry.Artifacts) > 0 {
+			toAdd = append(toAdd, &entry)
+		}
+	}
+
+	if group != nil {
+		for version := range group.Versions {
+			exists, err := inventory.PluginGroupVersionExists(db, group, version)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if exists {
+				group = nil
+				break
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, nil, errors.Wrap(kerrors.NewAggregate(errs), "the promotion conflicts with the inventory database of the repository")
+	}
+	return toAdd, group, nil
+}
+
+// addPromotedEntries adds the promoted plugins and plugin-group to the inventory database
+func addPromotedEntries(db plugininventory.PluginInventory, plugins []*plugininventory.PluginInventoryEntry, group *plugininventory.PluginGroup) error {
+	if err := db.InsertPlugins(context.Background(), plugins); err != nil {
+		return errors.Wrap(err, "error while inserting plugins")
+	}
+	if group != nil {
+		if err := db.InsertPluginGroup(context.Background(), group, false); err != nil {
+			return errors.Wrapf(err, "error while inserting plugin-group %q", plugininventory.PluginGroupToID(group))
+		}
+	}
+	return nil
+}
+
+// findArtifact returns the artifact of the plugin version for the platform, nil if not found
+func findArtifact(plugins []*plugininventory.PluginInventoryEntry, version, os, arch string) *distribution.Artifact {
+	for _, p := range plugins {
+		for i := range p.Artifacts[version] {
+			if p.Artifacts[version][i].OS == os && p.Artifacts[version][i].Arch == arch {
+				return &p.Artifacts[version][i]
+			}
+		}
+	}
+	return nil
+}
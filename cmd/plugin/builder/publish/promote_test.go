@@ -0,0 +1,281 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package publish
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/fakes"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+)
+
+var _ = Describe("Unit tests for promote", func() {
+	const fooImage = "vmware/tkg/linux/amd64/global/foo:v0.0.1"
+
+	var (
+		dir                 string
+		pro                 *PromoteOptions
+		fakeImageOperations *fakes.ImageOperationsImpl
+		fakeCrane           *fakeCraneWrapper
+		fakeCosignSigner    *fakes.CosignSignerFake
+		sourceDBFile        string
+		targetDBFile        string
+		publishedDBFile     string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		sourceDBFile = filepath.Join(dir, "source.db")
+		db := plugininventory.NewSQLiteInventory(sourceDBFile, "")
+		Expect(db.CreateSchema(context.Background())).To(Succeed())
+		for _, version := range []string{"v0.0.1", "v0.0.2"} {
+			Expect(db.InsertPlugin(context.Background(), &plugininventory.PluginInventoryEntry{
+				Name:        "foo",
+				Target:      types.TargetGlobal,
+				Description: "foo plugin",
+				Publisher:   "tkg",
+				Vendor:      "vmware",
+				Artifacts: distribution.Artifacts{
+					version: []distribution.Artifact{{OS: "linux", Arch: "amd64", Digest: "fake-digest", Image: "vmware/tkg/linux/amd64/global/foo:" + version}},
+				},
+			})).To(Succeed())
+		}
+		Expect(db.InsertPluginGroup(context.Background(), &plugininventory.PluginGroup{
+			Vendor:      "vmware",
+			Publisher:   "tkg",
+			Name:        "default",
+			Description: "default group",
+			Versions: map[string][]*plugininventory.PluginGroupPluginEntry{
+				"v1.0.0": {{PluginIdentifier: plugininventory.PluginIdentifier{Name: "foo", Target: types.TargetGlobal, Version: "v0.0.1"}}},
+			},
+		}, false)).To(Succeed())
+
+		targetDBFile = filepath.Join(dir, "target.db")
+		Expect(plugininventory.NewSQLiteInventory(targetDBFile, "").CreateSchema(context.Background())).To(Succeed())
+		publishedDBFile = filepath.Join(dir, "published.db")
+
+		fakeImageOperations = &fakes.ImageOperationsImpl{}
+		fakeImageOperations.ResolveImageStub = func(image string) error {
+			if strings.Contains(image, "plugin-inventory:") {
+				return nil
+			}
+			return errors.New("image not found")
+		}
+		fakeImageOperations.DownloadImageAndSaveFilesToDirStub = func(image, path string) error {
+			Expect(os.MkdirAll(path, 0755)).To(Succeed())
+			if strings.HasPrefix(image, "staging-repo.com/") {
+				return utils.CopyFile(sourceDBFile, filepath.Join(path, plugininventory.SQliteDBFileName))
+			}
+			return utils.CopyFile(targetDBFile, filepath.Join(path, plugininventory.SQliteDBFileName))
+		}
+		fakeImageOperations.PushImageStub = func(image string, files []string) error {
+			return utils.CopyFile(files[0], publishedDBFile)
+		}
+		fakeCrane = &fakeCraneWrapper{publishedDigests: map[string]string{
+			"staging-repo.com/" + fooImage: "sha256:foo-digest",
+		}}
+		fakeCosignSigner = &fakes.CosignSignerFake{}
+
+		pro = &PromoteOptions{
+			SourceRepository:        "staging-repo.com",
+			SourceInventoryImageTag: "latest",
+			Repository:              "test-repo.com",
+			InventoryImageTag:       "latest",
+			PluginName:              "foo",
+			PluginVersion:           "v0.0.1",
+			ImageOperationsImpl:     fakeImageOperations,
+			CraneOptions:            fakeCrane,
+			CosignSigner:            fakeCosignSigner,
+		}
+	})
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	getPromotedPlugins := func() []*plugininventory.PluginInventoryEntry {
+		plugins, err := plugininventory.NewSQLiteInventory(publishedDBFile, "").GetPlugins(context.Background(), &plugininventory.PluginInventoryFilter{IncludeHidden: true})
+		Expect(err).NotTo(HaveOccurred())
+		return plugins
+	}
+
+	var _ = Context("tests for the promote function", func() {
+		var _ = It("when a plugin version is promoted", func() {
+			Expect(pro.Promote()).To(Succeed())
+
+			Expect(fakeCrane.copiedImages).To(ConsistOf("staging-repo.com/" + fooImage + "@sha256:foo-digest"))
+			plugins := getPromotedPlugins()
+			Expect(plugins).To(HaveLen(1))
+			Expect(plugins[0].Artifacts).To(HaveLen(1))
+			Expect(plugins[0].Artifacts["v0.0.1"]).To(HaveLen(1))
+			Expect(plugins[0].Artifacts["v0.0.1"][0].Digest).To(Equal("fake-digest"))
+			Expect(plugins[0].Artifacts["v0.0.1"][0].Image).To(Equal("/" + fooImage))
+			Expect(fakeCosignSigner.SignCallCount()).To(Equal(1))
+		})
+
+		var _ = It("when a plugin version with a URI artifact is promoted", func() {
+			Expect(plugininventory.NewSQLiteInventory(sourceDBFile, "").InsertPlugin(context.Background(), &plugininventory.PluginInventoryEntry{
+				Name: "foo", Target: types.TargetGlobal, Description: "foo plugin", Publisher: "tkg", Vendor: "vmware",
+				Artifacts: distribution.Artifacts{
+					"v0.0.1": []distribution.Artifact{{OS: "darwin", Arch: "amd64", Digest: "uri-digest", URI: "https://example.com/foo/darwin/amd64/foo"}},
+				},
+			})).To(Succeed())
+			Expect(pro.Promote()).To(Succeed())
+
+			// Only the image is copied, the URI artifact is promoted with its URI
+			Expect(fakeCrane.copiedImages).To(ConsistOf("staging-repo.com/" + fooImage + "@sha256:foo-digest"))
+			plugins := getPromotedPlugins()
+			Expect(plugins).To(HaveLen(1))
+			Expect(plugins[0].Artifacts["v0.0.1"]).To(HaveLen(2))
+			for _, a := range plugins[0].Artifacts["v0.0.1"] {
+				if a.OS == "darwin" {
+					Expect(a.Image).To(BeEmpty())
+					Expect(a.URI).To(Equal("https://example.com/foo/darwin/amd64/foo"))
+					Expect(a.Digest).To(Equal("uri-digest"))
+				}
+			}
+		})
+
+		var _ = It("when a URI artifact exists in the repository with a different URI", func() {
+			entry := &plugininventory.PluginInventoryEntry{
+				Name: "foo", Target: types.TargetGlobal, Description: "foo plugin", Publisher: "tkg", Vendor: "vmware",
+				Artifacts: distribution.Artifacts{
+					"v0.0.1": []distribution.Artifact{{OS: "darwin", Arch: "amd64", Digest: "uri-digest", URI: "https://example.com/foo/darwin/amd64/foo"}},
+				},
+			}
+			Expect(plugininventory.NewSQLiteInventory(sourceDBFile, "").InsertPlugin(context.Background(), entry)).To(Succeed())
+			entry.Artifacts["v0.0.1"][0].URI = "https://other.example.com/foo"
+			Expect(plugininventory.NewSQLiteInventory(targetDBFile, "").InsertPlugin(context.Background(), entry)).To(Succeed())
+
+			err := pro.Promote()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("already exists with a different URI"))
+			Expect(fakeCrane.copiedImages).To(BeEmpty())
+			Expect(fakeImageOperations.PushImageCallCount()).To(Equal(0))
+		})
+
+		var _ = It("when a plugin-group version is promoted", func() {
+			pro.PluginName = ""
+			pro.PluginVersion = ""
+			pro.PluginGroupID = "vmware-tkg/default:v1.0.0"
+			Expect(pro.Promote()).To(Succeed())
+
+			Expect(fakeCrane.copiedImages).To(HaveLen(1))
+			Expect(getPromotedPlugins()).To(HaveLen(1))
+			groups, err := plugininventory.NewSQLiteInventory(publishedDBFile, "").GetPluginGroups(context.Background(), plugininventory.PluginGroupFilter{IncludeHidden: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(groups).To(HaveLen(1))
+			Expect(groups[0].Versions).To(HaveKey("v1.0.0"))
+		})
+
+		var _ = It("when the plugin version was already promoted", func() {
+			Expect(pro.Promote()).To(Succeed())
+			Expect(utils.CopyFile(publishedDBFile, targetDBFile)).To(Succeed())
+
+			Expect(pro.Promote()).To(Succeed())
+			Expect(fakeCrane.copiedImages).To(HaveLen(1))
+			Expect(getPromotedPlugins()).To(HaveLen(1))
+		})
+
+		var _ = It("when the plugin version exists in the repository with a different digest", func() {
+			Expect(pro.Promote()).To(Succeed())
+			Expect(utils.CopyFile(publishedDBFile, targetDBFile)).To(Succeed())
+			sourceDBFile = filepath.Join(dir, "rebuilt.db")
+			rebuilt := plugininventory.NewSQLiteInventory(sourceDBFile, "")
+			Expect(rebuilt.CreateSchema(context.Background())).To(Succeed())
+			Expect(rebuilt.InsertPlugin(context.Background(), &plugininventory.PluginInventoryEntry{
+				Name: "foo", Target: types.TargetGlobal, Description: "foo plugin", Publisher: "tkg", Vendor: "vmware",
+				Artifacts: distribution.Artifacts{
+					"v0.0.1": []distribution.Artifact{{OS: "linux", Arch: "amd64", Digest: "other-digest", Image: fooImage}},
+				},
+			})).To(Succeed())
+			fakeCrane.publishedDigests["staging-repo.com/"+fooImage] = "sha256:rebuilt-digest"
+			pushCount := fakeImageOperations.PushImageCallCount()
+
+			err := pro.Promote()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("already exists with a different digest"))
+			Expect(fakeImageOperations.PushImageCallCount()).To(Equal(pushCount))
+			// The image of the repository must not be overwritten
+			Expect(fakeCrane.copiedImages).To(HaveLen(1))
+			Expect(fakeCrane.publishedDigests["test-repo.com/"+fooImage]).To(Equal("sha256:foo-digest"))
+		})
+
+		var _ = It("when the plugin-group version exists in the repository with different plugins", func() {
+			target := plugininventory.NewSQLiteInventory(targetDBFile, "")
+			Expect(target.InsertPlugin(context.Background(), &plugininventory.PluginInventoryEntry{
+				Name: "foo", Target: types.TargetGlobal, Description: "foo plugin", Publisher: "tkg", Vendor: "vmware",
+				Artifacts: distribution.Artifacts{
+					"v0.0.2": []distribution.Artifact{{OS: "linux", Arch: "amd64", Digest: "fake-digest", Image: "/vmware/tkg/linux/amd64/global/foo:v0.0.2"}},
+				},
+			})).To(Succeed())
+			Expect(target.InsertPluginGroup(context.Background(), &plugininventory.PluginGroup{
+				Vendor:      "vmware",
+				Publisher:   "tkg",
+				Name:        "default",
+				Description: "default group",
+				Versions: map[string][]*plugininventory.PluginGroupPluginEntry{
+					"v1.0.0": {{PluginIdentifier: plugininventory.PluginIdentifier{Name: "foo", Target: types.TargetGlobal, Version: "v0.0.2"}}},
+				},
+			}, false)).To(Succeed())
+			pro.PluginName = ""
+			pro.PluginVersion = ""
+			pro.PluginGroupID = "vmware-tkg/default:v1.0.0"
+
+			err := pro.Promote()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("plugin group 'vmware-tkg/default:v1.0.0' is already in the inventory database with different plugins"))
+			Expect(fakeCrane.copiedImages).To(BeEmpty())
+			Expect(fakeImageOperations.PushImageCallCount()).To(Equal(0))
+		})
+
+		var _ = It("when the digest of the promoted image does not match", func() {
+			fakeCrane.publishedDigests["test-repo.com/"+fooImage] = "sha256:other-digest"
+			pro.CraneOptions = &mismatchingCraneWrapper{fakeCrane}
+
+			err := pro.Promote()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("does not match the digest"))
+			Expect(fakeImageOperations.PushImageCallCount()).To(Equal(0))
+		})
+
+		var _ = It("when the plugin version is not in the source repository", func() {
+			pro.PluginVersion = "v1.0.0"
+			err := pro.Promote()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("plugin 'foo:v1.0.0' not found in the source inventory database"))
+			Expect(fakeCrane.copiedImages).To(BeEmpty())
+		})
+
+		var _ = It("when the plugin-group is invalid", func() {
+			pro.PluginGroupID = "default"
+			err := pro.Promote()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid plugin-group"))
+		})
+	})
+})
+
+// mismatchingCraneWrapper copies the images without updating their digest in the repository
+type mismatchingCraneWrapper struct {
+	*fakeCraneWrapper
+}
+
+func (f *mismatchingCraneWrapper) CopyImage(src, _ string) error {
+	f.copiedImages = append(f.copiedImages, src)
+	return nil
+}
@@ -0,0 +1,412 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package publish implements the publishing of plugins to a repository in a single operation
+package publish
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/crane"
+	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/helpers"
+	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/inventory"
+	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/plugin"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/airgapped"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cosignhelper"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+)
+
+// PublishOptions defines options for publishing plugins and updating the
+// inventory of the repository in a single operation
+type PublishOptions struct {
+	PackageArtifactDir      string
+	Repository              string
+	InventoryImageTag       string
+	Publisher               string
+	Vendor                  string
+	PluginGroupManifestFile string
+	GroupName               string
+	GroupVersion            string
+	GroupDescription        string
+	MultiArch               bool
+	// MirrorRepositories are the repositories to which the plugins are also published,
+	// e.g. the disaster recovery or regional mirrors of the repository
+	MirrorRepositories []string
+	// DryRun reports the changes the publish would make to the inventory database
+	// of the repository instead of publishing the plugins
+	DryRun bool
+
+	ImageOperationsImpl carvelhelpers.ImageOperationsImpl
+	CraneOptions        crane.CraneWrapper
+	// CosignSigner signs the inventory database image once published. The image is not signed if nil.
+	CosignSigner cosignhelper.CosignSigner
+	// NewCosignSigner returns the signer of the inventory database image of a repository, so that every
+	// repository is accessed with its own registry options. CosignSigner is used for all the repositories if nil.
+	NewCosignSigner func(repository string) (cosignhelper.CosignSigner, error)
+}
+
+// Publish publishes the plugin packages, adds the plugins and the plugin-group to the
+// inventory database, updates the inventory metadata database if the repository
+// has one and signs the inventory database image.
+// When mirror repositories are specified, the plugins are published to all the repositories
+// with all-or-nothing semantics: the inventory databases are only published once the plugin
+// packages are published to all the repositories and the inventory databases of all the
+// repositories are prepared, and the inventory databases already published are restored
+// if the publishing, the update of the inventory metadata database or the signing of
+// another one fails.
+// Publishing the same plugins again is a no-op so a failed publish can simply be retried.
+func (po *PublishOptions) Publish() error {
+	repositories, err := po.getRepositoryPublishOptions()
+	if err != nil {
+		return err
+	}
+	if po.DryRun {
+		for _, rpo := range repositories {
+			if err := rpo.publishPluginPackages(); err != nil {
+				return err
+			}
+			if err := rpo.dryRun(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	states := make([]*repositoryPublishState, len(repositories))
+	for i, rpo := range repositories {
+		states[i] = &repositoryPublishState{repository: rpo.Repository}
+	}
+
+	for i, rpo := range repositories {
+		if err := rpo.publishPluginPackages(); err != nil {
+			reportPublishState(states)
+			return errors.Wrapf(err, "error while publishing the plugin packages to %q, no inventory database was updated", rpo.Repository)
+		}
+		states[i].packagesPublished = true
+	}
+
+	tempDir, err := os.MkdirTemp("", "")
+	if err != nil {
+		return errors.Wrap(err, "unable to create temporary directory")
+	}
+	defer os.RemoveAll(tempDir)
+
+	dirs := make([]string, len(repositories))
+	dbFiles := make([]string, len(repositories))
+	for i, rpo := range repositories {
+		dirs[i] = filepath.Join(tempDir, strconv.Itoa(i))
+		if err := os.MkdirAll(dirs[i], 0755); err != nil {
+			return errors.Wrap(err, "unable to create temporary directory")
+		}
+		dbFiles[i], states[i].previousDBFile, err = rpo.prepareInventoryDB(dirs[i])
+		if err != nil {
+			reportPublishState(states)
+			return errors.Wrapf(err, "error while updating the inventory database of %q, no inventory database was updated", rpo.Repository)
+		}
+	}
+
+	pluginIDs, groupIDs, err := po.getPublishedIdentifiers()
+	if err != nil {
+		return err
+	}
+	metadataDBFiles := make([]string, len(repositories))
+	for i, rpo := range repositories {
+		metadataDBFiles[i], states[i].previousMetadataDBFile, err = rpo.prepareInventoryMetadataDB(filepath.Join(dirs[i], "metadata"), pluginIDs, groupIDs)
+		if err != nil {
+			reportPublishState(states)
+			return errors.Wrapf(err, "error while updating the inventory metadata database of %q, no inventory database was updated", rpo.Repository)
+		}
+	}
+
+	for i, rpo := range repositories {
+		if err := rpo.publishInventoryDB(dbFiles[i]); err != nil {
+			rollbackInventoryDBs(repositories, states)
+			reportPublishState(states)
+			return err
+		}
+		states[i].inventoryPublished = true
+	}
+
+	for i, rpo := range repositories {
+		if err := rpo.publishInventoryMetadataAndSign(metadataDBFiles[i], states[i]); err != nil {
+			rollbackInventoryDBs(repositories, states)
+			reportPublishState(states)
+			return err
+		}
+	}
+
+	for _, rpo := range repositories {
+		log.Infof("successfully published plugins to %q", rpo.Repository)
+	}
+	return nil
+}
+
+// getRepositoryPublishOptions returns the options to publish the plugins to each repository
+func (po *PublishOptions) getRepositoryPublishOptions() ([]*PublishOptions, error) {
+	var repositories []*PublishOptions
+	for _, repository := range append([]string{po.Repository}, po.MirrorRepositories...) {
+		rpo := *po
+		rpo.Repository = repository
+		rpo.MirrorRepositories = nil
+		if po.NewCosignSigner != nil && !po.DryRun {
+			signer, err := po.NewCosignSigner(repository)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to create the signer of the inventory database of %q", repository)
+			}
+			rpo.CosignSigner = signer
+		}
+		repositories = append(repositories, &rpo)
+	}
+	return repositories, nil
+}
+
+// publishInventoryMetadataAndSign publishes the prepared inventory metadata database, if any, to the
+// repository and signs the inventory database image, recording the progress in the state
+func (po *PublishOptions) publishInventoryMetadataAndSign(metadataDBFile string, state *repositoryPublishState) error {
+	if metadataDBFile != "" {
+		if err := po.publishInventoryMetadataDB(metadataDBFile); err != nil {
+			return err
+		}
+		state.metadataPublished = true
+	}
+	if err := po.signInventory(); err != nil {
+		return err
+	}
+	state.inventorySigned = true
+	return nil
+}
+
+// publishPluginPackages publishes the plugin packages to the repository
+func (po *PublishOptions) publishPluginPackages() error {
+	ppo := &plugin.PublishPluginPackageOptions{
+		PackageArtifactDir: po.PackageArtifactDir,
+		Publisher:          po.Publisher,
+		Vendor:             po.Vendor,
+		Repository:         po.Repository,
+		MultiArch:          po.MultiArch,
+		DryRun:             po.DryRun,
+		CraneOptions:       po.CraneOptions,
+	}
+	return ppo.PublishPluginPackages()
+}
+
+// prepareInventoryDB downloads the inventory database of the repository to the directory and adds the
+// plugins and the plugin-group to it. The updated inventory database and a copy of the inventory database
+// before the update are returned.
+func (po *PublishOptions) prepareInventoryDB(dir string) (string, string, error) {
+	dbFile, err := po.getInventoryDBFile(dir)
+	if err != nil {
+		return "", "", err
+	}
+	// The copy keeps the name of the inventory database, which is the name of the file in the image
+	// once the copy is published again to restore the inventory database
+	previousDBFile := filepath.Join(dir, "previous", plugininventory.SQliteDBFileName)
+	if err := os.MkdirAll(filepath.Dir(previousDBFile), 0755); err != nil {
+		return "", "", errors.Wrap(err, "unable to create temporary directory")
+	}
+	if err := utils.CopyFile(dbFile, previousDBFile); err != nil {
+		return "", "", errors.Wrap(err, "unable to copy the inventory database")
+	}
+	if err := po.updateInventoryDB(dbFile, po.ImageOperationsImpl); err != nil {
+		return "", "", err
+	}
+	return dbFile, previousDBFile, nil
+}
+
+// publishInventoryDB publishes the inventory database to the repository
+func (po *PublishOptions) publishInventoryDB(dbFile string) error {
+	pluginInventoryDBImage := po.getPluginInventoryDBImagePath()
+	log.Infof("publishing plugin inventory database at: %q", pluginInventoryDBImage)
+	if err := po.ImageOperationsImpl.PushImage(pluginInventoryDBImage, []string{dbFile}); err != nil {
+		return errors.Wrapf(err, "error while publishing inventory database to the repository as image: %q", pluginInventoryDBImage)
+	}
+	inventory.PublishInventoryDBChunks(po.ImageOperationsImpl, pluginInventoryDBImage, dbFile)
+	return nil
+}
+
+// signInventory signs the inventory database image of the repository if a signer is configured
+func (po *PublishOptions) signInventory() error {
+	if po.CosignSigner == nil {
+		return nil
+	}
+	iso := &inventory.InventorySignOptions{
+		Repository:          po.Repository,
+		InventoryImageTag:   po.InventoryImageTag,
+		CosignSigner:        po.CosignSigner,
+		ImageOperationsImpl: po.ImageOperationsImpl,
+	}
+	return iso.SignInventory()
+}
+
+func (po *PublishOptions) getPluginInventoryDBImagePath() string {
+	return fmt.Sprintf("%s/%s:%s", po.Repository, helpers.PluginInventoryDBImageName, po.InventoryImageTag)
+}
+
+// getInventoryDBFile downloads the inventory database of the repository
+// or creates an empty inventory database if the repository has none
+func (po *PublishOptions) getInventoryDBFile(dir string) (string, error) {
+	pluginInventoryDBImage := po.getPluginInventoryDBImagePath()
+	if err := po.ImageOperationsImpl.ResolveImage(pluginInventoryDBImage); err != nil {
+		log.Infof("no plugin inventory database found at %q, creating a new one", pluginInventoryDBImage)
+		iio := &inventory.InventoryInitOptions{InventoryDBFile: filepath.Join(dir, plugininventory.SQliteDBFileName)}
+		return iio.InventoryDBFile, iio.InitializeInventory()
+	}
+
+	log.Infof("pulling plugin inventory database from: %q", pluginInventoryDBImage)
+	if err := po.ImageOperationsImpl.DownloadImageAndSaveFilesToDir(pluginInventoryDBImage, dir); err != nil {
+		return "", errors.Wrapf(err, "error while pulling database from the image: %q", pluginInventoryDBImage)
+	}
+	return filepath.Join(dir, plugininventory.SQliteDBFileName), nil
+}
+
+// getInventoryPluginUpdateOptions returns the options to add the plugins to the local inventory database
+func (po *PublishOptions) getInventoryPluginUpdateOptions(dbFile string, imageOperationsImpl carvelhelpers.ImageOperationsImpl) *inventory.InventoryPluginUpdateOptions {
+	return &inventory.InventoryPluginUpdateOptions{
+		Repository:          po.Repository,
+		InventoryImageTag:   po.InventoryImageTag,
+		ManifestFile:        filepath.Join(po.PackageArtifactDir, cli.PluginManifestFileName),
+		Publisher:           po.Publisher,
+		Vendor:              po.Vendor,
+		InventoryDBFile:     dbFile,
+		MultiArch:           po.MultiArch,
+		SkipExisting:        true,
+		ImageOperationsImpl: imageOperationsImpl,
+	}
+}
+
+// updateInventoryDB adds the plugins and the plugin-group to the local inventory database and validates it
+func (po *PublishOptions) updateInventoryDB(dbFile string, imageOperationsImpl carvelhelpers.ImageOperationsImpl) error {
+	ipuo := po.getInventoryPluginUpdateOptions(dbFile, imageOperationsImpl)
+	if err := ipuo.PluginAdd(); err != nil {
+		return err
+	}
+
+	if po.PluginGroupManifestFile != "" {
+		ipguo := &inventory.InventoryPluginGroupUpdateOptions{
+			Repository:              po.Repository,
+			InventoryImageTag:       po.InventoryImageTag,
+			PluginGroupManifestFile: po.PluginGroupManifestFile,
+			Publisher:               po.Publisher,
+			Vendor:                  po.Vendor,
+			GroupName:               po.GroupName,
+			GroupVersion:            po.GroupVersion,
+			Description:             po.GroupDescription,
+			InventoryDBFile:         dbFile,
+			SkipExisting:            true,
+			ImageOperationsImpl:     imageOperationsImpl,
+		}
+		if err := ipguo.PluginGroupAdd(); err != nil {
+			return err
+		}
+	}
+
+	ivo := &inventory.InventoryValidateOptions{InventoryDBFile: dbFile}
+	return ivo.ValidateInventory()
+}
+
+// updateInventoryMetadataDB adds the plugins and the plugin-groups to the inventory metadata
+// database of the repository, if any, so that they are not filtered out of the inventory
+func (po *PublishOptions) updateInventoryMetadataDB(dir string, pluginIDs []*plugininventory.PluginIdentifier, groupIDs []*plugininventory.PluginGroupIdentifier) error {
+	metadataDBFile, _, err := po.prepareInventoryMetadataDB(dir, pluginIDs, groupIDs)
+	if err != nil || metadataDBFile == "" {
+		return err
+	}
+	return po.publishInventoryMetadataDB(metadataDBFile)
+}
+
+// prepareInventoryMetadataDB downloads the inventory metadata database of the repository, if any, to the
+// directory and adds the plugins and the plugin-groups to it. The updated inventory metadata database and
+// a copy of the inventory metadata database before the update are returned, or empty paths if the
+// repository has no inventory metadata database.
+func (po *PublishOptions) prepareInventoryMetadataDB(dir string, pluginIDs []*plugininventory.PluginIdentifier, groupIDs []*plugininventory.PluginGroupIdentifier) (string, string, error) {
+	metadataImage, err := airgapped.GetPluginInventoryMetadataImage(po.getPluginInventoryDBImagePath())
+	if err != nil {
+		return "", "", err
+	}
+	if err := po.ImageOperationsImpl.ResolveImage(metadataImage); err != nil {
+		log.Infof("no plugin inventory metadata database found at %q, skipping its update", metadataImage)
+		return "", "", nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", errors.Wrap(err, "unable to create temporary directory")
+	}
+	log.Infof("pulling plugin inventory metadata database from: %q", metadataImage)
+	if err := po.ImageOperationsImpl.DownloadImageAndSaveFilesToDir(metadataImage, dir); err != nil {
+		return "", "", errors.Wrapf(err, "error while pulling database from the image: %q", metadataImage)
+	}
+	metadataDBFile := filepath.Join(dir, plugininventory.SQliteInventoryMetadataDBFileName)
+	previousMetadataDBFile := filepath.Join(dir, "previous", plugininventory.SQliteInventoryMetadataDBFileName)
+	if err := os.MkdirAll(filepath.Dir(previousMetadataDBFile), 0755); err != nil {
+		return "", "", errors.Wrap(err, "unable to create temporary directory")
+	}
+	if err := utils.CopyFile(metadataDBFile, previousMetadataDBFile); err != nil {
+		return "", "", errors.Wrap(err, "unable to copy the inventory metadata database")
+	}
+
+	// Merging the published entries is idempotent unlike inserting them in the existing database
+	publishedMetadataDBFile := filepath.Join(dir, "published_"+plugininventory.SQliteInventoryMetadataDBFileName)
+	if err := createInventoryMetadataDB(publishedMetadataDBFile, pluginIDs, groupIDs); err != nil {
+		return "", "", err
+	}
+	if err := plugininventory.NewSQLiteInventoryMetadata(metadataDBFile).MergeInventoryMetadataDatabase(publishedMetadataDBFile, plugininventory.MetadataMergePolicyUnion); err != nil {
+		return "", "", errors.Wrap(err, "error while updating the plugin inventory metadata database")
+	}
+	return metadataDBFile, previousMetadataDBFile, nil
+}
+
+// publishInventoryMetadataDB publishes the inventory metadata database to the repository
+func (po *PublishOptions) publishInventoryMetadataDB(metadataDBFile string) error {
+	metadataImage, err := airgapped.GetPluginInventoryMetadataImage(po.getPluginInventoryDBImagePath())
+	if err != nil {
+		return err
+	}
+	log.Infof("publishing plugin inventory metadata database at: %q", metadataImage)
+	if err := po.ImageOperationsImpl.PushImage(metadataImage, []string{metadataDBFile}); err != nil {
+		return errors.Wrapf(err, "error while publishing inventory metadata database to the repository as image: %q", metadataImage)
+	}
+	return nil
+}
+
+// getPublishedIdentifiers returns the identifiers of the published plugins and plugin-group
+func (po *PublishOptions) getPublishedIdentifiers() ([]*plugininventory.PluginIdentifier, []*plugininventory.PluginGroupIdentifier, error) {
+	pluginManifest, err := helpers.ReadPluginManifest(filepath.Join(po.PackageArtifactDir, cli.PluginManifestFileName))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var pluginIDs []*plugininventory.PluginIdentifier
+	for i := range pluginManifest.Plugins {
+		for _, version := range pluginManifest.Plugins[i].Versions {
+			pluginIDs = append(pluginIDs, &plugininventory.PluginIdentifier{Name: pluginManifest.Plugins[i].Name, Target: configtypes.StringToTarget(pluginManifest.Plugins[i].Target), Version: version})
+		}
+	}
+	var groupIDs []*plugininventory.PluginGroupIdentifier
+	if po.PluginGroupManifestFile != "" {
+		groupIDs = append(groupIDs, &plugininventory.PluginGroupIdentifier{Vendor: po.Vendor, Publisher: po.Publisher, Name: po.GroupName, Version: po.GroupVersion})
+	}
+	return pluginIDs, groupIDs, nil
+}
+
+// createInventoryMetadataDB creates an inventory metadata database with the specified plugins and plugin-groups
+func createInventoryMetadataDB(metadataDBFile string, pluginIDs []*plugininventory.PluginIdentifier, groupIDs []*plugininventory.PluginGroupIdentifier) error {
+	db := plugininventory.NewSQLiteInventoryMetadata(metadataDBFile)
+	if err := db.CreateInventoryMetadataDBSchema(); err != nil {
+		return err
+	}
+	if err := db.InsertPluginIdentifiers(pluginIDs); err != nil {
+		return err
+	}
+	return db.InsertPluginGroupIdentifiers(groupIDs)
+}
@@ -0,0 +1,455 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package publish
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+
+	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/helpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cosignhelper"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/fakes"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+)
+
+func TestPublishSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Builder Publish Suite")
+}
+
+// fakeCraneWrapper records the images pushed without accessing any registry
+type fakeCraneWrapper struct {
+	pushedImages []string
+	// publishedDigests contains the digests of the images already published
+	publishedDigests map[string]string
+	pushErr          error
+	// failingRepository is the repository to which the images cannot be pushed
+	failingRepository string
+	copiedImages      []string
+}
+
+func (f *fakeCraneWrapper) SaveImage(_, _ string) error {
+	return nil
+}
+
+func (f *fakeCraneWrapper) PushImage(_, image string) error {
+	if f.pushErr != nil {
+		return f.pushErr
+	}
+	if f.failingRepository != "" && strings.HasPrefix(image, f.failingRepository+"/") {
+		return errors.New("connection refused")
+	}
+	f.pushedImages = append(f.pushedImages, image)
+	return nil
+}
+
+func (f *fakeCraneWrapper) PushImageIndex(indexImage string, _ []carvelhelpers.PlatformImage) error {
+	f.pushedImages = append(f.pushedImages, indexImage)
+	return nil
+}
+
+func (f *fakeCraneWrapper) GetImageDigest(image string) (string, error) {
+	if digest, exists := f.publishedDigests[image]; exists {
+		return digest, nil
+	}
+	return "", errors.New("image not found")
+}
+
+func (f *fakeCraneWrapper) GetTarImageDigest(_ string) (string, error) {
+	return "sha256:package-digest", nil
+}
+
+func (f *fakeCraneWrapper) CopyImage(src, dst string) error {
+	if f.publishedDigests == nil {
+		f.publishedDigests = map[string]string{}
+	}
+	_, digest, _ := strings.Cut(src, "@")
+	f.publishedDigests[dst] = digest
+	f.copiedImages = append(f.copiedImages, src)
+	return nil
+}
+
+const pluginManifest = `plugins:
+- name: foo
+  target: global
+  description: foo plugin
+  versions:
+  - v0.0.1
+`
+
+var _ = Describe("Unit tests for publish", func() {
+	var (
+		dir                 string
+		po                  *PublishOptions
+		fakeImageOperations *fakes.ImageOperationsImpl
+		fakeCosignSigner    *fakes.CosignSignerFake
+		publishedDBFile     string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "")
+		Expect(err).NotTo(HaveOccurred())
+		packageArtifactDir := filepath.Join(dir, "packages")
+		Expect(os.MkdirAll(packageArtifactDir, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(packageArtifactDir, cli.PluginManifestFileName), []byte(pluginManifest), 0644)).To(Succeed())
+		publishedDBFile = filepath.Join(dir, "published.db")
+
+		fakeImageOperations = &fakes.ImageOperationsImpl{}
+		fakeImageOperations.GetFileDigestFromImageReturns("fake-digest", nil)
+		fakeImageOperations.PushImageStub = func(image string, files []string) error {
+			if strings.Contains(image, "plugin-inventory:") {
+				return utils.CopyFile(files[0], publishedDBFile)
+			}
+			return nil
+		}
+		fakeCosignSigner = &fakes.CosignSignerFake{}
+
+		po = &PublishOptions{
+			PackageArtifactDir:  packageArtifactDir,
+			Repository:          "test-repo.com",
+			InventoryImageTag:   "latest",
+			Publisher:           "tkg",
+			Vendor:              "vmware",
+			ImageOperationsImpl: fakeImageOperations,
+			CraneOptions:        &fakeCraneWrapper{},
+			CosignSigner:        fakeCosignSigner,
+		}
+	})
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	createPluginPackages := func() {
+		manifest, err := helpers.ReadPluginManifest(filepath.Join(po.PackageArtifactDir, cli.PluginManifestFileName))
+		Expect(err).NotTo(HaveOccurred())
+		for _, osArch := range cli.AllOSArch {
+			packageFile := filepath.Join(po.PackageArtifactDir, helpers.GetPluginArchiveRelativePath(manifest.Plugins[0], osArch, "v0.0.1"))
+			Expect(os.MkdirAll(filepath.Dir(packageFile), 0755)).To(Succeed())
+			Expect(os.WriteFile(packageFile, []byte("package"), 0644)).To(Succeed())
+		}
+	}
+
+	getPublishedPlugins := func() []*plugininventory.PluginInventoryEntry {
+		plugins, err := plugininventory.NewSQLiteInventory(publishedDBFile, "").GetPlugins(context.Background(), &plugininventory.PluginInventoryFilter{IncludeHidden: true})
+		Expect(err).NotTo(HaveOccurred())
+		return plugins
+	}
+
+	var _ = Context("tests for the publish function", func() {
+		var _ = It("when the repository has no inventory database yet", func() {
+			fakeImageOperations.ResolveImageReturns(errors.New("image not found"))
+
+			Expect(po.Publish()).To(Succeed())
+
+			plugins := getPublishedPlugins()
+			Expect(plugins).To(HaveLen(1))
+			Expect(plugins[0].Name).To(Equal("foo"))
+			Expect(plugins[0].Target).To(Equal(types.TargetGlobal))
+			Expect(plugins[0].Artifacts["v0.0.1"]).To(HaveLen(len(cli.AllOSArch)))
+
+			Expect(fakeImageOperations.PushImageCallCount()).To(Equal(1))
+			Expect(fakeCosignSigner.SignCallCount()).To(Equal(1))
+			_, images := fakeCosignSigner.SignArgsForCall(0)
+			Expect(images).To(Equal([]string{"test-repo.com/plugin-inventory:latest"}))
+		})
+
+		var _ = It("when the same plugins are published again with the inventory metadata database", func() {
+			fakeImageOperations.ResolveImageReturns(errors.New("image not found"))
+			Expect(po.Publish()).To(Succeed())
+
+			fakeImageOperations.ResolveImageReturns(nil)
+			fakeImageOperations.DownloadImageAndSaveFilesToDirStub = func(image, path string) error {
+				if strings.Contains(image, "plugin-inventory-metadata:") {
+					return plugininventory.NewSQLiteInventoryMetadata(filepath.Join(path, plugininventory.SQliteInventoryMetadataDBFileName)).CreateInventoryMetadataDBSchema()
+				}
+				return utils.CopyFile(publishedDBFile, filepath.Join(path, plugininventory.SQliteDBFileName))
+			}
+			Expect(po.Publish()).To(Succeed())
+
+			plugins := getPublishedPlugins()
+			Expect(plugins).To(HaveLen(1))
+			Expect(plugins[0].Artifacts["v0.0.1"]).To(HaveLen(len(cli.AllOSArch)))
+
+			image, _ := fakeImageOperations.PushImageArgsForCall(fakeImageOperations.PushImageCallCount() - 1)
+			Expect(image).To(Equal("test-repo.com/plugin-inventory-metadata:latest"))
+		})
+
+		var _ = It("when the published plugin binaries would be overwritten", func() {
+			fakeImageOperations.ResolveImageReturns(errors.New("image not found"))
+			Expect(po.Publish()).To(Succeed())
+			pushCount := fakeImageOperations.PushImageCallCount()
+
+			fakeImageOperations.ResolveImageReturns(nil)
+			fakeImageOperations.DownloadImageAndSaveFilesToDirStub = func(_, path string) error {
+				return utils.CopyFile(publishedDBFile, filepath.Join(path, plugininventory.SQliteDBFileName))
+			}
+			fakeImageOperations.GetFileDigestFromImageReturns("other-digest", nil)
+
+			err := po.Publish()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("is already in the inventory database with digest fake-digest, it cannot be overwritten with digest other-digest"))
+			Expect(fakeImageOperations.PushImageCallCount()).To(Equal(pushCount))
+
+			plugins := getPublishedPlugins()
+			Expect(plugins).To(HaveLen(1))
+			Expect(plugins[0].Artifacts["v0.0.1"][0].Digest).To(Equal("fake-digest"))
+		})
+
+		var _ = It("when some plugin packages are already published", func() {
+			createPluginPackages()
+			fakeImageOperations.ResolveImageReturns(errors.New("image not found"))
+			fakeCrane := po.CraneOptions.(*fakeCraneWrapper)
+			fakeCrane.publishedDigests = map[string]string{
+				"test-repo.com/vmware/tkg/linux/amd64/global/foo:v0.0.1":  "sha256:package-digest",
+				"test-repo.com/vmware/tkg/darwin/amd64/global/foo:v0.0.1": "sha256:other-digest",
+			}
+
+			Expect(po.Publish()).To(Succeed())
+			Expect(fakeCrane.pushedImages).To(HaveLen(len(cli.AllOSArch) - 1))
+			Expect(fakeCrane.pushedImages).NotTo(ContainElement("test-repo.com/vmware/tkg/linux/amd64/global/foo:v0.0.1"))
+			Expect(fakeCrane.pushedImages).To(ContainElement("test-repo.com/vmware/tkg/darwin/amd64/global/foo:v0.0.1"))
+			Expect(getPublishedPlugins()).To(HaveLen(1))
+		})
+
+		var _ = It("when publishing the plugin packages fails", func() {
+			createPluginPackages()
+			fakeImageOperations.ResolveImageReturns(errors.New("image not found"))
+			po.CraneOptions.(*fakeCraneWrapper).pushErr = errors.New("connection reset")
+
+			err := po.Publish()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(fmt.Sprintf("publishing of %d plugin packages failed", len(cli.AllOSArch))))
+			Expect(fakeImageOperations.PushImageCallCount()).To(Equal(0))
+
+			// Publishing again converges once the registry is reachable
+			po.CraneOptions.(*fakeCraneWrapper).pushErr = nil
+			Expect(po.Publish()).To(Succeed())
+			Expect(getPublishedPlugins()).To(HaveLen(1))
+		})
+
+		var _ = Context("when publishing to mirror repositories", func() {
+			BeforeEach(func() {
+				createPluginPackages()
+				po.MirrorRepositories = []string{"mirror-repo.com"}
+				fakeImageOperations.ResolveImageReturns(errors.New("image not found"))
+			})
+
+			var _ = It("should publish the plugins to all the repositories", func() {
+				Expect(po.Publish()).To(Succeed())
+
+				Expect(po.CraneOptions.(*fakeCraneWrapper).pushedImages).To(HaveLen(2 * len(cli.AllOSArch)))
+				Expect(fakeImageOperations.PushImageCallCount()).To(Equal(2))
+				image, _ := fakeImageOperations.PushImageArgsForCall(1)
+				Expect(image).To(Equal("mirror-repo.com/plugin-inventory:latest"))
+				Expect(fakeCosignSigner.SignCallCount()).To(Equal(2))
+				Expect(getPublishedPlugins()).To(HaveLen(1))
+			})
+
+			var _ = It("should not update any inventory if the plugin packages cannot be published to a mirror", func() {
+				po.CraneOptions.(*fakeCraneWrapper).failingRepository = "mirror-repo.com"
+
+				err := po.Publish()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("error while publishing the plugin packages to \"mirror-repo.com\", no inventory database was updated"))
+				Expect(fakeImageOperations.PushImageCallCount()).To(Equal(0))
+				Expect(fakeCosignSigner.SignCallCount()).To(Equal(0))
+			})
+
+			var _ = It("should restore the inventories already updated if the inventory of a mirror cannot be published", func() {
+				fakeImageOperations.PushImageStub = func(image string, files []string) error {
+					if strings.HasPrefix(image, "mirror-repo.com/") {
+						return errors.New("unauthorized")
+					}
+					return utils.CopyFile(files[0], publishedDBFile)
+				}
+
+				err := po.Publish()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("error while publishing inventory database to the repository as image: \"mirror-repo.com/plugin-inventory:latest\""))
+
+				// The inventory of the repository is published, the one of the mirror fails and the one of the repository is restored
+				Expect(fakeImageOperations.PushImageCallCount()).To(Equal(3))
+				image, files := fakeImageOperations.PushImageArgsForCall(2)
+				Expect(image).To(Equal("test-repo.com/plugin-inventory:latest"))
+				Expect(files).To(HaveLen(1))
+				Expect(filepath.Base(files[0])).To(Equal(plugininventory.SQliteDBFileName))
+				Expect(getPublishedPlugins()).To(BeEmpty())
+				// Only the restored inventory is signed
+				Expect(fakeCosignSigner.SignCallCount()).To(Equal(1))
+				_, images := fakeCosignSigner.SignArgsForCall(0)
+				Expect(images).To(Equal([]string{"test-repo.com/plugin-inventory:latest"}))
+			})
+
+			var _ = It("should restore the inventories already updated if the inventory of a mirror cannot be signed", func() {
+				fakeCosignSigner.SignStub = func(_ context.Context, images []string) error {
+					if strings.HasPrefix(images[0], "mirror-repo.com/") {
+						return errors.New("unauthorized")
+					}
+					return nil
+				}
+
+				err := po.Publish()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("unauthorized"))
+
+				// Both inventories are published then restored
+				Expect(fakeImageOperations.PushImageCallCount()).To(Equal(4))
+				image, files := fakeImageOperations.PushImageArgsForCall(2)
+				Expect(image).To(Equal("test-repo.com/plugin-inventory:latest"))
+				Expect(filepath.Base(files[0])).To(Equal(plugininventory.SQliteDBFileName))
+				image, files = fakeImageOperations.PushImageArgsForCall(3)
+				Expect(image).To(Equal("mirror-repo.com/plugin-inventory:latest"))
+				Expect(filepath.Base(files[0])).To(Equal(plugininventory.SQliteDBFileName))
+				Expect(getPublishedPlugins()).To(BeEmpty())
+				// The inventories are signed once published and once restored
+				Expect(fakeCosignSigner.SignCallCount()).To(Equal(4))
+				_, images := fakeCosignSigner.SignArgsForCall(2)
+				Expect(images).To(Equal([]string{"test-repo.com/plugin-inventory:latest"}))
+			})
+
+			var _ = It("should restore the inventory metadata databases with their name if the inventory of a mirror cannot be signed", func() {
+				fakeImageOperations.ResolveImageStub = func(image string) error {
+					if strings.Contains(image, "plugin-inventory-metadata:") {
+						return nil
+					}
+					return errors.New("image not found")
+				}
+				fakeImageOperations.DownloadImageAndSaveFilesToDirStub = func(_, path string) error {
+					return plugininventory.NewSQLiteInventoryMetadata(filepath.Join(path, plugininventory.SQliteInventoryMetadataDBFileName)).CreateInventoryMetadataDBSchema()
+				}
+				fakeCosignSigner.SignStub = func(_ context.Context, images []string) error {
+					if strings.HasPrefix(images[0], "mirror-repo.com/") {
+						return errors.New("unauthorized")
+					}
+					return nil
+				}
+
+				err := po.Publish()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("unauthorized"))
+
+				// Both inventories and inventory metadata databases are published then restored
+				Expect(fakeImageOperations.PushImageCallCount()).To(Equal(8))
+				var restored []string
+				for i := 4; i < 8; i++ {
+					image, files := fakeImageOperations.PushImageArgsForCall(i)
+					Expect(files).To(HaveLen(1))
+					restored = append(restored, image+" "+filepath.Base(files[0]))
+				}
+				Expect(restored).To(Equal([]string{
+					"test-repo.com/plugin-inventory:latest " + plugininventory.SQliteDBFileName,
+					"test-repo.com/plugin-inventory-metadata:latest " + plugininventory.SQliteInventoryMetadataDBFileName,
+					"mirror-repo.com/plugin-inventory:latest " + plugininventory.SQliteDBFileName,
+					"mirror-repo.com/plugin-inventory-metadata:latest " + plugininventory.SQliteInventoryMetadataDBFileName,
+				}))
+			})
+
+			var _ = It("should sign the inventory of every repository with the signer of the repository", func() {
+				signers := map[string]*fakes.CosignSignerFake{}
+				po.NewCosignSigner = func(repository string) (cosignhelper.CosignSigner, error) {
+					signers[repository] = &fakes.CosignSignerFake{}
+					return signers[repository], nil
+				}
+
+				Expect(po.Publish()).To(Succeed())
+				Expect(fakeCosignSigner.SignCallCount()).To(Equal(0))
+				Expect(signers).To(HaveLen(2))
+				for repository, signer := range signers {
+					Expect(signer.SignCallCount()).To(Equal(1))
+					_, images := signer.SignArgsForCall(0)
+					Expect(images).To(Equal([]string{repository + "/plugin-inventory:latest"}))
+				}
+
+				po.NewCosignSigner = func(repository string) (cosignhelper.CosignSigner, error) {
+					return nil, errors.New("invalid certificate")
+				}
+				err := po.Publish()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("unable to create the signer of the inventory database of \"test-repo.com\""))
+			})
+		})
+
+		var _ = Context("when running in dry-run mode", func() {
+			var localDigest string
+			BeforeEach(func() {
+				createPluginPackages()
+				localDigest = "fake-digest"
+				getFileDigestFromImageTar = func(_, _ string) (string, error) {
+					return localDigest, nil
+				}
+			})
+			AfterEach(func() {
+				getFileDigestFromImageTar = carvelhelpers.GetFileDigestFromImageTar
+			})
+
+			var _ = It("should not publish anything", func() {
+				fakeImageOperations.ResolveImageReturns(errors.New("image not found"))
+				po.DryRun = true
+
+				Expect(po.Publish()).To(Succeed())
+				Expect(fakeImageOperations.PushImageCallCount()).To(Equal(0))
+				Expect(fakeImageOperations.GetFileDigestFromImageCallCount()).To(Equal(0))
+				Expect(po.CraneOptions.(*fakeCraneWrapper).pushedImages).To(BeEmpty())
+				Expect(fakeCosignSigner.SignCallCount()).To(Equal(0))
+			})
+
+			var _ = It("should not publish anything for multi-arch image indexes", func() {
+				fakeImageOperations.ResolveImageReturns(errors.New("image not found"))
+				po.DryRun = true
+				po.MultiArch = true
+
+				Expect(po.Publish()).To(Succeed())
+				Expect(fakeImageOperations.PushImageCallCount()).To(Equal(0))
+				Expect(fakeImageOperations.GetFileDigestFromImageCallCount()).To(Equal(0))
+				Expect(po.CraneOptions.(*fakeCraneWrapper).pushedImages).To(BeEmpty())
+			})
+
+			var _ = It("should fail if released plugin binaries would be overwritten", func() {
+				fakeImageOperations.ResolveImageReturns(errors.New("image not found"))
+				Expect(po.Publish()).To(Succeed())
+				pushCount := fakeImageOperations.PushImageCallCount()
+
+				fakeImageOperations.ResolveImageReturns(nil)
+				fakeImageOperations.DownloadImageAndSaveFilesToDirStub = func(_, path string) error {
+					return utils.CopyFile(publishedDBFile, filepath.Join(path, plugininventory.SQliteDBFileName))
+				}
+				po.DryRun = true
+
+				// Publishing the same plugin binaries again is a no-op
+				Expect(po.Publish()).To(Succeed())
+
+				localDigest = "other-digest"
+				err := po.Publish()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(fmt.Sprintf("publishing would overwrite %d plugin binaries", len(cli.AllOSArch))))
+				Expect(fakeImageOperations.PushImageCallCount()).To(Equal(pushCount))
+			})
+		})
+
+		var _ = It("when publishing the inventory database fails", func() {
+			fakeImageOperations.ResolveImageReturns(errors.New("image not found"))
+			fakeImageOperations.PushImageStub = nil
+			fakeImageOperations.PushImageReturns(errors.New("unauthorized"))
+
+			err := po.Publish()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("error while publishing inventory database to the repository as image"))
+			Expect(fakeCosignSigner.SignCallCount()).To(Equal(0))
+		})
+	})
+})
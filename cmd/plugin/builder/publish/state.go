@@ -0,0 +1,81 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package publish
+
+import (
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+)
+
+// repositoryPublishState tracks the progress of the publishing of the plugins to a repository
+type repositoryPublishState struct {
+	repository string
+	// previousDBFile is a copy of the inventory database of the repository before the publish
+	previousDBFile string
+	// previousMetadataDBFile is a copy of the inventory metadata database of the repository before the publish
+	previousMetadataDBFile string
+	packagesPublished      bool
+	inventoryPublished     bool
+	metadataPublished      bool
+	inventorySigned        bool
+	rolledBack             bool
+	rollbackFailed         bool
+	rollbackSignFailed     bool
+}
+
+// rollbackInventoryDBs publishes again the previous inventory database and inventory metadata database
+// of the repositories whose inventory database was already updated so that no repository refers to the plugins.
+// The restored inventory database image is signed again, as publishing it replaces the signed image.
+func rollbackInventoryDBs(repositories []*PublishOptions, states []*repositoryPublishState) {
+	for i, rpo := range repositories {
+		if !states[i].inventoryPublished {
+			continue
+		}
+		log.Infof("restoring the previous plugin inventory database of %q", rpo.Repository)
+		if err := rpo.publishInventoryDB(states[i].previousDBFile); err != nil {
+			log.Errorf("unable to restore the previous plugin inventory database of %q: %v", rpo.Repository, err)
+			states[i].rollbackFailed = true
+			continue
+		}
+		if states[i].metadataPublished {
+			if err := rpo.publishInventoryMetadataDB(states[i].previousMetadataDBFile); err != nil {
+				log.Errorf("unable to restore the previous plugin inventory metadata database of %q: %v", rpo.Repository, err)
+				states[i].rollbackFailed = true
+				continue
+			}
+			states[i].metadataPublished = false
+		}
+		states[i].inventoryPublished = false
+		states[i].inventorySigned = false
+		states[i].rolledBack = true
+		if err := rpo.signInventory(); err != nil {
+			log.Errorf("unable to sign the restored plugin inventory database of %q: %v", rpo.Repository, err)
+			states[i].rollbackSignFailed = true
+		}
+	}
+}
+
+// reportPublishState logs the state of the publishing of the plugins to each repository
+func reportPublishState(states []*repositoryPublishState) {
+	log.Info("state of the repositories:")
+	for _, state := range states {
+		packages := "not published"
+		if state.packagesPublished {
+			packages = "published"
+		}
+		inventory := "not updated"
+		switch {
+		case state.rollbackFailed:
+			inventory = "updated, restoring the previous inventory database failed"
+		case state.rolledBack && state.rollbackSignFailed:
+			inventory = "restored to the previous inventory database, signing it failed"
+		case state.rolledBack:
+			inventory = "restored to the previous inventory database"
+		case state.inventorySigned:
+			inventory = "updated and signed"
+		case state.inventoryPublished:
+			inventory = "updated"
+		}
+		log.Infof("  %s: plugin packages %s, plugin inventory %s", state.repository, packages, inventory)
+	}
+}
@@ -23,3 +23,9 @@ var PluginTest = Target{
 	Filepath: "cmd/plugin/{{ .PluginName }}/test/main.go",
 	Template: plugintemplates.MainTestGo,
 }
+
+// PluginMetadata target
+var PluginMetadata = Target{
+	Filepath: "cmd/plugin/{{ .PluginName | ToLower }}/metadata.yaml",
+	Template: plugintemplates.MetadataYaml,
+}
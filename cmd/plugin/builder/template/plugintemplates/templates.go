@@ -45,6 +45,11 @@ var PluginToolingMK string
 //go:embed main.go.tmpl
 var MainGo string
 
+// MetadataYaml contains the plugin metadata template
+//
+//go:embed metadata.yaml.tmpl
+var MetadataYaml string
+
 // MainTestGo contains the plugin main test template
 //
 //go:embed main_test.go.tmpl
@@ -73,3 +73,11 @@ var DefaultPluginTargets = []Target{
 	PluginMain,
 	PluginTest,
 }
+
+// InitPluginTargets are the plugin targets of a repository initialized for a plugin.
+var InitPluginTargets = []Target{
+	PluginReadMe,
+	PluginMain,
+	PluginTest,
+	PluginMetadata,
+}
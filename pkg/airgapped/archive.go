@@ -0,0 +1,295 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package airgapped
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+// PluginBundleCompression is the compression of the tar file of a plugin bundle
+type PluginBundleCompression string
+
+const (
+	// PluginBundleCompressionNone does not compress the tar file
+	PluginBundleCompressionNone PluginBundleCompression = "none"
+	// PluginBundleCompressionGzip compresses the tar file with gzip
+	PluginBundleCompressionGzip PluginBundleCompression = "gzip"
+	// PluginBundleCompressionZstd compresses the tar file with zstd, which is faster
+	// than gzip and compresses better at the same speed
+	PluginBundleCompressionZstd PluginBundleCompression = "zstd"
+)
+
+// PluginBundleCompressions are the compressions of the tar file of a plugin bundle
+var PluginBundleCompressions = []PluginBundleCompression{
+	PluginBundleCompressionNone,
+	PluginBundleCompressionGzip,
+	PluginBundleCompressionZstd,
+}
+
+// The magic numbers at the start of the compressed files
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// ValidatePluginBundleCompression returns an error if the compression is not one of PluginBundleCompressions,
+// or if the compression level is out of the range of the compression. An empty compression is valid and
+// stands for no compression. A zero level stands for the default level of the compression.
+func ValidatePluginBundleCompression(compression PluginBundleCompression, level int) error {
+	switch compression {
+	case "", PluginBundleCompressionNone:
+		if level != 0 {
+			return errors.Errorf("a compression level cannot be specified without compression")
+		}
+	case PluginBundleCompressionGzip:
+		if level < 0 || level > gzip.BestCompression {
+			return errors.Errorf("invalid gzip compression level %d, it must be between %d and %d", level, gzip.BestSpeed, gzip.BestCompression)
+		}
+	case PluginBundleCompressionZstd:
+		// The levels of the zstd CLI, from 1 to 22, are mapped to the levels of the encoder
+		if level < 0 || level > 22 {
+			return errors.Errorf("invalid zstd compression level %d, it must be between 1 and 22", level)
+		}
+	default:
+		return errors.Errorf("invalid compression '%s', it must be one of %v", compression, PluginBundleCompressions)
+	}
+	return nil
+}
+
+// pluginBundleCompressionFromFileName returns the compression matching the extension of the tar file,
+// gzip for .gz and .tgz, zstd for .zst and .zstd, and no compression otherwise
+func pluginBundleCompressionFromFileName(tarFile string) PluginBundleCompression {
+	switch {
+	case strings.HasSuffix(tarFile, ".gz") || strings.HasSuffix(tarFile, ".tgz"):
+		return PluginBundleCompressionGzip
+	case strings.HasSuffix(tarFile, ".zst") || strings.HasSuffix(tarFile, ".zstd"):
+		return PluginBundleCompressionZstd
+	default:
+		return PluginBundleCompressionNone
+	}
+}
+
+// pluginBundleArchiveExtensions are the extensions of the tar file of a plugin bundle,
+// kept at the end of the file names of its parts
+var pluginBundleArchiveExtensions = []string{".tar.gz", ".tgz", ".tar.zst", ".tar.zstd", ".tar"}
+
+// pluginBundlePartFileName returns the tar file of the part of the plugin bundle with the index,
+// named after the tar file of the first part, e.g. bundle.part1.tar.gz for bundle.tar.gz
+func pluginBundlePartFileName(tarFile string, index int) string {
+	for _, ext := range pluginBundleArchiveExtensions {
+		if strings.HasSuffix(tarFile, ext) {
+			return fmt.Sprintf("%s.part%d%s", strings.TrimSuffix(tarFile, ext), index, ext)
+		}
+	}
+	return fmt.Sprintf("%s.part%d", tarFile, index)
+}
+
+// archivedSize returns the size of the files in a tar file which is not compressed, the
+// headers and the padding of the files included, used to estimate the size of a tar file
+func archivedSize(fileSizes ...int64) int64 {
+	const blockSize = 512
+	// The directory header and the two blocks ending the tar file
+	size := int64(3 * blockSize)
+	for _, fileSize := range fileSizes {
+		size += blockSize + (fileSize+blockSize-1)/blockSize*blockSize
+	}
+	return size
+}
+
+// fileDigest returns the sha256 digest of the file, as sha256:<hex>
+func fileDigest(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sha256:%x", hash.Sum(nil)), nil
+}
+
+// createArchive saves the directory, with its name as the top directory, to the tar file
+// compressed with the compression and level. The tar file is removed if it cannot be saved.
+func createArchive(dir, tarFile string, compression PluginBundleCompression, level int) (err error) {
+	file, err := os.Create(tarFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(tarFile)
+		}
+	}()
+
+	var writer io.WriteCloser
+	switch compression {
+	case PluginBundleCompressionGzip:
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		if writer, err = gzip.NewWriterLevel(file, level); err != nil {
+			return err
+		}
+	case PluginBundleCompressionZstd:
+		encoderLevel := zstd.SpeedDefault
+		if level != 0 {
+			encoderLevel = zstd.EncoderLevelFromZstd(level)
+		}
+		if writer, err = zstd.NewWriter(file, zstd.WithEncoderLevel(encoderLevel)); err != nil {
+			return err
+		}
+	default:
+		writer = nopWriteCloser{file}
+	}
+
+	tarWriter := tar.NewWriter(writer)
+	baseDir := filepath.Base(dir)
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(filepath.Join(baseDir, relativePath))
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tarWriter, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// extractArchive extracts the tar file to the directory. The compression of the
+// tar file is detected from its content, whatever its extension.
+func extractArchive(tarFile, dir string) error {
+	file, err := os.Open(tarFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader, closeReader, err := newDecompressingReader(file)
+	if err != nil {
+		return err
+	}
+	defer closeReader()
+
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return errors.Errorf("invalid file path %q in the tar file", header.Name)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := extractFile(tarReader, target, header.FileInfo().Mode().Perm()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// extractFile writes the content of the reader to the file, creating its directory if needed
+func extractFile(reader io.Reader, target string, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, reader); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// newDecompressingReader returns a reader of the decompressed content of the reader, which can be
+// gzipped, compressed with zstd or not compressed, and the function to close the returned reader
+func newDecompressingReader(r io.Reader) (io.Reader, func(), error) {
+	bufferedReader := bufio.NewReader(r)
+	magic, err := bufferedReader.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gzipReader, err := gzip.NewReader(bufferedReader)
+		if err != nil {
+			return nil, nil, err
+		}
+		return gzipReader, func() { gzipReader.Close() }, nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		zstdReader, err := zstd.NewReader(bufferedReader)
+		if err != nil {
+			return nil, nil, err
+		}
+		return zstdReader, zstdReader.Close, nil
+	default:
+		return bufferedReader, func() {}, nil
+	}
+}
+
+// nopWriteCloser adds a no-op Close method to a writer
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package airgapped
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func Test_CreateAndExtractArchive(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	bundleDir := filepath.Join(dir, PluginBundleDirName)
+	assert.Nil(os.MkdirAll(filepath.Join(bundleDir, "sub"), 0o755))
+	content := bytes.Repeat([]byte("plugin image content "), 1000)
+	assert.Nil(os.WriteFile(filepath.Join(bundleDir, "image.tar.gz"), content, 0o600))
+	assert.Nil(os.WriteFile(filepath.Join(bundleDir, "sub", PluginMigrationManifestFile), []byte("imagesToCopy: []"), 0o600))
+
+	tests := []struct {
+		compression PluginBundleCompression
+		level       int
+		magic       []byte
+	}{
+		{compression: PluginBundleCompressionNone},
+		{compression: PluginBundleCompressionGzip, magic: gzipMagic},
+		{compression: PluginBundleCompressionGzip, level: 9, magic: gzipMagic},
+		{compression: PluginBundleCompressionZstd, magic: zstdMagic},
+		{compression: PluginBundleCompressionZstd, level: 19, magic: zstdMagic},
+	}
+	for _, test := range tests {
+		// The extension of the tar file does not matter when extracting it
+		tarFile := filepath.Join(dir, "bundle-"+string(test.compression)+".tar")
+		assert.Nil(createArchive(bundleDir, tarFile, test.compression, test.level))
+
+		archived, err := os.ReadFile(tarFile)
+		assert.Nil(err)
+		assert.True(bytes.HasPrefix(archived, test.magic))
+		if test.compression != PluginBundleCompressionNone {
+			assert.Less(len(archived), len(content))
+		}
+
+		extractDir := filepath.Join(dir, "extract-"+string(test.compression))
+		assert.Nil(extractArchive(tarFile, extractDir))
+		extracted, err := os.ReadFile(filepath.Join(extractDir, PluginBundleDirName, "image.tar.gz"))
+		assert.Nil(err)
+		assert.Equal(content, extracted)
+
+		manifest, err := readFileFromTar(tarFile, PluginBundleDirName+"/sub/"+PluginMigrationManifestFile)
+		assert.Nil(err)
+		assert.Equal("imagesToCopy: []", string(manifest))
+		assert.Nil(os.RemoveAll(extractDir))
+	}
+}
+
+func Test_ValidatePluginBundleCompression(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		compression PluginBundleCompression
+		level       int
+		errString   string
+	}{
+		{compression: "", level: 0},
+		{compression: PluginBundleCompressionNone, level: 0},
+		{compression: PluginBundleCompressionGzip, level: 9},
+		{compression: PluginBundleCompressionZstd, level: 22},
+		{compression: PluginBundleCompressionNone, level: 1, errString: "a compression level cannot be specified without compression"},
+		{compression: PluginBundleCompressionGzip, level: 10, errString: "invalid gzip compression level 10, it must be between 1 and 9"},
+		{compression: PluginBundleCompressionZstd, level: 23, errString: "invalid zstd compression level 23, it must be between 1 and 22"},
+		{compression: "bzip2", level: 0, errString: "invalid compression 'bzip2', it must be one of [none gzip zstd]"},
+	}
+	for _, test := range tests {
+		err := ValidatePluginBundleCompression(test.compression, test.level)
+		if test.errString == "" {
+			assert.Nil(err)
+		} else {
+			assert.EqualError(err, test.errString)
+		}
+	}
+
+	assert.Equal(PluginBundleCompressionGzip, pluginBundleCompressionFromFileName("bundle.tar.gz"))
+	assert.Equal(PluginBundleCompressionGzip, pluginBundleCompressionFromFileName("bundle.tgz"))
+	assert.Equal(PluginBundleCompressionZstd, pluginBundleCompressionFromFileName("bundle.tar.zst"))
+	assert.Equal(PluginBundleCompressionNone, pluginBundleCompressionFromFileName("bundle.tar"))
+}
+
+func Test_PluginBundlePartFileName(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("/tmp/bundle.part1.tar.gz", pluginBundlePartFileName("/tmp/bundle.tar.gz", 1))
+	assert.Equal("/tmp/bundle.part2.tgz", pluginBundlePartFileName("/tmp/bundle.tgz", 2))
+	assert.Equal("/tmp/bundle.part3.tar.zst", pluginBundlePartFileName("/tmp/bundle.tar.zst", 3))
+	assert.Equal("/tmp/bundle.part1.tar", pluginBundlePartFileName("/tmp/bundle.tar", 1))
+	assert.Equal("/tmp/bundle.part1", pluginBundlePartFileName("/tmp/bundle", 1))
+}
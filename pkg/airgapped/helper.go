@@ -43,3 +43,18 @@ func GetImageRelativePath(image, basePath string, withTag bool) string {
 	}
 	return relativePath
 }
+
+// GetImageTagAndDigest returns the tag and the digest of the image, if any.
+// E.g. if the image is `fake.repo.com/plugin/foo:v1.0.0@sha256:1234` it returns
+// `v1.0.0` and `sha256:1234`
+func GetImageTagAndDigest(image string) (string, string) {
+	var digest string
+	if idx := strings.LastIndex(image, "@"); idx != -1 {
+		image, digest = image[:idx], image[idx+1:]
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	if idx := strings.LastIndex(name, ":"); idx != -1 {
+		return name[idx+1:], digest
+	}
+	return "", digest
+}
@@ -5,8 +5,11 @@ package airgapped
 
 import (
 	"testing"
+	"time"
 
 	"github.com/tj/assert"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
 )
 
 func Test_GetPluginInventoryMetadataImage(t *testing.T) {
@@ -100,3 +103,112 @@ func Test_GetImageRelativePath(t *testing.T) {
 		})
 	}
 }
+
+func Test_GetImageTagAndDigest(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		image          string
+		expectedTag    string
+		expectedDigest string
+	}{
+		{
+			image:          "fake.repo.com/plugin/plugin-inventory:latest@sha256:1234",
+			expectedTag:    "latest",
+			expectedDigest: "sha256:1234",
+		},
+		{
+			image:          "fake.repo.com:5000/plugin/airgapped:v1.0.0",
+			expectedTag:    "v1.0.0",
+			expectedDigest: "",
+		},
+		{
+			image:          "fake.repo.com:5000/plugin/airgapped@sha256:1234",
+			expectedTag:    "",
+			expectedDigest: "sha256:1234",
+		},
+		{
+			image:          "fake.repo.com:5000/plugin/airgapped",
+			expectedTag:    "",
+			expectedDigest: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.image, func(t *testing.T) {
+			tag, digest := GetImageTagAndDigest(test.image)
+			assert.Equal(test.expectedTag, tag)
+			assert.Equal(test.expectedDigest, digest)
+		})
+	}
+}
+
+func Test_UploadRetryPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	// The retry settings of the options take precedence over the default retry policy
+	o := &UploadPluginBundleOptions{Retries: 5, RetryBackoff: time.Second, RetryMaxBackoff: 10 * time.Second}
+	policy := o.retryPolicy()
+	assert.Equal(5, policy.Retries)
+	assert.Equal(time.Second, policy.Backoff)
+	assert.Equal(10*time.Second, policy.MaxBackoff)
+	assert.True(policy.Jitter)
+	assert.Equal(carvelhelpers.DefaultRetryPolicy().Timeout, policy.Timeout)
+
+	// The delays of the default retry policy are used when not set
+	o = &UploadPluginBundleOptions{}
+	policy = o.retryPolicy()
+	assert.Equal(0, policy.Retries)
+	assert.Equal(carvelhelpers.DefaultRetryPolicy().Backoff, policy.Backoff)
+	assert.Equal(carvelhelpers.DefaultRetryPolicy().MaxBackoff, policy.MaxBackoff)
+}
+
+func Test_ParsePluginMigrationManifest(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		manifest  string
+		errString string
+	}{
+		{
+			manifest: "manifestVersion: v2\nrelativeInventoryImagePathWithTag: /plugin-inventory:latest\ninventoryMetadataImage:\n  sourceFilePath: plugin_inventory_metadata.db\n",
+		},
+		{
+			// The manifests written by older CLIs have no version
+			manifest: "relativeInventoryImagePathWithTag: /plugin-inventory:latest\ninventoryMetadataImage:\n  sourceFilePath: plugin_inventory_metadata.db\n",
+		},
+		{
+			manifest:  "relativeInventoryImagePathWithTag: /plugin-inventory:latest\n",
+			errString: `error while converting plugin migration manifest version "v1": the plugin inventory metadata image is missing`,
+		},
+		{
+			// The manifests written by newer CLIs are rejected before being parsed
+			manifest:  "manifestVersion: v3\nimagesToCopy: {}\n",
+			errString: `the plugin bundle was created by a newer version of the Tanzu CLI, its plugin migration manifest version "v3" is not supported by this version of the Tanzu CLI which supports up to version "v2", please upgrade the Tanzu CLI`,
+		},
+		{
+			manifest:  "manifestVersion: v0\n",
+			errString: `unsupported plugin migration manifest version "v0"`,
+		},
+		{
+			manifest:  "manifestVersion: latest\n",
+			errString: `invalid plugin migration manifest version "latest"`,
+		},
+		{
+			manifest:  "manifestVersion: v2\nimagesToCopy: {}\n",
+			errString: `error while parsing plugin migration manifest version "v2"`,
+		},
+	}
+
+	for _, test := range tests {
+		manifest, err := parsePluginMigrationManifest([]byte(test.manifest))
+		if test.errString == "" {
+			assert.Nil(err)
+			assert.Equal(PluginMigrationManifestVersion, manifest.ManifestVersion)
+			assert.Equal("/plugin-inventory:latest", manifest.RelativeInventoryImagePathWithTag)
+		} else {
+			assert.NotNil(err)
+			assert.Contains(err.Error(), test.errString)
+		}
+	}
+}
@@ -4,20 +4,26 @@
 package airgapped
 
 import (
+	"context"
+	"crypto/sha256"
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 
 	"github.com/pkg/errors"
-	"github.com/verybluebot/tarinator-go"
+	"golang.org/x/sync/errgroup"
 	"gopkg.in/yaml.v3"
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cosignhelper"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cosignhelper/sigverifier"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/essentials"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/registry"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
 
 	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
@@ -26,28 +32,80 @@ import (
 
 const fileExists = "the file '%s' already exists"
 
+// PluginBundleDownloadDirSuffix is appended to the path of a plugin bundle to get the path of the
+// directory the plugin bundle is downloaded to, kept when the download fails to resume it
+const PluginBundleDownloadDirSuffix = ".download"
+
+// downloadedImagesDirName is the directory of the download directory holding the downloaded images
+const downloadedImagesDirName = "images"
+
 // DownloadPluginBundleOptions defines options for downloading plugin bundle
 type DownloadPluginBundleOptions struct {
 	PluginInventoryImage string
 	ToTar                string
 	Groups               []string
 	Plugins              []string
-
-	DryRun         bool
+	// SignKey is the private key (or the KMS URI) used to sign the plugin bundle and its plugin
+	// inventory metadata with cosign, the plugin bundle is not signed when not set
+	SignKey string
+	// Base is a plugin bundle downloaded previously, or its plugin migration manifest, and BaseRepo a
+	// repository plugin bundles were uploaded to. The images of the plugins already part of the base
+	// plugin bundle or already present in the base repository are not included in the plugin bundle.
+	Base     string
+	BaseRepo string
+	// Compression is the compression of the tar file, the one matching the extension of the tar file
+	// when not set, and CompressionLevel the level of the compression, its default level when not set
+	Compression      PluginBundleCompression
+	CompressionLevel int
+	// MaxPartSize is the maximum size in bytes of the tar files of the plugin bundle, which is split
+	// in several parts when it is larger. The plugin bundle is a single tar file when not set.
+	MaxPartSize int64
+	// Concurrency is the number of images downloaded concurrently, one at a time when not set
+	Concurrency int
+
+	// Connection are the TLS and proxy settings used to access the registries, on top of the
+	// certificate configuration of the registries and of the proxy environment variables
+	Connection *registry.ConnectionOptions
+
+	DryRun bool
+	// ImageProcessor is created with the Connection settings when not set
 	ImageProcessor carvelhelpers.ImageOperationsImpl
+	// progress relays the progress of the transfers reported by the ImageProcessor
+	// when it is created with the Connection settings
+	progress *progressRelay
+
+	// pluginInventoryImageWithDigest is the plugin inventory image pinned to its digest
+	// to make sure the verified image is the one used for the rest of the download
+	pluginInventoryImageWithDigest string
+	// baseImages are the images of the base plugin bundle, by relative image path and digest
+	baseImages map[string]bool
 }
 
 // DownloadPluginBundle download the plugin bundle based on provided plugin inventory image
 // and save it as tar file
-func (o *DownloadPluginBundleOptions) DownloadPluginBundle() error {
+func (o *DownloadPluginBundleOptions) DownloadPluginBundle() (err error) {
 	// Validate the input options
-	err := o.validateOptions()
+	err = o.validateOptions()
 	if err != nil {
 		return err
 	}
 
+	// The plugin bundle is downloaded to its download directory, next to the tar file, where the
+	// downloaded images are kept when the download fails so it can be resumed
+	downloadDir := ""
+	if !o.DryRun {
+		downloadDir = o.ToTar + PluginBundleDownloadDirSuffix
+		err = os.MkdirAll(filepath.Join(downloadDir, downloadedImagesDirName), os.ModePerm)
+		if err != nil {
+			return errors.Wrap(err, "unable to create download directory")
+		}
+		defer func() {
+			cleanUpDownloadDir(downloadDir, err)
+		}()
+	}
+
 	// Create temp download directory
-	tempBaseDir, err := os.MkdirTemp("", "")
+	tempBaseDir, err := os.MkdirTemp(downloadDir, "")
 	if err != nil {
 		return errors.Wrap(err, "unable to create temp directory")
 	}
@@ -58,14 +116,21 @@ func (o *DownloadPluginBundleOptions) DownloadPluginBundle() error {
 	}
 	defer os.RemoveAll(tempBaseDir)
 
-	// Get selected plugin groups and plugins objects based on the inputs
-	selectedPluginEntries, selectedPluginGroups, err := o.getSelectedPluginInfo()
+	// Get selected plugin groups and plugins objects based on the inputs.
+	// The plugin inventory database is kept in the temp directory until the end of the download
+	// as the plugins can be read from it while downloading their images
+	inventoryDir := filepath.Join(tempBaseDir, "inventory")
+	err = os.Mkdir(inventoryDir, os.ModePerm)
+	if err != nil {
+		return errors.Wrap(err, "unable to create temp directory")
+	}
+	selectedPlugins, selectedPluginGroups, err := o.getSelectedPluginInfo(inventoryDir)
 	if err != nil {
 		return errors.Wrap(err, "error while getting selected plugin and plugin group information")
 	}
 
 	if o.DryRun {
-		imageMetadata, err := o.getListOfImages(selectedPluginEntries)
+		imageMetadata, err := o.getListOfImages(selectedPlugins)
 		if err != nil {
 			return err
 		}
@@ -81,52 +146,126 @@ func (o *DownloadPluginBundleOptions) DownloadPluginBundle() error {
 	}
 
 	// Save plugin images and get list of images that needs to be copied as part of the upload process
-	relativeInventoryImagePathWithTag, imagesToCopy, err := o.saveAndGetImagesToCopy(selectedPluginEntries, tempPluginBundleDir)
+	relativeInventoryImagePathWithTag, imagesToCopy, baseImages, err := o.saveAndGetImagesToCopy(selectedPlugins, tempPluginBundleDir)
 	if err != nil {
 		return errors.Wrap(err, "error while downloading and saving plugin images")
 	}
 
 	// Save plugin inventory metadata file and create an entry object
-	inventoryMetadataImageInfo, err := o.savePluginInventoryMetadata(selectedPluginGroups, selectedPluginEntries, tempPluginBundleDir)
+	inventoryMetadataImageInfo, err := o.savePluginInventoryMetadata(selectedPluginGroups, selectedPlugins, tempPluginBundleDir)
 	if err != nil {
 		return errors.Wrap(err, "error while saving plugin inventory metadata")
 	}
 
+	// Sign the plugin inventory metadata, the signature is recorded in the plugin migration manifest
+	if o.SignKey != "" {
+		inventoryMetadataImageInfo.Signature, err = cosignhelper.SignBlob(context.Background(), o.SignKey, filepath.Join(tempPluginBundleDir, inventoryMetadataImageInfo.SourceFilePath))
+		if err != nil {
+			return errors.Wrap(err, "error while signing plugin inventory metadata")
+		}
+	}
+
+	// Save the images of the plugins to other parts when the plugin bundle is too large. The first
+	// part is saved last as its plugin migration manifest records the digests of the other parts.
+	var parts []*PluginBundlePart
+	if o.MaxPartSize > 0 {
+		parts, err = o.savePluginBundleParts(imagesToCopy, tempBaseDir, tempPluginBundleDir)
+		if err != nil {
+			return errors.Wrap(err, "error while splitting plugin bundle in parts")
+		}
+	}
+
 	// Save plugin migration manifest file to the plugin bundle directory
-	err = savePluginMigrationManifestFile(relativeInventoryImagePathWithTag, imagesToCopy, inventoryMetadataImageInfo, tempPluginBundleDir)
+	err = savePluginMigrationManifestFile(relativeInventoryImagePathWithTag, imagesToCopy, baseImages, getPluginGroupsToCopy(selectedPluginGroups), inventoryMetadataImageInfo, parts, tempPluginBundleDir)
 	if err != nil {
 		return errors.Wrap(err, "error while saving plugin migration manifest")
 	}
 
 	// Save entire plugin bundle as a single tar file which can be used with upload-bundle
 	log.Infof("saving plugin bundle at: %s", o.ToTar)
-	err = tarinator.Tarinate([]string{tempPluginBundleDir}, o.ToTar)
+	err = createArchive(tempPluginBundleDir, o.ToTar, o.Compression, o.CompressionLevel)
 	if err != nil {
 		return errors.Wrap(err, "error while creating archive file")
 	}
+	if len(parts) > 0 {
+		// The plugin inventory, the plugin inventory metadata and the plugin migration manifest cannot be split
+		if info, err := os.Stat(o.ToTar); err == nil && info.Size() > o.MaxPartSize {
+			log.Warningf("the first part of the plugin bundle %q is larger than the maximum part size (%s)", o.ToTar, utils.FormatBytes(info.Size()))
+		}
+		log.Infof("the plugin bundle is split in %d parts, all of them are needed to upload it", len(parts))
+	}
+
+	if o.SignKey != "" {
+		signatureFile := o.ToTar + PluginBundleSignatureFileSuffix
+		log.Infof("saving plugin bundle signature at: %s", signatureFile)
+		signature, err := cosignhelper.SignBlob(context.Background(), o.SignKey, o.ToTar)
+		if err != nil {
+			return errors.Wrap(err, "error while signing plugin bundle")
+		}
+		if err := os.WriteFile(signatureFile, []byte(signature), 0o600); err != nil {
+			return errors.Wrap(err, "error while saving plugin bundle signature")
+		}
+	}
 
 	return nil
 }
 
-// getSelectedPluginInfo returns the list of PluginInventoryEntry and
+// selectedPlugins are the plugins to include in the plugin bundle
+type selectedPlugins struct {
+	// entries are the plugins selected individually or through their plugin groups
+	entries []*plugininventory.PluginInventoryEntry
+	// inventory is set when all the plugins of the inventory are selected, in which case the
+	// plugins are read from the inventory one at a time rather than loaded in memory all at once
+	inventory plugininventory.PluginInventory
+}
+
+// imagesSize returns the total size in bytes of the images of the selected plugins known by
+// the inventory, the number of images, and the number of images whose size is unknown
+func (s *selectedPlugins) imagesSize() (size int64, count, unknownSizes int, err error) {
+	err = s.forEach(func(pe *plugininventory.PluginInventoryEntry) error {
+		for _, artifacts := range pe.Artifacts {
+			count += len(artifacts)
+			for _, a := range artifacts {
+				if a.Size > 0 {
+					size += a.Size
+				} else {
+					unknownSizes++
+				}
+			}
+		}
+		return nil
+	})
+	return size, count, unknownSizes, err
+}
+
+// forEach calls fn for each selected plugin
+func (s *selectedPlugins) forEach(fn func(*plugininventory.PluginInventoryEntry) error) error {
+	if s.inventory != nil {
+		// Include the hidden plugins during plugin migration
+		return s.inventory.WalkPlugins(context.Background(), &plugininventory.PluginInventoryFilter{IncludeHidden: true}, fn)
+	}
+	for _, pe := range s.entries {
+		if err := fn(pe); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getSelectedPluginInfo returns the selected plugins and the list of
 // PluginGroupEntry based on the DownloadPluginBundleOptions that needs to be
 // considered for downloading plugin bundle.
 // Downloads the plugin inventory image and selects the plugins and plugin
 // groups based on the DownloadPluginBundleOptions.Groups by querying the
-// plugin inventory database
-func (o *DownloadPluginBundleOptions) getSelectedPluginInfo() ([]*plugininventory.PluginInventoryEntry, []*plugininventory.PluginGroup, error) {
+// plugin inventory database. The plugin inventory image is downloaded to inventoryDir,
+// which must be kept until the selected plugins are no longer used.
+func (o *DownloadPluginBundleOptions) getSelectedPluginInfo(inventoryDir string) (*selectedPlugins, []*plugininventory.PluginGroup, error) {
 	var err error
-	tempDBDir, err := os.MkdirTemp("", "")
-	if err != nil {
-		return nil, nil, errors.Wrap(err, "unable to create temp directory")
-	}
-	defer os.RemoveAll(tempDBDir)
-
 	log.Infof("Getting selected plugin information...")
 
-	// Download the plugin inventory oci image to tempDBDir
-	inventoryFile := filepath.Join(tempDBDir, plugininventory.SQliteDBFileName)
-	if err := o.ImageProcessor.DownloadImageAndSaveFilesToDir(o.PluginInventoryImage, filepath.Dir(inventoryFile)); err != nil {
+	// Download the plugin inventory oci image to inventoryDir
+	inventoryFile := filepath.Join(inventoryDir, plugininventory.SQliteDBFileName)
+	if err := o.ImageProcessor.DownloadImageAndSaveFilesToDir(o.pluginInventoryImageWithDigest, filepath.Dir(inventoryFile)); err != nil {
 		return nil, nil, errors.Wrapf(err, "failed to download plugin inventory image '%s'", o.PluginInventoryImage)
 	}
 
@@ -135,21 +274,29 @@ func (o *DownloadPluginBundleOptions) getSelectedPluginInfo() ([]*plugininventor
 
 	selectedPluginGroups := []*plugininventory.PluginGroup{}
 	selectedPluginEntries := []*plugininventory.PluginInventoryEntry{}
+	var allPlugins *selectedPlugins
 
 	// If groups were not provided as argument select all available plugin groups and all available plugins
 	if len(o.Groups) == 0 && len(o.Plugins) == 0 {
-		selectedPluginGroups, err = pi.GetPluginGroups(plugininventory.PluginGroupFilter{IncludeHidden: true}) // Include the hidden plugin groups during plugin migration
+		selectedPluginGroups, err = pi.GetPluginGroups(context.Background(), plugininventory.PluginGroupFilter{IncludeHidden: true}) // Include the hidden plugin groups during plugin migration
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "unable to read all plugin groups from database")
 		}
-		selectedPluginEntries, err = pi.GetPlugins(&plugininventory.PluginInventoryFilter{IncludeHidden: true}) // Include the hidden plugins during plugin migration
+		// The plugins are read from the inventory when needed, rather than loaded all at once,
+		// as large inventories have many plugins each with many artifacts
+		allPlugins = &selectedPlugins{inventory: pi}
+		pluginsCount := 0
+		err = allPlugins.forEach(func(_ *plugininventory.PluginInventoryEntry) error {
+			pluginsCount++
+			return nil
+		})
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "unable to read all plugins from database")
 		}
-		if len(selectedPluginEntries) == 1 {
+		if pluginsCount == 1 {
 			log.Infof("will be downloading the one plugin from: %s", o.PluginInventoryImage)
 		} else {
-			log.Infof("will be downloading the %d plugins from: %s", len(selectedPluginEntries), o.PluginInventoryImage)
+			log.Infof("will be downloading the %d plugins from: %s", pluginsCount, o.PluginInventoryImage)
 		}
 	} else {
 		// If groups were provided as argument select only provided plugin groups and
@@ -185,10 +332,13 @@ func (o *DownloadPluginBundleOptions) getSelectedPluginInfo() ([]*plugininventor
 	}
 
 	// Remove duplicate PluginInventoryEntries and PluginGroups from the selected list
-	selectedPluginEntries = plugininventory.RemoveDuplicatePluginInventoryEntries(selectedPluginEntries)
 	selectedPluginGroups = plugininventory.RemoveDuplicatePluginGroups(selectedPluginGroups)
+	if allPlugins != nil {
+		return allPlugins, selectedPluginGroups, nil
+	}
+	selectedPluginEntries = plugininventory.RemoveDuplicatePluginInventoryEntries(selectedPluginEntries)
 
-	return selectedPluginEntries, selectedPluginGroups, nil
+	return &selectedPlugins{entries: selectedPluginEntries}, selectedPluginGroups, nil
 }
 
 func (o *DownloadPluginBundleOptions) getPluginFromPluginID(pluginID string, pi plugininventory.PluginInventory) ([]*plugininventory.PluginInventoryEntry, error) {
@@ -197,7 +347,7 @@ func (o *DownloadPluginBundleOptions) getPluginFromPluginID(pluginID string, pi
 		pluginVersion = cli.VersionLatest
 	}
 
-	pluginEntries, err := pi.GetPlugins(&plugininventory.PluginInventoryFilter{
+	pluginEntries, err := pi.GetPlugins(context.Background(), &plugininventory.PluginInventoryFilter{
 		Name:          pluginName,
 		Target:        configtypes.StringToTarget(pluginTarget),
 		Version:       pluginVersion,
@@ -237,7 +387,7 @@ func (o *DownloadPluginBundleOptions) getAllPluginGroupsAndPluginEntriesFromPlug
 		Name:          pgi.Name,
 		Version:       pgi.Version,
 	}
-	pluginGroups, err := pi.GetPluginGroups(pgFilter)
+	pluginGroups, err := pi.GetPluginGroups(context.Background(), pgFilter)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "unable to get plugin groups")
 	}
@@ -257,7 +407,7 @@ func (o *DownloadPluginBundleOptions) getAllPluginGroupsAndPluginEntriesFromPlug
 					Version:       p.Version,
 					IncludeHidden: true, // Include the hidden plugins during plugin migration
 				}
-				pluginEntries, err := pi.GetPlugins(pif)
+				pluginEntries, err := pi.GetPlugins(context.Background(), pif)
 				if err != nil {
 					return nil, nil, errors.Wrapf(err, "unable to get plugins in plugin group %v", plugininventory.PluginGroupToID(pg))
 				}
@@ -288,68 +438,212 @@ func (o *DownloadPluginBundleOptions) getAllPluginGroupsAndPluginEntriesFromPlug
 }
 
 // saveAndGetImagesToCopy saves the images after downloading them and
-// returns the images to copy object
-func (o *DownloadPluginBundleOptions) saveAndGetImagesToCopy(pluginEntries []*plugininventory.PluginInventoryEntry, downloadDir string) (string, []*ImageCopyInfo, error) {
+// returns the images to copy object and the images of the base not downloaded
+func (o *DownloadPluginBundleOptions) saveAndGetImagesToCopy(plugins *selectedPlugins, downloadDir string) (string, []*ImageCopyInfo, []*ImageCopyInfo, error) {
 	// Download all plugin inventory database and plugins as tar file
-	return o.downloadImagesAsTarFile(pluginEntries, downloadDir)
+	return o.downloadImagesAsTarFile(plugins, downloadDir)
 }
 
 // downloadImagesAsTarFile downloads plugin inventory image and all plugin images
-// as tar file to the specified directory
-func (o *DownloadPluginBundleOptions) downloadImagesAsTarFile(pluginEntries []*plugininventory.PluginInventoryEntry, downloadDir string) (string, []*ImageCopyInfo, error) {
+// as tar file to the specified directory. The plugin images which are part of the
+// base are not downloaded, they are returned separately.
+func (o *DownloadPluginBundleOptions) downloadImagesAsTarFile(plugins *selectedPlugins, downloadDir string) (string, []*ImageCopyInfo, []*ImageCopyInfo, error) {
 	allImages := []*ImageCopyInfo{}
+	var baseImages []*ImageCopyInfo
 
 	// Download plugin inventory database as tar file
 	pluginInventoryFileNameTar := "plugin-inventory-image.tar.gz"
 	log.Infof("downloading image %q", o.PluginInventoryImage)
-	err := o.ImageProcessor.CopyImageToTar(o.PluginInventoryImage, filepath.Join(downloadDir, pluginInventoryFileNameTar))
+	_, err := o.downloadImageToTar(o.pluginInventoryImageWithDigest, filepath.Join(downloadDir, pluginInventoryFileNameTar))
 	if err != nil {
-		return "", nil, err
+		return "", nil, nil, err
 	}
 
 	relativeInventoryImagePathWithTag := GetImageRelativePath(o.PluginInventoryImage, path.Dir(o.PluginInventoryImage), true)
 
+	inventoryImageTag, _ := GetImageTagAndDigest(o.PluginInventoryImage)
+	_, inventoryImageDigest := GetImageTagAndDigest(o.pluginInventoryImageWithDigest)
 	allImages = append(allImages, &ImageCopyInfo{
 		SourceTarFilePath: pluginInventoryFileNameTar,
 		RelativeImagePath: GetImageRelativePath(o.PluginInventoryImage, path.Dir(o.PluginInventoryImage), false),
+		Tag:               inventoryImageTag,
+		Digest:            inventoryImageDigest,
 	})
 
-	// Process all plugin entries and download the oci image as tar file
-	for _, pe := range pluginEntries {
+	size, count, unknownSizes, err := plugins.imagesSize()
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if size > 0 {
+		log.Infof("downloading %s of plugin images", utils.FormatBytes(size))
+		if unknownSizes > 0 {
+			log.Infof("the size of %d plugin images is unknown", unknownSizes)
+		}
+	}
+	// The progress of the download is based on the number of images when the size of some is unknown
+	if unknownSizes > 0 {
+		size = 0
+	}
+	progress := newTransferProgress("downloading", count, size)
+	o.progress.relayTo(progress)
+	defer o.progress.relayTo(nil)
+
+	// Process all plugin entries and download the oci images as tar files concurrently
+	concurrency := o.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	eg, ctx := errgroup.WithContext(context.Background())
+	eg.SetLimit(concurrency)
+	err = plugins.forEach(func(pe *plugininventory.PluginInventoryEntry) error {
 		for version, artifacts := range pe.Artifacts {
 			for _, a := range artifacts {
+				if a.Image == "" {
+					// Only OCI images can be copied to the air-gapped repository
+					return errors.Errorf("plugin %q version %q is distributed with the artifact URI %q which cannot be included in a plugin bundle", pe.Name, version, a.URI)
+				}
 				log.Infof("---------------------------")
-				log.Infof("downloading image %q", a.Image)
 				tarfileName := fmt.Sprintf("%s-%s-%s_%s-%s.tar.gz", pe.Name, pe.Target, a.OS, a.Arch, version)
-				err = o.ImageProcessor.CopyImageToTar(a.Image, filepath.Join(downloadDir, tarfileName))
+				imageWithDigest, err := carvelhelpers.PinImageToDigest(o.ImageProcessor, a.Image)
 				if err != nil {
-					return "", nil, err
+					return err
 				}
-				allImages = append(allImages, &ImageCopyInfo{
+				tag, digest := GetImageTagAndDigest(imageWithDigest)
+				imageInfo := &ImageCopyInfo{
 					SourceTarFilePath: tarfileName,
 					RelativeImagePath: GetImageRelativePath(a.Image, path.Dir(o.PluginInventoryImage), false),
+					Tag:               tag,
+					Digest:            digest,
+					Plugin:            fmt.Sprintf("%s@%s:%s", pe.Name, pe.Target, version),
+				}
+				if o.isBaseImage(imageInfo) {
+					log.Infof("image %q is part of the base, skipping", a.Image)
+					imageInfo.SourceTarFilePath = ""
+					baseImages = append(baseImages, imageInfo)
+					progress.imageTransferred(imageWithDigest, a.Size, true)
+					continue
+				}
+
+				if ctx.Err() != nil {
+					// The download of another image failed
+					return nil
+				}
+				image, size := a.Image, a.Size
+				eg.Go(func() error {
+					if size > 0 {
+						log.Infof("downloading image %q (%s)", image, utils.FormatBytes(size))
+					} else {
+						log.Infof("downloading image %q", image)
+					}
+					tarFile := filepath.Join(downloadDir, tarfileName)
+					downloaded, err := o.downloadImageToTar(imageWithDigest, tarFile)
+					if err != nil {
+						return err
+					}
+					if size <= 0 {
+						if info, err := os.Stat(tarFile); err == nil {
+							size = info.Size()
+						}
+					}
+					progress.imageTransferred(imageWithDigest, size, !downloaded)
+					return nil
 				})
+				allImages = append(allImages, imageInfo)
 			}
 		}
+		return nil
+	})
+	if waitErr := eg.Wait(); err == nil {
+		err = waitErr
+	}
+	progress.finish(err)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if len(baseImages) > 0 {
+		log.Infof("%d plugin images of the base are not included in the plugin bundle", len(baseImages))
+	}
+	return relativeInventoryImagePathWithTag, allImages, baseImages, nil
+}
+
+// downloadImageToTar downloads the image pinned to its digest as the tar file, and tells if the image was
+// downloaded rather than already downloaded. The image is downloaded to the download directory of the plugin
+// bundle first, named after the image and its digest, where it is kept when the download of the plugin bundle
+// fails so it is not downloaded again when resumed.
+func (o *DownloadPluginBundleOptions) downloadImageToTar(imageWithDigest, tarFile string) (bool, error) {
+	downloaded := false
+	downloadedTar := filepath.Join(o.ToTar+PluginBundleDownloadDirSuffix, downloadedImagesDirName, fmt.Sprintf("%x.tar.gz", sha256.Sum256([]byte(imageWithDigest))))
+	if _, err := os.Stat(downloadedTar); err == nil {
+		log.Infof("image %q was already downloaded", imageWithDigest)
+	} else {
+		// The image is only renamed once fully downloaded, a partially downloaded image is downloaded again
+		partialTar := downloadedTar + ".partial"
+		if err := o.ImageProcessor.CopyImageToTar(imageWithDigest, partialTar); err != nil {
+			os.Remove(partialTar)
+			return false, err
+		}
+		if err := os.Rename(partialTar, downloadedTar); err != nil {
+			return false, err
+		}
+		downloaded = true
 	}
-	return relativeInventoryImagePathWithTag, allImages, nil
+	// The image is linked rather than copied as the download directory holds the plugin bundle directory
+	if err := os.Link(downloadedTar, tarFile); err == nil {
+		return downloaded, nil
+	}
+	return downloaded, copyFile(downloadedTar, tarFile)
+}
+
+// copyFile copies the content of the source file to the destination file
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// cleanUpDownloadDir removes the download directory of the plugin bundle once the plugin bundle is
+// downloaded. It is kept when the download fails after downloading some images, to resume it.
+func cleanUpDownloadDir(downloadDir string, downloadErr error) {
+	if downloadErr != nil {
+		if entries, err := os.ReadDir(filepath.Join(downloadDir, downloadedImagesDirName)); err == nil && len(entries) > 0 {
+			log.Infof("the images downloaded so far are kept in %q, download the plugin bundle again to resume the download", downloadDir)
+			return
+		}
+	}
+	os.RemoveAll(downloadDir)
 }
 
 // downloadImagesAsTarFile downloads plugin inventory image and all plugin images
 // as tar file to the specified directory
-//
-//nolint:unparam
-func (o *DownloadPluginBundleOptions) getListOfImages(pluginEntries []*plugininventory.PluginInventoryEntry) (map[string]interface{}, error) {
+func (o *DownloadPluginBundleOptions) getListOfImages(plugins *selectedPlugins) (map[string]interface{}, error) {
 	images := []string{}
 	images = append(images, o.PluginInventoryImage)
 
 	// Process all plugin entries and download the oci image as tar file
-	for _, pe := range pluginEntries {
+	err := plugins.forEach(func(pe *plugininventory.PluginInventoryEntry) error {
 		for _, artifacts := range pe.Artifacts {
 			for _, a := range artifacts {
-				images = append(images, a.Image)
+				if a.Image != "" {
+					images = append(images, a.Image)
+				}
 			}
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the plugins from database")
 	}
 
 	metadata := make(map[string]interface{})
@@ -360,23 +654,105 @@ func (o *DownloadPluginBundleOptions) getListOfImages(pluginEntries []*plugininv
 // validateOptions validates the provided options and returns
 // error if contains invalid option
 func (o *DownloadPluginBundleOptions) validateOptions() error {
+	if err := o.Connection.Validate(); err != nil {
+		return err
+	}
+	if o.ImageProcessor == nil {
+		o.progress = &progressRelay{}
+		o.ImageProcessor = carvelhelpers.NewCachingImageOperations(newImageOperations(carvelhelpers.WithConnectionOptions(o.Connection), carvelhelpers.WithProgressCallback(o.progress.report)))
+	}
+	if o.Compression == "" {
+		o.Compression = pluginBundleCompressionFromFileName(o.ToTar)
+	}
+	if err := ValidatePluginBundleCompression(o.Compression, o.CompressionLevel); err != nil {
+		return err
+	}
+	if o.MaxPartSize < 0 {
+		return errors.Errorf("invalid maximum part size %d, it must be positive", o.MaxPartSize)
+	}
 	if !o.DryRun {
 		// Verify tar file to be used to save plugin bundle
 		err := o.verifyTarFile()
 		if err != nil {
 			return err
 		}
+		// Fail before downloading the plugin bundle if it cannot be signed
+		if o.SignKey != "" {
+			signatureFile := o.ToTar + PluginBundleSignatureFileSuffix
+			if _, err = os.Stat(signatureFile); err == nil {
+				return fmt.Errorf(fileExists, signatureFile)
+			}
+			if err = cosignhelper.ValidateSigningKey(context.Background(), o.SignKey); err != nil {
+				return errors.Wrap(err, "unable to sign the plugin bundle")
+			}
+		}
+	}
+
+	// Resolve the digest of the inventory image once, all subsequent operations use this digest
+	imageWithDigest, err := carvelhelpers.PinImageToDigest(o.ImageProcessor, o.PluginInventoryImage)
+	if err != nil {
+		return err
 	}
+	o.pluginInventoryImageWithDigest = imageWithDigest
 
 	// Verify the inventory image signature before downloading the plugin inventory database
-	err := sigverifier.VerifyInventoryImageSignature(o.PluginInventoryImage)
+	err = sigverifier.VerifyInventoryImageSignatureWithDigest(o.PluginInventoryImage, o.pluginInventoryImageWithDigest)
 	if err != nil {
 		return err
 	}
 
+	if o.Base != "" {
+		o.baseImages, err = readBasePluginBundleImages(o.Base)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// readBasePluginBundleImages returns the images of the base plugin bundle, the ones of the bundle
+// and the ones of its own base, by relative image path and digest. The images without digest,
+// in the plugin bundles downloaded by older CLIs, are ignored as they cannot be compared.
+func readBasePluginBundleImages(base string) (map[string]bool, error) {
+	manifest, err := readPluginMigrationManifest(base)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read the base plugin bundle %q", base)
+	}
+	baseImages := map[string]bool{}
+	for _, ic := range append(append([]*ImageCopyInfo{}, manifest.ImagesToCopy...), manifest.BaseImages...) {
+		if ic.Digest != "" {
+			baseImages[ic.RelativeImagePath+"@"+ic.Digest] = true
+		}
+	}
+	if len(baseImages) == 0 {
+		log.Warningf("the base plugin bundle %q does not record the digests of its images, all the images will be downloaded", base)
+	}
+	return baseImages, nil
+}
+
+// isBaseImage tells if the image, with the same digest, is part of the base plugin bundle or is
+// already present in the base repository
+func (o *DownloadPluginBundleOptions) isBaseImage(ic *ImageCopyInfo) bool {
+	if o.baseImages[ic.RelativeImagePath+"@"+ic.Digest] {
+		return true
+	}
+	if o.BaseRepo == "" {
+		return false
+	}
+	repoImagePath, err := utils.JoinURL(o.BaseRepo, ic.RelativeImagePath)
+	if err != nil {
+		return false
+	}
+	image := repoImagePath + "@" + ic.Digest
+	if ic.Tag != "" {
+		// The image must also be tagged as in the plugin bundle
+		image = repoImagePath + ":" + ic.Tag
+	}
+	algorithm, hex, err := o.ImageProcessor.GetImageDigest(image)
+	return err == nil && algorithm+":"+hex == ic.Digest
+}
+
 func (o *DownloadPluginBundleOptions) verifyTarFile() error {
 	dir := filepath.Dir(o.ToTar)
 	_, err := os.Stat(dir)
@@ -398,12 +774,16 @@ func (o *DownloadPluginBundleOptions) verifyTarFile() error {
 
 // savePluginMigrationManifestFile save the plugin_migration_manifest.yaml file
 // to the provided pluginBundleDir
-func savePluginMigrationManifestFile(relativeInventoryImagePathWithTag string, imagesToCopy []*ImageCopyInfo, inventoryMetadataImageInfo *ImagePublishInfo, pluginBundleDir string) error {
+func savePluginMigrationManifestFile(relativeInventoryImagePathWithTag string, imagesToCopy, baseImages []*ImageCopyInfo, pluginGroups []*PluginGroupCopyInfo, inventoryMetadataImageInfo *ImagePublishInfo, parts []*PluginBundlePart, pluginBundleDir string) error {
 	// Save all downloaded images as part of manifest file
 	manifest := PluginMigrationManifest{
+		ManifestVersion:                   PluginMigrationManifestVersion,
 		RelativeInventoryImagePathWithTag: relativeInventoryImagePathWithTag,
 		ImagesToCopy:                      imagesToCopy,
+		BaseImages:                        baseImages,
 		InventoryMetadataImage:            inventoryMetadataImageInfo,
+		PluginGroups:                      pluginGroups,
+		Parts:                             parts,
 	}
 	bytes, err := yaml.Marshal(&manifest)
 	if err != nil {
@@ -416,10 +796,107 @@ func savePluginMigrationManifestFile(relativeInventoryImagePathWithTag string, i
 	return nil
 }
 
+// savePluginBundleParts moves the image tar files of the plugins to parts of at most o.MaxPartSize bytes,
+// saved as tar files next to o.ToTar, and returns the parts with the first one which is not saved yet.
+// The first part keeps the plugin inventory image, the plugin inventory metadata and the plugin
+// migration manifest. The part of each image is set in imagesToCopy. No part is returned when the
+// plugin bundle does not need to be split.
+func (o *DownloadPluginBundleOptions) savePluginBundleParts(imagesToCopy []*ImageCopyInfo, tempBaseDir, pluginBundleDir string) ([]*PluginBundlePart, error) {
+	entries, err := os.ReadDir(pluginBundleDir)
+	if err != nil {
+		return nil, err
+	}
+	var fileSizes []int64
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		fileSizes = append(fileSizes, info.Size())
+	}
+	if archivedSize(fileSizes...) <= o.MaxPartSize {
+		return nil, nil
+	}
+
+	// Assign the images of the plugins to the parts in their order, starting
+	// a new part when an image does not fit in the current one
+	var partSizes []int64
+	for _, ic := range imagesToCopy[1:] {
+		info, err := os.Stat(filepath.Join(pluginBundleDir, ic.SourceTarFilePath))
+		if err != nil {
+			return nil, err
+		}
+		if archivedSize(info.Size()) > o.MaxPartSize {
+			return nil, errors.Errorf("the image tar file %q (%s) is larger than the maximum part size", ic.SourceTarFilePath, utils.FormatBytes(info.Size()))
+		}
+		imageSize := archivedSize(info.Size()) - archivedSize()
+		if len(partSizes) == 0 || partSizes[len(partSizes)-1]+imageSize > o.MaxPartSize {
+			partSizes = append(partSizes, archivedSize())
+		}
+		partSizes[len(partSizes)-1] += imageSize
+		ic.Part = len(partSizes)
+	}
+	if len(partSizes) == 0 {
+		return nil, nil
+	}
+
+	partDirs := make([]string, len(partSizes)+1)
+	for i := 1; i < len(partDirs); i++ {
+		partDirs[i] = filepath.Join(tempBaseDir, fmt.Sprintf("part%d", i), PluginBundleDirName)
+		if err := os.MkdirAll(partDirs[i], os.ModePerm); err != nil {
+			return nil, errors.Wrap(err, "unable to create temp directory")
+		}
+	}
+	for _, ic := range imagesToCopy[1:] {
+		if err := os.Rename(filepath.Join(pluginBundleDir, ic.SourceTarFilePath), filepath.Join(partDirs[ic.Part], ic.SourceTarFilePath)); err != nil {
+			return nil, err
+		}
+	}
+
+	parts := []*PluginBundlePart{{FileName: filepath.Base(o.ToTar)}}
+	for i := 1; i < len(partDirs); i++ {
+		partFile := pluginBundlePartFileName(o.ToTar, i)
+		if _, err := os.Stat(partFile); err == nil {
+			return nil, fmt.Errorf(fileExists, partFile)
+		}
+		log.Infof("saving part %d of the plugin bundle at: %s", i, partFile)
+		if err := createArchive(partDirs[i], partFile, o.Compression, o.CompressionLevel); err != nil {
+			return nil, errors.Wrap(err, "error while creating archive file")
+		}
+		digest, err := fileDigest(partFile)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, &PluginBundlePart{FileName: filepath.Base(partFile), Digest: digest})
+	}
+	return parts, nil
+}
+
+// getPluginGroupsToCopy returns the versions of the plugin groups with their plugins,
+// to record them in the plugin migration manifest
+func getPluginGroupsToCopy(pgs []*plugininventory.PluginGroup) []*PluginGroupCopyInfo {
+	var pluginGroups []*PluginGroupCopyInfo
+	for _, pg := range pgs {
+		versions := make([]string, 0, len(pg.Versions))
+		for version := range pg.Versions {
+			versions = append(versions, version)
+		}
+		sort.Strings(versions)
+		for _, version := range versions {
+			pgc := &PluginGroupCopyInfo{Group: fmt.Sprintf("%s:%s", plugininventory.PluginGroupToID(pg), version)}
+			for _, p := range pg.Versions[version] {
+				pgc.Plugins = append(pgc.Plugins, fmt.Sprintf("%s@%s:%s", p.Name, p.Target, p.Version))
+			}
+			pluginGroups = append(pluginGroups, pgc)
+		}
+	}
+	return pluginGroups
+}
+
 // savePluginInventoryMetadata saves the plugin inventory metadata database file
 // and returns ImagePublishInfo object containing the details on where to publish
 // the metadata database file as an oci image
-func (o *DownloadPluginBundleOptions) savePluginInventoryMetadata(pgs []*plugininventory.PluginGroup, pes []*plugininventory.PluginInventoryEntry, pluginBundleDir string) (*ImagePublishInfo, error) {
+func (o *DownloadPluginBundleOptions) savePluginInventoryMetadata(pgs []*plugininventory.PluginGroup, plugins *selectedPlugins, pluginBundleDir string) (*ImagePublishInfo, error) {
 	inventoryMetadataDBFileName := plugininventory.SQliteInventoryMetadataDBFileName
 	inventoryMetadataDBFilePath := filepath.Join(pluginBundleDir, inventoryMetadataDBFileName)
 	inventoryMetadataDB := plugininventory.NewSQLiteInventoryMetadata(inventoryMetadataDBFilePath)
@@ -429,22 +906,29 @@ func (o *DownloadPluginBundleOptions) savePluginInventoryMetadata(pgs []*plugini
 		return nil, err
 	}
 
-	for _, pe := range pes {
+	var pluginIDs []*plugininventory.PluginIdentifier
+	err = plugins.forEach(func(pe *plugininventory.PluginInventoryEntry) error {
 		for version := range pe.Artifacts {
-			err := inventoryMetadataDB.InsertPluginIdentifier(&plugininventory.PluginIdentifier{Name: pe.Name, Target: pe.Target, Version: version})
-			if err != nil {
-				return nil, err
-			}
+			pluginIDs = append(pluginIDs, &plugininventory.PluginIdentifier{Name: pe.Name, Target: pe.Target, Version: version})
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := inventoryMetadataDB.InsertPluginIdentifiers(pluginIDs); err != nil {
+		return nil, err
 	}
+
+	var groupIDs []*plugininventory.PluginGroupIdentifier
 	for _, pg := range pgs {
 		for version := range pg.Versions {
-			err := inventoryMetadataDB.InsertPluginGroupIdentifier(&plugininventory.PluginGroupIdentifier{Vendor: pg.Vendor, Publisher: pg.Publisher, Name: pg.Name, Version: version})
-			if err != nil {
-				return nil, err
-			}
+			groupIDs = append(groupIDs, &plugininventory.PluginGroupIdentifier{Vendor: pg.Vendor, Publisher: pg.Publisher, Name: pg.Name, Version: version})
 		}
 	}
+	if err := inventoryMetadataDB.InsertPluginGroupIdentifiers(groupIDs); err != nil {
+		return nil, err
+	}
 
 	pluginInventoryMetadataImage, err := GetPluginInventoryMetadataImage(o.PluginInventoryImage)
 	if err != nil {
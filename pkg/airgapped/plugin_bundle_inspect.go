@@ -0,0 +1,174 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package airgapped
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+)
+
+// InspectPluginBundleOptions defines options for inspecting plugin bundle
+type InspectPluginBundleOptions struct {
+	Tar string
+}
+
+// PluginBundleContent describes the content of a plugin bundle
+type PluginBundleContent struct {
+	// InventoryImage and InventoryMetadataImage are the plugin inventory image and the plugin inventory
+	// metadata image of the bundle, relative to the repository the bundle is uploaded to
+	InventoryImage         string `json:"inventoryImage" yaml:"inventoryImage"`
+	InventoryMetadataImage string `json:"inventoryMetadataImage" yaml:"inventoryMetadataImage"`
+	// Repositories are the repositories of the images of the bundle, relative to the repository
+	// the bundle is uploaded to
+	Repositories []string                   `json:"repositories" yaml:"repositories"`
+	Plugins      []*PluginBundlePlugin      `json:"plugins" yaml:"plugins"`
+	PluginGroups []*PluginBundlePluginGroup `json:"pluginGroups" yaml:"pluginGroups"`
+	// ImagesCount and Size are the number of images of the bundle and their total size in bytes
+	ImagesCount int   `json:"imagesCount" yaml:"imagesCount"`
+	Size        int64 `json:"size" yaml:"size"`
+	// BaseImagesCount is the number of images of the plugins of an incremental bundle which are
+	// not part of the bundle as they are part of its base
+	BaseImagesCount int `json:"baseImagesCount,omitempty" yaml:"baseImagesCount,omitempty"`
+}
+
+// PluginBundlePlugin describes a plugin of a plugin bundle with the versions of the bundle
+type PluginBundlePlugin struct {
+	Name        string   `json:"name" yaml:"name"`
+	Target      string   `json:"target" yaml:"target"`
+	Description string   `json:"description" yaml:"description"`
+	Versions    []string `json:"versions" yaml:"versions"`
+}
+
+// PluginBundlePluginGroup describes a plugin group of a plugin bundle with the versions of the bundle
+type PluginBundlePluginGroup struct {
+	// Group is the plugin group, as vendor-publisher/name
+	Group       string   `json:"group" yaml:"group"`
+	Description string   `json:"description" yaml:"description"`
+	Versions    []string `json:"versions" yaml:"versions"`
+}
+
+// InspectPluginBundle returns the content of the plugin bundle, read from its plugin migration manifest
+// and its plugin inventory database, without uploading it
+func (o *InspectPluginBundleOptions) InspectPluginBundle() (*PluginBundleContent, error) {
+	// create a temporary directory
+	tempDir, err := os.MkdirTemp("", "")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create temp directory")
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Untar the specified plugin bundle to the temp directory
+	log.Infof("extracting %q for inspection...", o.Tar)
+	manifest, err := extractPluginBundle(o.Tar, nil, tempDir)
+	if err != nil {
+		return nil, err
+	}
+
+	pluginBundleDir := filepath.Join(tempDir, PluginBundleDirName)
+	if errs := validatePluginMigrationManifest(manifest, pluginBundleDir); len(errs) > 0 {
+		return nil, errors.Wrap(kerrors.NewAggregate(errs), "invalid plugin bundle")
+	}
+
+	content := &PluginBundleContent{
+		InventoryImage:         manifest.RelativeInventoryImagePathWithTag,
+		InventoryMetadataImage: manifest.InventoryMetadataImage.RelativeImagePathWithTag,
+		ImagesCount:            len(manifest.ImagesToCopy),
+		BaseImagesCount:        len(manifest.BaseImages),
+	}
+	repositories := map[string]bool{}
+	for _, ic := range manifest.ImagesToCopy {
+		repositories[ic.RelativeImagePath] = true
+		info, err := os.Stat(filepath.Join(pluginBundleDir, ic.SourceTarFilePath))
+		if err != nil {
+			return nil, err
+		}
+		content.Size += info.Size()
+	}
+	for repository := range repositories {
+		content.Repositories = append(content.Repositories, repository)
+	}
+	sort.Strings(content.Repositories)
+
+	inventory, err := extractBundledPluginInventory(manifest, pluginBundleDir, tempDir)
+	if err != nil {
+		return nil, err
+	}
+	content.Plugins, err = getPluginBundlePlugins(inventory)
+	if err != nil {
+		return nil, err
+	}
+	content.PluginGroups, err = getPluginBundlePluginGroups(inventory)
+	if err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// getPluginBundlePlugins returns the plugins of the plugin inventory sorted by name and target
+func getPluginBundlePlugins(inventory plugininventory.PluginInventory) ([]*PluginBundlePlugin, error) {
+	// Include the hidden plugins which are part of the bundle
+	plugins, err := inventory.GetPlugins(context.Background(), &plugininventory.PluginInventoryFilter{IncludeHidden: true})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the plugins of the plugin inventory database")
+	}
+	bundlePlugins := make([]*PluginBundlePlugin, 0, len(plugins))
+	for _, p := range plugins {
+		bundlePlugins = append(bundlePlugins, &PluginBundlePlugin{
+			Name:        p.Name,
+			Target:      string(p.Target),
+			Description: p.Description,
+			Versions:    sortedVersions(p.Artifacts),
+		})
+	}
+	sort.Slice(bundlePlugins, func(i, j int) bool {
+		if bundlePlugins[i].Name != bundlePlugins[j].Name {
+			return bundlePlugins[i].Name < bundlePlugins[j].Name
+		}
+		return bundlePlugins[i].Target < bundlePlugins[j].Target
+	})
+	return bundlePlugins, nil
+}
+
+// getPluginBundlePluginGroups returns the plugin groups of the plugin inventory sorted by name
+func getPluginBundlePluginGroups(inventory plugininventory.PluginInventory) ([]*PluginBundlePluginGroup, error) {
+	// Include the hidden plugin groups which are part of the bundle
+	pgs, err := inventory.GetPluginGroups(context.Background(), plugininventory.PluginGroupFilter{IncludeHidden: true})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the plugin groups of the plugin inventory database")
+	}
+	bundleGroups := make([]*PluginBundlePluginGroup, 0, len(pgs))
+	for _, pg := range pgs {
+		bundleGroups = append(bundleGroups, &PluginBundlePluginGroup{
+			Group:       plugininventory.PluginGroupToID(pg),
+			Description: pg.Description,
+			Versions:    sortedVersions(pg.Versions),
+		})
+	}
+	sort.Slice(bundleGroups, func(i, j int) bool {
+		return bundleGroups[i].Group < bundleGroups[j].Group
+	})
+	return bundleGroups, nil
+}
+
+// sortedVersions returns the versions of the map sorted in semver order,
+// or in lexical order if some of them are not valid semantic versions
+func sortedVersions[T any](m map[string]T) []string {
+	versions := make([]string, 0, len(m))
+	for version := range m {
+		versions = append(versions, version)
+	}
+	if err := utils.SortVersions(versions); err != nil {
+		sort.Strings(versions)
+	}
+	return versions
+}
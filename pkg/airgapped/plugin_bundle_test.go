@@ -4,22 +4,33 @@
 package airgapped
 
 import (
+	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
 	"github.com/verybluebot/tarinator-go"
 	"gopkg.in/yaml.v3"
 
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cosignhelper"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/fakes"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/registry"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
 )
@@ -172,90 +183,191 @@ var _ = Describe("Unit tests for download and upload bundle", func() {
 
 	// Plugin bundle manifest file generated based on the above mentioned
 	// plugin entry in the inventory database
-	pluginBundleManifestCompleteRepositoryString := `relativeInventoryImagePathWithTag: /plugin-inventory:latest
+	pluginBundleManifestCompleteRepositoryString := `manifestVersion: v2
+relativeInventoryImagePathWithTag: /plugin-inventory:latest
 inventoryMetadataImage:
     sourceFilePath: plugin_inventory_metadata.db
     relativeImagePathWithTag: /plugin-inventory-metadata:latest
 imagesToCopy:
     - sourceTarFilePath: plugin-inventory-image.tar.gz
       relativeImagePath: /plugin-inventory
+      tag: latest
+      digest: sha256:fakedigest
     - sourceTarFilePath: bar-kubernetes-darwin_amd64-v0.0.1.tar.gz
       relativeImagePath: /path/darwin/amd64/kubernetes/bar
+      tag: v0.0.1
+      digest: sha256:fakedigest
+      plugin: bar@kubernetes:v0.0.1
     - sourceTarFilePath: foo-global-darwin_amd64-v0.0.2.tar.gz
       relativeImagePath: /path/darwin/amd64/global/foo
+      tag: v0.0.2
+      digest: sha256:fakedigest
+      plugin: foo@global:v0.0.2
     - sourceTarFilePath: foo-global-linux_amd64-v0.0.2.tar.gz
       relativeImagePath: /path/linux/amd64/global/foo
+      tag: v0.0.2
+      digest: sha256:fakedigest
+      plugin: foo@global:v0.0.2
     - sourceTarFilePath: telemetry-global-darwin_amd64-v0.0.1.tar.gz
       relativeImagePath: /path/darwin/amd64/global/telemetry
+      tag: v0.0.1
+      digest: sha256:fakedigest
+      plugin: telemetry@global:v0.0.1
+pluginGroups:
+    - group: fakevendor-fakepublisher/default:v1.0.0
+      plugins:
+        - bar@kubernetes:v0.0.1
+    - group: fakevendor-fakepublisher/default2:v1.0.0
+      plugins:
+        - bar@kubernetes:v0.0.1
+    - group: vmware-tanzucli/essentials:v0.0.1
+      plugins:
+        - telemetry@global:v0.0.1
 `
 
 	// Plugin bundle manifest file generated based on the above mentioned
 	// plugin entry in the inventory database with only single plugin group specified
-	pluginBundleManifestDefaultGroupOnlyString := `relativeInventoryImagePathWithTag: /plugin-inventory:latest
+	pluginBundleManifestDefaultGroupOnlyString := `manifestVersion: v2
+relativeInventoryImagePathWithTag: /plugin-inventory:latest
 inventoryMetadataImage:
     sourceFilePath: plugin_inventory_metadata.db
     relativeImagePathWithTag: /plugin-inventory-metadata:latest
 imagesToCopy:
     - sourceTarFilePath: plugin-inventory-image.tar.gz
       relativeImagePath: /plugin-inventory
+      tag: latest
+      digest: sha256:fakedigest
     - sourceTarFilePath: bar-kubernetes-darwin_amd64-v0.0.1.tar.gz
       relativeImagePath: /path/darwin/amd64/kubernetes/bar
+      tag: v0.0.1
+      digest: sha256:fakedigest
+      plugin: bar@kubernetes:v0.0.1
     - sourceTarFilePath: telemetry-global-darwin_amd64-v0.0.1.tar.gz
       relativeImagePath: /path/darwin/amd64/global/telemetry
+      tag: v0.0.1
+      digest: sha256:fakedigest
+      plugin: telemetry@global:v0.0.1
+pluginGroups:
+    - group: fakevendor-fakepublisher/default:v1.0.0
+      plugins:
+        - bar@kubernetes:v0.0.1
+    - group: fakevendor-fakepublisher/default2:v1.0.0
+      plugins:
+        - bar@kubernetes:v0.0.1
+    - group: vmware-tanzucli/essentials:v0.0.1
+      plugins:
+        - telemetry@global:v0.0.1
 `
 	// Plugin bundle manifest file generated based on the above mentioned
 	// plugin entry in the inventory database with only foo plugin specified
-	pluginBundleManifestFooPluginOnlyString := `relativeInventoryImagePathWithTag: /plugin-inventory:latest
+	pluginBundleManifestFooPluginOnlyString := `manifestVersion: v2
+relativeInventoryImagePathWithTag: /plugin-inventory:latest
 inventoryMetadataImage:
     sourceFilePath: plugin_inventory_metadata.db
     relativeImagePathWithTag: /plugin-inventory-metadata:latest
 imagesToCopy:
     - sourceTarFilePath: plugin-inventory-image.tar.gz
       relativeImagePath: /plugin-inventory
+      tag: latest
+      digest: sha256:fakedigest
     - sourceTarFilePath: telemetry-global-darwin_amd64-v0.0.1.tar.gz
       relativeImagePath: /path/darwin/amd64/global/telemetry
+      tag: v0.0.1
+      digest: sha256:fakedigest
+      plugin: telemetry@global:v0.0.1
     - sourceTarFilePath: foo-global-darwin_amd64-v0.0.2.tar.gz
       relativeImagePath: /path/darwin/amd64/global/foo
+      tag: v0.0.2
+      digest: sha256:fakedigest
+      plugin: foo@global:v0.0.2
     - sourceTarFilePath: foo-global-linux_amd64-v0.0.2.tar.gz
       relativeImagePath: /path/linux/amd64/global/foo
+      tag: v0.0.2
+      digest: sha256:fakedigest
+      plugin: foo@global:v0.0.2
+pluginGroups:
+    - group: vmware-tanzucli/essentials:v0.0.1
+      plugins:
+        - telemetry@global:v0.0.1
 `
 
 	// Plugin bundle manifest file generated based on the above mentioned
 	// plugin entry in the inventory database with only single plugin group and specific plugin specified
-	pluginBundleManifestDefaultGroupAndFooPluginOnlyString := `relativeInventoryImagePathWithTag: /plugin-inventory:latest
+	pluginBundleManifestDefaultGroupAndFooPluginOnlyString := `manifestVersion: v2
+relativeInventoryImagePathWithTag: /plugin-inventory:latest
 inventoryMetadataImage:
     sourceFilePath: plugin_inventory_metadata.db
     relativeImagePathWithTag: /plugin-inventory-metadata:latest
 imagesToCopy:
     - sourceTarFilePath: plugin-inventory-image.tar.gz
       relativeImagePath: /plugin-inventory
+      tag: latest
+      digest: sha256:fakedigest
     - sourceTarFilePath: bar-kubernetes-darwin_amd64-v0.0.1.tar.gz
       relativeImagePath: /path/darwin/amd64/kubernetes/bar
+      tag: v0.0.1
+      digest: sha256:fakedigest
+      plugin: bar@kubernetes:v0.0.1
     - sourceTarFilePath: telemetry-global-darwin_amd64-v0.0.1.tar.gz
       relativeImagePath: /path/darwin/amd64/global/telemetry
+      tag: v0.0.1
+      digest: sha256:fakedigest
+      plugin: telemetry@global:v0.0.1
     - sourceTarFilePath: foo-global-darwin_amd64-v0.0.2.tar.gz
       relativeImagePath: /path/darwin/amd64/global/foo
+      tag: v0.0.2
+      digest: sha256:fakedigest
+      plugin: foo@global:v0.0.2
     - sourceTarFilePath: foo-global-linux_amd64-v0.0.2.tar.gz
       relativeImagePath: /path/linux/amd64/global/foo
+      tag: v0.0.2
+      digest: sha256:fakedigest
+      plugin: foo@global:v0.0.2
+pluginGroups:
+    - group: fakevendor-fakepublisher/default:v1.0.0
+      plugins:
+        - bar@kubernetes:v0.0.1
+    - group: vmware-tanzucli/essentials:v0.0.1
+      plugins:
+        - telemetry@global:v0.0.1
 `
 
 	// Plugin bundle manifest file generated based on the above mentioned
 	// plugin entry in the inventory database with only foo and bar plugin specified
-	pluginBundleManifestFooAndBarPluginOnlyString := `relativeInventoryImagePathWithTag: /plugin-inventory:latest
+	pluginBundleManifestFooAndBarPluginOnlyString := `manifestVersion: v2
+relativeInventoryImagePathWithTag: /plugin-inventory:latest
 inventoryMetadataImage:
     sourceFilePath: plugin_inventory_metadata.db
     relativeImagePathWithTag: /plugin-inventory-metadata:latest
 imagesToCopy:
     - sourceTarFilePath: plugin-inventory-image.tar.gz
       relativeImagePath: /plugin-inventory
+      tag: latest
+      digest: sha256:fakedigest
     - sourceTarFilePath: telemetry-global-darwin_amd64-v0.0.1.tar.gz
       relativeImagePath: /path/darwin/amd64/global/telemetry
+      tag: v0.0.1
+      digest: sha256:fakedigest
+      plugin: telemetry@global:v0.0.1
     - sourceTarFilePath: foo-global-darwin_amd64-v0.0.2.tar.gz
       relativeImagePath: /path/darwin/amd64/global/foo
+      tag: v0.0.2
+      digest: sha256:fakedigest
+      plugin: foo@global:v0.0.2
     - sourceTarFilePath: foo-global-linux_amd64-v0.0.2.tar.gz
       relativeImagePath: /path/linux/amd64/global/foo
+      tag: v0.0.2
+      digest: sha256:fakedigest
+      plugin: foo@global:v0.0.2
     - sourceTarFilePath: bar-kubernetes-darwin_amd64-v0.0.1.tar.gz
       relativeImagePath: /path/darwin/amd64/kubernetes/bar
+      tag: v0.0.1
+      digest: sha256:fakedigest
+      plugin: bar@kubernetes:v0.0.1
+pluginGroups:
+    - group: vmware-tanzucli/essentials:v0.0.1
+      plugins:
+        - telemetry@global:v0.0.1
 `
 
 	// Configure the configuration before running the tests
@@ -274,6 +386,7 @@ imagesToCopy:
 			ImageProcessor:  fakeImageOperations,
 		}
 		os.Setenv(constants.PluginDiscoveryImageSignatureVerificationSkipList, dpbo.PluginInventoryImage)
+		fakeImageOperations.GetImageDigestReturns("sha256", "fakedigest", nil)
 	})
 	AfterEach(func() {
 		defer os.RemoveAll(tempTestDir)
@@ -289,21 +402,21 @@ imagesToCopy:
 		Expect(err).ToNot(HaveOccurred())
 
 		db := plugininventory.NewSQLiteInventory(dbFile, "")
-		err = db.CreateSchema()
+		err = db.CreateSchema(context.Background())
 		Expect(err).ToNot(HaveOccurred())
 
-		err = db.InsertPlugin(pluginEntryFoo)
+		err = db.InsertPlugin(context.Background(), pluginEntryFoo)
 		Expect(err).ToNot(HaveOccurred())
-		err = db.InsertPlugin(pluginEntryBar)
+		err = db.InsertPlugin(context.Background(), pluginEntryBar)
 		Expect(err).ToNot(HaveOccurred())
-		err = db.InsertPluginGroup(pluginGroupEntry, true)
+		err = db.InsertPluginGroup(context.Background(), pluginGroupEntry, true)
 		Expect(err).ToNot(HaveOccurred())
-		err = db.InsertPluginGroup(pluginGroupEntry2, true)
+		err = db.InsertPluginGroup(context.Background(), pluginGroupEntry2, true)
 		Expect(err).ToNot(HaveOccurred())
 
-		err = db.InsertPlugin(essentialPluginEntryTelemetry)
+		err = db.InsertPlugin(context.Background(), essentialPluginEntryTelemetry)
 		Expect(err).ToNot(HaveOccurred())
-		err = db.InsertPluginGroup(essentialPluginGroupEntry, true)
+		err = db.InsertPluginGroup(context.Background(), essentialPluginGroupEntry, true)
 		Expect(err).ToNot(HaveOccurred())
 		return nil
 	}
@@ -423,6 +536,32 @@ imagesToCopy:
 			}
 		})
 
+		var _ = It("when the plugin inventory image cannot be resolved to a digest, it should return an error", func() {
+			fakeImageOperations.GetImageDigestReturns("", "", errors.New("fake error"))
+
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("unable to resolve the digest of the image"))
+		})
+
+		var _ = It("when everything works as expected, it should download the images pinned to their digest", func() {
+			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadInventoryImageAndSaveFilesToDirStub)
+			fakeImageOperations.CopyImageToTarCalls(copyImageToTarStub)
+			downloadCallCount := fakeImageOperations.DownloadImageAndSaveFilesToDirCallCount()
+			copyCallCount := fakeImageOperations.CopyImageToTarCallCount()
+
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+
+			image, _ := fakeImageOperations.DownloadImageAndSaveFilesToDirArgsForCall(downloadCallCount)
+			Expect(image).To(Equal(dpbo.PluginInventoryImage + "@sha256:fakedigest"))
+			Expect(fakeImageOperations.CopyImageToTarCallCount()).To(BeNumerically(">", copyCallCount))
+			for i := copyCallCount; i < fakeImageOperations.CopyImageToTarCallCount(); i++ {
+				image, _ = fakeImageOperations.CopyImageToTarArgsForCall(i)
+				Expect(image).To(HaveSuffix("@sha256:fakedigest"))
+			}
+		})
+
 		var _ = It("when group specified does not exists, it should return an error", func() {
 			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadInventoryImageAndSaveFilesToDirStub)
 			fakeImageOperations.CopyImageToTarCalls(copyImageToTarStub)
@@ -630,6 +769,85 @@ imagesToCopy:
 
 			Expect(images).To(ContainElements(expectedImages))
 		})
+
+		var _ = It("when the zstd compression is specified, it should compress the plugin bundle with zstd which can be uploaded", func() {
+			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadInventoryImageAndSaveFilesToDirStub)
+			fakeImageOperations.CopyImageToTarCalls(copyImageToTarStub)
+			dpbo.ToTar = filepath.Join(tempTestDir, "plugin_bundle.tar.zst")
+			dpbo.Compression = PluginBundleCompressionZstd
+			dpbo.CompressionLevel = 19
+
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			bytes, err := os.ReadFile(dpbo.ToTar)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bytes[:4]).To(Equal(zstdMagic))
+
+			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadInventoryMetadataImageWithNoExistingPlugins)
+			fakeImageOperations.CopyImageFromTarReturns(nil)
+			upbo.Tar = dpbo.ToTar
+			err = upbo.UploadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		var _ = It("when the compression level is invalid, it should return an error", func() {
+			dpbo.Compression = PluginBundleCompressionGzip
+			dpbo.CompressionLevel = 12
+
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid gzip compression level 12"))
+		})
+
+		var _ = It("when the images are downloaded concurrently, it should download all the images in the order of the plugins", func() {
+			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadInventoryImageAndSaveFilesToDirStub)
+			fakeImageOperations.CopyImageToTarCalls(copyImageToTarStub)
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			manifest, err := readPluginMigrationManifest(dpbo.ToTar)
+			Expect(err).NotTo(HaveOccurred())
+
+			dpbo.ToTar = filepath.Join(tempTestDir, "plugin_bundle_concurrent.tar")
+			dpbo.Concurrency = 4
+			err = dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			concurrentManifest, err := readPluginMigrationManifest(dpbo.ToTar)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(concurrentManifest.ImagesToCopy).To(Equal(manifest.ImagesToCopy))
+			// The download directory is removed once the plugin bundle is downloaded
+			Expect(dpbo.ToTar + PluginBundleDownloadDirSuffix).NotTo(BeADirectory())
+		})
+
+		var _ = It("when the download fails, it should resume the download without downloading the images already downloaded", func() {
+			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadInventoryImageAndSaveFilesToDirStub)
+			downloadedImages := map[string]bool{}
+			fakeImageOperations.CopyImageToTarCalls(func(image, tarfile string) error {
+				if strings.Contains(image, "/bar:") {
+					return errors.New("fake network error")
+				}
+				downloadedImages[image] = true
+				return copyImageToTarStub(image, tarfile)
+			})
+			dpbo.Concurrency = 1
+
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake network error"))
+			Expect(downloadedImages).NotTo(BeEmpty())
+			Expect(dpbo.ToTar + PluginBundleDownloadDirSuffix).To(BeADirectory())
+			Expect(dpbo.ToTar).NotTo(BeAnExistingFile())
+
+			fakeImageOperations.CopyImageToTarCalls(func(image, tarfile string) error {
+				Expect(downloadedImages).NotTo(HaveKey(image))
+				downloadedImages[image] = true
+				return copyImageToTarStub(image, tarfile)
+			})
+			err = dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			// The plugin inventory image and the four images of the plugins
+			Expect(downloadedImages).To(HaveLen(5))
+			Expect(dpbo.ToTar + PluginBundleDownloadDirSuffix).NotTo(BeADirectory())
+		})
 	})
 
 	var _ = Context("Tests for uploading plugin bundle when downloading entire plugin repository with all plugin", func() {
@@ -639,6 +857,9 @@ imagesToCopy:
 
 			err := dpbo.DownloadPluginBundle()
 			Expect(err).NotTo(HaveOccurred())
+
+			// The images are not present in the destination repository
+			fakeImageOperations.GetImageDigestReturns("", "", errors.New("image not found"))
 		})
 
 		var _ = It("when incorrect tarfile is provided, it should return an error", func() {
@@ -687,6 +908,900 @@ imagesToCopy:
 			err := upbo.UploadPluginBundle()
 			Expect(err).NotTo(HaveOccurred())
 		})
+
+		var _ = It("when the existing inventory metadata has some of the plugins with the fail-on-conflict merge policy, it should return an error before uploading images", func() {
+			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadInventoryMetadataImageWithExistingPlugins)
+			fakeImageOperations.CopyImageFromTarReturns(nil)
+			copyCount := fakeImageOperations.CopyImageFromTarCallCount()
+			upbo.MergePolicy = plugininventory.MetadataMergePolicyFailOnConflict
+			err := upbo.UploadPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("error while merging the plugin inventory metadata database before uploading images"))
+			Expect(err.Error()).To(ContainSubstring("entries are already in the inventory metadata"))
+			Expect(fakeImageOperations.CopyImageFromTarCallCount()).To(Equal(copyCount))
+		})
+
+		var _ = It("when the existing inventory metadata has none of the plugins with the fail-on-conflict merge policy, it should not return an error", func() {
+			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadInventoryMetadataImageWithNoExistingPlugins)
+			fakeImageOperations.CopyImageFromTarReturns(nil)
+			upbo.MergePolicy = plugininventory.MetadataMergePolicyFailOnConflict
+			err := upbo.UploadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		var _ = It("with the replace merge policy, it should not fetch the existing inventory metadata", func() {
+			fakeImageOperations.DownloadImageAndSaveFilesToDirReturns(errors.New("unexpected download"))
+			fakeImageOperations.CopyImageFromTarReturns(nil)
+			downloadCount := fakeImageOperations.DownloadImageAndSaveFilesToDirCallCount()
+			upbo.MergePolicy = plugininventory.MetadataMergePolicyReplace
+			err := upbo.UploadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeImageOperations.DownloadImageAndSaveFilesToDirCallCount()).To(Equal(downloadCount))
+		})
+
+		var _ = It("when no image processor is set, it should create one with the connection options, the progress callback and the retry policy", func() {
+			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadInventoryMetadataImageWithNoExistingPlugins)
+			fakeImageOperations.CopyImageFromTarReturns(nil)
+			var created int
+			defer func(f func(...carvelhelpers.ImageOperationsOption) carvelhelpers.ImageOperationsImpl) {
+				newImageOperations = f
+			}(newImageOperations)
+			newImageOperations = func(opts ...carvelhelpers.ImageOperationsOption) carvelhelpers.ImageOperationsImpl {
+				created++
+				Expect(opts).To(HaveLen(3))
+				return fakeImageOperations
+			}
+			upbo.ImageProcessor = nil
+			upbo.Connection = &registry.ConnectionOptions{SkipCertVerify: true, HTTPSProxy: "http://proxy.example.com:3128"}
+
+			err := upbo.UploadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created).To(Equal(1))
+		})
+
+		var _ = It("when the connection options are invalid, it should return an error before uploading images", func() {
+			copyCount := fakeImageOperations.CopyImageFromTarCallCount()
+			upbo.Connection = &registry.ConnectionOptions{CACertPaths: []string{filepath.Join(tempTestDir, "does-not-exist.crt")}}
+
+			err := upbo.UploadPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("unable to read the CA certificates file"))
+			Expect(fakeImageOperations.CopyImageFromTarCallCount()).To(Equal(copyCount))
+		})
+
+		var _ = It("when uploading to an OCI image layout directory, it should write the images to the directory", func() {
+			fakeImageOperations.DownloadImageAndSaveFilesToDirReturns(errors.New("image not found"))
+			fakeImageOperations.CopyImageFromTarReturns(nil)
+			layoutDir := filepath.Join(tempTestDir, "staging")
+			var usedLayoutDir string
+			defer func(f func(string) carvelhelpers.ImageOperationsImpl) { newOCILayoutImageOperations = f }(newOCILayoutImageOperations)
+			newOCILayoutImageOperations = func(dir string) carvelhelpers.ImageOperationsImpl {
+				usedLayoutDir = dir
+				return fakeImageOperations
+			}
+			copyCount := fakeImageOperations.CopyImageFromTarCallCount()
+			upbo.DestinationRepo = "file://" + layoutDir
+
+			err := upbo.UploadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(usedLayoutDir).To(Equal(layoutDir))
+			Expect(fakeImageOperations.CopyImageFromTarCallCount()).To(BeNumerically(">", copyCount))
+			for i := copyCount; i < fakeImageOperations.CopyImageFromTarCallCount(); i++ {
+				_, repoImagePath := fakeImageOperations.CopyImageFromTarArgsForCall(i)
+				Expect(repoImagePath).To(HavePrefix("file://" + filepath.ToSlash(layoutDir) + "/"))
+			}
+			metadataImage, _ := fakeImageOperations.PushImageArgsForCall(fakeImageOperations.PushImageCallCount() - 1)
+			Expect(metadataImage).To(HavePrefix("file://" + filepath.ToSlash(layoutDir) + "/"))
+		})
+
+		var _ = It("when uploading to several destination repositories, it should upload the plugin bundle to each of them", func() {
+			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadInventoryMetadataImageWithExistingPlugins)
+			fakeImageOperations.CopyImageFromTarReturns(nil)
+			copyCount := fakeImageOperations.CopyImageFromTarCallCount()
+			pushCount := fakeImageOperations.PushImageCallCount()
+			upbo.DestinationRepos = []string{"fake.mirror.abc/plugin/path", upbo.DestinationRepo}
+
+			err := upbo.UploadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			uploadedTo := map[string]int{}
+			for i := copyCount; i < fakeImageOperations.CopyImageFromTarCallCount(); i++ {
+				_, repoImagePath := fakeImageOperations.CopyImageFromTarArgsForCall(i)
+				uploadedTo[strings.SplitN(repoImagePath, "/", 2)[0]]++
+			}
+			Expect(uploadedTo).To(HaveLen(2))
+			Expect(uploadedTo["fake.newfakerepo.abc"]).To(Equal(uploadedTo["fake.mirror.abc"]))
+			Expect(fakeImageOperations.PushImageCallCount()).To(Equal(pushCount + 2))
+			metadataImage, _ := fakeImageOperations.PushImageArgsForCall(pushCount + 1)
+			Expect(metadataImage).To(HavePrefix("fake.mirror.abc/plugin/path/"))
+		})
+
+		var _ = It("when uploading to one of several destination repositories fails, it should upload the plugin bundle to the others and return an error", func() {
+			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadInventoryMetadataImageWithNoExistingPlugins)
+			fakeImageOperations.CopyImageFromTarCalls(func(_, repoImagePath string) error {
+				if strings.HasPrefix(repoImagePath, "fake.failing.abc/") {
+					return errors.New("fake error")
+				}
+				return nil
+			})
+			pushCount := fakeImageOperations.PushImageCallCount()
+			upbo.DestinationRepos = []string{"fake.failing.abc/plugin/path", "fake.mirror.abc/plugin/path"}
+
+			err := upbo.UploadPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("the plugin bundle could not be uploaded to 1 of the 3 destinations"))
+			Expect(err.Error()).To(ContainSubstring(`upload to "fake.failing.abc/plugin/path" failed`))
+			Expect(err.Error()).To(ContainSubstring("fake error"))
+			Expect(err.Error()).NotTo(ContainSubstring("fake.mirror.abc"))
+			Expect(fakeImageOperations.PushImageCallCount()).To(Equal(pushCount + 2))
+		})
+
+		var _ = It("when a mirrors file is specified, it should upload the plugin bundle to the repositories of the mirrors file", func() {
+			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadInventoryMetadataImageWithNoExistingPlugins)
+			fakeImageOperations.CopyImageFromTarReturns(nil)
+			pushCount := fakeImageOperations.PushImageCallCount()
+			mirrors := &PluginBundleMirrors{Mirrors: []*PluginBundleMirror{
+				{Repository: "fake.site1.abc/plugin/path"},
+				{Repository: "fake.site2.abc/plugin/path"},
+			}}
+			b, err := yaml.Marshal(mirrors)
+			Expect(err).NotTo(HaveOccurred())
+			upbo.MirrorsFile = filepath.Join(tempTestDir, "mirrors.yaml")
+			Expect(os.WriteFile(upbo.MirrorsFile, b, 0o600)).To(Succeed())
+			upbo.DestinationRepo = ""
+
+			err = upbo.UploadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeImageOperations.PushImageCallCount()).To(Equal(pushCount + 2))
+			for i, site := range []string{"fake.site1.abc", "fake.site2.abc"} {
+				metadataImage, _ := fakeImageOperations.PushImageArgsForCall(pushCount + i)
+				Expect(metadataImage).To(HavePrefix(site + "/plugin/path/"))
+			}
+		})
+
+		var _ = It("when the mirrors file has a mirror without repository, it should return an error before uploading images", func() {
+			copyCount := fakeImageOperations.CopyImageFromTarCallCount()
+			upbo.MirrorsFile = filepath.Join(tempTestDir, "mirrors.yaml")
+			Expect(os.WriteFile(upbo.MirrorsFile, []byte("mirrors:\n- repository: fake.site1.abc/plugin/path\n- {}\n"), 0o600)).To(Succeed())
+
+			err := upbo.UploadPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("has no repository"))
+			Expect(fakeImageOperations.CopyImageFromTarCallCount()).To(Equal(copyCount))
+		})
+
+		var _ = It("when no destination repository is specified, it should return an error", func() {
+			upbo.DestinationRepo = ""
+
+			err := upbo.UploadPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("no destination repository specified"))
+		})
+
+		var _ = It("when uploading an image fails transiently, it should return the error as the image operations already retried it", func() {
+			var lock sync.Mutex
+			attempts := 0
+			fakeImageOperations.CopyImageFromTarCalls(func(_, _ string) error {
+				lock.Lock()
+				defer lock.Unlock()
+				attempts++
+				return fmt.Errorf("fake error: %w", &transport.Error{StatusCode: http.StatusTooManyRequests})
+			})
+			upbo.Concurrency = 1
+			upbo.Retries = 2
+
+			err := upbo.UploadPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("error while uploading image"))
+			Expect(err.Error()).To(ContainSubstring("fake error"))
+			Expect(attempts).To(Equal(1))
+		})
+
+		var _ = It("when some images are already present in the destination repository, it should only upload the missing images", func() {
+			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadInventoryMetadataImageWithNoExistingPlugins)
+			fakeImageOperations.GetImageDigestCalls(func(image string) (string, string, error) {
+				switch image {
+				case "fake.newfakerepo.abc/plugin/path/darwin/amd64/global/foo:v0.0.2":
+					return "sha256", "fakedigest", nil
+				case "fake.newfakerepo.abc/plugin/path/linux/amd64/global/foo:v0.0.2":
+					// The image was updated since
+					return "sha256", "otherdigest", nil
+				}
+				return "", "", errors.New("image not found")
+			})
+			var lock sync.Mutex
+			var uploaded []string
+			fakeImageOperations.CopyImageFromTarCalls(func(_, repoImagePath string) error {
+				lock.Lock()
+				defer lock.Unlock()
+				uploaded = append(uploaded, repoImagePath)
+				return nil
+			})
+
+			err := upbo.UploadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(uploaded).To(ConsistOf(
+				"fake.newfakerepo.abc/plugin/plugin-inventory",
+				"fake.newfakerepo.abc/plugin/path/darwin/amd64/kubernetes/bar",
+				"fake.newfakerepo.abc/plugin/path/linux/amd64/global/foo",
+				"fake.newfakerepo.abc/plugin/path/darwin/amd64/global/telemetry",
+			))
+		})
+
+		var _ = It("when some plugins and plugin groups are selected, it should only upload them with the essential plugin group", func() {
+			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadInventoryMetadataImageWithNoExistingPlugins)
+			var lock sync.Mutex
+			var uploaded []string
+			fakeImageOperations.CopyImageFromTarCalls(func(_, repoImagePath string) error {
+				lock.Lock()
+				defer lock.Unlock()
+				uploaded = append(uploaded, repoImagePath)
+				return nil
+			})
+			var metadataPlugins, metadataGroups []string
+			fakeImageOperations.PushImageCalls(func(_ string, filePaths []string) error {
+				metadataPlugins = queryMetadataDB(filePaths[0], "SELECT PluginName || '@' || Target || ':' || Version FROM AvailablePluginBinaries")
+				metadataGroups = queryMetadataDB(filePaths[0], "SELECT Vendor || '-' || Publisher || '/' || GroupName || ':' || GroupVersion FROM AvailablePluginGroups")
+				return nil
+			})
+			defer fakeImageOperations.PushImageReturns(nil)
+			upbo.Plugins = []string{"foo:v0.0.2"}
+			upbo.Groups = []string{"fakevendor-fakepublisher/default2"}
+
+			err := upbo.UploadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(uploaded).To(ConsistOf(
+				"fake.newfakerepo.abc/plugin/plugin-inventory",
+				"fake.newfakerepo.abc/plugin/path/darwin/amd64/kubernetes/bar",
+				"fake.newfakerepo.abc/plugin/path/darwin/amd64/global/foo",
+				"fake.newfakerepo.abc/plugin/path/linux/amd64/global/foo",
+				"fake.newfakerepo.abc/plugin/path/darwin/amd64/global/telemetry",
+			))
+			Expect(metadataPlugins).To(ConsistOf("bar@kubernetes:v0.0.1", "foo@global:v0.0.2", "telemetry@global:v0.0.1"))
+			Expect(metadataGroups).To(ConsistOf("fakevendor-fakepublisher/default2:v1.0.0", "vmware-tanzucli/essentials:v0.0.1"))
+		})
+
+		var _ = It("when a plugin or plugin group which is not part of the bundle is selected, it should return an error", func() {
+			copyCount := fakeImageOperations.CopyImageFromTarCallCount()
+
+			upbo.Plugins = []string{"foo:v9.9.9"}
+			err := upbo.UploadPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("error while selecting the plugins and plugin groups to upload"))
+			Expect(err.Error()).To(ContainSubstring(`plugin "foo:v9.9.9" is not part of the plugin bundle`))
+
+			upbo.Plugins = nil
+			upbo.Groups = []string{"fakevendor-fakepublisher/default:v9.9.9"}
+			err = upbo.UploadPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`plugin group "fakevendor-fakepublisher/default:v9.9.9" is not part of the plugin bundle`))
+			Expect(fakeImageOperations.CopyImageFromTarCallCount()).To(Equal(copyCount))
+		})
+
+		var _ = It("when the plugin bundle was created by a newer CLI, it should return an error before uploading images", func() {
+			copyCount := fakeImageOperations.CopyImageFromTarCallCount()
+			upbo.Tar = rewritePluginBundle(dpbo.ToTar, tempTestDir, func(manifest *PluginMigrationManifest, _ string) {
+				manifest.ManifestVersion = "v99"
+			})
+
+			err := upbo.UploadPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`the plugin bundle was created by a newer version of the Tanzu CLI, its plugin migration manifest version "v99" is not supported`))
+			Expect(fakeImageOperations.CopyImageFromTarCallCount()).To(Equal(copyCount))
+		})
+
+		var _ = It("when the plugin bundle was created by an older CLI without manifest version, it should upload the plugin bundle", func() {
+			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadInventoryMetadataImageWithNoExistingPlugins)
+			fakeImageOperations.CopyImageFromTarReturns(nil)
+			upbo.Tar = rewritePluginBundle(dpbo.ToTar, tempTestDir, func(manifest *PluginMigrationManifest, _ string) {
+				manifest.ManifestVersion = ""
+			})
+
+			err := upbo.UploadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		var _ = It("with an invalid merge policy, it should return an error", func() {
+			upbo.MergePolicy = "invalid"
+			err := upbo.UploadPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid merge policy 'invalid'"))
+		})
+	})
+
+	var _ = Context("Tests for signing and verifying the signature of plugin bundle", func() {
+		var privateKey, publicKey, otherPublicKey string
+
+		BeforeEach(func() {
+			privateKey, publicKey = generateCosignKeyPair(tempTestDir, "cosign")
+			_, otherPublicKey = generateCosignKeyPair(tempTestDir, "other")
+			dpbo.SignKey = privateKey
+			upbo.VerifyKey = publicKey
+
+			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadInventoryImageAndSaveFilesToDirStub)
+			fakeImageOperations.CopyImageToTarCalls(copyImageToTarStub)
+			fakeImageOperations.CopyImageFromTarReturns(nil)
+		})
+		AfterEach(func() {
+			os.Unsetenv("COSIGN_PASSWORD")
+		})
+
+		var _ = It("when the plugin bundle is signed, it should verify the signatures of the plugin bundle and of its plugin inventory metadata before uploading it", func() {
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(utils.PathExists(dpbo.ToTar + PluginBundleSignatureFileSuffix)).To(BeTrue())
+
+			var signature string
+			rewritePluginBundle(dpbo.ToTar, tempTestDir, func(manifest *PluginMigrationManifest, _ string) {
+				signature = manifest.InventoryMetadataImage.Signature
+			})
+			Expect(signature).NotTo(BeEmpty())
+
+			fakeImageOperations.GetImageDigestReturns("", "", errors.New("image not found"))
+			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadInventoryMetadataImageWithNoExistingPlugins)
+			err = upbo.UploadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		var _ = It("when the signature of the plugin bundle is specified, it should be used to verify the plugin bundle", func() {
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			upbo.Signature = filepath.Join(tempTestDir, "bundle.sig")
+			Expect(os.Rename(dpbo.ToTar+PluginBundleSignatureFileSuffix, upbo.Signature)).To(Succeed())
+
+			fakeImageOperations.GetImageDigestReturns("", "", errors.New("image not found"))
+			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadInventoryMetadataImageWithNoExistingPlugins)
+			err = upbo.UploadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		var _ = It("when the plugin bundle is signed with another key, it should return an error before uploading images", func() {
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			copyCount := fakeImageOperations.CopyImageFromTarCallCount()
+			upbo.VerifyKey = otherPublicKey
+
+			err = upbo.UploadPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("error while verifying the signature of the plugin bundle"))
+			Expect(fakeImageOperations.CopyImageFromTarCallCount()).To(Equal(copyCount))
+		})
+
+		var _ = It("when the plugin bundle is not signed, it should return an error", func() {
+			dpbo.SignKey = ""
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(utils.PathExists(dpbo.ToTar + PluginBundleSignatureFileSuffix)).To(BeFalse())
+
+			err = upbo.UploadPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("error while reading the signature of the plugin bundle"))
+		})
+
+		var _ = It("when the plugin inventory metadata is altered and the plugin bundle signed again, it should return an error", func() {
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			upbo.Tar = rewritePluginBundle(dpbo.ToTar, tempTestDir, func(manifest *PluginMigrationManifest, pluginBundleDir string) {
+				metadataDB := plugininventory.NewSQLiteInventoryMetadata(filepath.Join(pluginBundleDir, manifest.InventoryMetadataImage.SourceFilePath))
+				Expect(metadataDB.InsertPluginIdentifier(&plugininventory.PluginIdentifier{Name: "baz", Target: "global", Version: "v1.0.0"})).To(Succeed())
+			})
+			signature, err := cosignhelper.SignBlob(context.Background(), privateKey, upbo.Tar)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.WriteFile(upbo.Tar+PluginBundleSignatureFileSuffix, []byte(signature), 0o600)).To(Succeed())
+
+			err = upbo.UploadPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("error while verifying the signature of the plugin inventory metadata"))
+		})
+
+		var _ = It("when the signing key cannot be loaded, it should return an error before downloading the plugin bundle", func() {
+			os.Setenv("COSIGN_PASSWORD", "wrong-password")
+			copyCount := fakeImageOperations.CopyImageToTarCallCount()
+
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("unable to sign the plugin bundle"))
+			Expect(fakeImageOperations.CopyImageToTarCallCount()).To(Equal(copyCount))
+		})
+	})
+
+	var _ = Context("Tests for incremental plugin bundle", func() {
+		var baseTar string
+
+		BeforeEach(func() {
+			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadInventoryImageAndSaveFilesToDirStub)
+			fakeImageOperations.CopyImageToTarCalls(copyImageToTarStub)
+
+			// The base plugin bundle holds all the plugins
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			baseTar = dpbo.ToTar
+			dpbo.ToTar = filepath.Join(tempTestDir, "plugin_bundle_delta.tar")
+			upbo.Tar = dpbo.ToTar
+		})
+
+		// readManifest reads the plugin migration manifest of the plugin bundle
+		readManifest := func(tarFile string) *PluginMigrationManifest {
+			manifest, err := readPluginMigrationManifest(tarFile)
+			Expect(err).NotTo(HaveOccurred())
+			return manifest
+		}
+
+		var _ = It("when the images have not changed since the base plugin bundle, it should only include the plugin inventory image", func() {
+			dpbo.Base = baseTar
+			copyCount := fakeImageOperations.CopyImageToTarCallCount()
+
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeImageOperations.CopyImageToTarCallCount()).To(Equal(copyCount + 1))
+
+			manifest := readManifest(dpbo.ToTar)
+			Expect(manifest.ImagesToCopy).To(HaveLen(1))
+			Expect(manifest.ImagesToCopy[0].SourceTarFilePath).To(Equal("plugin-inventory-image.tar.gz"))
+			Expect(manifest.BaseImages).To(HaveLen(4))
+			for _, ic := range manifest.BaseImages {
+				Expect(ic.SourceTarFilePath).To(BeEmpty())
+				Expect(ic.Digest).To(Equal("sha256:fakedigest"))
+				Expect(ic.Plugin).NotTo(BeEmpty())
+			}
+			// The plugin inventory metadata still holds all the plugins and plugin groups
+			Expect(manifest.PluginGroups).To(HaveLen(3))
+
+			// An incremental plugin bundle can be the base of another one
+			dpbo.Base = dpbo.ToTar
+			dpbo.ToTar = filepath.Join(tempTestDir, "plugin_bundle_delta2.tar")
+			err = dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(readManifest(dpbo.ToTar).BaseImages).To(HaveLen(4))
+		})
+
+		var _ = It("when the base is a plugin migration manifest, it should only include the images which are not part of it", func() {
+			manifest := readManifest(baseTar)
+			var fooImages []*ImageCopyInfo
+			for _, ic := range manifest.ImagesToCopy {
+				if ic.Plugin == "foo@global:v0.0.2" {
+					fooImages = append(fooImages, ic)
+				}
+			}
+			Expect(fooImages).To(HaveLen(2))
+			manifest.ImagesToCopy = fooImages
+			bytes, err := yaml.Marshal(manifest)
+			Expect(err).NotTo(HaveOccurred())
+			dpbo.Base = filepath.Join(tempTestDir, PluginMigrationManifestFile)
+			Expect(os.WriteFile(dpbo.Base, bytes, 0o600)).To(Succeed())
+
+			err = dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+
+			manifest = readManifest(dpbo.ToTar)
+			Expect(manifest.ImagesToCopy).To(HaveLen(3))
+			Expect(manifest.BaseImages).To(HaveLen(2))
+			for _, ic := range manifest.BaseImages {
+				Expect(ic.Plugin).To(Equal("foo@global:v0.0.2"))
+			}
+		})
+
+		var _ = It("when the image of the base has another digest, it should include the image", func() {
+			dpbo.Base = baseTar
+			fakeImageOperations.GetImageDigestCalls(func(image string) (string, string, error) {
+				if strings.Contains(image, "/bar:") {
+					return "sha256", "newdigest", nil
+				}
+				return "sha256", "fakedigest", nil
+			})
+			defer fakeImageOperations.GetImageDigestCalls(nil)
+
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+
+			manifest := readManifest(dpbo.ToTar)
+			Expect(manifest.ImagesToCopy).To(HaveLen(2))
+			Expect(manifest.ImagesToCopy[1].Plugin).To(Equal("bar@kubernetes:v0.0.1"))
+			Expect(manifest.ImagesToCopy[1].Digest).To(Equal("sha256:newdigest"))
+			Expect(manifest.BaseImages).To(HaveLen(3))
+		})
+
+		var _ = It("when the images are present in the base repository, it should not include them", func() {
+			dpbo.BaseRepo = "fake.privaterepo.abc/plugin"
+			fakeImageOperations.GetImageDigestCalls(func(image string) (string, string, error) {
+				if strings.HasPrefix(image, "fake.privaterepo.abc/plugin/") && strings.Contains(image, "/telemetry:") {
+					return "", "", errors.New("image not found")
+				}
+				return "sha256", "fakedigest", nil
+			})
+			defer fakeImageOperations.GetImageDigestCalls(nil)
+
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+
+			manifest := readManifest(dpbo.ToTar)
+			Expect(manifest.ImagesToCopy).To(HaveLen(2))
+			Expect(manifest.ImagesToCopy[1].Plugin).To(Equal("telemetry@global:v0.0.1"))
+			Expect(manifest.BaseImages).To(HaveLen(3))
+		})
+
+		var _ = It("when the base plugin bundle does not exist, it should return an error", func() {
+			dpbo.Base = filepath.Join(tempTestDir, "does-not-exist.tar")
+
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("unable to read the base plugin bundle"))
+		})
+
+		var _ = It("when the images of the base have been uploaded, it should upload the incremental plugin bundle", func() {
+			dpbo.Base = baseTar
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadInventoryMetadataImageWithNoExistingPlugins)
+			fakeImageOperations.CopyImageFromTarReturns(nil)
+
+			err = upbo.UploadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		var _ = It("when the images of the base have not been uploaded, it should return an error before uploading images", func() {
+			dpbo.Base = baseTar
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			fakeImageOperations.GetImageDigestReturns("", "", errors.New("image not found"))
+			copyCount := fakeImageOperations.CopyImageFromTarCallCount()
+
+			err = upbo.UploadPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("of the base of the incremental plugin bundle is not present in the repository, please upload the base plugin bundle first"))
+			Expect(fakeImageOperations.CopyImageFromTarCallCount()).To(Equal(copyCount))
+		})
+
+		var _ = It("when some plugins of the base are selected, it should only check their images have been uploaded", func() {
+			dpbo.Base = baseTar
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			fakeImageOperations.GetImageDigestCalls(func(image string) (string, string, error) {
+				if strings.Contains(image, "/foo:") {
+					return "", "", errors.New("image not found")
+				}
+				return "sha256", "fakedigest", nil
+			})
+			defer fakeImageOperations.GetImageDigestCalls(nil)
+			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadInventoryMetadataImageWithNoExistingPlugins)
+			fakeImageOperations.CopyImageFromTarReturns(nil)
+			upbo.Plugins = []string{"bar"}
+
+			err = upbo.UploadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		var _ = It("when verifying the incremental plugin bundle, the plugin inventory can refer to the images of the base", func() {
+			dpbo.Base = baseTar
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+
+			savedVerifyImageTar, savedGetFilesMapFromImageTar := verifyImageTar, getFilesMapFromImageTar
+			defer func() {
+				verifyImageTar, getFilesMapFromImageTar = savedVerifyImageTar, savedGetFilesMapFromImageTar
+			}()
+			verifyImageTar = func(_, _ string) error { return nil }
+			getFilesMapFromImageTar = func(_, _ string) (map[string][]byte, error) {
+				dir := filepath.Join(tempTestDir, "inventory")
+				Expect(downloadInventoryImageAndSaveFilesToDirStub("", dir)).To(Succeed())
+				db, err := os.ReadFile(filepath.Join(dir, plugininventory.SQliteDBFileName))
+				Expect(err).NotTo(HaveOccurred())
+				return map[string][]byte{plugininventory.SQliteDBFileName: db}, nil
+			}
+
+			vpbo := &VerifyPluginBundleOptions{Tar: dpbo.ToTar}
+			Expect(vpbo.VerifyPluginBundle()).To(Succeed())
+
+			ipbo := &InspectPluginBundleOptions{Tar: dpbo.ToTar}
+			content, err := ipbo.InspectPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(content.ImagesCount).To(Equal(1))
+			Expect(content.BaseImagesCount).To(Equal(4))
+			Expect(content.Plugins).To(HaveLen(3))
+		})
+	})
+
+	var _ = Context("Tests for plugin bundle split in parts", func() {
+		const imageSize = 4096
+
+		BeforeEach(func() {
+			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadInventoryImageAndSaveFilesToDirStub)
+			// The image tar files of the plugins have a fixed size, filled in the parts in their order
+			fakeImageOperations.CopyImageToTarCalls(func(_, tarfile string) error {
+				return os.WriteFile(tarfile, []byte(strings.Repeat("a", imageSize)), 0o600)
+			})
+			// Two image tar files fit in a part
+			dpbo.MaxPartSize = archivedSize(imageSize, imageSize)
+		})
+
+		var _ = It("when the plugin bundle is larger than the maximum part size, it should split the images of the plugins in parts which can be uploaded", func() {
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+
+			manifest, err := readPluginMigrationManifest(dpbo.ToTar)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(manifest.Parts).To(HaveLen(3))
+			Expect(manifest.Parts[0].FileName).To(Equal("plugin_bundle.tar"))
+			Expect(manifest.Parts[0].Digest).To(BeEmpty())
+			parts := []string{}
+			for i, part := range manifest.Parts[1:] {
+				Expect(part.FileName).To(Equal(fmt.Sprintf("plugin_bundle.part%d.tar", i+1)))
+				partFile := filepath.Join(tempTestDir, part.FileName)
+				digest, err := fileDigest(partFile)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(part.Digest).To(Equal(digest))
+				info, err := os.Stat(partFile)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.Size()).To(BeNumerically("<=", dpbo.MaxPartSize))
+				parts = append(parts, partFile)
+			}
+			// The plugin inventory image stays in the first part
+			Expect(manifest.ImagesToCopy[0].Part).To(Equal(0))
+			partImages := map[int]int{}
+			for _, ic := range manifest.ImagesToCopy[1:] {
+				partImages[ic.Part]++
+			}
+			Expect(partImages).To(Equal(map[int]int{1: 2, 2: 2}))
+
+			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadInventoryMetadataImageWithNoExistingPlugins)
+			fakeImageOperations.CopyImageFromTarReturns(nil)
+			// The parts are found next to the first part
+			upbo.Tar = dpbo.ToTar
+			err = upbo.UploadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+
+			// The parts can also be specified, wherever they are
+			upbo.Parts = parts
+			err = upbo.UploadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		var _ = It("when the parts do not match the plugin migration manifest, it should return an error", func() {
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			part1 := pluginBundlePartFileName(dpbo.ToTar, 1)
+			part2 := pluginBundlePartFileName(dpbo.ToTar, 2)
+
+			upbo.Tar = dpbo.ToTar
+			upbo.Parts = []string{part2, part1}
+			err = upbo.UploadPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(fmt.Sprintf("the file %q is not part 1 of the plugin bundle", part2)))
+
+			upbo.Parts = []string{part1}
+			err = upbo.UploadPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("the plugin bundle is split in 3 parts, 2 parts are specified"))
+
+			upbo.Parts = nil
+			Expect(os.Remove(part2)).To(Succeed())
+			err = upbo.UploadPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("unable to read part 2 of the plugin bundle"))
+		})
+
+		var _ = It("when the plugin bundle is smaller than the maximum part size, it should not split it", func() {
+			dpbo.MaxPartSize = 1 << 30
+
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			manifest, err := readPluginMigrationManifest(dpbo.ToTar)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(manifest.Parts).To(BeEmpty())
+			_, err = os.Stat(pluginBundlePartFileName(dpbo.ToTar, 1))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		var _ = It("when an image is larger than the maximum part size, it should return an error", func() {
+			dpbo.MaxPartSize = imageSize
+
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("is larger than the maximum part size"))
+		})
+	})
+
+	var _ = Context("Tests for verifying plugin bundle", func() {
+		var (
+			vpbo                         *VerifyPluginBundleOptions
+			verifiedImageTars            map[string]string
+			verifiedImageTarsMutex       sync.Mutex
+			corruptedImageTar            string
+			savedVerifyImageTar          func(string, string) error
+			savedGetFilesMapFromImageTar func(string, string) (map[string][]byte, error)
+		)
+
+		BeforeEach(func() {
+			vpbo = &VerifyPluginBundleOptions{Tar: dpbo.ToTar}
+			verifiedImageTars = map[string]string{}
+			corruptedImageTar = ""
+			savedVerifyImageTar, savedGetFilesMapFromImageTar = verifyImageTar, getFilesMapFromImageTar
+			verifyImageTar = func(imageTar, digest string) error {
+				// The image tars are verified concurrently
+				verifiedImageTarsMutex.Lock()
+				defer verifiedImageTarsMutex.Unlock()
+				verifiedImageTars[filepath.Base(imageTar)] = digest
+				if filepath.Base(imageTar) == corruptedImageTar {
+					return errors.New("fake corrupted image")
+				}
+				return nil
+			}
+			// The plugin inventory image holds the plugin inventory database of the download
+			getFilesMapFromImageTar = func(_, _ string) (map[string][]byte, error) {
+				dir := filepath.Join(tempTestDir, "inventory")
+				Expect(downloadInventoryImageAndSaveFilesToDirStub("", dir)).To(Succeed())
+				db, err := os.ReadFile(filepath.Join(dir, plugininventory.SQliteDBFileName))
+				Expect(err).NotTo(HaveOccurred())
+				return map[string][]byte{plugininventory.SQliteDBFileName: db}, nil
+			}
+		})
+		AfterEach(func() {
+			verifyImageTar, getFilesMapFromImageTar = savedVerifyImageTar, savedGetFilesMapFromImageTar
+		})
+		JustBeforeEach(func() {
+			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadInventoryImageAndSaveFilesToDirStub)
+			fakeImageOperations.CopyImageToTarCalls(copyImageToTarStub)
+
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		var _ = It("when the plugin bundle is valid, it should verify all the image tars against their digests", func() {
+			err := vpbo.VerifyPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verifiedImageTars).To(Equal(map[string]string{
+				"plugin-inventory-image.tar.gz":               "sha256:fakedigest",
+				"bar-kubernetes-darwin_amd64-v0.0.1.tar.gz":   "sha256:fakedigest",
+				"foo-global-darwin_amd64-v0.0.2.tar.gz":       "sha256:fakedigest",
+				"foo-global-linux_amd64-v0.0.2.tar.gz":        "sha256:fakedigest",
+				"telemetry-global-darwin_amd64-v0.0.1.tar.gz": "sha256:fakedigest",
+			}))
+		})
+
+		var _ = It("when an image tar is corrupted, it should return an error", func() {
+			corruptedImageTar = "foo-global-linux_amd64-v0.0.2.tar.gz"
+			err := vpbo.VerifyPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("1 problems found in the plugin bundle"))
+			Expect(err.Error()).To(ContainSubstring(`image "/path/linux/amd64/global/foo": fake corrupted image`))
+		})
+
+		var _ = It("when the plugin inventory image has no plugin inventory database, it should return an error", func() {
+			getFilesMapFromImageTar = func(_, _ string) (map[string][]byte, error) {
+				return map[string][]byte{}, nil
+			}
+			err := vpbo.VerifyPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`the plugin inventory image has no "plugin_inventory.db" file`))
+		})
+
+		var _ = It("when the plugin inventory database refers to images which are not part of the plugin bundle, it should return an error", func() {
+			vpbo.Tar = rewritePluginBundle(dpbo.ToTar, tempTestDir, func(manifest *PluginMigrationManifest, _ string) {
+				manifest.ImagesToCopy = append(manifest.ImagesToCopy[:1], &ImageCopyInfo{SourceTarFilePath: "foo-global-darwin_amd64-v0.0.2.tar.gz", RelativeImagePath: "/path/darwin/amd64/global/foo"})
+			})
+
+			err := vpbo.VerifyPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("3 problems found in the plugin bundle"))
+			Expect(err.Error()).To(ContainSubstring(`plugin 'bar_kubernetes' version "v0.0.1" for darwin/amd64: the image "/path/darwin/amd64/kubernetes/bar" is not part of the plugin bundle`))
+			Expect(err.Error()).To(ContainSubstring(`plugin 'foo_global' version "v0.0.2" for linux/amd64: the image "/path/linux/amd64/global/foo" is not part of the plugin bundle`))
+			Expect(err.Error()).To(ContainSubstring(`plugin 'telemetry_global' version "v0.0.1" for darwin/amd64: the image "/path/darwin/amd64/global/telemetry" is not part of the plugin bundle`))
+		})
+
+		var _ = It("when an image tar is missing from the plugin bundle, it should return an error", func() {
+			vpbo.Tar = rewritePluginBundle(dpbo.ToTar, tempTestDir, func(_ *PluginMigrationManifest, pluginBundleDir string) {
+				Expect(os.Remove(filepath.Join(pluginBundleDir, "foo-global-linux_amd64-v0.0.2.tar.gz"))).To(Succeed())
+			})
+
+			err := vpbo.VerifyPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("1 problems found in the plugin bundle"))
+			Expect(err.Error()).To(ContainSubstring(`the file "foo-global-linux_amd64-v0.0.2.tar.gz" of the plugin migration manifest is missing from the plugin bundle`))
+		})
+
+		var _ = It("when incorrect tarfile is provided, it should return an error", func() {
+			vpbo.Tar = createIncorrectPluginBundleTarFile(tempTestDir)
+
+			err := vpbo.VerifyPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("error while reading plugin migration manifest"))
+		})
+	})
+
+	var _ = Context("Tests for inspecting plugin bundle", func() {
+		var (
+			ipbo                         *InspectPluginBundleOptions
+			savedGetFilesMapFromImageTar func(string, string) (map[string][]byte, error)
+		)
+
+		BeforeEach(func() {
+			ipbo = &InspectPluginBundleOptions{Tar: dpbo.ToTar}
+			savedGetFilesMapFromImageTar = getFilesMapFromImageTar
+			// The plugin inventory image holds the plugin inventory database of the download
+			getFilesMapFromImageTar = func(_, _ string) (map[string][]byte, error) {
+				dir := filepath.Join(tempTestDir, "inventory")
+				Expect(downloadInventoryImageAndSaveFilesToDirStub("", dir)).To(Succeed())
+				db, err := os.ReadFile(filepath.Join(dir, plugininventory.SQliteDBFileName))
+				Expect(err).NotTo(HaveOccurred())
+				return map[string][]byte{plugininventory.SQliteDBFileName: db}, nil
+			}
+			fakeImageOperations.DownloadImageAndSaveFilesToDirCalls(downloadInventoryImageAndSaveFilesToDirStub)
+			fakeImageOperations.CopyImageToTarCalls(copyImageToTarStub)
+		})
+		AfterEach(func() {
+			getFilesMapFromImageTar = savedGetFilesMapFromImageTar
+		})
+
+		var _ = It("when the plugin bundle holds the entire plugin repository, it should return all the plugins and plugin groups", func() {
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			ipbo.Tar = rewritePluginBundle(dpbo.ToTar, tempTestDir, func(_ *PluginMigrationManifest, pluginBundleDir string) {
+				Expect(os.WriteFile(filepath.Join(pluginBundleDir, "foo-global-linux_amd64-v0.0.2.tar.gz"), make([]byte, 1024), 0o600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(pluginBundleDir, "bar-kubernetes-darwin_amd64-v0.0.1.tar.gz"), make([]byte, 512), 0o600)).To(Succeed())
+			})
+
+			content, err := ipbo.InspectPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(content.InventoryImage).To(Equal("/plugin-inventory:latest"))
+			Expect(content.InventoryMetadataImage).To(Equal("/plugin-inventory-metadata:latest"))
+			Expect(content.Repositories).To(Equal([]string{
+				"/path/darwin/amd64/global/foo",
+				"/path/darwin/amd64/global/telemetry",
+				"/path/darwin/amd64/kubernetes/bar",
+				"/path/linux/amd64/global/foo",
+				"/plugin-inventory",
+			}))
+			Expect(content.Plugins).To(Equal([]*PluginBundlePlugin{
+				{Name: "bar", Target: "kubernetes", Description: "Bar plugin", Versions: []string{"v0.0.1"}},
+				{Name: "foo", Target: "global", Description: "Foo plugin", Versions: []string{"v0.0.2"}},
+				{Name: "telemetry", Target: "global", Description: "Telemetry plugin", Versions: []string{"v0.0.1"}},
+			}))
+			Expect(content.PluginGroups).To(Equal([]*PluginBundlePluginGroup{
+				{Group: "fakevendor-fakepublisher/default", Description: "Desc for plugin", Versions: []string{"v1.0.0"}},
+				{Group: "fakevendor-fakepublisher/default2", Description: "Desc for plugin", Versions: []string{"v1.0.0"}},
+				{Group: "vmware-tanzucli/essentials", Description: "Desc for plugin", Versions: []string{"v0.0.1"}},
+			}))
+			Expect(content.ImagesCount).To(Equal(5))
+			Expect(content.Size).To(Equal(int64(1536)))
+		})
+
+		var _ = It("when the plugin bundle holds a plugin group, it should only return the plugins and plugin groups of the bundle", func() {
+			dpbo.Groups = []string{"fakevendor-fakepublisher/default:v1.0.0"}
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+
+			content, err := ipbo.InspectPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(content.Plugins).To(Equal([]*PluginBundlePlugin{
+				{Name: "bar", Target: "kubernetes", Description: "Bar plugin", Versions: []string{"v0.0.1"}},
+				{Name: "telemetry", Target: "global", Description: "Telemetry plugin", Versions: []string{"v0.0.1"}},
+			}))
+			Expect(content.PluginGroups).To(Equal([]*PluginBundlePluginGroup{
+				{Group: "fakevendor-fakepublisher/default", Description: "Desc for plugin", Versions: []string{"v1.0.0"}},
+				{Group: "vmware-tanzucli/essentials", Description: "Desc for plugin", Versions: []string{"v0.0.1"}},
+			}))
+			Expect(content.ImagesCount).To(Equal(3))
+		})
+
+		var _ = It("when an image tar is missing from the plugin bundle, it should return an error", func() {
+			err := dpbo.DownloadPluginBundle()
+			Expect(err).NotTo(HaveOccurred())
+			ipbo.Tar = rewritePluginBundle(dpbo.ToTar, tempTestDir, func(_ *PluginMigrationManifest, pluginBundleDir string) {
+				Expect(os.Remove(filepath.Join(pluginBundleDir, "foo-global-linux_amd64-v0.0.2.tar.gz"))).To(Succeed())
+			})
+
+			_, err = ipbo.InspectPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid plugin bundle"))
+			Expect(err.Error()).To(ContainSubstring(`the file "foo-global-linux_amd64-v0.0.2.tar.gz" of the plugin migration manifest is missing from the plugin bundle`))
+		})
+
+		var _ = It("when incorrect tarfile is provided, it should return an error", func() {
+			ipbo.Tar = createIncorrectPluginBundleTarFile(tempTestDir)
+
+			_, err := ipbo.InspectPluginBundle()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("error while reading plugin migration manifest"))
+		})
 	})
 })
 
@@ -701,6 +1816,58 @@ func createIncorrectPluginBundleTarFile(dir string) string {
 	return tarFile
 }
 
+// queryMetadataDB returns the values of the single column selected by the query of the inventory metadata database
+func queryMetadataDB(dbFile, query string) []string {
+	db, err := sql.Open("sqlite", dbFile)
+	Expect(err).NotTo(HaveOccurred())
+	defer db.Close()
+	rows, err := db.Query(query)
+	Expect(err).NotTo(HaveOccurred())
+	defer rows.Close()
+	var values []string
+	for rows.Next() {
+		var value string
+		Expect(rows.Scan(&value)).To(Succeed())
+		values = append(values, value)
+	}
+	Expect(rows.Err()).NotTo(HaveOccurred())
+	return values
+}
+
+// rewritePluginBundle extracts the plugin bundle, lets update its manifest and files and returns the updated plugin bundle
+func rewritePluginBundle(tarFile, dir string, update func(manifest *PluginMigrationManifest, pluginBundleDir string)) string {
+	extractDir := filepath.Join(dir, "rewrite")
+	Expect(tarinator.UnTarinate(extractDir, tarFile)).To(Succeed())
+	pluginBundleDir := filepath.Join(extractDir, PluginBundleDirName)
+	manifestFile := filepath.Join(pluginBundleDir, PluginMigrationManifestFile)
+	bytes, err := os.ReadFile(manifestFile)
+	Expect(err).NotTo(HaveOccurred())
+	manifest := &PluginMigrationManifest{}
+	Expect(yaml.Unmarshal(bytes, manifest)).To(Succeed())
+
+	update(manifest, pluginBundleDir)
+
+	bytes, err = yaml.Marshal(manifest)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(os.WriteFile(manifestFile, bytes, 0644)).To(Succeed())
+	rewrittenTarFile := filepath.Join(dir, "rewritten-plugin-bundle.tar")
+	Expect(tarinator.Tarinate([]string{pluginBundleDir}, rewrittenTarFile)).To(Succeed())
+	return rewrittenTarFile
+}
+
+// generateCosignKeyPair generates a cosign key pair, whose password is set to the COSIGN_PASSWORD
+// environment variable, and returns the paths of the private and public keys
+func generateCosignKeyPair(dir, name string) (string, string) {
+	os.Setenv("COSIGN_PASSWORD", "password")
+	keys, err := cosign.GenerateKeyPair(func(bool) ([]byte, error) { return []byte("password"), nil })
+	Expect(err).NotTo(HaveOccurred())
+	privateKey := filepath.Join(dir, name+".key")
+	publicKey := filepath.Join(dir, name+".pub")
+	Expect(os.WriteFile(privateKey, keys.PrivateBytes, 0o600)).To(Succeed())
+	Expect(os.WriteFile(publicKey, keys.PublicBytes, 0o600)).To(Succeed())
+	return privateKey, publicKey
+}
+
 func readOutput(r io.Reader, c chan<- []byte) {
 	data, err := io.ReadAll(r)
 	Expect(err).NotTo(HaveOccurred())
@@ -4,31 +4,119 @@
 package airgapped
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
-
-	"github.com/verybluebot/tarinator-go"
-
+	"golang.org/x/sync/errgroup"
 	"gopkg.in/yaml.v3"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cosignhelper"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/essentials"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/registry"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
 )
 
 // UploadPluginBundleOptions defines options for uploading plugin bundle
 type UploadPluginBundleOptions struct {
-	Tar             string
+	Tar string
+	// Parts are the tar files of the other parts of a plugin bundle split in several parts, by part
+	// index, Tar being the first part. The tar files named in the plugin migration manifest are
+	// read from the directory of Tar when not set.
+	Parts []string
+	// DestinationRepo is the repository the plugin bundle is uploaded to, or an OCI image layout
+	// directory, as a file:// URL or a local path, to stage the upload on disk. The images of an
+	// OCI image layout can later be copied to a registry with other tools.
 	DestinationRepo string
+	// DestinationRepos are other destinations the plugin bundle is uploaded to in the same run,
+	// e.g. the registry replicas of several sites, in the same formats as DestinationRepo
+	DestinationRepos []string
+	// MirrorsFile is a YAML file listing other destinations the plugin bundle is uploaded to
+	// in the same run, see PluginBundleMirrors
+	MirrorsFile string
+	// MergePolicy tells how the plugin inventory metadata of the bundle is merged with
+	// the one already published to the repository, the union of both by default
+	MergePolicy plugininventory.MetadataMergePolicy
+	// Concurrency is the number of images uploaded concurrently, one at a time when not set
+	Concurrency int
+	// Retries is the number of times the operations of the ImageProcessor, e.g. the upload of an
+	// image, are retried when they fail with a transient error, e.g. a network error or a 429 or
+	// 5xx response of the registry
+	Retries int
+	// RetryBackoff is the delay before retrying an operation the first time, doubled for each
+	// subsequent retry up to RetryMaxBackoff. Some jitter is applied to the delays so that the
+	// concurrent uploads do not retry at the same time. The delays of carvelhelpers.DefaultRetryPolicy
+	// are used when not set. The retry settings configure the retry policy of the ImageProcessor
+	// created with the Connection settings.
+	RetryBackoff    time.Duration
+	RetryMaxBackoff time.Duration
+	// Groups and Plugins select the plugin groups and plugins of the bundle to upload,
+	// all the content of the bundle is uploaded when none is specified
+	Groups  []string
+	Plugins []string
+	// VerifyKey is the public key used to verify the cosign signatures of the plugin bundle and of
+	// its plugin inventory metadata before uploading it, no signature is verified when not set
+	VerifyKey string
+	// Signature is the file holding the signature of the plugin bundle, the tar file
+	// path with the PluginBundleSignatureFileSuffix suffix when not set
+	Signature string
+	// Connection are the TLS and proxy settings used to access the registries, on top of the
+	// certificate configuration of the registries and of the proxy environment variables
+	Connection *registry.ConnectionOptions
 
+	// ImageProcessor is created with the Connection settings when not set
 	ImageProcessor carvelhelpers.ImageOperationsImpl
+	// progress relays the progress of the transfers reported by the ImageProcessor
+	// when it is created with the Connection settings
+	progress *progressRelay
 }
 
-// UploadPluginBundle uploads the given plugin bundle to the specified remote repository
+// newImageOperations returns the image operations used to access the registries when
+// not set in the options. It can be overridden for unit testing.
+var newImageOperations = carvelhelpers.NewImageOperationsImpl
+
+// newOCILayoutImageOperations returns the image operations used to upload a plugin bundle to
+// an OCI image layout directory. It can be overridden for unit testing.
+var newOCILayoutImageOperations = carvelhelpers.NewOCILayoutImageOperations
+
+// UploadPluginBundle uploads the given plugin bundle to the specified remote repository, and to
+// the other destinations of o.DestinationRepos and o.MirrorsFile. The plugin bundle is extracted
+// and verified once, and then uploaded to each destination in turn. A failed upload to a
+// destination does not prevent the upload to the other destinations, the outcome of the upload
+// to each destination being reported once the plugin bundle has been uploaded to all of them.
 func (o *UploadPluginBundleOptions) UploadPluginBundle() error {
+	if err := plugininventory.ValidateMetadataMergePolicy(o.MergePolicy); err != nil {
+		return err
+	}
+	if err := o.Connection.Validate(); err != nil {
+		return err
+	}
+	destinations, err := o.getDestinations()
+	if err != nil {
+		return err
+	}
+	if o.ImageProcessor == nil && !allOCILayoutDirs(destinations) {
+		o.progress = &progressRelay{}
+		o.ImageProcessor = newImageOperations(carvelhelpers.WithConnectionOptions(o.Connection), carvelhelpers.WithProgressCallback(o.progress.report), carvelhelpers.WithRetryPolicy(o.retryPolicy()))
+	}
+
+	// Verify the signature of the plugin bundle before processing its content
+	if o.VerifyKey != "" {
+		err := o.verifyPluginBundleSignature()
+		if err != nil {
+			return err
+		}
+	}
+
 	// create a temporary directory
 	tempDir, err := os.MkdirTemp("", "")
 	if err != nil {
@@ -38,50 +126,163 @@ func (o *UploadPluginBundleOptions) UploadPluginBundle() error {
 
 	// Untar the specified plugin bundle to the temp directory
 	log.Infof("extracting %q for processing...", o.Tar)
-	err = tarinator.UnTarinate(tempDir, o.Tar)
+	manifest, err := extractPluginBundle(o.Tar, o.Parts, tempDir)
 	if err != nil {
-		return errors.Wrap(err, "unable to extract provided file")
+		return err
 	}
-
-	// Read the plugin migration manifest file
 	pluginBundleDir := filepath.Join(tempDir, PluginBundleDirName)
-	bytes, err := os.ReadFile(filepath.Join(pluginBundleDir, PluginMigrationManifestFile))
-	if err != nil {
-		return errors.Wrap(err, "error while reading plugin migration manifest")
+
+	bundledPluginInventoryMetadataDBFilePath := filepath.Join(pluginBundleDir, manifest.InventoryMetadataImage.SourceFilePath)
+	if o.VerifyKey != "" {
+		err = o.verifyPluginInventoryMetadataSignature(manifest.InventoryMetadataImage, bundledPluginInventoryMetadataDBFilePath)
+		if err != nil {
+			return err
+		}
+	}
+	if len(o.Groups) != 0 || len(o.Plugins) != 0 {
+		err = o.selectPluginBundleContent(manifest, bundledPluginInventoryMetadataDBFilePath)
+		if err != nil {
+			return errors.Wrap(err, "error while selecting the plugins and plugin groups to upload")
+		}
+	}
+
+	if len(destinations) == 1 {
+		return o.forDestination(destinations[0]).uploadToDestination(manifest, pluginBundleDir, tempDir)
+	}
+	errs := make([]error, len(destinations))
+	for i, destination := range destinations {
+		log.Infof("===========================")
+		log.Infof("uploading the plugin bundle to %q (%d/%d)", destination, i+1, len(destinations))
+		errs[i] = o.forDestination(destination).uploadToDestination(manifest, pluginBundleDir, tempDir)
+		if errs[i] != nil {
+			log.Warningf("uploading the plugin bundle to %q failed: %v", destination, errs[i])
+		}
+	}
+	return reportUploadsToDestinations(destinations, errs)
+}
+
+// getDestinations returns the destinations the plugin bundle is uploaded to, o.DestinationRepo
+// followed by o.DestinationRepos and the mirrors of o.MirrorsFile, without duplicates
+func (o *UploadPluginBundleOptions) getDestinations() ([]string, error) {
+	candidates := append([]string{o.DestinationRepo}, o.DestinationRepos...)
+	if o.MirrorsFile != "" {
+		mirrors, err := readPluginBundleMirrorsFile(o.MirrorsFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, mirror := range mirrors.Mirrors {
+			candidates = append(candidates, mirror.Repository)
+		}
+	}
+	var destinations []string
+	seen := map[string]bool{}
+	for _, destination := range candidates {
+		destination = strings.TrimSpace(destination)
+		if destination == "" || seen[destination] {
+			continue
+		}
+		seen[destination] = true
+		destinations = append(destinations, destination)
+	}
+	if len(destinations) == 0 {
+		return nil, errors.New("no destination repository specified")
+	}
+	return destinations, nil
+}
+
+// allOCILayoutDirs returns true if all the destinations are OCI image layout directories
+func allOCILayoutDirs(destinations []string) bool {
+	for _, destination := range destinations {
+		if _, ok := carvelhelpers.GetOCILayoutDir(destination); !ok {
+			return false
+		}
 	}
-	manifest := &PluginMigrationManifest{}
-	err = yaml.Unmarshal(bytes, &manifest)
+	return true
+}
+
+// readPluginBundleMirrorsFile reads the destinations of the plugin bundle from the mirrors file
+func readPluginBundleMirrorsFile(mirrorsFile string) (*PluginBundleMirrors, error) {
+	b, err := os.ReadFile(mirrorsFile)
 	if err != nil {
-		return errors.Wrap(err, "error while parsing plugin migration manifest")
+		return nil, errors.Wrap(err, "error while reading the mirrors file")
+	}
+	mirrors := &PluginBundleMirrors{}
+	if err := yaml.Unmarshal(b, mirrors); err != nil {
+		return nil, errors.Wrapf(err, "error while parsing the mirrors file %q", mirrorsFile)
+	}
+	for i, mirror := range mirrors.Mirrors {
+		if strings.TrimSpace(mirror.Repository) == "" {
+			return nil, errors.Errorf("the mirror %d of the mirrors file %q has no repository", i+1, mirrorsFile)
+		}
 	}
+	return mirrors, nil
+}
 
-	// Iterate through all the images and publish them to the remote repository
-	for _, ic := range manifest.ImagesToCopy {
-		imageTar := filepath.Join(pluginBundleDir, ic.SourceTarFilePath)
-		repoImagePath, err := utils.JoinURL(o.DestinationRepo, ic.RelativeImagePath)
+// forDestination returns the options to upload the plugin bundle to the destination, with the image
+// operations writing to the OCI image layout directory when the destination is a local directory
+func (o *UploadPluginBundleOptions) forDestination(destination string) *UploadPluginBundleOptions {
+	do := *o
+	do.DestinationRepo = destination
+	if dir, ok := carvelhelpers.GetOCILayoutDir(destination); ok {
+		log.Infof("uploading the plugin bundle to the OCI image layout %q", dir)
+		do.DestinationRepo = carvelhelpers.OCILayoutScheme + filepath.ToSlash(dir)
+		do.ImageProcessor = newOCILayoutImageOperations(dir)
+	}
+	return &do
+}
+
+// uploadToDestination uploads the extracted plugin bundle to o.DestinationRepo. The bundled plugin
+// inventory metadata database is merged with the one of the destination in a copy of its own, so
+// that the plugin bundle can be uploaded to several destinations.
+func (o *UploadPluginBundleOptions) uploadToDestination(manifest *PluginMigrationManifest, pluginBundleDir, tempDir string) error {
+	// The images of the base of an incremental bundle must have been uploaded with the base
+	if len(manifest.BaseImages) > 0 {
+		err := o.checkBaseImagesUploaded(manifest.BaseImages)
 		if err != nil {
-			return errors.Wrap(err, "error while constructing the repo image path")
+			return err
 		}
-		log.Infof("---------------------------")
-		log.Infof("uploading image %q", repoImagePath)
-		err = o.ImageProcessor.CopyImageFromTar(imageTar, repoImagePath)
+	}
+
+	destinationTempDir, err := os.MkdirTemp(tempDir, "destination")
+	if err != nil {
+		return errors.Wrap(err, "unable to create temp directory")
+	}
+	bundledPluginInventoryMetadataDBFilePath := filepath.Join(destinationTempDir, filepath.Base(manifest.InventoryMetadataImage.SourceFilePath))
+	err = copyFile(filepath.Join(pluginBundleDir, manifest.InventoryMetadataImage.SourceFilePath), bundledPluginInventoryMetadataDBFilePath)
+	if err != nil {
+		return errors.Wrap(err, "unable to copy the plugin inventory metadata database")
+	}
+
+	pluginInventoryMetadataImageWithTag, err := o.destinationImage(manifest.InventoryMetadataImage.RelativeImagePathWithTag)
+	if err != nil {
+		return errors.Wrap(err, "error while constructing the plugin inventory metadata image with tag")
+	}
+
+	// The conflicts must be found before uploading the images, which would
+	// otherwise overwrite the images of the plugins already published
+	mergeBeforeUpload := o.MergePolicy == plugininventory.MetadataMergePolicyFailOnConflict
+	if mergeBeforeUpload {
+		err = o.mergePluginInventoryMetadata(pluginInventoryMetadataImageWithTag, bundledPluginInventoryMetadataDBFilePath, destinationTempDir)
 		if err != nil {
-			return errors.Wrap(err, "error while uploading image")
+			return errors.Wrap(err, "error while merging the plugin inventory metadata database before uploading images")
 		}
 	}
+
+	// Publish all the images to the remote repository
+	err = o.uploadImages(manifest.ImagesToCopy, pluginBundleDir)
+	if err != nil {
+		return err
+	}
 	log.Infof("---------------------------")
 	log.Infof("---------------------------")
 
 	// Publish plugin inventory metadata image after merging inventory metadata
 	log.Infof("publishing plugin inventory metadata image...")
-	bundledPluginInventoryMetadataDBFilePath := filepath.Join(pluginBundleDir, manifest.InventoryMetadataImage.SourceFilePath)
-	pluginInventoryMetadataImageWithTag, err := utils.JoinURL(o.DestinationRepo, manifest.InventoryMetadataImage.RelativeImagePathWithTag)
-	if err != nil {
-		return errors.Wrap(err, "error while constructing the plugin inventory metadata image with tag")
-	}
-	err = o.mergePluginInventoryMetadata(pluginInventoryMetadataImageWithTag, bundledPluginInventoryMetadataDBFilePath, tempDir)
-	if err != nil {
-		return errors.Wrap(err, "error while merging the plugin inventory metadata database before uploading metadata image")
+	if !mergeBeforeUpload {
+		err = o.mergePluginInventoryMetadata(pluginInventoryMetadataImageWithTag, bundledPluginInventoryMetadataDBFilePath, destinationTempDir)
+		if err != nil {
+			return errors.Wrap(err, "error while merging the plugin inventory metadata database before uploading metadata image")
+		}
 	}
 
 	log.Infof("uploading image %q", pluginInventoryMetadataImageWithTag)
@@ -92,7 +293,7 @@ func (o *UploadPluginBundleOptions) UploadPluginBundle() error {
 
 	log.Infof("---------------------------")
 
-	joinedURL, err := utils.JoinURL(o.DestinationRepo, manifest.RelativeInventoryImagePathWithTag)
+	joinedURL, err := o.destinationImage(manifest.RelativeInventoryImagePathWithTag)
 	if err != nil {
 		return errors.Wrap(err, "error while constructing the image URL")
 	}
@@ -101,15 +302,323 @@ func (o *UploadPluginBundleOptions) UploadPluginBundle() error {
 	return nil
 }
 
+// reportUploadsToDestinations reports the outcome of the upload of the plugin bundle to each
+// destination and returns an error if the upload to some of the destinations failed
+func reportUploadsToDestinations(destinations []string, errs []error) error {
+	log.Infof("===========================")
+	log.Infof("summary of the upload of the plugin bundle to %d destinations:", len(destinations))
+	var failed []error
+	for i, destination := range destinations {
+		if errs[i] == nil {
+			log.Infof("  %s: succeeded", destination)
+			continue
+		}
+		log.Infof("  %s: failed: %v", destination, errs[i])
+		failed = append(failed, errors.Wrapf(errs[i], "upload to %q failed", destination))
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return errors.Wrapf(kerrors.NewAggregate(failed), "the plugin bundle could not be uploaded to %d of the %d destinations", len(failed), len(destinations))
+}
+
+// destinationImage returns the image path in the destination repository, or in the destination
+// OCI image layout, of the image path relative to the destination
+func (o *UploadPluginBundleOptions) destinationImage(relativeImagePath string) (string, error) {
+	if strings.HasPrefix(o.DestinationRepo, carvelhelpers.OCILayoutScheme) {
+		return strings.TrimSuffix(o.DestinationRepo, "/") + "/" + strings.TrimPrefix(relativeImagePath, "/"), nil
+	}
+	return utils.JoinURL(o.DestinationRepo, relativeImagePath)
+}
+
+// verifyPluginBundleSignature verifies the signature of the plugin bundle with the public key
+func (o *UploadPluginBundleOptions) verifyPluginBundleSignature() error {
+	signatureFile := o.Signature
+	if signatureFile == "" {
+		signatureFile = o.Tar + PluginBundleSignatureFileSuffix
+	}
+	log.Infof("verifying the signature of %q with %q", o.Tar, signatureFile)
+	signature, err := os.ReadFile(signatureFile)
+	if err != nil {
+		return errors.Wrap(err, "error while reading the signature of the plugin bundle")
+	}
+	err = cosignhelper.VerifyBlob(context.Background(), o.VerifyKey, o.Tar, string(signature))
+	if err != nil {
+		return errors.Wrap(err, "error while verifying the signature of the plugin bundle")
+	}
+	return nil
+}
+
+// verifyPluginInventoryMetadataSignature verifies the signature of the plugin inventory metadata
+// recorded in the plugin migration manifest with the public key. The plugin inventory metadata
+// must have been signed when the plugin bundle was downloaded.
+func (o *UploadPluginBundleOptions) verifyPluginInventoryMetadataSignature(metadataInfo *ImagePublishInfo, pluginInventoryMetadataDBFilePath string) error {
+	if metadataInfo.Signature == "" {
+		return errors.New("the plugin inventory metadata of the plugin bundle is not signed")
+	}
+	err := cosignhelper.VerifyBlob(context.Background(), o.VerifyKey, pluginInventoryMetadataDBFilePath, metadataInfo.Signature)
+	if err != nil {
+		return errors.Wrap(err, "error while verifying the signature of the plugin inventory metadata")
+	}
+	return nil
+}
+
+// selectPluginBundleContent only keeps the images of the plugins selected by o.Plugins and o.Groups
+// in the manifest and re-creates the bundled plugin inventory metadata database with only the
+// selected plugins and plugin groups. As when downloading a plugin bundle, the essential plugin
+// group is always selected if it is part of the bundle.
+func (o *UploadPluginBundleOptions) selectPluginBundleContent(manifest *PluginMigrationManifest, pluginInventoryMetadataDBFilePath string) error {
+	if len(manifest.ImagesToCopy) == 0 {
+		return errors.New("the plugin bundle has no plugin inventory image")
+	}
+	// The plugins of the bundle in the order of their images, the plugin inventory image being the only image without plugin,
+	// including the plugins of an incremental bundle whose images are part of its base
+	var bundledPlugins []string
+	bundled := map[string]bool{}
+	for _, ic := range append(append([]*ImageCopyInfo{}, manifest.ImagesToCopy[1:]...), manifest.BaseImages...) {
+		if ic.Plugin == "" {
+			return errors.New("the plugin bundle does not record the plugins of its images, please download it again to upload only some of its plugins or plugin groups")
+		}
+		if !bundled[ic.Plugin] {
+			bundled[ic.Plugin] = true
+			bundledPlugins = append(bundledPlugins, ic.Plugin)
+		}
+	}
+
+	selectedPlugins := map[string]bool{}
+	var selectedGroups []*plugininventory.PluginGroupIdentifier
+
+	groupIDs := append([]string{}, o.Groups...)
+	name, version := essentials.GetEssentialsPluginGroupDetails()
+	essentialPluginGroup := name
+	if version != "" {
+		essentialPluginGroup = fmt.Sprintf("%v:%v", essentialPluginGroup, version)
+	}
+	groupIDs = append(groupIDs, essentialPluginGroup)
+	for _, groupID := range groupIDs {
+		pgi := plugininventory.PluginGroupIdentifierFromID(groupID)
+		if pgi == nil {
+			return errors.Errorf("incorrect plugin group %q specified", groupID)
+		}
+		var found bool
+		for _, pgc := range manifest.PluginGroups {
+			bundledPgi := plugininventory.PluginGroupIdentifierFromID(pgc.Group)
+			if bundledPgi == nil || bundledPgi.Vendor != pgi.Vendor || bundledPgi.Publisher != pgi.Publisher || bundledPgi.Name != pgi.Name ||
+				(pgi.Version != "" && bundledPgi.Version != pgi.Version) {
+				continue
+			}
+			found = true
+			selectedGroups = append(selectedGroups, bundledPgi)
+			for _, pluginID := range pgc.Plugins {
+				pluginName, pluginTarget, pluginVersion := utils.ParsePluginID(pluginID)
+				for _, p := range matchBundledPlugins(bundledPlugins, pluginName, pluginTarget, pluginVersion) {
+					selectedPlugins[p] = true
+				}
+			}
+		}
+		if !found {
+			// Continue to upload the rest of the plugin groups if essentials is not part of the bundle
+			if groupID == essentialPluginGroup {
+				continue
+			}
+			return errors.Errorf("plugin group %q is not part of the plugin bundle", groupID)
+		}
+	}
+
+	for _, pluginID := range o.Plugins {
+		pluginName, pluginTarget, pluginVersion := utils.ParsePluginID(pluginID)
+		matches := matchBundledPlugins(bundledPlugins, pluginName, pluginTarget, "")
+		targets := map[string]bool{}
+		var matchesWithVersion []string
+		for _, p := range matches {
+			_, target, version := utils.ParsePluginID(p)
+			targets[target] = true
+			if pluginVersion == "" || version == pluginVersion {
+				matchesWithVersion = append(matchesWithVersion, p)
+			}
+		}
+		if len(targets) > 1 {
+			return errors.Errorf("more than one plugins found for pluginID '%s'. Please specify the uniquely identifiable pluginID in the form of 'name@target'", pluginID)
+		}
+		if len(matchesWithVersion) == 0 {
+			return errors.Errorf("plugin %q is not part of the plugin bundle", pluginID)
+		}
+		for _, p := range matchesWithVersion {
+			selectedPlugins[p] = true
+		}
+	}
+
+	// Only keep the plugin inventory image and the images of the selected plugins
+	imagesToCopy := manifest.ImagesToCopy[:1]
+	for _, ic := range manifest.ImagesToCopy[1:] {
+		if selectedPlugins[ic.Plugin] {
+			imagesToCopy = append(imagesToCopy, ic)
+		}
+	}
+	manifest.ImagesToCopy = imagesToCopy
+	var baseImages []*ImageCopyInfo
+	for _, ic := range manifest.BaseImages {
+		if selectedPlugins[ic.Plugin] {
+			baseImages = append(baseImages, ic)
+		}
+	}
+	manifest.BaseImages = baseImages
+	log.Infof("will be uploading %d plugin versions and %d plugin group versions of the plugin bundle", len(selectedPlugins), len(selectedGroups))
+
+	// Re-create the plugin inventory metadata database with only the selected plugins and plugin groups
+	if err := os.Remove(pluginInventoryMetadataDBFilePath); err != nil {
+		return errors.Wrap(err, "unable to remove the plugin inventory metadata database")
+	}
+	inventoryMetadataDB := plugininventory.NewSQLiteInventoryMetadata(pluginInventoryMetadataDBFilePath)
+	if err := inventoryMetadataDB.CreateInventoryMetadataDBSchema(); err != nil {
+		return err
+	}
+	var pluginIDs []*plugininventory.PluginIdentifier
+	for _, p := range bundledPlugins {
+		if selectedPlugins[p] {
+			pluginName, pluginTarget, pluginVersion := utils.ParsePluginID(p)
+			pluginIDs = append(pluginIDs, &plugininventory.PluginIdentifier{Name: pluginName, Target: configtypes.StringToTarget(pluginTarget), Version: pluginVersion})
+		}
+	}
+	if err := inventoryMetadataDB.InsertPluginIdentifiers(pluginIDs); err != nil {
+		return err
+	}
+	return inventoryMetadataDB.InsertPluginGroupIdentifiers(selectedGroups)
+}
+
+// matchBundledPlugins returns the plugins of the bundle with the specified name, and target and version
+// when specified. The version of a plugin of a plugin group can be shortened, e.g. "v1.2" matching "v1.2.3".
+func matchBundledPlugins(bundledPlugins []string, name, target, version string) []string {
+	var matches []string
+	for _, p := range bundledPlugins {
+		pName, pTarget, pVersion := utils.ParsePluginID(p)
+		if pName != name || (target != "" && pTarget != target) {
+			continue
+		}
+		if version != "" && pVersion != version && !strings.HasPrefix(pVersion, version+".") {
+			continue
+		}
+		matches = append(matches, p)
+	}
+	return matches
+}
+
+// uploadImages publishes the images of the plugin bundle to the remote repository, uploading up to
+// o.Concurrency images concurrently. No other image is uploaded once the upload of an image fails.
+// The images already present in the remote repository are skipped, so that uploading a plugin
+// bundle again after an interrupted upload only uploads the missing images.
+func (o *UploadPluginBundleOptions) uploadImages(imagesToCopy []*ImageCopyInfo, pluginBundleDir string) error {
+	concurrency := o.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	// The progress of the upload is reported based on the size of the image tar files
+	imageSizes := make([]int64, len(imagesToCopy))
+	var totalSize int64
+	for i, ic := range imagesToCopy {
+		info, err := os.Stat(filepath.Join(pluginBundleDir, ic.SourceTarFilePath))
+		if err != nil {
+			return errors.Wrap(err, "error while reading the image tar file")
+		}
+		imageSizes[i] = info.Size()
+		totalSize += info.Size()
+	}
+	progress := newTransferProgress("uploading", len(imagesToCopy), totalSize)
+	o.progress.relayTo(progress)
+	defer o.progress.relayTo(nil)
+
+	eg, ctx := errgroup.WithContext(context.Background())
+	eg.SetLimit(concurrency)
+	for i, ic := range imagesToCopy {
+		imageTar := filepath.Join(pluginBundleDir, ic.SourceTarFilePath)
+		repoImagePath, err := o.destinationImage(ic.RelativeImagePath)
+		if err != nil {
+			return errors.Wrap(err, "error while constructing the repo image path")
+		}
+		ic, size := ic, imageSizes[i]
+		eg.Go(func() error {
+			if ctx.Err() != nil {
+				// The upload of another image failed
+				return nil
+			}
+			log.Infof("---------------------------")
+			if o.isImageUploaded(ic, repoImagePath) {
+				log.Infof("image %q is already present, skipping", repoImagePath)
+				progress.imageTransferred(repoImagePath, size, true)
+				return nil
+			}
+			log.Infof("uploading image %q", repoImagePath)
+			if err := o.ImageProcessor.CopyImageFromTar(imageTar, repoImagePath); err != nil {
+				return errors.Wrapf(err, "error while uploading image %q", repoImagePath)
+			}
+			progress.imageTransferred(repoImagePath, size, false)
+			return nil
+		})
+	}
+	err := eg.Wait()
+	progress.finish(err)
+	return err
+}
+
+// checkBaseImagesUploaded returns an error if some of the images of the base of an incremental
+// plugin bundle are not present in the remote repository
+func (o *UploadPluginBundleOptions) checkBaseImagesUploaded(baseImages []*ImageCopyInfo) error {
+	log.Infof("checking the %d images of the base of the incremental plugin bundle are present in %q", len(baseImages), o.DestinationRepo)
+	for _, ic := range baseImages {
+		repoImagePath, err := o.destinationImage(ic.RelativeImagePath)
+		if err != nil {
+			return errors.Wrap(err, "error while constructing the repo image path")
+		}
+		if !o.isImageUploaded(ic, repoImagePath) {
+			return errors.Errorf("the image %q of the base of the incremental plugin bundle is not present in the repository, please upload the base plugin bundle first", repoImagePath)
+		}
+	}
+	return nil
+}
+
+// isImageUploaded tells if the image is already present in the remote repository with the same digest.
+// The images of the plugin bundles without digest in their manifest are always uploaded.
+func (o *UploadPluginBundleOptions) isImageUploaded(ic *ImageCopyInfo, repoImagePath string) bool {
+	if ic.Digest == "" {
+		return false
+	}
+	image := repoImagePath + "@" + ic.Digest
+	if ic.Tag != "" {
+		// The image must also be tagged as in the plugin bundle
+		image = repoImagePath + ":" + ic.Tag
+	}
+	algorithm, hex, err := o.ImageProcessor.GetImageDigest(image)
+	return err == nil && algorithm+":"+hex == ic.Digest
+}
+
+// retryPolicy returns the retry policy of the image operations uploading the plugin bundle, the
+// default retry policy of the image operations with the retry settings of the options
+func (o *UploadPluginBundleOptions) retryPolicy() carvelhelpers.RetryPolicy {
+	policy := carvelhelpers.DefaultRetryPolicy()
+	policy.Retries = o.Retries
+	if o.RetryBackoff > 0 {
+		policy.Backoff = o.RetryBackoff
+	}
+	if o.RetryMaxBackoff > 0 {
+		policy.MaxBackoff = o.RetryMaxBackoff
+	}
+	return policy
+}
+
 // mergePluginInventoryMetadata merges the downloaded plugin inventory metadata with
 // existing plugin inventory metadata available on the remote repository
 func (o *UploadPluginBundleOptions) mergePluginInventoryMetadata(pluginInventoryMetadataImageWithTag, bundledPluginInventoryMetadataDBFilePath, tempDir string) error {
+	if o.MergePolicy == plugininventory.MetadataMergePolicyReplace {
+		log.Infof("replacing the plugin inventory metadata of image %q without merging", pluginInventoryMetadataImageWithTag)
+		return nil
+	}
+
 	tempPluginInventoryMetadataDir := filepath.Join(tempDir, "inventory-metadata")
 	err := o.ImageProcessor.DownloadImageAndSaveFilesToDir(pluginInventoryMetadataImageWithTag, tempPluginInventoryMetadataDir)
 	if err == nil {
 		downloadedPluginInventoryMetadataDBFilePath := filepath.Join(tempPluginInventoryMetadataDir, plugininventory.SQliteInventoryMetadataDBFileName)
 		pluginInventoryDB := plugininventory.NewSQLiteInventoryMetadata(bundledPluginInventoryMetadataDBFilePath)
-		err = pluginInventoryDB.MergeInventoryMetadataDatabase(downloadedPluginInventoryMetadataDBFilePath)
+		err = pluginInventoryDB.MergeInventoryMetadataDatabase(downloadedPluginInventoryMetadataDBFilePath, o.MergePolicy)
 		if err != nil {
 			return err
 		}
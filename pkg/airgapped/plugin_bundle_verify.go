@@ -0,0 +1,395 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package airgapped
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+	"gopkg.in/yaml.v3"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+)
+
+// VerifyPluginBundleOptions defines options for verifying plugin bundle
+type VerifyPluginBundleOptions struct {
+	Tar string
+}
+
+// imageTarVerificationConcurrency is the maximum number of image tar files, or of parts of
+// the plugin bundle, verified concurrently
+const imageTarVerificationConcurrency = 4
+
+// verifyImageTar and getFilesMapFromImageTar read the image tar files of the
+// plugin bundle. They can be overridden for unit testing.
+var (
+	verifyImageTar          = carvelhelpers.VerifyImageTar
+	getFilesMapFromImageTar = carvelhelpers.GetFilesMapFromImageTar
+)
+
+// VerifyPluginBundle checks the plugin bundle can be uploaded: its plugin migration manifest is valid,
+// its image tar files are not corrupted and match the digests recorded when the bundle was downloaded,
+// and its plugin inventory database is well-formed and only refers to the images of the bundle.
+// The problems found are returned aggregated in a single error.
+func (o *VerifyPluginBundleOptions) VerifyPluginBundle() error {
+	// create a temporary directory
+	tempDir, err := os.MkdirTemp("", "")
+	if err != nil {
+		return errors.Wrap(err, "unable to create temp directory")
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Untar the specified plugin bundle to the temp directory
+	log.Infof("extracting %q for verification...", o.Tar)
+	manifest, err := extractPluginBundle(o.Tar, nil, tempDir)
+	if err != nil {
+		return err
+	}
+
+	pluginBundleDir := filepath.Join(tempDir, PluginBundleDirName)
+	errs := validatePluginMigrationManifest(manifest, pluginBundleDir)
+	if len(errs) == 0 {
+		errs = append(errs, verifyImageTars(manifest.ImagesToCopy, pluginBundleDir)...)
+		errs = append(errs, verifyBundledPluginInventory(manifest, pluginBundleDir, tempDir)...)
+	}
+	if len(errs) > 0 {
+		return errors.Wrapf(kerrors.NewAggregate(errs), "%d problems found in the plugin bundle", len(errs))
+	}
+	log.Infof("the plugin bundle %q is valid", o.Tar)
+	return nil
+}
+
+// extractPluginBundle extracts the plugin bundle to the directory and returns its plugin migration manifest.
+// The other parts of a plugin bundle split in several parts are the parts, by part index, or the tar files
+// named in the plugin migration manifest in the directory of the tar file when none is specified.
+func extractPluginBundle(tarFile string, parts []string, dir string) (*PluginMigrationManifest, error) {
+	err := extractArchive(tarFile, dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to extract provided file")
+	}
+
+	// Read the plugin migration manifest file
+	bytes, err := os.ReadFile(filepath.Join(dir, PluginBundleDirName, PluginMigrationManifestFile))
+	if err != nil {
+		return nil, errors.Wrap(err, "error while reading plugin migration manifest")
+	}
+	manifest, err := parsePluginMigrationManifest(bytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := extractPluginBundleParts(tarFile, parts, manifest, dir); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// extractPluginBundleParts extracts the other parts of a plugin bundle split in several parts to the
+// directory, after checking they are the parts recorded in the plugin migration manifest
+func extractPluginBundleParts(tarFile string, parts []string, manifest *PluginMigrationManifest, dir string) error {
+	if len(manifest.Parts) == 0 {
+		if len(parts) > 0 {
+			return errors.New("the plugin bundle is not split in parts")
+		}
+		return nil
+	}
+	if len(parts) == 0 {
+		for _, part := range manifest.Parts[1:] {
+			parts = append(parts, filepath.Join(filepath.Dir(tarFile), part.FileName))
+		}
+	} else if len(parts) != len(manifest.Parts)-1 {
+		return errors.Errorf("the plugin bundle is split in %d parts, %d parts are specified", len(manifest.Parts), len(parts)+1)
+	}
+	// The parts are digested concurrently before extracting any of them
+	partErrs := make([]error, len(parts))
+	var eg errgroup.Group
+	eg.SetLimit(imageTarVerificationConcurrency)
+	for i, part := range parts {
+		i, part := i, part
+		eg.Go(func() error {
+			digest, err := fileDigest(part)
+			if err != nil {
+				partErrs[i] = errors.Wrapf(err, "unable to read part %d of the plugin bundle", i+1)
+			} else if digest != manifest.Parts[i+1].Digest {
+				partErrs[i] = errors.Errorf("the file %q is not part %d of the plugin bundle, its digest does not match the plugin migration manifest", part, i+1)
+			}
+			return nil
+		})
+	}
+	_ = eg.Wait()
+	for _, err := range partErrs {
+		if err != nil {
+			return err
+		}
+	}
+	for i, part := range parts {
+		log.Infof("extracting part %d of the plugin bundle %q...", i+1, part)
+		if err := extractArchive(part, dir); err != nil {
+			return errors.Wrapf(err, "unable to extract part %d of the plugin bundle", i+1)
+		}
+	}
+	return nil
+}
+
+// readPluginMigrationManifest returns the plugin migration manifest of the plugin bundle, read without
+// extracting the bundle, or the plugin migration manifest read from a yaml file
+func readPluginMigrationManifest(file string) (*PluginMigrationManifest, error) {
+	var bytes []byte
+	var err error
+	if strings.HasSuffix(file, ".yaml") || strings.HasSuffix(file, ".yml") {
+		bytes, err = os.ReadFile(file)
+	} else {
+		bytes, err = readFileFromTar(file, path.Join(PluginBundleDirName, PluginMigrationManifestFile))
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "error while reading plugin migration manifest")
+	}
+	return parsePluginMigrationManifest(bytes)
+}
+
+// pluginMigrationManifestConverters convert the plugin migration manifests of a version, read
+// by this CLI, to the next version, by version
+var pluginMigrationManifestConverters = map[string]func(*PluginMigrationManifest) error{
+	PluginMigrationManifestVersionV1: convertPluginMigrationManifestV1,
+}
+
+// parsePluginMigrationManifest parses the plugin migration manifest and converts it to the version
+// of the plugin migration manifests written by this CLI. The version is checked before parsing the
+// entire manifest, so that the manifests written by newer CLIs, which this CLI may not be able to
+// parse, are reported as such.
+func parsePluginMigrationManifest(bytes []byte) (*PluginMigrationManifest, error) {
+	header := struct {
+		ManifestVersion string `yaml:"manifestVersion"`
+	}{}
+	if err := yaml.Unmarshal(bytes, &header); err != nil {
+		return nil, errors.Wrap(err, "error while parsing plugin migration manifest")
+	}
+	if header.ManifestVersion == "" {
+		header.ManifestVersion = PluginMigrationManifestVersionV1
+	}
+	if err := checkPluginMigrationManifestVersion(header.ManifestVersion); err != nil {
+		return nil, err
+	}
+
+	manifest := &PluginMigrationManifest{}
+	if err := yaml.Unmarshal(bytes, &manifest); err != nil {
+		return nil, errors.Wrapf(err, "error while parsing plugin migration manifest version %q", header.ManifestVersion)
+	}
+	manifest.ManifestVersion = header.ManifestVersion
+	for manifest.ManifestVersion != PluginMigrationManifestVersion {
+		convert := pluginMigrationManifestConverters[manifest.ManifestVersion]
+		if err := convert(manifest); err != nil {
+			return nil, errors.Wrapf(err, "error while converting plugin migration manifest version %q", header.ManifestVersion)
+		}
+	}
+	return manifest, nil
+}
+
+// checkPluginMigrationManifestVersion returns an error if this CLI cannot read the plugin migration
+// manifests of the version, which are written by a newer CLI when the version is a later version
+func checkPluginMigrationManifestVersion(version string) error {
+	if version == PluginMigrationManifestVersion {
+		return nil
+	}
+	if _, ok := pluginMigrationManifestConverters[version]; ok {
+		return nil
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(version, "v"))
+	if err != nil || !strings.HasPrefix(version, "v") {
+		return errors.Errorf("invalid plugin migration manifest version %q", version)
+	}
+	current, _ := strconv.Atoi(strings.TrimPrefix(PluginMigrationManifestVersion, "v"))
+	if n > current {
+		return errors.Errorf("the plugin bundle was created by a newer version of the Tanzu CLI, its plugin migration manifest version %q is not supported by this version of the Tanzu CLI which supports up to version %q, please upgrade the Tanzu CLI", version, PluginMigrationManifestVersion)
+	}
+	return errors.Errorf("unsupported plugin migration manifest version %q", version)
+}
+
+// convertPluginMigrationManifestV1 converts a plugin migration manifest written by a CLI which did
+// not version the manifests. Such manifests may lack the optional fields added since then, e.g. the
+// tags and digests of the images, which the CLI already handles as unset.
+func convertPluginMigrationManifestV1(manifest *PluginMigrationManifest) error {
+	if manifest.InventoryMetadataImage == nil {
+		return errors.New("the plugin inventory metadata image is missing")
+	}
+	manifest.ManifestVersion = PluginMigrationManifestVersionV2
+	return nil
+}
+
+// readFileFromTar returns the content of a file of the tar file, which can be compressed
+func readFileFromTar(tarFile, name string) ([]byte, error) {
+	file, err := os.Open(tarFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader, closeReader, err := newDecompressingReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer closeReader()
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil, errors.Errorf("the file %q is not part of %q", name, tarFile)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if path.Clean(header.Name) == name {
+			return io.ReadAll(tarReader)
+		}
+	}
+}
+
+// validatePluginMigrationManifest returns the problems found in the plugin migration manifest,
+// all the files it refers to must be part of the plugin bundle
+func validatePluginMigrationManifest(manifest *PluginMigrationManifest, pluginBundleDir string) []error {
+	var errs []error
+	if manifest.RelativeInventoryImagePathWithTag == "" {
+		errs = append(errs, errors.New("the plugin migration manifest has no plugin inventory image"))
+	}
+	if manifest.InventoryMetadataImage == nil || manifest.InventoryMetadataImage.SourceFilePath == "" || manifest.InventoryMetadataImage.RelativeImagePathWithTag == "" {
+		errs = append(errs, errors.New("the plugin migration manifest has no plugin inventory metadata image"))
+	} else if err := checkBundledFile(pluginBundleDir, manifest.InventoryMetadataImage.SourceFilePath); err != nil {
+		errs = append(errs, err)
+	}
+	if len(manifest.ImagesToCopy) == 0 {
+		errs = append(errs, errors.New("the plugin migration manifest has no image to copy"))
+	}
+	for i, ic := range manifest.ImagesToCopy {
+		if ic.SourceTarFilePath == "" || ic.RelativeImagePath == "" {
+			errs = append(errs, errors.Errorf("the image to copy #%d of the plugin migration manifest has no tar file or image path", i+1))
+			continue
+		}
+		if err := checkBundledFile(pluginBundleDir, ic.SourceTarFilePath); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for i, ic := range manifest.BaseImages {
+		if ic.RelativeImagePath == "" || ic.Digest == "" {
+			errs = append(errs, errors.Errorf("the base image #%d of the plugin migration manifest has no image path or digest", i+1))
+		}
+	}
+	return errs
+}
+
+// checkBundledFile returns an error if the file is not part of the plugin bundle
+func checkBundledFile(pluginBundleDir, file string) error {
+	path := filepath.Join(pluginBundleDir, file)
+	if !strings.HasPrefix(path, filepath.Clean(pluginBundleDir)+string(os.PathSeparator)) {
+		return errors.Errorf("invalid file path %q in the plugin migration manifest", file)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return errors.Errorf("the file %q of the plugin migration manifest is missing from the plugin bundle", file)
+	}
+	return nil
+}
+
+// verifyImageTars returns the problems found in the image tar files of the plugin bundle. The digests of
+// the images are only recorded in the plugin bundles downloaded by recent CLIs, the images of the other
+// plugin bundles are only checked to not be corrupted. The image tar files are verified by concurrent
+// workers, as digesting large image tar files one at a time dominates the verification, and the problems
+// are returned in the order of the images.
+func verifyImageTars(imagesToCopy []*ImageCopyInfo, pluginBundleDir string) []error {
+	imageErrs := make([]error, len(imagesToCopy))
+	var eg errgroup.Group
+	eg.SetLimit(imageTarVerificationConcurrency)
+	for i, ic := range imagesToCopy {
+		i, ic := i, ic
+		eg.Go(func() error {
+			log.Infof("verifying image tar %q", ic.SourceTarFilePath)
+			if err := verifyImageTar(filepath.Join(pluginBundleDir, ic.SourceTarFilePath), ic.Digest); err != nil {
+				imageErrs[i] = errors.Wrapf(err, "image %q", ic.RelativeImagePath)
+			}
+			return nil
+		})
+	}
+	_ = eg.Wait()
+
+	var errs []error
+	for _, err := range imageErrs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// verifyBundledPluginInventory returns the problems found in the plugin inventory database of the
+// plugin inventory image, the first image of the bundle, restricted to the plugins and plugin-groups
+// of the plugin inventory metadata database as it is once uploaded.
+func verifyBundledPluginInventory(manifest *PluginMigrationManifest, pluginBundleDir, tempDir string) []error {
+	log.Infof("verifying the plugin inventory database of image tar %q", manifest.ImagesToCopy[0].SourceTarFilePath)
+	inventory, err := extractBundledPluginInventory(manifest, pluginBundleDir, tempDir)
+	if err != nil {
+		return []error{err}
+	}
+	if err := inventory.Validate(context.Background()); err != nil {
+		return []error{err}
+	}
+	plugins, err := inventory.GetPlugins(context.Background(), &plugininventory.PluginInventoryFilter{IncludeHidden: true})
+	if err != nil {
+		return []error{errors.Wrap(err, "unable to read the plugins of the plugin inventory database")}
+	}
+
+	bundledImages := map[string]bool{}
+	// The images of the base of an incremental bundle have already been uploaded
+	for _, ic := range append(append([]*ImageCopyInfo{}, manifest.ImagesToCopy...), manifest.BaseImages...) {
+		bundledImages[ic.RelativeImagePath] = true
+	}
+	var errs []error
+	for _, p := range plugins {
+		for version, artifacts := range p.Artifacts {
+			for _, a := range artifacts {
+				if a.Image == "" {
+					continue
+				}
+				if image := GetImageRelativePath(a.Image, "", false); !bundledImages[image] {
+					errs = append(errs, errors.Errorf("plugin '%s_%s' version %q for %s/%s: the image %q is not part of the plugin bundle", p.Name, p.Target, version, a.OS, a.Arch, image))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// extractBundledPluginInventory saves to the temp directory the plugin inventory database of the plugin
+// inventory image, the first image of the bundle, restricted to the plugins and plugin-groups of the
+// plugin inventory metadata database, and returns the plugin inventory of this database
+func extractBundledPluginInventory(manifest *PluginMigrationManifest, pluginBundleDir, tempDir string) (plugininventory.PluginInventory, error) {
+	inventoryImage := manifest.ImagesToCopy[0]
+	files, err := getFilesMapFromImageTar(filepath.Join(pluginBundleDir, inventoryImage.SourceTarFilePath), inventoryImage.Digest)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the plugin inventory image")
+	}
+	inventoryDB, exists := files[plugininventory.SQliteDBFileName]
+	if !exists {
+		return nil, errors.Errorf("the plugin inventory image has no %q file", plugininventory.SQliteDBFileName)
+	}
+	inventoryDBFilePath := filepath.Join(tempDir, plugininventory.SQliteDBFileName)
+	if err := os.WriteFile(inventoryDBFilePath, inventoryDB, 0o600); err != nil {
+		return nil, err
+	}
+
+	metadataDB := plugininventory.NewSQLiteInventoryMetadata(filepath.Join(pluginBundleDir, manifest.InventoryMetadataImage.SourceFilePath))
+	if err := metadataDB.UpdatePluginInventoryDatabase(inventoryDBFilePath); err != nil {
+		return nil, errors.Wrap(err, "the plugin inventory database or the plugin inventory metadata database is malformed")
+	}
+
+	// The images of the inventory are relative to the repository of the inventory
+	return plugininventory.NewSQLiteInventory(inventoryDBFilePath, ""), nil
+}
@@ -0,0 +1,210 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package airgapped
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/component"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+)
+
+const progressBarWidth = 30
+
+// progressBarRefreshInterval is the minimum delay between two renderings of the progress bar
+// for the bytes transferred, to not redraw it for every chunk of bytes transferred
+const progressBarRefreshInterval = 200 * time.Millisecond
+
+// transferProgress reports the progress of the transfer of the images of a plugin bundle as a whole:
+// the bytes transferred, the percentage, the throughput and the estimated time remaining. The progress
+// is updated every time an image is transferred and, when the image operations report the progress of
+// the transfers of the images, as the bytes of the images are transferred. It is rendered as a progress
+// bar updated in place when the output is a terminal, and as log lines for each image transferred
+// otherwise. It can be updated by concurrent transfers.
+type transferProgress struct {
+	mu sync.Mutex
+	// operation is the transfer, e.g. "uploading", used in the messages
+	operation   string
+	totalImages int
+	// totalBytes is the size of all the images, 0 when unknown in which
+	// case the progress is computed from the number of images
+	totalBytes int64
+	images     int
+	bytes      int64
+	// skippedImages and skippedBytes are the images which did not need to be transferred, they are
+	// part of the progress but not of the throughput
+	skippedImages int
+	skippedBytes  int64
+	// transferring are the bytes transferred so far of the images being transferred, by image
+	transferring map[string]int64
+	start        time.Time
+	// refreshed is the last time the progress bar was rendered
+	refreshed time.Time
+
+	// now, tty, out and logf can be overridden for unit testing
+	now  func() time.Time
+	tty  bool
+	out  io.Writer
+	logf func(format string, args ...interface{})
+}
+
+// newTransferProgress returns the progress of the transfer of the images of a plugin bundle,
+// started now. The size of the images is 0 when unknown.
+func newTransferProgress(operation string, totalImages int, totalBytes int64) *transferProgress {
+	return &transferProgress{
+		operation:   operation,
+		totalImages: totalImages,
+		totalBytes:  totalBytes,
+		start:       time.Now(),
+		now:         time.Now,
+		tty:         component.IsTTYEnabled(),
+		out:         os.Stderr,
+		logf:        log.Infof,
+	}
+}
+
+// imageProgress updates the progress with the bytes transferred so far of an image being transferred.
+// The progress bar is rendered at most every progressBarRefreshInterval and no log line is reported.
+func (p *transferProgress) imageProgress(progress carvelhelpers.Progress) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if progress.Image == "" {
+		return
+	}
+	if p.transferring == nil {
+		p.transferring = map[string]int64{}
+	}
+	p.transferring[progress.Image] = progress.BytesTransferred
+	if now := p.now(); p.tty && now.Sub(p.refreshed) >= progressBarRefreshInterval {
+		p.refreshed = now
+		fmt.Fprintf(p.out, "\r\033[K%s", p.render())
+	}
+}
+
+// imageTransferred updates the progress with the image of the size in bytes which has been
+// transferred, or skipped when it did not need to be transferred
+func (p *transferProgress) imageTransferred(image string, size int64, skipped bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.transferring, image)
+	p.images++
+	p.bytes += size
+	if skipped {
+		p.skippedImages++
+		p.skippedBytes += size
+	}
+	if p.tty {
+		p.refreshed = p.now()
+		fmt.Fprintf(p.out, "\r\033[K%s", p.render())
+	} else {
+		p.logf("%s: %s", p.operation, p.render())
+	}
+}
+
+// finish ends the progress bar and reports the images transferred when the transfer succeeded
+func (p *transferProgress) finish(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.tty && p.images > 0 {
+		fmt.Fprintln(p.out)
+	}
+	if err == nil {
+		p.logf("%s %d images (%s) took %v", p.operation, p.images-p.skippedImages, utils.FormatBytes(p.bytes-p.skippedBytes), p.now().Sub(p.start).Round(time.Second))
+	}
+}
+
+// render returns the progress, e.g.
+// [=========>                    ]  33% 1.0 GiB/3.0 GiB, 10.0 MiB/s, 3m25s remaining (4/12 images)
+func (p *transferProgress) render() string {
+	bytes := p.bytes
+	for _, transferring := range p.transferring {
+		bytes += transferring
+	}
+	done, skipped := p.fractions(bytes)
+	var sb strings.Builder
+	if p.tty {
+		filled := int(done * progressBarWidth)
+		sb.WriteString("[" + strings.Repeat("=", filled))
+		if filled < progressBarWidth {
+			sb.WriteString(">" + strings.Repeat(" ", progressBarWidth-filled-1))
+		}
+		sb.WriteString("] ")
+	}
+	fmt.Fprintf(&sb, "%3d%% ", int(done*100))
+	if p.totalBytes > 0 {
+		fmt.Fprintf(&sb, "%s/%s", utils.FormatBytes(bytes), utils.FormatBytes(p.totalBytes))
+	} else {
+		sb.WriteString(utils.FormatBytes(bytes))
+	}
+
+	elapsed := p.now().Sub(p.start)
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		fmt.Fprintf(&sb, ", %s/s", utils.FormatBytes(int64(float64(bytes-p.skippedBytes)/seconds)))
+	}
+	switch {
+	case done >= 1:
+	case done > skipped:
+		// The time remaining is estimated from the time taken by the images transferred so far
+		remaining := time.Duration(float64(elapsed) * (1 - done) / (done - skipped))
+		fmt.Fprintf(&sb, ", %v remaining", remaining.Round(time.Second))
+	default:
+		sb.WriteString(", estimating time remaining")
+	}
+	fmt.Fprintf(&sb, " (%d/%d images)", p.images, p.totalImages)
+	return sb.String()
+}
+
+// fractions returns the fractions of the transfer done, with the bytes transferred so far, and of the
+// transfer skipped
+func (p *transferProgress) fractions(bytes int64) (done, skipped float64) {
+	switch {
+	case p.totalBytes > 0:
+		done, skipped = float64(bytes)/float64(p.totalBytes), float64(p.skippedBytes)/float64(p.totalBytes)
+	case p.totalImages > 0:
+		done, skipped = float64(p.images)/float64(p.totalImages), float64(p.skippedImages)/float64(p.totalImages)
+	default:
+		return 1, 1
+	}
+	// The sizes of the images can differ slightly from the ones they were expected to have
+	return min(done, 1), min(skipped, 1)
+}
+
+// progressRelay relays the progress reported by the image operations, which are created once for
+// all the transfers of a plugin bundle, to the progress of the transfer in progress if any
+type progressRelay struct {
+	mu       sync.Mutex
+	progress *transferProgress
+}
+
+// report is the progress callback of the image operations
+func (r *progressRelay) report(progress carvelhelpers.Progress) {
+	r.mu.Lock()
+	p := r.progress
+	r.mu.Unlock()
+	if p != nil {
+		p.imageProgress(progress)
+	}
+}
+
+// relayTo relays the progress reported by the image operations to the transfer, or to no transfer
+// when nil. It can be invoked on a nil relay, when the image operations were not created with one.
+func (r *progressRelay) relayTo(p *transferProgress) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.progress = p
+}
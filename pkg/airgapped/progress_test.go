@@ -0,0 +1,119 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package airgapped
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+)
+
+// newTestTransferProgress returns a transfer progress whose clock is advanced by the returned function
+func newTestTransferProgress(totalImages int, totalBytes int64, tty bool) (*transferProgress, *bytes.Buffer, *[]string, func(time.Duration)) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	out := &bytes.Buffer{}
+	lines := &[]string{}
+	p := &transferProgress{
+		operation:   "uploading",
+		totalImages: totalImages,
+		totalBytes:  totalBytes,
+		start:       start,
+		now:         func() time.Time { return now },
+		tty:         tty,
+		out:         out,
+		logf: func(format string, args ...interface{}) {
+			*lines = append(*lines, fmt.Sprintf(format, args...))
+		},
+	}
+	return p, out, lines, func(d time.Duration) { now = now.Add(d) }
+}
+
+func Test_TransferProgressLogLines(t *testing.T) {
+	assert := assert.New(t)
+
+	const mib = 1024 * 1024
+	p, out, lines, advance := newTestTransferProgress(4, 40*mib, false)
+
+	// The skipped images are part of the progress but not of the throughput
+	p.imageTransferred("image1", 10*mib, true)
+	advance(10 * time.Second)
+	p.imageTransferred("image2", 10*mib, false)
+	advance(10 * time.Second)
+	p.imageTransferred("image3", 10*mib, false)
+	advance(5 * time.Second)
+	p.imageTransferred("image4", 10*mib, false)
+	p.finish(nil)
+
+	assert.Empty(out.String())
+	assert.Equal([]string{
+		"uploading:  25% 10.0 MiB/40.0 MiB, estimating time remaining (1/4 images)",
+		"uploading:  50% 20.0 MiB/40.0 MiB, 1.0 MiB/s, 20s remaining (2/4 images)",
+		"uploading:  75% 30.0 MiB/40.0 MiB, 1.0 MiB/s, 10s remaining (3/4 images)",
+		"uploading: 100% 40.0 MiB/40.0 MiB, 1.2 MiB/s (4/4 images)",
+		"uploading 3 images (30.0 MiB) took 25s",
+	}, *lines)
+}
+
+func Test_TransferProgressBar(t *testing.T) {
+	assert := assert.New(t)
+
+	// The progress is based on the number of images when the size of the images is unknown
+	p, out, lines, advance := newTestTransferProgress(3, 0, true)
+	advance(time.Minute)
+	p.imageTransferred("image5", 2048, false)
+	assert.Equal("\r\033[K[==========>                   ]  33% 2.0 KiB, 34 B/s, 2m0s remaining (1/3 images)", out.String())
+	assert.Empty(*lines)
+
+	out.Reset()
+	p.finish(errors.New("fake error"))
+	// The progress bar is ended but no summary is reported when the transfer fails
+	assert.Equal("\n", out.String())
+	assert.Empty(*lines)
+}
+
+func Test_TransferProgressBytesTransferred(t *testing.T) {
+	assert := assert.New(t)
+
+	const mib = 1024 * 1024
+	p, out, lines, advance := newTestTransferProgress(2, 40*mib, true)
+	relay := &progressRelay{}
+	relay.relayTo(p)
+
+	// The bytes of the images being transferred are part of the progress
+	advance(10 * time.Second)
+	relay.report(carvelhelpers.Progress{Image: "image1", BytesTransferred: 5 * mib, TotalBytes: 20 * mib})
+	assert.Equal("\r\033[K[===>                          ]  12% 5.0 MiB/40.0 MiB, 512.0 KiB/s, 1m10s remaining (0/2 images)", out.String())
+
+	// The progress bar is not rendered again until the refresh interval has elapsed
+	out.Reset()
+	relay.report(carvelhelpers.Progress{Image: "image2", BytesTransferred: 5 * mib, TotalBytes: 20 * mib})
+	assert.Empty(out.String())
+	advance(10 * time.Second)
+	relay.report(carvelhelpers.Progress{Image: "image1", BytesTransferred: 15 * mib, TotalBytes: 20 * mib})
+	assert.Equal("\r\033[K[===============>              ]  50% 20.0 MiB/40.0 MiB, 1.0 MiB/s, 20s remaining (0/2 images)", out.String())
+
+	// The bytes of a transferred image are replaced by its size
+	out.Reset()
+	p.imageTransferred("image1", 20*mib, false)
+	assert.Equal("\r\033[K[==================>           ]  62% 25.0 MiB/40.0 MiB, 1.2 MiB/s, 12s remaining (1/2 images)", out.String())
+
+	// No progress is relayed once the transfer is over
+	out.Reset()
+	relay.relayTo(nil)
+	advance(10 * time.Second)
+	relay.report(carvelhelpers.Progress{Image: "image2", BytesTransferred: 20 * mib, TotalBytes: 20 * mib})
+	assert.Empty(out.String())
+	assert.Empty(*lines)
+
+	// The relay of image operations created without it can be used
+	var none *progressRelay
+	none.relayTo(p)
+}
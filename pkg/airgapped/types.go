@@ -7,21 +7,89 @@ package airgapped
 const PluginBundleDirName = "plugin_bundle"
 const PluginMigrationManifestFile = "plugin_migration_manifest.yaml"
 
+// PluginBundleSignatureFileSuffix is appended to the path of a signed plugin bundle to get the path of its signature
+const PluginBundleSignatureFileSuffix = ".sig"
+
+// Versions of the plugin migration manifest. The plugin migration manifests written by older CLIs have
+// no version and are PluginMigrationManifestVersionV1 manifests. The version must be increased when
+// the manifest changes in a way older CLIs cannot read, with a converter from the previous version.
+const (
+	PluginMigrationManifestVersionV1 = "v1"
+	PluginMigrationManifestVersionV2 = "v2"
+	// PluginMigrationManifestVersion is the version of the plugin migration manifests written by this CLI
+	PluginMigrationManifestVersion = PluginMigrationManifestVersionV2
+)
+
 // PluginMigrationManifest defines struct for plugin bundle manifest
 type PluginMigrationManifest struct {
+	// ManifestVersion is the version of the plugin migration manifest, used to reject the plugin
+	// bundles created by newer CLIs and to convert the ones created by older CLIs
+	ManifestVersion                   string            `yaml:"manifestVersion,omitempty"`
 	RelativeInventoryImagePathWithTag string            `yaml:"relativeInventoryImagePathWithTag"`
 	InventoryMetadataImage            *ImagePublishInfo `yaml:"inventoryMetadataImage"`
 	ImagesToCopy                      []*ImageCopyInfo  `yaml:"imagesToCopy"`
+	// BaseImages are the images of the plugins of an incremental bundle which are not part of the
+	// bundle, without tar file, as they are part of its base. They must have been uploaded already.
+	BaseImages []*ImageCopyInfo `yaml:"baseImages,omitempty"`
+	// PluginGroups are the plugin group versions of the bundle with their plugins, used to
+	// upload only some of the plugin groups of the bundle. They are not set by older CLIs.
+	PluginGroups []*PluginGroupCopyInfo `yaml:"pluginGroups,omitempty"`
+	// Parts are the tar files of a plugin bundle split in several parts, by part index, the first
+	// one being the tar file holding the plugin migration manifest. They are not set when the
+	// plugin bundle is a single tar file.
+	Parts []*PluginBundlePart `yaml:"parts,omitempty"`
 }
 
 // ImageCopyInfo maps the relative image path and local relative file path
 type ImageCopyInfo struct {
 	SourceTarFilePath string `yaml:"sourceTarFilePath"`
 	RelativeImagePath string `yaml:"relativeImagePath"`
+	// Tag and Digest of the image are used to skip uploading the images already
+	// present in the destination repository. They are not set by older CLIs.
+	Tag    string `yaml:"tag,omitempty"`
+	Digest string `yaml:"digest,omitempty"`
+	// Plugin is the plugin version of the image, as name@target:version, used to upload
+	// only some of the plugins of the bundle. It is not set for the plugin inventory image.
+	Plugin string `yaml:"plugin,omitempty"`
+	// Part is the index of the part of the plugin bundle holding the image tar file,
+	// when the plugin bundle is split in several parts
+	Part int `yaml:"part,omitempty"`
+}
+
+// PluginBundlePart is a tar file of a plugin bundle split in several parts
+type PluginBundlePart struct {
+	// FileName is the name of the tar file of the part, without its directory
+	FileName string `yaml:"fileName"`
+	// Digest is the sha256 digest of the tar file, used to check the parts match the plugin
+	// migration manifest. It is not set for the first part which holds the manifest.
+	Digest string `yaml:"digest,omitempty"`
+}
+
+// PluginGroupCopyInfo maps a plugin group version to the plugins of the group
+type PluginGroupCopyInfo struct {
+	// Group is the plugin group version, as vendor-publisher/name:version
+	Group string `yaml:"group"`
+	// Plugins are the plugins of the group, as name@target:version
+	Plugins []string `yaml:"plugins"`
 }
 
 // ImagePublishInfo maps the relative image path and local relative file path
 type ImagePublishInfo struct {
 	SourceFilePath           string `yaml:"sourceFilePath"`
 	RelativeImagePathWithTag string `yaml:"relativeImagePathWithTag"`
+	// Signature is the base64 encoded cosign signature of the source file, set
+	// when the plugin bundle is signed while being downloaded
+	Signature string `yaml:"signature,omitempty"`
+}
+
+// PluginBundleMirrors lists the destinations a plugin bundle is uploaded to, e.g. the registry
+// replicas of several isolated sites
+type PluginBundleMirrors struct {
+	Mirrors []*PluginBundleMirror `yaml:"mirrors"`
+}
+
+// PluginBundleMirror is a destination a plugin bundle is uploaded to
+type PluginBundleMirror struct {
+	// Repository is the repository, or the OCI image layout directory, the plugin bundle is uploaded to
+	Repository string `yaml:"repository"`
 }
@@ -17,10 +17,18 @@ import (
 // file contents.
 type fileMapGetterFn func(string) (map[string][]byte, error)
 
+// A platform image resolver takes an OCI image name, possibly referring to a
+// multi-arch image index, and returns the image to use for the os and arch.
+type platformImageResolverFn func(image, os, arch string) (string, error)
+
 // OCIArtifact defines OCI artifact image endpoint
 type OCIArtifact struct {
-	Image                string
-	getFilesMapFromImage fileMapGetterFn
+	Image string
+	// OS and Arch are set when the image is a multi-arch image index
+	OS                      string
+	Arch                    string
+	getFilesMapFromImage    fileMapGetterFn
+	resolveImageForPlatform platformImageResolverFn
 }
 
 // NewOCIArtifact creates OCI Artifact object
@@ -31,9 +39,30 @@ func NewOCIArtifact(image string) Artifact {
 	}
 }
 
+// NewOCIArtifactForPlatform creates OCI Artifact object for an image which
+// may be a multi-arch image index. The image matching the os and arch is used.
+func NewOCIArtifactForPlatform(image, os, arch string) Artifact {
+	return &OCIArtifact{
+		Image:                   image,
+		OS:                      os,
+		Arch:                    arch,
+		getFilesMapFromImage:    carvelhelpers.GetFilesMapFromImage,
+		resolveImageForPlatform: carvelhelpers.ResolveImageForPlatform,
+	}
+}
+
 // Fetch an artifact.
 func (g *OCIArtifact) Fetch() ([]byte, error) {
-	filesMap, err := g.getFilesMapFromImage(g.Image)
+	image := g.Image
+	if g.resolveImageForPlatform != nil {
+		var err error
+		image, err = g.resolveImageForPlatform(g.Image, g.OS, g.Arch)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to resolve the plugin image for the platform")
+		}
+	}
+
+	filesMap, err := g.getFilesMapFromImage(image)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable fetch plugin binary")
 	}
@@ -33,3 +33,28 @@ func TestOCIArtifactWhenMultipleFilesFound(t *testing.T) {
 		t.Fatalf("Did not receive the expected error message. Expected '%s', got '%s'", expectedErrorMessage, err.Error())
 	}
 }
+
+func TestOCIArtifactForPlatform(t *testing.T) {
+	artifact := NewOCIArtifactForPlatform("index", "linux", "arm64")
+	o, _ := artifact.(*OCIArtifact)
+	o.resolveImageForPlatform = func(image, os, arch string) (string, error) {
+		if image != "index" || os != "linux" || arch != "arm64" {
+			t.Fatalf("Unexpected arguments in call to resolve the image: '%s', '%s', '%s'", image, os, arch)
+		}
+		return "index@sha256:1234", nil
+	}
+	o.getFilesMapFromImage = func(s string) (map[string][]byte, error) {
+		if s != "index@sha256:1234" {
+			t.Fatalf("Unexpected image in call to get files map. Expected '%s', got '%s'", "index@sha256:1234", s)
+		}
+		return map[string][]byte{"plugin": []byte("binary")}, nil
+	}
+
+	data, err := o.Fetch()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(data) != "binary" {
+		t.Fatalf("Unexpected data. Expected 'binary', got '%s'", string(data))
+	}
+}
@@ -9,8 +9,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt"
@@ -130,13 +132,45 @@ func GetAccessTokenFromAPIToken(apiToken, issuer string) (*Token, error) {
 }
 
 // GetIssuer returns the appropriate CSP issuer based on the environment.
+// In E2E test environment the issuer can be overridden with a mock issuer,
+// which is only honored if it is served from the loopback interface or from a
+// CSP host, so that the authentication cannot be redirected to any other host.
 func GetIssuer(staging bool) string {
+	if issuer := os.Getenv(constants.E2ETestCSPIssuer); issuer != "" && os.Getenv(constants.E2ETestEnvironment) == "true" {
+		if isAllowedTestIssuer(issuer) {
+			log.Warningf("The CSP issuer is overridden with %q by the %s environment variable, it must only be set for testing", issuer, constants.E2ETestCSPIssuer)
+			return issuer
+		}
+		log.Warningf("Ignoring the CSP issuer %q of the %s environment variable, it is neither a local nor a CSP issuer", issuer, constants.E2ETestCSPIssuer)
+	}
 	if staging {
 		return StgIssuer
 	}
 	return ProdIssuer
 }
 
+// isAllowedTestIssuer returns true if the issuer is on the loopback interface,
+// like the mock issuer of the E2E tests, or on the host of a CSP issuer
+func isAllowedTestIssuer(issuer string) bool {
+	u, err := url.Parse(issuer)
+	if err != nil || u.Hostname() == "" {
+		return false
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return true
+	}
+	for _, cspIssuer := range []string{ProdIssuer, StgIssuer} {
+		if cspURL, err := url.Parse(cspIssuer); err == nil && u.Scheme == cspURL.Scheme && host == cspURL.Hostname() {
+			return true
+		}
+	}
+	return false
+}
+
 // IsExpired checks for the token expiry and returns true if the token has expired else will return false
 func IsExpired(tokenExpiry time.Time) bool {
 	// refresh at half token life
@@ -20,6 +20,7 @@ import (
 
 	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
 
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/fakes"
 )
 
@@ -104,6 +105,34 @@ func TestIsExpired(t *testing.T) {
 	assert.False(IsExpired(testTime))
 }
 
+func TestGetIssuer(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(ProdIssuer, GetIssuer(false))
+	assert.Equal(StgIssuer, GetIssuer(true))
+
+	// the mock issuer is ignored outside of the E2E test environment
+	t.Setenv(constants.E2ETestCSPIssuer, "http://127.0.0.1:8080/csp/gateway/am/api")
+	assert.Equal(ProdIssuer, GetIssuer(false))
+
+	t.Setenv(constants.E2ETestEnvironment, "true")
+	assert.Equal("http://127.0.0.1:8080/csp/gateway/am/api", GetIssuer(false))
+	assert.Equal("http://127.0.0.1:8080/csp/gateway/am/api", GetIssuer(true))
+
+	t.Setenv(constants.E2ETestCSPIssuer, "http://localhost:8080/csp/gateway/am/api")
+	assert.Equal("http://localhost:8080/csp/gateway/am/api", GetIssuer(false))
+
+	// an issuer on another host than the loopback interface or a CSP host is ignored
+	for _, issuer := range []string{"https://attacker.example.com/csp/gateway/am/api", "http://console.cloud.vmware.com.example.com", "not a url"} {
+		t.Setenv(constants.E2ETestCSPIssuer, issuer)
+		assert.Equal(ProdIssuer, GetIssuer(false))
+		assert.Equal(StgIssuer, GetIssuer(true))
+	}
+
+	t.Setenv(constants.E2ETestCSPIssuer, StgIssuer)
+	assert.Equal(StgIssuer, GetIssuer(false))
+}
+
 func generateJWTToken(claims string) string {
 	hm := hmac.New(sha256.New, []byte("secret"))
 	_, _ = hm.Write([]byte(fmt.Sprintf(
@@ -0,0 +1,288 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package carvelhelpers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	regv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/registry"
+)
+
+// The files of an image can also be published in chunks, each chunk being a layer of
+// a separate image. As the layers are content-addressed, a client which has downloaded
+// a previous version of the files only downloads the chunks which have changed since.
+// This suits files updated in place like SQLite databases, whose pages keep their offset.
+
+const (
+	// DefaultChunkSize is the size of the chunks of the files, a multiple of the page size of SQLite
+	DefaultChunkSize = 1024 * 1024
+
+	// chunksImageRepositorySuffix is appended to the repository of an image to get the repository
+	// of the image holding the chunks of its files
+	chunksImageRepositorySuffix = "-chunks"
+	// chunkMediaType is the media type of the layers of the chunks, each layer being a gzipped chunk
+	chunkMediaType = types.MediaType("application/vnd.vmware.tanzu.cli.file.chunk.v1+gzip")
+	// chunkFileAnnotation is the annotation of the layers giving the name of the file of the chunk
+	chunkFileAnnotation = "org.vmware.tanzu.cli.chunk.file"
+	// chunkedFilesDigestsAnnotation is the annotation of the image giving the SHA256 digest of each file
+	chunkedFilesDigestsAnnotation = "org.vmware.tanzu.cli.chunk.digests"
+)
+
+// ChunksImageForImage returns the image holding the chunks of the files of the image pinned to
+// a digest. The chunks of each version of the image are tagged after its digest, e.g. the chunks
+// of "repo/image:latest@sha256:1234" are published as "repo/image-chunks:sha256-1234".
+func ChunksImageForImage(imageWithDigest string) (string, error) {
+	repository, digest := splitImageDigest(imageWithDigest)
+	if digest == "" {
+		return "", errors.Errorf("the image %q is not pinned to a digest", imageWithDigest)
+	}
+	if hasTag(repository) {
+		repository = repository[:strings.LastIndex(repository, ":")]
+	}
+	return repository + chunksImageRepositorySuffix + ":" + strings.Replace(digest, ":", "-", 1), nil
+}
+
+// PushFilesInChunks publishes the image holding the chunks of the files.
+// Files are added by name, directories are added with their content.
+func PushFilesInChunks(imageWithTag string, filePaths []string, chunkSize int) error {
+	files := map[string][]byte{}
+	for _, filePath := range filePaths {
+		if err := addFilesToMap(filePath, files); err != nil {
+			return err
+		}
+	}
+	img, err := newChunksImage(files, chunkSize)
+	if err != nil {
+		return errors.Wrap(err, "unable to create the image of the chunks")
+	}
+
+	g := &GGCRImageOperations{}
+	ref, opts, err := g.parseReference(imageWithTag)
+	if err != nil {
+		return err
+	}
+	err = runWithRetries(g.retry, "pushing image chunks", func(ctx context.Context) error {
+		return g.writeImage(ctx, imageWithTag, ref, img, opts)
+	})
+	return registry.DiagnoseRegistryError(ref.Context().RegistryStr(), err)
+}
+
+// DownloadChunkedFilesToDir downloads the files of the image holding their chunks and
+// saves them to the specified location. The chunks are cached in the chunks directory,
+// only the chunks which are not already cached are downloaded and the chunks of the
+// previous versions of the files are removed. It returns the number of chunks downloaded.
+func DownloadChunkedFilesToDir(chunksImage, destinationDir, chunksDir string) (int, error) {
+	g := &GGCRImageOperations{}
+	ref, opts, err := g.parseReference(chunksImage)
+	if err != nil {
+		return 0, err
+	}
+	var downloaded int
+	files, err := runWithRetriesAndResult(nil, "fetching image chunks", func(ctx context.Context) (map[string][]byte, error) {
+		img, err := remote.Image(ref, append(opts, remote.WithContext(ctx))...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to fetch image %q", chunksImage)
+		}
+		var files map[string][]byte
+		files, downloaded, err = readChunkedFiles(img, chunksDir, registry.GetDownloadConcurrency())
+		return files, err
+	})
+	if err != nil {
+		return 0, registry.DiagnoseRegistryError(ref.Context().RegistryStr(), err)
+	}
+	return downloaded, saveFilesToDir(files, destinationDir, chunksImage)
+}
+
+// newChunksImage returns the image holding the chunks of the files, in the order of the files
+func newChunksImage(files map[string][]byte, chunkSize int) (regv1.Image, error) {
+	if chunkSize <= 0 {
+		return nil, errors.Errorf("invalid chunk size %d", chunkSize)
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	digests := map[string]string{}
+	var addenda []mutate.Addendum
+	for _, name := range names {
+		content := files[name]
+		digests[name] = fmt.Sprintf("%x", sha256.Sum256(content))
+		for offset := 0; offset == 0 || offset < len(content); offset += chunkSize {
+			chunk, err := gzipChunk(content[offset:min(offset+chunkSize, len(content))])
+			if err != nil {
+				return nil, err
+			}
+			addenda = append(addenda, mutate.Addendum{
+				Layer:       static.NewLayer(chunk, chunkMediaType),
+				Annotations: map[string]string{chunkFileAnnotation: name},
+			})
+		}
+	}
+	digestsAnnotation, err := json.Marshal(digests)
+	if err != nil {
+		return nil, err
+	}
+
+	img := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	img = mutate.ConfigMediaType(img, types.OCIConfigJSON)
+	img, err = mutate.Append(img, addenda...)
+	if err != nil {
+		return nil, err
+	}
+	return mutate.Annotations(img, map[string]string{chunkedFilesDigestsAnnotation: string(digestsAnnotation)}).(regv1.Image), nil
+}
+
+// readChunkedFiles returns the files of the image holding their chunks, reading the chunks
+// cached in the chunks directory and caching the others once downloaded. Up to `concurrency`
+// chunks are downloaded concurrently. It also returns the number of chunks downloaded.
+func readChunkedFiles(img regv1.Image, chunksDir string, concurrency int) (map[string][]byte, int, error) {
+	manifest, err := img.Manifest()
+	if err != nil {
+		return nil, 0, err
+	}
+	digests := map[string]string{}
+	if err := json.Unmarshal([]byte(manifest.Annotations[chunkedFilesDigestsAnnotation]), &digests); err != nil {
+		return nil, 0, errors.Wrap(err, "the image does not hold the chunks of files")
+	}
+	if err := os.MkdirAll(chunksDir, os.ModePerm); err != nil {
+		return nil, 0, err
+	}
+
+	chunks := make([][]byte, len(manifest.Layers))
+	var downloaded int32
+	var eg errgroup.Group
+	eg.SetLimit(concurrency)
+	for i := range manifest.Layers {
+		i := i
+		eg.Go(func() error {
+			digest := manifest.Layers[i].Digest
+			chunkFile := filepath.Join(chunksDir, digest.Hex)
+			if chunk, err := os.ReadFile(chunkFile); err == nil {
+				chunks[i] = chunk
+				return nil
+			}
+			chunk, err := readChunk(img, digest)
+			if err != nil {
+				return err
+			}
+			cacheChunk(chunkFile, chunk)
+			atomic.AddInt32(&downloaded, 1)
+			chunks[i] = chunk
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, 0, err
+	}
+
+	files := map[string][]byte{}
+	for i, layer := range manifest.Layers {
+		name := layer.Annotations[chunkFileAnnotation]
+		if _, exists := files[name]; !exists {
+			files[name] = []byte{}
+		}
+		files[name] = append(files[name], chunks[i]...)
+	}
+	for name, content := range files {
+		if fmt.Sprintf("%x", sha256.Sum256(content)) != digests[name] {
+			// A cached chunk may have been corrupted, download all the chunks next time
+			_ = os.RemoveAll(chunksDir)
+			return nil, 0, errors.Errorf("the chunks of the file %q do not match its digest", name)
+		}
+	}
+	if len(files) != len(digests) {
+		return nil, 0, errors.New("the image does not hold the chunks of all its files")
+	}
+	pruneChunks(chunksDir, manifest.Layers)
+	return files, int(downloaded), nil
+}
+
+// readChunk downloads the layer of the chunk and returns the chunk
+func readChunk(img regv1.Image, digest regv1.Hash) ([]byte, error) {
+	layer, err := img.LayerByDigest(digest)
+	if err != nil {
+		return nil, err
+	}
+	compressed, err := layer.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer compressed.Close()
+	reader, err := gzip.NewReader(compressed)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid chunk %q", digest)
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// cacheChunk saves the chunk to the chunk file. The chunk is written to a temporary file
+// renamed once complete, as the same chunk may be read concurrently for another offset.
+// The cache is an optimization, failing to update it is not an error.
+func cacheChunk(chunkFile string, chunk []byte) {
+	tmpFile, err := os.CreateTemp(filepath.Dir(chunkFile), filepath.Base(chunkFile)+".tmp")
+	if err != nil {
+		return
+	}
+	_, err = tmpFile.Write(chunk)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpFile.Name(), chunkFile)
+	}
+	if err != nil {
+		_ = os.Remove(tmpFile.Name())
+	}
+}
+
+// gzipChunk compresses the chunk. The compressed chunk only depends on the content of
+// the chunk, so that the unchanged chunks of a file keep the same digest.
+func gzipChunk(chunk []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(chunk); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// pruneChunks removes the cached chunks which are not layers of the image anymore
+func pruneChunks(chunksDir string, layers []regv1.Descriptor) {
+	current := map[string]bool{}
+	for _, layer := range layers {
+		current[layer.Digest.Hex] = true
+	}
+	entries, _ := os.ReadDir(chunksDir)
+	for _, entry := range entries {
+		if !current[entry.Name()] {
+			_ = os.Remove(filepath.Join(chunksDir, entry.Name()))
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package carvelhelpers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ChunksImageForImage(t *testing.T) {
+	assert := assert.New(t)
+
+	image, err := ChunksImageForImage("localhost:5001/test/plugin-inventory:latest@sha256:1234")
+	assert.Nil(err)
+	assert.Equal("localhost:5001/test/plugin-inventory-chunks:sha256-1234", image)
+
+	image, err = ChunksImageForImage("localhost:5001/test/plugin-inventory@sha256:1234")
+	assert.Nil(err)
+	assert.Equal("localhost:5001/test/plugin-inventory-chunks:sha256-1234", image)
+
+	_, err = ChunksImageForImage("localhost:5001/test/plugin-inventory:latest")
+	assert.ErrorContains(err, "is not pinned to a digest")
+}
+
+func Test_ReadChunkedFiles(t *testing.T) {
+	assert := assert.New(t)
+
+	chunksDir := filepath.Join(t.TempDir(), "chunks")
+	files := map[string][]byte{
+		"plugin_inventory.db": []byte("aaaabbbbccccdd"),
+		"empty":               {},
+	}
+	img, err := newChunksImage(files, 4)
+	assert.Nil(err)
+	layers, err := img.Layers()
+	assert.Nil(err)
+	assert.Equal(5, len(layers))
+
+	// All the chunks are downloaded the first time
+	read, downloaded, err := readChunkedFiles(img, chunksDir, 2)
+	assert.Nil(err)
+	assert.Equal(files, read)
+	assert.Equal(5, downloaded)
+
+	// No chunk is downloaded when the files have not changed
+	read, downloaded, err = readChunkedFiles(img, chunksDir, 2)
+	assert.Nil(err)
+	assert.Equal(files, read)
+	assert.Equal(0, downloaded)
+
+	// Only the changed chunks are downloaded, the chunks of the previous files are removed
+	files["plugin_inventory.db"] = []byte("aaaaBBBBccccddeeee")
+	img, err = newChunksImage(files, 4)
+	assert.Nil(err)
+	read, downloaded, err = readChunkedFiles(img, chunksDir, 2)
+	assert.Nil(err)
+	assert.Equal(files, read)
+	assert.Equal(3, downloaded)
+	entries, err := os.ReadDir(chunksDir)
+	assert.Nil(err)
+	assert.Equal(6, len(entries))
+
+	// A corrupted chunk is detected and the cached chunks are discarded
+	assert.Nil(os.WriteFile(filepath.Join(chunksDir, entries[0].Name()), []byte("corrupted"), 0644))
+	_, _, err = readChunkedFiles(img, chunksDir, 2)
+	assert.ErrorContains(err, "do not match its digest")
+	assert.NoDirExists(chunksDir)
+}
+
+func Test_ReadChunkedFiles_NotChunks(t *testing.T) {
+	assert := assert.New(t)
+
+	img, err := crane.Image(map[string][]byte{"plugin_inventory.db": []byte("aaaa")})
+	assert.Nil(err)
+	_, _, err = readChunkedFiles(img, t.TempDir(), 2)
+	assert.ErrorContains(err, "the image does not hold the chunks of files")
+
+	_, err = newChunksImage(map[string][]byte{"plugin_inventory.db": []byte("aaaa")}, 0)
+	assert.ErrorContains(err, "invalid chunk size")
+}
@@ -0,0 +1,49 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package carvelhelpers
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ImageWithDigest returns the image pinned to the specified digest.
+// The tag of the image, if any, is kept but the digest takes precedence
+// when the image is pulled, e.g. `registry/path/image:tag@sha256:<hex>`.
+func ImageWithDigest(image, algorithm, hex string) string {
+	tagged, _ := splitImageDigest(image)
+	return tagged + "@" + algorithm + ":" + hex
+}
+
+// PinImageToDigest resolves the tag of the image to a digest and returns the
+// image pinned to this digest. Pulling the returned image guarantees to always
+// get the same content, even if the tag is updated in the meantime.
+// Images which are already pinned to a digest are returned as is.
+func PinImageToDigest(imageOps ImageOperationsImpl, image string) (string, error) {
+	if _, digest := splitImageDigest(image); digest != "" {
+		return image, nil
+	}
+	algorithm, hex, err := imageOps.GetImageDigest(image)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to resolve the digest of the image %q", image)
+	}
+	if hex == "" {
+		return "", errors.Errorf("unable to resolve the digest of the image %q", image)
+	}
+	return ImageWithDigest(image, algorithm, hex), nil
+}
+
+// splitImageDigest splits the image into the image without digest and the digest
+func splitImageDigest(image string) (string, string) {
+	if i := strings.Index(image, "@"); i >= 0 {
+		return image[:i], image[i+1:]
+	}
+	return image, ""
+}
+
+// hasTag returns true if the image without digest specifies a tag
+func hasTag(image string) bool {
+	return strings.Contains(image[strings.LastIndex(image, "/")+1:], ":")
+}
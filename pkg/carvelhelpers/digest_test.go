@@ -0,0 +1,57 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package carvelhelpers
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDigestImageOperations only implements the GetImageDigest function
+type fakeDigestImageOperations struct {
+	ImageOperationsImpl
+	algorithm string
+	hex       string
+	err       error
+	calls     int
+}
+
+func (f *fakeDigestImageOperations) GetImageDigest(_ string) (string, string, error) {
+	f.calls++
+	return f.algorithm, f.hex, f.err
+}
+
+func Test_ImageWithDigest(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("localhost:5000/test/image:v1@sha256:1234", ImageWithDigest("localhost:5000/test/image:v1", "sha256", "1234"))
+	assert.Equal("localhost:5000/test/image@sha256:1234", ImageWithDigest("localhost:5000/test/image", "sha256", "1234"))
+	assert.Equal("localhost:5000/test/image:v1@sha256:5678", ImageWithDigest("localhost:5000/test/image:v1@sha256:1234", "sha256", "5678"))
+
+	assert.True(hasTag("localhost:5000/test/image:v1"))
+	assert.False(hasTag("localhost:5000/test/image"))
+}
+
+func Test_PinImageToDigest(t *testing.T) {
+	assert := assert.New(t)
+
+	fakeImageOperations := &fakeDigestImageOperations{algorithm: "sha256", hex: "1234"}
+
+	image, err := PinImageToDigest(fakeImageOperations, "test/image:v1")
+	assert.Nil(err)
+	assert.Equal("test/image:v1@sha256:1234", image)
+
+	// Images already pinned to a digest are not resolved again
+	image, err = PinImageToDigest(fakeImageOperations, "test/image:v1@sha256:5678")
+	assert.Nil(err)
+	assert.Equal("test/image:v1@sha256:5678", image)
+	assert.Equal(1, fakeImageOperations.calls)
+
+	fakeImageOperations.err = errors.New("image not found")
+	_, err = PinImageToDigest(fakeImageOperations, "test/image:v1")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "image not found")
+}
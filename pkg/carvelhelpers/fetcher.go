@@ -14,14 +14,16 @@ import (
 // GetFilesMapFromImage returns map of files metadata
 // It takes os environment variables for custom repository and proxy
 // configuration into account while downloading image from repository
+// The files of the image are served from the image cache if available.
 func GetFilesMapFromImage(imageWithTag string) (map[string][]byte, error) {
-	return NewImageOperationsImpl().GetFilesMapFromImage(imageWithTag)
+	return NewCachingImageOperations(NewImageOperationsImpl()).GetFilesMapFromImage(imageWithTag)
 }
 
 // DownloadImageAndSaveFilesToDir reads a plain OCI image and saves its
 // files to the specified location.
+// The files of the image are served from the image cache if available.
 func DownloadImageAndSaveFilesToDir(imageWithTag, destinationDir string) error {
-	return NewImageOperationsImpl().DownloadImageAndSaveFilesToDir(imageWithTag, destinationDir)
+	return NewCachingImageOperations(NewImageOperationsImpl()).DownloadImageAndSaveFilesToDir(imageWithTag, destinationDir)
 }
 
 // GetImageDigest gets digest of the image
@@ -31,7 +33,12 @@ func GetImageDigest(imageWithTag string) (string, string, error) {
 
 // newRegistry returns a new registry object by also taking
 // into account for any custom registry provided by the user
-func newRegistry(registryHost string) (registry.Registry, error) {
+// and the connection options of the invocation, if any.
+// An error is returned if the registry is not allowed.
+func newRegistry(registryHost string, connection *registry.ConnectionOptions) (registry.Registry, error) {
+	if err := registry.CheckRegistryAllowed(registryHost); err != nil {
+		return nil, err
+	}
 	registryOpts := &ctlimg.Opts{
 		Anon: true,
 	}
@@ -40,6 +47,7 @@ func newRegistry(registryHost string) (registry.Registry, error) {
 	if err != nil {
 		return nil, errors.Wrapf(err, "unable to get the registry certificate configuration")
 	}
+	connection.ApplyToCertOptions(regCertOptions)
 	registryOpts.CACertPaths = regCertOptions.CACertPaths
 	registryOpts.VerifyCerts = !(regCertOptions.SkipCertVerify)
 	registryOpts.Insecure = regCertOptions.Insecure
@@ -0,0 +1,97 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package carvelhelpers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/imagecache"
+)
+
+// CachingImageOperations decorates an ImageOperationsImpl to serve the files
+// of the images from the shared image cache, only downloading the images
+// which are not already cached. The images are looked up by digest.
+type CachingImageOperations struct {
+	ImageOperationsImpl
+	cache *imagecache.Cache
+}
+
+// NewCachingImageOperations returns the image operations using the shared image cache
+func NewCachingImageOperations(imageOps ImageOperationsImpl) ImageOperationsImpl {
+	return &CachingImageOperations{
+		ImageOperationsImpl: imageOps,
+		cache:               imagecache.NewDefaultCache(),
+	}
+}
+
+// GetFilesMapFromImage returns map of files metadata, using the cached
+// files of the image if available
+func (c *CachingImageOperations) GetFilesMapFromImage(imageWithTag string) (map[string][]byte, error) {
+	if !c.cache.Enabled() {
+		return c.ImageOperationsImpl.GetFilesMapFromImage(imageWithTag)
+	}
+	imageWithDigest, err := PinImageToDigest(c.ImageOperationsImpl, imageWithTag)
+	if err != nil {
+		log.V(4).Infof("not using the image cache: %v", err)
+		return c.ImageOperationsImpl.GetFilesMapFromImage(imageWithTag)
+	}
+	_, digest := splitImageDigest(imageWithDigest)
+	if files, found := c.cache.Get(digest); found {
+		log.V(4).Infof("using the cached content of the image %q", imageWithDigest)
+		return files, nil
+	}
+
+	// Download the image by digest to cache the content matching the digest
+	files, err := c.ImageOperationsImpl.GetFilesMapFromImage(imageWithDigest)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.cache.Put(digest, files); err != nil {
+		// The cache is an optimization, failing to update it is not an error
+		log.V(4).Infof("unable to cache the content of the image %q: %v", imageWithDigest, err)
+	}
+	return files, nil
+}
+
+// DownloadImageAndSaveFilesToDir saves the files of the image to the
+// specified location, using the cached files of the image if available
+func (c *CachingImageOperations) DownloadImageAndSaveFilesToDir(imageWithTag, destinationDir string) error {
+	if !c.cache.Enabled() {
+		return c.ImageOperationsImpl.DownloadImageAndSaveFilesToDir(imageWithTag, destinationDir)
+	}
+	files, err := c.GetFilesMapFromImage(imageWithTag)
+	if err != nil {
+		return errors.Wrap(err, "error downloading image")
+	}
+	return saveFilesToDir(files, destinationDir, imageWithTag)
+}
+
+// GetFileDigestFromImage returns the digest of the specified file of the image,
+// using the cached files of the image if available
+func (c *CachingImageOperations) GetFileDigestFromImage(imageWithTag, fileName string) (string, error) {
+	return getFileDigestFromImage(c, imageWithTag, fileName)
+}
+
+// saveFilesToDir writes the files of the image to the destination directory
+func saveFilesToDir(files map[string][]byte, destinationDir, image string) error {
+	for name, content := range files {
+		path := filepath.Join(destinationDir, filepath.FromSlash(name))
+		if !strings.HasPrefix(path, filepath.Clean(destinationDir)+string(os.PathSeparator)) {
+			return errors.Errorf("invalid file path %q in image %q", name, image)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
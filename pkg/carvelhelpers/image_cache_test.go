@@ -0,0 +1,67 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package carvelhelpers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/imagecache"
+)
+
+// fakeFilesImageOperations only implements the GetImageDigest and GetFilesMapFromImage functions
+type fakeFilesImageOperations struct {
+	fakeDigestImageOperations
+	files       map[string][]byte
+	pulledImage string
+	pulls       int
+}
+
+func (f *fakeFilesImageOperations) GetFilesMapFromImage(image string) (map[string][]byte, error) {
+	f.pulls++
+	f.pulledImage = image
+	return f.files, nil
+}
+
+func Test_CachingImageOperations(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := os.MkdirTemp("", "test-image-cache")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	fakeImageOperations := &fakeFilesImageOperations{
+		fakeDigestImageOperations: fakeDigestImageOperations{algorithm: "sha256", hex: "1234"},
+		files:                     map[string][]byte{"dir/file.yaml": []byte("content")},
+	}
+	imageOps := &CachingImageOperations{
+		ImageOperationsImpl: fakeImageOperations,
+		cache:               imagecache.NewCache(filepath.Join(dir, "cache"), 1024),
+	}
+
+	// The image is pulled by digest the first time only
+	for i := 0; i < 2; i++ {
+		files, err := imageOps.GetFilesMapFromImage("test/image:v1")
+		assert.Nil(err)
+		assert.Equal(fakeImageOperations.files, files)
+	}
+	assert.Equal(1, fakeImageOperations.pulls)
+	assert.Equal("test/image:v1@sha256:1234", fakeImageOperations.pulledImage)
+
+	destDir := filepath.Join(dir, "dest")
+	assert.Nil(imageOps.DownloadImageAndSaveFilesToDir("test/image:v1", destDir))
+	assert.Equal(1, fakeImageOperations.pulls)
+	content, err := os.ReadFile(filepath.Join(destDir, "dir", "file.yaml"))
+	assert.Nil(err)
+	assert.Equal("content", string(content))
+
+	// A different digest is pulled again
+	fakeImageOperations.hex = "5678"
+	_, err = imageOps.GetFilesMapFromImage("test/image:v1")
+	assert.Nil(err)
+	assert.Equal(2, fakeImageOperations.pulls)
+}
@@ -0,0 +1,134 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package carvelhelpers
+
+import (
+	"context"
+
+	regv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/pkg/errors"
+)
+
+// PlatformImage is an image containing the content for a specific platform
+type PlatformImage struct {
+	// Image is the location of the image
+	Image string
+	// OS is the operating system of the platform
+	OS string
+	// Arch is the architecture of the platform
+	Arch string
+}
+
+// ResolveImageForPlatform returns the image to use for the specified platform.
+// If the image is a multi-arch image index, the digest reference of the image
+// matching the platform is returned, otherwise the image is returned as is.
+func ResolveImageForPlatform(image, os, arch string) (string, error) {
+	g := &GGCRImageOperations{}
+	ref, opts, err := g.parseReference(image)
+	if err != nil {
+		return "", err
+	}
+	desc, err := runWithRetriesAndResult(nil, "resolving image index", func(ctx context.Context) (*remote.Descriptor, error) {
+		return remote.Get(ref, append(opts, remote.WithContext(ctx))...)
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to fetch image %q", image)
+	}
+	if !desc.MediaType.IsIndex() {
+		return image, nil
+	}
+
+	index, err := desc.ImageIndex()
+	if err != nil {
+		return "", err
+	}
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to read the image index %q", image)
+	}
+	for _, m := range manifest.Manifests {
+		if m.Platform != nil && m.Platform.OS == os && m.Platform.Architecture == arch {
+			return ref.Context().Digest(m.Digest.String()).String(), nil
+		}
+	}
+	return "", errors.Errorf("image index %q does not contain an image for %s/%s", image, os, arch)
+}
+
+// GetImageIndexPlatformImages returns the images of all the platforms referenced by the
+// multi-arch image index. The images are returned as digest references.
+func GetImageIndexPlatformImages(indexImage string) ([]PlatformImage, error) {
+	g := &GGCRImageOperations{}
+	ref, opts, err := g.parseReference(indexImage)
+	if err != nil {
+		return nil, err
+	}
+	index, err := runWithRetriesAndResult(nil, "fetching image index", func(ctx context.Context) (regv1.ImageIndex, error) {
+		return remote.Index(ref, append(opts, remote.WithContext(ctx))...)
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to fetch image index %q", indexImage)
+	}
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read the image index %q", indexImage)
+	}
+	var platformImages []PlatformImage
+	for _, m := range manifest.Manifests {
+		if m.Platform == nil {
+			continue
+		}
+		platformImages = append(platformImages, PlatformImage{
+			Image: ref.Context().Digest(m.Digest.String()).String(),
+			OS:    m.Platform.OS,
+			Arch:  m.Platform.Architecture,
+		})
+	}
+	return platformImages, nil
+}
+
+// PushImageIndex publishes a multi-arch image index referencing the specified platform images.
+// The platform images must already be published. The image index is not published
+// again if the same image index is already published.
+func PushImageIndex(indexImage string, platformImages []PlatformImage) error {
+	g := &GGCRImageOperations{}
+	var index regv1.ImageIndex = mutate.IndexMediaType(empty.Index, types.OCIImageIndex)
+	for _, pi := range platformImages {
+		ref, opts, err := g.parseReference(pi.Image)
+		if err != nil {
+			return err
+		}
+		img, err := runWithRetriesAndResult(nil, "fetching platform image", func(ctx context.Context) (regv1.Image, error) {
+			return remote.Image(ref, append(opts, remote.WithContext(ctx))...)
+		})
+		if err != nil {
+			return errors.Wrapf(err, "unable to fetch image %q", pi.Image)
+		}
+		index = mutate.AppendManifests(index, mutate.IndexAddendum{
+			Add: img,
+			Descriptor: regv1.Descriptor{
+				Platform: &regv1.Platform{OS: pi.OS, Architecture: pi.Arch},
+			},
+		})
+	}
+
+	ref, opts, err := g.parseReference(indexImage)
+	if err != nil {
+		return err
+	}
+	// Publishing the same image index again is skipped
+	digest, err := index.Digest()
+	if err != nil {
+		return err
+	}
+	if desc, err := remote.Head(ref, opts...); err == nil && desc.Digest == digest {
+		return nil
+	}
+	return runWithRetries(nil, "pushing image index", func(ctx context.Context) error {
+		return remote.WriteIndex(ref, index, append(opts, remote.WithContext(ctx))...)
+	})
+}
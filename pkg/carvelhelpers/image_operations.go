@@ -6,19 +6,53 @@ package carvelhelpers
 import (
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/pkg/errors"
 
 	"github.com/vmware-tanzu/tanzu-cli/cmd/plugin/builder/helpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/registry"
 )
 
+// Supported implementations of the ImageOperationsImpl interface
+const (
+	ImageOperationsImplementationImgpkg = "imgpkg"
+	ImageOperationsImplementationGGCR   = "go-containerregistry"
+)
+
 // ImageOperationOptions implements the ImageOperationsImpl interface by using `imgpkg` library
-type ImageOperationOptions struct{}
+type ImageOperationOptions struct {
+	progress   ProgressFunc
+	connection *registry.ConnectionOptions
+	retry      *RetryPolicy
+}
 
-// NewImageOperationsImpl creates new ImgpkgWrapper instance
-func NewImageOperationsImpl() ImageOperationsImpl {
-	return &ImageOperationOptions{}
+// NewImageOperationsImpl creates a new ImageOperationsImpl instance.
+// The `imgpkg` based implementation is used by default. The `go-containerregistry` based
+// implementation can be selected with the TANZU_CLI_IMAGE_OPERATIONS_IMPLEMENTATION variable.
+// It is also used when a proxy is set in the connection options, as the imgpkg commands
+// cannot be given a transport of their own.
+func NewImageOperationsImpl(opts ...ImageOperationsOption) ImageOperationsImpl {
+	if strings.EqualFold(os.Getenv(constants.ConfigVariableImageOperationsImplementation), ImageOperationsImplementationGGCR) {
+		return NewGGCRImageOperations(opts...)
+	}
+	if c := newImageOperationsConfig(opts); c.connection.ProxyFunc() != nil {
+		return NewGGCRImageOperations(opts...)
+	}
+	return NewImgpkgImageOperations(opts...)
+}
+
+// NewImgpkgImageOperations creates a new ImageOperationOptions instance.
+// As imgpkg does not expose the transfer progress, the progress callback is only invoked
+// once the copies of images to and from tar files complete.
+// The imgpkg commands create their transports from the default transport of the process,
+// which is left untouched, so the proxy of the connection options is not used by this
+// implementation, only the proxy environment variables are.
+// As the imgpkg commands cannot be canceled, an operation is not retried once it times out.
+func NewImgpkgImageOperations(opts ...ImageOperationsOption) ImageOperationsImpl {
+	c := newImageOperationsConfig(opts)
+	return &ImageOperationOptions{progress: c.progress, connection: c.connection, retry: c.retry}
 }
 
 // CopyImageToTar downloads the image as tar file
@@ -28,11 +62,20 @@ func (i *ImageOperationOptions) CopyImageToTar(sourceImageName, destTarFile stri
 	if err != nil {
 		return err
 	}
-	reg, err := newRegistry(registryName)
+	reg, err := newRegistry(registryName, i.connection)
 	if err != nil {
 		return errors.Wrapf(err, "unable to initialize registry")
 	}
-	return reg.CopyImageToTar(sourceImageName, destTarFile)
+
+	// The images pinned to a digest are copied by digest, along with their tag if any
+	err = runUncancelableWithRetries(i.retry, "copying image to tar", func() error {
+		return reg.CopyImageToTar(sourceImageName, destTarFile)
+	})
+	if err != nil {
+		return err
+	}
+	reportTarTransferred(i.progress, sourceImageName, destTarFile)
+	return nil
 }
 
 // CopyImageFromTar publishes the image to destination repository from specified tar file
@@ -42,11 +85,18 @@ func (i *ImageOperationOptions) CopyImageFromTar(sourceTarFile, destImageRepo st
 	if err != nil {
 		return err
 	}
-	reg, err := newRegistry(registryName)
+	reg, err := newRegistry(registryName, i.connection)
 	if err != nil {
 		return errors.Wrapf(err, "unable to initialize registry")
 	}
-	return reg.CopyImageFromTar(sourceTarFile, destImageRepo)
+	err = runUncancelableWithRetries(i.retry, "copying image from tar", func() error {
+		return reg.CopyImageFromTar(sourceTarFile, destImageRepo)
+	})
+	if err != nil {
+		return err
+	}
+	reportTarTransferred(i.progress, destImageRepo, sourceTarFile)
+	return nil
 }
 
 // DownloadImageAndSaveFilesToDir reads a plain OCI image and saves its
@@ -56,11 +106,13 @@ func (i *ImageOperationOptions) DownloadImageAndSaveFilesToDir(imageWithTag, des
 	if err != nil {
 		return err
 	}
-	reg, err := newRegistry(registryName)
+	reg, err := newRegistry(registryName, i.connection)
 	if err != nil {
 		return errors.Wrapf(err, "unable to initialize registry")
 	}
-	err = reg.DownloadImage(imageWithTag, destinationDir)
+	err = runUncancelableWithRetries(i.retry, "downloading image", func() error {
+		return reg.DownloadImage(imageWithTag, destinationDir)
+	})
 	if err != nil {
 		return errors.Wrap(err, "error downloading image")
 	}
@@ -75,11 +127,13 @@ func (i *ImageOperationOptions) GetFilesMapFromImage(imageWithTag string) (map[s
 	if err != nil {
 		return nil, err
 	}
-	reg, err := newRegistry(registryName)
+	reg, err := newRegistry(registryName, i.connection)
 	if err != nil {
 		return nil, errors.Wrapf(err, "unable to initialize registry")
 	}
-	return reg.GetFiles(imageWithTag)
+	return runUncancelableWithRetriesAndResult(i.retry, "fetching image files", func() (map[string][]byte, error) {
+		return reg.GetFiles(imageWithTag)
+	})
 }
 
 // GetImageDigest gets digest of the image
@@ -88,17 +142,20 @@ func (i *ImageOperationOptions) GetImageDigest(imageWithTag string) (string, str
 	if err != nil {
 		return "", "", err
 	}
-	reg, err := newRegistry(registryName)
+	reg, err := newRegistry(registryName, i.connection)
 	if err != nil {
 		return "", "", errors.Wrapf(err, "unable to initialize registry")
 	}
 
-	hashAlgorithm, hashHexVal, err := reg.GetImageDigest(imageWithTag)
+	digest, err := runUncancelableWithRetriesAndResult(i.retry, "getting image digest", func() ([2]string, error) {
+		hashAlgorithm, hashHexVal, err := reg.GetImageDigest(imageWithTag)
+		return [2]string{hashAlgorithm, hashHexVal}, err
+	})
 	if err != nil {
 		return "", "", errors.Wrap(err, "error getting the image digest")
 	}
 
-	return hashAlgorithm, hashHexVal, nil
+	return digest[0], digest[1], nil
 }
 
 // PushImage publishes the image to the specified location
@@ -107,11 +164,13 @@ func (i *ImageOperationOptions) PushImage(imageWithTag string, filePaths []strin
 	if err != nil {
 		return err
 	}
-	reg, err := newRegistry(registryName)
+	reg, err := newRegistry(registryName, i.connection)
 	if err != nil {
 		return errors.Wrapf(err, "unable to initialize registry")
 	}
-	return reg.PushImage(imageWithTag, filePaths)
+	return runUncancelableWithRetries(i.retry, "pushing image", func() error {
+		return reg.PushImage(imageWithTag, filePaths)
+	})
 }
 
 // ResolveImage invokes `imgpkg tag resolve -i <image>` command
@@ -120,15 +179,22 @@ func (i *ImageOperationOptions) ResolveImage(imageWithTag string) error {
 	if err != nil {
 		return err
 	}
-	reg, err := newRegistry(registryName)
+	reg, err := newRegistry(registryName, i.connection)
 	if err != nil {
 		return errors.Wrapf(err, "unable to initialize registry")
 	}
-	return reg.ResolveImage(imageWithTag)
+	return runUncancelableWithRetries(i.retry, "resolving image", func() error {
+		return reg.ResolveImage(imageWithTag)
+	})
 }
 
 // GetFileDigestFromImage invokes `DownloadImageAndSaveFilesToDir` to fetch the image and returns the digest of the specified file
 func (i *ImageOperationOptions) GetFileDigestFromImage(imageWithTag, fileName string) (string, error) {
+	return getFileDigestFromImage(i, imageWithTag, fileName)
+}
+
+// getFileDigestFromImage fetches the image using the specified implementation and returns the digest of the specified file
+func getFileDigestFromImage(i ImageOperationsImpl, imageWithTag, fileName string) (string, error) {
 	tempDir, err := os.MkdirTemp("", "")
 	if err != nil {
 		return "", errors.Wrap(err, "unable to create temporary directory")
@@ -0,0 +1,404 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package carvelhelpers
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/crane"
+	regname "github.com/google/go-containerregistry/pkg/name"
+	regv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/registry"
+)
+
+// GGCRImageOperations implements the ImageOperationsImpl interface by using the
+// `go-containerregistry` library directly instead of the imgpkg bundle semantics.
+// It can be used with registries which do not handle imgpkg bundles properly.
+// Note: The tar files created by CopyImageToTar use the docker tarball format and
+// can only be published with the CopyImageFromTar function of this implementation.
+type GGCRImageOperations struct {
+	progress   ProgressFunc
+	connection *registry.ConnectionOptions
+	retry      *RetryPolicy
+}
+
+// NewGGCRImageOperations creates a new GGCRImageOperations instance
+func NewGGCRImageOperations(opts ...ImageOperationsOption) ImageOperationsImpl {
+	c := newImageOperationsConfig(opts)
+	return &GGCRImageOperations{progress: c.progress, connection: c.connection, retry: c.retry}
+}
+
+// CopyImageToTar downloads the image as tar file.
+// The tag of the images pinned to a digest is preserved in the tar file.
+func (g *GGCRImageOperations) CopyImageToTar(sourceImageName, destTarFile string) error {
+	ref, opts, err := g.parseReference(sourceImageName)
+	if err != nil {
+		return err
+	}
+	tarRef := ref
+	if tagged, digest := splitImageDigest(sourceImageName); digest != "" && hasTag(tagged) {
+		if tarRef, err = regname.NewTag(tagged, regname.WeakValidation); err != nil {
+			return errors.Wrapf(err, "invalid image %q", sourceImageName)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(destTarFile), os.ModePerm); err != nil {
+		return err
+	}
+	err = runWithRetries(g.retry, "copying image to tar", func(ctx context.Context) error {
+		img, err := remote.Image(ref, append(opts, remote.WithContext(ctx))...)
+		if err != nil {
+			return errors.Wrapf(err, "unable to fetch image %q", sourceImageName)
+		}
+		if g.progress == nil {
+			return tarball.WriteToFile(destTarFile, tarRef, img)
+		}
+		layers, err := img.Layers()
+		if err != nil {
+			return err
+		}
+		updates, wait := newProgressTracker(g.progress, sourceImageName).trackUpdates(len(layers))
+		err = tarball.WriteToFile(destTarFile, tarRef, img, tarball.WithProgress(updates))
+		close(updates)
+		wait()
+		return err
+	})
+	return registry.DiagnoseRegistryError(ref.Context().RegistryStr(), err)
+}
+
+// CopyImageFromTar publishes the image to destination repository from specified tar file.
+// The image is tagged with the tag it was saved with, if any.
+func (g *GGCRImageOperations) CopyImageFromTar(sourceTarFile, destImageRepo string) error {
+	manifest, err := tarball.LoadManifest(func() (io.ReadCloser, error) { return os.Open(sourceTarFile) })
+	if err != nil {
+		return errors.Wrapf(err, "unable to read the manifest of %q", sourceTarFile)
+	}
+	img, err := tarball.ImageFromPath(sourceTarFile, nil)
+	if err != nil {
+		return errors.Wrapf(err, "unable to read the image from %q", sourceTarFile)
+	}
+
+	dest := destImageRepo
+	if len(manifest) > 0 && len(manifest[0].RepoTags) > 0 {
+		if tag, err := regname.NewTag(manifest[0].RepoTags[0], regname.WeakValidation); err == nil {
+			dest = destImageRepo + ":" + tag.TagStr()
+		}
+	}
+	if dest == destImageRepo {
+		digest, err := img.Digest()
+		if err != nil {
+			return err
+		}
+		dest = destImageRepo + "@" + digest.String()
+	}
+
+	ref, opts, err := g.parseReference(dest)
+	if err != nil {
+		return err
+	}
+	err = runWithRetries(g.retry, "copying image from tar", func(ctx context.Context) error {
+		return g.writeImage(ctx, destImageRepo, ref, img, opts)
+	})
+	return registry.DiagnoseRegistryError(ref.Context().RegistryStr(), err)
+}
+
+// DownloadImageAndSaveFilesToDir reads a plain OCI image and saves its
+// files to the specified location.
+func (g *GGCRImageOperations) DownloadImageAndSaveFilesToDir(imageWithTag, destinationDir string) error {
+	files, err := g.GetFilesMapFromImage(imageWithTag)
+	if err != nil {
+		return errors.Wrap(err, "error downloading image")
+	}
+	return saveFilesToDir(files, destinationDir, imageWithTag)
+}
+
+// GetFilesMapFromImage returns map of files metadata
+func (g *GGCRImageOperations) GetFilesMapFromImage(imageWithTag string) (map[string][]byte, error) {
+	ref, opts, err := g.parseReference(imageWithTag)
+	if err != nil {
+		return nil, err
+	}
+	files, err := runWithRetriesAndResult(g.retry, "fetching image files", func(ctx context.Context) (map[string][]byte, error) {
+		img, err := remote.Image(ref, append(opts, remote.WithContext(ctx))...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to fetch image %q", imageWithTag)
+		}
+		layers, err := img.Layers()
+		if err != nil {
+			return nil, err
+		}
+		layers, err = newProgressTracker(g.progress, imageWithTag).trackLayers(layers)
+		if err != nil {
+			return nil, err
+		}
+
+		return readFilesFromLayers(layers, registry.GetDownloadConcurrency())
+	})
+	if err != nil {
+		return nil, registry.DiagnoseRegistryError(ref.Context().RegistryStr(), err)
+	}
+	if len(files) == 0 {
+		return nil, errors.New("cannot find file from the image")
+	}
+	return files, nil
+}
+
+// GetImageDigest gets digest of the image
+func (g *GGCRImageOperations) GetImageDigest(imageWithTag string) (string, string, error) {
+	ref, opts, err := g.parseReference(imageWithTag)
+	if err != nil {
+		return "", "", err
+	}
+	desc, err := runWithRetriesAndResult(g.retry, "getting image digest", func(ctx context.Context) (*regv1.Descriptor, error) {
+		return remote.Head(ref, append(opts, remote.WithContext(ctx))...)
+	})
+	if err != nil {
+		return "", "", errors.Wrap(registry.DiagnoseRegistryError(ref.Context().RegistryStr(), err), "error getting the image digest")
+	}
+	return desc.Digest.Algorithm, desc.Digest.Hex, nil
+}
+
+// PushImage publishes the image to the specified location.
+// Files are added at the root of the image, directories are added with their content.
+func (g *GGCRImageOperations) PushImage(imageWithTag string, filePaths []string) error {
+	files := map[string][]byte{}
+	for _, filePath := range filePaths {
+		if err := addFilesToMap(filePath, files); err != nil {
+			return err
+		}
+	}
+	layer, err := crane.Layer(files)
+	if err != nil {
+		return errors.Wrap(err, "unable to create the image layer")
+	}
+	img, err := mutate.AppendLayers(empty.Image, layer)
+	if err != nil {
+		return err
+	}
+
+	ref, opts, err := g.parseReference(imageWithTag)
+	if err != nil {
+		return err
+	}
+	err = runWithRetries(g.retry, "pushing image", func(ctx context.Context) error {
+		return g.writeImage(ctx, imageWithTag, ref, img, opts)
+	})
+	return registry.DiagnoseRegistryError(ref.Context().RegistryStr(), err)
+}
+
+// writeImage publishes the image to the registry, reporting the upload progress
+// of the image named as requested by the caller if configured
+func (g *GGCRImageOperations) writeImage(ctx context.Context, image string, ref regname.Reference, img regv1.Image, opts []remote.Option) error {
+	opts = append(opts, remote.WithContext(ctx))
+	if g.progress == nil {
+		return remote.Write(ref, img, opts...)
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return err
+	}
+	updates, wait := newProgressTracker(g.progress, image).trackUpdates(len(layers))
+	err = remote.Write(ref, img, append(opts, remote.WithProgress(updates))...)
+	wait()
+	return err
+}
+
+// ResolveImage verifies the image exists in the registry
+func (g *GGCRImageOperations) ResolveImage(imageWithTag string) error {
+	ref, opts, err := g.parseReference(imageWithTag)
+	if err != nil {
+		return err
+	}
+	err = runWithRetries(g.retry, "resolving image", func(ctx context.Context) error {
+		_, err := remote.Head(ref, append(opts, remote.WithContext(ctx))...)
+		return err
+	})
+	return registry.DiagnoseRegistryError(ref.Context().RegistryStr(), err)
+}
+
+// GetFileDigestFromImage invokes `DownloadImageAndSaveFilesToDir` to fetch the image and returns the digest of the specified file
+func (g *GGCRImageOperations) GetFileDigestFromImage(imageWithTag, fileName string) (string, error) {
+	return getFileDigestFromImage(g, imageWithTag, fileName)
+}
+
+// parseReference parses the image reference and returns the remote options to use
+// to access its registry, taking the certificate configuration of the registry and
+// the connection options into account.
+// The requests go through the compatibility transport handling the quirks of registries
+// like JFrog Artifactory and Sonatype Nexus. An error is returned if the registry is not allowed.
+func (g *GGCRImageOperations) parseReference(image string) (regname.Reference, []remote.Option, error) {
+	certOptions, err := registry.GetRegistryCertOptionsForImage(image)
+	if err != nil {
+		return nil, nil, err
+	}
+	g.connection.ApplyToCertOptions(certOptions)
+
+	nameOpts := []regname.Option{regname.WeakValidation}
+	if certOptions.Insecure {
+		nameOpts = append(nameOpts, regname.Insecure)
+	}
+	ref, err := regname.ParseReference(image, nameOpts...)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "invalid image %q", image)
+	}
+	if err := registry.CheckRegistryAllowed(ref.Context().RegistryStr()); err != nil {
+		return nil, nil, err
+	}
+
+	transport, err := registry.NewHTTPTransport(certOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+	g.connection.ApplyToTransport(transport)
+	return ref, []remote.Option{
+		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+		remote.WithTransport(registry.NewCompatibilityTransport(transport)),
+	}, nil
+}
+
+// GetImageLayerDigests returns the digests of the layers of the image.
+// Only the manifest of the image is downloaded, which allows to know if the content
+// of an image is already available locally before downloading its layers.
+func GetImageLayerDigests(image string) ([]string, error) {
+	ref, opts, err := (&GGCRImageOperations{}).parseReference(image)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := runWithRetriesAndResult(nil, "getting image manifest", func(ctx context.Context) (*regv1.Manifest, error) {
+		img, err := remote.Image(ref, append(opts, remote.WithContext(ctx))...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to fetch image %q", image)
+		}
+		return img.Manifest()
+	})
+	if err != nil {
+		return nil, errors.Wrap(registry.DiagnoseRegistryError(ref.Context().RegistryStr(), err), "error getting the image manifest")
+	}
+	digests := make([]string, 0, len(manifest.Layers))
+	for _, layer := range manifest.Layers {
+		digests = append(digests, layer.Digest.String())
+	}
+	return digests, nil
+}
+
+// GetFileDigestFromImageTar returns the SHA256 digest of the specified file of the image saved in the tar file
+func GetFileDigestFromImageTar(sourceTarFile, fileName string) (string, error) {
+	img, err := tarball.ImageFromPath(sourceTarFile, nil)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to read the image from %q", sourceTarFile)
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to read the layers of the image from %q", sourceTarFile)
+	}
+	files, err := readFilesFromLayers(layers, 1)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to read the files of the image from %q", sourceTarFile)
+	}
+	for name, content := range files {
+		if filepath.Clean(name) == filepath.Clean(fileName) {
+			return fmt.Sprintf("%x", sha256.Sum256(content)), nil
+		}
+	}
+	return "", errors.Errorf("unable to find file %q in the image from %q", fileName, sourceTarFile)
+}
+
+// readFilesFromLayers returns the regular files of the layers. Up to `concurrency` layers
+// are downloaded concurrently. The files of the upper layers take precedence.
+func readFilesFromLayers(layers []regv1.Layer, concurrency int) (map[string][]byte, error) {
+	layerFiles := make([]map[string][]byte, len(layers))
+	var eg errgroup.Group
+	eg.SetLimit(concurrency)
+	for i := range layers {
+		i := i
+		eg.Go(func() error {
+			layerFiles[i] = map[string][]byte{}
+			return readFilesFromLayer(layers[i], layerFiles[i])
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{}
+	for _, lf := range layerFiles {
+		for name, content := range lf {
+			files[name] = content
+		}
+	}
+	return files, nil
+}
+
+// readFilesFromLayer adds the regular files of the layer to the files map
+func readFilesFromLayer(layer regv1.Layer, files map[string][]byte) error {
+	layerStream, err := layer.Uncompressed()
+	if err != nil {
+		return err
+	}
+	defer layerStream.Close()
+
+	tarReader := tar.NewReader(layerStream)
+	for {
+		hdr, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		buf, err := io.ReadAll(tarReader)
+		if err != nil {
+			return err
+		}
+		files[hdr.Name] = buf
+	}
+}
+
+// addFilesToMap adds the file, or all the files of the directory, to the files map
+func addFilesToMap(path string, files map[string][]byte) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.Base(path)] = content
+		return nil
+	}
+	return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		relPath, err := filepath.Rel(path, p)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(relPath)] = content
+		return nil
+	})
+}
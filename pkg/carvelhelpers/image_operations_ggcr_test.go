@@ -0,0 +1,103 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package carvelhelpers
+
+import (
+	"crypto/sha256"
+	"encoding/pem"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	regname "github.com/google/go-containerregistry/pkg/name"
+	gocontainerregistry "github.com/google/go-containerregistry/pkg/registry"
+	regv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/registry"
+)
+
+func Test_ReadFilesFromLayers(t *testing.T) {
+	assert := assert.New(t)
+
+	var layers []regv1.Layer
+	for _, files := range []map[string][]byte{
+		{"a": []byte("lower"), "b": []byte("lower")},
+		{"b": []byte("middle"), "c": []byte("middle")},
+		{"c": []byte("upper")},
+	} {
+		layer, err := crane.Layer(files)
+		assert.Nil(err)
+		layers = append(layers, layer)
+	}
+
+	// The files of the upper layers take precedence whatever the concurrency
+	for _, concurrency := range []int{1, 2, 8} {
+		files, err := readFilesFromLayers(layers, concurrency)
+		assert.Nil(err)
+		assert.Equal(map[string][]byte{
+			"a": []byte("lower"),
+			"b": []byte("middle"),
+			"c": []byte("upper"),
+		}, files)
+	}
+}
+
+func Test_GetFileDigestFromImageTar(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := os.MkdirTemp("", "test-image-tar")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	img, err := crane.Image(map[string][]byte{"tanzu-foo-linux_amd64": []byte("binary")})
+	assert.Nil(err)
+	tag, err := regname.NewTag("localhost:5001/test/foo:v1.0.0")
+	assert.Nil(err)
+	tarFile := filepath.Join(dir, "foo.tar.gz")
+	assert.Nil(tarball.WriteToFile(tarFile, tag, img))
+
+	digest, err := GetFileDigestFromImageTar(tarFile, "tanzu-foo-linux_amd64")
+	assert.Nil(err)
+	assert.Equal(fmt.Sprintf("%x", sha256.Sum256([]byte("binary"))), digest)
+
+	_, err = GetFileDigestFromImageTar(tarFile, "tanzu-bar-linux_amd64")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "unable to find file")
+}
+
+func Test_GGCRImageOperationsWithConnectionOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	// A registry using a self-signed certificate
+	server := httptest.NewTLSServer(gocontainerregistry.New())
+	defer server.Close()
+	caCertFile := filepath.Join(t.TempDir(), "ca.crt")
+	assert.Nil(os.WriteFile(caCertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+
+	image := strings.TrimPrefix(server.URL, "https://") + "/test/foo:v1.0.0"
+	img, err := crane.Image(map[string][]byte{"tanzu-foo-linux_amd64": []byte("binary")})
+	assert.Nil(err)
+	assert.Nil(crane.Push(img, image, crane.WithTransport(server.Client().Transport)))
+	digest, err := img.Digest()
+	assert.Nil(err)
+
+	// The certificate of the registry is not trusted without the CA certificate
+	_, _, err = NewGGCRImageOperations().GetImageDigest(image)
+	assert.NotNil(err)
+
+	for _, connection := range []*registry.ConnectionOptions{
+		{CACertPaths: []string{caCertFile}},
+		{SkipCertVerify: true},
+	} {
+		algorithm, hex, err := NewGGCRImageOperations(WithConnectionOptions(connection)).GetImageDigest(image)
+		assert.Nil(err)
+		assert.Equal(digest.String(), algorithm+":"+hex)
+	}
+}
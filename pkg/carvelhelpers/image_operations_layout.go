@@ -0,0 +1,319 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package carvelhelpers
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	regv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/match"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/pkg/errors"
+)
+
+// OCILayoutScheme is the scheme of the references to the images of an OCI image layout directory,
+// e.g. file:///tmp/staging/tanzu-cli/plugins/foo:v1.0.0 for the image tanzu-cli/plugins/foo:v1.0.0
+// of the OCI image layout /tmp/staging
+const OCILayoutScheme = "file://"
+
+// ociLayoutRefNameAnnotation is the annotation of the descriptors of the index of an OCI image
+// layout naming their image, as read by the tools copying the images of an OCI image layout
+const ociLayoutRefNameAnnotation = "org.opencontainers.image.ref.name"
+
+// GetOCILayoutDir returns the absolute path of the OCI image layout directory the destination refers
+// to, either as a file:// URL or as a local path, and false if the destination is a repository
+func GetOCILayoutDir(destination string) (string, bool) {
+	dir := destination
+	switch {
+	case strings.HasPrefix(destination, OCILayoutScheme):
+		dir = strings.TrimPrefix(destination, OCILayoutScheme)
+	case filepath.IsAbs(destination), destination == ".", strings.HasPrefix(destination, "./"), strings.HasPrefix(destination, "../"):
+	default:
+		return "", false
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return filepath.Clean(dir), true
+	}
+	return absDir, true
+}
+
+// OCILayoutImageOperations implements the ImageOperationsImpl interface on the images of an OCI image
+// layout directory instead of the images of a registry, so that the images can be staged on disk and
+// later copied to a registry with other tools. The images are referred to as file://<dir>/<name> and
+// are named in the layout by their name and tag, or by their name and digest when they have no tag.
+type OCILayoutImageOperations struct {
+	dir string
+	// mu serializes the updates of the index of the layout, which are not safe for concurrent use
+	mu sync.Mutex
+}
+
+// NewOCILayoutImageOperations creates a new OCILayoutImageOperations instance for the OCI image layout
+// directory, which is created when an image is written to it if it does not exist yet
+func NewOCILayoutImageOperations(dir string) ImageOperationsImpl {
+	return &OCILayoutImageOperations{dir: dir}
+}
+
+// CopyImageToTar is not supported, the images of an OCI image layout are copied with other tools
+func (l *OCILayoutImageOperations) CopyImageToTar(sourceImageName, _ string) error {
+	return errors.Errorf("unable to copy image %q, copying an image of an OCI image layout to a tar file is not supported", sourceImageName)
+}
+
+// CopyImageFromTar writes the images saved in the tar file to the OCI image layout, named after
+// the destination repository and the tags they were saved with
+func (l *OCILayoutImageOperations) CopyImageFromTar(sourceTarFile, destImageRepo string) error {
+	name, _, _, err := l.parseReference(destImageRepo)
+	if err != nil {
+		return err
+	}
+	images, indexes, err := readImagesFromTar(sourceTarFile)
+	if err != nil {
+		return err
+	}
+	tags, err := readTarballTags(sourceTarFile)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	p, err := l.layoutPath()
+	if err != nil {
+		return err
+	}
+	for _, img := range images {
+		refName, err := layoutRefName(name, img, tags)
+		if err != nil {
+			return err
+		}
+		if err := p.ReplaceImage(img, refNameMatcher(refName), layoutRefNameOption(refName)); err != nil {
+			return errors.Wrapf(err, "unable to write image %q to %q", refName, l.dir)
+		}
+	}
+	for _, idx := range indexes {
+		refName, err := layoutRefName(name, idx, nil)
+		if err != nil {
+			return err
+		}
+		if err := p.ReplaceIndex(idx, refNameMatcher(refName), layoutRefNameOption(refName)); err != nil {
+			return errors.Wrapf(err, "unable to write image index %q to %q", refName, l.dir)
+		}
+	}
+	return nil
+}
+
+// DownloadImageAndSaveFilesToDir reads a plain OCI image of the OCI image layout
+// and saves its files to the specified location.
+func (l *OCILayoutImageOperations) DownloadImageAndSaveFilesToDir(imageWithTag, destinationDir string) error {
+	files, err := l.GetFilesMapFromImage(imageWithTag)
+	if err != nil {
+		return errors.Wrap(err, "error reading image")
+	}
+	return saveFilesToDir(files, destinationDir, imageWithTag)
+}
+
+// GetFilesMapFromImage returns map of files metadata
+func (l *OCILayoutImageOperations) GetFilesMapFromImage(imageWithTag string) (map[string][]byte, error) {
+	desc, p, err := l.findImage(imageWithTag)
+	if err != nil {
+		return nil, err
+	}
+	img, err := p.Image(desc.Digest)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read image %q", imageWithTag)
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+	files, err := readFilesFromLayers(layers, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, errors.New("cannot find file from the image")
+	}
+	return files, nil
+}
+
+// GetImageDigest gets digest of the image
+func (l *OCILayoutImageOperations) GetImageDigest(imageWithTag string) (string, string, error) {
+	desc, _, err := l.findImage(imageWithTag)
+	if err != nil {
+		return "", "", errors.Wrap(err, "error getting the image digest")
+	}
+	return desc.Digest.Algorithm, desc.Digest.Hex, nil
+}
+
+// PushImage writes the image to the OCI image layout.
+// Files are added at the root of the image, directories are added with their content.
+func (l *OCILayoutImageOperations) PushImage(imageWithTag string, filePaths []string) error {
+	name, tag, _, err := l.parseReference(imageWithTag)
+	if err != nil {
+		return err
+	}
+	if tag == "" {
+		return errors.Errorf("image %q must be tagged to be written to an OCI image layout", imageWithTag)
+	}
+	files := map[string][]byte{}
+	for _, filePath := range filePaths {
+		if err := addFilesToMap(filePath, files); err != nil {
+			return err
+		}
+	}
+	layer, err := crane.Layer(files)
+	if err != nil {
+		return errors.Wrap(err, "unable to create the image layer")
+	}
+	img, err := mutate.AppendLayers(empty.Image, layer)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	p, err := l.layoutPath()
+	if err != nil {
+		return err
+	}
+	refName := name + ":" + tag
+	if err := p.ReplaceImage(img, refNameMatcher(refName), layoutRefNameOption(refName)); err != nil {
+		return errors.Wrapf(err, "unable to write image %q to %q", refName, l.dir)
+	}
+	return nil
+}
+
+// ResolveImage verifies the image exists in the OCI image layout
+func (l *OCILayoutImageOperations) ResolveImage(imageWithTag string) error {
+	_, _, err := l.findImage(imageWithTag)
+	return err
+}
+
+// GetFileDigestFromImage invokes `DownloadImageAndSaveFilesToDir` to read the image and returns the digest of the specified file
+func (l *OCILayoutImageOperations) GetFileDigestFromImage(imageWithTag, fileName string) (string, error) {
+	return getFileDigestFromImage(l, imageWithTag, fileName)
+}
+
+// parseReference returns the name, tag and digest of the image of the OCI image layout,
+// referred to as file://<dir>/<name>[:<tag>][@<digest>]
+func (l *OCILayoutImageOperations) parseReference(image string) (name, tag, digest string, err error) {
+	prefix := OCILayoutScheme + filepath.ToSlash(l.dir) + "/"
+	if !strings.HasPrefix(image, prefix) {
+		return "", "", "", errors.Errorf("image %q is not an image of the OCI image layout %q", image, l.dir)
+	}
+	name, digest = splitImageDigest(strings.TrimPrefix(image, prefix))
+	if hasTag(name) {
+		i := strings.LastIndex(name, ":")
+		name, tag = name[:i], name[i+1:]
+	}
+	if name == "" {
+		return "", "", "", errors.Errorf("invalid image %q", image)
+	}
+	return name, tag, digest, nil
+}
+
+// findImage returns the descriptor of the image in the index of the OCI image layout, matched by tag
+// when the image is tagged, and by digest otherwise. When both are specified, the tagged image must
+// have the digest.
+func (l *OCILayoutImageOperations) findImage(image string) (*regv1.Descriptor, layout.Path, error) {
+	name, tag, digest, err := l.parseReference(image)
+	if err != nil {
+		return nil, "", err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	p, err := layout.FromPath(l.dir)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "unable to read the OCI image layout %q", l.dir)
+	}
+	index, err := p.ImageIndex()
+	if err != nil {
+		return nil, "", err
+	}
+	indexManifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, "", err
+	}
+	for i := range indexManifest.Manifests {
+		desc := &indexManifest.Manifests[i]
+		refName := desc.Annotations[ociLayoutRefNameAnnotation]
+		switch {
+		case tag != "" && refName != name+":"+tag:
+		case tag == "" && refName != name+"@"+digest:
+		case digest != "" && desc.Digest.String() != digest:
+		default:
+			return desc, p, nil
+		}
+	}
+	return nil, "", errors.Errorf("image %q not found in the OCI image layout %q", image, l.dir)
+}
+
+// layoutPath returns the OCI image layout, created if it does not exist yet
+func (l *OCILayoutImageOperations) layoutPath() (layout.Path, error) {
+	if p, err := layout.FromPath(l.dir); err == nil {
+		return p, nil
+	}
+	if err := os.MkdirAll(l.dir, os.ModePerm); err != nil {
+		return "", err
+	}
+	p, err := layout.Write(l.dir, empty.Index)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to create the OCI image layout %q", l.dir)
+	}
+	return p, nil
+}
+
+// readTarballTags returns the tags of the image saved in the tar file by go-containerregistry by digest,
+// none for the tar files saved by imgpkg whose images record their own tag
+func readTarballTags(sourceTarFile string) (map[string]string, error) {
+	manifest, err := tarball.LoadManifest(func() (io.ReadCloser, error) { return os.Open(sourceTarFile) })
+	if err != nil || len(manifest) == 0 || len(manifest[0].RepoTags) == 0 {
+		return nil, nil //nolint:nilerr
+	}
+	img, err := tarball.ImageFromPath(sourceTarFile, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read the image from %q", sourceTarFile)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		return nil, err
+	}
+	repoTag := manifest[0].RepoTags[0]
+	return map[string]string{digest.String(): repoTag[strings.LastIndex(repoTag, ":")+1:]}, nil
+}
+
+// layoutRefName returns the name of the image or image index in the OCI image layout, name:tag when
+// it has a tag, name@digest otherwise
+func layoutRefName(name string, image interface{ Digest() (regv1.Hash, error) }, tags map[string]string) (string, error) {
+	digest, err := image.Digest()
+	if err != nil {
+		return "", err
+	}
+	tag := tags[digest.String()]
+	if tagged, ok := image.(interface{ Tag() string }); ok && tag == "" {
+		tag = tagged.Tag()
+	}
+	if tag != "" {
+		return name + ":" + tag, nil
+	}
+	return name + "@" + digest.String(), nil
+}
+
+// refNameMatcher matches the descriptors of the index of an OCI image layout named refName
+func refNameMatcher(refName string) match.Matcher {
+	return match.Annotation(ociLayoutRefNameAnnotation, refName)
+}
+
+// layoutRefNameOption names the descriptor written to the index of an OCI image layout refName
+func layoutRefNameOption(refName string) layout.Option {
+	return layout.WithAnnotations(map[string]string{ociLayoutRefNameAnnotation: refName})
+}
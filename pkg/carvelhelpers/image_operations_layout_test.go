@@ -0,0 +1,108 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package carvelhelpers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	regname "github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetOCILayoutDir(t *testing.T) {
+	assert := assert.New(t)
+
+	wd, err := os.Getwd()
+	assert.Nil(err)
+
+	tests := []struct {
+		destination string
+		dir         string
+		isLayout    bool
+	}{
+		{destination: "file:///tmp/staging", dir: "/tmp/staging", isLayout: true},
+		{destination: "/tmp/staging/", dir: "/tmp/staging", isLayout: true},
+		{destination: "./staging", dir: filepath.Join(wd, "staging"), isLayout: true},
+		{destination: "../staging", dir: filepath.Join(filepath.Dir(wd), "staging"), isLayout: true},
+		{destination: "localhost:5001/tanzu-cli/plugins"},
+		{destination: "registry.example.com/staging"},
+	}
+	for _, test := range tests {
+		dir, isLayout := GetOCILayoutDir(test.destination)
+		assert.Equal(test.isLayout, isLayout, test.destination)
+		assert.Equal(test.dir, dir, test.destination)
+	}
+}
+
+func Test_OCILayoutImageOperations(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	layoutDir := filepath.Join(dir, "staging")
+	ops := NewOCILayoutImageOperations(layoutDir)
+	repo := OCILayoutScheme + filepath.ToSlash(layoutDir) + "/test/foo"
+
+	// The images are not found until the OCI image layout is created
+	_, _, err := ops.GetImageDigest(repo + ":v1.0.0")
+	assert.NotNil(err)
+
+	img, err := crane.Image(map[string][]byte{"tanzu-foo-linux_amd64": []byte("binary")})
+	assert.Nil(err)
+	digest, err := img.Digest()
+	assert.Nil(err)
+	tag, err := regname.NewTag("localhost:5001/test/foo:v1.0.0")
+	assert.Nil(err)
+	tarFile := filepath.Join(dir, "foo.tar")
+	assert.Nil(tarball.WriteToFile(tarFile, tag, img))
+
+	// The image is named after the destination repository and the tag it was saved with
+	assert.Nil(ops.CopyImageFromTar(tarFile, repo))
+	algorithm, hex, err := ops.GetImageDigest(repo + ":v1.0.0")
+	assert.Nil(err)
+	assert.Equal(digest.String(), algorithm+":"+hex)
+	assert.Nil(ops.ResolveImage(repo + ":v1.0.0@" + digest.String()))
+	assert.NotNil(ops.ResolveImage(repo + ":v1.0.0@sha256:0000000000000000000000000000000000000000000000000000000000000000"))
+	assert.NotNil(ops.ResolveImage(repo + ":v2.0.0"))
+	files, err := ops.GetFilesMapFromImage(repo + ":v1.0.0")
+	assert.Nil(err)
+	assert.Equal(map[string][]byte{"tanzu-foo-linux_amd64": []byte("binary")}, files)
+
+	// Copying the image again does not duplicate it in the index of the layout
+	assert.Nil(ops.CopyImageFromTar(tarFile, repo))
+	p, err := layout.FromPath(layoutDir)
+	assert.Nil(err)
+	index, err := p.ImageIndex()
+	assert.Nil(err)
+	indexManifest, err := index.IndexManifest()
+	assert.Nil(err)
+	assert.Len(indexManifest.Manifests, 1)
+	assert.Equal("test/foo:v1.0.0", indexManifest.Manifests[0].Annotations[ociLayoutRefNameAnnotation])
+
+	// A pushed image replaces the image with the same tag
+	dbFile := filepath.Join(dir, "plugin_inventory.db")
+	assert.Nil(os.WriteFile(dbFile, []byte("database"), 0o600))
+	metadataImage := OCILayoutScheme + filepath.ToSlash(layoutDir) + "/test/metadata:latest"
+	assert.Nil(ops.PushImage(metadataImage, []string{dbFile}))
+	assert.Nil(ops.PushImage(metadataImage, []string{dbFile}))
+	destDir := filepath.Join(dir, "metadata")
+	assert.Nil(ops.DownloadImageAndSaveFilesToDir(metadataImage, destDir))
+	content, err := os.ReadFile(filepath.Join(destDir, "plugin_inventory.db"))
+	assert.Nil(err)
+	assert.Equal("database", string(content))
+	index, err = p.ImageIndex()
+	assert.Nil(err)
+	indexManifest, err = index.IndexManifest()
+	assert.Nil(err)
+	assert.Len(indexManifest.Manifests, 2)
+
+	// The images must be referred to within the OCI image layout
+	err = ops.PushImage("localhost:5001/test/metadata:latest", []string{dbFile})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "is not an image of the OCI image layout")
+}
@@ -0,0 +1,202 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package carvelhelpers_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/registry"
+)
+
+var _ = Describe("Unit tests for NewImageOperationsImpl", func() {
+	AfterEach(func() {
+		os.Unsetenv(constants.ConfigVariableImageOperationsImplementation)
+	})
+
+	It("should return the imgpkg implementation by default", func() {
+		Expect(NewImageOperationsImpl()).To(BeAssignableToTypeOf(&ImageOperationOptions{}))
+	})
+	It("should return the go-containerregistry implementation when configured", func() {
+		os.Setenv(constants.ConfigVariableImageOperationsImplementation, ImageOperationsImplementationGGCR)
+		Expect(NewImageOperationsImpl()).To(BeAssignableToTypeOf(&GGCRImageOperations{}))
+	})
+	It("should return the imgpkg implementation for unknown values", func() {
+		os.Setenv(constants.ConfigVariableImageOperationsImplementation, "unknown")
+		Expect(NewImageOperationsImpl()).To(BeAssignableToTypeOf(&ImageOperationOptions{}))
+	})
+	It("should return the go-containerregistry implementation when a proxy is set without changing the default transport", func() {
+		connection := &registry.ConnectionOptions{HTTPSProxy: "http://proxy.example.com:3128"}
+		Expect(NewImageOperationsImpl(WithConnectionOptions(connection))).To(BeAssignableToTypeOf(&GGCRImageOperations{}))
+		Expect(NewImageOperationsImpl(WithConnectionOptions(&registry.ConnectionOptions{SkipCertVerify: true}))).To(BeAssignableToTypeOf(&ImageOperationOptions{}))
+
+		NewImgpkgImageOperations(WithConnectionOptions(connection))
+		req, err := http.NewRequest(http.MethodGet, "https://registry.example.com/v2/", http.NoBody)
+		Expect(err).NotTo(HaveOccurred())
+		proxy, err := http.DefaultTransport.(*http.Transport).Proxy(req)
+		Expect(err).NotTo(HaveOccurred())
+		if proxy != nil {
+			Expect(proxy.Host).NotTo(Equal("proxy.example.com:3128"))
+		}
+	})
+})
+
+var _ = Describe("Unit tests for the go-containerregistry image operations", func() {
+	var (
+		tmpDir       string
+		registryHost string
+		stopRegistry func()
+		imageOps     ImageOperationsImpl
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "ggcr-image-operations")
+		Expect(err).NotTo(HaveOccurred())
+
+		os.Setenv("TANZU_CONFIG", filepath.Join(tmpDir, "config.yaml"))
+		os.Setenv("TANZU_CONFIG_NEXT_GEN", filepath.Join(tmpDir, "config-ng.yaml"))
+
+		var port string
+		port, stopRegistry, err = registry.ServeLocalRegistry("")
+		Expect(err).NotTo(HaveOccurred())
+		registryHost = "localhost:" + port
+
+		imageOps = NewGGCRImageOperations()
+	})
+	AfterEach(func() {
+		stopRegistry()
+		os.Unsetenv("TANZU_CONFIG")
+		os.Unsetenv("TANZU_CONFIG_NEXT_GEN")
+		os.RemoveAll(tmpDir)
+	})
+
+	It("should push, resolve, pull and copy images", func() {
+		filePath := filepath.Join(tmpDir, "plugin.db")
+		Expect(os.WriteFile(filePath, []byte("content"), 0644)).To(Succeed())
+
+		image := registryHost + "/test/plugin-inventory:latest"
+		Expect(imageOps.PushImage(image, []string{filePath})).To(Succeed())
+		Expect(imageOps.ResolveImage(image)).To(Succeed())
+		Expect(imageOps.ResolveImage(registryHost + "/test/missing:latest")).NotTo(Succeed())
+
+		algorithm, hex, err := imageOps.GetImageDigest(image)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(algorithm).To(Equal("sha256"))
+		Expect(hex).NotTo(BeEmpty())
+
+		files, err := imageOps.GetFilesMapFromImage(image)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(HaveKeyWithValue("plugin.db", []byte("content")))
+
+		downloadDir := filepath.Join(tmpDir, "download")
+		Expect(imageOps.DownloadImageAndSaveFilesToDir(image, downloadDir)).To(Succeed())
+		Expect(filepath.Join(downloadDir, "plugin.db")).To(BeAnExistingFile())
+
+		tarFile := filepath.Join(tmpDir, "image.tar")
+		Expect(imageOps.CopyImageToTar(image, tarFile)).To(Succeed())
+		Expect(imageOps.CopyImageFromTar(tarFile, registryHost+"/copy/plugin-inventory")).To(Succeed())
+
+		_, copiedHex, err := imageOps.GetImageDigest(registryHost + "/copy/plugin-inventory:latest")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(copiedHex).To(Equal(hex))
+	})
+
+	It("should report the progress of the transfers", func() {
+		filePath := filepath.Join(tmpDir, "plugin.db")
+		Expect(os.WriteFile(filePath, []byte("content"), 0644)).To(Succeed())
+
+		var progress Progress
+		imageOps = NewGGCRImageOperations(WithProgressCallback(func(p Progress) { progress = p }))
+
+		image := registryHost + "/test/plugin-inventory:latest"
+		Expect(imageOps.PushImage(image, []string{filePath})).To(Succeed())
+		Expect(progress.Image).To(Equal(image))
+		Expect(progress.TotalBytes).To(BeNumerically(">", 0))
+		Expect(progress.BytesTransferred).To(Equal(progress.TotalBytes))
+		Expect(progress.LayersCompleted).To(Equal(1))
+
+		progress = Progress{}
+		_, err := imageOps.GetFilesMapFromImage(image)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(progress.Image).To(Equal(image))
+		Expect(progress.TotalLayers).To(Equal(1))
+		Expect(progress.LayersCompleted).To(Equal(1))
+		Expect(progress.TotalBytes).To(BeNumerically(">", 0))
+		Expect(progress.BytesTransferred).To(Equal(progress.TotalBytes))
+
+		progress = Progress{}
+		tarFile := filepath.Join(tmpDir, "image.tar")
+		Expect(imageOps.CopyImageToTar(image, tarFile)).To(Succeed())
+		Expect(progress.Image).To(Equal(image))
+		Expect(progress.BytesTransferred).To(Equal(progress.TotalBytes))
+
+		progress = Progress{}
+		Expect(imageOps.CopyImageFromTar(tarFile, registryHost+"/copy/plugin-inventory")).To(Succeed())
+		Expect(progress.Image).To(Equal(registryHost + "/copy/plugin-inventory"))
+		Expect(progress.BytesTransferred).To(Equal(progress.TotalBytes))
+	})
+	It("should report the copies of the imgpkg implementation once completed", func() {
+		filePath := filepath.Join(tmpDir, "plugin.db")
+		Expect(os.WriteFile(filePath, []byte("content"), 0644)).To(Succeed())
+		image := registryHost + "/test/plugin-inventory:latest"
+		Expect(imageOps.PushImage(image, []string{filePath})).To(Succeed())
+
+		var progress Progress
+		imageOps = NewImgpkgImageOperations(WithProgressCallback(func(p Progress) { progress = p }))
+
+		tarFile := filepath.Join(tmpDir, "image.tar")
+		Expect(imageOps.CopyImageToTar(image, tarFile)).To(Succeed())
+		info, err := os.Stat(tarFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(progress).To(Equal(Progress{Image: image, BytesTransferred: info.Size(), TotalBytes: info.Size()}))
+
+		progress = Progress{}
+		Expect(imageOps.CopyImageFromTar(tarFile, registryHost+"/copy/plugin-inventory")).To(Succeed())
+		Expect(progress).To(Equal(Progress{Image: registryHost + "/copy/plugin-inventory", BytesTransferred: info.Size(), TotalBytes: info.Size()}))
+	})
+	It("should copy the images pinned to a digest by digest with the imgpkg implementation and keep their tag", func() {
+		filePath := filepath.Join(tmpDir, "plugin.db")
+		Expect(os.WriteFile(filePath, []byte("content"), 0644)).To(Succeed())
+		image := registryHost + "/test/plugin-inventory:latest"
+		Expect(imageOps.PushImage(image, []string{filePath})).To(Succeed())
+		algorithm, hex, err := imageOps.GetImageDigest(image)
+		Expect(err).NotTo(HaveOccurred())
+
+		// The tag is updated once the image is pinned to its digest
+		Expect(os.WriteFile(filePath, []byte("updated content"), 0644)).To(Succeed())
+		Expect(imageOps.PushImage(image, []string{filePath})).To(Succeed())
+
+		imageOps = NewImgpkgImageOperations()
+		tarFile := filepath.Join(tmpDir, "image.tar")
+		Expect(imageOps.CopyImageToTar(ImageWithDigest(image, algorithm, hex), tarFile)).To(Succeed())
+		Expect(imageOps.CopyImageFromTar(tarFile, registryHost+"/copy/plugin-inventory")).To(Succeed())
+
+		_, copiedHex, err := imageOps.GetImageDigest(registryHost + "/copy/plugin-inventory:latest")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(copiedHex).To(Equal(hex))
+	})
+	It("should upload the layers in chunks when a chunk size is configured", func() {
+		os.Setenv(constants.ConfigVariableRegistryUploadChunkSize, "16")
+		defer os.Unsetenv(constants.ConfigVariableRegistryUploadChunkSize)
+
+		content := []byte(strings.Repeat("chunked content ", 64))
+		filePath := filepath.Join(tmpDir, "plugin.db")
+		Expect(os.WriteFile(filePath, content, 0644)).To(Succeed())
+
+		image := registryHost + "/test/plugin-inventory:latest"
+		Expect(imageOps.PushImage(image, []string{filePath})).To(Succeed())
+
+		files, err := imageOps.GetFilesMapFromImage(image)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(HaveKeyWithValue("plugin.db", content))
+	})
+})
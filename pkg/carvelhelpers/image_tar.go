@@ -0,0 +1,219 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package carvelhelpers
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+
+	regv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/pkg/errors"
+	"github.com/vmware-tanzu/carvel-imgpkg/pkg/imgpkg/imagetar"
+)
+
+// The images are saved to tar files either by imgpkg or by go-containerregistry depending on the
+// implementation of the image operations, the tar files of both formats can be read.
+
+// VerifyImageTar checks the images saved in the tar file are complete and not corrupted, i.e.
+// their manifests, configs and layers match their digests. When a digest is specified, the
+// tar file must hold the image, or image index, with this digest.
+func VerifyImageTar(sourceTarFile, digest string) error {
+	images, indexes, err := readImagesFromTar(sourceTarFile)
+	if err != nil {
+		return err
+	}
+	var digests []string
+	for _, img := range images {
+		imgDigest, err := verifyImage(img)
+		if err != nil {
+			return errors.Wrapf(err, "the image from %q is corrupted", sourceTarFile)
+		}
+		digests = append(digests, imgDigest)
+	}
+	for _, idx := range indexes {
+		idxDigests, err := verifyImageIndex(idx)
+		if err != nil {
+			return errors.Wrapf(err, "the image index from %q is corrupted", sourceTarFile)
+		}
+		digests = append(digests, idxDigests...)
+	}
+	if digest == "" {
+		return nil
+	}
+	for _, d := range digests {
+		if d == digest {
+			return nil
+		}
+	}
+	return errors.Errorf("the tar file %q does not hold the image with digest %q", sourceTarFile, digest)
+}
+
+// GetFilesMapFromImageTar returns the files of the image saved in the tar file. When a digest
+// is specified, the files of the image with this digest are returned, otherwise the files of
+// the first image of the tar file.
+func GetFilesMapFromImageTar(sourceTarFile, digest string) (map[string][]byte, error) {
+	images, _, err := readImagesFromTar(sourceTarFile)
+	if err != nil {
+		return nil, err
+	}
+	for _, img := range images {
+		imgDigest, err := img.Digest()
+		if err != nil {
+			return nil, err
+		}
+		if digest != "" && imgDigest.String() != digest {
+			continue
+		}
+		layers, err := img.Layers()
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read the layers of the image from %q", sourceTarFile)
+		}
+		files, err := readFilesFromLayers(layers, 1)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read the files of the image from %q", sourceTarFile)
+		}
+		return files, nil
+	}
+	return nil, errors.Errorf("unable to find the image with digest %q in %q", digest, sourceTarFile)
+}
+
+// readImagesFromTar returns the images and image indexes saved in the tar file by imgpkg, which can
+// also hold the signatures of the images, or the image saved in the tar file by go-containerregistry
+func readImagesFromTar(sourceTarFile string) ([]regv1.Image, []regv1.ImageIndex, error) {
+	if img, err := tarball.ImageFromPath(sourceTarFile, nil); err == nil {
+		if _, err := img.Manifest(); err == nil {
+			return []regv1.Image{img}, nil, nil
+		}
+	}
+
+	contents, err := imagetar.NewTarReader(sourceTarFile).Read()
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "unable to read the images from %q", sourceTarFile)
+	}
+	var images []regv1.Image
+	var indexes []regv1.ImageIndex
+	for _, content := range contents {
+		switch {
+		case content.Image != nil:
+			images = append(images, *content.Image)
+		case content.Index != nil:
+			indexes = append(indexes, *content.Index)
+		}
+	}
+	if len(images) == 0 && len(indexes) == 0 {
+		return nil, nil, errors.Errorf("no image found in %q", sourceTarFile)
+	}
+	return images, indexes, nil
+}
+
+// verifyImage checks the manifest, config and layers of the image match their digests and returns the digest of the image
+func verifyImage(img regv1.Image) (string, error) {
+	digest, err := verifyManifestDigest(img)
+	if err != nil {
+		return "", err
+	}
+	config, err := img.RawConfigFile()
+	if err != nil {
+		return "", errors.Wrap(err, "unable to read the config")
+	}
+	configName, err := img.ConfigName()
+	if err != nil {
+		return "", err
+	}
+	if actual := fmt.Sprintf("sha256:%x", sha256.Sum256(config)); actual != configName.String() {
+		return "", errors.Errorf("the config has digest %q instead of %q", actual, configName)
+	}
+
+	layers, err := img.Layers()
+	if err != nil {
+		return "", errors.Wrap(err, "unable to read the layers")
+	}
+	for _, layer := range layers {
+		if err := verifyLayer(layer); err != nil {
+			return "", err
+		}
+	}
+	return digest, nil
+}
+
+// verifyImageIndex checks the manifest of the image index and its images match their digests
+// and returns the digests of the image index and of its images and nested image indexes
+func verifyImageIndex(idx regv1.ImageIndex) ([]string, error) {
+	digest, err := verifyManifestDigest(idx)
+	if err != nil {
+		return nil, err
+	}
+	indexManifest, err := idx.IndexManifest()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the manifest")
+	}
+	digests := []string{digest}
+	for i := range indexManifest.Manifests {
+		desc := &indexManifest.Manifests[i]
+		if desc.MediaType.IsIndex() {
+			nested, err := idx.ImageIndex(desc.Digest)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to read the image index %q", desc.Digest)
+			}
+			nestedDigests, err := verifyImageIndex(nested)
+			if err != nil {
+				return nil, err
+			}
+			digests = append(digests, nestedDigests...)
+			continue
+		}
+		img, err := idx.Image(desc.Digest)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read the image %q", desc.Digest)
+		}
+		imgDigest, err := verifyImage(img)
+		if err != nil {
+			return nil, err
+		}
+		digests = append(digests, imgDigest)
+	}
+	return digests, nil
+}
+
+// verifyManifestDigest checks the raw manifest matches the digest and returns the digest
+func verifyManifestDigest(m interface {
+	RawManifest() ([]byte, error)
+	Digest() (regv1.Hash, error)
+}) (string, error) {
+	manifest, err := m.RawManifest()
+	if err != nil {
+		return "", errors.Wrap(err, "unable to read the manifest")
+	}
+	digest, err := m.Digest()
+	if err != nil {
+		return "", err
+	}
+	if actual := fmt.Sprintf("sha256:%x", sha256.Sum256(manifest)); actual != digest.String() {
+		return "", errors.Errorf("the manifest has digest %q instead of %q", actual, digest)
+	}
+	return digest.String(), nil
+}
+
+// verifyLayer checks the compressed content of the layer matches its digest
+func verifyLayer(layer regv1.Layer) error {
+	digest, err := layer.Digest()
+	if err != nil {
+		return err
+	}
+	content, err := layer.Compressed()
+	if err != nil {
+		return errors.Wrapf(err, "unable to read the layer %q", digest)
+	}
+	defer content.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return errors.Wrapf(err, "unable to read the layer %q", digest)
+	}
+	if actual := fmt.Sprintf("sha256:%x", hash.Sum(nil)); actual != digest.String() {
+		return errors.Errorf("the layer has digest %q instead of %q", actual, digest)
+	}
+	return nil
+}
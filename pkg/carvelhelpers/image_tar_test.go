@@ -0,0 +1,92 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package carvelhelpers
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	regname "github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_VerifyImageTar(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	img, err := crane.Image(map[string][]byte{"tanzu-foo-linux_amd64": []byte("binary")})
+	assert.Nil(err)
+	digest, err := img.Digest()
+	assert.Nil(err)
+	tag, err := regname.NewTag("localhost:5001/test/foo:v1.0.0")
+	assert.Nil(err)
+	tarFile := filepath.Join(dir, "foo.tar.gz")
+	assert.Nil(tarball.WriteToFile(tarFile, tag, img))
+
+	assert.Nil(VerifyImageTar(tarFile, ""))
+	assert.Nil(VerifyImageTar(tarFile, digest.String()))
+
+	err = VerifyImageTar(tarFile, "sha256:1234")
+	assert.ErrorContains(err, `does not hold the image with digest "sha256:1234"`)
+
+	files, err := GetFilesMapFromImageTar(tarFile, digest.String())
+	assert.Nil(err)
+	assert.Equal(map[string][]byte{"tanzu-foo-linux_amd64": []byte("binary")}, files)
+
+	// The layer of the image is altered
+	otherLayer, err := crane.Layer(map[string][]byte{"tanzu-foo-linux_amd64": []byte("altered")})
+	assert.Nil(err)
+	otherContent, err := otherLayer.Compressed()
+	assert.Nil(err)
+	altered, err := io.ReadAll(otherContent)
+	assert.Nil(err)
+	alteredTarFile := filepath.Join(dir, "altered.tar.gz")
+	rewriteTar(t, tarFile, alteredTarFile, func(name string, content []byte) []byte {
+		if strings.HasSuffix(name, ".tar.gz") {
+			return altered
+		}
+		return content
+	})
+	err = VerifyImageTar(alteredTarFile, digest.String())
+	assert.ErrorContains(err, "does not hold the image with digest")
+
+	// The file is not an image tar
+	notATar := filepath.Join(dir, "not-a-tar.tar.gz")
+	assert.Nil(os.WriteFile(notATar, []byte("not a tar"), 0o600))
+	err = VerifyImageTar(notATar, "")
+	assert.ErrorContains(err, "unable to read the images")
+}
+
+// rewriteTar copies the tar file, replacing the content of its files with the result of the replace function
+func rewriteTar(t *testing.T, src, dst string, replace func(name string, content []byte) []byte) {
+	in, err := os.Open(src)
+	assert.Nil(t, err)
+	defer in.Close()
+	var buf bytes.Buffer
+	reader := tar.NewReader(in)
+	writer := tar.NewWriter(&buf)
+	for {
+		hdr, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(t, err)
+		content, err := io.ReadAll(reader)
+		assert.Nil(t, err)
+		content = replace(hdr.Name, content)
+		hdr.Size = int64(len(content))
+		assert.Nil(t, writer.WriteHeader(hdr))
+		_, err = writer.Write(content)
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, writer.Close())
+	assert.Nil(t, os.WriteFile(dst, buf.Bytes(), 0o600))
+}
@@ -11,16 +11,29 @@ import (
 	"github.com/cppforlife/go-cli-ui/ui"
 	"github.com/k14s/kbld/pkg/kbld/cmd"
 	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/registry"
 )
 
 // ResolveImagesInPackage resolves the images using kbld tool
 // Implements similar functionality as `kbld -f <file1> -f <file2>`
 func ResolveImagesInPackage(files []string) ([]byte, error) {
+	return resolveImagesInPackage(files, &registry.CertOptions{})
+}
+
+// resolveImagesInPackage resolves the images using kbld tool and the
+// specified registry certificate configuration
+func resolveImagesInPackage(files []string, certOptions *registry.CertOptions) ([]byte, error) {
 	var outputBuf, errorBuf bytes.Buffer
 	writerUI := ui.NewWriterUI(&outputBuf, &errorBuf, nil)
 	kbldResolveOptions := cmd.NewResolveOptions(writerUI)
 	kbldResolveOptions.FileFlags = cmd.FileFlags{Files: files}
 	kbldResolveOptions.BuildConcurrency = 1
+	kbldResolveOptions.RegistryFlags = cmd.RegistryFlags{
+		CACertPaths: certOptions.CACertPaths,
+		VerifyCerts: !certOptions.SkipCertVerify,
+		Insecure:    certOptions.Insecure,
+	}
 
 	// backup and reset stderr to avoid kbld to write anything to stderr
 	stdErr := os.Stderr
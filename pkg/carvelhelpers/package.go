@@ -9,6 +9,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/vmware-tanzu/tanzu-cli/pkg/registry"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
 )
 
@@ -28,8 +29,10 @@ func ProcessCarvelPackage(image string, valuesFiles ...string) ([]byte, error) {
 	return CarvelPackageProcessor(pkgDir, image, valuesFiles...)
 }
 
-// CarvelPackageProcessor processes a carvel package and returns a configuration YAML file
-func CarvelPackageProcessor(pkgDir, _ string, valuesFiles ...string) ([]byte, error) {
+// CarvelPackageProcessor processes a carvel package and returns a configuration YAML file.
+// The images of the package are resolved using the certificate configuration of the
+// registry hosting the package image, if specified.
+func CarvelPackageProcessor(pkgDir, image string, valuesFiles ...string) ([]byte, error) {
 	// Each package contains `config` and `.imgpkg` directory
 	// `config` directory contains ytt files
 	// `.imgpkg` directory contains ImageLock configuration for ImageResolution
@@ -59,5 +62,12 @@ func CarvelPackageProcessor(pkgDir, _ string, valuesFiles ...string) ([]byte, er
 		inputFilesForImageResolution = append(inputFilesForImageResolution, imgpkgDir)
 	}
 
-	return ResolveImagesInPackage(inputFilesForImageResolution)
+	certOptions := &registry.CertOptions{}
+	if image != "" {
+		certOptions, err = registry.GetRegistryCertOptionsForImage(image)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resolveImagesInPackage(inputFilesForImageResolution, certOptions)
 }
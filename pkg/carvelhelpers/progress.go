@@ -0,0 +1,218 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package carvelhelpers
+
+import (
+	"io"
+	"os"
+	"sync"
+
+	regv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/registry"
+)
+
+// Progress describes the progress of an image transfer
+type Progress struct {
+	// Image is the image transferred: the source image when copying an image to a tar
+	// file and the destination image otherwise. It tells apart the concurrent transfers.
+	Image string
+	// BytesTransferred is the number of bytes transferred so far
+	BytesTransferred int64
+	// TotalBytes is the total number of bytes to transfer
+	TotalBytes int64
+	// LayersCompleted is the number of layers fully transferred so far
+	LayersCompleted int
+	// TotalLayers is the number of layers of the image
+	TotalLayers int
+}
+
+// ProgressFunc is invoked every time the progress of an image transfer changes
+type ProgressFunc func(Progress)
+
+// ImageOperationsOption configures an ImageOperationsImpl instance
+type ImageOperationsOption func(*imageOperationsConfig)
+
+type imageOperationsConfig struct {
+	progress   ProgressFunc
+	connection *registry.ConnectionOptions
+	retry      *RetryPolicy
+}
+
+// WithProgressCallback registers a callback reporting the progress of the image
+// transfers. The callback can be invoked concurrently by concurrent transfers.
+// The go-containerregistry based implementation reports the bytes transferred as
+// the transfers go. As the imgpkg commands do not expose the progress of their
+// transfers, the imgpkg based implementation only reports the copies of images
+// to and from tar files once completed.
+func WithProgressCallback(fn ProgressFunc) ImageOperationsOption {
+	return func(c *imageOperationsConfig) {
+		c.progress = fn
+	}
+}
+
+// WithConnectionOptions sets the TLS and proxy settings used to access the registries,
+// on top of the certificate configuration of the registries and of the environment
+func WithConnectionOptions(connection *registry.ConnectionOptions) ImageOperationsOption {
+	return func(c *imageOperationsConfig) {
+		c.connection = connection
+	}
+}
+
+func newImageOperationsConfig(opts []ImageOperationsOption) *imageOperationsConfig {
+	c := &imageOperationsConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// progressTracker aggregates the progress of the layers of an image
+// and reports it to the progress callback
+type progressTracker struct {
+	mu       sync.Mutex
+	progress Progress
+	fn       ProgressFunc
+}
+
+// newProgressTracker returns a progressTracker for the transfer of the image or nil if no
+// callback is configured. All the methods of progressTracker can be invoked on a nil tracker.
+func newProgressTracker(fn ProgressFunc, image string) *progressTracker {
+	if fn == nil {
+		return nil
+	}
+	return &progressTracker{fn: fn, progress: Progress{Image: image}}
+}
+
+// reportTarTransferred reports the transfer of the image to or from the tar file as completed,
+// for the implementations which cannot report the progress of the transfer as it goes
+func reportTarTransferred(fn ProgressFunc, image, tarFile string) {
+	if fn == nil {
+		return
+	}
+	info, err := os.Stat(tarFile)
+	if err != nil {
+		return
+	}
+	fn(Progress{Image: image, BytesTransferred: info.Size(), TotalBytes: info.Size()})
+}
+
+func (t *progressTracker) update(f func(p *Progress)) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	f(&t.progress)
+	t.fn(t.progress)
+}
+
+// trackLayers returns layers which report the bytes read from their compressed
+// content to the tracker
+func (t *progressTracker) trackLayers(layers []regv1.Layer) ([]regv1.Layer, error) {
+	if t == nil {
+		return layers, nil
+	}
+	var total int64
+	tracked := make([]regv1.Layer, 0, len(layers))
+	for _, layer := range layers {
+		size, err := layer.Size()
+		if err != nil {
+			return nil, err
+		}
+		total += size
+		trackedLayer, err := partial.CompressedToLayer(&progressLayer{layer: layer, tracker: t})
+		if err != nil {
+			return nil, err
+		}
+		tracked = append(tracked, trackedLayer)
+	}
+	t.update(func(p *Progress) {
+		p.TotalBytes = total
+		p.TotalLayers = len(layers)
+	})
+	return tracked, nil
+}
+
+// trackUpdates returns a channel to pass to the go-containerregistry functions
+// supporting progress updates and a function waiting for all the updates to be
+// reported. The channel is closed by the remote functions of go-containerregistry once
+// the operation completes, the callers of the tarball functions must close it themselves.
+// As the updates only report bytes, all the layers are considered completed once
+// all the bytes are transferred.
+func (t *progressTracker) trackUpdates(totalLayers int) (chan regv1.Update, func()) {
+	updates := make(chan regv1.Update, 100)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for u := range updates {
+			if u.Error != nil {
+				continue
+			}
+			t.update(func(p *Progress) {
+				p.BytesTransferred = u.Complete
+				p.TotalBytes = u.Total
+				p.TotalLayers = totalLayers
+				if u.Total > 0 && u.Complete >= u.Total {
+					p.LayersCompleted = totalLayers
+				}
+			})
+		}
+	}()
+	return updates, func() { <-done }
+}
+
+// progressLayer wraps a layer to report the bytes read from its compressed content
+type progressLayer struct {
+	layer   regv1.Layer
+	tracker *progressTracker
+}
+
+// Digest returns the digest of the compressed layer
+func (l *progressLayer) Digest() (regv1.Hash, error) {
+	return l.layer.Digest()
+}
+
+// Size returns the size of the compressed layer
+func (l *progressLayer) Size() (int64, error) {
+	return l.layer.Size()
+}
+
+// MediaType returns the media type of the layer
+func (l *progressLayer) MediaType() (types.MediaType, error) {
+	return l.layer.MediaType()
+}
+
+// Compressed returns the compressed content of the layer
+func (l *progressLayer) Compressed() (io.ReadCloser, error) {
+	rc, err := l.layer.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	return &progressReader{ReadCloser: rc, tracker: l.tracker}, nil
+}
+
+// progressReader reports the bytes read to the tracker and
+// marks the layer completed once its content is fully read
+type progressReader struct {
+	io.ReadCloser
+	tracker   *progressTracker
+	completed bool
+}
+
+func (r *progressReader) Read(b []byte) (int, error) {
+	n, err := r.ReadCloser.Read(b)
+	if n > 0 || (err == io.EOF && !r.completed) {
+		r.tracker.update(func(p *Progress) {
+			p.BytesTransferred += int64(n)
+			if err == io.EOF && !r.completed {
+				r.completed = true
+				p.LayersCompleted++
+			}
+		})
+	}
+	return n, err
+}
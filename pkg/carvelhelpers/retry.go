@@ -0,0 +1,220 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package carvelhelpers
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+)
+
+// RetryPolicy defines the timeout and retry behavior of the image operations
+type RetryPolicy struct {
+	// Timeout of each attempt of an operation, 0 means no timeout
+	Timeout time.Duration
+	// Retries is the number of retries of an operation failing with a transient error
+	Retries int
+	// Backoff is the delay before the first retry, doubled for each subsequent retry up to MaxBackoff
+	Backoff time.Duration
+	// MaxBackoff is the maximum delay before a retry, 0 means no maximum
+	MaxBackoff time.Duration
+	// Jitter reduces each delay by a random amount of up to half of it, so that
+	// the concurrent operations failing at the same time do not retry at the same time
+	Jitter bool
+}
+
+// DefaultRetryPolicy returns the retry policy of the image operations configured through the
+// environment variables, which can also be set in the configuration of the CLI with
+// `tanzu config set env.<variable> <value>`, with jitter and the default values otherwise
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		Timeout:    time.Duration(getNonNegativeIntFromEnv(constants.ConfigVariableRegistryOperationTimeoutSeconds, constants.DefaultRegistryOperationTimeoutSeconds)) * time.Second,
+		Retries:    getNonNegativeIntFromEnv(constants.ConfigVariableRegistryOperationRetryCount, constants.DefaultRegistryOperationRetryCount),
+		Backoff:    time.Duration(getNonNegativeIntFromEnv(constants.ConfigVariableRegistryOperationRetryBackoffSeconds, constants.DefaultRegistryOperationRetryBackoffSeconds)) * time.Second,
+		MaxBackoff: time.Duration(getNonNegativeIntFromEnv(constants.ConfigVariableRegistryOperationRetryMaxBackoffSeconds, constants.DefaultRegistryOperationRetryMaxBackoffSeconds)) * time.Second,
+		Jitter:     true,
+	}
+}
+
+// WithRetryPolicy sets the timeout and retry behavior of the image operations,
+// DefaultRetryPolicy when not set
+func WithRetryPolicy(policy RetryPolicy) ImageOperationsOption {
+	return func(c *imageOperationsConfig) {
+		c.retry = &policy
+	}
+}
+
+func getNonNegativeIntFromEnv(variable string, defaultValue int) int {
+	override := os.Getenv(variable)
+	if override != "" {
+		value, err := strconv.Atoi(override)
+		if err == nil && value >= 0 {
+			return value
+		}
+	}
+	return defaultValue
+}
+
+// delay returns the delay before the retry following the backoff, capped to the maximum
+// backoff and reduced by the jitter
+func (p *RetryPolicy) delay(backoff time.Duration) time.Duration {
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	if !p.Jitter || backoff < 2 {
+		return backoff
+	}
+	return backoff - time.Duration(rand.Int63n(int64(backoff/2)+1)) //nolint:gosec
+}
+
+// runWithRetries runs the operation with the timeout of the retry policy, DefaultRetryPolicy when
+// nil, and retries it with an exponential backoff as long as it fails with a transient error.
+// The context passed to the operation is canceled once its attempt times out, the operation
+// must then return promptly.
+func runWithRetries(policy *RetryPolicy, operation string, f func(ctx context.Context) error) error {
+	_, err := runWithRetriesAndResult(policy, operation, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, f(ctx)
+	})
+	return err
+}
+
+// runWithRetriesAndResult is similar to runWithRetries for operations returning a result
+func runWithRetriesAndResult[T any](policy *RetryPolicy, operation string, f func(ctx context.Context) (T, error)) (T, error) {
+	return retry(policy, operation, true, f)
+}
+
+// runUncancelableWithRetries is similar to runWithRetries for the operations which cannot be
+// canceled, e.g. the imgpkg commands. An attempt which times out is abandoned and, as it may
+// still be running, the operation is not retried so that no other attempt runs concurrently.
+func runUncancelableWithRetries(policy *RetryPolicy, operation string, f func() error) error {
+	_, err := runUncancelableWithRetriesAndResult(policy, operation, func() (struct{}, error) {
+		return struct{}{}, f()
+	})
+	return err
+}
+
+// runUncancelableWithRetriesAndResult is similar to runUncancelableWithRetries for operations
+// returning a result. The result of an abandoned attempt is discarded.
+func runUncancelableWithRetriesAndResult[T any](policy *RetryPolicy, operation string, f func() (T, error)) (T, error) {
+	return retry(policy, operation, false, func(context.Context) (T, error) {
+		return f()
+	})
+}
+
+func retry[T any](policy *RetryPolicy, operation string, cancelable bool, f func(ctx context.Context) (T, error)) (T, error) {
+	p := DefaultRetryPolicy()
+	if policy != nil {
+		p = *policy
+	}
+	backoff := p.Backoff
+	for attempt := 0; ; attempt++ {
+		result, err := runWithTimeout(operation, p.Timeout, cancelable, f)
+		if err == nil || attempt >= p.Retries || !IsTransientError(err) {
+			return result, err
+		}
+		if !cancelable && errors.Is(err, errOperationTimedOut) {
+			return result, err
+		}
+		delay := p.delay(backoff)
+		log.V(4).Infof("%s failed, retrying in %v: %v", operation, delay.Round(time.Millisecond), err)
+		time.Sleep(delay)
+		backoff *= 2
+	}
+}
+
+// errOperationTimedOut is returned when an attempt of an operation times out
+var errOperationTimedOut = errors.New("operation timed out")
+
+type operationResult[T any] struct {
+	value T
+	err   error
+}
+
+// runWithTimeout runs the attempt of the operation. A cancelable attempt is canceled once it
+// times out and its completion is awaited, an attempt which cannot be canceled is abandoned.
+func runWithTimeout[T any](operation string, timeout time.Duration, cancelable bool, f func(ctx context.Context) (T, error)) (T, error) {
+	if timeout == 0 {
+		return f(context.Background())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if cancelable {
+		value, err := f(ctx)
+		if err != nil && ctx.Err() != nil {
+			return value, errors.Wrapf(errOperationTimedOut, "%s did not complete within %v", operation, timeout)
+		}
+		return value, err
+	}
+
+	resultCh := make(chan operationResult[T], 1)
+	go func() {
+		value, err := f(ctx)
+		resultCh <- operationResult[T]{value: value, err: err}
+	}()
+	select {
+	case result := <-resultCh:
+		return result.value, result.err
+	case <-ctx.Done():
+		var zero T
+		return zero, errors.Wrapf(errOperationTimedOut, "%s did not complete within %v", operation, timeout)
+	}
+}
+
+// transientErrorMessages are the messages of the transient errors which may be reported without
+// their type, as some imgpkg operations only keep the message of the errors of the registry
+var transientErrorMessages = []string{
+	"429 Too Many Requests",
+	"500 Internal Server Error",
+	"502 Bad Gateway",
+	"503 Service Unavailable",
+	"504 Gateway Timeout",
+	"connection reset by peer",
+	"connection refused",
+	"i/o timeout",
+	"TLS handshake timeout",
+	"unexpected EOF",
+}
+
+// IsTransientError returns true if the error may not happen again when retrying the operation,
+// e.g. a network error, or a 429 or 5xx response of the registry
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, errOperationTimedOut) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var transportErr *transport.Error
+	if errors.As(err, &transportErr) {
+		return transportErr.Temporary() || transportErr.StatusCode >= http.StatusInternalServerError ||
+			transportErr.StatusCode == http.StatusTooManyRequests
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	message := err.Error()
+	for _, transientMessage := range transientErrorMessages {
+		if strings.Contains(message, transientMessage) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package carvelhelpers
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+)
+
+func Test_DefaultRetryPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	p := DefaultRetryPolicy()
+	assert.Equal(time.Duration(constants.DefaultRegistryOperationTimeoutSeconds)*time.Second, p.Timeout)
+	assert.Equal(constants.DefaultRegistryOperationRetryCount, p.Retries)
+	assert.Equal(time.Duration(constants.DefaultRegistryOperationRetryBackoffSeconds)*time.Second, p.Backoff)
+	assert.Equal(time.Duration(constants.DefaultRegistryOperationRetryMaxBackoffSeconds)*time.Second, p.MaxBackoff)
+	assert.True(p.Jitter)
+
+	os.Setenv(constants.ConfigVariableRegistryOperationTimeoutSeconds, "0")
+	os.Setenv(constants.ConfigVariableRegistryOperationRetryCount, "5")
+	os.Setenv(constants.ConfigVariableRegistryOperationRetryBackoffSeconds, "-1")
+	os.Setenv(constants.ConfigVariableRegistryOperationRetryMaxBackoffSeconds, "30")
+	defer os.Unsetenv(constants.ConfigVariableRegistryOperationTimeoutSeconds)
+	defer os.Unsetenv(constants.ConfigVariableRegistryOperationRetryCount)
+	defer os.Unsetenv(constants.ConfigVariableRegistryOperationRetryBackoffSeconds)
+	defer os.Unsetenv(constants.ConfigVariableRegistryOperationRetryMaxBackoffSeconds)
+
+	p = DefaultRetryPolicy()
+	assert.Equal(time.Duration(0), p.Timeout)
+	assert.Equal(5, p.Retries)
+	// Invalid values are ignored
+	assert.Equal(time.Duration(constants.DefaultRegistryOperationRetryBackoffSeconds)*time.Second, p.Backoff)
+	assert.Equal(30*time.Second, p.MaxBackoff)
+}
+
+func Test_RetryPolicyDelay(t *testing.T) {
+	assert := assert.New(t)
+
+	p := &RetryPolicy{MaxBackoff: time.Minute}
+	assert.Equal(10*time.Second, p.delay(10*time.Second))
+	assert.Equal(time.Minute, p.delay(2*time.Minute))
+	p.MaxBackoff = 0
+	assert.Equal(2*time.Minute, p.delay(2*time.Minute))
+
+	// The jitter reduces the delays by up to half of them
+	p = &RetryPolicy{MaxBackoff: time.Minute, Jitter: true}
+	assert.Equal(time.Duration(0), p.delay(0))
+	assert.Equal(time.Duration(1), p.delay(1))
+	for i := 0; i < 100; i++ {
+		delay := p.delay(10 * time.Second)
+		assert.GreaterOrEqual(delay, 5*time.Second)
+		assert.LessOrEqual(delay, 10*time.Second)
+		delay = p.delay(2 * time.Minute)
+		assert.GreaterOrEqual(delay, 30*time.Second)
+		assert.LessOrEqual(delay, time.Minute)
+	}
+}
+
+func Test_RunWithRetries(t *testing.T) {
+	assert := assert.New(t)
+
+	os.Setenv(constants.ConfigVariableRegistryOperationRetryCount, "2")
+	os.Setenv(constants.ConfigVariableRegistryOperationRetryBackoffSeconds, "0")
+	defer os.Unsetenv(constants.ConfigVariableRegistryOperationRetryCount)
+	defer os.Unsetenv(constants.ConfigVariableRegistryOperationRetryBackoffSeconds)
+
+	// Transient errors are retried
+	attempts := 0
+	err := runWithRetries(nil, "test", func(_ context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return &transport.Error{StatusCode: http.StatusServiceUnavailable}
+		}
+		return nil
+	})
+	assert.Nil(err)
+	assert.Equal(3, attempts)
+
+	// The last error is returned once all the retries are exhausted
+	attempts = 0
+	err = runWithRetries(nil, "test", func(_ context.Context) error {
+		attempts++
+		return errors.Wrap(&transport.Error{StatusCode: http.StatusTooManyRequests}, "wrapped")
+	})
+	assert.NotNil(err)
+	assert.Equal(3, attempts)
+
+	// Other errors are not retried
+	attempts = 0
+	err = runWithRetries(nil, "test", func(_ context.Context) error {
+		attempts++
+		return &transport.Error{StatusCode: http.StatusNotFound}
+	})
+	assert.NotNil(err)
+	assert.Equal(1, attempts)
+
+	// The retry policy takes precedence over the environment variables
+	attempts = 0
+	err = runWithRetries(&RetryPolicy{Retries: 1, Backoff: time.Nanosecond, Jitter: true}, "test", func(_ context.Context) error {
+		attempts++
+		return &transport.Error{StatusCode: http.StatusServiceUnavailable}
+	})
+	assert.NotNil(err)
+	assert.Equal(2, attempts)
+}
+
+func Test_RunWithRetriesTimeout(t *testing.T) {
+	assert := assert.New(t)
+
+	policy := &RetryPolicy{Timeout: 100 * time.Millisecond, Retries: 2}
+
+	// Attempts which do not complete in time are canceled, awaited and retried
+	var running, timedAttempts int32
+	result, err := runWithRetriesAndResult(policy, "test", func(ctx context.Context) (string, error) {
+		assert.Equal(int32(1), atomic.AddInt32(&running, 1), "the attempts must not overlap")
+		defer atomic.AddInt32(&running, -1)
+		if atomic.AddInt32(&timedAttempts, 1) == 1 {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return "done", nil
+	})
+	assert.Nil(err)
+	assert.Equal("done", result)
+	assert.Equal(int32(2), atomic.LoadInt32(&timedAttempts))
+
+	// Attempts which cannot be canceled are abandoned once they time out and not retried
+	var uncancelableAttempts int32
+	release := make(chan struct{})
+	defer close(release)
+	err = runUncancelableWithRetries(policy, "test", func() error {
+		atomic.AddInt32(&uncancelableAttempts, 1)
+		<-release
+		return nil
+	})
+	assert.NotNil(err)
+	assert.True(errors.Is(err, errOperationTimedOut))
+	assert.Equal(int32(1), atomic.LoadInt32(&uncancelableAttempts))
+
+	// Attempts which cannot be canceled are retried when they fail in time
+	uncancelableAttempts = 0
+	err = runUncancelableWithRetries(policy, "test", func() error {
+		if atomic.AddInt32(&uncancelableAttempts, 1) == 1 {
+			return &transport.Error{StatusCode: http.StatusBadGateway}
+		}
+		return nil
+	})
+	assert.Nil(err)
+	assert.Equal(int32(2), atomic.LoadInt32(&uncancelableAttempts))
+}
+
+func Test_IsTransientError(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.False(IsTransientError(nil))
+	assert.True(IsTransientError(&transport.Error{StatusCode: http.StatusServiceUnavailable}))
+	assert.True(IsTransientError(errors.Wrap(&transport.Error{StatusCode: http.StatusTooManyRequests}, "wrapped")))
+	assert.False(IsTransientError(&transport.Error{StatusCode: http.StatusUnauthorized}))
+	// The errors which only keep the message of a transient error
+	assert.True(IsTransientError(errors.New("PUT https://registry/v2/blobs: 502 Bad Gateway")))
+	assert.True(IsTransientError(errors.New("read tcp 10.0.0.1:443: read: connection reset by peer")))
+	assert.False(IsTransientError(errors.New("PUT https://registry/v2/blobs: 401 Unauthorized")))
+}
+
+func Test_IsTransientErrorFalsePositives(t *testing.T) {
+	assert := assert.New(t)
+
+	// The errors which will happen again when retrying are not transient, including
+	// the ones whose message merely resembles the one of a transient error
+	for _, message := range []string{
+		"GET https://registry/v2/tanzu/plugin/manifests/v1.0.0: MANIFEST_UNKNOWN: manifest unknown",
+		"GET https://registry/v2/tanzu/plugin/manifests/v1.0.0: 404 Not Found",
+		"POST https://registry/v2/tanzu/plugin/blobs/uploads/: DENIED: requested access to the resource is denied",
+		"GET https://registry/v2/: 403 Forbidden",
+		"Get \"https://registry/v2/\": x509: certificate signed by unknown authority",
+		"Get \"https://registry/v2/\": dial tcp: lookup registry on 127.0.0.53:53: no such host",
+		"could not parse reference: registry/tanzu/plugin:v5.0.3",
+		"image registry/tanzu/plugin-503:v1.0.0 not found",
+		"the plugin \"connection\" was refused by the policy",
+		"unable to read the manifest of \"/tmp/image.tar\": open /tmp/image.tar: no such file or directory",
+	} {
+		assert.False(IsTransientError(errors.New(message)), message)
+	}
+}
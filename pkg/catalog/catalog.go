@@ -194,6 +194,17 @@ func newSharedCatalog() (*Catalog, error) {
 // and keep the WriteLock to the file along with returning `lockedFile` object. It is caller's
 // responsibility to unlock the WriteLock after the catalog update
 func getCatalogCache(setWriteLock bool) (*Catalog, *lockedfile.File, error) {
+	if !setWriteLock {
+		// Use the parsed form of the catalog if it is up-to-date with the catalog file
+		// to avoid parsing the catalog file on every invocation
+		if fi, err := os.Stat(GetCatalogCachePath()); err == nil {
+			if c := readCatalogIndex(fi); c != nil {
+				initCatalogIndexes(c)
+				return c, nil, nil
+			}
+		}
+	}
+
 	b, lockedFile, err := getCatalogCacheBytes(setWriteLock)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -212,6 +223,13 @@ func getCatalogCache(setWriteLock bool) (*Catalog, *lockedfile.File, error) {
 		return nil, lockedFile, errors.Wrap(err, "could not decode catalog file")
 	}
 
+	initCatalogIndexes(&c)
+
+	return &c, lockedFile, nil
+}
+
+// initCatalogIndexes initializes the nil indexes of the catalog
+func initCatalogIndexes(c *Catalog) {
 	if c.IndexByPath == nil {
 		c.IndexByPath = map[string]cli.PluginInfo{}
 	}
@@ -224,8 +242,6 @@ func getCatalogCache(setWriteLock bool) (*Catalog, *lockedfile.File, error) {
 	if c.ServerPlugins == nil {
 		c.ServerPlugins = map[string]PluginAssociation{}
 	}
-
-	return &c, lockedFile, nil
 }
 
 func getCatalogCacheBytes(setWriteLock bool) ([]byte, *lockedfile.File, error) {
@@ -234,17 +250,17 @@ func getCatalogCacheBytes(setWriteLock bool) ([]byte, *lockedfile.File, error) {
 	var b []byte
 
 	if setWriteLock {
-		if !utils.PathExists(getCatalogCachePath()) {
+		if !utils.PathExists(GetCatalogCachePath()) {
 			// Create directory path if missing before locking the file
 			_ = os.MkdirAll(getCatalogCacheDir(), 0755)
 		}
-		lockedFile, err = lockedfile.Edit(getCatalogCachePath())
+		lockedFile, err = lockedfile.Edit(GetCatalogCachePath())
 		if err != nil {
 			return nil, lockedFile, err
 		}
 		b, err = io.ReadAll(lockedFile)
 	} else {
-		b, err = lockedfile.Read(getCatalogCachePath())
+		b, err = lockedfile.Read(GetCatalogCachePath())
 	}
 	return b, lockedFile, err
 }
@@ -255,7 +271,7 @@ func saveCatalogCache(catalog *Catalog, lockedCatalogFile *lockedfile.File) erro
 		return errors.New("cannot save the catalog file. catalog is not locked")
 	}
 
-	catalogCachePath := getCatalogCachePath()
+	catalogCachePath := GetCatalogCachePath()
 	_, err := os.Stat(catalogCachePath)
 	if os.IsNotExist(err) {
 		err = os.MkdirAll(getCatalogCacheDir(), 0755)
@@ -271,6 +287,10 @@ func saveCatalogCache(catalog *Catalog, lockedCatalogFile *lockedfile.File) erro
 		return errors.Wrap(err, "failed to encode catalog cache file")
 	}
 
+	// Remove the catalog index before updating the catalog file so that a failure
+	// to update the index cannot leave a stale index behind
+	removeCatalogIndex()
+
 	if err := lockedCatalogFile.Truncate(0); err != nil {
 		return errors.Wrap(err, "failed to write catalog cache file. truncate failed")
 	}
@@ -280,19 +300,23 @@ func saveCatalogCache(catalog *Catalog, lockedCatalogFile *lockedfile.File) erro
 	if _, err := lockedCatalogFile.Write(out); err != nil {
 		return errors.Wrap(err, "failed to write catalog cache file")
 	}
+	if fi, err := lockedCatalogFile.Stat(); err == nil {
+		saveCatalogIndex(catalog, fi)
+	}
 	return nil
 }
 
 // CleanCatalogCache cleans the catalog cache
 func CleanCatalogCache() error {
-	if err := os.Remove(getCatalogCachePath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+	removeCatalogIndex()
+	if err := os.Remove(GetCatalogCachePath()); err != nil && !errors.Is(err, os.ErrNotExist) {
 		return err
 	}
 	return nil
 }
 
-// getCatalogCachePath gets the catalog cache path
-func getCatalogCachePath() string {
+// GetCatalogCachePath gets the catalog cache path
+func GetCatalogCachePath() string {
 	return filepath.Join(getCatalogCacheDir(), catalogCacheFileName)
 }
 
@@ -0,0 +1,88 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package catalog
+
+import (
+	"bytes"
+	"encoding/gob"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// catalogIndexFileName is the name of the file which holds the parsed form of the
+	// Catalog cache. Decoding it is much faster than parsing the YAML catalog file
+	// which matters for every CLI invocation when many plugins are installed.
+	catalogIndexFileName = "catalog.idx"
+)
+
+// catalogIndex is the parsed form of the catalog cache file along with the
+// modification time and size of the catalog file it was built from
+type catalogIndex struct {
+	CatalogModTime int64
+	CatalogSize    int64
+	Catalog        Catalog
+}
+
+// getCatalogIndexPath gets the catalog index path
+func getCatalogIndexPath() string {
+	return filepath.Join(getCatalogCacheDir(), catalogIndexFileName)
+}
+
+// readCatalogIndex returns the catalog stored in the catalog index if the index
+// is up-to-date with the catalog file described by catalogFileInfo.
+// It returns nil if the index is missing, stale or cannot be decoded.
+func readCatalogIndex(catalogFileInfo os.FileInfo) *Catalog {
+	b, err := os.ReadFile(getCatalogIndexPath())
+	if err != nil {
+		return nil
+	}
+	var idx catalogIndex
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&idx); err != nil {
+		return nil
+	}
+	if idx.CatalogModTime != catalogFileInfo.ModTime().UnixNano() || idx.CatalogSize != catalogFileInfo.Size() {
+		return nil
+	}
+	return &idx.Catalog
+}
+
+// saveCatalogIndex stores the parsed catalog keyed by the modification time and size
+// of the catalog file described by catalogFileInfo. It must be called while holding
+// the write lock of the catalog file, right after the catalog file is saved.
+// The index is only an optimization, so failures are ignored and the index removed
+// to force readers to fall back to parsing the catalog file.
+func saveCatalogIndex(catalog *Catalog, catalogFileInfo os.FileInfo) {
+	var buf bytes.Buffer
+	idx := catalogIndex{
+		CatalogModTime: catalogFileInfo.ModTime().UnixNano(),
+		CatalogSize:    catalogFileInfo.Size(),
+		Catalog:        *catalog,
+	}
+	if err := gob.NewEncoder(&buf).Encode(&idx); err != nil {
+		removeCatalogIndex()
+		return
+	}
+
+	// Write to a temporary file first and rename it to make the update
+	// atomic for readers which do not lock the index file
+	tmpFile, err := os.CreateTemp(getCatalogCacheDir(), catalogIndexFileName)
+	if err != nil {
+		removeCatalogIndex()
+		return
+	}
+	defer os.Remove(tmpFile.Name())
+	_, err = tmpFile.Write(buf.Bytes())
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || os.Rename(tmpFile.Name(), getCatalogIndexPath()) != nil {
+		removeCatalogIndex()
+	}
+}
+
+// removeCatalogIndex removes the catalog index
+func removeCatalogIndex() {
+	_ = os.Remove(getCatalogIndexPath())
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package catalog
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+)
+
+func Test_CatalogIndex(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := os.MkdirTemp("", "test-catalog")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	common.DefaultCacheDir = dir
+
+	cc, err := NewContextCatalogUpdater("")
+	assert.Nil(err)
+	assert.Nil(cc.Upsert(&cli.PluginInfo{Name: "fakeplugin1", InstallationPath: "/path/to/plugin/fakeplugin1", Version: "1.0.0"}))
+	cc.Unlock()
+
+	// Saving the catalog should create an up-to-date index
+	fi, err := os.Stat(GetCatalogCachePath())
+	assert.Nil(err)
+	c := readCatalogIndex(fi)
+	assert.NotNil(c)
+	assert.Equal("1.0.0", c.IndexByPath["/path/to/plugin/fakeplugin1"].Version)
+
+	// Readers should get the content of the index
+	reader, err := NewContextCatalog("")
+	assert.Nil(err)
+	pd, exists := reader.Get("fakeplugin1")
+	assert.True(exists)
+	assert.Equal("1.0.0", pd.Version)
+
+	// An index which does not match the catalog file should be ignored
+	assert.Nil(os.WriteFile(GetCatalogCachePath(), []byte(`indexByPath:
+  /path/to/plugin/fakeplugin2:
+    name: fakeplugin2
+    version: 2.0.0
+    installationPath: /path/to/plugin/fakeplugin2
+standAlonePlugins:
+  fakeplugin2: /path/to/plugin/fakeplugin2
+`), 0644))
+	fi, err = os.Stat(GetCatalogCachePath())
+	assert.Nil(err)
+	assert.Nil(readCatalogIndex(fi))
+
+	reader, err = NewContextCatalog("")
+	assert.Nil(err)
+	_, exists = reader.Get("fakeplugin1")
+	assert.False(exists)
+	pd, exists = reader.Get("fakeplugin2")
+	assert.True(exists)
+	assert.Equal("2.0.0", pd.Version)
+
+	// Cleaning the catalog should also remove the index
+	assert.Nil(CleanCatalogCache())
+	assert.NoFileExists(getCatalogIndexPath())
+}
@@ -0,0 +1,299 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package catalog
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+)
+
+// testPluginPrefix is the prefix of the test plugin binaries installed next to their plugin binary
+const testPluginPrefix = "test-"
+
+// TempPluginBinaryInfix is part of the name of the temporary files the plugin binaries are written to
+// before being renamed, which belong to an installation in progress and are never garbage collected
+const TempPluginBinaryInfix = ".tmp-"
+
+// PrefetchedPluginMarkerSuffix is appended to the path of a plugin binary prefetched to the plugin store,
+// to name the marker file which keeps the binary from being garbage collected until the plugin is installed
+const PrefetchedPluginMarkerSuffix = ".prefetched"
+
+// prefetchedPluginRetention is how long a prefetched plugin binary which does not get installed is kept
+var prefetchedPluginRetention = 7 * 24 * time.Hour
+
+// GarbageCollectionOptions defines the options for a plugin binaries garbage collection
+type GarbageCollectionOptions struct {
+	// DryRun only reports the binaries that would be removed
+	DryRun bool
+	// RetainedVersions is the number of most recent versions of each installed plugin and
+	// target kept in the plugin store. Installed versions are always kept and count towards
+	// this number. Keeping older versions allows reinstalling a previous version of a
+	// plugin, e.g. to roll back an upgrade, without downloading it again. No version of
+	// a plugin which is no longer installed is kept.
+	RetainedVersions int
+	// PluginName restricts the garbage collection to the binaries of the given plugin
+	PluginName string
+	// RetentionOnly restricts the garbage collection to the versions of the installed plugins
+	// beyond the retention policy, e.g. after installing a plugin, keeping the binaries of the
+	// plugins and targets which are not installed
+	RetentionOnly bool
+}
+
+// GarbageCollectionResult holds the result of a plugin binaries garbage collection
+type GarbageCollectionResult struct {
+	// RemovedPaths are the paths of the plugin binaries that were (or would be, for a dry-run) removed
+	RemovedPaths []string
+	// ReclaimedBytes is the disk space freed by removing the plugin binaries
+	ReclaimedBytes int64
+}
+
+// pluginBinary describes a plugin binary of the plugin store.
+// Plugin binaries are stored as <pluginRoot>/<name>/<version>_<digest>_<target>
+type pluginBinary struct {
+	path       string
+	pluginName string
+	target     string
+	version    *semver.Version
+	referenced bool
+	prefetched bool
+}
+
+// GarbageCollectPluginBinaries removes the plugin binaries of the plugin store that are
+// not referenced by any stand-alone or context plugin association of the catalog and
+// that are older than the number of versions to retain.
+// Such binaries can be left behind by failed installations, plugin upgrades and deletions or removed contexts.
+// The catalog is locked for the whole operation so that no plugin can be installed concurrently.
+func GarbageCollectPluginBinaries(options GarbageCollectionOptions) (*GarbageCollectionResult, error) {
+	c, lockedFile, err := getCatalogCache(true)
+	if err != nil {
+		return nil, err
+	}
+	defer lockedFile.Close()
+
+	orphans, err := findOrphanedPluginBinaries(c, options)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GarbageCollectionResult{}
+	var errList []error
+	for _, path := range orphans {
+		fi, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if !options.DryRun {
+			if err := os.Remove(path); err != nil {
+				errList = append(errList, errors.Wrapf(err, "unable to remove plugin binary %q", path))
+				continue
+			}
+			c.removeIndexEntries(path)
+		}
+		result.RemovedPaths = append(result.RemovedPaths, path)
+		result.ReclaimedBytes += fi.Size()
+	}
+
+	if !options.DryRun && len(result.RemovedPaths) > 0 {
+		removeEmptyPluginDirs()
+		if err := saveCatalogCache(c, lockedFile); err != nil {
+			errList = append(errList, err)
+		}
+	}
+	return result, kerrors.NewAggregate(errList)
+}
+
+// referencedInstallationPaths returns the installation paths of all the plugins
+// associated with a context or installed as stand-alone plugins
+func (c *Catalog) referencedInstallationPaths() map[string]bool {
+	referenced := map[string]bool{}
+	for _, path := range c.StandAlonePlugins {
+		referenced[path] = true
+	}
+	for _, pa := range c.ServerPlugins {
+		for _, path := range pa {
+			referenced[path] = true
+		}
+	}
+	return referenced
+}
+
+// removeIndexEntries removes the entries of the catalog indexes for the given installation path
+func (c *Catalog) removeIndexEntries(installationPath string) {
+	delete(c.IndexByPath, installationPath)
+	for name, paths := range c.IndexByName {
+		var remaining []string
+		for _, p := range paths {
+			if p != installationPath {
+				remaining = append(remaining, p)
+			}
+		}
+		if len(remaining) == 0 {
+			delete(c.IndexByName, name)
+		} else {
+			c.IndexByName[name] = remaining
+		}
+	}
+}
+
+// findOrphanedPluginBinaries returns the paths of the files under the plugin root
+// directory that are neither referenced by the catalog nor retained by the retention policy.
+// A test plugin binary, or the marker of a prefetched plugin binary, is handled along with the
+// plugin binary it belongs to. The temporary files of the installations in progress and the
+// recently prefetched plugin binaries are never orphaned.
+func findOrphanedPluginBinaries(c *Catalog, options GarbageCollectionOptions) ([]string, error) {
+	referenced := c.referencedInstallationPaths()
+
+	var orphans []string
+	binaries := map[string][]*pluginBinary{}
+	err := filepath.WalkDir(common.DefaultPluginRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			if path != common.DefaultPluginRoot && options.PluginName != "" && d.Name() != options.PluginName {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if options.PluginName != "" && filepath.Base(filepath.Dir(path)) != options.PluginName {
+			return nil
+		}
+		if strings.Contains(d.Name(), TempPluginBinaryInfix) {
+			return nil
+		}
+		if strings.HasSuffix(d.Name(), PrefetchedPluginMarkerSuffix) {
+			pluginPath := strings.TrimSuffix(path, PrefetchedPluginMarkerSuffix)
+			if utils.PathExists(pluginPath) && !referenced[pluginPath] {
+				// Handled along with its plugin binary
+				return nil
+			}
+			// The plugin was installed or its binary removed, the marker is no longer needed
+			orphans = append(orphans, path)
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), testPluginPrefix) {
+			pluginPath := filepath.Join(filepath.Dir(path), strings.TrimPrefix(d.Name(), testPluginPrefix))
+			if cli.TestPluginPathFromPluginPath(pluginPath) == path && utils.PathExists(pluginPath) {
+				// Handled along with its plugin binary
+				return nil
+			}
+			orphans = append(orphans, path)
+			return nil
+		}
+
+		pb := parsePluginBinary(path)
+		if pb == nil {
+			if !referenced[path] {
+				orphans = append(orphans, path)
+			}
+			return nil
+		}
+		pb.referenced = referenced[path]
+		pb.prefetched = !pb.referenced && isRecentlyPrefetched(path)
+		key := pb.pluginName + "_" + pb.target
+		binaries[key] = append(binaries[key], pb)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pbs := range binaries {
+		// Most recent versions first
+		sort.SliceStable(pbs, func(i, j int) bool {
+			return pbs[i].version.GreaterThan(pbs[j].version)
+		})
+		// The retention policy applies to versions, while a version can have several binaries,
+		// e.g. for different digests, so all the binaries of a retained version are kept
+		retainedVersions := map[string]bool{}
+		for _, pb := range pbs {
+			if pb.referenced {
+				retainedVersions[pb.version.String()] = true
+			}
+		}
+		// Older versions are only retained for the plugins which are still installed, to allow
+		// rolling back an upgrade, all the binaries of an uninstalled plugin are orphaned
+		installed := len(retainedVersions) > 0
+		if !installed && options.RetentionOnly {
+			continue
+		}
+		for _, pb := range pbs {
+			if pb.referenced || pb.prefetched || retainedVersions[pb.version.String()] {
+				continue
+			}
+			if installed && len(retainedVersions) < options.RetainedVersions {
+				retainedVersions[pb.version.String()] = true
+				continue
+			}
+			orphans = append(orphans, pb.path)
+			if testPath := cli.TestPluginPathFromPluginPath(pb.path); utils.PathExists(testPath) {
+				orphans = append(orphans, testPath)
+			}
+			if markerPath := pb.path + PrefetchedPluginMarkerSuffix; utils.PathExists(markerPath) {
+				orphans = append(orphans, markerPath)
+			}
+		}
+	}
+	sort.Strings(orphans)
+	return orphans, nil
+}
+
+// isRecentlyPrefetched returns true if the plugin binary was prefetched to the plugin store
+// less than prefetchedPluginRetention ago and is waiting to be installed
+func isRecentlyPrefetched(path string) bool {
+	fi, err := os.Stat(path + PrefetchedPluginMarkerSuffix)
+	return err == nil && time.Since(fi.ModTime()) < prefetchedPluginRetention
+}
+
+// parsePluginBinary returns the description of the plugin binary stored at the given path
+// or nil if the file name does not follow the plugin binary naming scheme
+func parsePluginBinary(path string) *pluginBinary {
+	fileName := strings.TrimSuffix(filepath.Base(path), ".exe")
+	parts := strings.SplitN(fileName, "_", 3)
+	if len(parts) != 3 {
+		return nil
+	}
+	v, err := semver.NewVersion(parts[0])
+	if err != nil {
+		return nil
+	}
+	return &pluginBinary{
+		path:       path,
+		pluginName: filepath.Base(filepath.Dir(path)),
+		target:     parts[2],
+		version:    v,
+	}
+}
+
+// removeEmptyPluginDirs removes the plugin directories that no longer contain any binary
+func removeEmptyPluginDirs() {
+	entries, err := os.ReadDir(common.DefaultPluginRoot)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(common.DefaultPluginRoot, entry.Name())
+		if files, err := os.ReadDir(dir); err == nil && len(files) == 0 {
+			_ = os.Remove(dir)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+)
+
+func Test_GarbageCollectPluginBinaries(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := os.MkdirTemp("", "test-catalog")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	common.DefaultCacheDir = dir
+
+	pluginRootDir, err := os.MkdirTemp("", "test-catalog-plugins")
+	assert.Nil(err)
+	defer os.RemoveAll(pluginRootDir)
+	common.DefaultPluginRoot = pluginRootDir
+
+	writeBinary := func(path string, size int) {
+		assert.Nil(os.MkdirAll(filepath.Dir(path), 0755))
+		assert.Nil(os.WriteFile(path, make([]byte, size), 0755))
+	}
+
+	standalonePath := filepath.Join(pluginRootDir, "foo", "v1.0.0_abc_global")
+	contextPath := filepath.Join(pluginRootDir, "bar", "v2.0.0_def_kubernetes")
+	orphanPath := filepath.Join(pluginRootDir, "foo", "v0.9.0_123_global")
+	deletedPath := filepath.Join(pluginRootDir, "baz", "v1.0.0_456_global")
+	writeBinary(standalonePath, 10)
+	writeBinary(cli.TestPluginPathFromPluginPath(standalonePath), 5)
+	writeBinary(contextPath, 10)
+	writeBinary(orphanPath, 100)
+	writeBinary(deletedPath, 20)
+
+	cc, err := NewContextCatalogUpdater("")
+	assert.Nil(err)
+	assert.Nil(cc.Upsert(&cli.PluginInfo{Name: "foo", InstallationPath: standalonePath, Version: "v1.0.0"}))
+	assert.Nil(cc.Upsert(&cli.PluginInfo{Name: "baz", InstallationPath: deletedPath, Version: "v1.0.0"}))
+	assert.Nil(cc.Delete("baz"))
+	cc.Unlock()
+
+	cc, err = NewContextCatalogUpdater("server")
+	assert.Nil(err)
+	assert.Nil(cc.Upsert(&cli.PluginInfo{Name: "bar", InstallationPath: contextPath, Version: "v2.0.0"}))
+	cc.Unlock()
+
+	// A dry-run should only report the orphaned binaries
+	result, err := GarbageCollectPluginBinaries(GarbageCollectionOptions{DryRun: true})
+	assert.Nil(err)
+	assert.ElementsMatch([]string{orphanPath, deletedPath}, result.RemovedPaths)
+	assert.Equal(int64(120), result.ReclaimedBytes)
+	assert.FileExists(orphanPath)
+	assert.FileExists(deletedPath)
+
+	result, err = GarbageCollectPluginBinaries(GarbageCollectionOptions{})
+	assert.Nil(err)
+	assert.ElementsMatch([]string{orphanPath, deletedPath}, result.RemovedPaths)
+	assert.Equal(int64(120), result.ReclaimedBytes)
+	assert.NoFileExists(orphanPath)
+	assert.NoDirExists(filepath.Dir(deletedPath))
+	assert.FileExists(standalonePath)
+	assert.FileExists(cli.TestPluginPathFromPluginPath(standalonePath))
+	assert.FileExists(contextPath)
+
+	c, _, err := getCatalogCache(false)
+	assert.Nil(err)
+	_, exists := c.IndexByPath[deletedPath]
+	assert.False(exists)
+	_, exists = c.IndexByPath[standalonePath]
+	assert.True(exists)
+
+	// Nothing left to collect
+	result, err = GarbageCollectPluginBinaries(GarbageCollectionOptions{})
+	assert.Nil(err)
+	assert.Empty(result.RemovedPaths)
+}
+
+func Test_GarbageCollectPluginBinaries_WithRetention(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := os.MkdirTemp("", "test-catalog")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	common.DefaultCacheDir = dir
+
+	pluginRootDir, err := os.MkdirTemp("", "test-catalog-plugins")
+	assert.Nil(err)
+	defer os.RemoveAll(pluginRootDir)
+	common.DefaultPluginRoot = pluginRootDir
+
+	fooPath := func(version string) string {
+		return filepath.Join(pluginRootDir, "foo", version+"_abc_global")
+	}
+	for _, v := range []string{"v1.0.0", "v1.1.0", "v1.2.0", "v1.10.0"} {
+		assert.Nil(os.MkdirAll(filepath.Dir(fooPath(v)), 0755))
+		assert.Nil(os.WriteFile(fooPath(v), []byte("binary"), 0755))
+	}
+	assert.Nil(os.WriteFile(cli.TestPluginPathFromPluginPath(fooPath("v1.0.0")), []byte("test"), 0755))
+	// A second binary of the most recent version, e.g. for another digest
+	fooOtherDigestPath := filepath.Join(pluginRootDir, "foo", "v1.10.0_xyz_global")
+	assert.Nil(os.WriteFile(fooOtherDigestPath, []byte("binary"), 0755))
+	barPath := filepath.Join(pluginRootDir, "bar", "v1.0.0_def_global")
+	assert.Nil(os.MkdirAll(filepath.Dir(barPath), 0755))
+	assert.Nil(os.WriteFile(barPath, []byte("binary"), 0755))
+
+	// The installed version is older than the most recent version available in the store
+	cc, err := NewContextCatalogUpdater("")
+	assert.Nil(err)
+	assert.Nil(cc.Upsert(&cli.PluginInfo{Name: "foo", InstallationPath: fooPath("v1.1.0"), Version: "v1.1.0"}))
+	cc.Unlock()
+
+	// The installed version counts towards the retained versions
+	result, err := GarbageCollectPluginBinaries(GarbageCollectionOptions{RetainedVersions: 2, PluginName: "foo"})
+	assert.Nil(err)
+	assert.ElementsMatch([]string{fooPath("v1.0.0"), cli.TestPluginPathFromPluginPath(fooPath("v1.0.0")), fooPath("v1.2.0")}, result.RemovedPaths)
+	assert.FileExists(fooPath("v1.1.0"))
+	assert.FileExists(fooPath("v1.10.0"))
+	assert.FileExists(fooOtherDigestPath)
+	// Binaries of other plugins are left untouched
+	assert.FileExists(barPath)
+}
+
+func Test_GarbageCollectPluginBinaries_TempAndPrefetchedBinaries(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := os.MkdirTemp("", "test-catalog")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	common.DefaultCacheDir = dir
+
+	pluginRootDir, err := os.MkdirTemp("", "test-catalog-plugins")
+	assert.Nil(err)
+	defer os.RemoveAll(pluginRootDir)
+	common.DefaultPluginRoot = pluginRootDir
+
+	writeBinary := func(path string) {
+		assert.Nil(os.MkdirAll(filepath.Dir(path), 0755))
+		assert.Nil(os.WriteFile(path, []byte("binary"), 0755))
+	}
+
+	// The binary being written by an installation in progress is kept
+	tempPath := filepath.Join(pluginRootDir, "foo", "v1.2.0_abc_global"+TempPluginBinaryInfix+"123")
+	writeBinary(tempPath)
+	// A recently prefetched binary is kept until it gets installed, an old one is removed
+	prefetchedPath := filepath.Join(pluginRootDir, "bar", "v1.0.0_def_global")
+	writeBinary(prefetchedPath)
+	writeBinary(prefetchedPath + PrefetchedPluginMarkerSuffix)
+	expiredPath := filepath.Join(pluginRootDir, "baz", "v1.0.0_ghi_global")
+	writeBinary(expiredPath)
+	writeBinary(expiredPath + PrefetchedPluginMarkerSuffix)
+	expired := time.Now().Add(-2 * prefetchedPluginRetention)
+	assert.Nil(os.Chtimes(expiredPath+PrefetchedPluginMarkerSuffix, expired, expired))
+
+	result, err := GarbageCollectPluginBinaries(GarbageCollectionOptions{})
+	assert.Nil(err)
+	assert.ElementsMatch([]string{expiredPath, expiredPath + PrefetchedPluginMarkerSuffix}, result.RemovedPaths)
+	assert.FileExists(tempPath)
+	assert.FileExists(prefetchedPath)
+	assert.FileExists(prefetchedPath + PrefetchedPluginMarkerSuffix)
+
+	// Once the prefetched plugin is installed, its marker is no longer needed
+	cc, err := NewContextCatalogUpdater("")
+	assert.Nil(err)
+	assert.Nil(cc.Upsert(&cli.PluginInfo{Name: "bar", InstallationPath: prefetchedPath, Version: "v1.0.0"}))
+	cc.Unlock()
+	result, err = GarbageCollectPluginBinaries(GarbageCollectionOptions{})
+	assert.Nil(err)
+	assert.Equal([]string{prefetchedPath + PrefetchedPluginMarkerSuffix}, result.RemovedPaths)
+	assert.FileExists(prefetchedPath)
+}
+
+func Test_GarbageCollectPluginBinaries_UninstalledPlugin(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := os.MkdirTemp("", "test-catalog")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	common.DefaultCacheDir = dir
+
+	pluginRootDir, err := os.MkdirTemp("", "test-catalog-plugins")
+	assert.Nil(err)
+	defer os.RemoveAll(pluginRootDir)
+	common.DefaultPluginRoot = pluginRootDir
+
+	pluginPath := func(name, version string) string {
+		return filepath.Join(pluginRootDir, name, version+"_abc_global")
+	}
+	for _, name := range []string{"foo", "bar"} {
+		for _, v := range []string{"v1.0.0", "v1.1.0"} {
+			assert.Nil(os.MkdirAll(filepath.Dir(pluginPath(name, v)), 0755))
+			assert.Nil(os.WriteFile(pluginPath(name, v), []byte("binary"), 0755))
+		}
+	}
+
+	// foo is installed, while bar was installed and then uninstalled
+	cc, err := NewContextCatalogUpdater("")
+	assert.Nil(err)
+	assert.Nil(cc.Upsert(&cli.PluginInfo{Name: "foo", InstallationPath: pluginPath("foo", "v1.1.0"), Version: "v1.1.0"}))
+	assert.Nil(cc.Upsert(&cli.PluginInfo{Name: "bar", InstallationPath: pluginPath("bar", "v1.1.0"), Version: "v1.1.0"}))
+	assert.Nil(cc.Delete("bar"))
+	cc.Unlock()
+
+	// Applying the retention policy only does not remove the plugins which are not installed
+	result, err := GarbageCollectPluginBinaries(GarbageCollectionOptions{RetainedVersions: 3, RetentionOnly: true})
+	assert.Nil(err)
+	assert.Empty(result.RemovedPaths)
+
+	// The retention policy keeps the older versions of the installed plugin only
+	result, err = GarbageCollectPluginBinaries(GarbageCollectionOptions{RetainedVersions: 3})
+	assert.Nil(err)
+	assert.ElementsMatch([]string{pluginPath("bar", "v1.0.0"), pluginPath("bar", "v1.1.0")}, result.RemovedPaths)
+	assert.NoDirExists(filepath.Join(pluginRootDir, "bar"))
+	assert.FileExists(pluginPath("foo", "v1.0.0"))
+	assert.FileExists(pluginPath("foo", "v1.1.0"))
+}
@@ -46,6 +46,18 @@ func (a Arch) IsWindows() bool {
 	return false
 }
 
+// EmulatedArch returns the arch whose binaries can run with emulation on the arch, i.e. amd64
+// for arm64 on darwin (Rosetta) and windows (Windows 11), or an empty arch if there is none.
+func (a Arch) EmulatedArch() Arch {
+	switch a {
+	case DarwinARM64:
+		return DarwinAMD64
+	case WinARM64:
+		return WinAMD64
+	}
+	return ""
+}
+
 // OS returns os-name based on the arch
 func (a Arch) OS() string {
 	ele := strings.Split(a.String(), "_")
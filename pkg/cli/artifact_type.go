@@ -0,0 +1,157 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/config"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+)
+
+// PluginArtifactType is the type of the artifact of an installed plugin
+type PluginArtifactType string
+
+const (
+	// PluginArtifactTypeBinary is a native executable of the platform
+	PluginArtifactTypeBinary PluginArtifactType = "binary"
+	// PluginArtifactTypeScript is an interpreted script declaring its runtime with a `#!` line
+	PluginArtifactTypeScript PluginArtifactType = "script"
+	// PluginArtifactTypeWASM is a WebAssembly module using the WebAssembly System Interface (WASI)
+	PluginArtifactTypeWASM PluginArtifactType = "wasm"
+)
+
+// DefaultWASMRuntime is the command used to execute the WebAssembly plugins
+// if none is configured with the TANZU_CLI_WASM_RUNTIME variable
+const DefaultWASMRuntime = "wasmtime run"
+
+var wasmMagic = []byte("\x00asm")
+
+// DetectPluginArtifactType returns the type of the plugin artifact based on its first bytes
+func DetectPluginArtifactType(content []byte) PluginArtifactType {
+	switch {
+	case bytes.HasPrefix(content, wasmMagic):
+		return PluginArtifactTypeWASM
+	case bytes.HasPrefix(content, []byte("#!")):
+		return PluginArtifactTypeScript
+	}
+	return PluginArtifactTypeBinary
+}
+
+// IsAlternatePluginArtifactTypesActivated returns true if the plugins can be distributed
+// as scripts executed with their declared runtime or as WebAssembly modules
+func IsAlternatePluginArtifactTypesActivated() bool {
+	return config.IsFeatureActivated(constants.FeatureAlternatePluginArtifactTypes)
+}
+
+// CheckPluginArtifactSupported returns an error if the plugin artifact cannot be executed
+// because its type requires the alternate plugin artifact types feature
+func CheckPluginArtifactSupported(content []byte) error {
+	if DetectPluginArtifactType(content) == PluginArtifactTypeWASM && !IsAlternatePluginArtifactTypesActivated() {
+		return errors.Errorf("the plugin is a WebAssembly module, which is only supported when the feature is activated with `tanzu config set %s true`", constants.FeatureAlternatePluginArtifactTypes)
+	}
+	return nil
+}
+
+// PluginCommandLine returns the executable and the arguments to run the installed plugin with the arguments.
+// The native binaries are executed directly. When the alternate plugin artifact types feature is activated,
+// the scripts are executed with the runtime declared by their `#!` line, which also allows to run them on
+// Windows, and the WebAssembly modules are executed with the runtime configured with TANZU_CLI_WASM_RUNTIME.
+func PluginCommandLine(pluginPath string, args ...string) (string, []string, error) {
+	header, err := readPluginHeader(pluginPath)
+	if err != nil {
+		// Let the execution report the error
+		return pluginPath, args, nil //nolint:nilerr
+	}
+
+	artifactType := DetectPluginArtifactType(header)
+	if artifactType == PluginArtifactTypeBinary {
+		return pluginPath, args, nil
+	}
+	if !IsAlternatePluginArtifactTypesActivated() {
+		if artifactType == PluginArtifactTypeWASM {
+			return "", nil, CheckPluginArtifactSupported(header)
+		}
+		// Scripts were always executed directly, relying on the operating system to honor their `#!` line
+		return pluginPath, args, nil
+	}
+
+	var runtime []string
+	if artifactType == PluginArtifactTypeWASM {
+		runtime = strings.Fields(os.Getenv(constants.ConfigVariableWASMRuntime))
+		if len(runtime) == 0 {
+			runtime = strings.Fields(DefaultWASMRuntime)
+		}
+	} else {
+		runtime = parseScriptRuntime(header)
+		if len(runtime) == 0 {
+			return "", nil, errors.Errorf("the plugin script %q does not declare its runtime", pluginPath)
+		}
+	}
+
+	executable, err := lookupRuntime(runtime[0])
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "unable to find the runtime %q of the plugin %q", runtime[0], pluginPath)
+	}
+	runtimeArgs := append([]string{}, runtime[1:]...)
+	runtimeArgs = append(runtimeArgs, pluginPath)
+	return executable, append(runtimeArgs, args...), nil
+}
+
+// readPluginHeader returns the first line of the plugin, which is enough to detect its type
+func readPluginHeader(pluginPath string) ([]byte, error) {
+	f, err := os.Open(pluginPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadSlice('\n')
+	if err != nil && len(line) == 0 {
+		return nil, err
+	}
+	return line, nil
+}
+
+// parseScriptRuntime returns the interpreter and the arguments declared by the `#!` line of the script.
+// The interpreters declared through `/usr/bin/env` are returned without it, e.g. `python3` for
+// `#!/usr/bin/env python3`, so that they are found in the PATH on all the platforms.
+func parseScriptRuntime(header []byte) []string {
+	line := strings.TrimSpace(strings.TrimPrefix(strings.SplitN(string(header), "\n", 2)[0], "#!"))
+	fields := strings.Fields(line)
+	if len(fields) > 0 && path.Base(fields[0]) == "env" {
+		fields = fields[1:]
+		if len(fields) > 0 && fields[0] == "-S" {
+			fields = fields[1:]
+		}
+	}
+	return fields
+}
+
+// lookupRuntime returns the path of the runtime executable. A runtime declared with an absolute
+// path which does not exist on this machine, e.g. `/bin/bash` on Windows, is looked up in the PATH.
+func lookupRuntime(runtime string) (string, error) {
+	if path.IsAbs(runtime) || filepath.IsAbs(runtime) {
+		if _, err := os.Stat(runtime); err == nil {
+			return runtime, nil
+		}
+	}
+	executable, err := exec.LookPath(runtime)
+	if err == nil {
+		return executable, nil
+	}
+	if strings.Contains(runtime, "/") {
+		return exec.LookPath(path.Base(runtime))
+	}
+	return "", err
+}
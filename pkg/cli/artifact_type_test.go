@@ -0,0 +1,117 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/config"
+)
+
+// setupArtifactTypeTest creates a temporary configuration, with the alternate plugin
+// artifact types feature activated if requested, and returns its directory
+func setupArtifactTypeTest(t *testing.T, activated bool) string {
+	dir, err := os.MkdirTemp("", "tanzu-cli-artifact-type")
+	assert.Nil(t, err)
+	t.Setenv("TANZU_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("TANZU_CONFIG_NEXT_GEN", filepath.Join(dir, "config-ng.yaml"))
+	if activated {
+		assert.Nil(t, config.SetFeature("global", "alternate-plugin-artifact-types-beta", "true"))
+	}
+	return dir
+}
+
+func TestDetectPluginArtifactType(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(PluginArtifactTypeBinary, DetectPluginArtifactType([]byte("\x7fELF\x02\x01\x01")))
+	assert.Equal(PluginArtifactTypeBinary, DetectPluginArtifactType(nil))
+	assert.Equal(PluginArtifactTypeScript, DetectPluginArtifactType([]byte("#!/usr/bin/env python3\nprint('hello')\n")))
+	assert.Equal(PluginArtifactTypeWASM, DetectPluginArtifactType([]byte("\x00asm\x01\x00\x00\x00")))
+}
+
+func TestParseScriptRuntime(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal([]string{"/bin/bash", "-e"}, parseScriptRuntime([]byte("#!/bin/bash -e\n")))
+	assert.Equal([]string{"python3"}, parseScriptRuntime([]byte("#!/usr/bin/env python3\n")))
+	assert.Equal([]string{"node", "--no-warnings"}, parseScriptRuntime([]byte("#! /usr/bin/env -S node --no-warnings\r\n")))
+	assert.Empty(parseScriptRuntime([]byte("#!\n")))
+}
+
+func TestPluginCommandLine(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := setupArtifactTypeTest(t, false)
+	defer os.RemoveAll(dir)
+
+	binaryPath := filepath.Join(dir, "binary")
+	assert.Nil(os.WriteFile(binaryPath, []byte("\x7fELF\x02\x01\x01"), 0755))
+	scriptPath := filepath.Join(dir, "script")
+	assert.Nil(os.WriteFile(scriptPath, []byte("#!/usr/bin/env sh\necho hello\n"), 0755))
+	wasmPath := filepath.Join(dir, "module")
+	assert.Nil(os.WriteFile(wasmPath, []byte("\x00asm\x01\x00\x00\x00"), 0755))
+
+	// The binaries and scripts are executed directly when the feature is not activated
+	executable, args, err := PluginCommandLine(binaryPath, "info")
+	assert.Nil(err)
+	assert.Equal(binaryPath, executable)
+	assert.Equal([]string{"info"}, args)
+
+	executable, args, err = PluginCommandLine(scriptPath, "info")
+	assert.Nil(err)
+	assert.Equal(scriptPath, executable)
+	assert.Equal([]string{"info"}, args)
+
+	_, _, err = PluginCommandLine(wasmPath, "info")
+	assert.ErrorContains(err, "the plugin is a WebAssembly module, which is only supported when the feature is activated")
+	assert.ErrorContains(CheckPluginArtifactSupported([]byte("\x00asm")), "features.global.alternate-plugin-artifact-types-beta")
+	assert.Nil(CheckPluginArtifactSupported([]byte("#!/bin/sh")))
+
+	// A missing plugin is reported when executed
+	executable, _, err = PluginCommandLine(filepath.Join(dir, "missing"))
+	assert.Nil(err)
+	assert.Equal(filepath.Join(dir, "missing"), executable)
+}
+
+func TestPluginCommandLineWithAlternateArtifactTypes(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := setupArtifactTypeTest(t, true)
+	defer os.RemoveAll(dir)
+
+	shPath, err := lookupRuntime("sh")
+	assert.Nil(err)
+
+	scriptPath := filepath.Join(dir, "script")
+	assert.Nil(os.WriteFile(scriptPath, []byte("#!/usr/bin/env sh\necho hello\n"), 0644))
+	executable, args, err := PluginCommandLine(scriptPath, "info")
+	assert.Nil(err)
+	assert.Equal(shPath, executable)
+	assert.Equal([]string{scriptPath, "info"}, args)
+
+	// The script is run with its runtime even if it is not executable
+	stdout, _, err := NewRunner("script", scriptPath, nil).RunOutput(context.Background())
+	assert.Nil(err)
+	assert.Equal("hello\n", stdout)
+
+	missingRuntimePath := filepath.Join(dir, "missing-runtime")
+	assert.Nil(os.WriteFile(missingRuntimePath, []byte("#!/usr/bin/env tanzu-missing-runtime\n"), 0644))
+	_, _, err = PluginCommandLine(missingRuntimePath)
+	assert.ErrorContains(err, `unable to find the runtime "tanzu-missing-runtime"`)
+
+	wasmPath := filepath.Join(dir, "module")
+	assert.Nil(os.WriteFile(wasmPath, []byte("\x00asm\x01\x00\x00\x00"), 0644))
+	t.Setenv("TANZU_CLI_WASM_RUNTIME", shPath+" -c")
+	executable, args, err = PluginCommandLine(wasmPath, "info")
+	assert.Nil(err)
+	assert.Equal(shPath, executable)
+	assert.Equal([]string{"-c", wasmPath, "info"}, args)
+	assert.Nil(CheckPluginArtifactSupported([]byte("\x00asm")))
+}
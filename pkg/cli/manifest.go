@@ -52,6 +52,42 @@ type Plugin struct {
 
 	// Versions available for plugin.
 	Versions []string `json:"versions" yaml:"versions"`
+
+	// ReleaseNotes are the optional release notes of the versions of the plugin, keyed by version.
+	ReleaseNotes map[string]PluginReleaseNotes `json:"releaseNotes,omitempty" yaml:"releaseNotes,omitempty"`
+
+	// MinCLIVersions are the optional minimum CLI versions required by the versions of the plugin, keyed by version.
+	MinCLIVersions map[string]string `json:"minCLIVersions,omitempty" yaml:"minCLIVersions,omitempty"`
+
+	// Licenses are the optional licenses of the versions of the plugin, keyed by version.
+	Licenses map[string]PluginLicense `json:"licenses,omitempty" yaml:"licenses,omitempty"`
+
+	// Deprecated tells whether the plugin is deprecated.
+	Deprecated bool `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+
+	// DeprecationMessage optionally explains why the plugin is deprecated.
+	DeprecationMessage string `json:"deprecationMessage,omitempty" yaml:"deprecationMessage,omitempty"`
+
+	// ReplacedBy is the optional name of the plugin replacing the deprecated plugin.
+	ReplacedBy string `json:"replacedBy,omitempty" yaml:"replacedBy,omitempty"`
+}
+
+// PluginReleaseNotes describes the changes introduced by a version of a plugin
+type PluginReleaseNotes struct {
+	// ChangelogURL is the URL of the changelog of the version.
+	ChangelogURL string `json:"changelogURL,omitempty" yaml:"changelogURL,omitempty"`
+
+	// Notes is a short summary of the changes of the version.
+	Notes string `json:"notes,omitempty" yaml:"notes,omitempty"`
+}
+
+// PluginLicense describes the license of a version of a plugin
+type PluginLicense struct {
+	// License is the license of the version, e.g. an SPDX identifier such as "Apache-2.0".
+	License string `json:"license,omitempty" yaml:"license,omitempty"`
+
+	// SBOMURI is the URI of the software bill of materials of the version.
+	SBOMURI string `json:"sbomURI,omitempty" yaml:"sbomURI,omitempty"`
 }
 
 // PluginGroupManifest is used to parse metadata about Plugin Groups
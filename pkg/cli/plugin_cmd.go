@@ -75,6 +75,9 @@ func getCmdForPluginEx(p *PluginInfo, cmdGroupName string) *cobra.Command {
 		Use:   cmdGroupName,
 		Short: p.Description,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if warning := p.DeprecationWarning(); warning != "" {
+				log.Warning(warning)
+			}
 			runner := NewRunner(p.Name, p.InstallationPath, args)
 			ctx := context.Background()
 			setupPluginEnv()
@@ -4,6 +4,9 @@
 package cli
 
 import (
+	"fmt"
+	"strings"
+
 	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/plugin"
 )
@@ -22,6 +25,10 @@ type PluginInfo struct {
 	// BuildSHA is the git commit hash the plugin was built with.
 	BuildSHA string `json:"buildSHA" yaml:"buildSHA"`
 
+	// PluginRuntimeVersion is the version of the tanzu-plugin-runtime library the plugin was built with.
+	// It is only reported by the plugins built with a recent version of the library.
+	PluginRuntimeVersion string `json:"pluginRuntimeVersion,omitempty" yaml:"pluginRuntimeVersion,omitempty"`
+
 	// Digest is the SHA256 hash of the plugin binary.
 	Digest string `json:"digest" yaml:"digest"`
 
@@ -68,6 +75,15 @@ type PluginInfo struct {
 	// Target specifies the target of the plugin
 	Target configtypes.Target `json:"target" yaml:"target"`
 
+	// Deprecated tells whether the plugin was deprecated in its discovery when it was installed
+	Deprecated bool `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+
+	// DeprecationMessage explains why the plugin is deprecated
+	DeprecationMessage string `json:"deprecationMessage,omitempty" yaml:"deprecationMessage,omitempty"`
+
+	// ReplacedBy is the name of the plugin replacing the deprecated plugin, if any
+	ReplacedBy string `json:"replacedBy,omitempty" yaml:"replacedBy,omitempty"`
+
 	// PostInstallHook is function to be run post install of a plugin.
 	PostInstallHook plugin.Hook `json:"-" yaml:"-"`
 
@@ -83,6 +99,28 @@ type PluginInfo struct {
 	SupportedContextType []configtypes.ContextType `json:"supportedContextType,omitempty" yaml:"supportedContextType,omitempty"`
 }
 
+// DeprecationWarning returns the warning to display to the users of the plugin if it is deprecated,
+// or an empty string otherwise.
+func (p *PluginInfo) DeprecationWarning() string {
+	if !p.Deprecated {
+		return ""
+	}
+	return PluginDeprecationWarning(p.Name, p.DeprecationMessage, p.ReplacedBy)
+}
+
+// PluginDeprecationWarning returns the warning to display to the users of a deprecated plugin,
+// pointing them to its replacement if there is one.
+func PluginDeprecationWarning(name, message, replacedBy string) string {
+	warning := fmt.Sprintf("Plugin '%s' is deprecated", name)
+	if message != "" {
+		warning += ": " + strings.TrimSuffix(message, ".")
+	}
+	if replacedBy != "" {
+		warning += fmt.Sprintf(". Please use plugin '%s' instead", replacedBy)
+	}
+	return warning
+}
+
 // PluginInfoSorter sorts PluginInfo objects.
 type PluginInfoSorter []PluginInfo
 
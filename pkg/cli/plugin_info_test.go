@@ -0,0 +1,39 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginInfoDeprecationWarning(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   PluginInfo
+		expected string
+	}{
+		{
+			name:     "not deprecated",
+			plugin:   PluginInfo{Name: "cluster", DeprecationMessage: "ignored"},
+			expected: "",
+		},
+		{
+			name:     "deprecated",
+			plugin:   PluginInfo{Name: "cluster", Deprecated: true},
+			expected: "Plugin 'cluster' is deprecated",
+		},
+		{
+			name:     "deprecated with a message and a replacement",
+			plugin:   PluginInfo{Name: "cluster", Deprecated: true, DeprecationMessage: "It is no longer maintained.", ReplacedBy: "kubernetes-cluster"},
+			expected: "Plugin 'cluster' is deprecated: It is no longer maintained. Please use plugin 'kubernetes-cluster' instead",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.plugin.DeprecationWarning())
+		})
+	}
+}
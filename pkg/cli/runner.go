@@ -75,7 +75,11 @@ func (r *Runner) run(ctx context.Context, pluginPath string, stdout, stderr *byt
 		return fmt.Errorf("%q is a directory", pluginPath)
 	}
 
-	cmd := exec.CommandContext(ctx, pluginPath, r.args...) //nolint:gosec
+	executable, args, err := PluginCommandLine(pluginPath, r.args...)
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, executable, args...) //nolint:gosec
 
 	cmd.Stdin = os.Stdin
 	// Check if the execution output should be captured
@@ -50,6 +50,8 @@ type Client interface {
 	VerifyCLIPluginCRD() (bool, error)
 	// GetCLIPluginImageRepositoryOverride returns map of image repository override
 	GetCLIPluginImageRepositoryOverride() (map[string]string, error)
+	// ListTanzuCLIPluginSetResources lists TanzuCLIPluginSet resources across all namespaces
+	ListTanzuCLIPluginSetResources() ([]cliv1alpha1.TanzuCLIPluginSet, error)
 
 	// BuildClusterQuery builds ClusterQuery with Dynamic client and Discovery client
 	BuildClusterQuery() (*capdiscovery.ClusterQuery, error)
@@ -172,6 +174,16 @@ func (c *client) ListCLIPluginResources() ([]cliv1alpha1.CLIPlugin, error) {
 	return cliPlugins.Items, nil
 }
 
+// ListTanzuCLIPluginSetResources lists TanzuCLIPluginSet resources across all namespaces
+func (c *client) ListTanzuCLIPluginSetResources() ([]cliv1alpha1.TanzuCLIPluginSet, error) {
+	var pluginSets cliv1alpha1.TanzuCLIPluginSetList
+	err := c.CrtClient.ListObjects(context.TODO(), &pluginSets, &crtclient.ListOptions{Namespace: ""})
+	if err != nil {
+		return nil, err
+	}
+	return pluginSets.Items, nil
+}
+
 // GetCLIPluginImageRepositoryOverride returns map of image repository override
 func (c *client) GetCLIPluginImageRepositoryOverride() (map[string]string, error) {
 	cmList := &corev1.ConfigMapList{}
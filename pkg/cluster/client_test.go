@@ -70,6 +70,19 @@ var _ = Describe("New Cluster Client Tests", func() {
 				Expect(err).To(BeNil())
 			})
 		})
+		Context("when list plugin sets don't return any plugin sets", func() {
+			BeforeEach(func() {
+				discoveryClientFactoryFake.NewDiscoveryClientForConfigReturns(&discovery.DiscoveryClient{}, nil)
+				discoveryClientFactoryFake.ServerVersionReturns(nil, nil)
+				clusterClient, _ = cluster.NewClient(kubeconfigFile, "foo-context", nil, options)
+				crtClientFake.ListObjectsReturns(nil)
+			})
+			It("return empty plugin sets and no error", func() {
+				pluginSets, err := clusterClient.ListTanzuCLIPluginSetResources()
+				Expect(pluginSets).To(BeNil())
+				Expect(err).To(BeNil())
+			})
+		})
 		Context("when BuildClusterQuery() called", func() {
 			BeforeEach(func() {
 				discoveryClientFactoryFake.NewDiscoveryClientForConfigReturns(&discovery.DiscoveryClient{}, nil)
@@ -0,0 +1,292 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/config"
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/plugin"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginmanager"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginsupplier"
+)
+
+const (
+	defaultAPIListenAddress = "127.0.0.1:8080"
+
+	apiPluginSearchPath    = "/v1/plugins/search"
+	apiInstalledPluginPath = "/v1/plugins/installed"
+	apiContextsPath        = "/v1/contexts"
+	apiSourcesPath         = "/v1/sources"
+)
+
+var apiListenAddress string
+
+func newAPICmd() *cobra.Command {
+	var apiCmd = &cobra.Command{
+		Use:   "api",
+		Short: "Serve the CLI information over a local REST API",
+		Long: `Serve read-only information about the plugins, contexts and discovery sources
+of the CLI as JSON over HTTP, so that tools such as IDE extensions can query it
+without running CLI commands and parsing their output.`,
+		Annotations: map[string]string{
+			"group": string(plugin.SystemCmdGroup),
+		},
+	}
+	apiCmd.SetUsageFunc(cli.SubCmdUsageFunc)
+
+	apiCmd.AddCommand(
+		newServeAPICmd(),
+	)
+
+	return apiCmd
+}
+
+func newServeAPICmd() *cobra.Command {
+	var serveAPICmd = &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the read-only REST API on a loopback address",
+		Long: `Serve the read-only REST API on a loopback address until interrupted.
+The following GET endpoints are served:
+  ` + apiPluginSearchPath + `     plugins available for installation (query parameters: name, target)
+  ` + apiInstalledPluginPath + `  installed plugins
+  ` + apiContextsPath + `            contexts, without their credentials
+  ` + apiSourcesPath + `             plugin discovery sources`,
+		Example: `
+    # Serve the API on the default address
+    tanzu api serve
+
+    # Serve the API on a specific port
+    tanzu api serve --listen 127.0.0.1:9876`,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: noMoreCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateAPIListenAddress(apiListenAddress); err != nil {
+				return err
+			}
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return serveAPI(ctx, apiListenAddress)
+		},
+	}
+
+	serveAPICmd.Flags().StringVar(&apiListenAddress, "listen", defaultAPIListenAddress, "loopback address and port to listen on")
+
+	return serveAPICmd
+}
+
+// validateAPIListenAddress returns an error if the address is not a loopback host and port.
+// The API is not authenticated, so it must not be reachable from other machines.
+func validateAPIListenAddress(address string) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return errors.Wrapf(err, "invalid listen address %q", address)
+	}
+	if !isLoopbackHost(host) {
+		return errors.Errorf("invalid listen address %q, the API can only listen on a loopback address", address)
+	}
+	return nil
+}
+
+// isLoopbackHost returns true if the host is localhost or a loopback IP address
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// serveAPI serves the API on the address until the context is done
+func serveAPI(ctx context.Context, address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return errors.Wrapf(err, "unable to listen on %q", address)
+	}
+	server := &http.Server{
+		Handler:           newAPIHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	log.Infof("Serving the API on http://%s", listener.Addr().String())
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// newAPIHandler returns the handler of the API endpoints
+func newAPIHandler() http.Handler {
+	// The handlers read the configuration and the plugin inventories the same way as the
+	// CLI commands do, which are not meant to run concurrently within a process
+	var mutex sync.Mutex
+	handle := func(fn func(r *http.Request) (interface{}, int, error)) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				w.Header().Set("Allow", http.MethodGet)
+				writeAPIResponse(w, http.StatusMethodNotAllowed, apiError{Error: "method not allowed"})
+				return
+			}
+			// Reject the requests for other hosts, which could be sent by web pages
+			// to a DNS name resolving to the loopback address
+			host, _, err := net.SplitHostPort(r.Host)
+			if err != nil {
+				host = r.Host
+			}
+			if !isLoopbackHost(host) {
+				writeAPIResponse(w, http.StatusForbidden, apiError{Error: "forbidden host"})
+				return
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			body, status, err := fn(r)
+			if err != nil {
+				writeAPIResponse(w, status, apiError{Error: err.Error()})
+				return
+			}
+			writeAPIResponse(w, http.StatusOK, body)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(apiPluginSearchPath, handle(apiSearchPlugins))
+	mux.HandleFunc(apiInstalledPluginPath, handle(apiInstalledPlugins))
+	mux.HandleFunc(apiContextsPath, handle(apiContexts))
+	mux.HandleFunc(apiSourcesPath, handle(apiSources))
+	return mux
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+type apiPlugin struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Target      string   `json:"target"`
+	Latest      string   `json:"latest"`
+	Versions    []string `json:"versions"`
+}
+
+type apiContext struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Endpoint string `json:"endpoint,omitempty"`
+	Active   bool   `json:"active"`
+}
+
+type apiSource struct {
+	Name  string `json:"name"`
+	Image string `json:"image,omitempty"`
+}
+
+func writeAPIResponse(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func apiSearchPlugins(r *http.Request) (interface{}, int, error) {
+	targetStr := r.URL.Query().Get("target")
+	if !configtypes.IsValidTarget(targetStr, true, true) {
+		return nil, http.StatusBadRequest, errors.Errorf("invalid target %q, it must be one of '%s'", targetStr, common.TargetList)
+	}
+	criteria := &discovery.PluginDiscoveryCriteria{
+		Name:   r.URL.Query().Get("name"),
+		Target: configtypes.StringToTarget(targetStr),
+	}
+	discovered, err := pluginmanager.DiscoverStandalonePlugins(discovery.WithPluginDiscoveryCriteria(criteria))
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	sort.Sort(discovery.DiscoveredSorter(discovered))
+
+	plugins := []apiPlugin{}
+	for i := range discovered {
+		plugins = append(plugins, apiPlugin{
+			Name:        discovered[i].Name,
+			Description: discovered[i].Description,
+			Target:      string(discovered[i].Target),
+			Latest:      discovered[i].RecommendedVersion,
+			Versions:    discovered[i].SupportedVersions,
+		})
+	}
+	return plugins, http.StatusOK, nil
+}
+
+func apiInstalledPlugins(_ *http.Request) (interface{}, int, error) {
+	installed, err := pluginsupplier.GetInstalledPlugins()
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	sort.Sort(cli.PluginInfoSorter(installed))
+	if installed == nil {
+		installed = []cli.PluginInfo{}
+	}
+	return installed, http.StatusOK, nil
+}
+
+// apiContexts returns the contexts without their credentials, which must never be served
+func apiContexts(_ *http.Request) (interface{}, int, error) {
+	cfg, err := config.GetClientConfig()
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	ctxs := cfg.KnownContexts
+	sort.Sort(configtypes.ContextSorter(ctxs))
+
+	contexts := []apiContext{}
+	for _, ctx := range ctxs {
+		var ep string
+		if ctx.ContextType == configtypes.ContextTypeTMC && ctx.GlobalOpts != nil {
+			ep = ctx.GlobalOpts.Endpoint
+		} else if ctx.ClusterOpts != nil {
+			ep = ctx.ClusterOpts.Endpoint
+		}
+		contexts = append(contexts, apiContext{
+			Name:     ctx.Name,
+			Type:     string(ctx.ContextType),
+			Endpoint: ep,
+			Active:   ctx.Name == cfg.CurrentContext[ctx.ContextType],
+		})
+	}
+	return contexts, http.StatusOK, nil
+}
+
+func apiSources(_ *http.Request) (interface{}, int, error) { //nolint:unparam
+	// An error is returned when no discovery source is configured, which is not a failure of the request
+	discoverySources, _ := config.GetCLIDiscoverySources()
+	sources := []apiSource{}
+	for _, ds := range discoverySources {
+		if ds.OCI != nil {
+			sources = append(sources, apiSource{Name: ds.OCI.Name, Image: ds.OCI.Image})
+		}
+	}
+	return sources, http.StatusOK, nil
+}
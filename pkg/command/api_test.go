@@ -0,0 +1,124 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const apiTestConfig = `cli:
+  discoverySources:
+  - oci:
+      name: default
+      image: example.com/tanzu-cli/plugins/plugin-inventory:latest
+contexts:
+- name: tmc-ctx
+  target: mission-control
+  contextType: mission-control
+  globalOpts:
+    endpoint: tmc.example.com:443
+    auth:
+      accessToken: secret-access-token
+      refresh_token: secret-refresh-token
+- name: k8s-ctx
+  target: kubernetes
+  contextType: kubernetes
+  clusterOpts:
+    endpoint: https://k8s.example.com:6443
+    path: /tmp/kubeconfig
+    context: k8s-ctx
+currentContext:
+  kubernetes: k8s-ctx
+`
+
+func setupAPITestConfig(t *testing.T) {
+	configFile, err := os.CreateTemp("", "config")
+	assert.Nil(t, err)
+	t.Cleanup(func() { os.Remove(configFile.Name()) })
+	t.Setenv("TANZU_CONFIG", configFile.Name())
+	configFileNG, err := os.CreateTemp("", "config_ng")
+	assert.Nil(t, err)
+	t.Cleanup(func() { os.Remove(configFileNG.Name()) })
+	t.Setenv("TANZU_CONFIG_NEXT_GEN", configFileNG.Name())
+	assert.Nil(t, os.WriteFile(configFileNG.Name(), []byte(apiTestConfig), 0644))
+}
+
+func TestValidateAPIListenAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		errMsg  string
+	}{
+		{address: "127.0.0.1:8080"},
+		{address: "localhost:9876"},
+		{address: "[::1]:8080"},
+		{address: "0.0.0.0:8080", errMsg: "the API can only listen on a loopback address"},
+		{address: "example.com:8080", errMsg: "the API can only listen on a loopback address"},
+		{address: "127.0.0.1", errMsg: "invalid listen address"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.address, func(t *testing.T) {
+			err := validateAPIListenAddress(tc.address)
+			if tc.errMsg == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestAPIContexts(t *testing.T) {
+	setupAPITestConfig(t)
+
+	rec := httptest.NewRecorder()
+	newAPIHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://127.0.0.1:8080"+apiContextsPath, http.NoBody))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.NotContains(t, rec.Body.String(), "secret")
+
+	var contexts []apiContext
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &contexts))
+	assert.Equal(t, []apiContext{
+		{Name: "k8s-ctx", Type: "kubernetes", Endpoint: "https://k8s.example.com:6443", Active: true},
+		{Name: "tmc-ctx", Type: "mission-control", Endpoint: "tmc.example.com:443", Active: false},
+	}, contexts)
+}
+
+func TestAPISources(t *testing.T) {
+	setupAPITestConfig(t)
+
+	rec := httptest.NewRecorder()
+	newAPIHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://localhost:8080"+apiSourcesPath, http.NoBody))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var sources []apiSource
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &sources))
+	assert.Equal(t, []apiSource{{Name: "default", Image: "example.com/tanzu-cli/plugins/plugin-inventory:latest"}}, sources)
+}
+
+func TestAPIRejectedRequests(t *testing.T) {
+	setupAPITestConfig(t)
+	handler := newAPIHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "http://127.0.0.1:8080"+apiContextsPath, http.NoBody))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, http.MethodGet, rec.Header().Get("Allow"))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://attacker.example.com:8080"+apiContextsPath, http.NoBody))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "forbidden host")
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://127.0.0.1:8080"+apiPluginSearchPath+"?target=invalid", http.NoBody))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `invalid target \"invalid\"`)
+}
@@ -0,0 +1,86 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/component"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/plugin"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/imagecache"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+)
+
+func newCacheCmd() *cobra.Command {
+	var cacheCmd = &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the cache of the downloaded images",
+		Long: `Manage the local cache of the images downloaded by the CLI.
+The cache is shared by the plugin installations, the refresh of the plugin
+inventories and the creation of plugin bundles. The least recently used images
+are removed once the size of the cache exceeds the value of the
+TANZU_CLI_IMAGE_CACHE_MAX_SIZE_MB environment variable.`,
+		Annotations: map[string]string{
+			"group": string(plugin.SystemCmdGroup),
+		},
+	}
+	cacheCmd.SetUsageFunc(cli.SubCmdUsageFunc)
+
+	cacheCmd.AddCommand(
+		newListCacheCmd(),
+		newClearCacheCmd(),
+	)
+
+	return cacheCmd
+}
+
+func newListCacheCmd() *cobra.Command {
+	var listCacheCmd = &cobra.Command{
+		Use:               "list",
+		Short:             "List the images in the cache",
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: noMoreCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := imagecache.NewDefaultCache().Entries()
+			if err != nil {
+				return err
+			}
+			output := component.NewOutputWriterWithOptions(cmd.OutOrStdout(), outputFormat, []component.OutputWriterOption{}, "digest", "size", "last-used")
+			for _, entry := range entries {
+				output.AddRow(entry.Digest, utils.FormatBytes(entry.Size), entry.LastUsed.Format(time.RFC3339))
+			}
+			output.Render()
+			return nil
+		},
+	}
+
+	listCacheCmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format (yaml|json|table)")
+	utils.PanicOnErr(listCacheCmd.RegisterFlagCompletionFunc("output", completionGetOutputFormats))
+
+	return listCacheCmd
+}
+
+func newClearCacheCmd() *cobra.Command {
+	var clearCacheCmd = &cobra.Command{
+		Use:               "clear",
+		Short:             "Remove all the images from the cache",
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: noMoreCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := imagecache.NewDefaultCache().Clear(); err != nil {
+				return errors.Wrap(err, "unable to clear the image cache")
+			}
+			log.Success("cleared the image cache")
+			return nil
+		},
+	}
+
+	return clearCacheCmd
+}
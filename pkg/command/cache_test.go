@@ -0,0 +1,42 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/imagecache"
+)
+
+func TestCacheListAndClear(t *testing.T) {
+	assert := assert.New(t)
+
+	cacheDir, err := os.MkdirTemp("", "test-image-cache")
+	assert.Nil(err)
+	defer os.RemoveAll(cacheDir)
+	t.Setenv("TEST_CUSTOM_IMAGE_CACHE_DIR", cacheDir)
+
+	assert.Nil(imagecache.NewDefaultCache().Put("sha256:1234", map[string][]byte{"file": []byte("content")}))
+
+	var out bytes.Buffer
+	outputFormat = ""
+	cmd := newCacheCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"list"})
+	assert.Nil(cmd.Execute())
+	assert.Contains(out.String(), "sha256:1234")
+	assert.Contains(out.String(), "7 B")
+
+	cmd = newCacheCmd()
+	cmd.SetArgs([]string{"clear"})
+	assert.Nil(cmd.Execute())
+
+	entries, err := imagecache.NewDefaultCache().Entries()
+	assert.Nil(err)
+	assert.Empty(entries)
+}
@@ -5,6 +5,9 @@ package command
 
 import (
 	"github.com/spf13/cobra"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugincmdtree"
 )
 
 const (
@@ -23,6 +26,7 @@ const (
 	compTableOutput = "table\tOutput results in human-readable format"
 	compJSONOutput  = "json\tOutput results in JSON format"
 	compYAMLOutput  = "yaml\tOutput results in YAML format"
+	compCSVOutput   = "csv\tOutput results in CSV format"
 )
 
 // TODO(khouzam): move this to tanzu-plugin-runtime to be usable by plugins
@@ -43,3 +47,52 @@ func noMoreCompletions(_ *cobra.Command, _ []string, _ string) ([]string, cobra.
 func activeHelpNoMoreArgs(comps []string) []string {
 	return cobra.AppendActiveHelp(comps, "This command does not take any more arguments (but may accept flags).")
 }
+
+// setupPluginCommandTreeCompletion makes the plugin commands found under cmd complete the
+// subcommands of the plugins from their cached command trees, so that the plugins are only
+// executed to complete the flags and the arguments of their commands.
+// The command trees are cached by installation path, which is specific to every installed
+// plugin binary, so a tree never outlives the plugin version it was built for.
+func setupPluginCommandTreeCompletion(cmd *cobra.Command, plugins []cli.PluginInfo) {
+	pluginsByPath := make(map[string]*cli.PluginInfo, len(plugins))
+	for i := range plugins {
+		pluginsByPath[plugins[i].InstallationPath] = &plugins[i]
+	}
+	setupCommandTreeCompletion(cmd, pluginsByPath)
+}
+
+func setupCommandTreeCompletion(cmd *cobra.Command, pluginsByPath map[string]*cli.PluginInfo) {
+	for _, subCmd := range cmd.Commands() {
+		if !isPluginCommand(subCmd) {
+			setupCommandTreeCompletion(subCmd, pluginsByPath)
+			continue
+		}
+		p, exists := pluginsByPath[subCmd.Annotations["pluginInstallationPath"]]
+		if !exists || subCmd.ValidArgsFunction == nil {
+			continue
+		}
+		pluginCompletion := subCmd.ValidArgsFunction
+		subCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if cmdTree := getPluginCommandTree(p); cmdTree != nil {
+				if comps, ok := cmdTree.Complete(args, toComplete); ok {
+					return comps, cobra.ShellCompDirectiveNoFileComp
+				}
+			}
+			return pluginCompletion(cmd, args, toComplete)
+		}
+	}
+}
+
+// getPluginCommandTree returns the cached command tree of the plugin, built when the plugin was
+// installed, or nil if the tree is not available
+func getPluginCommandTree(p *cli.PluginInfo) *plugincmdtree.CommandNode {
+	cache, err := plugincmdtree.NewCache()
+	if err != nil {
+		return nil
+	}
+	cmdTree, err := cache.GetTree(p)
+	if err != nil {
+		return nil
+	}
+	return cmdTree
+}
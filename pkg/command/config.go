@@ -8,7 +8,9 @@ import (
 	"io"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -18,6 +20,7 @@ import (
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/plugin"
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginsupplier"
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
 )
@@ -26,8 +29,25 @@ import (
 const (
 	ConfigLiteralFeatures = "features"
 	ConfigLiteralEnv      = "env"
+	ConfigLiteralPlugin   = "plugin"
 )
 
+// pluginSetting is a setting of the plugin management of the CLI, set with 'plugin.<setting>'.
+// The settings are stored as the variables of the config file read by the CLI, so that they
+// can be overridden by the variables of the shell like any other variable of the config file.
+type pluginSetting struct {
+	// variable is the variable of the config file storing the setting
+	variable string
+	// parse validates the value of the setting and converts it to the value of the variable
+	parse func(value string) (string, error)
+}
+
+// pluginSettings are the settings supported with 'plugin.<setting>'
+var pluginSettings = map[string]pluginSetting{
+	// The time to live of the cached plugin inventories, before checking the digest of their image again
+	"source.cache-ttl": {variable: constants.ConfigVariablePluginDBCacheTTLSeconds, parse: parseCacheTTL},
+}
+
 func init() {
 	configCmd.SetUsageFunc(cli.SubCmdUsageFunc)
 	configCmd.AddCommand(
@@ -104,7 +124,7 @@ func warningForShadowedEnvVars(writer io.Writer) {
 var setConfigCmd = &cobra.Command{
 	Use:               "set PATH <value>",
 	Short:             "Set config values at the given PATH",
-	Long:              "Set config values at the given PATH. Supported PATH values: [features.global.<feature>, features.<plugin>.<feature>, env.<variable>]",
+	Long:              "Set config values at the given PATH. Supported PATH values: [features.global.<feature>, features.<plugin>.<feature>, env.<variable>, plugin.source.cache-ttl]",
 	ValidArgsFunction: completeSetConfig,
 	Example: `
     # Sets a custom CA cert for a proxy that requires it
@@ -112,7 +132,9 @@ var setConfigCmd = &cobra.Command{
     # Enables a specific plugin feature
     tanzu config set features.management-cluster.custom_nameservers true
     # Enables a general CLI feature
-    tanzu config set features.global.abcd true`,
+    tanzu config set features.global.abcd true
+    # Checks the plugin inventories for updates at most once every 2 hours
+    tanzu config set plugin.source.cache-ttl 2h`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) < 2 {
 			return errors.Errorf("both PATH and <value> are required")
@@ -154,6 +176,16 @@ func setConfiguration(pathParam, value string) error {
 			return errors.New("unable to parse config path parameter into two parts [" + strings.Join(paramArray, ".") + "]  (was expecting 'env.<variable>'")
 		}
 		return configlib.SetEnv(paramArray[1], value)
+	case ConfigLiteralPlugin:
+		setting, err := getPluginSetting(paramArray)
+		if err != nil {
+			return err
+		}
+		variableValue, err := setting.parse(value)
+		if err != nil {
+			return err
+		}
+		return configlib.SetEnv(setting.variable, variableValue)
 	default:
 		return errors.New("unsupported config path parameter [" + configLiteral + "] (was expecting 'features.<plugin>.<feature>' or 'env.<env_variable>')")
 	}
@@ -188,7 +220,7 @@ var initConfigCmd = &cobra.Command{
 var unsetConfigCmd = &cobra.Command{
 	Use:               "unset PATH",
 	Short:             "Unset config values at the given PATH",
-	Long:              "Unset config values at the given PATH. Supported PATH values: [features.global.<feature>, features.<plugin>.<feature>, env.<variable>]",
+	Long:              "Unset config values at the given PATH. Supported PATH values: [features.global.<feature>, features.<plugin>.<feature>, env.<variable>, plugin.source.cache-ttl]",
 	ValidArgsFunction: completeUnsetConfig,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) < 1 {
@@ -218,6 +250,12 @@ func unsetConfiguration(pathParam string) error {
 		return unsetFeatures(paramArray)
 	case ConfigLiteralEnv:
 		return unsetEnvs(paramArray)
+	case ConfigLiteralPlugin:
+		setting, err := getPluginSetting(paramArray)
+		if err != nil {
+			return err
+		}
+		return configlib.DeleteEnv(setting.variable)
 	default:
 		return errors.New("unsupported config path parameter [" + configLiteral + "] (was expecting 'features.<plugin>.<feature>' or 'env.<env_variable>')")
 	}
@@ -242,6 +280,38 @@ func unsetEnvs(paramArray []string) error {
 	return configlib.DeleteEnv(envVariable)
 }
 
+// getPluginSetting returns the plugin setting of the 'plugin.<setting>' path
+func getPluginSetting(paramArray []string) (*pluginSetting, error) {
+	name := strings.Join(paramArray[1:], ".")
+	setting, exists := pluginSettings[name]
+	if !exists {
+		supported := make([]string, 0, len(pluginSettings))
+		for supportedName := range pluginSettings {
+			supported = append(supported, ConfigLiteralPlugin+"."+supportedName)
+		}
+		sort.Strings(supported)
+		return nil, errors.Errorf("unsupported plugin setting [%s] (was expecting one of: %s)", strings.Join(paramArray, "."), strings.Join(supported, ", "))
+	}
+	return &setting, nil
+}
+
+// parseCacheTTL converts the cache TTL, a duration such as '90m' or a number of seconds,
+// to the number of seconds expected by the variable of the TTL
+func parseCacheTTL(value string) (string, error) {
+	seconds, err := strconv.Atoi(value)
+	if err != nil {
+		duration, durationErr := time.ParseDuration(value)
+		if durationErr != nil {
+			return "", errors.Errorf("invalid cache TTL %q, expecting a duration such as '90m' or a number of seconds", value)
+		}
+		seconds = int(duration.Seconds())
+	}
+	if seconds < 0 {
+		return "", errors.Errorf("invalid cache TTL %q, the TTL cannot be negative", value)
+	}
+	return strconv.Itoa(seconds), nil
+}
+
 // ====================================
 // Shell completion functions
 // ====================================
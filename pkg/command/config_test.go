@@ -12,6 +12,8 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	configlib "github.com/vmware-tanzu/tanzu-plugin-runtime/config"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
 )
 
 // Test_config_MalformedPathArg validates functionality when an invalid argument is provided.
@@ -125,6 +127,65 @@ func TestConfigFeatureFlags(t *testing.T) {
 	}
 }
 
+// TestConfigPluginSettings validates set and unset functionality when plugin setting path argument is provided.
+func TestConfigPluginSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		value    string
+		expected string
+		errStr   string
+	}{
+		{
+			name:     "should set the cache TTL as a duration",
+			key:      "plugin.source.cache-ttl",
+			value:    "2h",
+			expected: "7200",
+		},
+		{
+			name:     "should set the cache TTL as a number of seconds",
+			key:      "plugin.source.cache-ttl",
+			value:    "600",
+			expected: "600",
+		},
+		{
+			name:   "should not set an invalid cache TTL",
+			key:    "plugin.source.cache-ttl",
+			value:  "soon",
+			errStr: `invalid cache TTL "soon", expecting a duration such as '90m' or a number of seconds`,
+		},
+		{
+			name:   "should not set a negative cache TTL",
+			key:    "plugin.source.cache-ttl",
+			value:  "-1m",
+			errStr: `invalid cache TTL "-1m", the TTL cannot be negative`,
+		},
+		{
+			name:   "should not set an unknown plugin setting",
+			key:    "plugin.source.foo",
+			value:  "bar",
+			errStr: "unsupported plugin setting [plugin.source.foo] (was expecting one of: plugin.source.cache-ttl)",
+		},
+	}
+	for _, spec := range tests {
+		t.Run(spec.name, func(t *testing.T) {
+			err := setConfiguration(spec.key, spec.value)
+			if spec.errStr != "" {
+				assert.EqualError(t, err, spec.errStr)
+				return
+			}
+			assert.NoError(t, err)
+			value, err := configlib.GetEnv(constants.ConfigVariablePluginDBCacheTTLSeconds)
+			assert.NoError(t, err)
+			assert.Equal(t, spec.expected, value)
+
+			assert.NoError(t, unsetConfiguration(spec.key))
+			_, err = configlib.GetEnv(constants.ConfigVariablePluginDBCacheTTLSeconds)
+			assert.Error(t, err)
+		})
+	}
+}
+
 func TestCompletionConfig(t *testing.T) {
 	// Setup a temporary configuration
 	configFile, err := os.CreateTemp("", "config")
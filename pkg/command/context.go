@@ -273,8 +273,10 @@ func createCtx(cmd *cobra.Command, args []string) (err error) {
 		return err
 	}
 
-	// Sync all required plugins
-	_ = syncContextPlugins(cmd, ctx.ContextType, ctxName, true)
+	// Sync all required plugins, unless they are downloaded in the background
+	if !startBackgroundPluginPrefetch(ctxName) {
+		_ = syncContextPlugins(cmd, ctx.ContextType, ctxName, true)
+	}
 
 	return nil
 }
@@ -692,10 +694,7 @@ func updateTanzuContextMetadata(c *configtypes.Context, orgID, orgName string) e
 // getCSPOrganizationName returns the CSP Org name using the orgID from the claims.
 // It will return empty string if API fails
 func getCSPOrganizationName(c *configtypes.Context, claims *csp.Claims) (string, error) {
-	issuer := csp.ProdIssuer
-	if staging {
-		issuer = csp.StgIssuer
-	}
+	issuer := csp.GetIssuer(staging)
 	orgName, err := csp.GetOrgNameFromOrgID(claims.OrgID, c.GlobalOpts.Auth.AccessToken, issuer)
 	if err != nil {
 		return "", err
@@ -727,10 +726,7 @@ func doCSPAuthentication(c *configtypes.Context) (*csp.Claims, error) {
 }
 
 func doCSPInteractiveLoginAndUpdateContext(c *configtypes.Context) (claims *csp.Claims, err error) {
-	issuer := csp.ProdIssuer
-	if staging {
-		issuer = csp.StgIssuer
-	}
+	issuer := csp.GetIssuer(staging)
 	cspOrgIDValue, cspOrgIDExists := os.LookupEnv(constants.CSPLoginOrgID)
 	var loginOptions []csp.LoginOption
 	if cspOrgIDExists && cspOrgIDValue != "" {
@@ -766,10 +762,7 @@ func doCSPInteractiveLoginAndUpdateContext(c *configtypes.Context) (claims *csp.
 }
 
 func doCSPAPITokenAuthAndUpdateContext(c *configtypes.Context, apiTokenValue string) (claims *csp.Claims, err error) {
-	issuer := csp.ProdIssuer
-	if staging {
-		issuer = csp.StgIssuer
-	}
+	issuer := csp.GetIssuer(staging)
 	token, err := csp.GetAccessTokenFromAPIToken(apiTokenValue, issuer)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get the token from CSP")
@@ -1247,8 +1240,10 @@ func useCtx(cmd *cobra.Command, args []string) error { //nolint:gocyclo
 
 	log.Infof("Activated context '%s' %s ", ctxName, suffixString)
 
-	// Sync all required plugins
-	_ = syncContextPlugins(cmd, ctx.ContextType, ctxName, true)
+	// Sync all required plugins, unless they are downloaded in the background
+	if !startBackgroundPluginPrefetch(ctxName) {
+		_ = syncContextPlugins(cmd, ctx.ContextType, ctxName, true)
+	}
 
 	return nil
 }
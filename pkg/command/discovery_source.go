@@ -5,13 +5,18 @@ package command
 
 import (
 	"fmt"
+	"os"
+	"path"
+	"path/filepath"
 	"sort"
 
 	"github.com/pkg/errors"
 
+	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/config"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginmanager"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
 
@@ -26,6 +31,8 @@ import (
 
 var (
 	uri string
+	// Can be overridden for unit testing
+	imageProcessorForSourceDiff = carvelhelpers.NewImageOperationsImpl()
 )
 
 func newDiscoverySourceCmd() *cobra.Command {
@@ -41,6 +48,8 @@ func newDiscoverySourceCmd() *cobra.Command {
 		newUpdateDiscoverySourceCmd(),
 		newDeleteDiscoverySourceCmd(),
 		newInitDiscoverySourceCmd(),
+		newExportDiscoverySourceCmd(),
+		newDiffDiscoverySourceCmd(),
 	)
 
 	return discoverySourceCmd
@@ -197,6 +206,161 @@ func newInitDiscoverySourceCmd() *cobra.Command {
 	return initDiscoverySourceCmd
 }
 
+func newExportDiscoverySourceCmd() *cobra.Command {
+	var exportDiscoverySourceCmd = &cobra.Command{
+		Use:   "export SOURCE_NAME",
+		Short: "Export the plugin inventory of a discovery source",
+		Long:  "Export all the plugins, with their versions and artifacts, and all the plugin groups of the plugin inventory of a discovery source, including the deactivated ones, for auditing or comparing plugin repositories. The JSON and YAML exports can also be used as the manifest of a file-based plugin inventory.",
+		Example: `
+    # Export the plugin inventory of the default discovery source as YAML
+    tanzu plugin source export default
+
+    # Export the plugin inventory of the default discovery source as CSV to a file
+    tanzu plugin source export default -o csv > inventory.csv`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeDiscoverySources,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			discoveryName := args[0]
+
+			format := plugininventory.ExportFormatYAML
+			if outputFormat != "" {
+				format = plugininventory.ExportFormat(outputFormat)
+			}
+			if !isExportFormat(format) {
+				return fmt.Errorf("invalid output format %q, the supported formats are %v", outputFormat, plugininventory.ExportFormats)
+			}
+
+			discoverySource, _ := configlib.GetCLIDiscoverySource(discoveryName)
+			if discoverySource == nil {
+				return fmt.Errorf("discovery %q does not exist", discoveryName)
+			}
+			discObject, err := discovery.CreateDiscoveryFromV1alpha1(*discoverySource)
+			if err != nil {
+				return err
+			}
+			inventoryDiscovery, ok := discObject.(discovery.InventoryDiscovery)
+			if !ok {
+				return fmt.Errorf("discovery %q does not have a plugin inventory to export", discoveryName)
+			}
+			inventory, err := inventoryDiscovery.Inventory()
+			if err != nil {
+				return err
+			}
+			return inventory.Export(cmd.Context(), cmd.OutOrStdout(), format)
+		},
+	}
+
+	exportDiscoverySourceCmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format (yaml|json|csv)")
+	utils.PanicOnErr(exportDiscoverySourceCmd.RegisterFlagCompletionFunc("output", completionGetExportFormats))
+
+	return exportDiscoverySourceCmd
+}
+
+// isExportFormat returns true if the format is one of the formats of a plugin inventory export
+func isExportFormat(format plugininventory.ExportFormat) bool {
+	for _, f := range plugininventory.ExportFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+func newDiffDiscoverySourceCmd() *cobra.Command {
+	var diffDiscoverySourceCmd = &cobra.Command{
+		Use:   "diff OLD_IMAGE NEW_IMAGE",
+		Short: "Show the differences between two plugin inventory images",
+		Long:  "Show the plugin versions and plugin group versions which were added, removed or changed in the plugin inventory image NEW_IMAGE compared to OLD_IMAGE, including the deactivated ones. The artifacts of the plugins are compared by digest, so that a repository can be compared with its mirror.",
+		Example: `
+    # Review the changes of the central repository before mirroring it to an air-gapped repository
+    tanzu plugin source diff registry.example.com/tanzu/plugin-inventory:latest projects.packages.broadcom.com/tanzu_cli/plugins/plugin-inventory:latest`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeDiffDiscoverySource,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			oldInventory, cleanup, err := downloadInventoryImage(args[0])
+			defer cleanup()
+			if err != nil {
+				return err
+			}
+			newInventory, cleanupNew, err := downloadInventoryImage(args[1])
+			defer cleanupNew()
+			if err != nil {
+				return err
+			}
+
+			diff, err := plugininventory.DiffInventories(cmd.Context(), oldInventory, newInventory)
+			if err != nil {
+				return err
+			}
+			displayInventoryDiff(diff, cmd)
+			return nil
+		},
+	}
+
+	diffDiscoverySourceCmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format (yaml|json|table)")
+	utils.PanicOnErr(diffDiscoverySourceCmd.RegisterFlagCompletionFunc("output", completionGetOutputFormats))
+
+	return diffDiscoverySourceCmd
+}
+
+func completeDiffDiscoverySource(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return cobra.AppendActiveHelp(nil, "Please enter the uri of the old plugin inventory image"), cobra.ShellCompDirectiveNoFileComp
+	case 1:
+		return cobra.AppendActiveHelp(nil, "Please enter the uri of the new plugin inventory image"), cobra.ShellCompDirectiveNoFileComp
+	}
+	return activeHelpNoMoreArgs(nil), cobra.ShellCompDirectiveNoFileComp
+}
+
+// downloadInventoryImage downloads the plugin inventory image to a temporary directory,
+// and returns its inventory and the function removing the directory
+func downloadInventoryImage(image string) (plugininventory.PluginInventory, func(), error) {
+	dir, err := os.MkdirTemp("", "")
+	if err != nil {
+		return nil, func() {}, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+	if err := imageProcessorForSourceDiff.DownloadImageAndSaveFilesToDir(image, dir); err != nil {
+		return nil, cleanup, errors.Wrapf(err, "failed to download the plugin inventory image '%s'", image)
+	}
+	return plugininventory.NewSQLiteInventory(filepath.Join(dir, plugininventory.SQliteDBFileName), path.Dir(image)), cleanup, nil
+}
+
+// displayInventoryDiff displays a row for each plugin version and plugin group version
+// which was added or removed, and for each change of a changed one
+func displayInventoryDiff(diff *plugininventory.InventoryDiff, cmd *cobra.Command) {
+	output := component.NewOutputWriterWithOptions(cmd.OutOrStdout(), outputFormat, []component.OutputWriterOption{}, "kind", "name", "target", "version", "status", "change")
+	addPluginRows := func(plugins []plugininventory.PluginVersionDiff, status string) {
+		for _, p := range plugins {
+			if len(p.Changes) == 0 {
+				output.AddRow("plugin", p.Name, string(p.Target), p.Version, status, "")
+			}
+			for _, change := range p.Changes {
+				output.AddRow("plugin", p.Name, string(p.Target), p.Version, status, change)
+			}
+		}
+	}
+	addGroupRows := func(groups []plugininventory.PluginGroupVersionDiff, status string) {
+		for _, g := range groups {
+			id := fmt.Sprintf("%s-%s/%s", g.Vendor, g.Publisher, g.Name)
+			if len(g.Changes) == 0 {
+				output.AddRow("plugin-group", id, "", g.Version, status, "")
+			}
+			for _, change := range g.Changes {
+				output.AddRow("plugin-group", id, "", g.Version, status, change)
+			}
+		}
+	}
+	addPluginRows(diff.AddedPlugins, "added")
+	addPluginRows(diff.RemovedPlugins, "removed")
+	addPluginRows(diff.ChangedPlugins, "changed")
+	addGroupRows(diff.AddedPluginGroups, "added")
+	addGroupRows(diff.RemovedPluginGroups, "removed")
+	addGroupRows(diff.ChangedPluginGroups, "changed")
+	output.Render()
+}
+
 func createDiscoverySource(dsName, uri string) (configtypes.PluginDiscovery, error) {
 	pluginDiscoverySource := configtypes.PluginDiscovery{}
 
@@ -249,6 +413,10 @@ func completeDiscoverySources(_ *cobra.Command, args []string, _ string) ([]stri
 	return comps, cobra.ShellCompDirectiveNoFileComp
 }
 
+func completionGetExportFormats(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	return []string{compYAMLOutput, compJSONOutput, compCSVOutput}, cobra.ShellCompDirectiveNoFileComp
+}
+
 func completeUpdateDiscoverySource(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	if len(args) > 0 && uri == "" {
 		// The --uri flag is required, so completion will be provided for it
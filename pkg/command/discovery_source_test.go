@@ -5,6 +5,7 @@ package command
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"os"
 	"path/filepath"
@@ -12,11 +13,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/config"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/fakes"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
 	configlib "github.com/vmware-tanzu/tanzu-plugin-runtime/config"
 	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
@@ -484,6 +488,190 @@ func Test_deleteDiscoverySource(t *testing.T) {
 	os.Unsetenv(constants.EULAPromptAnswer)
 }
 
+func Test_exportDiscoverySource(t *testing.T) {
+	tests := []struct {
+		test            string
+		args            []string
+		expected        []string
+		expectedFailure bool
+	}{
+		{
+			test:            "export missing arg error",
+			args:            []string{"plugin", "source", "export"},
+			expectedFailure: true,
+			expected:        []string{"accepts 1 arg(s), received 0"},
+		},
+		{
+			test:            "export invalid source",
+			args:            []string{"plugin", "source", "export", "invalid"},
+			expectedFailure: true,
+			expected:        []string{`discovery "invalid" does not exist`},
+		},
+		{
+			test:            "export invalid format",
+			args:            []string{"plugin", "source", "export", "default", "-o", "table"},
+			expectedFailure: true,
+			expected:        []string{`invalid output format "table", the supported formats are [json yaml csv]`},
+		},
+		{
+			test:     "export as yaml by default",
+			args:     []string{"plugin", "source", "export", "default"},
+			expected: []string{"plugins:\n  - name: cluster\n", "pluginGroups:\n  - vendor: vmware\n    publisher: tkg\n    name: default\n"},
+		},
+		{
+			test:     "export as json",
+			args:     []string{"plugin", "source", "export", "default", "-o", "json"},
+			expected: []string{`"name": "management-cluster"`, `"pluginGroups": [`},
+		},
+		{
+			test:     "export as csv",
+			args:     []string{"plugin", "source", "export", "default", "-o", "csv"},
+			expected: []string{"plugin-group,plugin-group-version,name,target,version,vendor,publisher,os,arch,digest,image,hidden,mandatory\n", "vmware-tkg/default,v1.1.1,management-cluster,kubernetes,v0.1.0,,,,,,,false,true\n"},
+		},
+	}
+
+	// Setup a plugin source and a set of installed plugins
+	defer setupPluginSourceForTesting(t)()
+
+	// For these tests, we force using the cache.
+	// Normal behavior of the CLI verifies the cache validity
+	// which we don't want for unit tests.
+	os.Setenv("TEST_TANZU_CLI_USE_DB_CACHE_ONLY", "1")
+
+	for _, spec := range tests {
+		t.Run(spec.test, func(t *testing.T) {
+			assert := assert.New(t)
+
+			rootCmd, err := NewRootCmd()
+			assert.Nil(err)
+
+			var out bytes.Buffer
+			rootCmd.SetOut(&out)
+			rootCmd.SetArgs(spec.args)
+
+			err = rootCmd.Execute()
+			assert.Equal(err != nil, spec.expectedFailure)
+			for _, expected := range spec.expected {
+				if spec.expectedFailure {
+					assert.Contains(err.Error(), expected)
+				} else {
+					assert.Contains(out.String(), expected)
+				}
+			}
+
+			resetPluginCommandFlags()
+		})
+	}
+
+	os.Unsetenv("TEST_TANZU_CLI_USE_DB_CACHE_ONLY")
+}
+
+func Test_diffDiscoverySource(t *testing.T) {
+	tests := []struct {
+		test            string
+		args            []string
+		expected        []string
+		expectedFailure bool
+	}{
+		{
+			test:            "diff missing arg error",
+			args:            []string{"plugin", "source", "diff", "old/plugin-inventory:latest"},
+			expectedFailure: true,
+			expected:        []string{"accepts 2 arg(s), received 1"},
+		},
+		{
+			test:            "diff image not found",
+			args:            []string{"plugin", "source", "diff", "old/plugin-inventory:latest", "invalid/plugin-inventory:latest"},
+			expectedFailure: true,
+			expected:        []string{"failed to download the plugin inventory image 'invalid/plugin-inventory:latest'"},
+		},
+		{
+			test:     "diff same image",
+			args:     []string{"plugin", "source", "diff", "old/plugin-inventory:latest", "old/plugin-inventory:latest", "-o", "json"},
+			expected: []string{"[]"},
+		},
+		{
+			test:     "diff as table",
+			args:     []string{"plugin", "source", "diff", "old/plugin-inventory:latest", "new/plugin-inventory:latest"},
+			expected: []string{"KIND", "CHANGE", "plugin  login  global  v1.0.0   added"},
+		},
+		{
+			test:     "diff as yaml",
+			args:     []string{"plugin", "source", "diff", "old/plugin-inventory:latest", "new/plugin-inventory:latest", "-o", "yaml"},
+			expected: []string{"- change: \"\"\n  kind: plugin\n  name: login\n  status: added\n  target: global\n  version: v1.0.0\n"},
+		},
+	}
+
+	// Setup a plugin source and a set of installed plugins
+	defer setupPluginSourceForTesting(t)()
+
+	// The old inventory image contains the test inventory DB,
+	// and the new one also contains a new plugin
+	oldDB := filepath.Join(common.DefaultCacheDir, common.PluginInventoryDirName, config.DefaultStandaloneDiscoveryName, plugininventory.SQliteDBFileName)
+	newDir, err := os.MkdirTemp("", "")
+	assert.Nil(t, err)
+	defer os.RemoveAll(newDir)
+	newDB := filepath.Join(newDir, plugininventory.SQliteDBFileName)
+	copyFile(t, oldDB, newDB)
+	err = plugininventory.NewSQLiteInventory(newDB, "new").InsertPlugin(context.Background(), &plugininventory.PluginInventoryEntry{
+		Name:      "login",
+		Target:    configtypes.TargetGlobal,
+		Publisher: "test",
+		Vendor:    "vmware",
+		Artifacts: distribution.Artifacts{
+			"v1.0.0": []distribution.Artifact{{OS: "linux", Arch: "amd64", Digest: "0000000000", Image: "vmware/test/linux/amd64/global/login:v1.0.0"}},
+		},
+	})
+	assert.Nil(t, err)
+
+	fakeImageProcessor := &fakes.ImageOperationsImpl{}
+	fakeImageProcessor.DownloadImageAndSaveFilesToDirCalls(func(image, dir string) error {
+		switch image {
+		case "old/plugin-inventory:latest":
+			copyFile(t, oldDB, filepath.Join(dir, plugininventory.SQliteDBFileName))
+		case "new/plugin-inventory:latest":
+			copyFile(t, newDB, filepath.Join(dir, plugininventory.SQliteDBFileName))
+		default:
+			return errors.New("image not found")
+		}
+		return nil
+	})
+	imageProcessor := imageProcessorForSourceDiff
+	imageProcessorForSourceDiff = fakeImageProcessor
+	defer func() { imageProcessorForSourceDiff = imageProcessor }()
+
+	for _, spec := range tests {
+		t.Run(spec.test, func(t *testing.T) {
+			assert := assert.New(t)
+
+			rootCmd, err := NewRootCmd()
+			assert.Nil(err)
+
+			var out bytes.Buffer
+			rootCmd.SetOut(&out)
+			rootCmd.SetArgs(spec.args)
+
+			err = rootCmd.Execute()
+			assert.Equal(err != nil, spec.expectedFailure)
+			for _, expected := range spec.expected {
+				if spec.expectedFailure {
+					assert.Contains(err.Error(), expected)
+				} else {
+					assert.Contains(out.String(), expected)
+				}
+			}
+
+			resetPluginCommandFlags()
+		})
+	}
+}
+
+func copyFile(t *testing.T, src, dst string) {
+	b, err := os.ReadFile(src)
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(dst, b, 0o600))
+}
+
 func TestCompletionPluginSource(t *testing.T) {
 	// This is global logic and needs not be tested for each
 	// command.  Let's deactivate it.
@@ -551,6 +739,52 @@ func TestCompletionPluginSource(t *testing.T) {
 			expected: "_activeHelp_ Please enter the uri of the OCI image for plugin discovery\n:4\n",
 		},
 		// ==========================
+		// tanzu plugin source export
+		// ==========================
+		{
+			test: "completion for the source export command",
+			args: []string{"__complete", "plugin", "source", "export", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "default\texample.com/tanzu_cli/plugins/plugin-inventory:latest\n" +
+				":4\n",
+		},
+		{
+			test: "completion for the --output flag value of the source export command",
+			args: []string{"__complete", "plugin", "source", "export", "default", "--output", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "yaml\tOutput results in YAML format\n" +
+				"json\tOutput results in JSON format\n" +
+				"csv\tOutput results in CSV format\n" +
+				":4\n",
+		},
+		// ========================
+		// tanzu plugin source diff
+		// ========================
+		{
+			test: "completion for the old image of the source diff command",
+			args: []string{"__complete", "plugin", "source", "diff", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ Please enter the uri of the old plugin inventory image\n:4\n",
+		},
+		{
+			test: "completion for the new image of the source diff command",
+			args: []string{"__complete", "plugin", "source", "diff", "old/plugin-inventory:latest", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ Please enter the uri of the new plugin inventory image\n:4\n",
+		},
+		{
+			test: "no completion after the new image of the source diff command",
+			args: []string{"__complete", "plugin", "source", "diff", "old/plugin-inventory:latest", "new/plugin-inventory:latest", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ " + compNoMoreArgsMsg + "\n:4\n",
+		},
+		{
+			test: "completion for the --output flag value of the source diff command",
+			args: []string{"__complete", "plugin", "source", "diff", "old/plugin-inventory:latest", "new/plugin-inventory:latest", "--output", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: expectedOutForOutputFlag + ":4\n",
+		},
+		// ==========================
 		// tanzu plugin source delete
 		// ==========================
 
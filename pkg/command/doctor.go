@@ -0,0 +1,84 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/plugin"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/supportbundle"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+)
+
+type doctorBundleOptions struct {
+	tarFile   string
+	logsSince time.Duration
+}
+
+var dbo doctorBundleOptions
+
+func newDoctorCmd() *cobra.Command {
+	var doctorCmd = &cobra.Command{
+		Use:   "doctor",
+		Short: "Troubleshoot the Tanzu CLI installation",
+		Annotations: map[string]string{
+			"group": string(plugin.SystemCmdGroup),
+		},
+	}
+	doctorCmd.SetUsageFunc(cli.SubCmdUsageFunc)
+
+	doctorCmd.AddCommand(
+		newDoctorBundleCmd(),
+	)
+
+	return doctorCmd
+}
+
+func newDoctorBundleCmd() *cobra.Command {
+	var bundleCmd = &cobra.Command{
+		Use:   "bundle",
+		Short: "Collect the CLI state into a support bundle",
+		Long: `Collect the plugin catalog, the CLI configuration, the plugin command tree cache
+and the recent log files into a single archive that can be shared with support.
+Secrets such as tokens and passwords are redacted from the configuration.`,
+		Example: `
+    # Create a support bundle
+    tanzu doctor bundle --to-tar /tmp/tanzu_support_bundle.tar.gz
+
+    # Create a support bundle including the log files of the last 24 hours only
+    tanzu doctor bundle --to-tar /tmp/tanzu_support_bundle.tar.gz --logs-since 24h`,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: noMoreCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if dbo.tarFile == "" {
+				return errors.New("flag '--to-tar' is required")
+			}
+			options := supportbundle.CreateSupportBundleOptions{
+				ToTar:     dbo.tarFile,
+				LogsSince: dbo.logsSince,
+			}
+			if err := options.CreateSupportBundle(); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Support bundle saved to %q\n", dbo.tarFile)
+			return nil
+		},
+	}
+
+	f := bundleCmd.Flags()
+	// Shell completion for this flag is the default behavior of doing file completion
+	f.StringVarP(&dbo.tarFile, "to-tar", "", "", "local tar file path to store the support bundle")
+	f.DurationVarP(&dbo.logsSince, "logs-since", "", supportbundle.DefaultLogsSince, "only include the log files modified within this duration")
+	utils.PanicOnErr(bundleCmd.RegisterFlagCompletionFunc("logs-since", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return cobra.AppendActiveHelp(nil, "Please enter a duration such as 24h"), cobra.ShellCompDirectiveNoFileComp
+	}))
+
+	return bundleCmd
+}
@@ -0,0 +1,49 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoctorBundleMissingTarFile(t *testing.T) {
+	assert := assert.New(t)
+
+	dbo = doctorBundleOptions{}
+	cmd := newDoctorCmd()
+	cmd.SetArgs([]string{"bundle"})
+	err := cmd.Execute()
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "flag '--to-tar' is required")
+}
+
+func TestDoctorBundle(t *testing.T) {
+	assert := assert.New(t)
+
+	cacheDir, err := os.MkdirTemp("", "test-doctor-cache")
+	assert.Nil(err)
+	defer os.RemoveAll(cacheDir)
+	t.Setenv("TEST_CUSTOM_CATALOG_CACHE_DIR", cacheDir)
+	t.Setenv("TEST_CUSTOM_PLUGIN_COMMAND_TREE_CACHE_DIR", cacheDir)
+
+	configFile, err := os.CreateTemp("", "config")
+	assert.Nil(err)
+	defer os.Remove(configFile.Name())
+	t.Setenv("TANZU_CONFIG", configFile.Name())
+	configFileNG, err := os.CreateTemp("", "config_ng")
+	assert.Nil(err)
+	defer os.Remove(configFileNG.Name())
+	t.Setenv("TANZU_CONFIG_NEXT_GEN", configFileNG.Name())
+
+	tarFile := filepath.Join(cacheDir, "bundle.tar.gz")
+	dbo = doctorBundleOptions{}
+	cmd := newDoctorCmd()
+	cmd.SetArgs([]string{"bundle", "--to-tar", tarFile})
+	assert.Nil(cmd.Execute())
+	assert.FileExists(tarFile)
+}
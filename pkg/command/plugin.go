@@ -37,6 +37,7 @@ var (
 	outputFormat string
 	targetStr    string
 	group        string
+	showLicense  bool
 )
 
 const (
@@ -64,6 +65,7 @@ func newPluginCmd() *cobra.Command {
 	describePluginCmd := newDescribePluginCmd()
 	deletePluginCmd := newDeletePluginCmd()
 	cleanPluginCmd := newCleanPluginCmd()
+	gcPluginCmd := newGCPluginCmd()
 	syncPluginCmd := newSyncPluginCmd()
 	discoverySourceCmd := newDiscoverySourceCmd()
 
@@ -104,6 +106,7 @@ func newPluginCmd() *cobra.Command {
 
 	describePluginCmd.Flags().StringVarP(&targetStr, "target", "t", "", targetFlagDesc)
 	utils.PanicOnErr(describePluginCmd.RegisterFlagCompletionFunc("target", completeTargetsForInstalledPlugins))
+	describePluginCmd.Flags().BoolVar(&showLicense, "show-license", false, "show the license and the SBOM of the installed version of the plugin")
 
 	installPluginCmd.MarkFlagsMutuallyExclusive("group", "local")
 	installPluginCmd.MarkFlagsMutuallyExclusive("group", "local-source")
@@ -117,12 +120,16 @@ func newPluginCmd() *cobra.Command {
 		describePluginCmd,
 		deletePluginCmd,
 		cleanPluginCmd,
+		gcPluginCmd,
 		syncPluginCmd,
 		discoverySourceCmd,
 		newSearchPluginCmd(),
+		newCompatPluginCmd(),
 		newPluginGroupCmd(),
 		newDownloadBundlePluginCmd(),
 		newUploadBundlePluginCmd(),
+		newVerifyBundlePluginCmd(),
+		newInspectBundlePluginCmd(),
 	)
 
 	return pluginCmd
@@ -158,6 +165,10 @@ func newListPluginCmd() *cobra.Command {
 				displayInstalledAndMissingListView(standalonePlugins, installedContextPlugins, missingContextPlugins, cmd.OutOrStdout())
 			}
 
+			// The deprecation of the context plugins is recorded in the catalog when they are installed
+			serverPlugins, _ := pluginsupplier.GetInstalledServerPlugins()
+			warnDeprecatedPlugins(append(standalonePlugins, serverPlugins...))
+
 			return kerrors.NewAggregate(errorList)
 		},
 	}
@@ -172,7 +183,6 @@ func newDescribePluginCmd() *cobra.Command {
 		Long:              "Displays detailed information for a plugin",
 		ValidArgsFunction: completeInstalledPlugins,
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
-			output := component.NewOutputWriterWithOptions(cmd.OutOrStdout(), outputFormat, []component.OutputWriterOption{}, "name", "version", "status", "target", "description", "installationPath")
 			if len(args) != 1 {
 				return fmt.Errorf("must provide one plugin name as a positional argument")
 			}
@@ -186,7 +196,32 @@ func newDescribePluginCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			output.AddRow(pd.Name, pd.Version, pd.Status, pd.Target, pd.Description, pd.InstallationPath)
+
+			columns := []string{"name", "version", "status", "target", "description", "installationPath"}
+			row := []interface{}{pd.Name, pd.Version, pd.Status, pd.Target, pd.Description, pd.InstallationPath}
+
+			// The table only shows the release notes columns when the publisher provided release notes for the
+			// version, while the other output formats always have them so that their fields can be relied upon
+			releaseNotes := pluginmanager.GetPluginReleaseNotes(pd.Name, pd.Target, pd.Version)
+			if releaseNotes == nil && outputFormat != "" && outputFormat != string(component.TableOutputType) {
+				releaseNotes = &plugininventory.PluginReleaseNotes{}
+			}
+			if releaseNotes != nil {
+				columns = append(columns, "changelog", "releaseNotes")
+				row = append(row, releaseNotes.ChangelogURL, releaseNotes.Notes)
+			}
+			if showLicense {
+				license := pluginmanager.GetPluginLicense(pd.Name, pd.Target, pd.Version)
+				if license == nil {
+					log.Warningf("the publisher of plugin '%s' did not provide the license of version '%s'", pd.Name, pd.Version)
+					license = &plugininventory.PluginLicense{}
+				}
+				columns = append(columns, "license", "sbom")
+				row = append(row, license.License, license.SBOMURI)
+			}
+
+			output := component.NewOutputWriterWithOptions(cmd.OutOrStdout(), outputFormat, []component.OutputWriterOption{}, columns...)
+			output.AddRow(row...)
 			output.Render()
 			return nil
 		},
@@ -415,14 +450,68 @@ func newCleanPluginCmd() *cobra.Command {
 	return cleanCmd
 }
 
+func newGCPluginCmd() *cobra.Command {
+	var dryRun bool
+	var gcCmd = &cobra.Command{
+		Use:   "gc",
+		Short: "Remove orphaned plugin binaries",
+		Long: `Remove the plugin binaries that are no longer referenced by any installed plugin.
+Such binaries can be left behind by failed installations, uninstalled plugins or removed contexts.
+The most recent versions of each installed plugin are kept according to the retention policy which can be
+configured with the TANZU_CLI_PLUGIN_BINARY_RETENTION_COUNT variable.`,
+		ValidArgsFunction: noMoreCompletions,
+		Args:              cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := pluginmanager.GarbageCollectPluginBinaries(dryRun)
+			if err != nil {
+				return err
+			}
+			if dryRun {
+				for _, path := range result.RemovedPaths {
+					fmt.Fprintln(cmd.OutOrStdout(), path)
+				}
+				log.Infof("%d orphaned plugin binaries would be removed, reclaiming %s", len(result.RemovedPaths), utils.FormatBytes(result.ReclaimedBytes))
+				return nil
+			}
+			log.Successf("removed %d orphaned plugin binaries, reclaimed %s", len(result.RemovedPaths), utils.FormatBytes(result.ReclaimedBytes))
+			return nil
+		},
+	}
+	gcCmd.Flags().BoolVar(&dryRun, "dry-run", false, "only list the orphaned plugin binaries without removing them")
+	return gcCmd
+}
+
 func newSyncPluginCmd() *cobra.Command {
 	var syncCmd = &cobra.Command{
 		Use:   "sync",
 		Short: "Installs all plugins recommended by the active contexts",
 		Long: `Installs all plugins recommended by the active contexts.
-Plugins installed with this command will only be available while the context remains active.`,
+Plugins installed with this command will only be available while the context remains active.
+
+With the --from-cluster flag, installs instead the standalone plugins declared by the
+TanzuCLIPluginSet resources of the cluster of the kubeconfig, and keeps reconciling
+them with the --watch flag.`,
+		Example: `
+    # Install the plugins recommended by the active contexts
+    tanzu plugin sync
+
+    # Install the plugins declared by the TanzuCLIPluginSet resources of a cluster
+    tanzu plugin sync --from-cluster --kubeconfig ~/.kube/fleet-config --kubecontext fleet
+
+    # Keep the installed plugins reconciled with the TanzuCLIPluginSet resources of the cluster
+    tanzu plugin sync --from-cluster --watch --watch-interval 5m`,
 		ValidArgsFunction: noMoreCompletions,
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if prefetchOnly {
+				prefetchPlugins()
+				return nil
+			}
+			if err := validateSyncFromClusterFlags(cmd); err != nil {
+				return err
+			}
+			if syncFromCluster {
+				return syncPluginsFromCluster(cmd)
+			}
 			err = syncPlugins(cmd)
 			if err != nil {
 				return err
@@ -431,6 +520,18 @@ Plugins installed with this command will only be available while the context rem
 			return nil
 		},
 	}
+
+	// The --prefetch-only flag is used by the background prefetch of the plugins
+	// started by the context commands, it is not meant to be used directly
+	syncCmd.Flags().BoolVar(&prefetchOnly, "prefetch-only", false, "only download the plugins to the plugin cache without installing them")
+	utils.PanicOnErr(syncCmd.Flags().MarkHidden("prefetch-only"))
+
+	syncCmd.Flags().BoolVar(&syncFromCluster, "from-cluster", false, "install the plugins declared by the TanzuCLIPluginSet resources of the cluster")
+	syncCmd.Flags().BoolVar(&syncWatch, "watch", false, "keep reconciling the installed plugins with the cluster; valid only with --from-cluster")
+	syncCmd.Flags().DurationVar(&syncWatchInterval, "watch-interval", defaultSyncWatchInterval, "interval between the reconciliations of the installed plugins; valid only with --watch")
+	syncCmd.Flags().StringVar(&kubeConfig, "kubeconfig", "", "path to the kubeconfig file of the cluster; valid only with --from-cluster")
+	syncCmd.Flags().StringVar(&kubeContext, "kubecontext", "", "the context in the kubeconfig to use; valid only with --from-cluster")
+	utils.PanicOnErr(syncCmd.RegisterFlagCompletionFunc("kubecontext", completeKubeContext))
 	return syncCmd
 }
 
@@ -493,7 +594,7 @@ func getInstalledAndMissingContextPlugins() (installed, missing []discovery.Disc
 
 			// Store the installed plugin, which includes the context from which it was installed
 			found = true
-			if serverPlugins[i].RecommendedVersion != installedPlugins[j].Version {
+			if pluginmanager.IsPluginUpdateAvailable(&serverPlugins[i], installedPlugins[j].Version) {
 				serverPlugins[i].Status = common.PluginStatusUpdateAvailable
 				pluginSyncRequired = true
 			} else {
@@ -513,6 +614,15 @@ func getInstalledAndMissingContextPlugins() (installed, missing []discovery.Disc
 	return installed, missing, pluginSyncRequired, kerrors.NewAggregate(errorList)
 }
 
+// warnDeprecatedPlugins warns the user about the installed plugins which are deprecated
+func warnDeprecatedPlugins(plugins []cli.PluginInfo) {
+	for i := range plugins {
+		if warning := plugins[i].DeprecationWarning(); warning != "" {
+			log.Warning(warning)
+		}
+	}
+}
+
 func displayInstalledAndMissingSplitView(installedStandalonePlugins []cli.PluginInfo, installedContextPlugins, missingContextPlugins []discovery.Discovered, pluginSyncRequired bool, writer io.Writer) {
 	// List installed standalone plugins
 	cyanBold := color.New(color.FgCyan).Add(color.Bold)
@@ -4,21 +4,27 @@
 package command
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/airgapped"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/carvelhelpers"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/registry"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/component"
 )
 
 type downloadPluginBundleOptions struct {
@@ -26,7 +32,15 @@ type downloadPluginBundleOptions struct {
 	tarFile                 string
 	groups                  []string
 	plugins                 []string
+	signKey                 string
+	base                    string
+	baseRepo                string
+	compression             string
+	compressionLevel        int
+	maxPartSize             string
+	concurrency             int
 	dryRun                  bool
+	connection              registryConnectionFlags
 }
 
 var (
@@ -53,19 +67,52 @@ to an internet-restricted environment. Please also see the "upload-bundle" comma
     tanzu plugin download-bundle --plugin cluster:v1.0.0 --to-tar /tmp/plugin_bundle_cluster.tar.gz
 
     # Download a plugin bundle with the entire plugin repository from a custom discovery source
-    tanzu plugin download-bundle --image custom.registry.vmware.com/tkg/tanzu-plugins/plugin-inventory:latest --to-tar /tmp/plugin_bundle_complete.tar.gz`,
+    tanzu plugin download-bundle --image custom.registry.vmware.com/tkg/tanzu-plugins/plugin-inventory:latest --to-tar /tmp/plugin_bundle_complete.tar.gz
+
+    # Download a plugin bundle with only the images which are not part of a plugin bundle downloaded previously
+    tanzu plugin download-bundle --to-tar /tmp/plugin_bundle_complete_delta.tar.gz --base /tmp/plugin_bundle_complete.tar.gz
+
+    # Download a plugin bundle signed with a cosign private key, the signature is saved to /tmp/plugin_bundle_complete.tar.gz.sig
+    COSIGN_PASSWORD=<password> tanzu plugin download-bundle --to-tar /tmp/plugin_bundle_complete.tar.gz --sign-key cosign.key
+
+    # Download a plugin bundle compressed with zstd at its highest compression level
+    tanzu plugin download-bundle --to-tar /tmp/plugin_bundle_complete.tar.zst --compression zstd --compression-level 22
+
+    # Download a plugin bundle split in tar files of at most 4GiB, /tmp/plugin_bundle_complete.tar.gz, /tmp/plugin_bundle_complete.part1.tar.gz, ...
+    tanzu plugin download-bundle --to-tar /tmp/plugin_bundle_complete.tar.gz --max-part-size 4Gi
+
+    # Download a plugin bundle, downloading 8 images at a time. If the download fails, running the same command again resumes it
+    tanzu plugin download-bundle --to-tar /tmp/plugin_bundle_complete.tar.gz --concurrency 8`,
 		ValidArgsFunction: completeDownloadBundle,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if !dpbo.dryRun && dpbo.tarFile == "" {
 				return errors.New("flag '--to-tar' is required")
 			}
+			if dpbo.concurrency < 1 {
+				return errors.Errorf("invalid concurrency %d, at least one image must be downloaded at a time", dpbo.concurrency)
+			}
+			var maxPartSize resource.Quantity
+			if dpbo.maxPartSize != "" {
+				var err error
+				maxPartSize, err = resource.ParseQuantity(dpbo.maxPartSize)
+				if err != nil {
+					return errors.Wrapf(err, "invalid maximum part size %q", dpbo.maxPartSize)
+				}
+			}
 			options := airgapped.DownloadPluginBundleOptions{
 				PluginInventoryImage: dpbo.pluginDiscoveryOCIImage,
 				ToTar:                dpbo.tarFile,
 				Groups:               dpbo.groups,
 				Plugins:              dpbo.plugins,
+				SignKey:              dpbo.signKey,
+				Base:                 dpbo.base,
+				BaseRepo:             dpbo.baseRepo,
+				Compression:          airgapped.PluginBundleCompression(dpbo.compression),
+				CompressionLevel:     dpbo.compressionLevel,
+				MaxPartSize:          maxPartSize.Value(),
+				Concurrency:          dpbo.concurrency,
 				DryRun:               dpbo.dryRun,
-				ImageProcessor:       carvelhelpers.NewImageOperationsImpl(),
+				Connection:           dpbo.connection.connectionOptions(),
 			}
 			return options.DownloadPluginBundle()
 		},
@@ -85,6 +132,41 @@ to an internet-restricted environment. Please also see the "upload-bundle" comma
 	f.StringSliceVarP(&dpbo.plugins, "plugin", "", []string{}, "only download plugins matching specified pluginID. Format: name/name:version/name@target:version (can specify multiple)")
 	utils.PanicOnErr(downloadBundleCmd.RegisterFlagCompletionFunc("plugin", completePluginIDForBundleDownload))
 
+	// Shell completion for this flag is the default behavior of doing file completion
+	f.StringVarP(&dpbo.base, "base", "", "", "plugin bundle downloaded previously, or its plugin migration manifest, whose images are not included in the plugin bundle")
+	f.StringVarP(&dpbo.baseRepo, "base-repo", "", "", "repository plugin bundles were uploaded to, whose images are not included in the plugin bundle")
+	utils.PanicOnErr(downloadBundleCmd.RegisterFlagCompletionFunc("base-repo", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return cobra.AppendActiveHelp(nil, "Please enter the URI of the repository plugin bundles were uploaded to"), cobra.ShellCompDirectiveNoFileComp
+	}))
+
+	// Shell completion for this flag is the default behavior of doing file completion
+	f.StringVarP(&dpbo.signKey, "sign-key", "", "", "cosign private key (or KMS URI) used to sign the plugin bundle, the password of the key is read from the COSIGN_PASSWORD environment variable")
+
+	f.StringVarP(&dpbo.compression, "compression", "", "", "compression of the plugin bundle: 'none', 'gzip' or 'zstd' (default is based on the extension of the tar file)")
+	utils.PanicOnErr(downloadBundleCmd.RegisterFlagCompletionFunc("compression", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var compressions []string
+		for _, compression := range airgapped.PluginBundleCompressions {
+			compressions = append(compressions, string(compression))
+		}
+		return compressions, cobra.ShellCompDirectiveNoFileComp
+	}))
+	f.IntVarP(&dpbo.compressionLevel, "compression-level", "", 0, "compression level of the plugin bundle, from 1 to 9 for gzip and from 1 to 22 for zstd (default is the default level of the compression)")
+	utils.PanicOnErr(downloadBundleCmd.RegisterFlagCompletionFunc("compression-level", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return cobra.AppendActiveHelp(nil, "Please enter the compression level of the plugin bundle"), cobra.ShellCompDirectiveNoFileComp
+	}))
+
+	f.StringVarP(&dpbo.maxPartSize, "max-part-size", "", "", "split the plugin bundle in several tar files no larger than this size, e.g. 4Gi (optional)")
+	utils.PanicOnErr(downloadBundleCmd.RegisterFlagCompletionFunc("max-part-size", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return cobra.AppendActiveHelp(nil, "Please enter the maximum size of the tar files of the plugin bundle, e.g. 4Gi"), cobra.ShellCompDirectiveNoFileComp
+	}))
+
+	f.IntVarP(&dpbo.concurrency, "concurrency", "", 4, "number of images downloaded concurrently")
+	utils.PanicOnErr(downloadBundleCmd.RegisterFlagCompletionFunc("concurrency", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return cobra.AppendActiveHelp(nil, "Please enter the number of images to download concurrently"), cobra.ShellCompDirectiveNoFileComp
+	}))
+
+	dpbo.connection.addFlags(downloadBundleCmd)
+
 	f.BoolVarP(&dpbo.dryRun, "dry-run", "", false, "perform a dry run by listing the images to download without actually downloading them")
 	_ = downloadBundleCmd.Flags().MarkHidden("dry-run")
 
@@ -97,7 +179,19 @@ to an internet-restricted environment. Please also see the "upload-bundle" comma
 
 type uploadPluginBundleOptions struct {
 	sourceTar       string
-	destinationRepo string
+	parts           []string
+	destinationRepo []string
+	mirrorsFile     string
+	mergePolicy     string
+	concurrency     int
+	retries         int
+	retryBackoff    time.Duration
+	retryMaxBackoff time.Duration
+	groups          []string
+	plugins         []string
+	verifyKey       string
+	signature       string
+	connection      registryConnectionFlags
 }
 
 var upbo uploadPluginBundleOptions
@@ -111,33 +205,279 @@ environment. The plugin bundle is obtained using the "download-bundle" command.`
 		Example: `
     # Upload the plugin bundle to the remote repository
     tanzu plugin upload-bundle --tar /tmp/plugin_bundle_vmware_tkg_default_v1.0.0.tar.gz --to-repo custom.registry.company.com/tanzu-plugins/
-    tanzu plugin upload-bundle --tar /tmp/plugin_bundle_complete.tar.gz --to-repo custom.registry.company.com/tanzu-plugins/`,
+    tanzu plugin upload-bundle --tar /tmp/plugin_bundle_complete.tar.gz --to-repo custom.registry.company.com/tanzu-plugins/
+
+    # Upload only a plugin group and a plugin of the plugin bundle
+    tanzu plugin upload-bundle --tar /tmp/plugin_bundle_complete.tar.gz --to-repo custom.registry.company.com/tanzu-plugins/ --group vmware-tkg/default:v2.1.0 --plugin cluster@kubernetes:v1.0.0
+
+    # Upload the plugin bundle, uploading 8 images at a time
+    tanzu plugin upload-bundle --tar /tmp/plugin_bundle_complete.tar.gz --to-repo custom.registry.company.com/tanzu-plugins/ --concurrency 8
+
+    # Upload the plugin bundle, failing if some of its plugins or plugin-groups are already in the repository
+    tanzu plugin upload-bundle --tar /tmp/plugin_bundle_complete.tar.gz --to-repo custom.registry.company.com/tanzu-plugins/ --merge-policy fail-on-conflict
+
+    # Upload the plugin bundle after verifying its signature, read from /tmp/plugin_bundle_complete.tar.gz.sig, with a cosign public key
+    tanzu plugin upload-bundle --tar /tmp/plugin_bundle_complete.tar.gz --to-repo custom.registry.company.com/tanzu-plugins/ --verify-key cosign.pub
+
+    # Upload a plugin bundle split in several tar files whose other parts are not in the directory of the first part
+    tanzu plugin upload-bundle --tar /tmp/plugin_bundle_complete.tar.gz --part /mnt/usb2/plugin_bundle_complete.part1.tar.gz --part /mnt/usb3/plugin_bundle_complete.part2.tar.gz --to-repo custom.registry.company.com/tanzu-plugins/
+
+    # Stage the upload of the plugin bundle to an OCI image layout directory, to copy it to the registry later
+    tanzu plugin upload-bundle --tar /tmp/plugin_bundle_complete.tar.gz --to-repo file:///mnt/staging/tanzu-plugins
+
+    # Upload the plugin bundle to the registry replicas of several sites
+    tanzu plugin upload-bundle --tar /tmp/plugin_bundle_complete.tar.gz --to-repo site1.registry.company.com/tanzu-plugins/ --to-repo site2.registry.company.com/tanzu-plugins/
+    tanzu plugin upload-bundle --tar /tmp/plugin_bundle_complete.tar.gz --mirrors-file mirrors.yaml`,
 		ValidArgsFunction: completeUploadBundle,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if upbo.concurrency < 1 {
+				return errors.Errorf("invalid concurrency %d, at least one image must be uploaded at a time", upbo.concurrency)
+			}
+			if upbo.retries < 0 {
+				return errors.Errorf("invalid number of retries %d", upbo.retries)
+			}
+			// A maximum backoff of 0, as configured for the image operations, means no maximum
+			if upbo.retryBackoff < 0 || upbo.retryMaxBackoff < 0 || (upbo.retryMaxBackoff > 0 && upbo.retryMaxBackoff < upbo.retryBackoff) {
+				return errors.Errorf("invalid retry backoff %v and maximum backoff %v, the backoff must not be negative or exceed the maximum backoff", upbo.retryBackoff, upbo.retryMaxBackoff)
+			}
+			if upbo.signature != "" && upbo.verifyKey == "" {
+				return errors.New("flag '--verify-key' is required to verify the signature of the plugin bundle")
+			}
 			options := airgapped.UploadPluginBundleOptions{
 				Tar:             upbo.sourceTar,
-				DestinationRepo: upbo.destinationRepo,
-				ImageProcessor:  carvelhelpers.NewImageOperationsImpl(),
+				Parts:           upbo.parts,
+				MirrorsFile:     upbo.mirrorsFile,
+				MergePolicy:     plugininventory.MetadataMergePolicy(upbo.mergePolicy),
+				Concurrency:     upbo.concurrency,
+				Retries:         upbo.retries,
+				RetryBackoff:    upbo.retryBackoff,
+				RetryMaxBackoff: upbo.retryMaxBackoff,
+				Groups:          upbo.groups,
+				Plugins:         upbo.plugins,
+				VerifyKey:       upbo.verifyKey,
+				Signature:       upbo.signature,
+				Connection:      upbo.connection.connectionOptions(),
+			}
+			if len(upbo.destinationRepo) > 0 {
+				options.DestinationRepo = upbo.destinationRepo[0]
+				options.DestinationRepos = upbo.destinationRepo[1:]
 			}
 			return options.UploadPluginBundle()
 		},
 	}
 
 	f := uploadBundleCmd.Flags()
+	// The defaults of the retry flags are the ones of the image operations, which can be configured
+	// with environment variables
+	retryPolicy := carvelhelpers.DefaultRetryPolicy()
 
 	// Shell completion for this flag is the default behavior of doing file completion
 	f.StringVarP(&upbo.sourceTar, "tar", "", "", "source tar file")
-	f.StringVarP(&upbo.destinationRepo, "to-repo", "", "", "destination repository for publishing plugins")
+	// Shell completion for this flag is the default behavior of doing file completion
+	f.StringSliceVarP(&upbo.parts, "part", "", []string{}, "other tar files of a plugin bundle split in several parts, in the order of their index (default is the parts in the directory of the source tar file)")
+	f.StringSliceVarP(&upbo.destinationRepo, "to-repo", "", []string{}, "destination repository for publishing plugins, or OCI image layout directory as a file:// URL or a local path (can specify multiple)")
 	utils.PanicOnErr(uploadBundleCmd.RegisterFlagCompletionFunc("to-repo", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return cobra.AppendActiveHelp(nil, "Please enter the URI of the destination repository for publishing plugins"), cobra.ShellCompDirectiveNoFileComp
+		return cobra.AppendActiveHelp(nil, "Please enter the URI of the destination repository for publishing plugins, or a file:// URL of an OCI image layout directory"), cobra.ShellCompDirectiveNoFileComp
+	}))
+	// Shell completion for this flag is the default behavior of doing file completion
+	f.StringVarP(&upbo.mirrorsFile, "mirrors-file", "", "", "YAML file listing the repositories of the mirrors the plugin bundle is published to, in addition to the destination repositories")
+
+	f.StringVarP(&upbo.mergePolicy, "merge-policy", "", string(plugininventory.MetadataMergePolicyUnion), "how the plugins and plugin-groups of the bundle are merged with the ones already in the repository: 'union' keeps both, 'replace' keeps only the ones of the bundle, 'fail-on-conflict' fails if some are in both")
+	utils.PanicOnErr(uploadBundleCmd.RegisterFlagCompletionFunc("merge-policy", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var policies []string
+		for _, policy := range plugininventory.MetadataMergePolicies {
+			policies = append(policies, string(policy))
+		}
+		return policies, cobra.ShellCompDirectiveNoFileComp
+	}))
+
+	f.IntVarP(&upbo.concurrency, "concurrency", "", 4, "number of images uploaded concurrently")
+	utils.PanicOnErr(uploadBundleCmd.RegisterFlagCompletionFunc("concurrency", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return cobra.AppendActiveHelp(nil, "Please enter the number of images to upload concurrently"), cobra.ShellCompDirectiveNoFileComp
+	}))
+	f.IntVarP(&upbo.retries, "retries", "", retryPolicy.Retries, "number of times the upload of an image, and the other registry operations, are retried when they fail with a transient error")
+	utils.PanicOnErr(uploadBundleCmd.RegisterFlagCompletionFunc("retries", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return cobra.AppendActiveHelp(nil, "Please enter the number of times to retry the upload of an image"), cobra.ShellCompDirectiveNoFileComp
+	}))
+	f.DurationVarP(&upbo.retryBackoff, "retry-backoff", "", retryPolicy.Backoff, "delay before retrying the upload of an image the first time, doubled for each subsequent retry")
+	utils.PanicOnErr(uploadBundleCmd.RegisterFlagCompletionFunc("retry-backoff", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return cobra.AppendActiveHelp(nil, "Please enter the delay before retrying the upload of an image, e.g. 2s"), cobra.ShellCompDirectiveNoFileComp
+	}))
+	f.DurationVarP(&upbo.retryMaxBackoff, "retry-max-backoff", "", retryPolicy.MaxBackoff, "maximum delay before retrying the upload of an image")
+	utils.PanicOnErr(uploadBundleCmd.RegisterFlagCompletionFunc("retry-max-backoff", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return cobra.AppendActiveHelp(nil, "Please enter the maximum delay before retrying the upload of an image, e.g. 1m"), cobra.ShellCompDirectiveNoFileComp
 	}))
 
+	f.StringSliceVarP(&upbo.groups, "group", "", []string{}, "only upload the plugins of the plugin-group version of the bundle (can specify multiple)")
+	utils.PanicOnErr(uploadBundleCmd.RegisterFlagCompletionFunc("group", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return cobra.AppendActiveHelp(nil, "Please enter a plugin group of the bundle in the form vendor-publisher/name[:version]"), cobra.ShellCompDirectiveNoFileComp
+	}))
+	f.StringSliceVarP(&upbo.plugins, "plugin", "", []string{}, "only upload the plugins of the bundle matching specified pluginID. Format: name/name:version/name@target:version (can specify multiple)")
+	utils.PanicOnErr(uploadBundleCmd.RegisterFlagCompletionFunc("plugin", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return cobra.AppendActiveHelp(nil, "Please enter a plugin of the bundle in the form name[@target][:version]"), cobra.ShellCompDirectiveNoFileComp
+	}))
+
+	// Shell completion for these flags is the default behavior of doing file completion
+	f.StringVarP(&upbo.verifyKey, "verify-key", "", "", "cosign public key used to verify the signatures of the plugin bundle and of its plugin inventory metadata before uploading it")
+	f.StringVarP(&upbo.signature, "signature", "", "", "signature file of the plugin bundle, the tar file path with the '.sig' suffix by default")
+
+	upbo.connection.addFlags(uploadBundleCmd)
+
 	_ = uploadBundleCmd.MarkFlagRequired("tar")
-	_ = uploadBundleCmd.MarkFlagRequired("to-repo")
+	uploadBundleCmd.MarkFlagsOneRequired("to-repo", "mirrors-file")
 
 	return uploadBundleCmd
 }
 
+// registryConnectionFlags are the TLS and proxy settings of the commands accessing registries,
+// used on top of the configuration set with 'tanzu config cert' and of the proxy environment variables
+type registryConnectionFlags struct {
+	caCerts        []string
+	skipCertVerify bool
+	proxy          string
+	noProxy        string
+}
+
+func (c *registryConnectionFlags) addFlags(cmd *cobra.Command) {
+	f := cmd.Flags()
+	// Shell completion for this flag is the default behavior of doing file completion
+	f.StringSliceVarP(&c.caCerts, "ca-cert", "", []string{}, "path to a CA certificates bundle trusted to access the registries (can specify multiple)")
+	f.BoolVarP(&c.skipCertVerify, "skip-cert-verify", "", false, "skip the verification of the TLS certificates of the registries")
+	f.StringVarP(&c.proxy, "proxy", "", "", "URL of the proxy used to access the registries, overriding the HTTP_PROXY and HTTPS_PROXY environment variables")
+	utils.PanicOnErr(cmd.RegisterFlagCompletionFunc("proxy", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return cobra.AppendActiveHelp(nil, "Please enter the URL of the proxy, e.g. http://proxy.example.com:3128"), cobra.ShellCompDirectiveNoFileComp
+	}))
+	f.StringVarP(&c.noProxy, "no-proxy", "", "", "comma-separated list of the hosts accessed without proxy, overriding the NO_PROXY environment variable")
+	utils.PanicOnErr(cmd.RegisterFlagCompletionFunc("no-proxy", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return cobra.AppendActiveHelp(nil, "Please enter the comma-separated list of the hosts to access without proxy"), cobra.ShellCompDirectiveNoFileComp
+	}))
+}
+
+// connectionOptions returns the connection options of the flags, nil when none is set
+func (c *registryConnectionFlags) connectionOptions() *registry.ConnectionOptions {
+	if len(c.caCerts) == 0 && !c.skipCertVerify && c.proxy == "" && c.noProxy == "" {
+		return nil
+	}
+	return &registry.ConnectionOptions{
+		CACertPaths:    c.caCerts,
+		SkipCertVerify: c.skipCertVerify,
+		HTTPProxy:      c.proxy,
+		HTTPSProxy:     c.proxy,
+		NoProxy:        c.noProxy,
+	}
+}
+
+type verifyPluginBundleOptions struct {
+	sourceTar string
+}
+
+var vpbo verifyPluginBundleOptions
+
+func newVerifyBundlePluginCmd() *cobra.Command {
+	var verifyBundleCmd = &cobra.Command{
+		Use:   "verify-bundle",
+		Short: "Verify a plugin bundle before uploading it",
+		Long: `Verify a plugin bundle obtained using the "download-bundle" command, e.g. before carrying it
+into an internet-restricted environment. The plugin migration manifest of the bundle is validated,
+the images of the bundle are checked against the digests recorded when it was downloaded and the
+plugin inventory of the bundle is checked to be well-formed.`,
+		Example: `
+    # Verify the plugin bundle
+    tanzu plugin verify-bundle --tar /tmp/plugin_bundle_complete.tar.gz`,
+		ValidArgsFunction: completeVerifyBundle,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options := airgapped.VerifyPluginBundleOptions{
+				Tar: vpbo.sourceTar,
+			}
+			return options.VerifyPluginBundle()
+		},
+	}
+
+	f := verifyBundleCmd.Flags()
+
+	// Shell completion for this flag is the default behavior of doing file completion
+	f.StringVarP(&vpbo.sourceTar, "tar", "", "", "source tar file")
+
+	_ = verifyBundleCmd.MarkFlagRequired("tar")
+
+	return verifyBundleCmd
+}
+
+type inspectPluginBundleOptions struct {
+	sourceTar string
+}
+
+var ipbo inspectPluginBundleOptions
+
+func newInspectBundlePluginCmd() *cobra.Command {
+	var inspectBundleCmd = &cobra.Command{
+		Use:   "inspect-bundle",
+		Short: "Inspect the content of a plugin bundle",
+		Long: `Inspect a plugin bundle obtained using the "download-bundle" command without uploading it.
+The plugins and plugin-groups of the bundle with their versions, the repositories the images of the
+bundle are uploaded to, relative to the repository provided to the "upload-bundle" command, and the
+total size of the images are listed.`,
+		Example: `
+    # Inspect the plugin bundle
+    tanzu plugin inspect-bundle --tar /tmp/plugin_bundle_complete.tar.gz
+
+    # Inspect the plugin bundle and output its content in yaml
+    tanzu plugin inspect-bundle --tar /tmp/plugin_bundle_complete.tar.gz -o yaml`,
+		ValidArgsFunction: completeInspectBundle,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options := airgapped.InspectPluginBundleOptions{
+				Tar: ipbo.sourceTar,
+			}
+			content, err := options.InspectPluginBundle()
+			if err != nil {
+				return err
+			}
+			displayPluginBundleContent(content, cmd.OutOrStdout())
+			return nil
+		},
+	}
+
+	f := inspectBundleCmd.Flags()
+
+	// Shell completion for this flag is the default behavior of doing file completion
+	f.StringVarP(&ipbo.sourceTar, "tar", "", "", "source tar file")
+	f.StringVarP(&outputFormat, "output", "o", "", "Output format (yaml|json|table)")
+	utils.PanicOnErr(inspectBundleCmd.RegisterFlagCompletionFunc("output", completionGetOutputFormats))
+
+	_ = inspectBundleCmd.MarkFlagRequired("tar")
+
+	return inspectBundleCmd
+}
+
+func displayPluginBundleContent(content *airgapped.PluginBundleContent, writer io.Writer) {
+	if outputFormat != "" && outputFormat != string(component.TableOutputType) {
+		component.NewObjectWriter(writer, outputFormat, content).Render()
+		return
+	}
+
+	pluginsWriter := component.NewOutputWriterWithOptions(writer, outputFormat, []component.OutputWriterOption{}, "Name", "Target", "Versions")
+	for _, p := range content.Plugins {
+		pluginsWriter.AddRow(p.Name, p.Target, strings.Join(p.Versions, ", "))
+	}
+	pluginsWriter.Render()
+	fmt.Fprintln(writer)
+
+	groupsWriter := component.NewOutputWriterWithOptions(writer, outputFormat, []component.OutputWriterOption{}, "Group", "Versions")
+	for _, pg := range content.PluginGroups {
+		groupsWriter.AddRow(pg.Group, strings.Join(pg.Versions, ", "))
+	}
+	groupsWriter.Render()
+	fmt.Fprintln(writer)
+
+	fmt.Fprintf(writer, "Plugin inventory image: %s\n", content.InventoryImage)
+	fmt.Fprintf(writer, "Plugin inventory metadata image: %s\n", content.InventoryMetadataImage)
+	fmt.Fprintf(writer, "Repositories: %d\n", len(content.Repositories))
+	fmt.Fprintf(writer, "Images: %d (%s)\n", content.ImagesCount, utils.FormatBytes(content.Size))
+	if content.BaseImagesCount > 0 {
+		fmt.Fprintf(writer, "Images of the base not included: %d\n", content.BaseImagesCount)
+	}
+}
+
 // ====================================
 // Shell completion functions
 // ====================================
@@ -155,8 +495,28 @@ func completeDownloadBundle(_ *cobra.Command, _ []string, _ string) ([]string, c
 }
 
 func completeUploadBundle(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
-	if upbo.destinationRepo == "" || upbo.sourceTar == "" {
-		// Both flags are required, so completion will be provided for them
+	if (len(upbo.destinationRepo) == 0 && upbo.mirrorsFile == "") || upbo.sourceTar == "" {
+		// The flags are required, so completion will be provided for them
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	// The user has provided enough information
+	return activeHelpNoMoreArgs(nil), cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeVerifyBundle(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if vpbo.sourceTar == "" {
+		// The flag is required, so completion will be provided for it
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	// The user has provided enough information
+	return activeHelpNoMoreArgs(nil), cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeInspectBundle(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if ipbo.sourceTar == "" {
+		// The flag is required, so completion will be provided for it
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
@@ -227,7 +587,7 @@ func completionGetPluginGroupsForBundleDownload() ([]*plugininventory.PluginGrou
 
 	// Read the plugin inventory database to read the plugin groups it contains
 	pi := plugininventory.NewSQLiteInventory(inventoryFile, path.Dir(dpbo.pluginDiscoveryOCIImage))
-	pluginGroups, err := pi.GetPluginGroups(plugininventory.PluginGroupFilter{IncludeHidden: true}) // Include the hidden plugin groups during plugin migration
+	pluginGroups, err := pi.GetPluginGroups(context.Background(), plugininventory.PluginGroupFilter{IncludeHidden: true}) // Include the hidden plugin groups during plugin migration
 	if err != nil {
 		return nil, err
 	}
@@ -312,7 +672,7 @@ func completionGetPluginsForBundleDownload() ([]*plugininventory.PluginInventory
 
 	// Read the plugin inventory database to read the plugins it contains
 	pi := plugininventory.NewSQLiteInventory(inventoryFile, path.Dir(dpbo.pluginDiscoveryOCIImage))
-	pluginEntries, err := pi.GetPlugins(&plugininventory.PluginInventoryFilter{IncludeHidden: true}) // Include the hidden plugin during plugin migration
+	pluginEntries, err := pi.GetPlugins(context.Background(), &plugininventory.PluginInventoryFilter{IncludeHidden: true}) // Include the hidden plugin during plugin migration
 	if err != nil {
 		return nil, err
 	}
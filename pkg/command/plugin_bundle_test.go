@@ -66,6 +66,55 @@ func TestCompletionPluginBundle(t *testing.T) {
 			// ":4" is the value of the ShellCompDirectiveNoFileComp
 			expected: "_activeHelp_ Please enter the URI of the plugin discovery image providing the plugins\n:4\n",
 		},
+		{
+			test: "no completion for the --base-repo flag value of the download-bundle command",
+			args: []string{"__complete", "plugin", "download-bundle", "--base-repo", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ Please enter the URI of the repository plugin bundles were uploaded to\n:4\n",
+		},
+		{
+			test: "completion for the --compression flag value of the download-bundle command",
+			args: []string{"__complete", "plugin", "download-bundle", "--compression", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "none\ngzip\nzstd\n:4\n",
+		},
+		{
+			test: "no completion for the --compression-level flag value of the download-bundle command",
+			args: []string{"__complete", "plugin", "download-bundle", "--compression-level", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ Please enter the compression level of the plugin bundle\n:4\n",
+		},
+		{
+			test: "no completion for the --max-part-size flag value of the download-bundle command",
+			args: []string{"__complete", "plugin", "download-bundle", "--max-part-size", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ Please enter the maximum size of the tar files of the plugin bundle, e.g. 4Gi\n:4\n",
+		},
+		{
+			test: "no completion for the --concurrency flag value of the download-bundle command",
+			args: []string{"__complete", "plugin", "download-bundle", "--concurrency", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ Please enter the number of images to download concurrently\n:4\n",
+		},
+		{
+			test: "completion for the --proxy flag value of the download-bundle command",
+			args: []string{"__complete", "plugin", "download-bundle", "--proxy", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ Please enter the URL of the proxy, e.g. http://proxy.example.com:3128\n:4\n",
+		},
+		{
+			test: "completion for the --no-proxy flag value of the download-bundle command",
+			args: []string{"__complete", "plugin", "download-bundle", "--no-proxy", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ Please enter the comma-separated list of the hosts to access without proxy\n:4\n",
+		},
+		{
+			test: "file completion for the --ca-cert flag value of the download-bundle command",
+			args: []string{"__complete", "plugin", "download-bundle", "--ca-cert", ""},
+			// ":0" is the value of the ShellCompDirectiveDefault which indicates
+			// that file completion will be performed
+			expected: ":0\n",
+		},
 		{
 			test: "file completion for the --to-tar flag value of the download-bundle command",
 			args: []string{"__complete", "plugin", "download-bundle", "--to-tar", ""},
@@ -170,33 +219,155 @@ func TestCompletionPluginBundle(t *testing.T) {
 			// ":0" is the value of the ShellCompDirectiveDefault
 			expected: ":0\n",
 		},
+		{
+			test: "file completion for the --part flag value of the upload-bundle command",
+			args: []string{"__complete", "plugin", "upload-bundle", "--part", ""},
+			// ":0" is the value of the ShellCompDirectiveDefault
+			expected: ":0\n",
+		},
 		{
 			test: "completion for the --to-repo flag value for the upload-bundle command",
 			args: []string{"__complete", "plugin", "upload-bundle", "--tar", "plugin.tar", "--to-repo", ""},
 			// ":4" is the value of the ShellCompDirectiveNoFileComp
-			expected: "_activeHelp_ Please enter the URI of the destination repository for publishing plugins\n:4\n",
+			expected: "_activeHelp_ Please enter the URI of the destination repository for publishing plugins, or a file:// URL of an OCI image layout directory\n:4\n",
 		},
 		{
 			test: "flag completion after the upload-bundle command when no flags are present",
 			args: []string{"__complete", "plugin", "upload-bundle", ""},
 			// ":4" is the value of the ShellCompDirectiveNoFileComp
-			expected: "--tar\tsource tar file\n" +
-				"--to-repo\tdestination repository for publishing plugins\n" +
+			expected: "--mirrors-file\tYAML file listing the repositories of the mirrors the plugin bundle is published to, in addition to the destination repositories\n" +
+				"--tar\tsource tar file\n" +
+				"--to-repo\tdestination repository for publishing plugins, or OCI image layout directory as a file:// URL or a local path (can specify multiple)\n" +
 				":4\n",
 		},
 		{
 			test: "flag completion after the upload-bundle command when one flag is present",
 			args: []string{"__complete", "plugin", "upload-bundle", "--tar", "plugin.tar", ""},
 			// ":4" is the value of the ShellCompDirectiveNoFileComp
-			expected: "--to-repo\tdestination repository for publishing plugins\n" +
+			expected: "--mirrors-file\tYAML file listing the repositories of the mirrors the plugin bundle is published to, in addition to the destination repositories\n" +
+				"--to-repo\tdestination repository for publishing plugins, or OCI image layout directory as a file:// URL or a local path (can specify multiple)\n" +
 				":4\n",
 		},
+		{
+			test: "completion for the --merge-policy flag value for the upload-bundle command",
+			args: []string{"__complete", "plugin", "upload-bundle", "--merge-policy", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "union\nreplace\nfail-on-conflict\n:4\n",
+		},
+		{
+			test: "completion for the --group flag value for the upload-bundle command",
+			args: []string{"__complete", "plugin", "upload-bundle", "--group", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ Please enter a plugin group of the bundle in the form vendor-publisher/name[:version]\n:4\n",
+		},
+		{
+			test: "completion for the --plugin flag value for the upload-bundle command",
+			args: []string{"__complete", "plugin", "upload-bundle", "--plugin", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ Please enter a plugin of the bundle in the form name[@target][:version]\n:4\n",
+		},
+		{
+			test: "completion for the --retry-backoff flag value for the upload-bundle command",
+			args: []string{"__complete", "plugin", "upload-bundle", "--retry-backoff", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ Please enter the delay before retrying the upload of an image, e.g. 2s\n:4\n",
+		},
+		{
+			test: "completion for the --retry-max-backoff flag value for the upload-bundle command",
+			args: []string{"__complete", "plugin", "upload-bundle", "--retry-max-backoff", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ Please enter the maximum delay before retrying the upload of an image, e.g. 1m\n:4\n",
+		},
+		{
+			test: "completion for the --proxy flag value of the upload-bundle command",
+			args: []string{"__complete", "plugin", "upload-bundle", "--proxy", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ Please enter the URL of the proxy, e.g. http://proxy.example.com:3128\n:4\n",
+		},
+		{
+			test: "completion for the --no-proxy flag value of the upload-bundle command",
+			args: []string{"__complete", "plugin", "upload-bundle", "--no-proxy", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ Please enter the comma-separated list of the hosts to access without proxy\n:4\n",
+		},
+		{
+			test: "file completion for the --ca-cert flag value of the upload-bundle command",
+			args: []string{"__complete", "plugin", "upload-bundle", "--ca-cert", ""},
+			// ":0" is the value of the ShellCompDirectiveDefault which indicates
+			// that file completion will be performed
+			expected: ":0\n",
+		},
 		{
 			test: "no completion after the upload-bundle command when all flags are present",
 			args: []string{"__complete", "plugin", "upload-bundle", "--tar", "plugin.tar", "--to-repo", "repo", ""},
 			// ":4" is the value of the ShellCompDirectiveNoFileComp
 			expected: "_activeHelp_ " + compNoMoreArgsMsg + "\n:4\n",
 		},
+		{
+			test: "file completion for the --mirrors-file flag value of the upload-bundle command",
+			args: []string{"__complete", "plugin", "upload-bundle", "--mirrors-file", ""},
+			// ":0" is the value of the ShellCompDirectiveDefault which indicates
+			// that file completion will be performed
+			expected: ":0\n",
+		},
+		{
+			test: "no completion after the upload-bundle command when the mirrors file is present instead of the destination repository",
+			args: []string{"__complete", "plugin", "upload-bundle", "--tar", "plugin.tar", "--mirrors-file", "mirrors.yaml", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ " + compNoMoreArgsMsg + "\n:4\n",
+		},
+
+		// ============================
+		// tanzu plugin verify-bundle
+		// ============================
+		{
+			test: "file completion for the --tar flag value of the verify-bundle command",
+			args: []string{"__complete", "plugin", "verify-bundle", "--tar", ""},
+			// ":0" is the value of the ShellCompDirectiveDefault
+			expected: ":0\n",
+		},
+		{
+			test: "flag completion after the verify-bundle command when no flags are present",
+			args: []string{"__complete", "plugin", "verify-bundle", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "--tar\tsource tar file\n" +
+				":4\n",
+		},
+		{
+			test: "no completion after the verify-bundle command when all flags are present",
+			args: []string{"__complete", "plugin", "verify-bundle", "--tar", "plugin.tar", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ " + compNoMoreArgsMsg + "\n:4\n",
+		},
+
+		// ============================
+		// tanzu plugin inspect-bundle
+		// ============================
+		{
+			test: "file completion for the --tar flag value of the inspect-bundle command",
+			args: []string{"__complete", "plugin", "inspect-bundle", "--tar", ""},
+			// ":0" is the value of the ShellCompDirectiveDefault
+			expected: ":0\n",
+		},
+		{
+			test: "completion for the --output flag value of the inspect-bundle command",
+			args: []string{"__complete", "plugin", "inspect-bundle", "--output", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: expectedOutForOutputFlag + ":4\n",
+		},
+		{
+			test: "flag completion after the inspect-bundle command when no flags are present",
+			args: []string{"__complete", "plugin", "inspect-bundle", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "--tar\tsource tar file\n" +
+				":4\n",
+		},
+		{
+			test: "no completion after the inspect-bundle command when all flags are present",
+			args: []string{"__complete", "plugin", "inspect-bundle", "--tar", "plugin.tar", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ " + compNoMoreArgsMsg + "\n:4\n",
+		},
 	}
 
 	// Setup a plugin source and a set of installed plugins
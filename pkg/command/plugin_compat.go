@@ -0,0 +1,65 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/component"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginmanager"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+)
+
+const compatLongDesc = `Check the compatibility of the installed plugins with the CLI.
+The version of the tanzu-plugin-runtime library each plugin is built with is compared with the
+range of versions supported by the CLI, and the minimum CLI version required by the installed
+version of each plugin, if declared in the plugin inventory, is compared with the CLI version.
+An action is suggested for each incompatible plugin.`
+
+func newCompatPluginCmd() *cobra.Command {
+	var compatCmd = &cobra.Command{
+		Use:               "compat",
+		Short:             "Check the compatibility of the installed plugins with the CLI",
+		Long:              compatLongDesc,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: noMoreCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			results, err := pluginmanager.CheckInstalledPluginsCompatibility()
+			if err != nil {
+				return err
+			}
+
+			if outputFormat == "" || outputFormat == string(component.TableOutputType) {
+				minVersion, maxVersion := pluginmanager.SupportedPluginRuntimeVersions()
+				if maxVersion == "" {
+					log.Infof("The CLI supports the plugins built with the plugin runtime %s or later", minVersion)
+				} else {
+					log.Infof("The CLI supports the plugins built with the plugin runtime %s to %s", minVersion, maxVersion)
+				}
+			}
+
+			incompatible := 0
+			output := component.NewOutputWriterWithOptions(cmd.OutOrStdout(), outputFormat, []component.OutputWriterOption{}, "Name", "Target", "Version", "Plugin Runtime", "Min CLI Version", "Status", "Reason", "Action")
+			for i := range results {
+				output.AddRow(results[i].Name, results[i].Target, results[i].Version, results[i].PluginRuntimeVersion, results[i].MinCLIVersion, results[i].Status, results[i].Reason, results[i].Action)
+				if results[i].Status == pluginmanager.PluginCompatibilityStatusIncompatible {
+					incompatible++
+				}
+			}
+			output.Render()
+
+			if incompatible > 0 {
+				return errors.Errorf("%d of the installed plugins are not compatible with the CLI", incompatible)
+			}
+			return nil
+		},
+	}
+
+	compatCmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format (yaml|json|table)")
+	utils.PanicOnErr(compatCmd.RegisterFlagCompletionFunc("output", completionGetOutputFormats))
+	return compatCmd
+}
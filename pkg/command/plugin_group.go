@@ -14,9 +14,11 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/component"
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginmanager"
@@ -24,8 +26,12 @@ import (
 )
 
 var (
-	groupID          string
-	showNonMandatory bool
+	groupID           string
+	showNonMandatory  bool
+	groupVendor       string
+	groupPublisher    string
+	groupNameContains string
+	groupPluginName   string
 )
 
 const groupSearchShowDetailsMsg = "Note: To view all plugin group versions available, use 'tanzu plugin group search --show-details'."
@@ -54,18 +60,25 @@ func newSearchCmd() *cobra.Command {
 		Args:              cobra.MaximumNArgs(0),
 		ValidArgsFunction: noMoreCompletions,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			var criteria *discovery.GroupDiscoveryCriteria
+			if !configtypes.IsValidTarget(targetStr, true, true) {
+				return errors.New(invalidTargetMsg)
+			}
+			criteria := &discovery.GroupDiscoveryCriteria{
+				Vendor:       groupVendor,
+				Publisher:    groupPublisher,
+				NameContains: groupNameContains,
+				PluginName:   groupPluginName,
+				PluginTarget: getTarget(),
+			}
 			if groupID != "" {
 				groupIdentifier := plugininventory.PluginGroupIdentifierFromID(groupID)
 				if groupIdentifier == nil {
 					return errors.Errorf("incorrect plugin-group %q specified", groupID)
 				}
 
-				criteria = &discovery.GroupDiscoveryCriteria{
-					Vendor:    groupIdentifier.Vendor,
-					Publisher: groupIdentifier.Publisher,
-					Name:      groupIdentifier.Name,
-				}
+				criteria.Vendor = groupIdentifier.Vendor
+				criteria.Publisher = groupIdentifier.Publisher
+				criteria.Name = groupIdentifier.Name
 			}
 			groups, err := pluginmanager.DiscoverPluginGroups(discovery.WithGroupDiscoveryCriteria(criteria))
 			if err != nil {
@@ -86,6 +99,30 @@ func newSearchCmd() *cobra.Command {
 	f.StringVarP(&groupID, "name", "n", "", "limit the search to the plugin-group with the specified name")
 	utils.PanicOnErr(searchCmd.RegisterFlagCompletionFunc("name", completeGroupNames))
 
+	f.StringVar(&groupVendor, "vendor", "", "limit the search to the plugin-groups of the specified vendor")
+	f.StringVar(&groupPublisher, "publisher", "", "limit the search to the plugin-groups of the specified publisher")
+	f.StringVar(&groupNameContains, "name-contains", "", "limit the search to the plugin-groups whose name contains the specified text")
+	for flag, help := range map[string]string{
+		"vendor":        "Please enter the vendor of the plugin-groups to search for",
+		"publisher":     "Please enter the publisher of the plugin-groups to search for",
+		"name-contains": "Please enter the text to search for in the name of the plugin-groups",
+	} {
+		help := help
+		utils.PanicOnErr(searchCmd.RegisterFlagCompletionFunc(flag, func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return cobra.AppendActiveHelp(nil, help), cobra.ShellCompDirectiveNoFileComp
+		}))
+		searchCmd.MarkFlagsMutuallyExclusive("name", flag)
+	}
+
+	f.StringVar(&groupPluginName, "plugin", "", "limit the search to the plugin-group versions which include the specified plugin")
+	utils.PanicOnErr(searchCmd.RegisterFlagCompletionFunc("plugin", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return completionAllPlugins(), cobra.ShellCompDirectiveNoFileComp
+	}))
+	f.StringVarP(&targetStr, "target", "t", "", fmt.Sprintf("limit the search to the plugin-group versions which include a plugin of the specified target (%s)", common.TargetList))
+	utils.PanicOnErr(searchCmd.RegisterFlagCompletionFunc("target", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{compGlobalTarget, compK8sTarget, compTMCTarget, compOpsTarget}, cobra.ShellCompDirectiveNoFileComp
+	}))
+
 	f.BoolVar(&showDetails, "show-details", false, "show the details of the specified group, including all available versions")
 	f.StringVarP(&outputFormat, "output", "o", "", "output format (yaml|json|table)")
 	utils.PanicOnErr(searchCmd.RegisterFlagCompletionFunc("output", completionGetOutputFormats))
@@ -73,6 +73,42 @@ func TestPluginGroupSearch(t *testing.T) {
 			expectedFailure: false,
 			expected:        "[ { \"Name\": \"vmware-tap/default\", \"Description\": \"Plugins for TAP\", \"Latest\": \"v3.3.3\", \"Versions\": [ \"v3.3.3\" ] } ]",
 		},
+		{
+			test:            "search for groups with --publisher",
+			args:            []string{"plugin", "group", "search", "--publisher", "tkg"},
+			expectedFailure: false,
+			expected:        "GROUP DESCRIPTION LATEST vmware-tkg/default Plugins for TKG v2.2.2 " + groupSearchShowDetailsMsg,
+		},
+		{
+			test:            "search for groups with --name-contains and --vendor",
+			args:            []string{"plugin", "group", "search", "--name-contains", "FAULT", "--vendor", "other"},
+			expectedFailure: false,
+			expected:        "GROUP DESCRIPTION LATEST " + groupSearchShowDetailsMsg,
+		},
+		{
+			test:            "search for groups including a plugin with --plugin",
+			args:            []string{"plugin", "group", "search", "--plugin", "package", "--show-details"},
+			expectedFailure: false,
+			expected:        "name: vmware-tap/default description: Plugins for TAP latest: v3.3.3 versions: - v3.3.3 name: vmware-tkg/default description: Plugins for TKG latest: v1.1.1 versions: - v1.1.1",
+		},
+		{
+			test:            "search for groups including a plugin with --plugin and --target",
+			args:            []string{"plugin", "group", "search", "--plugin", "isolated-cluster", "--target", "global"},
+			expectedFailure: false,
+			expected:        "GROUP DESCRIPTION LATEST vmware-tkg/default Plugins for TKG v2.2.2 " + groupSearchShowDetailsMsg,
+		},
+		{
+			test:            "search for groups with an invalid --target",
+			args:            []string{"plugin", "group", "search", "--plugin", "package", "--target", "invalid"},
+			expectedFailure: true,
+			expected:        invalidTargetMsg,
+		},
+		{
+			test:            "search for groups with --name and --vendor",
+			args:            []string{"plugin", "group", "search", "--name", "vmware-tap/default", "--vendor", "vmware"},
+			expectedFailure: true,
+			expected:        "if any flags in the group [name vendor] are set none of the others can be; [name vendor] were all set",
+		},
 	}
 
 	// Setup a plugin source and a set of installed plugins
@@ -247,6 +283,12 @@ func TestCompletionPluginGroup(t *testing.T) {
 				"vmware-tkg/default\tPlugins for TKG\n" +
 				":4\n",
 		},
+		{
+			test: "completion for the --name-contains flag value of the group search command",
+			args: []string{"__complete", "plugin", "group", "search", "--name-contains", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ Please enter the text to search for in the name of the plugin-groups\n:4\n",
+		},
 		// ============================
 		// tanzu plugin group get
 		// ============================
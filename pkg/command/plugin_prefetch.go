@@ -0,0 +1,109 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	"github.com/rogpeppe/go-internal/lockedfile"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/config"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginmanager"
+)
+
+const (
+	// pluginPrefetchLogFileName is the name of the file, in the cache directory, receiving
+	// the output of the background prefetch of the plugins
+	pluginPrefetchLogFileName = "plugin_prefetch.log"
+	// pluginPrefetchLockFileName is the name of the file, in the cache directory, locked
+	// by the background prefetch of the plugins while it runs
+	pluginPrefetchLockFileName = "plugin_prefetch.lock"
+)
+
+// prefetchOnly is set by the hidden --prefetch-only flag of the plugin sync command
+var prefetchOnly bool
+
+// backgroundPluginPrefetchEnabled returns true if the user opted in to the background prefetch of the plugins
+func backgroundPluginPrefetchEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv(constants.ConfigVariableBackgroundPluginPrefetch))
+	return enabled
+}
+
+// startBackgroundPluginPrefetch starts, if the user opted in, a CLI process in the background which downloads
+// the plugins recommended by the active contexts to the plugin cache. The context commands then do not wait
+// for the plugins to be downloaded, and a later sync or installation installs the plugins from the cache.
+// It returns false if the background prefetch is not enabled or could not be started.
+func startBackgroundPluginPrefetch(ctxName string) bool {
+	if !backgroundPluginPrefetchEnabled() {
+		return false
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		log.V(6).Infof("unable to start the background prefetch of the plugins: %v", err)
+		return false
+	}
+	if err := os.MkdirAll(common.DefaultCacheDir, 0o755); err != nil {
+		log.V(6).Infof("unable to start the background prefetch of the plugins: %v", err)
+		return false
+	}
+	logFile, err := os.OpenFile(filepath.Join(common.DefaultCacheDir, pluginPrefetchLogFileName), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		log.V(6).Infof("unable to start the background prefetch of the plugins: %v", err)
+		return false
+	}
+	defer logFile.Close()
+
+	prefetchCmd := exec.Command(executable, "plugin", "sync", "--prefetch-only")
+	prefetchCmd.Stdout = logFile
+	prefetchCmd.Stderr = logFile
+	if err := prefetchCmd.Start(); err != nil {
+		log.V(6).Infof("unable to start the background prefetch of the plugins: %v", err)
+		return false
+	}
+	// The prefetch keeps running once this command returns, it is not waited for
+	_ = prefetchCmd.Process.Release()
+
+	log.Infof("The plugins of context '%s' are being downloaded in the background. Run 'tanzu plugin sync' to install them.", ctxName)
+	return true
+}
+
+// prefetchPlugins downloads the plugins recommended by the active contexts to the plugin cache,
+// without installing them. Any failure is only logged, as the plugins are downloaded again when installed.
+// The background prefetches run one at a time, a prefetch waiting for the running one and then only
+// downloading the plugins still missing from the plugin cache. The installation of the plugins does not
+// wait for a running prefetch: the plugin binaries are written to the plugin cache atomically, and the
+// prefetched binaries are verified against their digest before being installed.
+func prefetchPlugins() {
+	if err := os.MkdirAll(common.DefaultCacheDir, 0o755); err != nil {
+		log.Warningf("unable to create the cache directory: %v", err)
+		return
+	}
+	unlock, err := lockedfile.MutexAt(filepath.Join(common.DefaultCacheDir, pluginPrefetchLockFileName)).Lock()
+	if err != nil {
+		log.Warningf("unable to lock the prefetch of the plugins: %v", err)
+		return
+	}
+	defer unlock()
+
+	activeContexts, err := config.GetAllActiveContextsMap()
+	if err != nil {
+		log.Warningf("unable to get the active contexts: %v", err)
+		return
+	}
+	for contextType, ctx := range activeContexts {
+		plugins, err := pluginmanager.DiscoverPluginsForContextType(contextType)
+		if err != nil {
+			log.Warningf("unable to discover the plugins of context '%s': %v", ctx.Name, err)
+		}
+		pluginmanager.PrefetchDiscoveredContextPlugins(plugins)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rogpeppe/go-internal/lockedfile"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+)
+
+func TestBackgroundPluginPrefetchEnabled(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{value: "", expected: false},
+		{value: "false", expected: false},
+		{value: "invalid", expected: false},
+		{value: "1", expected: true},
+		{value: "true", expected: true},
+	}
+	for _, spec := range tests {
+		t.Run(spec.value, func(t *testing.T) {
+			t.Setenv(constants.ConfigVariableBackgroundPluginPrefetch, spec.value)
+			assert.Equal(t, spec.expected, backgroundPluginPrefetchEnabled())
+		})
+	}
+}
+
+func TestStartBackgroundPluginPrefetchNotEnabled(t *testing.T) {
+	t.Setenv(constants.ConfigVariableBackgroundPluginPrefetch, "false")
+	// The context commands then sync the plugins themselves
+	assert.False(t, startBackgroundPluginPrefetch("my-context"))
+}
+
+func TestPrefetchPluginsWaitsForTheRunningPrefetch(t *testing.T) {
+	savedCacheDir := common.DefaultCacheDir
+	common.DefaultCacheDir = t.TempDir()
+	defer func() { common.DefaultCacheDir = savedCacheDir }()
+
+	// Another prefetch is running
+	unlock, err := lockedfile.MutexAt(filepath.Join(common.DefaultCacheDir, pluginPrefetchLockFileName)).Lock()
+	assert.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		prefetchPlugins()
+	}()
+	select {
+	case <-done:
+		t.Fatal("the prefetch did not wait for the running prefetch")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the prefetch did not run once the running prefetch completed")
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
-	"sort"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -15,6 +14,7 @@ import (
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginmanager"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/component"
@@ -22,13 +22,19 @@ import (
 )
 
 var (
-	showDetails bool
-	pluginName  string
+	showDetails    bool
+	pluginName     string
+	searchKeywords string
+	sortBy         string
+	sortOrder      string
+	includeHidden  bool
 )
 
 const searchLongDesc = `Search provides the ability to search for plugins that can be installed.
 The command lists all plugins currently available for installation.
 The search command also provides flags to limit the scope of the search.
+The --keywords flag matches the plugins whose name or description contains all
+the specified keywords, ignoring the case, e.g. --keywords "cluster lifecycle".
 `
 
 func newSearchPluginCmd() *cobra.Command {
@@ -42,6 +48,9 @@ func newSearchPluginCmd() *cobra.Command {
 			if !configtypes.IsValidTarget(targetStr, true, true) {
 				return errors.New(invalidTargetMsg)
 			}
+			if err := plugininventory.ValidatePluginSort(plugininventory.PluginSortField(sortBy), plugininventory.SortOrder(sortOrder)); err != nil {
+				return err
+			}
 			errorList := make([]error, 0)
 			var err error
 			var allPlugins []discovery.Discovered
@@ -58,15 +67,19 @@ func newSearchPluginCmd() *cobra.Command {
 			} else {
 				// Show plugins found in the central repos
 				criteria := &discovery.PluginDiscoveryCriteria{
-					Name:   pluginName,
-					Target: configtypes.StringToTarget(targetStr),
+					Name:          pluginName,
+					Target:        configtypes.StringToTarget(targetStr),
+					SearchText:    searchKeywords,
+					IncludeHidden: includeHidden,
 				}
 				allPlugins, err = pluginmanager.DiscoverStandalonePlugins(discovery.WithPluginDiscoveryCriteria(criteria))
 				if err != nil {
 					errorList = append(errorList, fmt.Errorf("there was an error while discovering standalone plugins, error information: '%w'", err))
 				}
 			}
-			sort.Sort(discovery.DiscoveredSorter(allPlugins))
+			if err := discovery.SortDiscovered(allPlugins, plugininventory.PluginSortField(sortBy), plugininventory.SortOrder(sortOrder)); err != nil {
+				return err
+			}
 
 			if !showDetails {
 				displayPluginsFound(allPlugins, cmd.OutOrStdout())
@@ -80,14 +93,34 @@ func newSearchPluginCmd() *cobra.Command {
 
 	f := searchCmd.Flags()
 	f.BoolVar(&showDetails, "show-details", false, "show the details of the specified plugin, including all available versions")
-	f.StringVarP(&pluginName, "name", "n", "", "limit the search to plugins with the specified name")
+	f.StringVarP(&pluginName, "name", "n", "", "limit the search to plugins with the specified name, which can contain the wildcards '*' and '?'")
 	utils.PanicOnErr(searchCmd.RegisterFlagCompletionFunc("name", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
 		return completionAllPlugins(), cobra.ShellCompDirectiveNoFileComp
 	}))
 
+	f.StringVarP(&searchKeywords, "keywords", "k", "", "limit the search to plugins whose name or description contains all the specified keywords")
+	utils.PanicOnErr(searchCmd.RegisterFlagCompletionFunc("keywords", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return cobra.AppendActiveHelp(nil, "Please enter the keywords to search for in the name and description of the plugins"), cobra.ShellCompDirectiveNoFileComp
+	}))
+
 	f.StringVarP(&outputFormat, "output", "o", "", "output format (yaml|json|table)")
 	utils.PanicOnErr(searchCmd.RegisterFlagCompletionFunc("output", completionGetOutputFormats))
 
+	f.StringVar(&sortBy, "sort-by", "", "sort the plugins by the specified field (name|target|vendor|publisher|recommended-version|most-recent-version|released)")
+	utils.PanicOnErr(searchCmd.RegisterFlagCompletionFunc("sort-by", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		var fields []string
+		for _, field := range plugininventory.PluginSortFields {
+			fields = append(fields, string(field))
+		}
+		return fields, cobra.ShellCompDirectiveNoFileComp
+	}))
+	f.StringVar(&sortOrder, "sort-order", "", "order in which the plugins are sorted (asc|desc)")
+	utils.PanicOnErr(searchCmd.RegisterFlagCompletionFunc("sort-order", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{string(plugininventory.SortOrderAscending), string(plugininventory.SortOrderDescending)}, cobra.ShellCompDirectiveNoFileComp
+	}))
+
+	f.BoolVar(&includeHidden, "include-hidden", false, "include the plugins which are deactivated in the plugin inventory, e.g. the preview plugins")
+
 	f.StringVarP(&local, "local", "", "", "path to local plugin source")
 	msg := fmt.Sprintf("this was done in the %q release, it will be removed following the deprecation policy (6 months). Use the %q flag instead.\n", "v1.0.0", "--local-source")
 	utils.PanicOnErr(f.MarkDeprecated("local", msg))
@@ -106,9 +139,13 @@ func newSearchPluginCmd() *cobra.Command {
 	searchCmd.MarkFlagsMutuallyExclusive("local", "name")
 	searchCmd.MarkFlagsMutuallyExclusive("local", "target")
 	searchCmd.MarkFlagsMutuallyExclusive("local", "show-details")
+	searchCmd.MarkFlagsMutuallyExclusive("local", "keywords")
+	searchCmd.MarkFlagsMutuallyExclusive("local", "include-hidden")
 	searchCmd.MarkFlagsMutuallyExclusive("local-source", "name")
 	searchCmd.MarkFlagsMutuallyExclusive("local-source", "target")
 	searchCmd.MarkFlagsMutuallyExclusive("local-source", "show-details")
+	searchCmd.MarkFlagsMutuallyExclusive("local-source", "keywords")
+	searchCmd.MarkFlagsMutuallyExclusive("local-source", "include-hidden")
 
 	return searchCmd
 }
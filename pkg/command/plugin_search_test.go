@@ -29,6 +29,18 @@ func TestPluginSearch(t *testing.T) {
 			expectedFailure: true,
 			expected:        invalidTargetMsg,
 		},
+		{
+			test:            "invalid sort field",
+			args:            []string{"plugin", "search", "--sort-by", "size"},
+			expectedFailure: true,
+			expected:        "invalid field 'size' to sort the plugins by",
+		},
+		{
+			test:            "invalid sort order",
+			args:            []string{"plugin", "search", "--sort-order", "up"},
+			expectedFailure: true,
+			expected:        "invalid sort order 'up', it must be 'asc' or 'desc'",
+		},
 		{
 			test:            "no --local and --name together",
 			args:            []string{"plugin", "search", "--local", "./", "--name", "myplugin"},
@@ -47,6 +59,12 @@ func TestPluginSearch(t *testing.T) {
 			expectedFailure: true,
 			expected:        "if any flags in the group [local show-details] are set none of the others can be",
 		},
+		{
+			test:            "no --local and --keywords together",
+			args:            []string{"plugin", "search", "--local", "./", "--keywords", "cluster"},
+			expectedFailure: true,
+			expected:        "if any flags in the group [local keywords] are set none of the others can be",
+		},
 	}
 
 	assert := assert.New(t)
@@ -139,12 +157,30 @@ func TestCompletionPluginSearch(t *testing.T) {
 				"login\tPlugin login/global description\n" +
 				":4\n",
 		},
+		{
+			test: "completion for the --keywords flag value",
+			args: []string{"__complete", "plugin", "search", "--keywords", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "_activeHelp_ Please enter the keywords to search for in the name and description of the plugins\n:4\n",
+		},
 		{
 			test: "completion for the --output flag value",
 			args: []string{"__complete", "plugin", "search", "--output", ""},
 			// ":4" is the value of the ShellCompDirectiveNoFileComp
 			expected: expectedOutForOutputFlag + ":4\n",
 		},
+		{
+			test: "completion for the --sort-by flag value",
+			args: []string{"__complete", "plugin", "search", "--sort-by", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "name\ntarget\nvendor\npublisher\nrecommended-version\nmost-recent-version\n:4\n",
+		},
+		{
+			test: "completion for the --sort-order flag value",
+			args: []string{"__complete", "plugin", "search", "--sort-order", ""},
+			// ":4" is the value of the ShellCompDirectiveNoFileComp
+			expected: "asc\ndesc\n:4\n",
+		},
 		{
 			test: "completion for the --target flag value",
 			args: []string{"__complete", "plugin", "search", "--target", ""},
@@ -0,0 +1,74 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginmanager"
+)
+
+// defaultSyncWatchInterval is the default interval between the reconciliations
+// of the installed plugins with the TanzuCLIPluginSet resources of the cluster
+const defaultSyncWatchInterval = time.Minute
+
+var (
+	syncFromCluster   bool
+	syncWatch         bool
+	syncWatchInterval time.Duration
+)
+
+// validateSyncFromClusterFlags returns an error if the flags only valid with --from-cluster are used without it
+func validateSyncFromClusterFlags(cmd *cobra.Command) error {
+	if syncFromCluster {
+		if cmd.Flags().Changed("watch-interval") && !syncWatch {
+			return errors.New("the --watch-interval flag can only be used with the --watch flag")
+		}
+		if syncWatchInterval <= 0 {
+			return errors.New("the --watch-interval flag must be a positive duration")
+		}
+		return nil
+	}
+	for _, flag := range []string{"watch", "watch-interval", "kubeconfig", "kubecontext"} {
+		if cmd.Flags().Changed(flag) {
+			return errors.Errorf("the --%s flag can only be used with the --from-cluster flag", flag)
+		}
+	}
+	return nil
+}
+
+// syncPluginsFromCluster installs the plugins declared by the TanzuCLIPluginSet resources of the cluster.
+// With the --watch flag, the installed plugins are reconciled with the cluster at every interval until
+// the command is interrupted, and the failures of a reconciliation are only reported.
+func syncPluginsFromCluster(cmd *cobra.Command) error {
+	if !syncWatch {
+		if err := pluginmanager.SyncPluginsFromCluster(kubeConfig, kubeContext); err != nil {
+			return err
+		}
+		log.Success("Done")
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	log.Infof("Reconciling the installed plugins with the cluster every %s", syncWatchInterval)
+	for {
+		if err := pluginmanager.SyncPluginsFromCluster(kubeConfig, kubeContext); err != nil {
+			log.Warningf("unable to sync the plugins from the cluster: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(syncWatchInterval):
+		}
+	}
+}
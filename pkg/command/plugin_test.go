@@ -89,7 +89,16 @@ func TestPluginList(t *testing.T) {
 			targets:         []configtypes.Target{configtypes.TargetK8s},
 			args:            []string{"plugin", "describe", "foo", "-o", "json"},
 			expectedFailure: false,
-			expected:        `[ { "description": "some foo description", "installationpath": "%v", "name": "foo", "status": "installed", "target": "kubernetes", "version": "v0.1.0" } ]`,
+			expected:        `[ { "changelog": "", "description": "some foo description", "installationpath": "%v", "name": "foo", "releasenotes": "", "status": "installed", "target": "kubernetes", "version": "v0.1.0" } ]`,
+		},
+		{
+			test:            "plugin describe json output requested with the license",
+			plugins:         []string{"foo"},
+			versions:        []string{"v0.1.0"},
+			targets:         []configtypes.Target{configtypes.TargetK8s},
+			args:            []string{"plugin", "describe", "foo", "-o", "json", "--show-license"},
+			expectedFailure: false,
+			expected:        `[ { "changelog": "", "description": "some foo description", "installationpath": "%v", "license": "", "name": "foo", "releasenotes": "", "sbom": "", "status": "installed", "target": "kubernetes", "version": "v0.1.0" } ]`,
 		},
 	}
 
@@ -417,6 +426,73 @@ func TestInstallPlugin(t *testing.T) {
 	}
 }
 
+func TestSyncPlugin(t *testing.T) {
+	tests := []struct {
+		test             string
+		args             []string
+		expectedErrorMsg string
+	}{
+		{
+			test:             "no --watch without --from-cluster",
+			args:             []string{"plugin", "sync", "--watch"},
+			expectedErrorMsg: "the --watch flag can only be used with the --from-cluster flag",
+		},
+		{
+			test:             "no --kubeconfig without --from-cluster",
+			args:             []string{"plugin", "sync", "--kubeconfig", "config"},
+			expectedErrorMsg: "the --kubeconfig flag can only be used with the --from-cluster flag",
+		},
+		{
+			test:             "no --watch-interval without --watch",
+			args:             []string{"plugin", "sync", "--from-cluster", "--watch-interval", "5m"},
+			expectedErrorMsg: "the --watch-interval flag can only be used with the --watch flag",
+		},
+		{
+			test:             "no invalid --watch-interval",
+			args:             []string{"plugin", "sync", "--from-cluster", "--watch", "--watch-interval", "0s"},
+			expectedErrorMsg: "the --watch-interval flag must be a positive duration",
+		},
+		{
+			test:             "no sync from an unreachable cluster",
+			args:             []string{"plugin", "sync", "--from-cluster", "--kubeconfig", "does-not-exist"},
+			expectedErrorMsg: `Failed to load Kubeconfig file from "does-not-exist"`,
+		},
+	}
+
+	assert := assert.New(t)
+
+	tkgConfigFile, err := os.CreateTemp("", "config")
+	assert.Nil(err)
+	os.Setenv("TANZU_CONFIG", tkgConfigFile.Name())
+
+	tkgConfigFileNG, err := os.CreateTemp("", "config_ng")
+	assert.Nil(err)
+	os.Setenv("TANZU_CONFIG_NEXT_GEN", tkgConfigFileNG.Name())
+	os.Setenv("TANZU_CLI_CEIP_OPT_IN_PROMPT_ANSWER", "No")
+	os.Setenv("TANZU_CLI_EULA_PROMPT_ANSWER", "Yes")
+
+	defer func() {
+		os.Unsetenv("TANZU_CONFIG")
+		os.Unsetenv("TANZU_CONFIG_NEXT_GEN")
+		os.Unsetenv("TANZU_CLI_CEIP_OPT_IN_PROMPT_ANSWER")
+		os.Unsetenv("TANZU_CLI_EULA_PROMPT_ANSWER")
+		os.RemoveAll(tkgConfigFile.Name())
+		os.RemoveAll(tkgConfigFileNG.Name())
+	}()
+
+	for _, spec := range tests {
+		t.Run(spec.test, func(t *testing.T) {
+			rootCmd, err := NewRootCmd()
+			assert.Nil(err)
+			rootCmd.SetArgs(spec.args)
+
+			err = rootCmd.Execute()
+			assert.NotNil(err)
+			assert.Contains(err.Error(), spec.expectedErrorMsg)
+		})
+	}
+}
+
 func TestUpgradePlugin(t *testing.T) {
 	tests := []struct {
 		test             string
@@ -472,6 +548,80 @@ func TestUpgradePlugin(t *testing.T) {
 	}
 }
 
+func TestPluginCompat(t *testing.T) {
+	tests := []struct {
+		test            string
+		runtimeVersions []string
+		args            []string
+		expected        string
+		expectedFailure bool
+	}{
+		{
+			test:            "with compatible plugins",
+			runtimeVersions: []string{"v0.11.0"},
+			args:            []string{"plugin", "compat", "-o", "json"},
+			expected:        `[ { "action": "", "min_cli_version": "", "name": "foo0", "plugin_runtime": "v0.11.0", "reason": "", "status": "compatible", "target": "kubernetes", "version": "v0.1.0" } ]`,
+		},
+		{
+			test:            "with a plugin built with an unsupported plugin runtime",
+			runtimeVersions: []string{"v0.11.0", "v0.9.0"},
+			args:            []string{"plugin", "compat"},
+			expectedFailure: true,
+			expected:        "1 of the installed plugins are not compatible with the CLI",
+		},
+	}
+
+	for _, spec := range tests {
+		t.Run(spec.test, func(t *testing.T) {
+			assert := assert.New(t)
+
+			configFile, err := os.CreateTemp("", "config")
+			assert.Nil(err)
+			defer os.RemoveAll(configFile.Name())
+			t.Setenv("TANZU_CONFIG", configFile.Name())
+			configFileNG, err := os.CreateTemp("", "config_ng")
+			assert.Nil(err)
+			defer os.RemoveAll(configFileNG.Name())
+			t.Setenv("TANZU_CONFIG_NEXT_GEN", configFileNG.Name())
+			dir, err := os.MkdirTemp("", "tanzu-cli-root-cmd")
+			assert.Nil(err)
+			defer os.RemoveAll(dir)
+			t.Setenv("TEST_CUSTOM_CATALOG_CACHE_DIR", dir)
+			t.Setenv("TANZU_CLI_CEIP_OPT_IN_PROMPT_ANSWER", "No")
+			t.Setenv("TANZU_CLI_EULA_PROMPT_ANSWER", "Yes")
+
+			cc, err := catalog.NewContextCatalogUpdater("")
+			assert.Nil(err)
+			for i, runtimeVersion := range spec.runtimeVersions {
+				err = cc.Upsert(&cli.PluginInfo{
+					Name:                 fmt.Sprintf("foo%d", i),
+					Version:              "v0.1.0",
+					InstallationPath:     filepath.Join(dir, fmt.Sprintf("foo%d", i)),
+					Target:               configtypes.TargetK8s,
+					PluginRuntimeVersion: runtimeVersion,
+				})
+				assert.Nil(err)
+			}
+			cc.Unlock()
+
+			rootCmd, err := NewRootCmd()
+			assert.Nil(err)
+			rootCmd.SetArgs(spec.args)
+			b := bytes.NewBufferString("")
+			rootCmd.SetOut(b)
+
+			err = rootCmd.Execute()
+			assert.Equal(spec.expectedFailure, err != nil)
+			if spec.expectedFailure {
+				assert.Contains(err.Error(), spec.expected)
+			} else {
+				// whitespace-agnostic match
+				assert.Contains(strings.Join(strings.Fields(b.String()), " "), spec.expected)
+			}
+		})
+	}
+}
+
 func TestCompletionPlugin(t *testing.T) {
 	// This is global logic and needs not be tested for each
 	// command.  Let's deactivate it.
@@ -991,6 +1141,12 @@ func resetPluginCommandFlags() {
 	group = ""
 	showNonMandatory = false
 	groupID = ""
+	groupVendor = ""
+	groupPublisher = ""
+	groupNameContains = ""
+	groupPluginName = ""
 	showDetails = false
 	pluginName = ""
+	showLicense = false
+	includeHidden = false
 }
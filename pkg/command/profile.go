@@ -0,0 +1,126 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	profileFlag    = "--profile"
+	profileDirFlag = "--profile-dir"
+
+	profileCPU   = "cpu"
+	profileMem   = "mem"
+	profileTrace = "trace"
+)
+
+// profileOptions are the options of the hidden --profile and --profile-dir flags
+type profileOptions struct {
+	// profile is the type of profile to write: cpu, mem or trace
+	profile string
+	// dir is the directory where the profile is written
+	dir string
+}
+
+// parseProfileFlags extracts the hidden --profile and --profile-dir flags from the arguments of the CLI.
+// The flags are only recognized before the command, e.g. "tanzu --profile=cpu plugin list", so they
+// cannot be confused with the flags of the plugins, and they are removed from the returned arguments.
+// They are handled outside of cobra, so that the profile also covers the setup of the command tree.
+func parseProfileFlags(args []string) (*profileOptions, []string, error) {
+	var opts *profileOptions
+	i := 0
+	for ; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		if name != profileFlag && name != profileDirFlag {
+			break
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, nil, errors.Errorf("flag needs an argument: %s", name)
+			}
+			i++
+			value = args[i]
+		}
+		if opts == nil {
+			opts = &profileOptions{}
+		}
+		if name == profileFlag {
+			opts.profile = value
+		} else {
+			opts.dir = value
+		}
+	}
+	if opts == nil {
+		return nil, args, nil
+	}
+
+	switch opts.profile {
+	case profileCPU, profileMem, profileTrace:
+	case "":
+		return nil, nil, errors.Errorf("the %s flag is required with the %s flag", profileFlag, profileDirFlag)
+	default:
+		return nil, nil, errors.Errorf("invalid value %q for the %s flag, it must be one of: %s, %s, %s", opts.profile, profileFlag, profileCPU, profileMem, profileTrace)
+	}
+	if opts.dir == "" {
+		opts.dir = "."
+	}
+	return opts, args[i:], nil
+}
+
+// startProfiling starts the profile of the current invocation of the CLI and returns the function
+// to call at the end of the invocation to write the profile to the profile directory.
+// Only the CLI process is profiled, not the plugins it runs.
+func startProfiling(opts *profileOptions) (func() error, error) {
+	if err := os.MkdirAll(opts.dir, 0o755); err != nil {
+		return nil, errors.Wrapf(err, "unable to create the profile directory %q", opts.dir)
+	}
+	ext := "pprof"
+	if opts.profile == profileTrace {
+		ext = "out"
+	}
+	profileFile := filepath.Join(opts.dir, fmt.Sprintf("tanzu-%s-%s.%s", opts.profile, time.Now().Format("20060102-150405"), ext))
+	f, err := os.Create(profileFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to create the profile file %q", profileFile)
+	}
+
+	switch opts.profile {
+	case profileCPU:
+		err = pprof.StartCPUProfile(f)
+	case profileTrace:
+		err = trace.Start(f)
+	}
+	if err != nil {
+		f.Close()
+		return nil, errors.Wrapf(err, "unable to start the %s profile", opts.profile)
+	}
+
+	return func() error {
+		defer f.Close()
+		switch opts.profile {
+		case profileCPU:
+			pprof.StopCPUProfile()
+		case profileTrace:
+			trace.Stop()
+		case profileMem:
+			// Get up-to-date statistics of the allocations
+			runtime.GC()
+			if err := pprof.WriteHeapProfile(f); err != nil {
+				return errors.Wrap(err, "unable to write the mem profile")
+			}
+		}
+		fmt.Fprintf(os.Stderr, "The %s profile was written to %s\n", opts.profile, profileFile)
+		return nil
+	}, nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProfileFlags(t *testing.T) {
+	tests := []struct {
+		test         string
+		args         []string
+		expectedOpts *profileOptions
+		expectedArgs []string
+		expectedErr  string
+	}{
+		{
+			test:         "no profile flag",
+			args:         []string{"plugin", "list"},
+			expectedArgs: []string{"plugin", "list"},
+		},
+		{
+			test:         "profile flag after the command is left to the command",
+			args:         []string{"myplugin", "--profile=cpu"},
+			expectedArgs: []string{"myplugin", "--profile=cpu"},
+		},
+		{
+			test:         "profile flag with its value",
+			args:         []string{"--profile=cpu", "plugin", "list"},
+			expectedOpts: &profileOptions{profile: "cpu", dir: "."},
+			expectedArgs: []string{"plugin", "list"},
+		},
+		{
+			test:         "profile and profile-dir flags with separate values",
+			args:         []string{"--profile", "trace", "--profile-dir", "/tmp/profiles", "plugin", "list"},
+			expectedOpts: &profileOptions{profile: "trace", dir: "/tmp/profiles"},
+			expectedArgs: []string{"plugin", "list"},
+		},
+		{
+			test:        "invalid profile",
+			args:        []string{"--profile=block", "plugin", "list"},
+			expectedErr: `invalid value "block" for the --profile flag, it must be one of: cpu, mem, trace`,
+		},
+		{
+			test:        "profile-dir flag without profile flag",
+			args:        []string{"--profile-dir=/tmp", "plugin", "list"},
+			expectedErr: "the --profile flag is required with the --profile-dir flag",
+		},
+		{
+			test:        "profile flag without value",
+			args:        []string{"--profile"},
+			expectedErr: "flag needs an argument: --profile",
+		},
+	}
+	for _, spec := range tests {
+		t.Run(spec.test, func(t *testing.T) {
+			opts, args, err := parseProfileFlags(spec.args)
+			if spec.expectedErr != "" {
+				assert.EqualError(t, err, spec.expectedErr)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, spec.expectedOpts, opts)
+			assert.Equal(t, spec.expectedArgs, args)
+		})
+	}
+}
+
+func TestStartProfiling(t *testing.T) {
+	for _, profile := range []string{profileCPU, profileMem, profileTrace} {
+		t.Run(profile, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "profiles")
+			stopProfiling, err := startProfiling(&profileOptions{profile: profile, dir: dir})
+			assert.Nil(t, err)
+			assert.Nil(t, stopProfiling())
+
+			files, err := filepath.Glob(filepath.Join(dir, "tanzu-"+profile+"-*"))
+			assert.Nil(t, err)
+			assert.Len(t, files, 1)
+			fi, err := os.Stat(files[0])
+			assert.Nil(t, err)
+			assert.NotZero(t, fi.Size())
+		})
+	}
+}
@@ -58,25 +58,28 @@ func NewRootCmd() (*cobra.Command, error) { //nolint: gocyclo,funlen
 		//       If we decide to fold this functionality into existing 'tanzu telemetry' plugin
 		newCEIPParticipationCmd(),
 		newGenAllDocsCmd(),
+		newDoctorCmd(),
+		newCacheCmd(),
+		newAPICmd(),
 	)
 	if _, err := ensureCLIInstanceID(); err != nil {
 		return nil, errors.Wrap(err, "failed to ensure CLI ID")
 	}
 
-	// Setup the commands for the plugins under the k8s and tmc targets
-	if err := setupTargetPlugins(); err != nil {
-		return nil, err
-	}
-
+	// Read the installed plugins only once, the catalog and config reads are a
+	// significant part of the startup time of the CLI
 	allPlugins, err := pluginsupplier.GetInstalledPlugins()
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("unable to find installed plugins: %w", err)
 	}
 	plugins, err := pluginsupplier.FilterPluginsByActiveContextType(allPlugins)
 	if err != nil {
 		return nil, err
 	}
 
+	// Setup the commands for the plugins under the k8s and tmc targets
+	setupTargetPlugins(plugins)
+
 	telemetry.Client().SetInstalledPlugins(plugins)
 	if err = config.CopyLegacyConfigDir(); err != nil {
 		return nil, fmt.Errorf("failed to copy legacy configuration directory to new location: %w", err)
@@ -127,6 +130,7 @@ func NewRootCmd() (*cobra.Command, error) { //nolint: gocyclo,funlen
 
 	remapCommandTree(rootCmd, plugins)
 	updateTargetCommandGroupVisibility()
+	setupPluginCommandTreeCompletion(rootCmd, plugins)
 
 	if len(maskedPluginsWithPluginOverlap) > 0 {
 		catalog.DeleteIncorrectPluginEntriesFromCatalog()
@@ -202,23 +206,13 @@ func updateTargetCommandGroupVisibility() {
 }
 
 // setupTargetPlugins sets up the commands for the plugins under the k8s and tmc targets
-func setupTargetPlugins() error {
+func setupTargetPlugins(plugins []cli.PluginInfo) {
 	mapTargetToCmd := map[configtypes.Target]*cobra.Command{
 		configtypes.TargetK8s:        k8sCmd,
 		configtypes.TargetTMC:        tmcCmd,
 		configtypes.TargetOperations: opsCmd,
 	}
 
-	installedPlugins, err := pluginsupplier.GetInstalledPlugins()
-	if err != nil {
-		return fmt.Errorf("unable to find installed plugins: %w", err)
-	}
-
-	plugins, err := pluginsupplier.FilterPluginsByActiveContextType(installedPlugins)
-	if err != nil {
-		return err
-	}
-
 	// Insert the plugin commands under the appropriate target command
 	for i := range plugins {
 		if targetCmd, exists := mapTargetToCmd[plugins[i].Target]; exists {
@@ -228,8 +222,6 @@ func setupTargetPlugins() error {
 			}
 		}
 	}
-
-	return nil
 }
 
 func newRootCmd() *cobra.Command {
@@ -541,8 +533,16 @@ func shouldSkipEssentialPlugins(cmd *cobra.Command) bool {
 		// Avoid trying to install essential plugins when the user initializes or updates the plugin
 		// source information since the essential plugins installation would use the old plugin source
 		"tanzu plugin source",
+		// Printing the help does not need the essential plugins, and checking them requires
+		// reading, and possibly downloading, the plugin inventory
+		"tanzu help",
 	}
 
+	// The dispatch to an installed plugin should be as fast as possible, so the essential
+	// plugins are only checked when running the core commands of the CLI
+	if isPluginCommand(cmd) {
+		return true
+	}
 	return isSkipCommand(skipCommandsForEssentials, cmd.CommandPath())
 }
 
@@ -562,10 +562,31 @@ func shouldSkipVersionCheck(cmd *cobra.Command) bool {
 
 // Execute executes the CLI.
 func Execute() error {
+	// The hidden --profile flag is handled before creating the root command,
+	// so that the profile includes the setup of the command tree
+	profileOpts, args, err := parseProfileFlags(os.Args[1:])
+	if err != nil {
+		return err
+	}
+	if profileOpts != nil {
+		stopProfiling, err := startProfiling(profileOpts)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := stopProfiling(); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}()
+	}
+
 	root, err := NewRootCmd()
 	if err != nil {
 		return err
 	}
+	if profileOpts != nil {
+		root.SetArgs(args)
+	}
 	executionErr := root.Execute()
 	exitCode := 0
 	if executionErr != nil {
@@ -585,6 +606,10 @@ func Execute() error {
 	} else if sendErr := telemetry.Client().SendMetrics(context.Background(), 0); sendErr != nil {
 		telemetry.LogError(sendErr, "")
 	}
+	// The export to the OTLP endpoint of the user is independent of the CEIP metrics store
+	if exportErr := telemetry.Client().ExportMetrics(context.Background()); exportErr != nil {
+		telemetry.LogError(exportErr, "")
+	}
 	return executionErr
 }
 
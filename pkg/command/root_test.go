@@ -12,6 +12,7 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/config"
@@ -20,6 +21,7 @@ import (
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/catalog"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
 )
 
@@ -860,9 +862,12 @@ func TestCompletionShortHelpInActiveHelp(t *testing.T) {
 			args: []string{"__complete", "plugin", ""},
 			// ":4" is the value of the ShellCompDirectiveNoFileComp
 			expected: "clean\tClean the plugins\n" +
+				"compat\tCheck the compatibility of the installed plugins with the CLI\n" +
 				"describe\tDescribe a plugin\n" +
 				"download-bundle\tDownload plugin bundle to the local system\n" +
+				"gc\tRemove orphaned plugin binaries\n" +
 				"group\tManage plugin-groups\n" +
+				"inspect-bundle\tInspect the content of a plugin bundle\n" +
 				"install\tInstall a plugin\n" +
 				"list\tList installed plugins\n" +
 				"search\tSearch for available plugins\n" +
@@ -871,6 +876,7 @@ func TestCompletionShortHelpInActiveHelp(t *testing.T) {
 				"uninstall\tUninstall a plugin\n" +
 				"upgrade\tUpgrade a plugin\n" +
 				"upload-bundle\tUpload plugin bundle to a repository\n" +
+				"verify-bundle\tVerify a plugin bundle before uploading it\n" +
 				"_activeHelp_ Command help: Manage CLI plugins\n" +
 				":4\n",
 		},
@@ -1318,3 +1324,30 @@ func TestPluginLevelRemapping(t *testing.T) {
 		})
 	}
 }
+
+func TestShouldSkipEssentialPlugins(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "tanzu"}
+	pluginCmd := &cobra.Command{Use: "plugin"}
+	pluginListCmd := &cobra.Command{Use: "list"}
+	pluginSourceCmd := &cobra.Command{Use: "source"}
+	helpCmd := &cobra.Command{Use: "help"}
+	installedPluginCmd := &cobra.Command{Use: "dummy", Annotations: map[string]string{"type": common.CommandTypePlugin}}
+	pluginCmd.AddCommand(pluginListCmd, pluginSourceCmd)
+	rootCmd.AddCommand(pluginCmd, helpCmd, installedPluginCmd)
+
+	tests := []struct {
+		name     string
+		cmd      *cobra.Command
+		expected bool
+	}{
+		{name: "core command", cmd: pluginListCmd, expected: false},
+		{name: "core command in the skip list", cmd: pluginSourceCmd, expected: true},
+		{name: "help command", cmd: helpCmd, expected: true},
+		{name: "installed plugin", cmd: installedPluginCmd, expected: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, shouldSkipEssentialPlugins(tt.cmd))
+		})
+	}
+}
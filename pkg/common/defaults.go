@@ -30,4 +30,9 @@ const (
 	// the inventory of the discovery will be downloaded and stored.
 	// It should be used as a sub-directory of the cache directory (DefaultCacheDir).
 	PluginInventoryDirName = "plugin_inventory"
+
+	// ImageCacheDirName is the name of the directory where the content of the
+	// downloaded images is cached. It should be used as a sub-directory of the
+	// cache directory (DefaultCacheDir).
+	ImageCacheDirName = "images"
 )
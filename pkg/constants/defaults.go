@@ -31,6 +31,34 @@ const (
 	// For testing, it can be overridden using the environment variable TANZU_CLI_PLUGIN_DB_CACHE_TTL_SECONDS.
 	DefaultInventoryRefreshTTLSeconds = 30 * 60 // 30 minutes
 
+	// DefaultPluginBinaryRetentionCount is the default number of versions of each plugin kept in the plugin store.
+	// It can be overridden using the environment variable TANZU_CLI_PLUGIN_BINARY_RETENTION_COUNT.
+	DefaultPluginBinaryRetentionCount = 3
+
+	// DefaultRegistryOperationTimeoutSeconds is the default timeout of each attempt of an image operation.
+	// It can be overridden using the environment variable TANZU_CLI_REGISTRY_OPERATION_TIMEOUT_SECONDS.
+	DefaultRegistryOperationTimeoutSeconds = 10 * 60 // 10 minutes
+
+	// DefaultRegistryOperationRetryCount is the default number of retries of an image operation failing with a transient error.
+	// It can be overridden using the environment variable TANZU_CLI_REGISTRY_OPERATION_RETRY_COUNT.
+	DefaultRegistryOperationRetryCount = 3
+
+	// DefaultRegistryOperationRetryBackoffSeconds is the default delay before the first retry of an image operation.
+	// It can be overridden using the environment variable TANZU_CLI_REGISTRY_OPERATION_RETRY_BACKOFF_SECONDS.
+	DefaultRegistryOperationRetryBackoffSeconds = 2
+
+	// DefaultRegistryOperationRetryMaxBackoffSeconds is the default maximum delay before a retry of an image operation.
+	// It can be overridden using the environment variable TANZU_CLI_REGISTRY_OPERATION_RETRY_MAX_BACKOFF_SECONDS.
+	DefaultRegistryOperationRetryMaxBackoffSeconds = 60
+
+	// DefaultRegistryDownloadConcurrency is the default number of image layers downloaded concurrently.
+	// It can be overridden using the environment variable TANZU_CLI_REGISTRY_DOWNLOAD_CONCURRENCY.
+	DefaultRegistryDownloadConcurrency = 4
+
+	// DefaultImageCacheMaxSizeMB is the default maximum size of the cache of the downloaded images.
+	// It can be overridden using the environment variable TANZU_CLI_IMAGE_CACHE_MAX_SIZE_MB.
+	DefaultImageCacheMaxSizeMB = 1024
+
 	// TanzuContextPluginDiscoveryEndpointPath specifies the default plugin discovery endpoint path
 	// Note: This path value needs to be updated once the Tanzu context backend support the context-scoped
 	// plugin discovery and the endpoint value gets finalized
@@ -25,6 +25,15 @@ const (
 	ShowTelemetryConsoleLogs          = "TANZU_CLI_SHOW_TELEMETRY_CONSOLE_LOGS"
 	TelemetrySuperColliderEnvironment = "TANZU_CLI_SUPERCOLLIDER_ENVIRONMENT"
 
+	// TelemetryOTLPEndpoint is the base URL of the OTLP/HTTP endpoint the command spans and metrics are exported to
+	TelemetryOTLPEndpoint = "TANZU_CLI_TELEMETRY_OTLP_ENDPOINT"
+	// TelemetryOTLPHeaders is a comma separated list of key=value headers sent to the OTLP endpoint, e.g. for authentication
+	TelemetryOTLPHeaders = "TANZU_CLI_TELEMETRY_OTLP_HEADERS"
+
+	// E2ETestCSPIssuer overrides the CSP issuer with a mock issuer, honored only in E2E test environment
+	// and for an issuer on the loopback interface or on a CSP host
+	E2ETestCSPIssuer = "TANZU_CLI_E2E_TEST_CSP_ISSUER"
+
 	// TanzuCLIEssentialsPluginGroupName is used to override and customize the default essentials plugin group name
 	TanzuCLIEssentialsPluginGroupName = "TANZU_CLI_ESSENTIALS_PLUGIN_GROUP_NAME"
 
@@ -50,6 +59,9 @@ const (
 	// ConfigVariablePluginDBCacheRefreshThresholdSeconds Change the default value of db cache refresh threshold
 	ConfigVariablePluginDBCacheRefreshThresholdSeconds = "TANZU_CLI_PLUGIN_DB_CACHE_REFRESH_THRESHOLD_SECONDS"
 
+	// ConfigVariablePluginBinaryRetentionCount Change the default number of versions of each plugin kept in the plugin store
+	ConfigVariablePluginBinaryRetentionCount = "TANZU_CLI_PLUGIN_BINARY_RETENTION_COUNT"
+
 	// ConfigVariableRecommendVersionDelayDays Change the default value of the delay between printing a recommended version message
 	ConfigVariableRecommendVersionDelayDays = "TANZU_CLI_RECOMMEND_VERSION_DELAY_DAYS"
 
@@ -67,6 +79,50 @@ const (
 	// default discovery endpoint configured with TanzuContextPluginDiscoveryEndpointPath will be used
 	TanzuPluginDiscoveryPathforTanzuContext = "TANZU_CLI_PLUGIN_DISCOVERY_PATH_FOR_TANZU_CONTEXT"
 
+	// ConfigVariableImageOperationsImplementation selects the implementation used for the OCI image operations.
+	// Possible values are "imgpkg" (default) and "go-containerregistry". The latter can be used with
+	// registries which do not handle the imgpkg bundle semantics properly.
+	ConfigVariableImageOperationsImplementation = "TANZU_CLI_IMAGE_OPERATIONS_IMPLEMENTATION"
+
+	// ConfigVariableRegistryOperationTimeoutSeconds Change the default timeout of each attempt of an image operation.
+	// A value of 0 disables the timeout.
+	ConfigVariableRegistryOperationTimeoutSeconds = "TANZU_CLI_REGISTRY_OPERATION_TIMEOUT_SECONDS"
+
+	// ConfigVariableRegistryOperationRetryCount Change the default number of retries of an image operation failing with a transient error
+	ConfigVariableRegistryOperationRetryCount = "TANZU_CLI_REGISTRY_OPERATION_RETRY_COUNT"
+
+	// ConfigVariableRegistryOperationRetryBackoffSeconds Change the default delay before the first retry of an image operation.
+	// The delay is doubled for each subsequent retry.
+	ConfigVariableRegistryOperationRetryBackoffSeconds = "TANZU_CLI_REGISTRY_OPERATION_RETRY_BACKOFF_SECONDS"
+
+	// ConfigVariableRegistryOperationRetryMaxBackoffSeconds Change the default maximum delay before a retry of an image operation.
+	// A value of 0 removes the maximum.
+	ConfigVariableRegistryOperationRetryMaxBackoffSeconds = "TANZU_CLI_REGISTRY_OPERATION_RETRY_MAX_BACKOFF_SECONDS"
+
+	// ConfigVariableRegistryDownloadConcurrency Change the default number of image layers downloaded concurrently
+	ConfigVariableRegistryDownloadConcurrency = "TANZU_CLI_REGISTRY_DOWNLOAD_CONCURRENCY"
+
+	// ConfigVariableRegistryUploadChunkSize Maximum size in bytes of the requests uploading the image layers.
+	// The layers are uploaded in chunks of this size to registries or proxies limiting the size of the requests.
+	// Only used by the go-containerregistry implementation of the image operations. Each layer is uploaded at once if not set.
+	ConfigVariableRegistryUploadChunkSize = "TANZU_CLI_REGISTRY_UPLOAD_CHUNK_SIZE"
+
+	// ConfigVariableWASMRuntime Command executing the plugins distributed as WebAssembly modules, the path of the module
+	// and the arguments of the plugin being appended to it. Defaults to `wasmtime run`.
+	ConfigVariableWASMRuntime = "TANZU_CLI_WASM_RUNTIME"
+
+	// ConfigVariableRegistryAllowlist Comma-separated list of the registry hosts the CLI is allowed to access.
+	// A `*.` prefix allows all the sub-domains of a domain. All the registries are allowed if not set.
+	ConfigVariableRegistryAllowlist = "TANZU_CLI_REGISTRY_ALLOWLIST"
+
+	// ConfigVariableImageCacheMaxSizeMB Change the default maximum size of the cache of the downloaded images.
+	// The least recently used images are removed from the cache when the limit is reached. A value of 0 disables the cache.
+	ConfigVariableImageCacheMaxSizeMB = "TANZU_CLI_IMAGE_CACHE_MAX_SIZE_MB"
+
+	// ConfigVariableBackgroundPluginPrefetch Download the plugins recommended by a context in the background when the
+	// context is created or activated, instead of installing them. The plugins are then installed from the plugin cache.
+	ConfigVariableBackgroundPluginPrefetch = "TANZU_CLI_BACKGROUND_PLUGIN_PREFETCH"
+
 	// SkipPluginGroupVerificationOnPublish skips the plugin group verification of whether the plugins specified
 	// in the plugin-group are available in the database or not.
 	// Note: THIS SHOULD ONLY BE USED FOR TEST AND NON PRODUCTION ENVIRONMENTS.
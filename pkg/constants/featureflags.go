@@ -11,6 +11,10 @@ const (
 	// FeaturePluginDiscoveryForTanzuContext determines whether to enable context-scoped plugin discovery for Tanzu context.
 	// This is disabled by default
 	FeaturePluginDiscoveryForTanzuContext = "features.global.plugin-discovery-for-tanzu-context"
+
+	// FeatureAlternatePluginArtifactTypes determines whether plugins can be distributed as scripts executed with
+	// their declared runtime or as WebAssembly modules, in addition to native binaries. This is disabled by default.
+	FeatureAlternatePluginArtifactTypes = "features.global.alternate-plugin-artifact-types-beta"
 )
 
 // DefaultCliFeatureFlags is used to populate an initially empty config file with default values for feature flags.
@@ -26,7 +30,8 @@ const (
 // no conflict with previous installs (that have a false value for the entry "features.global.foo-bar-beta").
 var (
 	DefaultCliFeatureFlags = map[string]bool{
-		FeatureContextCommand: true,
+		FeatureContextCommand:               true,
+		FeatureAlternatePluginArtifactTypes: false,
 		// Do NOT include the test feature flag to disable the central repo.
 		// We don't want to publicize this feature flag.
 		// It defaults to false when not specified, which is what is needed.
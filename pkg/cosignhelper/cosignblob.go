@@ -0,0 +1,71 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cosignhelper
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	sigs "github.com/sigstore/cosign/v2/pkg/signature"
+)
+
+// The files are signed in the same format as the "cosign sign-blob" command, the signature being the
+// base64 encoding of the signature of the content of the file, so they can also be verified with
+// "cosign verify-blob --key <public-key> --signature <signature> <file>"
+
+// SignBlob signs the content of the file with the private key (or the KMS URI) and returns the
+// base64 encoded signature. The password of the private key, if any, is read from the
+// COSIGN_PASSWORD environment variable
+func SignBlob(ctx context.Context, keyRef, file string) (string, error) {
+	signer, err := sigs.SignerVerifierFromKeyRef(ctx, keyRef, getPassFromEnv)
+	if err != nil {
+		return "", fmt.Errorf("loading private key: %w", err)
+	}
+	content, err := os.Open(file)
+	if err != nil {
+		return "", fmt.Errorf("reading the file %s: %w", file, err)
+	}
+	defer content.Close()
+
+	signatureBytes, err := signer.SignMessage(content)
+	if err != nil {
+		return "", fmt.Errorf("signing the file %s: %w", file, err)
+	}
+	return base64.StdEncoding.EncodeToString(signatureBytes), nil
+}
+
+// ValidateSigningKey checks the private key (or the KMS URI) can be used to sign files
+func ValidateSigningKey(ctx context.Context, keyRef string) error {
+	if _, err := sigs.SignerVerifierFromKeyRef(ctx, keyRef, getPassFromEnv); err != nil {
+		return fmt.Errorf("loading private key: %w", err)
+	}
+	return nil
+}
+
+// VerifyBlob verifies the base64 encoded signature of the content of the file with the public key
+func VerifyBlob(ctx context.Context, publicKeyPath, file, signature string) error {
+	verifier, err := sigs.PublicKeyFromKeyRefWithHashAlgo(ctx, publicKeyPath, crypto.SHA256)
+	if err != nil {
+		return fmt.Errorf("loading public key: %w", err)
+	}
+	signatureBytes, err := base64.StdEncoding.DecodeString(strings.TrimSpace(signature))
+	if err != nil {
+		return fmt.Errorf("decoding the signature of the file %s: %w", file, err)
+	}
+	content, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("reading the file %s: %w", file, err)
+	}
+	defer content.Close()
+
+	if err := verifier.VerifySignature(bytes.NewReader(signatureBytes), content); err != nil {
+		return fmt.Errorf("verifying the signature of the file %s: %w", file, err)
+	}
+	return nil
+}
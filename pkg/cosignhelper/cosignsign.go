@@ -0,0 +1,111 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cosignhelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/sigstore/cosign/v2/pkg/oci/mutate"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	sigs "github.com/sigstore/cosign/v2/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/payload"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/registry"
+)
+
+// CosignSignOptions implements the "cosign sign" command using cosign library
+type CosignSignOptions struct {
+	// KeyRef is the path to the private key (or the KMS URI) to be used to sign the OCI images.
+	// The password of the private key, if any, is read from the COSIGN_PASSWORD environment variable
+	KeyRef string
+	// RegistryOpts registry options used while interacting with registry
+	RegistryOpts *RegistryOptions
+}
+
+func NewCosignSigner(keyRef string, registryOpts *RegistryOptions) CosignSigner {
+	return &CosignSignOptions{
+		KeyRef:       keyRef,
+		RegistryOpts: registryOpts,
+	}
+}
+
+// Sign signs the images and publishes the signatures next to the images
+// in the same format as the cosign CLI
+func (so *CosignSignOptions) Sign(ctx context.Context, images []string) error {
+	signer, err := sigs.SignerVerifierFromKeyRef(ctx, so.KeyRef, getPassFromEnv)
+	if err != nil {
+		return fmt.Errorf("loading private key: %w", err)
+	}
+
+	httpTrans, err := registry.NewHTTPTransport(&registry.CertOptions{
+		CACertPaths:    so.RegistryOpts.CACertPaths,
+		SkipCertVerify: so.RegistryOpts.SkipCertVerify,
+		Insecure:       so.RegistryOpts.AllowInsecure,
+	})
+	if err != nil {
+		return fmt.Errorf("creating registry HTTP transport: %w", err)
+	}
+	registryClientOpts := []ociremote.Option{
+		ociremote.WithRemoteOptions(remote.WithContext(ctx)),
+		ociremote.WithRemoteOptions(remote.WithAuthFromKeychain(authn.DefaultKeychain)),
+		ociremote.WithRemoteOptions(remote.WithTransport(httpTrans)),
+	}
+
+	var nameOpts []name.Option
+	if so.RegistryOpts.AllowInsecure {
+		nameOpts = append(nameOpts, name.Insecure)
+	}
+
+	for _, img := range images {
+		ref, err := name.ParseReference(img, nameOpts...)
+		if err != nil {
+			return fmt.Errorf("parsing reference: %w", err)
+		}
+		// The signature is always bound to the digest of the image
+		digest, err := ociremote.ResolveDigest(ref, registryClientOpts...)
+		if err != nil {
+			return fmt.Errorf("resolving the digest of the image %s: %w", img, err)
+		}
+
+		payloadBytes, err := payload.Cosign{Image: digest}.MarshalJSON()
+		if err != nil {
+			return fmt.Errorf("creating the signature payload of the image %s: %w", img, err)
+		}
+		signatureBytes, err := signer.SignMessage(bytes.NewReader(payloadBytes))
+		if err != nil {
+			return fmt.Errorf("signing the image %s: %w", img, err)
+		}
+		ociSignature, err := static.NewSignature(payloadBytes, base64.StdEncoding.EncodeToString(signatureBytes))
+		if err != nil {
+			return fmt.Errorf("creating the signature of the image %s: %w", img, err)
+		}
+
+		signedEntity, err := ociremote.SignedEntity(digest, registryClientOpts...)
+		if err != nil {
+			return fmt.Errorf("accessing the image %s: %w", img, err)
+		}
+		signedEntity, err = mutate.AttachSignatureToEntity(signedEntity, ociSignature)
+		if err != nil {
+			return fmt.Errorf("attaching the signature to the image %s: %w", img, err)
+		}
+		if err := ociremote.WriteSignatures(digest.Repository, signedEntity, registryClientOpts...); err != nil {
+			return fmt.Errorf("publishing the signature of the image %s: %w", img, err)
+		}
+	}
+
+	return nil
+}
+
+// getPassFromEnv returns the password of the private key from the COSIGN_PASSWORD environment variable
+func getPassFromEnv(_ bool) ([]byte, error) {
+	return []byte(os.Getenv("COSIGN_PASSWORD")), nil
+}
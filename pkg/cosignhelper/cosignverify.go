@@ -7,11 +7,8 @@ package cosignhelper
 import (
 	"context"
 	"crypto"
-	"crypto/tls"
-	"crypto/x509"
 	"fmt"
 	"net/http"
-	"os"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
@@ -23,6 +20,8 @@ import (
 	"github.com/sigstore/sigstore/pkg/cryptoutils"
 	"github.com/sigstore/sigstore/pkg/signature"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/registry"
 )
 
 // RegistryOptions registry options used while interacting with registry
@@ -132,31 +131,9 @@ func (vo *CosignVerifyOptions) Verify(ctx context.Context, images []string) erro
 }
 
 func (vo *CosignVerifyOptions) newHTTPTransport() (*http.Transport, error) {
-	var pool *x509.CertPool
-
-	var err error
-	pool, err = x509.SystemCertPool()
-	if err != nil {
-		return nil, err
-	}
-
-	if len(vo.RegistryOpts.CACertPaths) > 0 {
-		for _, path := range vo.RegistryOpts.CACertPaths {
-			if certs, err := os.ReadFile(path); err != nil {
-				return nil, errors.Wrapf(err, "failed reading CA certificates from '%s' ", path)
-			} else if ok := pool.AppendCertsFromPEM(certs); !ok {
-				return nil, fmt.Errorf("failed adding CA certificates from '%s'", path)
-			}
-		}
-	}
-
-	clonedDefaultTransport := http.DefaultTransport.(*http.Transport).Clone()
-	clonedDefaultTransport.ForceAttemptHTTP2 = false
-	// #nosec G402
-	clonedDefaultTransport.TLSClientConfig = &tls.Config{
-		RootCAs:            pool,
-		InsecureSkipVerify: vo.RegistryOpts.SkipCertVerify,
-	}
-
-	return clonedDefaultTransport, nil
+	return registry.NewHTTPTransport(&registry.CertOptions{
+		CACertPaths:    vo.RegistryOpts.CACertPaths,
+		SkipCertVerify: vo.RegistryOpts.SkipCertVerify,
+		Insecure:       vo.RegistryOpts.AllowInsecure,
+	})
 }
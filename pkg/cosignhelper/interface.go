@@ -12,3 +12,11 @@ type Cosignhelper interface {
 	// Verify verifies the signature on the images using cosign library
 	Verify(ctx context.Context, images []string) error
 }
+
+//go:generate counterfeiter -o ../fakes/cosignsigner_fake.go --fake-name CosignSignerFake . CosignSigner
+
+// CosignSigner is the interface to provide wrapper implementation for signing images using cosign libraries
+type CosignSigner interface {
+	// Sign signs the images using cosign library and publishes the signatures to the registry
+	Sign(ctx context.Context, images []string) error
+}
@@ -20,12 +20,20 @@ import (
 )
 
 func VerifyInventoryImageSignature(image string) error {
+	return VerifyInventoryImageSignatureWithDigest(image, image)
+}
+
+// VerifyInventoryImageSignatureWithDigest verifies the signature of the inventory image
+// pinned to a digest, so that the verified image is the one downloaded afterward even if
+// the tag of the image is updated in the meantime. The skip list is matched against the
+// image as configured by the user.
+func VerifyInventoryImageSignatureWithDigest(image, imageWithDigest string) error {
 	cosignVerifier, err := getCosignVerifier(image)
 	if err != nil {
 		return errors.Wrapf(err, "failed to initialize the cosign verifier")
 	}
 
-	if sigVerifyErr := verifyInventoryImageSignature(image, cosignVerifier); sigVerifyErr != nil {
+	if sigVerifyErr := verifyInventoryImageSignature(image, imageWithDigest, cosignVerifier); sigVerifyErr != nil {
 		// Print the message directly to stderr without using the log library
 		// to make sure the user sees the error message even if the logs are disabled
 		msg := fmt.Sprintf("Unable to verify the plugins discovery image signature: %v", sigVerifyErr)
@@ -47,7 +55,26 @@ func VerifyInventoryImageSignature(image string) error {
 	return nil
 }
 
+// VerifyInventoryChunksImageSignature verifies the signature of the image holding the chunks of
+// the files of the inventory image, which is signed like the inventory image. Unlike the inventory
+// image, a failed verification is returned as an error, as the inventory image can be downloaded
+// instead of its chunks. The verification is skipped for the inventory images of the skip list.
+func VerifyInventoryChunksImageSignature(image, chunksImageWithDigest string) error {
+	if _, exists := getPluginDiscoveryImagesSkippedForSignatureVerification()[strings.TrimSpace(image)]; exists {
+		return nil
+	}
+	cosignVerifier, err := getCosignVerifier(chunksImageWithDigest)
+	if err != nil {
+		return errors.Wrapf(err, "failed to initialize the cosign verifier")
+	}
+	return cosignVerifier.Verify(context.Background(), []string{chunksImageWithDigest})
+}
+
 func getCosignVerifier(image string) (cosignhelper.Cosignhelper, error) {
+	if err := registry.CheckImageRegistryAllowed(strings.TrimSpace(image)); err != nil {
+		return nil, err
+	}
+
 	// Get the custom public key path and prepare cosign verifier, if empty, cosign verifier would use embedded public key for verification
 	customPublicKeyPath := os.Getenv(constants.PublicKeyPathForPluginDiscoveryImageSignature)
 
@@ -61,14 +88,10 @@ func getCosignVerifier(image string) (cosignhelper.Cosignhelper, error) {
 // getCosignVerifierRegistryOptions prepares the registry options by including the custom certificate configuration if any
 func getCosignVerifierRegistryOptions(image string) (*cosignhelper.RegistryOptions, error) {
 	registryOpts := &cosignhelper.RegistryOptions{}
-	registryName, err := registry.GetRegistryName(strings.TrimSpace(image))
-	if err != nil {
-		return nil, err
-	}
 	// get the certificate configuration and update the registry options
-	regCertOptions, err := registry.GetRegistryCertOptions(registryName)
+	regCertOptions, err := registry.GetRegistryCertOptionsForImage(strings.TrimSpace(image))
 	if err != nil {
-		return nil, errors.Wrapf(err, "unable to get the registry certificate configuration")
+		return nil, err
 	}
 	registryOpts.CACertPaths = regCertOptions.CACertPaths
 	registryOpts.SkipCertVerify = regCertOptions.SkipCertVerify
@@ -77,7 +100,7 @@ func getCosignVerifierRegistryOptions(image string) (*cosignhelper.RegistryOptio
 	return registryOpts, nil
 }
 
-func verifyInventoryImageSignature(image string, verifier cosignhelper.Cosignhelper) error {
+func verifyInventoryImageSignature(image, imageWithDigest string, verifier cosignhelper.Cosignhelper) error {
 	signatureVerificationSkipSet := getPluginDiscoveryImagesSkippedForSignatureVerification()
 	if _, exists := signatureVerificationSkipSet[strings.TrimSpace(image)]; exists {
 		// log warning message iff user had not chosen to skip warning message for signature verification
@@ -87,7 +110,7 @@ func verifyInventoryImageSignature(image string, verifier cosignhelper.Cosignhel
 		return nil
 	}
 
-	err := verifier.Verify(context.Background(), []string{image})
+	err := verifier.Verify(context.Background(), []string{imageWithDigest})
 	if err != nil {
 		return err
 	}
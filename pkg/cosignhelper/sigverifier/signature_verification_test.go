@@ -59,7 +59,7 @@ var _ = Describe("Unit tests for discovery image signature verification", func()
 			It("should return success", func() {
 				cosignVerifier = &fakes.Cosignhelperfake{}
 				cosignVerifier.VerifyReturns(nil)
-				err = verifyInventoryImageSignature(image, cosignVerifier)
+				err = verifyInventoryImageSignature(image, image, cosignVerifier)
 				Expect(err).ToNot(HaveOccurred())
 			})
 		})
@@ -68,15 +68,34 @@ var _ = Describe("Unit tests for discovery image signature verification", func()
 				cosignVerifier = &fakes.Cosignhelperfake{}
 				cosignVerifier.VerifyReturns(fmt.Errorf("signature verification fake error"))
 				os.Setenv(constants.PluginDiscoveryImageSignatureVerificationSkipList, image)
-				err = verifyInventoryImageSignature(image, cosignVerifier)
+				err = verifyInventoryImageSignature(image, image, cosignVerifier)
 				Expect(err).ToNot(HaveOccurred())
 			})
 		})
+		Context("When the image is pinned to a digest", func() {
+			It("should verify the image pinned to the digest", func() {
+				fakeVerifier := &fakes.Cosignhelperfake{}
+				fakeVerifier.VerifyReturns(nil)
+				err = verifyInventoryImageSignature(image, image+"@sha256:1234", fakeVerifier)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeVerifier.VerifyCallCount()).To(Equal(1))
+				_, images := fakeVerifier.VerifyArgsForCall(0)
+				Expect(images).To(Equal([]string{image + "@sha256:1234"}))
+			})
+			It("should match the skip list against the image as configured", func() {
+				fakeVerifier := &fakes.Cosignhelperfake{}
+				fakeVerifier.VerifyReturns(fmt.Errorf("signature verification fake error"))
+				os.Setenv(constants.PluginDiscoveryImageSignatureVerificationSkipList, image)
+				err = verifyInventoryImageSignature(image, image+"@sha256:1234", fakeVerifier)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeVerifier.VerifyCallCount()).To(Equal(0))
+			})
+		})
 		Context("Cosign signature verification failed", func() {
 			It("should return error", func() {
 				cosignVerifier = &fakes.Cosignhelperfake{}
 				cosignVerifier.VerifyReturns(fmt.Errorf("signature verification fake error"))
-				err = verifyInventoryImageSignature(image, cosignVerifier)
+				err = verifyInventoryImageSignature(image, image, cosignVerifier)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("signature verification fake error"))
 			})
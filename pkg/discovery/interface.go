@@ -38,6 +38,15 @@ type GroupDiscovery interface {
 	GetGroups() ([]*plugininventory.PluginGroup, error)
 }
 
+// InventoryDiscovery is a discovery backed by a plugin inventory
+type InventoryDiscovery interface {
+	Discovery
+
+	// Inventory returns the plugin inventory of the discovery, refreshed
+	// unless the discovery uses its local cache only
+	Inventory() (plugininventory.PluginInventory, error)
+}
+
 // DiscoveryOpts used to customize the plugin discovery process or mechanism
 type DiscoveryOpts struct {
 	UseLocalCacheOnly       bool // UseLocalCacheOnly used to pull the plugin data from the cache
@@ -89,12 +98,21 @@ type PluginDiscoveryCriteria struct {
 	Name string
 	// Target is the target of the plugin
 	Target configtypes.Target
+	// Targets are the targets one of which the plugin applies to, e.g. the targets relevant to a context
+	Targets []configtypes.Target
 	// Version is the version for the plugin
 	Version string
 	// OS of the plugin binary in `GOOS` format.
 	OS string
 	// Arch of the plugin binary in `GOARCH` format.
 	Arch string
+	// SearchText contains the keywords that the name or the description of the plugin must contain
+	SearchText string
+	// IncludeHidden includes the plugins marked as hidden (deactivated) in the inventory,
+	// e.g. for testers to discover the internal or preview plugins
+	IncludeHidden bool
+	// ArchFallback tells which artifacts to return for the plugin versions without artifact for the OS and Arch
+	ArchFallback plugininventory.ArchFallbackPolicy
 }
 
 // GroupDiscoveryCriteria provides criteria to look for
@@ -106,8 +124,14 @@ type GroupDiscoveryCriteria struct {
 	Publisher string
 	// Name of the group
 	Name string
+	// NameContains is a case-insensitive substring of the name of the group
+	NameContains string
 	// Version is the version for the group
 	Version string
+	// PluginName is the name of a plugin the group versions must include
+	PluginName string
+	// PluginTarget is the target of the plugin the group versions must include
+	PluginTarget configtypes.Target
 }
 
 // CreateDiscoveryFromV1alpha1 creates discovery interface from v1alpha1 API
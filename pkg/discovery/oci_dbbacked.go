@@ -5,10 +5,12 @@ package discovery
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -24,6 +26,14 @@ import (
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
 )
 
+// inventoryLayersFileName is the name of the file storing the digests of the layers
+// of the image from which the cached plugin inventory database was extracted
+const inventoryLayersFileName = "layers.digest"
+
+// inventoryChunksDirName is the name of the directory caching the chunks
+// of the files of the plugin inventory image
+const inventoryChunksDirName = "chunks"
+
 // DBBackedOCIDiscovery is an artifact discovery utilizing an OCI image
 // which contains an SQLite database describing the content of the plugin
 // discovery.
@@ -51,6 +61,13 @@ type DBBackedOCIDiscovery struct {
 	pluginDataDir string
 	// inventory is the pluginInventory to be used by this discovery.
 	inventory plugininventory.PluginInventory
+	// imageWithDigest is the image pinned to the digest resolved when checking the cache.
+	// It is used to verify and download the image so that the downloaded image
+	// is the verified one even if the tag of the image is updated in the meantime.
+	imageWithDigest string
+	// metadataImageWithDigest is the plugin inventory metadata image pinned to
+	// the digest resolved when checking the cache, if the image exists
+	metadataImageWithDigest string
 }
 
 func (od *DBBackedOCIDiscovery) getInventory() plugininventory.PluginInventory {
@@ -101,34 +118,40 @@ func (od *DBBackedOCIDiscovery) GetGroups() ([]*plugininventory.PluginGroup, err
 	return od.listGroupsFromInventory()
 }
 
-func (od *DBBackedOCIDiscovery) listPluginsFromInventory() ([]Discovered, error) {
-	var pluginEntries []*plugininventory.PluginInventoryEntry
-	var err error
+// Inventory returns the plugin inventory of the discovery, after fetching the inventory image
+// unless the useLocalCacheOnly option is set.
+func (od *DBBackedOCIDiscovery) Inventory() (plugininventory.PluginInventory, error) {
+	if !od.useLocalCacheOnly {
+		if err := od.fetchInventoryImage(); err != nil {
+			return nil, errors.Wrapf(err, "unable to fetch the inventory of discovery '%s'", od.Name())
+		}
+	}
+	return od.getInventory(), nil
+}
 
+func (od *DBBackedOCIDiscovery) listPluginsFromInventory() ([]Discovered, error) {
 	shouldIncludeHidden, _ := strconv.ParseBool(os.Getenv(constants.ConfigVariableIncludeDeactivatedPluginsForTesting))
-	if od.pluginCriteria == nil {
-		pluginEntries, err = od.getInventory().GetPlugins(&plugininventory.PluginInventoryFilter{
-			IncludeHidden: shouldIncludeHidden,
-		})
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		pluginEntries, err = od.getInventory().GetPlugins(&plugininventory.PluginInventoryFilter{
+	filter := &plugininventory.PluginInventoryFilter{
+		IncludeHidden: shouldIncludeHidden,
+	}
+	if od.pluginCriteria != nil {
+		filter = &plugininventory.PluginInventoryFilter{
 			Name:          od.pluginCriteria.Name,
 			Target:        od.pluginCriteria.Target,
+			Targets:       od.pluginCriteria.Targets,
 			Version:       od.pluginCriteria.Version,
 			OS:            od.pluginCriteria.OS,
 			Arch:          od.pluginCriteria.Arch,
-			IncludeHidden: shouldIncludeHidden,
-		})
-		if err != nil {
-			return nil, err
+			SearchText:    od.pluginCriteria.SearchText,
+			IncludeHidden: shouldIncludeHidden || od.pluginCriteria.IncludeHidden,
+			ArchFallback:  od.pluginCriteria.ArchFallback,
 		}
 	}
 
+	// The inventory entries are converted as they are read from the inventory,
+	// so that all the entries are not held in memory along with the discovered plugins
 	var discoveredPlugins []Discovered
-	for _, entry := range pluginEntries {
+	err := od.getInventory().WalkPlugins(context.Background(), filter, func(entry *plugininventory.PluginInventoryEntry) error {
 		// First build the sorted list of versions from the Artifacts map
 		var versions []string
 		for v := range entry.Artifacts {
@@ -141,6 +164,8 @@ func (od *DBBackedOCIDiscovery) listPluginsFromInventory() ([]Discovered, error)
 		plugin := Discovered{
 			Name:               entry.Name,
 			Description:        entry.Description,
+			Vendor:             entry.Vendor,
+			Publisher:          entry.Publisher,
 			RecommendedVersion: entry.RecommendedVersion,
 			InstalledVersion:   "", // Not set when discovered, but later.
 			SupportedVersions:  versions,
@@ -152,8 +177,19 @@ func (od *DBBackedOCIDiscovery) listPluginsFromInventory() ([]Discovered, error)
 			DiscoveryType:      common.DiscoveryTypeOCI,
 			Target:             entry.Target,
 			Status:             common.PluginStatusNotInstalled, // Not set yet
+			ReleaseNotes:       entry.ReleaseNotes,
+			MinCLIVersions:     entry.MinCLIVersions,
+			Licenses:           entry.Licenses,
+			ReleasedAt:         entry.ReleasedAt,
+			Deprecated:         entry.Deprecated,
+			DeprecationMessage: entry.DeprecationMessage,
+			ReplacedBy:         entry.ReplacedBy,
 		}
 		discoveredPlugins = append(discoveredPlugins, plugin)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return discoveredPlugins, nil
 }
@@ -162,16 +198,19 @@ func (od *DBBackedOCIDiscovery) listGroupsFromInventory() ([]*plugininventory.Pl
 	shouldIncludeHidden, _ := strconv.ParseBool(os.Getenv(constants.ConfigVariableIncludeDeactivatedPluginsForTesting))
 
 	if od.groupCriteria == nil {
-		return od.getInventory().GetPluginGroups(plugininventory.PluginGroupFilter{
+		return od.getInventory().GetPluginGroups(context.Background(), plugininventory.PluginGroupFilter{
 			IncludeHidden: shouldIncludeHidden,
 		})
 	}
 
-	return od.getInventory().GetPluginGroups(plugininventory.PluginGroupFilter{
+	return od.getInventory().GetPluginGroups(context.Background(), plugininventory.PluginGroupFilter{
 		Vendor:        od.groupCriteria.Vendor,
 		Publisher:     od.groupCriteria.Publisher,
 		Name:          od.groupCriteria.Name,
+		NameContains:  od.groupCriteria.NameContains,
 		Version:       od.groupCriteria.Version,
+		PluginName:    od.groupCriteria.PluginName,
+		PluginTarget:  od.groupCriteria.PluginTarget,
 		IncludeHidden: shouldIncludeHidden,
 	})
 }
@@ -179,6 +218,10 @@ func (od *DBBackedOCIDiscovery) listGroupsFromInventory() ([]*plugininventory.Pl
 // fetchInventoryImage downloads the OCI image containing the information about the
 // inventory of this discovery and stores it in the cache directory.
 func (od *DBBackedOCIDiscovery) fetchInventoryImage() error {
+	// A corrupted cached DB would fail every command until the cache is cleaned,
+	// so discard it to download the inventory image again
+	od.invalidateCorruptedCache()
+
 	if !od.forceRefresh && !od.cacheTTLExpired() {
 		// If we refreshed the inventory image recently, don't refresh again.
 		// The inventory image does not need to be up-to-date by the second.
@@ -203,20 +246,31 @@ func (od *DBBackedOCIDiscovery) fetchInventoryImage() error {
 		return nil
 	}
 
-	// The DB has changed and needs to be updated in the cache.
-	log.Infof("Reading plugin inventory for %q, this will take a few seconds.", od.image)
-
-	// Verify the inventory image signature before downloading the plugin inventory database
-	err = sigverifier.VerifyInventoryImageSignature(od.image)
+	// Verify the inventory image signature before using the plugin inventory database
+	err = sigverifier.VerifyInventoryImageSignatureWithDigest(od.image, od.imageWithDigest)
 	if err != nil {
 		return err
 	}
 
-	// download the central repository image to get the 'plugin_inventory.db' and `central_config.yaml` files.
-	// Also handle the air-gapped scenario where additional plugin inventory metadata image is present
-	err = od.downloadCentralRepositoryData()
-	if err != nil {
-		return err
+	// The digest of the image changes whenever the image is published again, even if the
+	// database it contains has not changed.  Since the layers of an image are content-addressed,
+	// comparing their digests with the ones of the cached image avoids downloading the same
+	// database again.  This cannot be done if the metadata image has changed, as the cached
+	// database has been modified based on the previous metadata image.
+	inventoryLayers, _ := carvelhelpers.GetImageLayerDigests(od.imageWithDigest)
+	if newCacheHashFileForMetadataImage == "" && od.inventoryLayersCached(inventoryLayers) {
+		log.V(4).Infof("The plugin inventory for %q has not changed, using the cached inventory.", od.image)
+	} else {
+		// The DB has changed and needs to be updated in the cache.
+		log.Infof("Reading plugin inventory for %q, this will take a few seconds.", od.image)
+
+		// download the central repository image to get the 'plugin_inventory.db' and `central_config.yaml` files.
+		// Also handle the air-gapped scenario where additional plugin inventory metadata image is present
+		err = od.downloadCentralRepositoryData()
+		if err != nil {
+			return err
+		}
+		od.saveInventoryLayers(inventoryLayers)
 	}
 
 	// Now that the new DB has been downloaded, we can reset the TTL.
@@ -267,9 +321,12 @@ func (od *DBBackedOCIDiscovery) downloadCentralRepositoryData() error {
 	defer os.RemoveAll(tempDir1)
 	defer os.RemoveAll(tempDir2)
 
-	// Download the central repo OCI image and save it to tempDir1
-	if err := carvelhelpers.DownloadImageAndSaveFilesToDir(od.image, tempDir1); err != nil {
-		return errors.Wrapf(err, "failed to download OCI image from discovery '%s'", od.Name())
+	// Download the central repo OCI image and save it to tempDir1,
+	// only downloading the changed chunks of its files when possible
+	if !od.downloadInventoryChunks(tempDir1) {
+		if err := carvelhelpers.DownloadImageAndSaveFilesToDir(od.imageWithDigest, tempDir1); err != nil {
+			return errors.Wrapf(err, "failed to download OCI image from discovery '%s'", od.Name())
+		}
 	}
 
 	od.setupCentralConfig(tempDir1)
@@ -277,6 +334,35 @@ func (od *DBBackedOCIDiscovery) downloadCentralRepositoryData() error {
 	return od.setupPluginInventory(tempDir1, tempDir2)
 }
 
+// downloadInventoryChunks downloads the files of the central repo OCI image from the image
+// holding their chunks, if it is published along with the image, and saves them to the
+// destination directory. Only the chunks which changed since the previous download are
+// downloaded, which makes refreshing the inventory much faster over slow links.
+// It returns false if the files must be downloaded from the image itself.
+func (od *DBBackedOCIDiscovery) downloadInventoryChunks(destDir string) bool {
+	chunksImage, err := carvelhelpers.ChunksImageForImage(od.imageWithDigest)
+	if err != nil {
+		return false
+	}
+	algorithm, hex, err := carvelhelpers.GetImageDigest(chunksImage)
+	if err != nil || hex == "" {
+		log.V(4).Infof("the chunks of the plugin inventory image %q are not available", od.image)
+		return false
+	}
+	chunksImageWithDigest := carvelhelpers.ImageWithDigest(chunksImage, algorithm, hex)
+	if err := sigverifier.VerifyInventoryChunksImageSignature(od.image, chunksImageWithDigest); err != nil {
+		log.V(4).Infof("unable to verify the signature of the chunks of the plugin inventory image %q: %v", od.image, err)
+		return false
+	}
+	downloaded, err := carvelhelpers.DownloadChunkedFilesToDir(chunksImageWithDigest, destDir, filepath.Join(od.pluginDataDir, inventoryChunksDirName))
+	if err != nil {
+		log.V(4).Infof("unable to download the chunks of the plugin inventory image %q: %v", od.image, err)
+		return false
+	}
+	log.V(4).Infof("downloaded %d changed chunks of the plugin inventory image %q", downloaded, od.image)
+	return true
+}
+
 func (od *DBBackedOCIDiscovery) setupCentralConfig(sourceDir string) {
 	// Copy the central config file from the temp directory to pluginDataDir
 	sourceCentralConfigPath := filepath.Join(sourceDir, centralconfig.CentralConfigFileName)
@@ -301,16 +387,22 @@ func (od *DBBackedOCIDiscovery) setupPluginInventory(inventoryDir, metadataDir s
 	metadataDBFilePath := filepath.Join(metadataDir, plugininventory.SQliteInventoryMetadataDBFileName)
 
 	// Download the plugin inventory metadata image if exists and save to metadataDir
-	pluginInventoryMetadataImage, _ := airgapped.GetPluginInventoryMetadataImage(od.image)
-	if err := carvelhelpers.DownloadImageAndSaveFilesToDir(pluginInventoryMetadataImage, metadataDir); err == nil {
-		// Update the plugin inventory database (plugin_inventory.db) based on the plugin
-		// inventory metadata database (plugin_inventory_metadata.db)
-		err = plugininventory.NewSQLiteInventoryMetadata(metadataDBFilePath).UpdatePluginInventoryDatabase(inventoryDBFilePath)
-		if err != nil {
-			return errors.Wrap(err, "error while updating inventory database based on the inventory metadata database")
+	if od.metadataImageWithDigest != "" {
+		if err := carvelhelpers.DownloadImageAndSaveFilesToDir(od.metadataImageWithDigest, metadataDir); err == nil {
+			// Update the plugin inventory database (plugin_inventory.db) based on the plugin
+			// inventory metadata database (plugin_inventory_metadata.db)
+			err = plugininventory.NewSQLiteInventoryMetadata(metadataDBFilePath).UpdatePluginInventoryDatabase(inventoryDBFilePath)
+			if err != nil {
+				return errors.Wrap(err, "error while updating inventory database based on the inventory metadata database")
+			}
 		}
 	}
 
+	// The inventory database may have been published with an older schema
+	if err := plugininventory.NewSQLiteInventory(inventoryDBFilePath, "").MigrateSchema(context.Background()); err != nil {
+		log.V(4).Warningf("unable to migrate the schema of the plugin inventory database: %v", err)
+	}
+
 	// Copy the inventory database file from temp directory to pluginDataDir
 	return utils.CopyFile(inventoryDBFilePath, filepath.Join(od.pluginDataDir, plugininventory.SQliteDBFileName))
 }
@@ -331,16 +423,21 @@ func (od *DBBackedOCIDiscovery) checkImageCache() (string, string, error) {
 	// If the cache already contains the image with this digest
 	// we do not need to verify its signature nor to download it again.
 	log.Infof("Refreshing plugin inventory cache for %q, this will take a few seconds.", od.image)
-	_, hashHexValInventoryImage, err := carvelhelpers.GetImageDigest(od.image)
+	hashAlgorithmInventoryImage, hashHexValInventoryImage, err := carvelhelpers.GetImageDigest(od.image)
 	if err != nil {
 		// This will happen when the user has configured an invalid image discovery URI
 		return "", "", errors.Wrapf(err, "plugins discovery image resolution failed. Please check that the repository image URL %q is correct", od.image)
 	}
 
 	correctHashFileForInventoryImage := od.checkDigestFileExistence(hashHexValInventoryImage, "")
+	od.imageWithDigest = carvelhelpers.ImageWithDigest(od.image, hashAlgorithmInventoryImage, hashHexValInventoryImage)
 
 	pluginInventoryMetadataImage, _ := airgapped.GetPluginInventoryMetadataImage(od.image)
-	_, hashHexValMetadataImage, _ := carvelhelpers.GetImageDigest(pluginInventoryMetadataImage)
+	hashAlgorithmMetadataImage, hashHexValMetadataImage, _ := carvelhelpers.GetImageDigest(pluginInventoryMetadataImage)
+	od.metadataImageWithDigest = ""
+	if hashHexValMetadataImage != "" {
+		od.metadataImageWithDigest = carvelhelpers.ImageWithDigest(pluginInventoryMetadataImage, hashAlgorithmMetadataImage, hashHexValMetadataImage)
+	}
 	// Always store the metadata image digest file even if the image does not exists.
 	// If the metadata image does not exist, a file named `metadata.digest.none` will be stored.
 	// If the metadata image exists, a file named `metadata.digest.<hexval>` will be stored.
@@ -406,6 +503,59 @@ func (od *DBBackedOCIDiscovery) checkDigestFileExistence(hashHexVal, digestPrefi
 	return correctHashFile
 }
 
+// inventoryLayersCached returns true if the cached plugin inventory database was
+// extracted from an image with the specified layers
+func (od *DBBackedOCIDiscovery) inventoryLayersCached(layers []string) bool {
+	if len(layers) == 0 {
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(od.pluginDataDir, plugininventory.SQliteDBFileName)); err != nil {
+		return false
+	}
+	cachedLayers, err := os.ReadFile(filepath.Join(od.pluginDataDir, inventoryLayersFileName))
+	if err != nil {
+		return false
+	}
+	return string(cachedLayers) == strings.Join(layers, "\n")
+}
+
+// saveInventoryLayers stores the digests of the layers of the image from which the cached
+// plugin inventory database was extracted.  When the layers are unknown, any previous
+// digests are removed as they no longer describe the cached database.
+func (od *DBBackedOCIDiscovery) saveInventoryLayers(layers []string) {
+	layersFile := filepath.Join(od.pluginDataDir, inventoryLayersFileName)
+	if len(layers) == 0 {
+		_ = os.Remove(layersFile)
+		return
+	}
+	if err := os.WriteFile(layersFile, []byte(strings.Join(layers, "\n")), 0644); err != nil {
+		log.V(4).Warningf("unable to store the layers of the plugin inventory image: %v", err)
+	}
+}
+
+// invalidateCorruptedCache removes the cached plugin inventory database if it fails
+// its integrity check, e.g. when it was truncated by an interrupted copy or a full disk.
+// The digest files describing the database are removed along with it, so that the cache
+// is expired and the inventory image is downloaded again, whatever its digest.
+func (od *DBBackedOCIDiscovery) invalidateCorruptedCache() {
+	dbFile := filepath.Join(od.pluginDataDir, plugininventory.SQliteDBFileName)
+	if _, err := os.Stat(dbFile); err != nil {
+		return
+	}
+	err := plugininventory.CheckSQLiteInventoryIntegrity(context.Background(), dbFile)
+	if err == nil {
+		return
+	}
+	log.Warningf("The cached plugin inventory for %q is corrupted and will be downloaded again: %v", od.image, err)
+
+	for _, pattern := range []string{"digest.*", "metadata.digest.*", inventoryLayersFileName, plugininventory.SQliteDBFileName + "*"} {
+		matches, _ := filepath.Glob(filepath.Join(od.pluginDataDir, pattern))
+		for _, match := range matches {
+			_ = os.Remove(match)
+		}
+	}
+}
+
 func getCacheTTLValue() int {
 	cacheTTL := constants.DefaultInventoryRefreshTTLSeconds
 	cacheTTLOverride := os.Getenv(constants.ConfigVariablePluginDBCacheTTLSeconds)
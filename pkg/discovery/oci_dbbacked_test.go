@@ -5,6 +5,8 @@ package discovery
 
 import (
 	"bufio"
+	"context"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -31,30 +33,56 @@ func (i inventoryFilterInError) Error() string {
 
 type stubInventory struct{}
 
-func (stub *stubInventory) GetAllPlugins() ([]*plugininventory.PluginInventoryEntry, error) {
-	return stub.GetPlugins(&plugininventory.PluginInventoryFilter{})
+func (stub *stubInventory) GetAllPlugins(ctx context.Context) ([]*plugininventory.PluginInventoryEntry, error) {
+	return stub.GetPlugins(ctx, &plugininventory.PluginInventoryFilter{})
 }
-func (stub *stubInventory) GetPlugins(filter *plugininventory.PluginInventoryFilter) ([]*plugininventory.PluginInventoryEntry, error) {
+func (stub *stubInventory) GetPlugins(_ context.Context, filter *plugininventory.PluginInventoryFilter) ([]*plugininventory.PluginInventoryEntry, error) {
 	// Return the plugin filter so the tests can verify if it is correct
 	return nil, inventoryFilterInError{pluginFilter: filter}
 }
-func (stub *stubInventory) GetPluginGroups(filter plugininventory.PluginGroupFilter) ([]*plugininventory.PluginGroup, error) {
+func (stub *stubInventory) WalkPlugins(ctx context.Context, filter *plugininventory.PluginInventoryFilter, _ func(*plugininventory.PluginInventoryEntry) error) error {
+	_, err := stub.GetPlugins(ctx, filter)
+	return err
+}
+func (stub *stubInventory) GetPluginsPage(ctx context.Context, filter *plugininventory.PluginInventoryFilter, _, _ int) ([]*plugininventory.PluginInventoryEntry, error) {
+	return stub.GetPlugins(ctx, filter)
+}
+func (stub *stubInventory) GetPluginGroups(_ context.Context, filter plugininventory.PluginGroupFilter) ([]*plugininventory.PluginGroup, error) {
 	// Return the group filter so the tests can verify if it is correct
 	return nil, inventoryFilterInError{groupFilter: &filter}
 }
-func (stub *stubInventory) CreateSchema() error {
+func (stub *stubInventory) CreateSchema(_ context.Context) error {
+	return nil
+}
+func (stub *stubInventory) InsertPlugin(_ context.Context, _ *plugininventory.PluginInventoryEntry) error {
+	return nil
+}
+func (stub *stubInventory) InsertPlugins(_ context.Context, _ []*plugininventory.PluginInventoryEntry) error {
+	return nil
+}
+func (stub *stubInventory) InsertPluginGroup(_ context.Context, _ *plugininventory.PluginGroup, _ bool) error {
+	return nil
+}
+func (stub *stubInventory) UpdatePluginActivationState(_ context.Context, _ *plugininventory.PluginInventoryEntry) error {
+	return nil
+}
+func (stub *stubInventory) UpdatePluginGroupActivationState(_ context.Context, _ *plugininventory.PluginGroup) error {
+	return nil
+}
+func (stub *stubInventory) MigrateSchema(_ context.Context) error {
 	return nil
 }
-func (stub *stubInventory) InsertPlugin(_ *plugininventory.PluginInventoryEntry) error {
+func (stub *stubInventory) DeletePluginVersion(_ context.Context, _ string, _ configtypes.Target, _ string) error {
 	return nil
 }
-func (stub *stubInventory) InsertPluginGroup(_ *plugininventory.PluginGroup, _ bool) error {
+func (stub *stubInventory) DeletePlugin(_ context.Context, _ string, _ configtypes.Target) error {
 	return nil
 }
-func (stub *stubInventory) UpdatePluginActivationState(_ *plugininventory.PluginInventoryEntry) error {
+func (stub *stubInventory) Export(_ context.Context, _ io.Writer, _ plugininventory.ExportFormat) error {
 	return nil
 }
-func (stub *stubInventory) UpdatePluginGroupActivationState(_ *plugininventory.PluginGroup) error {
+
+func (stub *stubInventory) Validate(_ context.Context) error {
 	return nil
 }
 
@@ -201,6 +229,31 @@ var _ = Describe("Unit tests for DB-backed OCI discovery", func() {
 					IncludeHidden: true,
 				}))
 			})
+			It("with a criteria including hidden plugins the filter should include hidden plugins", func() {
+				criteria := &PluginDiscoveryCriteria{
+					Name:          filteredName,
+					Target:        filteredTarget,
+					IncludeHidden: true,
+				}
+				discovery := NewOCIDiscovery("test-discovery", "test-image:latest", WithPluginDiscoveryCriteria(criteria))
+				dbDiscovery, ok := discovery.(*DBBackedOCIDiscovery)
+				Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+
+				// Inject the stub inventory and data dir
+				dbDiscovery.pluginDataDir = tmpDir
+				dbDiscovery.inventory = &stubInventory{}
+
+				plugins, err := dbDiscovery.listPluginsFromInventory()
+				Expect(plugins).To(BeNil())
+				Expect(err).ToNot(BeNil())
+				filterInErr, ok := err.(inventoryFilterInError)
+				Expect(ok).To(BeTrue())
+				Expect(*filterInErr.pluginFilter).To(Equal(plugininventory.PluginInventoryFilter{
+					Name:          filteredName,
+					Target:        filteredTarget,
+					IncludeHidden: true,
+				}))
+			})
 		})
 	})
 
@@ -433,6 +486,105 @@ var _ = Describe("Unit tests for DB-backed OCI discovery", func() {
 			})
 		})
 
+		Context("inventoryLayersCached and saveInventoryLayers functions", func() {
+			const discoveryName = "test-discovery"
+			var dbDir, pluginDBFile string
+			var dbDiscovery *DBBackedOCIDiscovery
+			layers := []string{"sha256:1111", "sha256:2222"}
+			BeforeEach(func() {
+				dbDir, err = os.MkdirTemp("", "test-cache-dir")
+				Expect(err).To(BeNil())
+
+				common.DefaultCacheDir = dbDir
+
+				// Create the DB file
+				pluginDBdir := filepath.Join(common.DefaultCacheDir, common.PluginInventoryDirName, discoveryName)
+				err = os.MkdirAll(pluginDBdir, 0755)
+				Expect(err).To(BeNil())
+				pluginDBFile = filepath.Join(pluginDBdir, plugininventory.SQliteDBFileName)
+				file, err := os.Create(pluginDBFile)
+				Expect(err).To(BeNil())
+				file.Close()
+
+				var ok bool
+				dbDiscovery, ok = NewOCIDiscovery(discoveryName, "test-image:latest").(*DBBackedOCIDiscovery)
+				Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+			})
+			AfterEach(func() {
+				os.RemoveAll(dbDir)
+			})
+
+			It("should return false when no layers were saved", func() {
+				Expect(dbDiscovery.inventoryLayersCached(layers)).To(BeFalse())
+			})
+			It("should return true for the saved layers", func() {
+				dbDiscovery.saveInventoryLayers(layers)
+				Expect(dbDiscovery.inventoryLayersCached(layers)).To(BeTrue())
+			})
+			It("should return false for different layers", func() {
+				dbDiscovery.saveInventoryLayers(layers)
+				Expect(dbDiscovery.inventoryLayersCached([]string{"sha256:1111", "sha256:3333"})).To(BeFalse())
+				Expect(dbDiscovery.inventoryLayersCached(nil)).To(BeFalse())
+			})
+			It("should return false when the DB is not in the cache", func() {
+				dbDiscovery.saveInventoryLayers(layers)
+				Expect(os.Remove(pluginDBFile)).To(Succeed())
+				Expect(dbDiscovery.inventoryLayersCached(layers)).To(BeFalse())
+			})
+			It("should forget the saved layers when the layers are unknown", func() {
+				dbDiscovery.saveInventoryLayers(layers)
+				dbDiscovery.saveInventoryLayers(nil)
+				Expect(dbDiscovery.inventoryLayersCached(layers)).To(BeFalse())
+			})
+		})
+		Context("invalidateCorruptedCache function", func() {
+			const discoveryName = "test-discovery"
+			var dbDir, pluginDBdir, pluginDBFile, digestFile string
+			var dbDiscovery *DBBackedOCIDiscovery
+			BeforeEach(func() {
+				dbDir, err = os.MkdirTemp("", "test-cache-dir")
+				Expect(err).To(BeNil())
+
+				common.DefaultCacheDir = dbDir
+
+				// Create a valid DB file along with its digest file
+				pluginDBdir = filepath.Join(common.DefaultCacheDir, common.PluginInventoryDirName, discoveryName)
+				err = os.MkdirAll(pluginDBdir, 0755)
+				Expect(err).To(BeNil())
+				pluginDBFile = filepath.Join(pluginDBdir, plugininventory.SQliteDBFileName)
+				err = plugininventory.CreateInventoryDB(pluginDBFile, &plugininventory.InventorySeed{})
+				Expect(err).To(BeNil())
+				digestFile = filepath.Join(pluginDBdir, "digest.1234567890")
+				err = os.WriteFile(digestFile, []byte("test-image:latest"), 0644)
+				Expect(err).To(BeNil())
+
+				var ok bool
+				dbDiscovery, ok = NewOCIDiscovery(discoveryName, "test-image:latest").(*DBBackedOCIDiscovery)
+				Expect(ok).To(BeTrue(), "oci discovery is not of type DBBackedOCIDiscovery")
+				dbDiscovery.saveInventoryLayers([]string{"sha256:1111"})
+			})
+			AfterEach(func() {
+				os.RemoveAll(dbDir)
+			})
+
+			It("should keep a valid cache", func() {
+				dbDiscovery.invalidateCorruptedCache()
+				Expect(pluginDBFile).To(BeAnExistingFile())
+				Expect(digestFile).To(BeAnExistingFile())
+				Expect(dbDiscovery.cacheTTLExpired()).To(BeFalse())
+			})
+			It("should remove a corrupted DB and expire the cache", func() {
+				err = os.WriteFile(pluginDBFile, []byte("truncated"), 0644)
+				Expect(err).To(BeNil())
+
+				dbDiscovery.invalidateCorruptedCache()
+				Expect(pluginDBFile).ToNot(BeAnExistingFile())
+				Expect(digestFile).ToNot(BeAnExistingFile())
+				Expect(filepath.Join(pluginDBdir, inventoryLayersFileName)).ToNot(BeAnExistingFile())
+				Expect(dbDiscovery.cacheTTLExpired()).To(BeTrue())
+			})
+		})
+
 		Context("cacheTTLExpired and resetCacheTTL functions", func() {
 			var dbDir, expiredDigest, nonExpiredDigest string
 			var err error
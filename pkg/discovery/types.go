@@ -4,9 +4,12 @@
 package discovery
 
 import (
+	"time"
+
 	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
 )
 
 // Discovered defines discovered plugin resource
@@ -17,6 +20,12 @@ type Discovered struct {
 	// Description is the plugin's description.
 	Description string
 
+	// Vendor is the vendor of the plugin, if provided by the discovery.
+	Vendor string
+
+	// Publisher is the publisher of the plugin, if provided by the discovery.
+	Publisher string
+
 	// RecommendedVersion is the version that Tanzu CLI should use if available.
 	// The value should be a valid semantic version as defined in
 	// https://semver.org/. E.g., 2.0.1
@@ -61,6 +70,27 @@ type Discovered struct {
 
 	// Status is the installed/uninstalled status of the plugin.
 	Status string
+
+	// ReleaseNotes contains the release notes of the versions which have some.
+	ReleaseNotes map[string]plugininventory.PluginReleaseNotes
+
+	// MinCLIVersions contains the minimum CLI version required by the versions which declare one.
+	MinCLIVersions map[string]string
+
+	// Licenses contains the license and SBOM of the versions which declare some.
+	Licenses map[string]plugininventory.PluginLicense
+
+	// ReleasedAt contains the time at which the versions which record one were published.
+	ReleasedAt map[string]time.Time
+
+	// Deprecated tells whether the plugin is deprecated.
+	Deprecated bool
+
+	// DeprecationMessage explains why the plugin is deprecated.
+	DeprecationMessage string
+
+	// ReplacedBy is the name of the plugin replacing the deprecated plugin, if any.
+	ReplacedBy string
 }
 
 // DiscoveredSorter sorts discovered objects.
@@ -74,3 +104,22 @@ func (d DiscoveredSorter) Less(i, j int) bool {
 	}
 	return string(d[i].Target) < string(d[j].Target)
 }
+
+// SortDiscovered sorts the discovered plugins by the field in the order.
+// The most recent version of a plugin is the last of its supported versions.
+func SortDiscovered(plugins []Discovered, sortBy plugininventory.PluginSortField, order plugininventory.SortOrder) error {
+	return plugininventory.SortPluginsBy(plugins, sortBy, order, func(i int) plugininventory.PluginSortValues {
+		values := plugininventory.PluginSortValues{
+			Name:               plugins[i].Name,
+			Target:             plugins[i].Target,
+			Vendor:             plugins[i].Vendor,
+			Publisher:          plugins[i].Publisher,
+			RecommendedVersion: plugins[i].RecommendedVersion,
+		}
+		if len(plugins[i].SupportedVersions) > 0 {
+			values.MostRecentVersion = plugins[i].SupportedVersions[len(plugins[i].SupportedVersions)-1]
+		}
+		values.Released = plugininventory.LatestReleaseTime(plugins[i].ReleasedAt)
+		return values
+	})
+}
@@ -4,6 +4,9 @@
 package distribution
 
 import (
+	"crypto/sha256"
+	"fmt"
+
 	"github.com/pkg/errors"
 
 	cliv1alpha1 "github.com/vmware-tanzu/tanzu-cli/apis/cli/v1alpha1"
@@ -27,6 +30,22 @@ type Artifact struct {
 
 	// Arch of the plugin binary in `GOARCH` format.
 	Arch string
+
+	// Size of the plugin binary or image in bytes, 0 if unknown.
+	Size int64
+
+	// Signature is a reference to the signature of the plugin binary, e.g. the URI
+	// of a cosign bundle or of a detached signature, empty if it is not signed.
+	Signature string
+}
+
+// ArtifactPlatformMultiArch is used as the OS and Arch of an artifact whose
+// image is a multi-arch image index containing the binaries of all the platforms.
+const ArtifactPlatformMultiArch = "multiarch"
+
+// IsMultiArch returns true if the image of the artifact is a multi-arch image index
+func (a *Artifact) IsMultiArch() bool {
+	return a.OS == ArtifactPlatformMultiArch && a.Arch == ArtifactPlatformMultiArch
 }
 
 // ArtifactList contains an Artifact object for every supported platform of a
@@ -55,9 +74,23 @@ func (aMap Artifacts) GetArtifact(version, os, arch string) (Artifact, error) {
 			return a, nil
 		}
 	}
+	// Fallback to the multi-arch image index, if any
+	for _, a := range aList {
+		if a.IsMultiArch() {
+			return a, nil
+		}
+	}
 	return Artifact{}, err
 }
 
+// newOCIArtifact returns the OCI artifact to use for the platform
+func newOCIArtifact(a *Artifact, os, arch string) artifact.Artifact {
+	if a.IsMultiArch() {
+		return artifact.NewOCIArtifactForPlatform(a.Image, os, arch)
+	}
+	return artifact.NewOCIArtifact(a.Image)
+}
+
 // Fetch the binary for a plugin version.
 func (aMap Artifacts) Fetch(version, os, arch string) ([]byte, error) {
 	a, err := aMap.GetArtifact(version, os, arch)
@@ -66,14 +99,21 @@ func (aMap Artifacts) Fetch(version, os, arch string) ([]byte, error) {
 	}
 
 	if a.Image != "" {
-		return artifact.NewOCIArtifact(a.Image).Fetch()
+		return newOCIArtifact(&a, os, arch).Fetch()
 	}
 	if a.URI != "" {
 		u, err := artifact.NewURIArtifact(a.URI)
 		if err != nil {
 			return nil, err
 		}
-		return u.Fetch()
+		b, err := u.Fetch()
+		if err != nil {
+			return nil, err
+		}
+		if err := verifyArtifactDigest(u, a, b); err != nil {
+			return nil, err
+		}
+		return b, nil
 	}
 
 	return nil, errors.Errorf("invalid artifact for version:%s, os:%s, "+
@@ -88,7 +128,7 @@ func (aMap Artifacts) FetchTest(version, os, arch string) ([]byte, error) {
 	}
 
 	if a.Image != "" {
-		return artifact.NewOCIArtifact(a.Image).FetchTest()
+		return newOCIArtifact(&a, os, arch).FetchTest()
 	}
 	if a.URI != "" {
 		u, err := artifact.NewURIArtifact(a.URI)
@@ -101,6 +141,22 @@ func (aMap Artifacts) FetchTest(version, os, arch string) ([]byte, error) {
 	return nil, errors.Errorf("invalid artifact for version:%s, os:%s, arch:%s", version, os, arch)
 }
 
+// verifyArtifactDigest compares the digest of the artifact against the SHA256 hash of
+// the fetched binary. The digest is mandatory for the artifacts downloaded over HTTP(S)
+// as, unlike OCI images, nothing else guarantees the integrity of the download.
+func verifyArtifactDigest(u artifact.Artifact, a Artifact, b []byte) error { //nolint:gocritic
+	if a.Digest == "" {
+		if _, isHTTP := u.(*artifact.HTTPArtifact); isHTTP {
+			return errors.Errorf("no digest specified for the artifact %q", a.URI)
+		}
+		return nil
+	}
+	if actualDigest := fmt.Sprintf("%x", sha256.Sum256(b)); actualDigest != a.Digest {
+		return errors.Errorf("digest mismatch for the artifact %q. expected digest: %s, actual digest: %s", a.URI, a.Digest, actualDigest)
+	}
+	return nil
+}
+
 // GetDigest returns the SHA256 hash of the binary for a plugin version.
 func (aMap Artifacts) GetDigest(version, os, arch string) (string, error) {
 	a, err := aMap.GetArtifact(version, os, arch)
@@ -111,6 +167,16 @@ func (aMap Artifacts) GetDigest(version, os, arch string) (string, error) {
 	return a.Digest, nil
 }
 
+// GetSize returns the size in bytes of the binary for a plugin version, 0 if unknown.
+func (aMap Artifacts) GetSize(version, os, arch string) (int64, error) {
+	a, err := aMap.GetArtifact(version, os, arch)
+	if err != nil {
+		return 0, err
+	}
+
+	return a.Size, nil
+}
+
 // DescribeArtifact returns the artifact resource based plugin metadata
 func (aMap Artifacts) DescribeArtifact(version, os, arch string) (Artifact, error) {
 	return aMap.GetArtifact(version, os, arch)
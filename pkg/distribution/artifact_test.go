@@ -4,6 +4,10 @@
 package distribution
 
 import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
@@ -24,6 +28,7 @@ var _ = Describe("Unit tests for distribution", func() {
 		Digest: "digest1",
 		OS:     "ubuntu",
 		Arch:   "amd64",
+		Size:   1024,
 	}
 
 	artifact2 := Artifact{
@@ -82,6 +87,24 @@ var _ = Describe("Unit tests for distribution", func() {
 			Expect(artifact).To(Equal(expectedArtifact))
 		})
 
+		var _ = It("should fallback to the multi-arch artifact", func() {
+			multiArchArtifact := Artifact{
+				Image: "index",
+				OS:    ArtifactPlatformMultiArch,
+				Arch:  ArtifactPlatformMultiArch,
+			}
+			artifacts := Artifacts{"1.0.0": ArtifactList{artifact1, multiArchArtifact}}
+
+			artifact, err := artifacts.GetArtifact("1.0.0", "ubuntu", "amd64")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(artifact).To(Equal(artifact1))
+
+			artifact, err = artifacts.GetArtifact("1.0.0", "darwin", "arm64")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(artifact).To(Equal(multiArchArtifact))
+			Expect(artifact.IsMultiArch()).To(BeTrue())
+		})
+
 	})
 
 	var _ = Context("Unit tests for the Fetch function", func() {
@@ -108,6 +131,41 @@ var _ = Describe("Unit tests for distribution", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(artifact).ToNot(BeNil())
 		})
+
+		It("should verify the digest of the URI artifacts", func() {
+			Expect(os.WriteFile(tmpFileName, []byte("binary"), 0644)).To(Succeed())
+			artifacts := Artifacts{"1.0.0": ArtifactList{{
+				URI:    "file://" + tmpFileName,
+				Digest: fmt.Sprintf("%x", sha256.Sum256([]byte("binary"))),
+				OS:     "linux",
+				Arch:   "amd64",
+			}}}
+			b, err := artifacts.Fetch("1.0.0", "linux", "amd64")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(b)).To(Equal("binary"))
+
+			artifacts["1.0.0"][0].Digest = "invalid"
+			_, err = artifacts.Fetch("1.0.0", "linux", "amd64")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("digest mismatch"))
+		})
+
+		It("should require a digest for the HTTP(S) artifacts", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("binary"))
+			}))
+			defer server.Close()
+
+			artifacts := Artifacts{"1.0.0": ArtifactList{{URI: server.URL + "/plugin", OS: "linux", Arch: "amd64"}}}
+			_, err := artifacts.Fetch("1.0.0", "linux", "amd64")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("no digest specified"))
+
+			artifacts["1.0.0"][0].Digest = fmt.Sprintf("%x", sha256.Sum256([]byte("binary")))
+			b, err := artifacts.Fetch("1.0.0", "linux", "amd64")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(b)).To(Equal("binary"))
+		})
 	})
 
 	var _ = Context("Unit tests for getting the digest", func() {
@@ -124,6 +182,19 @@ var _ = Describe("Unit tests for distribution", func() {
 		})
 	})
 
+	var _ = Context("tests for the GetSize function", func() {
+		var _ = It("test happy path", func() {
+			size, err := sampleArtifacts.GetSize("1.0.0", "ubuntu", "amd64")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(size).To(Equal(artifact1.Size))
+		})
+
+		var _ = It("test error in getting artifact", func() {
+			_, err := sampleArtifacts.GetSize("1.0.0", "linux", "amd64")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
 	Context("Unit tests for Describe Artifact", func() {
 		It("Test Happy Path", func() {
 			artifact, err := sampleArtifacts.DescribeArtifact("1.0.0", "ubuntu", "amd64")
@@ -18,6 +18,9 @@ type Distribution interface {
 	// GetDigest returns the SHA256 hash of the binary for a plugin version.
 	GetDigest(version, os, arch string) (string, error)
 
+	// GetSize returns the size in bytes of the binary for a plugin version, 0 if unknown.
+	GetSize(version, os, arch string) (int64, error)
+
 	// DescribeArtifact returns the artifact resource based plugin metadata
 	DescribeArtifact(version, os, arch string) (Artifact, error)
 }
@@ -0,0 +1,19 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package distribution
+
+// ArtifactVerifier verifies a plugin binary downloaded from an artifact before it
+// is installed, e.g. against the signature referenced by the artifact.
+type ArtifactVerifier interface {
+	// Verify returns an error if the binary downloaded from the artifact must not be installed.
+	Verify(a *Artifact, binary []byte) error
+}
+
+// ArtifactVerifierFunc is a function implementing the ArtifactVerifier interface.
+type ArtifactVerifierFunc func(a *Artifact, binary []byte) error
+
+// Verify calls the function.
+func (f ArtifactVerifierFunc) Verify(a *Artifact, binary []byte) error {
+	return f(a, binary)
+}
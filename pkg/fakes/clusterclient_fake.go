@@ -46,6 +46,18 @@ type ClusterClient struct {
 		result1 []v1alpha1.CLIPlugin
 		result2 error
 	}
+	ListTanzuCLIPluginSetResourcesStub        func() ([]v1alpha1.TanzuCLIPluginSet, error)
+	listTanzuCLIPluginSetResourcesMutex       sync.RWMutex
+	listTanzuCLIPluginSetResourcesArgsForCall []struct {
+	}
+	listTanzuCLIPluginSetResourcesReturns struct {
+		result1 []v1alpha1.TanzuCLIPluginSet
+		result2 error
+	}
+	listTanzuCLIPluginSetResourcesReturnsOnCall map[int]struct {
+		result1 []v1alpha1.TanzuCLIPluginSet
+		result2 error
+	}
 	VerifyCLIPluginCRDStub        func() (bool, error)
 	verifyCLIPluginCRDMutex       sync.RWMutex
 	verifyCLIPluginCRDArgsForCall []struct {
@@ -230,6 +242,62 @@ func (fake *ClusterClient) ListCLIPluginResourcesReturnsOnCall(i int, result1 []
 	}{result1, result2}
 }
 
+func (fake *ClusterClient) ListTanzuCLIPluginSetResources() ([]v1alpha1.TanzuCLIPluginSet, error) {
+	fake.listTanzuCLIPluginSetResourcesMutex.Lock()
+	ret, specificReturn := fake.listTanzuCLIPluginSetResourcesReturnsOnCall[len(fake.listTanzuCLIPluginSetResourcesArgsForCall)]
+	fake.listTanzuCLIPluginSetResourcesArgsForCall = append(fake.listTanzuCLIPluginSetResourcesArgsForCall, struct {
+	}{})
+	stub := fake.ListTanzuCLIPluginSetResourcesStub
+	fakeReturns := fake.listTanzuCLIPluginSetResourcesReturns
+	fake.recordInvocation("ListTanzuCLIPluginSetResources", []interface{}{})
+	fake.listTanzuCLIPluginSetResourcesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ClusterClient) ListTanzuCLIPluginSetResourcesCallCount() int {
+	fake.listTanzuCLIPluginSetResourcesMutex.RLock()
+	defer fake.listTanzuCLIPluginSetResourcesMutex.RUnlock()
+	return len(fake.listTanzuCLIPluginSetResourcesArgsForCall)
+}
+
+func (fake *ClusterClient) ListTanzuCLIPluginSetResourcesCalls(stub func() ([]v1alpha1.TanzuCLIPluginSet, error)) {
+	fake.listTanzuCLIPluginSetResourcesMutex.Lock()
+	defer fake.listTanzuCLIPluginSetResourcesMutex.Unlock()
+	fake.ListTanzuCLIPluginSetResourcesStub = stub
+}
+
+func (fake *ClusterClient) ListTanzuCLIPluginSetResourcesReturns(result1 []v1alpha1.TanzuCLIPluginSet, result2 error) {
+	fake.listTanzuCLIPluginSetResourcesMutex.Lock()
+	defer fake.listTanzuCLIPluginSetResourcesMutex.Unlock()
+	fake.ListTanzuCLIPluginSetResourcesStub = nil
+	fake.listTanzuCLIPluginSetResourcesReturns = struct {
+		result1 []v1alpha1.TanzuCLIPluginSet
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ClusterClient) ListTanzuCLIPluginSetResourcesReturnsOnCall(i int, result1 []v1alpha1.TanzuCLIPluginSet, result2 error) {
+	fake.listTanzuCLIPluginSetResourcesMutex.Lock()
+	defer fake.listTanzuCLIPluginSetResourcesMutex.Unlock()
+	fake.ListTanzuCLIPluginSetResourcesStub = nil
+	if fake.listTanzuCLIPluginSetResourcesReturnsOnCall == nil {
+		fake.listTanzuCLIPluginSetResourcesReturnsOnCall = make(map[int]struct {
+			result1 []v1alpha1.TanzuCLIPluginSet
+			result2 error
+		})
+	}
+	fake.listTanzuCLIPluginSetResourcesReturnsOnCall[i] = struct {
+		result1 []v1alpha1.TanzuCLIPluginSet
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *ClusterClient) VerifyCLIPluginCRD() (bool, error) {
 	fake.verifyCLIPluginCRDMutex.Lock()
 	ret, specificReturn := fake.verifyCLIPluginCRDReturnsOnCall[len(fake.verifyCLIPluginCRDArgsForCall)]
@@ -295,6 +363,8 @@ func (fake *ClusterClient) Invocations() map[string][][]interface{} {
 	defer fake.getCLIPluginImageRepositoryOverrideMutex.RUnlock()
 	fake.listCLIPluginResourcesMutex.RLock()
 	defer fake.listCLIPluginResourcesMutex.RUnlock()
+	fake.listTanzuCLIPluginSetResourcesMutex.RLock()
+	defer fake.listTanzuCLIPluginSetResourcesMutex.RUnlock()
 	fake.verifyCLIPluginCRDMutex.RLock()
 	defer fake.verifyCLIPluginCRDMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
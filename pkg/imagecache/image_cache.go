@@ -0,0 +1,273 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package imagecache implements a local cache of the content of the downloaded
+// OCI images. The cache is keyed by the digest of the images and is shared by
+// the plugin installation, the refresh of the plugin inventories and the
+// creation of plugin bundles.
+package imagecache
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+)
+
+// filesDirName is the name of the directory of an entry containing the files of the image
+const filesDirName = "files"
+
+// Entry describes an image stored in the cache
+type Entry struct {
+	// Digest of the image, e.g. sha256:<hex>
+	Digest string
+	// Size of the files of the image in bytes
+	Size int64
+	// LastUsed is the last time the image was stored or read from the cache
+	LastUsed time.Time
+}
+
+// Cache is a digest-keyed cache of the files of the downloaded images.
+// The least recently used images are removed once the size of the cache exceeds its maximum size.
+type Cache struct {
+	dir     string
+	maxSize int64
+}
+
+// NewCache returns a cache stored in the specified directory.
+// A maximum size of 0 disables the cache.
+func NewCache(dir string, maxSize int64) *Cache {
+	return &Cache{dir: dir, maxSize: maxSize}
+}
+
+// NewDefaultCache returns the cache shared by all the CLI operations,
+// taking into account the maximum size configured through the environment variable
+func NewDefaultCache() *Cache {
+	maxSizeMB := int64(constants.DefaultImageCacheMaxSizeMB)
+	if override := os.Getenv(constants.ConfigVariableImageCacheMaxSizeMB); override != "" {
+		if value, err := strconv.ParseInt(override, 10, 64); err == nil && value >= 0 {
+			maxSizeMB = value
+		}
+	}
+	return NewCache(GetDefaultCacheDir(), maxSizeMB*1024*1024)
+}
+
+// GetDefaultCacheDir returns the directory of the cache shared by all the CLI operations
+func GetDefaultCacheDir() string {
+	// NOTE: TEST_CUSTOM_IMAGE_CACHE_DIR is only for test purpose
+	if customCacheDirForTest := os.Getenv("TEST_CUSTOM_IMAGE_CACHE_DIR"); customCacheDirForTest != "" {
+		return customCacheDirForTest
+	}
+	return filepath.Join(common.DefaultCacheDir, common.ImageCacheDirName)
+}
+
+// Enabled returns true if the cache can store images
+func (c *Cache) Enabled() bool {
+	return c.maxSize > 0
+}
+
+// Get returns the files of the image with the specified digest if the image is in the cache
+func (c *Cache) Get(digest string) (map[string][]byte, bool) {
+	if !c.Enabled() {
+		return nil, false
+	}
+	entryDir, err := c.entryDir(digest)
+	if err != nil {
+		return nil, false
+	}
+	filesDir := filepath.Join(entryDir, filesDirName)
+	files := map[string][]byte{}
+	err = filepath.WalkDir(filesDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		relPath, err := filepath.Rel(filesDir, path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(relPath)] = content
+		return nil
+	})
+	if err != nil || len(files) == 0 {
+		return nil, false
+	}
+
+	// Mark the entry as recently used
+	now := time.Now()
+	_ = os.Chtimes(entryDir, now, now)
+	return files, true
+}
+
+// Put stores the files of the image with the specified digest in the cache
+// and removes the least recently used images if the cache exceeds its maximum size
+func (c *Cache) Put(digest string, files map[string][]byte) error {
+	if !c.Enabled() {
+		return nil
+	}
+	entryDir, err := c.entryDir(digest)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(entryDir); err == nil {
+		// The content of an image cannot change for a given digest
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(entryDir), os.ModePerm); err != nil {
+		return errors.Wrap(err, "unable to create the image cache directory")
+	}
+
+	// Write the files to a temporary directory first so that
+	// concurrent readers never see a partially written entry
+	tempDir, err := os.MkdirTemp(filepath.Dir(entryDir), ".tmp-")
+	if err != nil {
+		return errors.Wrap(err, "unable to create the image cache entry")
+	}
+	defer os.RemoveAll(tempDir)
+	tempFilesDir := filepath.Join(tempDir, filesDirName)
+	for name, content := range files {
+		path := filepath.Join(tempFilesDir, filepath.FromSlash(name))
+		if !strings.HasPrefix(path, tempFilesDir+string(os.PathSeparator)) {
+			return errors.Errorf("invalid file path %q for the image %s", name, digest)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			return errors.Wrap(err, "unable to write the image cache entry")
+		}
+	}
+	if err := os.Rename(tempDir, entryDir); err != nil && !os.IsExist(err) {
+		return errors.Wrap(err, "unable to store the image cache entry")
+	}
+
+	return c.GarbageCollect()
+}
+
+// Entries returns the images stored in the cache, most recently used first
+func (c *Cache) Entries() ([]Entry, error) {
+	algorithmDirs, err := os.ReadDir(c.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "unable to read the image cache")
+	}
+
+	var entries []Entry
+	for _, algorithmDir := range algorithmDirs {
+		if !algorithmDir.IsDir() {
+			continue
+		}
+		entryDirs, err := os.ReadDir(filepath.Join(c.dir, algorithmDir.Name()))
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read the image cache")
+		}
+		for _, entryDir := range entryDirs {
+			if !entryDir.IsDir() || strings.HasPrefix(entryDir.Name(), ".") {
+				continue
+			}
+			info, err := entryDir.Info()
+			if err != nil {
+				continue
+			}
+			size, err := dirSize(filepath.Join(c.dir, algorithmDir.Name(), entryDir.Name()))
+			if err != nil {
+				continue
+			}
+			entries = append(entries, Entry{
+				Digest:   algorithmDir.Name() + ":" + entryDir.Name(),
+				Size:     size,
+				LastUsed: info.ModTime(),
+			})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastUsed.After(entries[j].LastUsed)
+	})
+	return entries, nil
+}
+
+// Size returns the total size of the images stored in the cache in bytes
+func (c *Cache) Size() (int64, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return 0, err
+	}
+	var size int64
+	for _, e := range entries {
+		size += e.Size
+	}
+	return size, nil
+}
+
+// GarbageCollect removes the least recently used images until
+// the size of the cache no longer exceeds its maximum size
+func (c *Cache) GarbageCollect() error {
+	entries, err := c.Entries()
+	if err != nil {
+		return err
+	}
+	var size int64
+	for _, e := range entries {
+		size += e.Size
+	}
+	// Entries are sorted from the most recently used to the least recently used
+	for i := len(entries) - 1; i >= 0 && size > c.maxSize; i-- {
+		if err := c.Remove(entries[i].Digest); err != nil {
+			return err
+		}
+		size -= entries[i].Size
+	}
+	return nil
+}
+
+// Remove removes the image with the specified digest from the cache
+func (c *Cache) Remove(digest string) error {
+	entryDir, err := c.entryDir(digest)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(entryDir)
+}
+
+// Clear removes all the images from the cache
+func (c *Cache) Clear() error {
+	return os.RemoveAll(c.dir)
+}
+
+// entryDir returns the directory of the cache entry of the image with the specified digest
+func (c *Cache) entryDir(digest string) (string, error) {
+	algorithm, hex, found := strings.Cut(digest, ":")
+	if !found || algorithm == "" || hex == "" || strings.ContainsAny(digest, `/\.`) {
+		return "", errors.Errorf("invalid image digest %q", digest)
+	}
+	return filepath.Join(c.dir, algorithm, hex), nil
+}
+
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
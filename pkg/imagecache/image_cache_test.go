@@ -0,0 +1,133 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package imagecache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CachePutGet(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := os.MkdirTemp("", "test-image-cache")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	cache := NewCache(dir, 1024)
+	assert.True(cache.Enabled())
+
+	_, found := cache.Get("sha256:1234")
+	assert.False(found)
+
+	files := map[string][]byte{
+		"plugin_inventory.db": []byte("db"),
+		"sub/dir/file.yaml":   []byte("yaml"),
+	}
+	assert.Nil(cache.Put("sha256:1234", files))
+
+	cachedFiles, found := cache.Get("sha256:1234")
+	assert.True(found)
+	assert.Equal(files, cachedFiles)
+
+	entries, err := cache.Entries()
+	assert.Nil(err)
+	assert.Len(entries, 1)
+	assert.Equal("sha256:1234", entries[0].Digest)
+	assert.Equal(int64(6), entries[0].Size)
+
+	assert.Nil(cache.Remove("sha256:1234"))
+	_, found = cache.Get("sha256:1234")
+	assert.False(found)
+}
+
+func Test_CacheGarbageCollect(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := os.MkdirTemp("", "test-image-cache")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	cache := NewCache(dir, 10)
+	assert.Nil(cache.Put("sha256:1111", map[string][]byte{"file": []byte("1111")}))
+	assert.Nil(cache.Put("sha256:2222", map[string][]byte{"file": []byte("2222")}))
+
+	// Make the first image the most recently used one
+	past := time.Now().Add(-time.Hour)
+	assert.Nil(os.Chtimes(filepath.Join(dir, "sha256", "1111"), past, past))
+	assert.Nil(os.Chtimes(filepath.Join(dir, "sha256", "2222"), past, past))
+	_, found := cache.Get("sha256:1111")
+	assert.True(found)
+
+	// Adding a third image exceeds the maximum size and evicts the least recently used image
+	assert.Nil(cache.Put("sha256:3333", map[string][]byte{"file": []byte("3333")}))
+
+	_, found = cache.Get("sha256:2222")
+	assert.False(found)
+	_, found = cache.Get("sha256:1111")
+	assert.True(found)
+	_, found = cache.Get("sha256:3333")
+	assert.True(found)
+
+	size, err := cache.Size()
+	assert.Nil(err)
+	assert.Equal(int64(8), size)
+
+	assert.Nil(cache.Clear())
+	entries, err := cache.Entries()
+	assert.Nil(err)
+	assert.Empty(entries)
+}
+
+func Test_CacheDisabled(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := os.MkdirTemp("", "test-image-cache")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	cache := NewCache(dir, 0)
+	assert.False(cache.Enabled())
+	assert.Nil(cache.Put("sha256:1234", map[string][]byte{"file": []byte("content")}))
+	_, found := cache.Get("sha256:1234")
+	assert.False(found)
+}
+
+func Test_CacheInvalidDigest(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := os.MkdirTemp("", "test-image-cache")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	cache := NewCache(dir, 1024)
+	for _, digest := range []string{"", "sha256", "sha256:", "sha256:../1234", "../sha256:1234"} {
+		err = cache.Put(digest, map[string][]byte{"file": []byte("content")})
+		assert.NotNil(err)
+		assert.Contains(err.Error(), "invalid image digest")
+	}
+
+	err = cache.Put("sha256:1234", map[string][]byte{"../file": []byte("content")})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "invalid file path")
+}
+
+func Test_NewDefaultCache(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Setenv("TEST_CUSTOM_IMAGE_CACHE_DIR", "/tmp/test-image-cache")
+	cache := NewDefaultCache()
+	assert.Equal("/tmp/test-image-cache", cache.dir)
+	assert.Equal(int64(1024*1024*1024), cache.maxSize)
+
+	t.Setenv("TANZU_CLI_IMAGE_CACHE_MAX_SIZE_MB", "0")
+	assert.False(NewDefaultCache().Enabled())
+
+	t.Setenv("TANZU_CLI_IMAGE_CACHE_MAX_SIZE_MB", "invalid")
+	assert.True(NewDefaultCache().Enabled())
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugincmdtree
+
+import (
+	"sort"
+	"strings"
+)
+
+// Complete returns the names of the subcommands starting with toComplete of the command found
+// by following the args from the node, so that the shell completion of the plugin subcommands
+// does not need to execute the plugin. It returns false when the completions are not known from
+// the tree, e.g. for the flags or the arguments of the commands without subcommands, which only
+// the plugin can complete.
+func (n *CommandNode) Complete(args []string, toComplete string) ([]string, bool) {
+	if n == nil || strings.HasPrefix(toComplete, "-") {
+		return nil, false
+	}
+	cmd := n
+	for _, arg := range args {
+		if cmd = cmd.subcommand(arg); cmd == nil {
+			// A flag or an argument of a command
+			return nil, false
+		}
+	}
+	if len(cmd.Subcommands) == 0 {
+		return nil, false
+	}
+
+	completions := []string{}
+	for name := range cmd.Subcommands {
+		if strings.HasPrefix(name, toComplete) {
+			completions = append(completions, name)
+		}
+	}
+	sort.Strings(completions)
+	return completions, true
+}
+
+// subcommand returns the subcommand of the node with the given name or alias, or nil if there is none
+func (n *CommandNode) subcommand(nameOrAlias string) *CommandNode {
+	if subCmd, exists := n.Subcommands[nameOrAlias]; exists {
+		return subCmd
+	}
+	for _, subCmd := range n.Subcommands {
+		if subCmd == nil {
+			continue
+		}
+		if _, exists := subCmd.Aliases[nameOrAlias]; exists {
+			return subCmd
+		}
+	}
+	return nil
+}
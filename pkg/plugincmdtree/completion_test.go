@@ -0,0 +1,55 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugincmdtree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandNode_Complete(t *testing.T) {
+	assert := assert.New(t)
+
+	nodePool := NewCommandNode()
+	nodePool.Aliases = map[string]struct{}{"node-pool": {}, "np": {}}
+	nodePool.Subcommands["list"] = NewCommandNode()
+	tree := NewCommandNode()
+	tree.Subcommands["list"] = NewCommandNode()
+	tree.Subcommands["login"] = NewCommandNode()
+	tree.Subcommands["delete"] = NewCommandNode()
+	tree.Subcommands["node-pool"] = nodePool
+
+	comps, ok := tree.Complete(nil, "")
+	assert.True(ok)
+	assert.Equal([]string{"delete", "list", "login", "node-pool"}, comps)
+
+	comps, ok = tree.Complete(nil, "l")
+	assert.True(ok)
+	assert.Equal([]string{"list", "login"}, comps)
+
+	comps, ok = tree.Complete(nil, "x")
+	assert.True(ok)
+	assert.Empty(comps)
+
+	// The subcommands are found by name or alias
+	comps, ok = tree.Complete([]string{"node-pool"}, "")
+	assert.True(ok)
+	assert.Equal([]string{"list"}, comps)
+	comps, ok = tree.Complete([]string{"np"}, "")
+	assert.True(ok)
+	assert.Equal([]string{"list"}, comps)
+
+	// The arguments and flags of the commands are left to the plugin
+	_, ok = tree.Complete([]string{"delete"}, "")
+	assert.False(ok)
+	_, ok = tree.Complete(nil, "--")
+	assert.False(ok)
+	_, ok = tree.Complete([]string{"--verbose", "list"}, "")
+	assert.False(ok)
+
+	var missingTree *CommandNode
+	_, ok = missingTree.Complete(nil, "")
+	assert.False(ok)
+}
@@ -27,3 +27,24 @@ CREATE TABLE IF NOT EXISTS "PluginGroups" (
 		"Hidden"             TEXT NOT NULL,
 		PRIMARY KEY("Vendor", "Publisher", "GroupName", "GroupVersion", "PluginName", "Target")
 );
+
+CREATE INDEX IF NOT EXISTS "PluginBinariesTargetIndex" ON "PluginBinaries" ("Target", "PluginName");
+
+CREATE INDEX IF NOT EXISTS "PluginGroupsGroupNameIndex" ON "PluginGroups" ("GroupName");
+
+CREATE TABLE IF NOT EXISTS "PluginReleaseNotes" (
+		"PluginName"         TEXT NOT NULL,
+		"Target"             TEXT NOT NULL,
+		"Version"            TEXT NOT NULL,
+		"ChangelogURL"       TEXT NOT NULL,
+		"Notes"              TEXT NOT NULL,
+		PRIMARY KEY("PluginName", "Target", "Version")
+);
+
+CREATE TABLE IF NOT EXISTS "PluginCompatibility" (
+		"PluginName"         TEXT NOT NULL,
+		"Target"             TEXT NOT NULL,
+		"Version"            TEXT NOT NULL,
+		"MinCLIVersion"      TEXT NOT NULL,
+		PRIMARY KEY("PluginName", "Target", "Version")
+);
@@ -0,0 +1,269 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugininventory
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+)
+
+// FileInventoryManifest is a manifest of a file inventory, in YAML or JSON.
+// Its plugins and plugin-groups have the same format as the ones served by an HTTP inventory.
+type FileInventoryManifest struct {
+	Plugins      []HTTPInventoryPlugin      `json:"plugins,omitempty" yaml:"plugins,omitempty"`
+	PluginGroups []HTTPInventoryPluginGroup `json:"pluginGroups,omitempty" yaml:"pluginGroups,omitempty"`
+}
+
+// FileInventory is a read-only inventory described by YAML or JSON manifests, for the plugin
+// repositories hosted on a static file server or in a git repository without an inventory DB.
+//
+// The location of the inventory is either:
+//   - a manifest file,
+//   - a directory, of which all the '.yaml', '.yml' and '.json' files are manifests,
+//   - or the http(s) URL of a manifest file.
+type FileInventory struct {
+	// location is the path or URL of the manifests of the inventory
+	location string
+	// client is the HTTP client used to download a manifest from a URL
+	client *http.Client
+}
+
+// NewFileInventory returns a new PluginInventory described by the manifests at 'location'.
+func NewFileInventory(location string) PluginInventory {
+	return &FileInventory{
+		location: location,
+		client:   http.DefaultClient,
+	}
+}
+
+// GetAllPlugins returns all plugins found in the inventory.
+func (f *FileInventory) GetAllPlugins(ctx context.Context) ([]*PluginInventoryEntry, error) {
+	return f.GetPlugins(ctx, &PluginInventoryFilter{})
+}
+
+// GetPlugins returns the plugins found in the inventory that match the provided filter.
+func (f *FileInventory) GetPlugins(ctx context.Context, filter *PluginInventoryFilter) ([]*PluginInventoryEntry, error) {
+	inventory, err := f.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return inventory.GetPlugins(ctx, filter)
+}
+
+// WalkPlugins calls fn for each plugin found in the inventory that matches the provided filter.
+func (f *FileInventory) WalkPlugins(ctx context.Context, filter *PluginInventoryFilter, fn func(*PluginInventoryEntry) error) error {
+	inventory, err := f.load(ctx)
+	if err != nil {
+		return err
+	}
+	return inventory.WalkPlugins(ctx, filter, fn)
+}
+
+// GetPluginsPage returns at most 'limit' plugins matching the provided filter, after skipping the first
+// 'offset' of them in the order of their name and target.
+func (f *FileInventory) GetPluginsPage(ctx context.Context, filter *PluginInventoryFilter, offset, limit int) ([]*PluginInventoryEntry, error) {
+	inventory, err := f.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return inventory.GetPluginsPage(ctx, filter, offset, limit)
+}
+
+// GetPluginGroups returns the plugin groups found in the inventory that match the provided filter.
+func (f *FileInventory) GetPluginGroups(ctx context.Context, filter PluginGroupFilter) ([]*PluginGroup, error) {
+	inventory, err := f.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return inventory.GetPluginGroups(ctx, filter)
+}
+
+// load returns an inventory containing the plugins and plugin-groups of the manifests
+func (f *FileInventory) load(ctx context.Context) (*InMemoryInventory, error) {
+	manifests, err := f.readManifests(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var plugins []*PluginInventoryEntry
+	var groups []*PluginGroup
+	for i := range manifests {
+		for j := range manifests[i].Plugins {
+			plugins = append(plugins, pluginFromHTTP(&manifests[i].Plugins[j]))
+		}
+		for j := range manifests[i].PluginGroups {
+			groups = append(groups, pluginGroupFromHTTP(&manifests[i].PluginGroups[j]))
+		}
+	}
+
+	inventory := newInMemoryInventory()
+	if err := inventory.insertPlugins(ctx, plugins, true); err != nil {
+		return nil, errors.Wrapf(err, "invalid plugins in the plugin inventory at '%s'", f.location)
+	}
+	inventory.seedPluginGroups(groups)
+	return inventory, nil
+}
+
+// readManifests reads the manifests at the location of the inventory
+func (f *FileInventory) readManifests(ctx context.Context) ([]FileInventoryManifest, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(f.location, "http://") || strings.HasPrefix(f.location, "https://") {
+		b, err := f.download(ctx)
+		if err != nil {
+			return nil, err
+		}
+		manifest, err := parseFileInventoryManifest(f.location, b)
+		if err != nil {
+			return nil, err
+		}
+		return []FileInventoryManifest{*manifest}, nil
+	}
+
+	info, err := os.Stat(f.location)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to find the plugin inventory at '%s'", f.location)
+	}
+	files := []string{f.location}
+	if info.IsDir() {
+		entries, err := os.ReadDir(f.location)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read the plugin inventory at '%s'", f.location)
+		}
+		files = nil
+		for _, entry := range entries {
+			if !entry.IsDir() && isFileInventoryManifest(entry.Name()) {
+				files = append(files, filepath.Join(f.location, entry.Name()))
+			}
+		}
+		sort.Strings(files)
+	}
+
+	manifests := make([]FileInventoryManifest, 0, len(files))
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read the plugin inventory manifest '%s'", file)
+		}
+		manifest, err := parseFileInventoryManifest(file, b)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, *manifest)
+	}
+	return manifests, nil
+}
+
+// download downloads the manifest at the URL of the inventory
+func (f *FileInventory) download(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.location, http.NoBody)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid URL '%s' for the plugin inventory", f.location)
+	}
+	res, err := f.client.Do(req)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, errors.Wrapf(err, "unable to download the plugin inventory at '%s'", f.location)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
+		return nil, errors.Errorf("unable to download the plugin inventory at '%s', status code: %d", f.location, res.StatusCode)
+	}
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to download the plugin inventory at '%s'", f.location)
+	}
+	return b, nil
+}
+
+// isFileInventoryManifest returns true if the file is a manifest of a file inventory, based on its extension
+func isFileInventoryManifest(file string) bool {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// parseFileInventoryManifest parses a manifest in YAML or JSON, JSON being a subset of YAML
+func parseFileInventoryManifest(file string, b []byte) (*FileInventoryManifest, error) {
+	var manifest FileInventoryManifest
+	if err := yaml.Unmarshal(b, &manifest); err != nil {
+		return nil, errors.Wrapf(err, "invalid plugin inventory manifest '%s'", file)
+	}
+	return &manifest, nil
+}
+
+// CreateSchema returns an error as the inventory is read-only
+func (f *FileInventory) CreateSchema(_ context.Context) error {
+	return f.readOnlyError()
+}
+
+// MigrateSchema does nothing as the manifests of the inventory have no schema to migrate
+func (f *FileInventory) MigrateSchema(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// InsertPlugin returns an error as the inventory is read-only
+func (f *FileInventory) InsertPlugin(_ context.Context, _ *PluginInventoryEntry) error {
+	return f.readOnlyError()
+}
+
+// InsertPlugins returns an error as the inventory is read-only
+func (f *FileInventory) InsertPlugins(_ context.Context, _ []*PluginInventoryEntry) error {
+	return f.readOnlyError()
+}
+
+// InsertPluginGroup returns an error as the inventory is read-only
+func (f *FileInventory) InsertPluginGroup(_ context.Context, _ *PluginGroup, _ bool) error {
+	return f.readOnlyError()
+}
+
+// UpdatePluginActivationState returns an error as the inventory is read-only
+func (f *FileInventory) UpdatePluginActivationState(_ context.Context, _ *PluginInventoryEntry) error {
+	return f.readOnlyError()
+}
+
+// UpdatePluginGroupActivationState returns an error as the inventory is read-only
+func (f *FileInventory) UpdatePluginGroupActivationState(_ context.Context, _ *PluginGroup) error {
+	return f.readOnlyError()
+}
+
+// DeletePluginVersion returns an error as the inventory is read-only
+func (f *FileInventory) DeletePluginVersion(_ context.Context, _ string, _ configtypes.Target, _ string) error {
+	return f.readOnlyError()
+}
+
+// DeletePlugin returns an error as the inventory is read-only
+func (f *FileInventory) DeletePlugin(_ context.Context, _ string, _ configtypes.Target) error {
+	return f.readOnlyError()
+}
+
+// Export writes all the plugins and plugin-groups of the inventory to the writer in the provided format.
+func (f *FileInventory) Export(ctx context.Context, w io.Writer, format ExportFormat) error {
+	return exportInventory(ctx, f, w, format)
+}
+
+// Validate returns all the problems found in the plugins and plugin-groups of the inventory.
+func (f *FileInventory) Validate(ctx context.Context) error {
+	return validateInventory(ctx, f)
+}
+
+func (f *FileInventory) readOnlyError() error {
+	return errors.Errorf("the plugin inventory at '%s' is read-only; edit its manifests instead", f.location)
+}
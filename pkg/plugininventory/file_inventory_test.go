@@ -0,0 +1,134 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugininventory
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Unit tests for the file plugin inventory", func() {
+	var (
+		tmpDir          string
+		manifest        FileInventoryManifest
+		memoryInventory *InMemoryInventory
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp(os.TempDir(), "")
+		Expect(err).To(BeNil(), "unable to create temporary directory")
+
+		memoryInventory = NewInMemoryInventory(pluginsSeed.Plugins).(*InMemoryInventory)
+		memoryInventory.seedPluginGroups(groupsSeed.Groups)
+
+		manifest = FileInventoryManifest{}
+		for _, p := range pluginsSeed.Plugins {
+			manifest.Plugins = append(manifest.Plugins, pluginToHTTP(p))
+		}
+		for _, pg := range groupsSeed.Groups {
+			manifest.PluginGroups = append(manifest.PluginGroups, pluginGroupToHTTP(pg))
+		}
+	})
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	// expectSameInventory verifies that the inventory has the plugins and plugin-groups of the seed
+	expectSameInventory := func(inventory PluginInventory) {
+		for _, filter := range []PluginInventoryFilter{{}, {IncludeHidden: true}, {Name: "*-cluster", Version: cli.VersionLatest}} {
+			expectedFilter := filter
+			expected, err := memoryInventory.GetPlugins(context.Background(), &expectedFilter)
+			Expect(err).ToNot(HaveOccurred())
+			plugins, err := inventory.GetPlugins(context.Background(), &filter)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plugins).To(Equal(expected))
+		}
+		for _, filter := range []PluginGroupFilter{{}, {IncludeHidden: true}, {Name: "default", Version: cli.VersionLatest}} {
+			expected, err := memoryInventory.GetPluginGroups(context.Background(), filter)
+			Expect(err).ToNot(HaveOccurred())
+			groups, err := inventory.GetPluginGroups(context.Background(), filter)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(groups).To(Equal(expected))
+		}
+	}
+
+	It("should read the inventory from a YAML manifest", func() {
+		b, err := yaml.Marshal(&manifest)
+		Expect(err).ToNot(HaveOccurred())
+		file := filepath.Join(tmpDir, "inventory.yaml")
+		Expect(os.WriteFile(file, b, 0o600)).To(Succeed())
+
+		expectSameInventory(NewFileInventory(file))
+	})
+
+	It("should read the inventory from the YAML and JSON manifests of a directory", func() {
+		b, err := yaml.Marshal(&FileInventoryManifest{Plugins: manifest.Plugins})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(tmpDir, "plugins.yml"), b, 0o600)).To(Succeed())
+		b, err = json.Marshal(&FileInventoryManifest{PluginGroups: manifest.PluginGroups})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(tmpDir, "groups.json"), b, 0o600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(tmpDir, "README.md"), []byte("# Not a manifest"), 0o600)).To(Succeed())
+
+		expectSameInventory(NewFileInventory(tmpDir))
+	})
+
+	It("should download the inventory from the URL of a manifest", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/inventory.json" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(&manifest)
+		}))
+		defer server.Close()
+
+		expectSameInventory(NewFileInventory(server.URL + "/inventory.json"))
+
+		_, err := NewFileInventory(server.URL + "/missing.json").GetAllPlugins(context.Background())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("status code: 404"))
+	})
+
+	It("should return an error for an invalid or missing manifest", func() {
+		file := filepath.Join(tmpDir, "inventory.yaml")
+		Expect(os.WriteFile(file, []byte("plugins: {invalid"), 0o600)).To(Succeed())
+		_, err := NewFileInventory(file).GetAllPlugins(context.Background())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid plugin inventory manifest"))
+
+		_, err = NewFileInventory(filepath.Join(tmpDir, "missing.yaml")).GetAllPlugins(context.Background())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("unable to find the plugin inventory"))
+	})
+
+	It("should return an error for duplicate plugins in the manifests", func() {
+		b, err := yaml.Marshal(&FileInventoryManifest{Plugins: manifest.Plugins})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(tmpDir, "plugins.yaml"), b, 0o600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(tmpDir, "plugins-copy.yaml"), b, 0o600)).To(Succeed())
+
+		_, err = NewFileInventory(tmpDir).GetAllPlugins(context.Background())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid plugins in the plugin inventory"))
+	})
+
+	It("should not allow modifying the inventory", func() {
+		err := NewFileInventory(tmpDir).InsertPlugin(context.Background(), &piEntry1)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("is read-only"))
+	})
+})
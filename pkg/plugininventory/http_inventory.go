@@ -0,0 +1,442 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugininventory
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+)
+
+const (
+	// HTTPInventoryPluginsPath is the path, relative to the URL of an HTTP inventory,
+	// of the API listing the plugins of the inventory
+	HTTPInventoryPluginsPath = "plugins"
+	// HTTPInventoryPluginGroupsPath is the path, relative to the URL of an HTTP inventory,
+	// of the API listing the plugin-groups of the inventory
+	HTTPInventoryPluginGroupsPath = "plugin-groups"
+)
+
+// HTTPInventory is a read-only inventory served as JSON by a remote HTTP API, for the plugin
+// repositories fronted by an artifact service rather than an OCI registry.
+//
+// The API serves:
+//   - GET <url>/plugins: {"plugins": [HTTPInventoryPlugin...]}
+//   - GET <url>/plugin-groups: {"pluginGroups": [HTTPInventoryPluginGroup...]}
+//
+// The requests have the query parameters 'name', 'target', 'os', 'arch', 'publisher', 'vendor'
+// and 'includeHidden' for the plugins, and 'vendor', 'publisher', 'name' and 'includeHidden'
+// for the plugin-groups, which the API may use to only return the matching entries.
+// The entries returned are filtered again by the inventory, so the API can also ignore them.
+type HTTPInventory struct {
+	// inventoryURL is the URL of the API serving the inventory
+	inventoryURL string
+	// client is the HTTP client used to call the API
+	client *http.Client
+}
+
+// HTTPInventoryPlugin is a plugin as served by the API of an HTTP inventory,
+// or as listed in the manifests of a file inventory
+type HTTPInventoryPlugin struct {
+	Name               string                               `json:"name" yaml:"name"`
+	Target             configtypes.Target                   `json:"target" yaml:"target"`
+	Description        string                               `json:"description" yaml:"description"`
+	Publisher          string                               `json:"publisher" yaml:"publisher"`
+	Vendor             string                               `json:"vendor" yaml:"vendor"`
+	RecommendedVersion string                               `json:"recommendedVersion,omitempty" yaml:"recommendedVersion,omitempty"`
+	Hidden             bool                                 `json:"hidden,omitempty" yaml:"hidden,omitempty"`
+	Artifacts          map[string][]HTTPInventoryArtifact   `json:"artifacts" yaml:"artifacts"`
+	ReleaseNotes       map[string]HTTPInventoryReleaseNotes `json:"releaseNotes,omitempty" yaml:"releaseNotes,omitempty"`
+	MinCLIVersions     map[string]string                    `json:"minCLIVersions,omitempty" yaml:"minCLIVersions,omitempty"`
+	Licenses           map[string]HTTPInventoryLicense      `json:"licenses,omitempty" yaml:"licenses,omitempty"`
+	ReleasedAt         map[string]time.Time                 `json:"releasedAt,omitempty" yaml:"releasedAt,omitempty"`
+	Deprecated         bool                                 `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	DeprecationMessage string                               `json:"deprecationMessage,omitempty" yaml:"deprecationMessage,omitempty"`
+	ReplacedBy         string                               `json:"replacedBy,omitempty" yaml:"replacedBy,omitempty"`
+}
+
+// HTTPInventoryArtifact is an artifact of a plugin version as served by the API of an HTTP inventory,
+// or as listed in the manifests of a file inventory
+type HTTPInventoryArtifact struct {
+	Image     string `json:"image,omitempty" yaml:"image,omitempty"`
+	URI       string `json:"uri,omitempty" yaml:"uri,omitempty"`
+	Digest    string `json:"digest" yaml:"digest"`
+	OS        string `json:"os" yaml:"os"`
+	Arch      string `json:"arch" yaml:"arch"`
+	Size      int64  `json:"size,omitempty" yaml:"size,omitempty"`
+	Signature string `json:"signature,omitempty" yaml:"signature,omitempty"`
+}
+
+// HTTPInventoryReleaseNotes are the release notes of a plugin version as served by the API of an HTTP inventory,
+// or as listed in the manifests of a file inventory
+type HTTPInventoryReleaseNotes struct {
+	ChangelogURL string `json:"changelogURL,omitempty" yaml:"changelogURL,omitempty"`
+	Notes        string `json:"notes,omitempty" yaml:"notes,omitempty"`
+}
+
+// HTTPInventoryLicense is the license of a plugin version as served by the API of an HTTP inventory,
+// or as listed in the manifests of a file inventory
+type HTTPInventoryLicense struct {
+	License string `json:"license,omitempty" yaml:"license,omitempty"`
+	SBOMURI string `json:"sbomURI,omitempty" yaml:"sbomURI,omitempty"`
+}
+
+// HTTPInventoryPluginGroup is a plugin-group as served by the API of an HTTP inventory,
+// or as listed in the manifests of a file inventory
+type HTTPInventoryPluginGroup struct {
+	Vendor      string                                     `json:"vendor" yaml:"vendor"`
+	Publisher   string                                     `json:"publisher" yaml:"publisher"`
+	Name        string                                     `json:"name" yaml:"name"`
+	Description string                                     `json:"description" yaml:"description"`
+	Hidden      bool                                       `json:"hidden,omitempty" yaml:"hidden,omitempty"`
+	Versions    map[string][]HTTPInventoryPluginGroupEntry `json:"versions" yaml:"versions"`
+}
+
+// HTTPInventoryPluginGroupEntry is a plugin of a plugin-group version as served by the API of an HTTP inventory,
+// or as listed in the manifests of a file inventory
+type HTTPInventoryPluginGroupEntry struct {
+	Name      string             `json:"name" yaml:"name"`
+	Target    configtypes.Target `json:"target" yaml:"target"`
+	Version   string             `json:"version" yaml:"version"`
+	Mandatory bool               `json:"mandatory,omitempty" yaml:"mandatory,omitempty"`
+}
+
+// HTTPInventoryPluginsResponse is the response of the API listing the plugins of an HTTP inventory
+type HTTPInventoryPluginsResponse struct {
+	Plugins []HTTPInventoryPlugin `json:"plugins"`
+}
+
+// HTTPInventoryPluginGroupsResponse is the response of the API listing the plugin-groups of an HTTP inventory
+type HTTPInventoryPluginGroupsResponse struct {
+	PluginGroups []HTTPInventoryPluginGroup `json:"pluginGroups"`
+}
+
+// NewHTTPInventory returns a new PluginInventory served by the API at 'inventoryURL'.
+// The HTTP client is used to call the API, http.DefaultClient if nil.
+func NewHTTPInventory(inventoryURL string, client *http.Client) PluginInventory {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPInventory{
+		inventoryURL: strings.TrimSuffix(inventoryURL, "/"),
+		client:       client,
+	}
+}
+
+// GetAllPlugins returns all plugins found in the inventory.
+func (h *HTTPInventory) GetAllPlugins(ctx context.Context) ([]*PluginInventoryEntry, error) {
+	return h.GetPlugins(ctx, &PluginInventoryFilter{})
+}
+
+// GetPlugins returns the plugins found in the inventory that match the provided filter.
+func (h *HTTPInventory) GetPlugins(ctx context.Context, filter *PluginInventoryFilter) ([]*PluginInventoryEntry, error) {
+	inventory, err := h.fetchPlugins(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return inventory.GetPlugins(ctx, filter)
+}
+
+// WalkPlugins calls fn for each plugin found in the inventory that matches the provided filter.
+func (h *HTTPInventory) WalkPlugins(ctx context.Context, filter *PluginInventoryFilter, fn func(*PluginInventoryEntry) error) error {
+	inventory, err := h.fetchPlugins(ctx, filter)
+	if err != nil {
+		return err
+	}
+	return inventory.WalkPlugins(ctx, filter, fn)
+}
+
+// GetPluginsPage returns at most 'limit' plugins matching the provided filter, after skipping the first
+// 'offset' of them in the order of their name and target.
+func (h *HTTPInventory) GetPluginsPage(ctx context.Context, filter *PluginInventoryFilter, offset, limit int) ([]*PluginInventoryEntry, error) {
+	inventory, err := h.fetchPlugins(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return inventory.GetPluginsPage(ctx, filter, offset, limit)
+}
+
+// GetPluginGroups returns the plugin groups found in the inventory that match the provided filter.
+func (h *HTTPInventory) GetPluginGroups(ctx context.Context, filter PluginGroupFilter) ([]*PluginGroup, error) {
+	query := url.Values{}
+	setQueryParam(query, "vendor", filter.Vendor)
+	setQueryParam(query, "publisher", filter.Publisher)
+	setQueryParam(query, "name", filter.Name)
+	if filter.IncludeHidden {
+		query.Set("includeHidden", strconv.FormatBool(true))
+	}
+
+	var res HTTPInventoryPluginGroupsResponse
+	if err := h.get(ctx, HTTPInventoryPluginGroupsPath, query, &res); err != nil {
+		return nil, err
+	}
+
+	groups := make([]*PluginGroup, 0, len(res.PluginGroups))
+	for i := range res.PluginGroups {
+		groups = append(groups, pluginGroupFromHTTP(&res.PluginGroups[i]))
+	}
+	inventory := newInMemoryInventory()
+	inventory.seedPluginGroups(groups)
+	return inventory.GetPluginGroups(ctx, filter)
+}
+
+// fetchPlugins returns an inventory containing the plugins returned by the API for the filter
+func (h *HTTPInventory) fetchPlugins(ctx context.Context, filter *PluginInventoryFilter) (*InMemoryInventory, error) {
+	query := url.Values{}
+	if filter != nil {
+		if !isPluginNamePattern(filter.Name) {
+			setQueryParam(query, "name", strings.ToLower(filter.Name))
+		}
+		setQueryParam(query, "target", string(filter.Target))
+		setQueryParam(query, "os", filter.OS)
+		if filter.fallbackArch() == "" {
+			// Otherwise the artifacts of all the architectures are fetched, to fall back to some
+			setQueryParam(query, "arch", filter.Arch)
+		}
+		setQueryParam(query, "publisher", filter.Publisher)
+		setQueryParam(query, "vendor", filter.Vendor)
+		if filter.IncludeHidden {
+			query.Set("includeHidden", strconv.FormatBool(true))
+		}
+	}
+
+	var res HTTPInventoryPluginsResponse
+	if err := h.get(ctx, HTTPInventoryPluginsPath, query, &res); err != nil {
+		return nil, err
+	}
+
+	plugins := make([]*PluginInventoryEntry, 0, len(res.Plugins))
+	for i := range res.Plugins {
+		plugins = append(plugins, pluginFromHTTP(&res.Plugins[i]))
+	}
+	inventory := newInMemoryInventory()
+	if err := inventory.insertPlugins(ctx, plugins, true); err != nil {
+		return nil, errors.Wrapf(err, "invalid plugins returned by the plugin inventory at '%s'", h.inventoryURL)
+	}
+	return inventory, nil
+}
+
+// get calls the API at the path of the inventory and decodes its JSON response into v
+func (h *HTTPInventory) get(ctx context.Context, path string, query url.Values, v interface{}) error {
+	requestURL := h.inventoryURL + "/" + path
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, http.NoBody)
+	if err != nil {
+		return errors.Wrapf(err, "invalid URL '%s' for the plugin inventory", h.inventoryURL)
+	}
+	req.Header.Set("Accept", "application/json; charset=utf-8")
+
+	res, err := h.client.Do(req)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return errors.Wrapf(err, "unable to reach the plugin inventory at '%s'", h.inventoryURL)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
+		return errors.Errorf("API error from the plugin inventory at '%s', status code: %d", requestURL, res.StatusCode)
+	}
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return errors.Wrapf(err, "invalid response from the plugin inventory at '%s'", requestURL)
+	}
+	return nil
+}
+
+// setQueryParam sets the query parameter if the value is not empty
+func setQueryParam(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
+
+// pluginFromHTTP converts a plugin served by the API of an HTTP inventory to an inventory entry
+func pluginFromHTTP(p *HTTPInventoryPlugin) *PluginInventoryEntry {
+	entry := &PluginInventoryEntry{
+		Name:               p.Name,
+		Target:             configtypes.StringToTarget(strings.ToLower(string(p.Target))),
+		Description:        p.Description,
+		Publisher:          p.Publisher,
+		Vendor:             p.Vendor,
+		RecommendedVersion: p.RecommendedVersion,
+		Hidden:             p.Hidden,
+		Artifacts:          make(distribution.Artifacts, len(p.Artifacts)),
+		MinCLIVersions:     p.MinCLIVersions,
+		ReleasedAt:         p.ReleasedAt,
+		Deprecated:         p.Deprecated,
+		DeprecationMessage: p.DeprecationMessage,
+		ReplacedBy:         p.ReplacedBy,
+	}
+	for version, artifacts := range p.Artifacts {
+		for _, a := range artifacts {
+			entry.Artifacts[version] = append(entry.Artifacts[version], distribution.Artifact{Image: a.Image, URI: a.URI, Digest: a.Digest, OS: a.OS, Arch: a.Arch, Size: a.Size, Signature: a.Signature})
+		}
+	}
+	if len(p.ReleaseNotes) > 0 {
+		entry.ReleaseNotes = make(map[string]PluginReleaseNotes, len(p.ReleaseNotes))
+		for version, notes := range p.ReleaseNotes {
+			entry.ReleaseNotes[version] = PluginReleaseNotes{ChangelogURL: notes.ChangelogURL, Notes: notes.Notes}
+		}
+	}
+	if len(p.Licenses) > 0 {
+		entry.Licenses = make(map[string]PluginLicense, len(p.Licenses))
+		for version, license := range p.Licenses {
+			entry.Licenses[version] = PluginLicense{License: license.License, SBOMURI: license.SBOMURI}
+		}
+	}
+	return entry
+}
+
+// pluginGroupFromHTTP converts a plugin-group served by the API of an HTTP inventory to a plugin-group
+func pluginGroupFromHTTP(g *HTTPInventoryPluginGroup) *PluginGroup {
+	pg := &PluginGroup{
+		Vendor:      g.Vendor,
+		Publisher:   g.Publisher,
+		Name:        g.Name,
+		Description: g.Description,
+		Hidden:      g.Hidden,
+		Versions:    make(map[string][]*PluginGroupPluginEntry, len(g.Versions)),
+	}
+	for version, plugins := range g.Versions {
+		for _, p := range plugins {
+			pg.Versions[version] = append(pg.Versions[version], &PluginGroupPluginEntry{
+				PluginIdentifier: PluginIdentifier{
+					Name:    p.Name,
+					Target:  configtypes.StringToTarget(strings.ToLower(string(p.Target))),
+					Version: p.Version,
+				},
+				Mandatory: p.Mandatory,
+			})
+		}
+	}
+	return pg
+}
+
+// pluginToHTTP converts an inventory entry to a plugin as served by the API of an HTTP inventory
+func pluginToHTTP(p *PluginInventoryEntry) HTTPInventoryPlugin {
+	plugin := HTTPInventoryPlugin{
+		Name:               p.Name,
+		Target:             p.Target,
+		Description:        p.Description,
+		Publisher:          p.Publisher,
+		Vendor:             p.Vendor,
+		RecommendedVersion: p.RecommendedVersion,
+		Hidden:             p.Hidden,
+		Artifacts:          make(map[string][]HTTPInventoryArtifact, len(p.Artifacts)),
+		MinCLIVersions:     p.MinCLIVersions,
+		ReleasedAt:         p.ReleasedAt,
+		Deprecated:         p.Deprecated,
+		DeprecationMessage: p.DeprecationMessage,
+		ReplacedBy:         p.ReplacedBy,
+	}
+	for version, artifacts := range p.Artifacts {
+		for _, a := range artifacts {
+			plugin.Artifacts[version] = append(plugin.Artifacts[version], HTTPInventoryArtifact{Image: a.Image, URI: a.URI, Digest: a.Digest, OS: a.OS, Arch: a.Arch, Size: a.Size, Signature: a.Signature})
+		}
+	}
+	for version, notes := range p.ReleaseNotes {
+		if plugin.ReleaseNotes == nil {
+			plugin.ReleaseNotes = map[string]HTTPInventoryReleaseNotes{}
+		}
+		plugin.ReleaseNotes[version] = HTTPInventoryReleaseNotes{ChangelogURL: notes.ChangelogURL, Notes: notes.Notes}
+	}
+	for version, license := range p.Licenses {
+		if plugin.Licenses == nil {
+			plugin.Licenses = map[string]HTTPInventoryLicense{}
+		}
+		plugin.Licenses[version] = HTTPInventoryLicense{License: license.License, SBOMURI: license.SBOMURI}
+	}
+	return plugin
+}
+
+// pluginGroupToHTTP converts a plugin-group to a plugin-group as served by the API of an HTTP inventory
+func pluginGroupToHTTP(pg *PluginGroup) HTTPInventoryPluginGroup {
+	group := HTTPInventoryPluginGroup{
+		Vendor:      pg.Vendor,
+		Publisher:   pg.Publisher,
+		Name:        pg.Name,
+		Description: pg.Description,
+		Hidden:      pg.Hidden,
+		Versions:    map[string][]HTTPInventoryPluginGroupEntry{},
+	}
+	for version, plugins := range pg.Versions {
+		for _, p := range plugins {
+			group.Versions[version] = append(group.Versions[version], HTTPInventoryPluginGroupEntry{Name: p.Name, Target: p.Target, Version: p.Version, Mandatory: p.Mandatory})
+		}
+	}
+	return group
+}
+
+// CreateSchema returns an error as the inventory is read-only
+func (h *HTTPInventory) CreateSchema(_ context.Context) error {
+	return h.readOnlyError()
+}
+
+// MigrateSchema does nothing as the schema of the inventory is managed by its API
+func (h *HTTPInventory) MigrateSchema(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// InsertPlugin returns an error as the inventory is read-only
+func (h *HTTPInventory) InsertPlugin(_ context.Context, _ *PluginInventoryEntry) error {
+	return h.readOnlyError()
+}
+
+// InsertPlugins returns an error as the inventory is read-only
+func (h *HTTPInventory) InsertPlugins(_ context.Context, _ []*PluginInventoryEntry) error {
+	return h.readOnlyError()
+}
+
+// InsertPluginGroup returns an error as the inventory is read-only
+func (h *HTTPInventory) InsertPluginGroup(_ context.Context, _ *PluginGroup, _ bool) error {
+	return h.readOnlyError()
+}
+
+// UpdatePluginActivationState returns an error as the inventory is read-only
+func (h *HTTPInventory) UpdatePluginActivationState(_ context.Context, _ *PluginInventoryEntry) error {
+	return h.readOnlyError()
+}
+
+// UpdatePluginGroupActivationState returns an error as the inventory is read-only
+func (h *HTTPInventory) UpdatePluginGroupActivationState(_ context.Context, _ *PluginGroup) error {
+	return h.readOnlyError()
+}
+
+// DeletePluginVersion returns an error as the inventory is read-only
+func (h *HTTPInventory) DeletePluginVersion(_ context.Context, _ string, _ configtypes.Target, _ string) error {
+	return h.readOnlyError()
+}
+
+// DeletePlugin returns an error as the inventory is read-only
+func (h *HTTPInventory) DeletePlugin(_ context.Context, _ string, _ configtypes.Target) error {
+	return h.readOnlyError()
+}
+
+// Export writes all the plugins and plugin-groups of the inventory to the writer in the provided format.
+func (h *HTTPInventory) Export(ctx context.Context, w io.Writer, format ExportFormat) error {
+	return exportInventory(ctx, h, w, format)
+}
+
+// Validate returns all the problems found in the plugins and plugin-groups of the inventory.
+func (h *HTTPInventory) Validate(ctx context.Context) error {
+	return validateInventory(ctx, h)
+}
+
+func (h *HTTPInventory) readOnlyError() error {
+	return errors.Errorf("the plugin inventory at '%s' is read-only", h.inventoryURL)
+}
@@ -0,0 +1,155 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugininventory
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Unit tests for the HTTP plugin inventory", func() {
+	var (
+		server          *httptest.Server
+		queries         []url.Values
+		statusCode      int
+		httpInventory   PluginInventory
+		memoryInventory *InMemoryInventory
+	)
+
+	BeforeEach(func() {
+		queries = nil
+		statusCode = http.StatusOK
+
+		memoryInventory = NewInMemoryInventory(pluginsSeed.Plugins).(*InMemoryInventory)
+		memoryInventory.seedPluginGroups(groupsSeed.Groups)
+
+		var plugins HTTPInventoryPluginsResponse
+		for _, p := range pluginsSeed.Plugins {
+			plugins.Plugins = append(plugins.Plugins, pluginToHTTP(p))
+		}
+		var groups HTTPInventoryPluginGroupsResponse
+		for _, pg := range groupsSeed.Groups {
+			groups.PluginGroups = append(groups.PluginGroups, pluginGroupToHTTP(pg))
+		}
+
+		mux := http.NewServeMux()
+		serve := func(response interface{}) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				queries = append(queries, r.URL.Query())
+				w.WriteHeader(statusCode)
+				_ = json.NewEncoder(w).Encode(response)
+			}
+		}
+		mux.HandleFunc("/inventory/"+HTTPInventoryPluginsPath, serve(plugins))
+		mux.HandleFunc("/inventory/"+HTTPInventoryPluginGroupsPath, serve(groups))
+		server = httptest.NewServer(mux)
+
+		httpInventory = NewHTTPInventory(server.URL+"/inventory/", server.Client())
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+
+	DescribeTable("should get the plugins served by the API",
+		func(filter PluginInventoryFilter) {
+			memoryFilter, httpFilter := filter, filter
+			expected, err := memoryInventory.GetPlugins(context.Background(), &memoryFilter)
+			Expect(err).ToNot(HaveOccurred())
+
+			plugins, err := httpInventory.GetPlugins(context.Background(), &httpFilter)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plugins).To(Equal(expected))
+		},
+		Entry("with an empty filter", PluginInventoryFilter{}),
+		Entry("including the hidden plugins", PluginInventoryFilter{IncludeHidden: true}),
+		Entry("with a name pattern", PluginInventoryFilter{Name: "*-cluster"}),
+		Entry("with a target", PluginInventoryFilter{Target: types.TargetGlobal, IncludeHidden: true}),
+		Entry("with the latest version", PluginInventoryFilter{Name: "management-cluster", Version: cli.VersionLatest}),
+		Entry("with keywords", PluginInventoryFilter{SearchText: "cluster"}),
+	)
+
+	It("should pass the filter to the API as query parameters", func() {
+		_, err := httpInventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "management-cluster", Target: types.TargetK8s, OS: "linux", Arch: "amd64", Vendor: "vmware", Publisher: "tkg", IncludeHidden: true})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(queries).To(HaveLen(1))
+		Expect(queries[0]).To(Equal(url.Values{
+			"name":          {"management-cluster"},
+			"target":        {"kubernetes"},
+			"os":            {"linux"},
+			"arch":          {"amd64"},
+			"vendor":        {"vmware"},
+			"publisher":     {"tkg"},
+			"includeHidden": {"true"},
+		}))
+
+		// A name pattern cannot be used by the API
+		_, err = httpInventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "management-*"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(queries[1]).To(BeEmpty())
+	})
+
+	It("should walk the plugins and get them by page", func() {
+		var names []string
+		err := httpInventory.WalkPlugins(context.Background(), &PluginInventoryFilter{IncludeHidden: true}, func(p *PluginInventoryEntry) error {
+			names = append(names, PluginToID(p))
+			return nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(names).To(Equal([]string{"hidden-plugin@global", "isolated-cluster@global", "management-cluster@kubernetes"}))
+
+		page, err := httpInventory.GetPluginsPage(context.Background(), &PluginInventoryFilter{IncludeHidden: true}, 2, 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(page).To(HaveLen(1))
+		Expect(PluginToID(page[0])).To(Equal("management-cluster@kubernetes"))
+	})
+
+	DescribeTable("should get the plugin-groups served by the API",
+		func(filter PluginGroupFilter) {
+			expected, err := memoryInventory.GetPluginGroups(context.Background(), filter)
+			Expect(err).ToNot(HaveOccurred())
+
+			groups, err := httpInventory.GetPluginGroups(context.Background(), filter)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(groups).To(Equal(expected))
+		},
+		Entry("with an empty filter", PluginGroupFilter{}),
+		Entry("including the hidden plugin-groups", PluginGroupFilter{IncludeHidden: true}),
+		Entry("with the latest version", PluginGroupFilter{Vendor: "vmware", Publisher: "tkg", Name: "default", Version: cli.VersionLatest}),
+	)
+
+	It("should return an error if the API fails", func() {
+		statusCode = http.StatusInternalServerError
+		_, err := httpInventory.GetAllPlugins(context.Background())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("status code: 500"))
+		_, err = httpInventory.GetPluginGroups(context.Background(), PluginGroupFilter{})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return the error of a cancelled context", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := httpInventory.GetAllPlugins(ctx)
+		Expect(err).To(MatchError(context.Canceled))
+	})
+
+	It("should not allow modifying the inventory", func() {
+		err := httpInventory.InsertPlugin(context.Background(), &piEntry1)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("is read-only"))
+		err = httpInventory.DeletePlugin(context.Background(), piEntry1.Name, piEntry1.Target)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("is read-only"))
+	})
+})
@@ -0,0 +1,644 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugininventory
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+)
+
+// InMemoryInventory is an inventory stored in memory, which behaves like an SQLite inventory
+// without requiring a DB file. It is meant for tests and for the programs embedding the CLI.
+type InMemoryInventory struct {
+	lock sync.RWMutex
+	// pluginRows are the rows of the plugins, as they would be stored in the PluginBinaries table
+	pluginRows []*pluginDBRow
+	// groupRows are the rows of the plugin-groups, as they would be stored in the PluginGroups table
+	groupRows []*groupDBRow
+	// releaseNotes are the release notes of the plugin versions, by pluginVersionKey
+	releaseNotes map[string]PluginReleaseNotes
+	// minCLIVersions are the minimum CLI versions required by the plugin versions, by pluginVersionKey
+	minCLIVersions map[string]string
+	// licenses are the licenses of the plugin versions, by pluginVersionKey
+	licenses map[string]PluginLicense
+}
+
+// NewInMemoryInventory returns a new PluginInventory stored in memory and containing the plugins,
+// which keep their recommended version as when seeding an inventory DB with CreateInventoryDB().
+// It panics if the plugins cannot be inserted, e.g. if the same artifact is provided twice.
+func NewInMemoryInventory(plugins []*PluginInventoryEntry) PluginInventory {
+	inventory := newInMemoryInventory()
+	if err := inventory.insertPlugins(context.Background(), plugins, true); err != nil {
+		panic(err)
+	}
+	return inventory
+}
+
+// newInMemoryInventory returns a new empty InMemoryInventory
+func newInMemoryInventory() *InMemoryInventory {
+	return &InMemoryInventory{
+		releaseNotes:   make(map[string]PluginReleaseNotes),
+		minCLIVersions: make(map[string]string),
+		licenses:       make(map[string]PluginLicense),
+	}
+}
+
+// seedPluginGroups adds the plugin-groups to the inventory without verifying that their plugins
+// exist in the inventory, as when seeding an inventory DB with CreateInventoryDB()
+func (m *InMemoryInventory) seedPluginGroups(groups []*PluginGroup) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, pg := range groups {
+		for version, plugins := range pg.Versions {
+			for _, pi := range plugins {
+				m.groupRows = append(m.groupRows, &groupDBRow{
+					vendor:        pg.Vendor,
+					publisher:     pg.Publisher,
+					groupName:     pg.Name,
+					groupVersion:  version,
+					description:   pg.Description,
+					pluginName:    pi.Name,
+					target:        string(pi.Target),
+					pluginVersion: pi.Version,
+					mandatory:     strconv.FormatBool(pi.Mandatory),
+					hidden:        strconv.FormatBool(pg.Hidden),
+				})
+			}
+		}
+	}
+}
+
+// pluginVersionKey returns the key of the details of a plugin version
+func pluginVersionKey(name, target, version string) string {
+	return fmt.Sprintf("%s@%s:%s", name, target, version)
+}
+
+// GetAllPlugins returns all plugins found in the inventory.
+func (m *InMemoryInventory) GetAllPlugins(ctx context.Context) ([]*PluginInventoryEntry, error) {
+	return m.GetPlugins(ctx, &PluginInventoryFilter{})
+}
+
+// GetPlugins returns the plugins found in the inventory that match the provided filter.
+func (m *InMemoryInventory) GetPlugins(ctx context.Context, filter *PluginInventoryFilter) ([]*PluginInventoryEntry, error) {
+	if filter == nil {
+		filter = &PluginInventoryFilter{}
+	}
+	return getPlugins(ctx, filter, m.readPlugins)
+}
+
+// WalkPlugins calls fn for each plugin found in the inventory that matches the provided filter.
+func (m *InMemoryInventory) WalkPlugins(ctx context.Context, filter *PluginInventoryFilter, fn func(*PluginInventoryEntry) error) error {
+	plugins, err := m.getPluginsByNameAndTarget(ctx, filter)
+	if err != nil {
+		return err
+	}
+	for _, p := range plugins {
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPluginsPage returns at most 'limit' plugins matching the provided filter, after skipping the first
+// 'offset' of them in the order of their name and target.
+func (m *InMemoryInventory) GetPluginsPage(ctx context.Context, filter *PluginInventoryFilter, offset, limit int) ([]*PluginInventoryEntry, error) {
+	if offset < 0 || limit <= 0 {
+		return nil, fmt.Errorf("invalid page of plugins with offset %d and limit %d", offset, limit)
+	}
+	plugins, err := m.getPluginsByNameAndTarget(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if offset >= len(plugins) {
+		return []*PluginInventoryEntry{}, nil
+	}
+	return plugins[offset:min(offset+limit, len(plugins))], nil
+}
+
+// getPluginsByNameAndTarget returns the plugins matching the filter in the order of their name
+// and target, ignoring the sort requested by the filter
+func (m *InMemoryInventory) getPluginsByNameAndTarget(ctx context.Context, filter *PluginInventoryFilter) ([]*PluginInventoryEntry, error) {
+	unsortedFilter := PluginInventoryFilter{}
+	if filter != nil {
+		unsortedFilter = *filter
+	}
+	unsortedFilter.SortBy, unsortedFilter.SortOrder = "", ""
+	return m.GetPlugins(ctx, &unsortedFilter)
+}
+
+// readPlugins returns the plugins matching the filter in the order of their name and target
+func (m *InMemoryInventory) readPlugins(ctx context.Context, filter *PluginInventoryFilter) ([]*PluginInventoryEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	match, err := pluginRowMatcher(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	var rows []*pluginDBRow
+	for _, row := range m.pluginRows {
+		if match(row) {
+			rows = append(rows, row)
+		}
+	}
+	// Same order as pluginOrderClause
+	sort.Slice(rows, func(i, j int) bool {
+		ri := []string{rows[i].name, rows[i].target, rows[i].version, rows[i].os, rows[i].arch}
+		rj := []string{rows[j].name, rows[j].target, rows[j].version, rows[j].os, rows[j].arch}
+		return lessStrings(ri, rj)
+	})
+
+	plugins := make([]*PluginInventoryEntry, 0)
+	nextRow := func() (*pluginDBRow, error) {
+		if len(rows) == 0 {
+			return nil, nil
+		}
+		row := rows[0]
+		rows = rows[1:]
+		return row, nil
+	}
+	// The full image URIs are stored
+	imageURI := func(uri string) string { return uri }
+	err = walkPluginDBRows(nextRow, imageURI, func(p *PluginInventoryEntry) error {
+		applyArchFallback(filter, p)
+		for version := range p.Artifacts {
+			key := pluginVersionKey(p.Name, string(p.Target), version)
+			if releaseNotes, exists := m.releaseNotes[key]; exists {
+				if p.ReleaseNotes == nil {
+					p.ReleaseNotes = make(map[string]PluginReleaseNotes)
+				}
+				p.ReleaseNotes[version] = releaseNotes
+			}
+			if minCLIVersion, exists := m.minCLIVersions[key]; exists {
+				if p.MinCLIVersions == nil {
+					p.MinCLIVersions = make(map[string]string)
+				}
+				p.MinCLIVersions[version] = minCLIVersion
+			}
+			if license, exists := m.licenses[key]; exists {
+				if p.Licenses == nil {
+					p.Licenses = make(map[string]PluginLicense)
+				}
+				p.Licenses[version] = license
+			}
+		}
+		plugins = append(plugins, p)
+		return nil
+	})
+	return plugins, err
+}
+
+// pluginRowMatcher returns a function telling if a plugin row matches the filter,
+// as the conditions of the query created by createPluginQuery() would
+func pluginRowMatcher(filter *PluginInventoryFilter) (func(row *pluginDBRow) bool, error) {
+	var nameRegex *regexp.Regexp
+	if filter.NameRegex != "" {
+		var err error
+		if nameRegex, err = regexp.Compile(filter.NameRegex); err != nil {
+			return nil, errors.Wrapf(err, "invalid regular expression '%s' for the plugin name", filter.NameRegex)
+		}
+	}
+	keywords := strings.Fields(strings.ToLower(filter.SearchText))
+	fallbackArch := filter.fallbackArch()
+
+	return func(row *pluginDBRow) bool {
+		if filter.Name != "" {
+			if isPluginNamePattern(filter.Name) {
+				// An invalid pattern matches no plugin, like with GLOB
+				if matched, _ := path.Match(filter.Name, row.name); !matched {
+					return false
+				}
+			} else if !strings.EqualFold(row.name, filter.Name) {
+				return false
+			}
+		}
+		if nameRegex != nil && !nameRegex.MatchString(row.name) {
+			return false
+		}
+		if filter.Target != "" && row.target != string(filter.Target) {
+			return false
+		}
+		if len(filter.Targets) > 0 && !containsTarget(filter.Targets, row.target) {
+			return false
+		}
+		if filter.Version == cli.VersionLatest {
+			if row.version != row.recommendedVersion {
+				return false
+			}
+		} else if filter.Version != "" && row.version != filter.Version && !strings.HasPrefix(row.version, filter.Version+".") {
+			return false
+		}
+		if !filter.IncludeHidden && row.hidden != "false" {
+			return false
+		}
+		if filter.OS != "" && row.os != filter.OS && row.os != distribution.ArtifactPlatformMultiArch {
+			return false
+		}
+		if filter.Arch != "" && row.arch != filter.Arch && row.arch != distribution.ArtifactPlatformMultiArch && row.arch != fallbackArch {
+			return false
+		}
+		if filter.Publisher != "" && row.publisher != filter.Publisher {
+			return false
+		}
+		if filter.Vendor != "" && row.vendor != filter.Vendor {
+			return false
+		}
+		for _, keyword := range keywords {
+			if !strings.Contains(strings.ToLower(row.name), keyword) && !strings.Contains(strings.ToLower(row.description), keyword) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// GetPluginGroups returns the plugin groups found in the inventory that match the provided filter.
+func (m *InMemoryInventory) GetPluginGroups(ctx context.Context, filter PluginGroupFilter) ([]*PluginGroup, error) {
+	return getPluginGroups(ctx, filter, m.readGroups)
+}
+
+// readGroups returns the plugin groups matching the filter in the order of their ID
+func (m *InMemoryInventory) readGroups(ctx context.Context, filter PluginGroupFilter) ([]*PluginGroup, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	// The group versions which include the plugin, as the subquery of createGroupQuery()
+	groupVersionKey := func(row *groupDBRow) string {
+		return strings.Join([]string{row.vendor, row.publisher, row.groupName, row.groupVersion}, "\x00")
+	}
+	var withPlugin map[string]bool
+	if filter.PluginName != "" || filter.PluginTarget != "" {
+		withPlugin = make(map[string]bool)
+		for _, row := range m.groupRows {
+			if (filter.PluginName == "" || strings.EqualFold(row.pluginName, filter.PluginName)) &&
+				(filter.PluginTarget == "" || row.target == string(filter.PluginTarget)) {
+				withPlugin[groupVersionKey(row)] = true
+			}
+		}
+	}
+
+	var rows []*groupDBRow
+	for _, row := range m.groupRows {
+		if groupRowMatches(row, filter) && (withPlugin == nil || withPlugin[groupVersionKey(row)]) {
+			rows = append(rows, row)
+		}
+	}
+	// Same order as groupOrderClause
+	sort.Slice(rows, func(i, j int) bool {
+		ri := []string{rows[i].vendor, rows[i].publisher, rows[i].groupName, rows[i].groupVersion, rows[i].pluginName, rows[i].target}
+		rj := []string{rows[j].vendor, rows[j].publisher, rows[j].groupName, rows[j].groupVersion, rows[j].pluginName, rows[j].target}
+		return lessStrings(ri, rj)
+	})
+
+	groups, err := extractGroupDBRows(func() (*groupDBRow, error) {
+		if len(rows) == 0 {
+			return nil, nil
+		}
+		row := rows[0]
+		rows = rows[1:]
+		return row, nil
+	})
+	if groups == nil {
+		groups = []*PluginGroup{}
+	}
+	return groups, err
+}
+
+// containsTarget tells if the target is one of the targets
+func containsTarget(targets []configtypes.Target, target string) bool {
+	for _, t := range targets {
+		if string(t) == target {
+			return true
+		}
+	}
+	return false
+}
+
+// lessStrings compares the values of two rows column by column
+func lessStrings(values1, values2 []string) bool {
+	for i := range values1 {
+		if values1[i] != values2[i] {
+			return values1[i] < values2[i]
+		}
+	}
+	return false
+}
+
+// groupRowMatches tells if a plugin-group row matches the filter,
+// as the conditions of the query created by createGroupQuery() would,
+// except the condition on the plugins of the group version
+func groupRowMatches(row *groupDBRow, filter PluginGroupFilter) bool {
+	return (filter.Name == "" || row.groupName == filter.Name) &&
+		(filter.NameContains == "" || strings.Contains(strings.ToLower(row.groupName), strings.ToLower(filter.NameContains))) &&
+		(filter.Version == "" || row.groupVersion == filter.Version || strings.HasPrefix(row.groupVersion, filter.Version+".")) &&
+		(filter.IncludeHidden || row.hidden == "false") &&
+		(filter.Publisher == "" || row.publisher == filter.Publisher) &&
+		(filter.Vendor == "" || row.vendor == filter.Vendor)
+}
+
+// CreateSchema does nothing as the inventory has no schema
+func (m *InMemoryInventory) CreateSchema(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// MigrateSchema does nothing as the inventory has no schema
+func (m *InMemoryInventory) MigrateSchema(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// InsertPlugin inserts plugin to the inventory
+func (m *InMemoryInventory) InsertPlugin(ctx context.Context, entry *PluginInventoryEntry) error {
+	return m.InsertPlugins(ctx, []*PluginInventoryEntry{entry})
+}
+
+// InsertPlugins inserts plugins to the inventory: either all the plugins are inserted or none of them are
+func (m *InMemoryInventory) InsertPlugins(ctx context.Context, entries []*PluginInventoryEntry) error {
+	return m.insertPlugins(ctx, entries, false)
+}
+
+// insertPlugins inserts plugins to the inventory, with their recommended version if requested;
+// like for an SQLite inventory, it is otherwise found among the versions when reading the plugins
+func (m *InMemoryInventory) insertPlugins(ctx context.Context, entries []*PluginInventoryEntry, withRecommendedVersion bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	// The primary key of the PluginBinaries table
+	rowKey := func(row *pluginDBRow) string {
+		return strings.Join([]string{row.name, row.target, row.version, row.os, row.arch}, "\x00")
+	}
+	existingRows := make(map[string]bool, len(m.pluginRows))
+	for _, row := range m.pluginRows {
+		existingRows[rowKey(row)] = true
+	}
+
+	var rows []*pluginDBRow
+	for _, entry := range entries {
+		for version, artifacts := range entry.Artifacts {
+			for _, a := range artifacts {
+				row := &pluginDBRow{
+					name:               entry.Name,
+					target:             string(entry.Target),
+					recommendedVersion: "",
+					version:            version,
+					hidden:             strconv.FormatBool(entry.Hidden),
+					description:        entry.Description,
+					publisher:          entry.Publisher,
+					vendor:             entry.Vendor,
+					os:                 a.OS,
+					arch:               a.Arch,
+					digest:             a.Digest,
+					uri:                a.Image,
+					deprecated:         strconv.FormatBool(entry.Deprecated),
+					deprecationMessage: entry.DeprecationMessage,
+					replacedBy:         entry.ReplacedBy,
+					size:               a.Size,
+					signature:          a.Signature,
+					releasedAt:         formatReleaseTime(entry.ReleasedAt[version]),
+				}
+				if row.uri == "" {
+					row.uri = a.URI
+				}
+				if withRecommendedVersion {
+					row.recommendedVersion = entry.RecommendedVersion
+				}
+				if existingRows[rowKey(row)] {
+					return errors.Errorf("unable to insert plugin row %v: the artifact already exists", *row)
+				}
+				existingRows[rowKey(row)] = true
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	m.pluginRows = append(m.pluginRows, rows...)
+	for _, entry := range entries {
+		for version, releaseNotes := range entry.ReleaseNotes {
+			m.releaseNotes[pluginVersionKey(entry.Name, string(entry.Target), version)] = releaseNotes
+		}
+		for version, minCLIVersion := range entry.MinCLIVersions {
+			m.minCLIVersions[pluginVersionKey(entry.Name, string(entry.Target), version)] = minCLIVersion
+		}
+		for version, license := range entry.Licenses {
+			m.licenses[pluginVersionKey(entry.Name, string(entry.Target), version)] = license
+		}
+	}
+	return nil
+}
+
+// InsertPluginGroup inserts plugin-group to the inventory
+// specifying override will delete the existing versions of the plugin-group and add the new ones
+func (m *InMemoryInventory) InsertPluginGroup(ctx context.Context, pg *PluginGroup, override bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	rows, err := pluginGroupDBRows(ctx, m, pg)
+	if err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	// The primary key of the PluginGroups table
+	rowKey := func(row *groupDBRow) string {
+		return strings.Join([]string{row.vendor, row.publisher, row.groupName, row.groupVersion, row.pluginName, row.target}, "\x00")
+	}
+	remainingRows := make([]*groupDBRow, 0, len(m.groupRows))
+	existingRows := make(map[string]bool, len(m.groupRows))
+	for _, row := range m.groupRows {
+		_, replaced := pg.Versions[row.groupVersion]
+		if override && replaced && row.vendor == pg.Vendor && row.publisher == pg.Publisher && row.groupName == pg.Name {
+			continue
+		}
+		remainingRows = append(remainingRows, row)
+		existingRows[rowKey(row)] = true
+	}
+	for i := range rows {
+		if existingRows[rowKey(&rows[i])] {
+			return errors.Errorf("unable to insert plugin-group row %v: the row already exists", rows[i])
+		}
+		existingRows[rowKey(&rows[i])] = true
+		remainingRows = append(remainingRows, &rows[i])
+	}
+	m.groupRows = remainingRows
+	return nil
+}
+
+// UpdatePluginActivationState updates plugin metadata to activate or deactivate plugin
+func (m *InMemoryInventory) UpdatePluginActivationState(ctx context.Context, entry *PluginInventoryEntry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for version := range entry.Artifacts {
+		updated := false
+		for _, row := range m.pluginRows {
+			if row.name == entry.Name && row.target == string(entry.Target) && row.version == version && row.publisher == entry.Publisher && row.vendor == entry.Vendor {
+				row.hidden = strconv.FormatBool(entry.Hidden)
+				updated = true
+			}
+		}
+		if !updated {
+			return errors.Errorf("unable to update plugin %v_%v", entry.Name, string(entry.Target))
+		}
+	}
+	return nil
+}
+
+// UpdatePluginGroupActivationState updates plugin-group metadata to activate or deactivate the plugin-group
+func (m *InMemoryInventory) UpdatePluginGroupActivationState(ctx context.Context, pg *PluginGroup) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for version := range pg.Versions {
+		updated := false
+		for _, row := range m.groupRows {
+			if row.groupName == pg.Name && row.publisher == pg.Publisher && row.vendor == pg.Vendor && row.groupVersion == version {
+				row.hidden = strconv.FormatBool(pg.Hidden)
+				updated = true
+			}
+		}
+		if !updated {
+			return errors.Errorf("unable to update plugin-group '%s:%s'. This might be possible because the provided plugin-group version doesn't exists", PluginGroupToID(pg), version)
+		}
+	}
+	return nil
+}
+
+// DeletePluginVersion deletes a version of a plugin from the inventory, along with its release notes
+// and minimum CLI version
+func (m *InMemoryInventory) DeletePluginVersion(ctx context.Context, name string, target configtypes.Target, version string) error {
+	if version == "" {
+		return errors.Errorf("no version specified to delete for plugin '%s'", PluginToID(&PluginInventoryEntry{Name: name, Target: target}))
+	}
+	return m.deletePlugin(ctx, name, target, version)
+}
+
+// DeletePlugin deletes all the versions of a plugin from the inventory, along with their release notes
+// and minimum CLI versions
+func (m *InMemoryInventory) DeletePlugin(ctx context.Context, name string, target configtypes.Target) error {
+	return m.deletePlugin(ctx, name, target, "")
+}
+
+// Export writes all the plugins and plugin-groups of the inventory to the writer in the provided format.
+func (m *InMemoryInventory) Export(ctx context.Context, w io.Writer, format ExportFormat) error {
+	return exportInventory(ctx, m, w, format)
+}
+
+// Validate returns all the problems found in the plugins and plugin-groups of the inventory.
+func (m *InMemoryInventory) Validate(ctx context.Context) error {
+	return validateInventory(ctx, m)
+}
+
+// deletePlugin deletes the version of the plugin, or all its versions if the version is empty.
+// The plugins which are part of a plugin-group are not deleted so that the plugin-groups remain valid.
+func (m *InMemoryInventory) deletePlugin(ctx context.Context, name string, target configtypes.Target, version string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	pluginID := PluginToID(&PluginInventoryEntry{Name: name, Target: target})
+	if version != "" {
+		pluginID = fmt.Sprintf("%s:%s", pluginID, version)
+	}
+	matches := func(rowName, rowTarget, rowVersion string) bool {
+		return rowName == name && rowTarget == string(target) && (version == "" || rowVersion == version)
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	groupIDs := make(map[string]bool)
+	for _, row := range m.groupRows {
+		if matches(row.pluginName, row.target, row.pluginVersion) {
+			groupIDs[fmt.Sprintf("%s:%s", PluginGroupToID(&PluginGroup{Vendor: row.vendor, Publisher: row.publisher, Name: row.groupName}), row.groupVersion)] = true
+		}
+	}
+	if len(groupIDs) > 0 {
+		groups := make([]string, 0, len(groupIDs))
+		for id := range groupIDs {
+			groups = append(groups, id)
+		}
+		sort.Strings(groups)
+		return errors.Errorf("unable to delete plugin '%s' because it is part of the plugin-groups %v, remove it from the plugin-groups first", pluginID, groups)
+	}
+
+	remainingRows := make([]*pluginDBRow, 0, len(m.pluginRows))
+	for _, row := range m.pluginRows {
+		if !matches(row.name, row.target, row.version) {
+			remainingRows = append(remainingRows, row)
+		}
+	}
+	if len(remainingRows) == len(m.pluginRows) {
+		return errors.Errorf("unable to delete plugin '%s' because it is not found in the inventory", pluginID)
+	}
+	m.pluginRows = remainingRows
+
+	for key := range m.releaseNotes {
+		if pluginVersionKeyMatches(key, name, string(target), version) {
+			delete(m.releaseNotes, key)
+		}
+	}
+	for key := range m.minCLIVersions {
+		if pluginVersionKeyMatches(key, name, string(target), version) {
+			delete(m.minCLIVersions, key)
+		}
+	}
+	for key := range m.licenses {
+		if pluginVersionKeyMatches(key, name, string(target), version) {
+			delete(m.licenses, key)
+		}
+	}
+	if version != "" {
+		// The recommended version is found again among the remaining versions if it was deleted
+		for _, row := range m.pluginRows {
+			if row.name == name && row.target == string(target) && row.recommendedVersion == version {
+				row.recommendedVersion = ""
+			}
+		}
+	}
+	return nil
+}
+
+// pluginVersionKeyMatches tells if the key of the details of a plugin version is the one of the version
+// of the plugin, or of any of its versions if the version is empty
+func pluginVersionKeyMatches(key, name, target, version string) bool {
+	if version != "" {
+		return key == pluginVersionKey(name, target, version)
+	}
+	return strings.HasPrefix(key, pluginVersionKey(name, target, ""))
+}
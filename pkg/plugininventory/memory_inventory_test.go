@@ -0,0 +1,185 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugininventory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// withoutImagePrefix removes the prefix that an SQLite inventory without URI prefix
+// adds to the images of the plugins
+func withoutImagePrefix(plugins []*PluginInventoryEntry) []*PluginInventoryEntry {
+	for _, p := range plugins {
+		for _, artifacts := range p.Artifacts {
+			for i := range artifacts {
+				artifacts[i].Image = strings.TrimPrefix(artifacts[i].Image, "/")
+			}
+		}
+	}
+	return plugins
+}
+
+var _ = Describe("Unit tests for the in-memory plugin inventory", func() {
+	var (
+		tmpDir          string
+		sqliteInventory PluginInventory
+		memoryInventory PluginInventory
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp(os.TempDir(), "")
+		Expect(err).To(BeNil(), "unable to create temporary directory")
+
+		dbFile := filepath.Join(tmpDir, SQliteDBFileName)
+		err = CreateInventoryDB(dbFile, pluginsSeed)
+		Expect(err).To(BeNil(), "failed to seed the DB for testing")
+		sqliteInventory = NewSQLiteInventory(dbFile, "")
+		memoryInventory = NewInMemoryInventory(pluginsSeed.Plugins)
+	})
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	DescribeTable("should get the same plugins as an SQLite inventory",
+		func(filter PluginInventoryFilter) {
+			sqliteFilter, memoryFilter := filter, filter
+			expected, err := sqliteInventory.GetPlugins(context.Background(), &sqliteFilter)
+			Expect(err).ToNot(HaveOccurred())
+
+			plugins, err := memoryInventory.GetPlugins(context.Background(), &memoryFilter)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plugins).To(Equal(withoutImagePrefix(expected)))
+		},
+		Entry("with an empty filter", PluginInventoryFilter{}),
+		Entry("including the hidden plugins", PluginInventoryFilter{IncludeHidden: true}),
+		Entry("with a name", PluginInventoryFilter{Name: "management-cluster"}),
+		Entry("with a name of another case", PluginInventoryFilter{Name: "Management-Cluster"}),
+		Entry("with a name pattern", PluginInventoryFilter{Name: "*-cluster", IncludeHidden: true}),
+		Entry("with a name regular expression", PluginInventoryFilter{NameRegex: "^(hidden|isolated)-", IncludeHidden: true}),
+		Entry("with a target", PluginInventoryFilter{Target: types.TargetGlobal, IncludeHidden: true}),
+		Entry("with several targets", PluginInventoryFilter{Targets: []types.Target{types.TargetK8s, types.TargetGlobal}, IncludeHidden: true}),
+		Entry("with a version", PluginInventoryFilter{Version: "v0.28.0"}),
+		Entry("with a major.minor version", PluginInventoryFilter{Version: "v1.2"}),
+		Entry("with the latest version", PluginInventoryFilter{Name: "isolated-cluster", Version: cli.VersionLatest}),
+		Entry("with an OS and architecture", PluginInventoryFilter{OS: "windows", Arch: "amd64"}),
+		Entry("with an architecture fallback", PluginInventoryFilter{OS: "darwin", Arch: "arm64", ArchFallback: ArchFallbackEmulation}),
+		Entry("with a publisher and vendor", PluginInventoryFilter{Publisher: "otherpublisher", Vendor: "othervendor", IncludeHidden: true}),
+		Entry("with keywords", PluginInventoryFilter{SearchText: "CLUSTER hidden", IncludeHidden: true}),
+		Entry("sorted by version", PluginInventoryFilter{SortBy: PluginSortByMostRecentVersion, SortOrder: SortOrderDescending, IncludeHidden: true}),
+	)
+
+	It("should get the plugins by page and walk them in the order of their name and target", func() {
+		filter := &PluginInventoryFilter{IncludeHidden: true, SortBy: PluginSortByVendor}
+		page, err := memoryInventory.GetPluginsPage(context.Background(), filter, 1, 2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(page).To(HaveLen(2))
+		Expect(page[0].Name).To(Equal("isolated-cluster"))
+		Expect(page[1].Name).To(Equal("management-cluster"))
+
+		var names []string
+		err = memoryInventory.WalkPlugins(context.Background(), filter, func(p *PluginInventoryEntry) error {
+			names = append(names, p.Name)
+			return nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(names).To(Equal([]string{"hidden-plugin", "isolated-cluster", "management-cluster"}))
+	})
+
+	It("should return an error for an invalid name regular expression", func() {
+		_, err := memoryInventory.GetPlugins(context.Background(), &PluginInventoryFilter{NameRegex: "management-("})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid regular expression 'management-(' for the plugin name"))
+	})
+
+	It("should insert, update and delete the plugins and the plugin-groups", func() {
+		for _, inventory := range []PluginInventory{sqliteInventory, memoryInventory} {
+			err := inventory.InsertPlugins(context.Background(), []*PluginInventoryEntry{&piEntry3})
+			Expect(err).ToNot(HaveOccurred())
+			err = inventory.InsertPlugin(context.Background(), &piEntry3)
+			Expect(err).To(HaveOccurred(), "the plugin is already in the inventory")
+
+			err = inventory.InsertPluginGroup(context.Background(), &pluginGroup1, false)
+			Expect(err).ToNot(HaveOccurred())
+			hiddenGroup := pluginGroup1
+			hiddenGroup.Hidden = true
+			hiddenGroup.Versions = map[string][]*PluginGroupPluginEntry{"v1.0.0": nil}
+			err = inventory.UpdatePluginGroupActivationState(context.Background(), &hiddenGroup)
+			Expect(err).ToNot(HaveOccurred())
+
+			hiddenPlugin := piEntry3
+			hiddenPlugin.Hidden = true
+			err = inventory.UpdatePluginActivationState(context.Background(), &hiddenPlugin)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = inventory.DeletePlugin(context.Background(), "isolated-cluster", types.TargetGlobal)
+			Expect(err).To(MatchError(ContainSubstring("because it is part of the plugin-groups [fakevendor-fakepublisher/default:v2.0.0]")))
+			err = inventory.DeletePluginVersion(context.Background(), "management-cluster", types.TargetK8s, "v0.26.0")
+			Expect(err).ToNot(HaveOccurred())
+			err = inventory.DeletePluginVersion(context.Background(), "management-cluster", types.TargetK8s, "v0.26.0")
+			Expect(err).To(MatchError(ContainSubstring("because it is not found in the inventory")))
+		}
+
+		expectedPlugins, err := sqliteInventory.GetPlugins(context.Background(), &PluginInventoryFilter{IncludeHidden: true})
+		Expect(err).ToNot(HaveOccurred())
+		plugins, err := memoryInventory.GetPlugins(context.Background(), &PluginInventoryFilter{IncludeHidden: true})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(plugins).To(Equal(withoutImagePrefix(expectedPlugins)))
+
+		for _, filter := range []PluginGroupFilter{{}, {IncludeHidden: true}, {Name: "default", Version: cli.VersionLatest}} {
+			expectedGroups, err := sqliteInventory.GetPluginGroups(context.Background(), filter)
+			Expect(err).ToNot(HaveOccurred())
+			groups, err := memoryInventory.GetPluginGroups(context.Background(), filter)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(groups).To(ConsistOf(expectedGroups))
+		}
+	})
+
+	It("should get the same plugin-groups as an SQLite inventory", func() {
+		os.Setenv(constants.SkipPluginGroupVerificationOnPublish, "true")
+		defer os.Unsetenv(constants.SkipPluginGroupVerificationOnPublish)
+		for _, inventory := range []PluginInventory{sqliteInventory, memoryInventory} {
+			for _, pg := range groupsSeed.Groups {
+				err := inventory.InsertPluginGroup(context.Background(), pg, false)
+				Expect(err).ToNot(HaveOccurred())
+			}
+		}
+
+		for _, filter := range []PluginGroupFilter{
+			{},
+			{IncludeHidden: true},
+			{Vendor: "vmware", Publisher: "tkg", Name: "default", Version: "v1"},
+			{Name: "default", Version: cli.VersionLatest},
+			{NameContains: "DEF", IncludeHidden: true},
+			{PluginName: "Isolated-Cluster"},
+			{PluginName: "management-cluster", PluginTarget: types.TargetK8s, IncludeHidden: true},
+		} {
+			expectedGroups, err := sqliteInventory.GetPluginGroups(context.Background(), filter)
+			Expect(err).ToNot(HaveOccurred())
+			groups, err := memoryInventory.GetPluginGroups(context.Background(), filter)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(groups).To(Equal(expectedGroups))
+		}
+	})
+
+	It("should not get the plugins with a cancelled context", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := memoryInventory.GetAllPlugins(ctx)
+		Expect(err).To(MatchError(context.Canceled))
+		err = memoryInventory.InsertPlugin(ctx, &piEntry3)
+		Expect(err).To(MatchError(context.Canceled))
+	})
+})
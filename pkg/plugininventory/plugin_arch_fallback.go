@@ -0,0 +1,50 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugininventory
+
+import (
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+)
+
+// ArchFallbackPolicy tells which artifacts are returned for the plugin versions
+// without artifact for the OS and architecture of a filter
+type ArchFallbackPolicy string
+
+const (
+	// ArchFallbackNone returns no artifact for the plugin versions without artifact for the architecture
+	ArchFallbackNone ArchFallbackPolicy = ""
+	// ArchFallbackEmulation returns the amd64 artifacts of the plugin versions without arm64 artifact for
+	// darwin or windows, whose amd64 binaries can run with emulation on arm64
+	ArchFallbackEmulation ArchFallbackPolicy = "emulation"
+)
+
+// fallbackArch returns the architecture of the artifacts returned for the plugin versions without artifact
+// for the OS and architecture of the filter, or an empty string if there is none
+func (f *PluginInventoryFilter) fallbackArch() string {
+	if f == nil || f.ArchFallback != ArchFallbackEmulation || f.OS == "" || f.Arch == "" {
+		return ""
+	}
+	return cli.Arch(f.OS + "_" + f.Arch).EmulatedArch().Arch()
+}
+
+// applyArchFallback removes the artifacts of the fallback architecture of the filter from the plugin
+// versions which also have artifacts for the architecture of the filter
+func applyArchFallback(filter *PluginInventoryFilter, p *PluginInventoryEntry) {
+	fallbackArch := filter.fallbackArch()
+	if fallbackArch == "" {
+		return
+	}
+	for version, artifacts := range p.Artifacts {
+		var native distribution.ArtifactList
+		for _, a := range artifacts {
+			if a.Arch != fallbackArch {
+				native = append(native, a)
+			}
+		}
+		if len(native) > 0 {
+			p.Artifacts[version] = native
+		}
+	}
+}
@@ -8,8 +8,11 @@
 package plugininventory
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"strings"
+	"time"
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
 	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
@@ -18,33 +21,72 @@ import (
 // PluginInventory is the interface to interact with a plugin inventory.
 // It can be used to get the plugin information for plugins in the
 // inventory based on different criteria.
+// The operations are cancelled, and return the error of the context,
+// once the provided context is done.
 type PluginInventory interface {
 	// GetAllPlugins returns all plugins found in the inventory.
-	GetAllPlugins() ([]*PluginInventoryEntry, error)
+	GetAllPlugins(ctx context.Context) ([]*PluginInventoryEntry, error)
 
 	// GetPlugins returns the plugins found in the inventory that match the provided filter.
-	GetPlugins(*PluginInventoryFilter) ([]*PluginInventoryEntry, error)
+	GetPlugins(ctx context.Context, filter *PluginInventoryFilter) ([]*PluginInventoryEntry, error)
+
+	// WalkPlugins calls the function for each plugin found in the inventory that matches the
+	// provided filter, without loading all the plugins in memory at once.
+	// The walk stops at the first error returned by the function, and returns it.
+	WalkPlugins(ctx context.Context, filter *PluginInventoryFilter, fn func(*PluginInventoryEntry) error) error
+
+	// GetPluginsPage returns at most 'limit' plugins found in the inventory that match the provided
+	// filter, after skipping the first 'offset' of them. The plugins are ordered by name and target,
+	// so that consecutive pages can be requested to list all the plugins in chunks.
+	GetPluginsPage(ctx context.Context, filter *PluginInventoryFilter, offset, limit int) ([]*PluginInventoryEntry, error)
 
 	// GetPluginGroups returns the plugin groups found in the inventory that match the provided filter.
-	GetPluginGroups(PluginGroupFilter) ([]*PluginGroup, error)
+	GetPluginGroups(ctx context.Context, filter PluginGroupFilter) ([]*PluginGroup, error)
 
 	// CreateSchema creates table schemas to the provided database.
 	// returns error if table creation fails for any reason
-	CreateSchema() error
+	CreateSchema(ctx context.Context) error
+
+	// MigrateSchema applies the schema migrations not yet applied to the database,
+	// so that a database created by an older version of the CLI can be used.
+	// A database with a more recent schema version is left unchanged.
+	MigrateSchema(ctx context.Context) error
 
 	// InsertPlugin inserts plugin to the inventory
-	InsertPlugin(*PluginInventoryEntry) error
+	InsertPlugin(ctx context.Context, entry *PluginInventoryEntry) error
+
+	// InsertPlugins inserts plugins to the inventory in a single transaction:
+	// either all the plugins are inserted or none of them are
+	InsertPlugins(ctx context.Context, entries []*PluginInventoryEntry) error
 
 	// InsertPluginGroup inserts plugin-group to the inventory
 	// if override is true, it will update the existing plugin by
 	// updating the metadata and the plugin associated with the plugin-group
-	InsertPluginGroup(pg *PluginGroup, override bool) error
+	InsertPluginGroup(ctx context.Context, pg *PluginGroup, override bool) error
 
 	// UpdatePluginActivationState updates plugin metadata to activate or deactivate plugin
-	UpdatePluginActivationState(*PluginInventoryEntry) error
+	UpdatePluginActivationState(ctx context.Context, entry *PluginInventoryEntry) error
 
 	// UpdatePluginGroupActivationState updates plugin-group metadata to activate or deactivate the plugin-group
-	UpdatePluginGroupActivationState(*PluginGroup) error
+	UpdatePluginGroupActivationState(ctx context.Context, pg *PluginGroup) error
+
+	// DeletePluginVersion deletes a version of a plugin, for all its OS/architectures, from the inventory.
+	// Returns an error if the version is not found or if it is still part of a plugin-group.
+	DeletePluginVersion(ctx context.Context, name string, target configtypes.Target, version string) error
+
+	// DeletePlugin deletes all the versions of a plugin from the inventory.
+	// Returns an error if the plugin is not found or if it is still part of a plugin-group.
+	DeletePlugin(ctx context.Context, name string, target configtypes.Target) error
+
+	// Export writes all the plugins, with their versions and artifacts, and all the plugin-groups
+	// of the inventory, including the hidden ones, to the writer in the provided format.
+	Export(ctx context.Context, w io.Writer, format ExportFormat) error
+
+	// Validate checks all the plugins and plugin-groups of the inventory, including the hidden ones,
+	// e.g. before publishing it. It returns all the problems found, i.e. invalid versions, recommended
+	// versions which are not available, artifacts shared by several plugin versions or platforms, artifact URIs which cannot be reached
+	// and plugin-groups referring to plugin versions which are not in the inventory.
+	Validate(ctx context.Context) error
 }
 
 // PluginInventoryEntry represents the inventory information
@@ -69,15 +111,53 @@ type PluginInventoryEntry struct {
 	Hidden bool
 	// Artifacts contains an artifact list for every available version.
 	Artifacts distribution.Artifacts
+	// ReleaseNotes contains the release notes of the versions which have some.
+	ReleaseNotes map[string]PluginReleaseNotes
+	// MinCLIVersions contains the minimum CLI version required by the versions which declare one.
+	MinCLIVersions map[string]string
+	// Licenses contains the license and SBOM of the versions which declare some.
+	Licenses map[string]PluginLicense
+	// ReleasedAt contains the time at which the versions which record one were published.
+	ReleasedAt map[string]time.Time
+	// Deprecated tells whether the plugin is deprecated. A deprecated plugin can still
+	// be installed, but users are warned to move to its replacement.
+	Deprecated bool
+	// DeprecationMessage explains why the plugin is deprecated
+	DeprecationMessage string
+	// ReplacedBy is the name of the plugin replacing the deprecated plugin, if any
+	ReplacedBy string
+}
+
+// PluginReleaseNotes describes the changes introduced by a version of a plugin
+type PluginReleaseNotes struct {
+	// ChangelogURL is the URL of the changelog of the version
+	ChangelogURL string
+	// Notes is a short summary of the changes of the version
+	Notes string
+}
+
+// PluginLicense describes the license of a version of a plugin and where to find its
+// software bill of materials, for the audits of compliance teams
+type PluginLicense struct {
+	// License is the license of the version, e.g. an SPDX identifier such as "Apache-2.0"
+	License string
+	// SBOMURI is the URI of the software bill of materials of the version
+	SBOMURI string
 }
 
 // PluginInventoryFilter allows to specify different criteria for
 // looking up plugin entries.
 type PluginInventoryFilter struct {
-	// Name of the plugin to look for
+	// Name of the plugin to look for, which can contain the wildcards
+	// '*', '?' and '[...]' to look for a family of plugins, e.g. "management-*"
 	Name string
+	// NameRegex is a regular expression that the name of the plugins must match
+	NameRegex string
 	// Target to which the plugins apply
 	Target configtypes.Target
+	// Targets to one of which the plugins apply, e.g. all the targets relevant to a context,
+	// to get their plugins with a single query
+	Targets []configtypes.Target
 	// Version for the plugins to look for
 	Version string
 	// OS of the plugin binary in `GOOS` format.
@@ -88,8 +168,21 @@ type PluginInventoryFilter struct {
 	Publisher string
 	// Vendor of the plugins to look for
 	Vendor string
+	// SearchText contains the keywords that the name or the description
+	// of the plugins must all contain, ignoring the case
+	SearchText string
+	// SortBy is the field the plugins returned by GetPlugins are sorted by,
+	// the name by default. WalkPlugins and GetPluginsPage always go through
+	// the plugins in the order of their name and target.
+	SortBy PluginSortField
+	// SortOrder is the order the plugins returned by GetPlugins are sorted in,
+	// the ascending order by default
+	SortOrder SortOrder
 	// IncludeHidden indicates if hidden plugins should be included
 	IncludeHidden bool
+	// ArchFallback tells which artifacts to return for the plugin versions without
+	// artifact for the OS and Arch, none by default
+	ArchFallback ArchFallbackPolicy
 }
 
 // PluginIdentifier uniquely identifies a single version of a specific plugin
@@ -204,8 +297,15 @@ type PluginGroupFilter struct {
 	Publisher string
 	// Name of the group to look for
 	Name string
+	// NameContains is a case-insensitive substring of the name of the groups to look for
+	NameContains string
 	// Version of the group
 	Version string
+	// PluginName is the name of a plugin the versions of the groups must include,
+	// matched case-insensitively
+	PluginName string
+	// PluginTarget is the target of the plugin the versions of the groups must include
+	PluginTarget configtypes.Target
 	// IncludeHidden indicates if hidden plugin groups should be included
 	IncludeHidden bool
 }
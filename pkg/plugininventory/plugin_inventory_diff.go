@@ -0,0 +1,320 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugininventory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+)
+
+// PluginVersionDiff is a plugin version which differs between two inventories
+type PluginVersionDiff struct {
+	PluginIdentifier
+	// Changes describes the changes of a changed plugin version.
+	// It is empty for an added or removed plugin version.
+	Changes []string
+}
+
+// PluginGroupVersionDiff is a plugin-group version which differs between two inventories
+type PluginGroupVersionDiff struct {
+	PluginGroupIdentifier
+	// Changes describes the changes of a changed plugin-group version.
+	// It is empty for an added or removed plugin-group version.
+	Changes []string
+}
+
+// InventoryDiff is the difference between an old and a new plugin inventory
+type InventoryDiff struct {
+	AddedPlugins        []PluginVersionDiff
+	RemovedPlugins      []PluginVersionDiff
+	ChangedPlugins      []PluginVersionDiff
+	AddedPluginGroups   []PluginGroupVersionDiff
+	RemovedPluginGroups []PluginGroupVersionDiff
+	ChangedPluginGroups []PluginGroupVersionDiff
+}
+
+// IsEmpty returns true if the inventories have the same plugin versions and plugin-group versions
+func (d *InventoryDiff) IsEmpty() bool {
+	return len(d.AddedPlugins) == 0 && len(d.RemovedPlugins) == 0 && len(d.ChangedPlugins) == 0 &&
+		len(d.AddedPluginGroups) == 0 && len(d.RemovedPluginGroups) == 0 && len(d.ChangedPluginGroups) == 0
+}
+
+// pluginVersion is a version of a plugin of an inventory
+type pluginVersion struct {
+	entry     *PluginInventoryEntry
+	version   string
+	artifacts distribution.ArtifactList
+}
+
+// pluginGroupVersion is a version of a plugin-group of an inventory
+type pluginGroupVersion struct {
+	group   *PluginGroup
+	plugins []*PluginGroupPluginEntry
+}
+
+// DiffInventories returns the plugin versions and the plugin-group versions, including the hidden ones,
+// which were added, removed or changed in the new inventory compared to the old one.
+// The artifacts are compared by platform and digest, and not by image, so that the inventories
+// of a repository and of its mirror have no difference.
+func DiffInventories(ctx context.Context, oldInventory, newInventory PluginInventory) (*InventoryDiff, error) {
+	oldPlugins, err := getPluginVersions(ctx, oldInventory)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get the plugins of the old inventory")
+	}
+	newPlugins, err := getPluginVersions(ctx, newInventory)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get the plugins of the new inventory")
+	}
+	oldGroups, err := getPluginGroupVersions(ctx, oldInventory)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get the plugin-groups of the old inventory")
+	}
+	newGroups, err := getPluginGroupVersions(ctx, newInventory)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get the plugin-groups of the new inventory")
+	}
+
+	diff := &InventoryDiff{}
+	for _, id := range sortedPluginIdentifiers(oldPlugins, newPlugins) {
+		oldVersion, inOld := oldPlugins[id]
+		newVersion, inNew := newPlugins[id]
+		switch {
+		case !inOld:
+			diff.AddedPlugins = append(diff.AddedPlugins, PluginVersionDiff{PluginIdentifier: id})
+		case !inNew:
+			diff.RemovedPlugins = append(diff.RemovedPlugins, PluginVersionDiff{PluginIdentifier: id})
+		default:
+			if changes := diffPluginVersions(oldVersion, newVersion); len(changes) > 0 {
+				diff.ChangedPlugins = append(diff.ChangedPlugins, PluginVersionDiff{PluginIdentifier: id, Changes: changes})
+			}
+		}
+	}
+	for _, id := range sortedPluginGroupIdentifiers(oldGroups, newGroups) {
+		oldVersion, inOld := oldGroups[id]
+		newVersion, inNew := newGroups[id]
+		switch {
+		case !inOld:
+			diff.AddedPluginGroups = append(diff.AddedPluginGroups, PluginGroupVersionDiff{PluginGroupIdentifier: id})
+		case !inNew:
+			diff.RemovedPluginGroups = append(diff.RemovedPluginGroups, PluginGroupVersionDiff{PluginGroupIdentifier: id})
+		default:
+			if changes := diffPluginGroupVersions(oldVersion, newVersion); len(changes) > 0 {
+				diff.ChangedPluginGroups = append(diff.ChangedPluginGroups, PluginGroupVersionDiff{PluginGroupIdentifier: id, Changes: changes})
+			}
+		}
+	}
+	return diff, nil
+}
+
+// getPluginVersions returns all the plugin versions of the inventory
+func getPluginVersions(ctx context.Context, inventory PluginInventory) (map[PluginIdentifier]*pluginVersion, error) {
+	versions := make(map[PluginIdentifier]*pluginVersion)
+	err := inventory.WalkPlugins(ctx, &PluginInventoryFilter{IncludeHidden: true}, func(entry *PluginInventoryEntry) error {
+		for version, artifacts := range entry.Artifacts {
+			versions[PluginIdentifier{Name: entry.Name, Target: entry.Target, Version: version}] = &pluginVersion{entry: entry, version: version, artifacts: artifacts}
+		}
+		return nil
+	})
+	return versions, err
+}
+
+// getPluginGroupVersions returns all the plugin-group versions of the inventory
+func getPluginGroupVersions(ctx context.Context, inventory PluginInventory) (map[PluginGroupIdentifier]*pluginGroupVersion, error) {
+	groups, err := inventory.GetPluginGroups(ctx, PluginGroupFilter{IncludeHidden: true})
+	if err != nil {
+		return nil, err
+	}
+	versions := make(map[PluginGroupIdentifier]*pluginGroupVersion)
+	for _, pg := range groups {
+		for version, plugins := range pg.Versions {
+			versions[PluginGroupIdentifier{Vendor: pg.Vendor, Publisher: pg.Publisher, Name: pg.Name, Version: version}] = &pluginGroupVersion{group: pg, plugins: plugins}
+		}
+	}
+	return versions, nil
+}
+
+// diffPluginVersions describes the changes between two versions of a plugin
+func diffPluginVersions(oldVersion, newVersion *pluginVersion) []string {
+	var changes []string
+	oldEntry, newEntry := oldVersion.entry, newVersion.entry
+	changes = appendChange(changes, "description", oldEntry.Description, newEntry.Description)
+	changes = appendChange(changes, "vendor", oldEntry.Vendor, newEntry.Vendor)
+	changes = appendChange(changes, "publisher", oldEntry.Publisher, newEntry.Publisher)
+	changes = appendActivationChange(changes, oldEntry.Hidden, newEntry.Hidden)
+	changes = appendDeprecationChange(changes, oldEntry.Deprecated, newEntry.Deprecated)
+	changes = appendChange(changes, "deprecation message", oldEntry.DeprecationMessage, newEntry.DeprecationMessage)
+	changes = appendChange(changes, "replacement", oldEntry.ReplacedBy, newEntry.ReplacedBy)
+	changes = appendChange(changes, "minimum CLI version", oldEntry.MinCLIVersions[oldVersion.version], newEntry.MinCLIVersions[newVersion.version])
+	changes = appendChange(changes, "license", oldEntry.Licenses[oldVersion.version].License, newEntry.Licenses[newVersion.version].License)
+	changes = appendChange(changes, "SBOM", oldEntry.Licenses[oldVersion.version].SBOMURI, newEntry.Licenses[newVersion.version].SBOMURI)
+	changes = appendChange(changes, "release time", formatReleaseTime(oldEntry.ReleasedAt[oldVersion.version]), formatReleaseTime(newEntry.ReleasedAt[newVersion.version]))
+
+	oldArtifacts := artifactsByPlatform(oldVersion.artifacts)
+	newArtifacts := artifactsByPlatform(newVersion.artifacts)
+	for _, platform := range sortedKeys(oldArtifacts, newArtifacts) {
+		oldArtifact, inOld := oldArtifacts[platform]
+		newArtifact, inNew := newArtifacts[platform]
+		switch {
+		case !inOld:
+			changes = append(changes, fmt.Sprintf("artifact for %s added", platform))
+		case !inNew:
+			changes = append(changes, fmt.Sprintf("artifact for %s removed", platform))
+		case oldArtifact.Digest != newArtifact.Digest:
+			changes = append(changes, fmt.Sprintf("digest of the artifact for %s changed from '%s' to '%s'", platform, oldArtifact.Digest, newArtifact.Digest))
+		case oldArtifact.Signature != newArtifact.Signature:
+			changes = append(changes, fmt.Sprintf("signature of the artifact for %s changed from '%s' to '%s'", platform, oldArtifact.Signature, newArtifact.Signature))
+		}
+	}
+	return changes
+}
+
+// diffPluginGroupVersions describes the changes between two versions of a plugin-group
+func diffPluginGroupVersions(oldVersion, newVersion *pluginGroupVersion) []string {
+	var changes []string
+	changes = appendChange(changes, "description", oldVersion.group.Description, newVersion.group.Description)
+	changes = appendActivationChange(changes, oldVersion.group.Hidden, newVersion.group.Hidden)
+
+	oldPlugins := pluginsByID(oldVersion.plugins)
+	newPlugins := pluginsByID(newVersion.plugins)
+	for _, id := range sortedKeys(oldPlugins, newPlugins) {
+		oldPlugin, inOld := oldPlugins[id]
+		newPlugin, inNew := newPlugins[id]
+		switch {
+		case !inOld:
+			changes = append(changes, fmt.Sprintf("plugin %s:%s added", id, newPlugin.Version))
+		case !inNew:
+			changes = append(changes, fmt.Sprintf("plugin %s:%s removed", id, oldPlugin.Version))
+		default:
+			changes = appendChange(changes, fmt.Sprintf("version of plugin %s", id), oldPlugin.Version, newPlugin.Version)
+			switch {
+			case !oldPlugin.Mandatory && newPlugin.Mandatory:
+				changes = append(changes, fmt.Sprintf("plugin %s made mandatory", id))
+			case oldPlugin.Mandatory && !newPlugin.Mandatory:
+				changes = append(changes, fmt.Sprintf("plugin %s made optional", id))
+			}
+		}
+	}
+	return changes
+}
+
+// appendChange appends the change of the field, if any, to the changes
+func appendChange(changes []string, field, oldValue, newValue string) []string {
+	if oldValue == newValue {
+		return changes
+	}
+	return append(changes, fmt.Sprintf("%s changed from '%s' to '%s'", field, oldValue, newValue))
+}
+
+// appendActivationChange appends the change of the activation state, if any, to the changes
+func appendActivationChange(changes []string, oldHidden, newHidden bool) []string {
+	switch {
+	case !oldHidden && newHidden:
+		return append(changes, "deactivated")
+	case oldHidden && !newHidden:
+		return append(changes, "activated")
+	}
+	return changes
+}
+
+// appendDeprecationChange appends the deprecation or un-deprecation of a plugin to the changes
+func appendDeprecationChange(changes []string, oldDeprecated, newDeprecated bool) []string {
+	switch {
+	case !oldDeprecated && newDeprecated:
+		return append(changes, "deprecated")
+	case oldDeprecated && !newDeprecated:
+		return append(changes, "no longer deprecated")
+	}
+	return changes
+}
+
+// artifactsByPlatform returns the artifacts by their "os/arch" platform
+func artifactsByPlatform(artifacts distribution.ArtifactList) map[string]distribution.Artifact {
+	byPlatform := make(map[string]distribution.Artifact, len(artifacts))
+	for _, a := range artifacts {
+		byPlatform[a.OS+"/"+a.Arch] = a
+	}
+	return byPlatform
+}
+
+// pluginsByID returns the plugins of a plugin-group version by their "name@target" ID
+func pluginsByID(plugins []*PluginGroupPluginEntry) map[string]*PluginGroupPluginEntry {
+	byID := make(map[string]*PluginGroupPluginEntry, len(plugins))
+	for _, p := range plugins {
+		byID[fmt.Sprintf("%s@%s", p.Name, p.Target)] = p
+	}
+	return byID
+}
+
+// sortedKeys returns the keys of both maps in lexical order
+func sortedKeys[T any](m1, m2 map[string]T) []string {
+	keys := make([]string, 0, len(m1)+len(m2))
+	for k := range m1 {
+		keys = append(keys, k)
+	}
+	for k := range m2 {
+		if _, found := m1[k]; !found {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedPluginIdentifiers returns the plugin versions of both maps ordered by name, target and version
+func sortedPluginIdentifiers(m1, m2 map[PluginIdentifier]*pluginVersion) []PluginIdentifier {
+	ids := make([]PluginIdentifier, 0, len(m1)+len(m2))
+	for id := range m1 {
+		ids = append(ids, id)
+	}
+	for id := range m2 {
+		if _, found := m1[id]; !found {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if ids[i].Name != ids[j].Name {
+			return ids[i].Name < ids[j].Name
+		}
+		if ids[i].Target != ids[j].Target {
+			return ids[i].Target < ids[j].Target
+		}
+		return lessVersion(ids[i].Version, ids[j].Version)
+	})
+	return ids
+}
+
+// sortedPluginGroupIdentifiers returns the plugin-group versions of both maps ordered by ID and version
+func sortedPluginGroupIdentifiers(m1, m2 map[PluginGroupIdentifier]*pluginGroupVersion) []PluginGroupIdentifier {
+	ids := make([]PluginGroupIdentifier, 0, len(m1)+len(m2))
+	for id := range m1 {
+		ids = append(ids, id)
+	}
+	for id := range m2 {
+		if _, found := m1[id]; !found {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		for _, values := range [][2]string{{ids[i].Vendor, ids[j].Vendor}, {ids[i].Publisher, ids[j].Publisher}, {ids[i].Name, ids[j].Name}} {
+			if values[0] != values[1] {
+				return values[0] < values[1]
+			}
+		}
+		return lessVersion(ids[i].Version, ids[j].Version)
+	})
+	return ids
+}
+
+// lessVersion orders the versions by semver, or lexically if they are not both semver versions
+func lessVersion(v1, v2 string) bool {
+	if cmp := compareVersions(v1, v2); cmp != 0 {
+		return cmp < 0
+	}
+	return v1 < v2
+}
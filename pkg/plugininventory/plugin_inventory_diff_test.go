@@ -0,0 +1,159 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugininventory
+
+import (
+	"context"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Unit tests for the diff of plugin inventories", func() {
+	var (
+		oldInventory *InMemoryInventory
+		newInventory *InMemoryInventory
+	)
+
+	BeforeEach(func() {
+		oldInventory = NewInMemoryInventory([]*PluginInventoryEntry{&piEntry1, &piEntry2}).(*InMemoryInventory)
+		oldInventory.seedPluginGroups([]*PluginGroup{&pluginGroup1})
+
+		changedEntry1 := PluginInventoryEntry{
+			Name:        piEntry1.Name,
+			Target:      piEntry1.Target,
+			Description: "Updated description",
+			Publisher:   piEntry1.Publisher,
+			Vendor:      piEntry1.Vendor,
+			Artifacts: distribution.Artifacts{
+				"v0.28.0": []distribution.Artifact{
+					// Mirrored images are compared by digest
+					{OS: "linux", Arch: "amd64", Digest: "9999999999", Image: "mirror/linux/amd64/k8s/management-cluster:v0.28.0"},
+					{OS: "darwin", Arch: "amd64", Digest: "1111111111", Image: "mirror/darwin/amd64/k8s/management-cluster:v0.28.0"},
+					{OS: "darwin", Arch: "arm64", Digest: "4444444444", Image: "mirror/darwin/arm64/k8s/management-cluster:v0.28.0"},
+				},
+				"v0.29.0": []distribution.Artifact{
+					{OS: "linux", Arch: "amd64", Digest: "5555555555", Image: "mirror/linux/amd64/k8s/management-cluster:v0.29.0"},
+				},
+			},
+		}
+		changedGroup1 := PluginGroup{
+			Name:        pluginGroup1.Name,
+			Vendor:      pluginGroup1.Vendor,
+			Publisher:   pluginGroup1.Publisher,
+			Description: pluginGroup1.Description,
+			Versions: map[string][]*PluginGroupPluginEntry{
+				"v1.0.0": pluginGroup1.Versions["v1.0.0"],
+				"v2.0.0": {
+					{PluginIdentifier: PluginIdentifier{Name: "management-cluster", Target: types.TargetK8s, Version: "v0.29.0"}},
+					{PluginIdentifier: PluginIdentifier{Name: "management-cluster", Target: types.TargetTMC, Version: "v0.0.1"}, Mandatory: true},
+				},
+				"v3.0.0": {
+					{PluginIdentifier: PluginIdentifier{Name: "management-cluster", Target: types.TargetK8s, Version: "v0.29.0"}, Mandatory: true},
+				},
+			},
+		}
+		newInventory = NewInMemoryInventory([]*PluginInventoryEntry{&changedEntry1, &piEntry3}).(*InMemoryInventory)
+		newInventory.seedPluginGroups([]*PluginGroup{&changedGroup1})
+	})
+
+	It("should return the plugin versions and plugin-group versions which changed", func() {
+		diff, err := DiffInventories(context.Background(), oldInventory, newInventory)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff.IsEmpty()).To(BeFalse())
+
+		Expect(diff.AddedPlugins).To(Equal([]PluginVersionDiff{
+			{PluginIdentifier: PluginIdentifier{Name: "management-cluster", Target: types.TargetK8s, Version: "v0.29.0"}},
+			{PluginIdentifier: PluginIdentifier{Name: "management-cluster", Target: types.TargetTMC, Version: "v0.0.1"}},
+		}))
+		Expect(diff.RemovedPlugins).To(Equal([]PluginVersionDiff{
+			{PluginIdentifier: PluginIdentifier{Name: "isolated-cluster", Target: types.TargetGlobal, Version: "v1.2.3"}},
+		}))
+		Expect(diff.ChangedPlugins).To(Equal([]PluginVersionDiff{
+			{
+				PluginIdentifier: PluginIdentifier{Name: "management-cluster", Target: types.TargetK8s, Version: "v0.28.0"},
+				Changes: []string{
+					"description changed from 'Kubernetes management cluster operations' to 'Updated description'",
+					"artifact for darwin/arm64 added",
+					"digest of the artifact for linux/amd64 changed from '0000000000' to '9999999999'",
+					"artifact for windows/amd64 removed",
+				},
+			},
+		}))
+
+		Expect(diff.AddedPluginGroups).To(Equal([]PluginGroupVersionDiff{
+			{PluginGroupIdentifier: PluginGroupIdentifier{Vendor: "fakevendor", Publisher: "fakepublisher", Name: "default", Version: "v3.0.0"}},
+		}))
+		Expect(diff.RemovedPluginGroups).To(BeEmpty())
+		Expect(diff.ChangedPluginGroups).To(Equal([]PluginGroupVersionDiff{
+			{
+				PluginGroupIdentifier: PluginGroupIdentifier{Vendor: "fakevendor", Publisher: "fakepublisher", Name: "default", Version: "v2.0.0"},
+				Changes: []string{
+					"plugin isolated-cluster@global:v1.2.3 removed",
+					"version of plugin management-cluster@kubernetes changed from 'v0.28.0' to 'v0.29.0'",
+					"plugin management-cluster@kubernetes made optional",
+					"plugin management-cluster@mission-control:v0.0.1 added",
+				},
+			},
+		}))
+	})
+
+	It("should return no difference for the same inventory", func() {
+		diff, err := DiffInventories(context.Background(), oldInventory, oldInventory)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff.IsEmpty()).To(BeTrue())
+	})
+
+	It("should report the deactivated plugins", func() {
+		hiddenEntry2 := piEntry2
+		hiddenEntry2.Hidden = true
+		Expect(newInventory.InsertPlugin(context.Background(), &hiddenEntry2)).To(Succeed())
+
+		diff, err := DiffInventories(context.Background(), oldInventory, newInventory)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff.RemovedPlugins).To(BeEmpty())
+		Expect(diff.ChangedPlugins).To(ContainElement(PluginVersionDiff{
+			PluginIdentifier: PluginIdentifier{Name: "isolated-cluster", Target: types.TargetGlobal, Version: "v1.2.3"},
+			Changes:          []string{"deactivated"},
+		}))
+	})
+
+	It("should report the deprecated plugins", func() {
+		deprecatedEntry2 := piEntry2
+		deprecatedEntry2.Deprecated = true
+		deprecatedEntry2.ReplacedBy = "management-cluster"
+		Expect(newInventory.InsertPlugin(context.Background(), &deprecatedEntry2)).To(Succeed())
+
+		diff, err := DiffInventories(context.Background(), oldInventory, newInventory)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff.ChangedPlugins).To(ContainElement(PluginVersionDiff{
+			PluginIdentifier: PluginIdentifier{Name: "isolated-cluster", Target: types.TargetGlobal, Version: "v1.2.3"},
+			Changes:          []string{"deprecated", "replacement changed from '' to 'management-cluster'"},
+		}))
+	})
+
+	It("should report the changes of license", func() {
+		licensedEntry2 := piEntry2
+		licensedEntry2.Licenses = map[string]PluginLicense{"v1.2.3": {License: "Apache-2.0", SBOMURI: "https://example.com/sbom.json"}}
+		Expect(newInventory.InsertPlugin(context.Background(), &licensedEntry2)).To(Succeed())
+
+		diff, err := DiffInventories(context.Background(), oldInventory, newInventory)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff.ChangedPlugins).To(ContainElement(PluginVersionDiff{
+			PluginIdentifier: PluginIdentifier{Name: "isolated-cluster", Target: types.TargetGlobal, Version: "v1.2.3"},
+			Changes:          []string{"license changed from '' to 'Apache-2.0'", "SBOM changed from '' to 'https://example.com/sbom.json'"},
+		}))
+	})
+
+	It("should return the error of the inventories", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := DiffInventories(ctx, oldInventory, newInventory)
+		Expect(err).To(MatchError(context.Canceled))
+	})
+})
@@ -0,0 +1,124 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugininventory
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+)
+
+// ExportFormat is the format in which an inventory is exported
+type ExportFormat string
+
+const (
+	// ExportFormatJSON exports the inventory as a JSON manifest of a file inventory
+	ExportFormatJSON ExportFormat = "json"
+	// ExportFormatYAML exports the inventory as a YAML manifest of a file inventory
+	ExportFormatYAML ExportFormat = "yaml"
+	// ExportFormatCSV exports the inventory as CSV, with a row for each artifact of a plugin
+	// and for each plugin of a plugin-group
+	ExportFormatCSV ExportFormat = "csv"
+)
+
+// ExportFormats are the formats in which an inventory can be exported
+var ExportFormats = []ExportFormat{ExportFormatJSON, ExportFormatYAML, ExportFormatCSV}
+
+// exportCSVHeader is the header of an inventory exported as CSV.
+// The rows of the plugins have no plugin-group, and the rows of the plugins of a plugin-group have no artifact.
+var exportCSVHeader = []string{"plugin-group", "plugin-group-version", "name", "target", "version", "vendor", "publisher", "os", "arch", "digest", "image", "hidden", "mandatory"}
+
+// exportInventory writes all the plugins and plugin-groups of the inventory, including the hidden ones,
+// in the format
+func exportInventory(ctx context.Context, inventory PluginInventory, w io.Writer, format ExportFormat) error {
+	plugins, err := inventory.GetPlugins(ctx, &PluginInventoryFilter{IncludeHidden: true})
+	if err != nil {
+		return errors.Wrap(err, "unable to get the plugins of the inventory")
+	}
+	groups, err := inventory.GetPluginGroups(ctx, PluginGroupFilter{IncludeHidden: true})
+	if err != nil {
+		return errors.Wrap(err, "unable to get the plugin-groups of the inventory")
+	}
+	sort.Sort(PluginGroupSorter(groups))
+
+	switch format {
+	case ExportFormatJSON, ExportFormatYAML:
+		var manifest FileInventoryManifest
+		for _, p := range plugins {
+			manifest.Plugins = append(manifest.Plugins, pluginToHTTP(p))
+		}
+		for _, pg := range groups {
+			manifest.PluginGroups = append(manifest.PluginGroups, pluginGroupToHTTP(pg))
+		}
+		if format == ExportFormatYAML {
+			encoder := yaml.NewEncoder(w)
+			encoder.SetIndent(2)
+			if err := encoder.Encode(&manifest); err != nil {
+				return errors.Wrap(err, "unable to export the inventory")
+			}
+			return encoder.Close()
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return errors.Wrap(encoder.Encode(&manifest), "unable to export the inventory")
+	case ExportFormatCSV:
+		return exportInventoryCSV(w, plugins, groups)
+	}
+	return errors.Errorf("invalid export format '%s', the supported formats are %v", format, ExportFormats)
+}
+
+// exportInventoryCSV writes the plugins and plugin-groups as CSV
+func exportInventoryCSV(w io.Writer, plugins []*PluginInventoryEntry, groups []*PluginGroup) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportCSVHeader); err != nil {
+		return errors.Wrap(err, "unable to export the inventory")
+	}
+	for _, p := range plugins {
+		for _, version := range sortedVersions(p.Artifacts) {
+			for _, a := range p.Artifacts[version] {
+				image := a.Image
+				if image == "" {
+					image = a.URI
+				}
+				row := []string{"", "", p.Name, string(p.Target), version, p.Vendor, p.Publisher, a.OS, a.Arch, a.Digest, image, strconv.FormatBool(p.Hidden), ""}
+				if err := writer.Write(row); err != nil {
+					return errors.Wrap(err, "unable to export the inventory")
+				}
+			}
+		}
+	}
+	for _, pg := range groups {
+		for _, version := range sortedVersions(pg.Versions) {
+			for _, pi := range pg.Versions[version] {
+				row := []string{PluginGroupToID(pg), version, pi.Name, string(pi.Target), pi.Version, "", "", "", "", "", "", strconv.FormatBool(pg.Hidden), strconv.FormatBool(pi.Mandatory)}
+				if err := writer.Write(row); err != nil {
+					return errors.Wrap(err, "unable to export the inventory")
+				}
+			}
+		}
+	}
+	writer.Flush()
+	return errors.Wrap(writer.Error(), "unable to export the inventory")
+}
+
+// sortedVersions returns the versions of the map in ascending semver order,
+// or in lexical order if some of them are not semver versions
+func sortedVersions[T any](m map[string]T) []string {
+	versions := make([]string, 0, len(m))
+	for version := range m {
+		versions = append(versions, version)
+	}
+	if err := utils.SortVersions(versions); err != nil {
+		sort.Strings(versions)
+	}
+	return versions
+}
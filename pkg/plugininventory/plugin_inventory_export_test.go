@@ -0,0 +1,88 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugininventory
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Unit tests for exporting a plugin inventory", func() {
+	var (
+		tmpDir    string
+		inventory PluginInventory
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp(os.TempDir(), "")
+		Expect(err).To(BeNil(), "unable to create temporary directory")
+
+		dbFile := filepath.Join(tmpDir, SQliteDBFileName)
+		err = CreateInventoryDB(dbFile, pluginsSeed)
+		Expect(err).To(BeNil(), "failed to seed the DB for testing")
+		inventory = NewSQLiteInventory(dbFile, "example.com/plugins")
+		err = inventory.InsertPluginGroup(context.Background(), &pluginGroup1, false)
+		Expect(err).ToNot(HaveOccurred())
+	})
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	DescribeTable("should export the inventory as a manifest of a file inventory",
+		func(format ExportFormat, file string) {
+			var out bytes.Buffer
+			err := inventory.Export(context.Background(), &out, format)
+			Expect(err).ToNot(HaveOccurred())
+			manifestFile := filepath.Join(tmpDir, file)
+			Expect(os.WriteFile(manifestFile, out.Bytes(), 0o600)).To(Succeed())
+
+			// Exporting the inventory and reading it back must not change it
+			exported := NewFileInventory(manifestFile)
+			expected, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{IncludeHidden: true})
+			Expect(err).ToNot(HaveOccurred())
+			plugins, err := exported.GetPlugins(context.Background(), &PluginInventoryFilter{IncludeHidden: true})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plugins).To(Equal(expected))
+
+			expectedGroups, err := inventory.GetPluginGroups(context.Background(), PluginGroupFilter{IncludeHidden: true})
+			Expect(err).ToNot(HaveOccurred())
+			groups, err := exported.GetPluginGroups(context.Background(), PluginGroupFilter{IncludeHidden: true})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(groups).To(Equal(expectedGroups))
+
+			// The export is stable, so that the exports of an inventory can be diffed
+			var again bytes.Buffer
+			Expect(exported.Export(context.Background(), &again, format)).To(Succeed())
+			Expect(again.String()).To(Equal(out.String()))
+		},
+		Entry("in JSON", ExportFormatJSON, "inventory.json"),
+		Entry("in YAML", ExportFormatYAML, "inventory.yaml"),
+	)
+
+	It("should export the inventory as CSV", func() {
+		var out bytes.Buffer
+		err := inventory.Export(context.Background(), &out, ExportFormatCSV)
+		Expect(err).ToNot(HaveOccurred())
+
+		records, err := csv.NewReader(&out).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(records[0]).To(Equal(exportCSVHeader))
+		Expect(records).To(ContainElement([]string{"", "", "management-cluster", "kubernetes", "v0.28.0", "vmware", "tkg", "linux", "amd64", "0000000000", "example.com/plugins/vmware/tkg/linux/amd64/k8s/management-cluster:v0.28.0", "false", ""}))
+		Expect(records).To(ContainElement([]string{"fakevendor-fakepublisher/default", "v1.0.0", "management-cluster", "kubernetes", "v0.28.0", "", "", "", "", "", "", "false", "true"}))
+	})
+
+	It("should return an error for an invalid format", func() {
+		var out bytes.Buffer
+		err := inventory.Export(context.Background(), &out, "xml")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid export format 'xml'"))
+	})
+})
@@ -3,6 +3,44 @@
 
 package plugininventory
 
+import "github.com/pkg/errors"
+
+// MetadataMergePolicy tells how the entries of an additional inventory metadata
+// database are merged into an inventory metadata database
+type MetadataMergePolicy string
+
+const (
+	// MetadataMergePolicyUnion keeps the entries of both databases
+	MetadataMergePolicyUnion MetadataMergePolicy = "union"
+	// MetadataMergePolicyReplace keeps only the entries of the database,
+	// the entries of the additional database are discarded
+	MetadataMergePolicyReplace MetadataMergePolicy = "replace"
+	// MetadataMergePolicyFailOnConflict keeps the entries of both databases,
+	// unless an entry is in both databases in which case the merge fails
+	MetadataMergePolicyFailOnConflict MetadataMergePolicy = "fail-on-conflict"
+)
+
+// MetadataMergePolicies are the policies to merge inventory metadata databases
+var MetadataMergePolicies = []MetadataMergePolicy{
+	MetadataMergePolicyUnion,
+	MetadataMergePolicyReplace,
+	MetadataMergePolicyFailOnConflict,
+}
+
+// ValidateMetadataMergePolicy returns an error if the policy is not one of MetadataMergePolicies.
+// An empty policy is valid and stands for the union.
+func ValidateMetadataMergePolicy(policy MetadataMergePolicy) error {
+	if policy == "" {
+		return nil
+	}
+	for _, p := range MetadataMergePolicies {
+		if policy == p {
+			return nil
+		}
+	}
+	return errors.Errorf("invalid merge policy '%s', it must be one of %v", policy, MetadataMergePolicies)
+}
+
 // PluginInventoryMetadata is the interface to interact with a plugin inventory
 // metadata database and plugin inventory database.
 // It can be used to create database schema for metadata db, insert
@@ -19,13 +57,26 @@ type PluginInventoryMetadata interface {
 	// AvailablePluginBinaries table
 	InsertPluginIdentifier(*PluginIdentifier) error
 
+	// InsertPluginIdentifiers inserts the PluginIdentifier entries to the
+	// AvailablePluginBinaries table in a single transaction
+	InsertPluginIdentifiers([]*PluginIdentifier) error
+
+	// DeletePluginIdentifier deletes the PluginIdentifier entry from the
+	// AvailablePluginBinaries table. It is not an error if the entry does not exist.
+	DeletePluginIdentifier(*PluginIdentifier) error
+
 	// InsertPluginGroupIdentifier inserts the PluginGroupIdentifier entry to the
 	// AvailablePluginGroups table
 	InsertPluginGroupIdentifier(*PluginGroupIdentifier) error
 
+	// InsertPluginGroupIdentifiers inserts the PluginGroupIdentifier entries to the
+	// AvailablePluginGroups table in a single transaction
+	InsertPluginGroupIdentifiers([]*PluginGroupIdentifier) error
+
 	// MergeInventoryMetadataDatabase merges two inventory metadata database by
 	// merging the content of AvailablePluginBinaries and AvailablePluginGroups tables
-	MergeInventoryMetadataDatabase(additionalMetadataDBFilePath string) error
+	// as specified by the policy
+	MergeInventoryMetadataDatabase(additionalMetadataDBFilePath string, policy MetadataMergePolicy) error
 
 	// UpdatePluginInventoryDatabase updates the plugin inventory database based
 	// on the plugin inventory metadata database by deleting entries that don't
@@ -0,0 +1,158 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugininventory
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+)
+
+// validateInventory checks all the plugins and plugin-groups of the inventory, including the hidden ones,
+// and returns the problems found aggregated in a single error, or nil if the inventory is valid
+func validateInventory(ctx context.Context, inventory PluginInventory) error {
+	plugins, err := inventory.GetPlugins(ctx, &PluginInventoryFilter{IncludeHidden: true})
+	if err != nil {
+		return errors.Wrap(err, "unable to read the plugins of the inventory")
+	}
+	groups, err := inventory.GetPluginGroups(ctx, PluginGroupFilter{IncludeHidden: true})
+	if err != nil {
+		return errors.Wrap(err, "unable to read the plugin groups of the inventory")
+	}
+
+	pluginVersions := make(map[string]bool)
+	artifacts := make(map[string]string)
+	var errs []error
+	for _, p := range plugins {
+		for version := range p.Artifacts {
+			pluginVersions[pluginVersionKey(p.Name, string(p.Target), version)] = true
+		}
+		errs = append(errs, validatePlugin(ctx, p, artifacts)...)
+	}
+	for _, pg := range groups {
+		errs = append(errs, validatePluginGroup(pg, pluginVersions)...)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errors.Wrapf(kerrors.NewAggregate(errs), "%d problems found in the plugin inventory", len(errs))
+	}
+	return nil
+}
+
+// validatePlugin returns the problems found in the versions and artifacts of the plugin.
+// The artifacts are recorded by image or URI in 'artifacts', to find the ones shared with
+// another plugin version or platform; the URIs are only checked the first time.
+func validatePlugin(ctx context.Context, p *PluginInventoryEntry, artifacts map[string]string) []error {
+	var errs []error
+	id := fmt.Sprintf("plugin '%s_%s'", p.Name, p.Target)
+	if err := validateRecommendedVersion(p.RecommendedVersion, p.Artifacts); err != nil {
+		errs = append(errs, errors.Wrap(err, id))
+	}
+	for _, version := range sortedVersions(p.Artifacts) {
+		if _, err := semver.NewVersion(version); err != nil {
+			errs = append(errs, errors.Errorf("%s: invalid version %q", id, version))
+		}
+		versionArtifacts := p.Artifacts[version]
+		for i := range versionArtifacts {
+			a := &versionArtifacts[i]
+			artifactID := fmt.Sprintf("%s version %q for %s/%s", id, version, a.OS, a.Arch)
+			location := a.URI
+			if location == "" {
+				location = a.Image
+			}
+			if location == "" {
+				errs = append(errs, errors.Errorf("%s: the artifact has no image nor URI", artifactID))
+				continue
+			}
+			if existingID, exists := artifacts[location]; exists {
+				errs = append(errs, errors.Errorf("%s: the artifact %q is also the artifact of the %s", artifactID, location, existingID))
+				continue
+			}
+			artifacts[location] = artifactID
+
+			if a.URI != "" {
+				if err := checkArtifactURI(ctx, a); err != nil {
+					errs = append(errs, errors.Wrapf(err, "%s: version %q", id, version))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// validatePluginGroup returns the problems found in the versions of the plugin group,
+// whose plugins must be among the plugin versions of the inventory
+func validatePluginGroup(pg *PluginGroup, pluginVersions map[string]bool) []error {
+	var errs []error
+	id := fmt.Sprintf("plugin group '%s'", PluginGroupToID(pg))
+	if err := validateRecommendedVersion(pg.RecommendedVersion, pg.Versions); err != nil {
+		errs = append(errs, errors.Wrap(err, id))
+	}
+	for _, version := range sortedVersions(pg.Versions) {
+		if _, err := semver.NewVersion(version); err != nil {
+			errs = append(errs, errors.Errorf("%s: invalid version %q", id, version))
+		}
+		for _, entry := range pg.Versions[version] {
+			if !pluginVersions[pluginVersionKey(entry.Name, string(entry.Target), entry.Version)] {
+				errs = append(errs, errors.Errorf("%s: version %q refers to the unavailable plugin '%s_%s' version %q", id, version, entry.Name, entry.Target, entry.Version))
+			}
+		}
+	}
+	return errs
+}
+
+// validateRecommendedVersion returns an error if the recommended version is not one of the versions
+func validateRecommendedVersion[T any](recommendedVersion string, versions map[string]T) error {
+	if recommendedVersion == "" {
+		return errors.New("no recommended version")
+	}
+	if _, exists := versions[recommendedVersion]; !exists {
+		return errors.Errorf("the recommended version %q is not available", recommendedVersion)
+	}
+	return nil
+}
+
+// checkArtifactURI returns an error if the binary at the URI of the artifact cannot be downloaded.
+// The URIs without scheme are local paths, as when the artifacts are downloaded.
+func checkArtifactURI(ctx context.Context, a *distribution.Artifact) error {
+	u, err := url.Parse(a.URI)
+	if err != nil {
+		return errors.Wrapf(err, "invalid URI %q of the artifact for %s/%s", a.URI, a.OS, a.Arch)
+	}
+	switch u.Scheme {
+	case "http", "https":
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, a.URI, http.NoBody)
+		if err != nil {
+			return errors.Wrapf(err, "invalid URI %q of the artifact for %s/%s", a.URI, a.OS, a.Arch)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return errors.Wrapf(err, "the URI %q of the artifact for %s/%s is unreachable", a.URI, a.OS, a.Arch)
+		}
+		res.Body.Close()
+		if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
+			return errors.Errorf("the URI %q of the artifact for %s/%s is unreachable, status code: %d", a.URI, a.OS, a.Arch, res.StatusCode)
+		}
+	case "file":
+		if _, err := os.Stat(filepath.Join(u.Host, u.Path)); err != nil {
+			return errors.Errorf("the URI %q of the artifact for %s/%s does not point to an existing file", a.URI, a.OS, a.Arch)
+		}
+	default:
+		if _, err := os.Stat(a.URI); err != nil {
+			return errors.Errorf("the URI %q of the artifact for %s/%s does not point to an existing file", a.URI, a.OS, a.Arch)
+		}
+	}
+	return nil
+}
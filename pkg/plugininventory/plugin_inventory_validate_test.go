@@ -0,0 +1,106 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugininventory
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Unit tests for the validation of plugin inventories", func() {
+	It("should accept a valid inventory", func() {
+		inventory := NewInMemoryInventory([]*PluginInventoryEntry{&piEntry1, &piEntry2, &piEntry3, &hiddenPluginEntry}).(*InMemoryInventory)
+		inventory.seedPluginGroups([]*PluginGroup{&pluginGroup1})
+		Expect(inventory.Validate(context.Background())).To(Succeed())
+	})
+
+	It("should check the URIs of the artifacts can be reached", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/myplugin" {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+		binary := filepath.Join(GinkgoT().TempDir(), "myplugin")
+		Expect(os.WriteFile(binary, []byte("binary"), 0o600)).To(Succeed())
+
+		inventory := NewInMemoryInventory([]*PluginInventoryEntry{{
+			Name:               "myplugin",
+			Target:             types.TargetGlobal,
+			RecommendedVersion: "v1.0.0",
+			Artifacts: distribution.Artifacts{"v1.0.0": []distribution.Artifact{
+				{OS: "linux", Arch: "amd64", URI: server.URL + "/myplugin"},
+				{OS: "darwin", Arch: "amd64", URI: "file://" + binary},
+				{OS: "windows", Arch: "amd64", URI: server.URL + "/myplugin.exe"},
+			}},
+		}})
+		err := inventory.Validate(context.Background())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix("1 problems found in the plugin inventory"))
+		Expect(err.Error()).To(ContainSubstring(`plugin 'myplugin_global': version "v1.0.0": the URI "` + server.URL + `/myplugin.exe" of the artifact for windows/amd64 is unreachable, status code: 404`))
+	})
+
+	It("should return all the problems of the inventory", func() {
+		invalidEntry := PluginInventoryEntry{
+			Name:               "invalid",
+			Target:             types.TargetK8s,
+			RecommendedVersion: "v2.0.0",
+			Artifacts: distribution.Artifacts{
+				"1.x": []distribution.Artifact{
+					{OS: "linux", Arch: "amd64", Image: "vmware/tkg/linux/amd64/k8s/invalid:1.x"},
+				},
+				"v1.0.0": []distribution.Artifact{
+					{OS: "linux", Arch: "amd64", Image: "vmware/tkg/linux/amd64/k8s/management-cluster:v0.28.0"},
+					{OS: "darwin", Arch: "amd64", URI: "file:///does/not/exist"},
+				},
+			},
+		}
+		orphanGroup := PluginGroup{
+			Name:               "orphan",
+			Vendor:             "vmware",
+			Publisher:          "tkg",
+			RecommendedVersion: "v1.0.0",
+			Versions: map[string][]*PluginGroupPluginEntry{
+				"v1.0.0": {
+					{PluginIdentifier: PluginIdentifier{Name: "management-cluster", Target: types.TargetK8s, Version: "v9.9.9"}},
+				},
+			},
+		}
+		inventory := NewInMemoryInventory([]*PluginInventoryEntry{&piEntry1, &invalidEntry}).(*InMemoryInventory)
+		inventory.seedPluginGroups([]*PluginGroup{&orphanGroup})
+
+		err := inventory.Validate(context.Background())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix("5 problems found in the plugin inventory"))
+		Expect(err.Error()).To(ContainSubstring(`plugin 'invalid_kubernetes': the recommended version "v2.0.0" is not available`))
+		Expect(err.Error()).To(ContainSubstring(`plugin 'invalid_kubernetes': invalid version "1.x"`))
+		Expect(err.Error()).To(ContainSubstring(`plugin 'management-cluster_kubernetes' version "v0.28.0" for linux/amd64: the artifact "vmware/tkg/linux/amd64/k8s/management-cluster:v0.28.0" is also the artifact of the plugin 'invalid_kubernetes' version "v1.0.0" for linux/amd64`))
+		Expect(err.Error()).To(ContainSubstring(`plugin 'invalid_kubernetes': version "v1.0.0": the URI "file:///does/not/exist" of the artifact for darwin/amd64 does not point to an existing file`))
+		Expect(err.Error()).To(ContainSubstring(`plugin group 'vmware-tkg/orphan': version "v1.0.0" refers to the unavailable plugin 'management-cluster_kubernetes' version "v9.9.9"`))
+	})
+
+	It("should validate an SQLite inventory", func() {
+		dbFile := filepath.Join(GinkgoT().TempDir(), SQliteDBFileName)
+		Expect(CreateInventoryDB(dbFile, &InventorySeed{Plugins: []*PluginInventoryEntry{&piEntry1, &piEntry2}, Groups: []*PluginGroup{&pluginGroup1}})).To(Succeed())
+		inventory := NewSQLiteInventory(dbFile, "localhost:9876/tanzu-cli/plugins")
+		Expect(inventory.Validate(context.Background())).To(Succeed())
+	})
+
+	It("should return the error of the context", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		inventory := NewInMemoryInventory([]*PluginInventoryEntry{&piEntry1})
+		Expect(inventory.Validate(ctx)).To(MatchError(context.Canceled))
+	})
+})
@@ -0,0 +1,181 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugininventory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+)
+
+// PluginSortField is a field the plugins can be sorted by
+type PluginSortField string
+
+// SortOrder is the order in which the plugins are sorted
+type SortOrder string
+
+const (
+	// PluginSortByName sorts the plugins by name
+	PluginSortByName PluginSortField = "name"
+	// PluginSortByTarget sorts the plugins by target
+	PluginSortByTarget PluginSortField = "target"
+	// PluginSortByVendor sorts the plugins by vendor
+	PluginSortByVendor PluginSortField = "vendor"
+	// PluginSortByPublisher sorts the plugins by publisher
+	PluginSortByPublisher PluginSortField = "publisher"
+	// PluginSortByRecommendedVersion sorts the plugins by recommended version
+	PluginSortByRecommendedVersion PluginSortField = "recommended-version"
+	// PluginSortByMostRecentVersion sorts the plugins by their most recent version
+	PluginSortByMostRecentVersion PluginSortField = "most-recent-version"
+	// PluginSortByReleased sorts the plugins by the release time of their most recently published version
+	PluginSortByReleased PluginSortField = "released"
+
+	// SortOrderAscending sorts the plugins in ascending order
+	SortOrderAscending SortOrder = "asc"
+	// SortOrderDescending sorts the plugins in descending order
+	SortOrderDescending SortOrder = "desc"
+)
+
+// PluginSortFields are the fields the plugins can be sorted by
+var PluginSortFields = []PluginSortField{
+	PluginSortByName,
+	PluginSortByTarget,
+	PluginSortByVendor,
+	PluginSortByPublisher,
+	PluginSortByRecommendedVersion,
+	PluginSortByMostRecentVersion,
+	PluginSortByReleased,
+}
+
+// PluginSortValues contains the values of a plugin which can be used to sort the plugins
+type PluginSortValues struct {
+	Name               string
+	Target             configtypes.Target
+	Vendor             string
+	Publisher          string
+	RecommendedVersion string
+	MostRecentVersion  string
+	// Released is the release time of the most recently published version, zero if unknown
+	Released time.Time
+}
+
+// ValidatePluginSort returns an error if the plugins cannot be sorted by the field in the order.
+// An empty field and an empty order are valid and stand for the name and the ascending order.
+func ValidatePluginSort(sortBy PluginSortField, order SortOrder) error {
+	valid := sortBy == ""
+	for _, field := range PluginSortFields {
+		valid = valid || sortBy == field
+	}
+	if !valid {
+		return errors.Errorf("invalid field '%s' to sort the plugins by, it must be one of %v", sortBy, PluginSortFields)
+	}
+	if order != "" && order != SortOrderAscending && order != SortOrderDescending {
+		return errors.Errorf("invalid sort order '%s', it must be '%s' or '%s'", order, SortOrderAscending, SortOrderDescending)
+	}
+	return nil
+}
+
+// SortPluginsBy sorts the slice of plugins by the field in the order, using the values of the
+// plugin at the index. The plugins with the same value are sorted by name and target.
+// Without order, the plugins sorted by release time are listed newest first.
+func SortPluginsBy(plugins interface{}, sortBy PluginSortField, order SortOrder, values func(i int) PluginSortValues) error {
+	if err := ValidatePluginSort(sortBy, order); err != nil {
+		return err
+	}
+	if order == "" && sortBy == PluginSortByReleased {
+		order = SortOrderDescending
+	}
+	sort.SliceStable(plugins, func(i, j int) bool {
+		vi, vj := values(i), values(j)
+		if cmp := comparePluginSortValues(sortBy, &vi, &vj); cmp != 0 {
+			if order == SortOrderDescending {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		if vi.Name != vj.Name {
+			return vi.Name < vj.Name
+		}
+		return vi.Target < vj.Target
+	})
+	return nil
+}
+
+// SortPlugins sorts the plugin inventory entries by the field in the order
+func SortPlugins(plugins []*PluginInventoryEntry, sortBy PluginSortField, order SortOrder) error {
+	return SortPluginsBy(plugins, sortBy, order, func(i int) PluginSortValues {
+		values := PluginSortValues{
+			Name:               plugins[i].Name,
+			Target:             plugins[i].Target,
+			Vendor:             plugins[i].Vendor,
+			Publisher:          plugins[i].Publisher,
+			RecommendedVersion: plugins[i].RecommendedVersion,
+		}
+		for version := range plugins[i].Artifacts {
+			if values.MostRecentVersion == "" || compareVersions(version, values.MostRecentVersion) > 0 {
+				values.MostRecentVersion = version
+			}
+		}
+		values.Released = LatestReleaseTime(plugins[i].ReleasedAt)
+		return values
+	})
+}
+
+// comparePluginSortValues compares the values of the field of two plugins
+func comparePluginSortValues(sortBy PluginSortField, v1, v2 *PluginSortValues) int {
+	var s1, s2 string
+	switch sortBy {
+	case PluginSortByTarget:
+		s1, s2 = string(v1.Target), string(v2.Target)
+	case PluginSortByVendor:
+		s1, s2 = v1.Vendor, v2.Vendor
+	case PluginSortByPublisher:
+		s1, s2 = v1.Publisher, v2.Publisher
+	case PluginSortByRecommendedVersion:
+		return compareVersions(v1.RecommendedVersion, v2.RecommendedVersion)
+	case PluginSortByMostRecentVersion:
+		return compareVersions(v1.MostRecentVersion, v2.MostRecentVersion)
+	case PluginSortByReleased:
+		return v1.Released.Compare(v2.Released)
+	default:
+		s1, s2 = v1.Name, v2.Name
+	}
+	switch {
+	case s1 < s2:
+		return -1
+	case s1 > s2:
+		return 1
+	}
+	return 0
+}
+
+// LatestReleaseTime returns the release time of the most recently published version, zero if none is known
+func LatestReleaseTime(releasedAt map[string]time.Time) time.Time {
+	var latest time.Time
+	for _, t := range releasedAt {
+		if t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
+}
+
+// compareVersions compares two semantic versions; the invalid versions are lower than the valid ones
+func compareVersions(v1str, v2str string) int {
+	v1, err1 := semver.NewVersion(v1str)
+	v2, err2 := semver.NewVersion(v2str)
+	switch {
+	case err1 != nil && err2 != nil:
+		return 0
+	case err1 != nil:
+		return -1
+	case err2 != nil:
+		return 1
+	}
+	return v1.Compare(v2)
+}
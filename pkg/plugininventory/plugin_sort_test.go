@@ -0,0 +1,118 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugininventory
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+)
+
+func TestSortPlugins(t *testing.T) {
+	pluginA := &PluginInventoryEntry{Name: "a", Target: configtypes.TargetK8s, Vendor: "vmware", Publisher: "tkg", RecommendedVersion: "v1.10.0",
+		Artifacts: distribution.Artifacts{"v1.10.0": nil, "v2.0.0-alpha.1": nil}}
+	pluginB := &PluginInventoryEntry{Name: "b", Target: configtypes.TargetGlobal, Vendor: "other", Publisher: "tkg", RecommendedVersion: "v1.9.0",
+		Artifacts: distribution.Artifacts{"v1.9.0": nil}}
+	pluginBTMC := &PluginInventoryEntry{Name: "b", Target: configtypes.TargetTMC, Vendor: "vmware", Publisher: "tmc", RecommendedVersion: "v1.2.0",
+		Artifacts: distribution.Artifacts{"v1.2.0": nil, "v1.11.0": nil}}
+
+	releasedA := &PluginInventoryEntry{Name: "a", Target: configtypes.TargetK8s,
+		ReleasedAt: map[string]time.Time{"v1.0.0": time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC), "v2.0.0": time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)}}
+	releasedB := &PluginInventoryEntry{Name: "b", Target: configtypes.TargetK8s,
+		ReleasedAt: map[string]time.Time{"v1.0.0": time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)}}
+	unreleasedC := &PluginInventoryEntry{Name: "c", Target: configtypes.TargetK8s}
+
+	tests := []struct {
+		name   string
+		sortBy PluginSortField
+		order  SortOrder
+		input  []*PluginInventoryEntry
+		output []*PluginInventoryEntry
+	}{
+		{
+			name:   "Default",
+			output: []*PluginInventoryEntry{pluginA, pluginB, pluginBTMC},
+		},
+		{
+			name:   "NameDescending",
+			sortBy: PluginSortByName,
+			order:  SortOrderDescending,
+			output: []*PluginInventoryEntry{pluginB, pluginBTMC, pluginA},
+		},
+		{
+			name:   "Target",
+			sortBy: PluginSortByTarget,
+			output: []*PluginInventoryEntry{pluginB, pluginA, pluginBTMC},
+		},
+		{
+			name:   "VendorDescending",
+			sortBy: PluginSortByVendor,
+			order:  SortOrderDescending,
+			output: []*PluginInventoryEntry{pluginA, pluginBTMC, pluginB},
+		},
+		{
+			name:   "Publisher",
+			sortBy: PluginSortByPublisher,
+			output: []*PluginInventoryEntry{pluginA, pluginB, pluginBTMC},
+		},
+		{
+			name:   "RecommendedVersion",
+			sortBy: PluginSortByRecommendedVersion,
+			output: []*PluginInventoryEntry{pluginBTMC, pluginB, pluginA},
+		},
+		{
+			name:   "MostRecentVersionDescending",
+			sortBy: PluginSortByMostRecentVersion,
+			order:  SortOrderDescending,
+			output: []*PluginInventoryEntry{pluginA, pluginBTMC, pluginB},
+		},
+		{
+			name:   "ReleasedNewestFirst",
+			sortBy: PluginSortByReleased,
+			input:  []*PluginInventoryEntry{releasedA, unreleasedC, releasedB},
+			output: []*PluginInventoryEntry{releasedB, releasedA, unreleasedC},
+		},
+		{
+			name:   "ReleasedAscending",
+			sortBy: PluginSortByReleased,
+			order:  SortOrderAscending,
+			input:  []*PluginInventoryEntry{releasedA, unreleasedC, releasedB},
+			output: []*PluginInventoryEntry{unreleasedC, releasedA, releasedB},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plugins := []*PluginInventoryEntry{pluginBTMC, pluginA, pluginB}
+			if test.input != nil {
+				plugins = test.input
+			}
+			if err := SortPlugins(plugins, test.sortBy, test.order); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(plugins, test.output) {
+				t.Errorf("Expected %v, but got %v", test.output, plugins)
+			}
+		})
+	}
+}
+
+func TestValidatePluginSort(t *testing.T) {
+	if err := ValidatePluginSort("", ""); err != nil {
+		t.Errorf("unexpected error for the default sort: %v", err)
+	}
+	if err := ValidatePluginSort(PluginSortByMostRecentVersion, SortOrderDescending); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := ValidatePluginSort("size", ""); err == nil {
+		t.Error("expected an error for an invalid field")
+	}
+	if err := ValidatePluginSort(PluginSortByName, "up"); err == nil {
+		t.Error("expected an error for an invalid order")
+	}
+}
@@ -4,11 +4,18 @@
 package plugininventory
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	// Import the sqlite3 driver
 	_ "modernc.org/sqlite"
@@ -17,6 +24,7 @@ import (
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/catalog"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
@@ -38,13 +46,17 @@ const (
 	// the OCI image describing the inventory of plugins.
 	SQliteDBFileName = "plugin_inventory.db"
 
+	// pluginColumns are the columns of the PluginBinaries table created with the first version of the schema
+	pluginColumns = "PluginName,Target,RecommendedVersion,Version,Hidden,Description,Publisher,Vendor,OS,Architecture,Digest,URI"
+
 	// pluginSelectClause is the SELECT section of the SQL query to be used when querying the inventory DB.
-	pluginSelectClause = "SELECT PluginName,Target,RecommendedVersion,Version,Hidden,Description,Publisher,Vendor,OS,Architecture,Digest,URI FROM PluginBinaries"
+	// It includes the columns added by the migrations of the schema, see pluginMigratedColumns.
+	pluginSelectClause = "SELECT " + pluginColumns + ",Deprecated,DeprecationMessage,ReplacedBy,Size,Signature,ReleasedAt FROM PluginBinaries"
 
 	// pluginOrderClause is the ORDER section of the SQL query to be used when querying the inventory DB.
 	// It MUST be used, as the order of the results is required by the functions processing the results.
 	// The column order must also match the order used in getPluginNextRow().
-	pluginOrderClause = "ORDER BY PluginName,Target,Version"
+	pluginOrderClause = "ORDER BY PluginName,Target,Version,OS,Architecture"
 
 	// groupSelectClause is the SELECT section of the query used to extract plugin groups from the PluginGroups table
 	groupSelectClause = "SELECT Vendor,Publisher,GroupName,GroupVersion,Description,PluginName,Target,PluginVersion,Mandatory,Hidden FROM PluginGroups"
@@ -53,8 +65,30 @@ const (
 	// It MUST be used, as the order of the results is required by the functions processing the results.
 	// The column order must also match the order used in getGroupNextRow().
 	groupOrderClause = "ORDER by Vendor,Publisher,GroupName,GroupVersion,PluginName,Target"
+
+	// maxVersionDetailsQueryPlugins is the maximum number of plugin names used to filter the queries of the
+	// details of the plugin versions, e.g. the release notes; above it, the whole table is read instead.
+	maxVersionDetailsQueryPlugins = 500
+
+	// walkPluginsBatchSize is the number of plugins read from the DB before passing them to the
+	// function of WalkPlugins, as their release notes are read with one query per batch.
+	walkPluginsBatchSize = 100
 )
 
+// pluginMigratedColumns are the columns added to the PluginBinaries table by the migrations of the schema,
+// in the order of pluginSelectClause, with the value selected instead for the inventories not migrated yet.
+var pluginMigratedColumns = []struct {
+	name         string
+	defaultValue string
+}{
+	{name: "Deprecated", defaultValue: "'false'"},
+	{name: "DeprecationMessage", defaultValue: "''"},
+	{name: "ReplacedBy", defaultValue: "''"},
+	{name: "Size", defaultValue: "0"},
+	{name: "Signature", defaultValue: "''"},
+	{name: "ReleasedAt", defaultValue: "''"},
+}
+
 // Structure of each row of the PluginBinaries table within the SQLite database
 type pluginDBRow struct {
 	name               string
@@ -69,6 +103,12 @@ type pluginDBRow struct {
 	arch               string
 	digest             string
 	uri                string
+	deprecated         string
+	deprecationMessage string
+	replacedBy         string
+	size               int64
+	signature          string
+	releasedAt         string
 }
 
 // Structure of each row of the PluginGroups table within the SQLite database
@@ -93,19 +133,73 @@ func NewSQLiteInventory(inventoryFile, prefix string) PluginInventory {
 	}
 }
 
+// CheckSQLiteInventoryIntegrity returns an error if the inventory file is not a plugin inventory
+// database or is corrupted, e.g. a cached inventory database truncated while it was being written.
+// It uses the quick integrity check of SQLite, which verifies the structure of the whole database
+// file, but not the consistency of the indexes with the tables, as it is much faster.
+func CheckSQLiteInventoryIntegrity(ctx context.Context, inventoryFile string) error {
+	db, err := openInventoryDB(inventoryFile)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open the DB at '%s'", inventoryFile)
+	}
+	defer closeInventoryDB(db)
+
+	var tableCount int
+	err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name='PluginBinaries';").Scan(&tableCount)
+	if err != nil {
+		return errors.Wrapf(err, "the DB at '%s' is corrupted", inventoryFile)
+	}
+	if tableCount == 0 {
+		return errors.Errorf("the DB at '%s' is not a plugin inventory", inventoryFile)
+	}
+
+	rows, err := db.QueryContext(ctx, "PRAGMA quick_check;")
+	if err != nil {
+		return errors.Wrapf(err, "the DB at '%s' is corrupted", inventoryFile)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var problem string
+		if err := rows.Scan(&problem); err != nil {
+			return errors.Wrapf(err, "the DB at '%s' is corrupted", inventoryFile)
+		}
+		if problem != "ok" {
+			problems = append(problems, problem)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return errors.Wrapf(err, "the DB at '%s' is corrupted", inventoryFile)
+	}
+	if len(problems) > 0 {
+		return errors.Errorf("the DB at '%s' is corrupted: %s", inventoryFile, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
 // GetAllPlugins returns all plugins found in the inventory.
-func (b *SQLiteInventory) GetAllPlugins() ([]*PluginInventoryEntry, error) {
-	return b.GetPlugins(&PluginInventoryFilter{})
+func (b *SQLiteInventory) GetAllPlugins(ctx context.Context) ([]*PluginInventoryEntry, error) {
+	return b.GetPlugins(ctx, &PluginInventoryFilter{})
 }
 
 // GetPlugins returns the plugin found in the inventory that matches the provided parameters.
-func (b *SQLiteInventory) GetPlugins(filter *PluginInventoryFilter) ([]*PluginInventoryEntry, error) {
+func (b *SQLiteInventory) GetPlugins(ctx context.Context, filter *PluginInventoryFilter) ([]*PluginInventoryEntry, error) {
 	if filter == nil {
 		// Replace a nil filter with an empty object
 		// This will cause all hidden plugins to be ignored by default since
 		// the filter.IncludeHidden boolean field will default to false
 		filter = &PluginInventoryFilter{}
 	}
+	return getPlugins(ctx, filter, b.getPluginsFromDB)
+}
+
+// getPlugins returns the plugins matching the filter, read from the inventory with the function
+// which returns them in the order of their name and target, and sorts them as requested by the filter
+func getPlugins(ctx context.Context, filter *PluginInventoryFilter, readPlugins func(context.Context, *PluginInventoryFilter) ([]*PluginInventoryEntry, error)) ([]*PluginInventoryEntry, error) {
+	if err := ValidatePluginSort(filter.SortBy, filter.SortOrder); err != nil {
+		return nil, err
+	}
 
 	// Since the Central Repo does not have its RecommendedVersion field set yet,
 	// we first search for it by looking for the latest version amongst all versions.
@@ -115,7 +209,7 @@ func (b *SQLiteInventory) GetPlugins(filter *PluginInventoryFilter) ([]*PluginIn
 		}
 		// Ask for all versions
 		filter.Version = ""
-		plugins, err := b.getPluginsFromDB(filter)
+		plugins, err := readPlugins(ctx, filter)
 		if err != nil {
 			return nil, err
 		}
@@ -131,10 +225,87 @@ func (b *SQLiteInventory) GetPlugins(filter *PluginInventoryFilter) ([]*PluginIn
 		filter.Version = plugins[0].RecommendedVersion
 	}
 
-	return b.getPluginsFromDB(filter)
+	plugins, err := readPlugins(ctx, filter)
+	if err != nil || (filter.SortBy == "" && filter.SortOrder == "") {
+		// The plugins are already ordered by name and target
+		return plugins, err
+	}
+	return plugins, SortPlugins(plugins, filter.SortBy, filter.SortOrder)
 }
 
-func (b *SQLiteInventory) GetPluginGroups(filter PluginGroupFilter) ([]*PluginGroup, error) {
+// WalkPlugins calls fn for each plugin found in the inventory that matches the provided filter.
+// Unlike GetPlugins, the plugins are not all loaded in memory at once, which matters for
+// the operations reading every plugin of large inventories.
+func (b *SQLiteInventory) WalkPlugins(ctx context.Context, filter *PluginInventoryFilter, fn func(*PluginInventoryEntry) error) error {
+	if filter != nil && filter.Version == cli.VersionLatest {
+		// The latest version is only supported for a given plugin name,
+		// so there are few plugins to load.
+		plugins, err := b.GetPlugins(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, p := range plugins {
+			if err := fn(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if filter == nil {
+		filter = &PluginInventoryFilter{}
+	}
+	return b.walkPluginsFromDB(ctx, filter, fn)
+}
+
+// GetPluginsPage returns at most 'limit' plugins matching the provided filter, after skipping the first
+// 'offset' of them in the order of their name and target. Only the rows of the plugins of the page are
+// read from the DB.
+func (b *SQLiteInventory) GetPluginsPage(ctx context.Context, filter *PluginInventoryFilter, offset, limit int) ([]*PluginInventoryEntry, error) {
+	if offset < 0 || limit <= 0 {
+		return nil, fmt.Errorf("invalid page of plugins with offset %d and limit %d", offset, limit)
+	}
+	if filter != nil && filter.Version == cli.VersionLatest {
+		// The latest version is only supported for a given plugin name,
+		// so there are few plugins to load.
+		plugins, err := b.GetPlugins(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		if offset >= len(plugins) {
+			return []*PluginInventoryEntry{}, nil
+		}
+		return plugins[offset:min(offset+limit, len(plugins))], nil
+	}
+	if filter == nil {
+		filter = &PluginInventoryFilter{}
+	}
+
+	db, rows, err := b.queryPlugins(ctx, filter, &pluginPage{offset: offset, limit: limit})
+	if db == nil {
+		return []*PluginInventoryEntry{}, err
+	}
+	defer closeInventoryDB(db)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	plugins, err := b.extractPluginsFromRows(rows, filter)
+	if err != nil {
+		return plugins, err
+	}
+	if err := addPluginVersionDetails(ctx, db, plugins); err != nil {
+		return nil, errors.Wrapf(err, "from the DB at '%s'", b.inventoryFile)
+	}
+	return plugins, nil
+}
+
+func (b *SQLiteInventory) GetPluginGroups(ctx context.Context, filter PluginGroupFilter) ([]*PluginGroup, error) {
+	return getPluginGroups(ctx, filter, b.getGroupsFromDB)
+}
+
+// getPluginGroups returns the plugin groups matching the filter, read from the inventory with the function
+func getPluginGroups(ctx context.Context, filter PluginGroupFilter, readGroups func(context.Context, PluginGroupFilter) ([]*PluginGroup, error)) ([]*PluginGroup, error) {
 	// If the filter requires the latest version, we first look for it amongst all versions.
 	if filter.Version == cli.VersionLatest {
 		if filter.Name == "" {
@@ -142,7 +313,7 @@ func (b *SQLiteInventory) GetPluginGroups(filter PluginGroupFilter) ([]*PluginGr
 		}
 		// Ask for all versions
 		filter.Version = ""
-		groups, err := b.getGroupsFromDB(filter)
+		groups, err := readGroups(ctx, filter)
 		if err != nil {
 			return nil, err
 		}
@@ -155,61 +326,325 @@ func (b *SQLiteInventory) GetPluginGroups(filter PluginGroupFilter) ([]*PluginGr
 		filter.Version = groups[0].RecommendedVersion
 	}
 
-	return b.getGroupsFromDB(filter)
+	return readGroups(ctx, filter)
 }
 
 // getPluginsFromDB returns the plugins found in the DB 'inventoryFile' that match the filter
+func (b *SQLiteInventory) getPluginsFromDB(ctx context.Context, filter *PluginInventoryFilter) ([]*PluginInventoryEntry, error) {
+	db, rows, err := b.queryPlugins(ctx, filter, nil)
+	if db == nil {
+		return []*PluginInventoryEntry{}, err
+	}
+	defer closeInventoryDB(db)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	plugins, err := b.extractPluginsFromRows(rows, filter)
+	if err != nil {
+		return plugins, err
+	}
+	if err := addPluginVersionDetails(ctx, db, plugins); err != nil {
+		return nil, errors.Wrapf(err, "from the DB at '%s'", b.inventoryFile)
+	}
+	return plugins, nil
+}
+
+// walkPluginsFromDB calls fn for each plugin found in the DB 'inventoryFile' that matches the filter.
+// The plugins are read in batches of walkPluginsBatchSize plugins, to read the details of their
+// versions with a single query per batch.
+func (b *SQLiteInventory) walkPluginsFromDB(ctx context.Context, filter *PluginInventoryFilter, fn func(*PluginInventoryEntry) error) error {
+	db, rows, err := b.queryPlugins(ctx, filter, nil)
+	if db == nil {
+		return err
+	}
+	defer closeInventoryDB(db)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	batch := make([]*PluginInventoryEntry, 0, walkPluginsBatchSize)
+	flush := func() error {
+		if err := addPluginVersionDetails(ctx, db, batch); err != nil {
+			return errors.Wrapf(err, "from the DB at '%s'", b.inventoryFile)
+		}
+		for _, p := range batch {
+			if err := fn(p); err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+	err = b.walkPluginsFromRows(rows, filter, func(p *PluginInventoryEntry) error {
+		batch = append(batch, p)
+		if len(batch) < walkPluginsBatchSize {
+			return nil
+		}
+		return flush()
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+// pluginPage is a page of the plugins, in the order of their name and target, queried from the DB
+type pluginPage struct {
+	offset int
+	limit  int
+}
+
+// queryPlugins opens the DB 'inventoryFile' and queries the rows of the plugins matching the filter,
+// in the order expected by walkPluginsFromRows(). If a page is provided, only the rows of the plugins
+// of the page are queried.
+// The returned DB is nil when the inventory file does not exist or cannot be reached;
+// otherwise the caller must close it with closeInventoryDB(), as well as the returned rows when there is no error.
 //
 //nolint:dupl
-func (b *SQLiteInventory) getPluginsFromDB(filter *PluginInventoryFilter) ([]*PluginInventoryEntry, error) {
+func (b *SQLiteInventory) queryPlugins(ctx context.Context, filter *PluginInventoryFilter, page *pluginPage) (*sql.DB, *sql.Rows, error) {
 	// Check if the inventory file exists.
 	if _, err := os.Stat(b.inventoryFile); os.IsNotExist(err) {
-		return []*PluginInventoryEntry{}, nil
+		return nil, nil, nil
 	}
 
-	db, err := sql.Open("sqlite", b.inventoryFile)
+	db, err := openInventoryDB(b.inventoryFile)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to open the DB at '%s'", b.inventoryFile)
+		return nil, nil, errors.Wrapf(err, "failed to open the DB at '%s'", b.inventoryFile)
 	}
-	defer db.Close()
 
 	// Return empty data if db connection is not available
-	err = db.Ping()
+	err = db.PingContext(ctx)
 	if err != nil {
-		return []*PluginInventoryEntry{}, err
+		closeInventoryDB(db)
+		return nil, nil, err
 	}
 
-	whereClause, err := createPluginWhereClause(filter)
+	query, err := createPluginQuery(filter)
 	if err != nil {
-		return nil, err
+		return db, nil, err
+	}
+	if page != nil {
+		// The rows of a plugin are spread over its versions and platforms,
+		// so the page is selected among the distinct plugins
+		pageArgs := append(append([]interface{}{}, query.args...), page.limit, page.offset)
+		query.where("(PluginName,Target) IN (SELECT DISTINCT PluginName,Target FROM PluginBinaries "+query.whereClause()+" ORDER BY PluginName,Target LIMIT ? OFFSET ?)", pageArgs...)
+	}
+
+	selectClause, err := pluginSelectClauseForDB(ctx, db)
+	if err != nil {
+		return db, nil, errors.Wrapf(err, "unable to read the schema of the DB at '%s'", b.inventoryFile)
 	}
 
 	// Build the final query with the SELECT, WHERE and ORDER clauses.
-	// The ORDER clause is essential because the parsing algorithm of extractPluginsFromRows()
+	// The ORDER clause is essential because the parsing algorithm of walkPluginsFromRows()
 	// assumes that ordering.
-	dbQuery := fmt.Sprintf("%s %s %s", pluginSelectClause, whereClause, pluginOrderClause)
-	rows, err := db.Query(dbQuery)
+	rows, err := queryInventoryDB(ctx, db, query.query(selectClause, pluginOrderClause), query.args...)
+	if err != nil {
+		return db, nil, errors.Wrapf(err, "unable to setup DB query for DB at '%s'", b.inventoryFile)
+	}
+	return db, rows, nil
+}
+
+// pluginSelectClauseForDB returns the SELECT section of the SQL query of the plugins of the DB.
+// The columns missing from the inventories whose schema was not migrated yet are replaced
+// by their default value, so that the rows of all the inventories have the same columns.
+func pluginSelectClauseForDB(ctx context.Context, db *sql.DB) (string, error) {
+	rows, err := queryInventoryDB(ctx, db, "SELECT name FROM pragma_table_info('PluginBinaries');")
 	if err != nil {
-		return nil, errors.Wrapf(err, "unable to setup DB query for DB at '%s'", b.inventoryFile)
+		return "", err
 	}
 	defer rows.Close()
 
-	return b.extractPluginsFromRows(rows)
+	existingColumns := make(map[string]bool)
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return "", err
+		}
+		existingColumns[column] = true
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	columns := []string{pluginColumns}
+	for _, column := range pluginMigratedColumns {
+		if !existingColumns[column.name] {
+			columns = append(columns, column.defaultValue)
+			continue
+		}
+		columns = append(columns, column.name)
+	}
+	return fmt.Sprintf("SELECT %s FROM PluginBinaries", strings.Join(columns, ",")), nil
 }
 
-// createPluginWhereClause parses the filter and creates the WHERE clause for the DB query.
-//
-//nolint:unparam
-func createPluginWhereClause(filter *PluginInventoryFilter) (string, error) {
-	var whereClause string
+// addPluginVersionDetails sets the release notes, the minimum CLI versions and the licenses of the versions
+// of the plugins found in the DB
+func addPluginVersionDetails(ctx context.Context, db *sql.DB, plugins []*PluginInventoryEntry) error {
+	if err := addPluginReleaseNotes(ctx, db, plugins); err != nil {
+		return errors.Wrap(err, "unable to read the release notes")
+	}
+	if err := addPluginMinCLIVersions(ctx, db, plugins); err != nil {
+		return errors.Wrap(err, "unable to read the minimum CLI versions")
+	}
+	if err := addPluginLicenses(ctx, db, plugins); err != nil {
+		return errors.Wrap(err, "unable to read the licenses")
+	}
+	return nil
+}
+
+// addPluginReleaseNotes sets the release notes of the versions of the plugins found in the DB.
+// The inventories created before release notes were supported have no PluginReleaseNotes table,
+// in which case the plugins are left unchanged.
+func addPluginReleaseNotes(ctx context.Context, db *sql.DB, plugins []*PluginInventoryEntry) error {
+	return walkPluginVersionRows(ctx, db, "PluginReleaseNotes", []string{"ChangelogURL", "Notes"}, plugins, func(p *PluginInventoryEntry, version string, values []string) {
+		if p.ReleaseNotes == nil {
+			p.ReleaseNotes = make(map[string]PluginReleaseNotes)
+		}
+		p.ReleaseNotes[version] = PluginReleaseNotes{ChangelogURL: values[0], Notes: values[1]}
+	})
+}
+
+// addPluginMinCLIVersions sets the minimum CLI versions required by the versions of the plugins found in the DB.
+// The inventories created before the minimum CLI versions were supported have no PluginCompatibility table,
+// in which case the plugins are left unchanged.
+func addPluginMinCLIVersions(ctx context.Context, db *sql.DB, plugins []*PluginInventoryEntry) error {
+	return walkPluginVersionRows(ctx, db, "PluginCompatibility", []string{"MinCLIVersion"}, plugins, func(p *PluginInventoryEntry, version string, values []string) {
+		if p.MinCLIVersions == nil {
+			p.MinCLIVersions = make(map[string]string)
+		}
+		p.MinCLIVersions[version] = values[0]
+	})
+}
+
+// addPluginLicenses sets the licenses of the versions of the plugins found in the DB.
+// The inventories whose schema was not migrated to include licenses have no PluginLicenses table,
+// in which case the plugins are left unchanged.
+func addPluginLicenses(ctx context.Context, db *sql.DB, plugins []*PluginInventoryEntry) error {
+	return walkPluginVersionRows(ctx, db, "PluginLicenses", []string{"License", "SBOMURI"}, plugins, func(p *PluginInventoryEntry, version string, values []string) {
+		if p.Licenses == nil {
+			p.Licenses = make(map[string]PluginLicense)
+		}
+		p.Licenses[version] = PluginLicense{License: values[0], SBOMURI: values[1]}
+	})
+}
 
-	// If there is a filter, create a WHERE clause for the query.
+// walkPluginVersionRows calls fn with the values of the columns of each row of the table, keyed by
+// PluginName, Target and Version, which applies to a version of one of the plugins matching the filter.
+// The rows must also have the vendor and publisher of the plugin: they are read from the Vendor and
+// Publisher columns of the table if it has some, or from the PluginBinaries rows of the version otherwise.
+// Nothing is done if the table does not exist.
+func walkPluginVersionRows(ctx context.Context, db *sql.DB, table string, columns []string, plugins []*PluginInventoryEntry, fn func(p *PluginInventoryEntry, version string, values []string)) error {
+	if len(plugins) == 0 {
+		return nil
+	}
+	var tableCount int
+	err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?;", table).Scan(&tableCount)
+	if err != nil || tableCount == 0 {
+		return err
+	}
+	var vendorColumnCount int
+	err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM pragma_table_info(?) WHERE name='Vendor';", table).Scan(&vendorColumnCount)
+	if err != nil {
+		return err
+	}
+	vendorColumns := "Vendor,Publisher"
+	if vendorColumnCount == 0 {
+		binaryColumn := "(SELECT b.%[1]s FROM PluginBinaries b WHERE b.PluginName=%[2]s.PluginName AND b.Target=%[2]s.Target AND b.Version=%[2]s.Version LIMIT 1)"
+		vendorColumns = fmt.Sprintf("IFNULL(%s,''),IFNULL(%s,'')", fmt.Sprintf(binaryColumn, "Vendor", table), fmt.Sprintf(binaryColumn, "Publisher", table))
+	}
+
+	pluginsByID := make(map[string]*PluginInventoryEntry)
+	var names []string
+	for _, p := range plugins {
+		id := pluginVersionRowID(p.Vendor, p.Publisher, catalog.PluginNameTarget(p.Name, p.Target))
+		if _, exists := pluginsByID[id]; !exists {
+			names = append(names, p.Name)
+		}
+		pluginsByID[id] = p
+	}
+
+	// Only read the rows of the plugins found, using the primary key of the table,
+	// unless there are so many plugins that reading the whole table is cheaper
+	query := &sqlQuery{}
+	if len(names) <= maxVersionDetailsQueryPlugins {
+		query.whereIn("PluginName", names)
+	}
+	selectClause := fmt.Sprintf("SELECT PluginName,Target,Version,%s,%s FROM %s", vendorColumns, strings.Join(columns, ","), table)
+	rows, err := queryInventoryDB(ctx, db, query.query(selectClause, ""), query.args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	// The targets are normalized once per distinct value rather than for every row
+	targets := make(map[string]configtypes.Target)
+	var name, target, version, vendor, publisher string
+	values := make([]string, len(columns))
+	dest := []interface{}{&name, &target, &version, &vendor, &publisher}
+	for i := range values {
+		dest = append(dest, &values[i])
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		normalizedTarget, exists := targets[target]
+		if !exists {
+			normalizedTarget = configtypes.StringToTarget(strings.ToLower(target))
+			targets[target] = normalizedTarget
+		}
+		p, exists := pluginsByID[pluginVersionRowID(vendor, publisher, catalog.PluginNameTarget(name, normalizedTarget))]
+		if !exists {
+			continue
+		}
+		// Only keep the rows of the versions matching the filter
+		if _, exists := p.Artifacts[version]; !exists {
+			continue
+		}
+		fn(p, version, append([]string{}, values...))
+	}
+	return rows.Err()
+}
+
+// pluginVersionRowID returns the ID of the plugin of a row of the tables of the plugin versions
+func pluginVersionRowID(vendor, publisher, pluginNameTarget string) string {
+	return fmt.Sprintf("%s/%s/%s", vendor, publisher, pluginNameTarget)
+}
+
+// createPluginQuery parses the filter and creates the conditions of the DB query.
+func createPluginQuery(filter *PluginInventoryFilter) (*sqlQuery, error) {
+	query := &sqlQuery{}
+
+	// If there is a filter, add its conditions to the query.
 	if filter != nil {
 		if filter.Name != "" {
-			whereClause = fmt.Sprintf("%s PluginName='%s' AND", whereClause, filter.Name)
+			if isPluginNamePattern(filter.Name) {
+				// GLOB uses the same wildcards as the shell and is case-sensitive like the plugin names
+				query.where("PluginName GLOB ?", filter.Name)
+			} else {
+				// The names are matched case-insensitively, as the inventories only contain lowercase names
+				query.where("lower(PluginName)=?", strings.ToLower(filter.Name))
+			}
+		}
+		if filter.NameRegex != "" {
+			if _, err := regexp.Compile(filter.NameRegex); err != nil {
+				return nil, errors.Wrapf(err, "invalid regular expression '%s' for the plugin name", filter.NameRegex)
+			}
+			query.where("PluginName REGEXP ?", filter.NameRegex)
 		}
 		if filter.Target != "" {
-			whereClause = fmt.Sprintf("%s Target='%s' AND", whereClause, string(filter.Target))
+			query.where("Target=?", string(filter.Target))
+		}
+		if len(filter.Targets) > 0 {
+			targets := make([]string, 0, len(filter.Targets))
+			for _, target := range filter.Targets {
+				targets = append(targets, string(target))
+			}
+			query.whereIn("Target", targets)
 		}
 		if filter.Version != "" {
 			if filter.Version == cli.VersionLatest {
@@ -221,61 +656,118 @@ func createPluginWhereClause(filter *PluginInventoryFilter) (string, error) {
 				// This implies that the query below will never be triggered.
 				// We leave it in to prepare for the time when the repositories will have a
 				// RecommendedVersion column with correct values.
-				whereClause = fmt.Sprintf("%s Version=RecommendedVersion AND", whereClause)
+				query.where("Version=RecommendedVersion")
 			} else {
 				// We want a specific version of the plugin or the plugin version that matches vMAJOR or vMAJOR.MINOR pattern
-				// In following condition, "Version Like '%s.%%'" condition should handle the cases where only major or major.minor version is specified
+				// In following condition, "Version LIKE 'VERSION.%'" condition should handle the cases where only major or major.minor version is specified
 				// e.g. If specified version is `v1` it matches with all versions like v1.MINOR.PATCH
 				//      If specified version is `v1.2` it matches will all versions like v1.2.PATCH
-				// And "Version='%s'" condition will try to match with the exact same match for the version
-				whereClause = fmt.Sprintf("%[1]s ( Version Like '%[2]s.%%' OR Version='%[2]s' ) AND", whereClause, filter.Version)
+				// And "Version=VERSION" condition will try to match with the exact same match for the version
+				query.where(`( Version LIKE ? ESCAPE '\' OR Version=? )`, escapeLikePattern(filter.Version)+".%", filter.Version)
 			}
 		}
 		if !filter.IncludeHidden {
 			// Unless we want to also get the hidden plugins, we only request the ones that are not hidden
-			whereClause = fmt.Sprintf("%s Hidden='false' AND", whereClause)
+			query.where("Hidden='false'")
 		}
 		if filter.OS != "" {
-			whereClause = fmt.Sprintf("%s OS='%s' AND", whereClause, filter.OS)
+			// Multi-arch image indexes contain the binaries of all the platforms
+			query.whereIn("OS", []string{filter.OS, distribution.ArtifactPlatformMultiArch})
 		}
-		if filter.Arch != "" {
-			whereClause = fmt.Sprintf("%s Architecture='%s' AND", whereClause, filter.Arch)
+		if fallbackArch := filter.fallbackArch(); fallbackArch != "" {
+			// The artifacts of the fallback architecture are removed from the versions having native ones once read
+			query.whereIn("Architecture", []string{filter.Arch, fallbackArch, distribution.ArtifactPlatformMultiArch})
+		} else if filter.Arch != "" {
+			query.whereIn("Architecture", []string{filter.Arch, distribution.ArtifactPlatformMultiArch})
 		}
 		if filter.Publisher != "" {
-			whereClause = fmt.Sprintf("%s Publisher='%s' AND", whereClause, filter.Publisher)
+			query.where("Publisher=?", filter.Publisher)
 		}
 		if filter.Vendor != "" {
-			whereClause = fmt.Sprintf("%s Vendor='%s' AND", whereClause, filter.Vendor)
+			query.where("Vendor=?", filter.Vendor)
 		}
-
-		if whereClause != "" {
-			// Remove the last added "AND"
-			whereClause = strings.TrimSuffix(whereClause, "AND")
-			// Add the "WHERE" keyword
-			whereClause = fmt.Sprintf("WHERE %s", whereClause)
+		for _, keyword := range strings.Fields(filter.SearchText) {
+			// LIKE is case-insensitive for ASCII characters in SQLite
+			pattern := "%" + escapeLikePattern(keyword) + "%"
+			query.where(`(PluginName LIKE ? ESCAPE '\' OR Description LIKE ? ESCAPE '\')`, pattern, pattern)
 		}
 	}
-	return whereClause, nil
+	return query, nil
+}
+
+// isPluginNamePattern returns true if the plugin name contains wildcards
+// and therefore designates a family of plugins
+func isPluginNamePattern(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+// escapeLikePattern escapes the keyword so that it is matched literally
+// within a LIKE pattern using '\' as the escape character
+func escapeLikePattern(keyword string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(keyword)
 }
 
 // extractPluginsFromRows loops through all DB rows and builds an array
 // of Discovered plugins based on the data extracted.
-func (b *SQLiteInventory) extractPluginsFromRows(rows *sql.Rows) ([]*PluginInventoryEntry, error) {
+func (b *SQLiteInventory) extractPluginsFromRows(rows *sql.Rows, filter *PluginInventoryFilter) ([]*PluginInventoryEntry, error) {
+	allPlugins := make([]*PluginInventoryEntry, 0)
+	err := b.walkPluginsFromRows(rows, filter, func(plugin *PluginInventoryEntry) error {
+		allPlugins = append(allPlugins, plugin)
+		return nil
+	})
+	return allPlugins, err
+}
+
+// walkPluginsFromRows loops through all DB rows queried with the filter and calls fn for each plugin
+// as soon as all its rows have been read.
+func (b *SQLiteInventory) walkPluginsFromRows(rows *sql.Rows, filter *PluginInventoryFilter, fn func(*PluginInventoryEntry) error) error {
+	nextRow := func() (*pluginDBRow, error) {
+		if !rows.Next() {
+			return nil, nil
+		}
+		return getPluginNextRow(rows)
+	}
+	// The DB uses relative image URIs to be future-proof.
+	// Build the full URI before creating the artifact.
+	imageURI := func(uri string) string { return b.uriPrefix + "/" + uri }
+	err := walkPluginDBRows(nextRow, imageURI, func(p *PluginInventoryEntry) error {
+		applyArchFallback(filter, p)
+		return fn(p)
+	})
+	if err != nil {
+		return err
+	}
+	return rows.Err()
+}
+
+// walkPluginDBRows loops through the rows returned by nextRow, until it returns no row, and calls fn
+// for each plugin as soon as all its rows have been read. The rows must be ordered as by pluginOrderClause.
+// The image of an artifact is built from the URI of its row with imageURI.
+func walkPluginDBRows(nextRow func() (*pluginDBRow, error), imageURI func(string) string, fn func(*PluginInventoryEntry) error) error {
 	currentPluginID := ""
 	currentVersion := ""
 	var currentPlugin *PluginInventoryEntry
-	allPlugins := make([]*PluginInventoryEntry, 0)
 	var artifactList distribution.ArtifactList
 	var artifacts distribution.Artifacts
+	// The rows of a plugin are consecutive, so the target normalization and the plugin ID
+	// are only computed when the name or target of the row changes, not for every artifact.
+	var rowName, rowTarget, pluginIDFromRow string
+	var target configtypes.Target
 
-	for rows.Next() {
-		row, err := getPluginNextRow(rows)
+	for {
+		row, err := nextRow()
 		if err != nil {
-			return allPlugins, err
+			return err
+		}
+		if row == nil {
+			break
 		}
 
-		target := configtypes.StringToTarget(strings.ToLower(row.target))
-		pluginIDFromRow := catalog.PluginNameTarget(row.name, target)
+		if currentPlugin == nil || row.name != rowName || row.target != rowTarget {
+			rowName, rowTarget = row.name, row.target
+			target = configtypes.StringToTarget(strings.ToLower(row.target))
+			pluginIDFromRow = catalog.PluginNameTarget(row.name, target)
+		}
 		if currentPluginID != pluginIDFromRow {
 			// Found a new plugin.
 			// Store the current one in the array and prepare the new one.
@@ -283,11 +775,15 @@ func (b *SQLiteInventory) extractPluginsFromRows(rows *sql.Rows) ([]*PluginInven
 				artifacts[currentVersion] = artifactList
 				artifactList = distribution.ArtifactList{}
 				currentPlugin.Artifacts = artifacts
-				allPlugins = appendPlugin(allPlugins, currentPlugin)
+				setRecommendedVersion(currentPlugin)
+				if err := fn(currentPlugin); err != nil {
+					return err
+				}
 			}
 			currentPluginID = pluginIDFromRow
 
 			hidden, _ := strconv.ParseBool(row.hidden)
+			deprecated, _ := strconv.ParseBool(row.deprecated)
 			currentPlugin = &PluginInventoryEntry{
 				Name:               row.name,
 				Target:             target,
@@ -296,6 +792,9 @@ func (b *SQLiteInventory) extractPluginsFromRows(rows *sql.Rows) ([]*PluginInven
 				Vendor:             row.vendor,
 				RecommendedVersion: row.recommendedVersion,
 				Hidden:             hidden,
+				Deprecated:         deprecated,
+				DeprecationMessage: row.deprecationMessage,
+				ReplacedBy:         row.replacedBy,
 			}
 			currentVersion = ""
 			artifacts = distribution.Artifacts{}
@@ -314,17 +813,26 @@ func (b *SQLiteInventory) extractPluginsFromRows(rows *sql.Rows) ([]*PluginInven
 			}
 			currentVersion = row.version
 		}
+		if releasedAt, ok := parseReleaseTime(row.releasedAt); ok {
+			if currentPlugin.ReleasedAt == nil {
+				currentPlugin.ReleasedAt = map[string]time.Time{}
+			}
+			currentPlugin.ReleasedAt[currentVersion] = releasedAt
+		}
 
-		// The DB uses relative URIs to be future-proof.
-		// Build the full URI before creating the artifact.
-		fullImagePath := fmt.Sprintf("%s/%s", b.uriPrefix, row.uri)
 		// Create the artifact for this row.
 		artifact := distribution.Artifact{
-			Image:  fullImagePath,
-			URI:    "",
-			Digest: row.digest,
-			OS:     row.os,
-			Arch:   row.arch,
+			Digest:    row.digest,
+			OS:        row.os,
+			Arch:      row.arch,
+			Size:      row.size,
+			Signature: row.signature,
+		}
+		if isArtifactURI(row.uri) {
+			// Local files and HTTP(S) URLs are stored as absolute URIs
+			artifact.URI = row.uri
+		} else {
+			artifact.Image = imageURI(row.uri)
 		}
 		artifactList = append(artifactList, artifact)
 	}
@@ -332,42 +840,41 @@ func (b *SQLiteInventory) extractPluginsFromRows(rows *sql.Rows) ([]*PluginInven
 	if currentPlugin != nil {
 		artifacts[currentVersion] = artifactList
 		currentPlugin.Artifacts = artifacts
-		allPlugins = appendPlugin(allPlugins, currentPlugin)
+		setRecommendedVersion(currentPlugin)
+		if err := fn(currentPlugin); err != nil {
+			return err
+		}
 	}
-	return allPlugins, rows.Err()
+	return nil
 }
 
 // getGroupsFromDB returns all the plugin groups found in the DB 'inventoryFile' that match the filter
 //
 //nolint:dupl
-func (b *SQLiteInventory) getGroupsFromDB(filter PluginGroupFilter) ([]*PluginGroup, error) {
+func (b *SQLiteInventory) getGroupsFromDB(ctx context.Context, filter PluginGroupFilter) ([]*PluginGroup, error) {
 	// Check if the inventory file exists.
 	if _, err := os.Stat(b.inventoryFile); os.IsNotExist(err) {
 		return []*PluginGroup{}, nil
 	}
 
-	db, err := sql.Open("sqlite", b.inventoryFile)
+	db, err := openInventoryDB(b.inventoryFile)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to open the DB at '%s' for groups", b.inventoryFile)
 	}
-	defer db.Close()
+	defer closeInventoryDB(db)
 
 	// Return empty data if db connection is not available
-	err = db.Ping()
+	err = db.PingContext(ctx)
 	if err != nil {
 		return []*PluginGroup{}, err
 	}
 
-	whereClause, err := createGroupWhereClause(filter)
-	if err != nil {
-		return nil, err
-	}
+	query := createGroupQuery(filter)
 
 	// Build the final query with the SELECT, WHERE and ORDER clauses.
 	// The ORDER clause is essential because the parsing algorithm of extractGroupsFromRows()
 	// assumes that ordering.
-	dbQuery := fmt.Sprintf("%s %s %s", groupSelectClause, whereClause, groupOrderClause)
-	rows, err := db.Query(dbQuery)
+	rows, err := queryInventoryDB(ctx, db, query.query(groupSelectClause, groupOrderClause), query.args...)
 	if err != nil {
 		return nil, errors.Wrapf(err, "unable to setup DB query for DB at '%s' for groups", b.inventoryFile)
 	}
@@ -376,49 +883,68 @@ func (b *SQLiteInventory) getGroupsFromDB(filter PluginGroupFilter) ([]*PluginGr
 	return b.extractGroupsFromRows(rows)
 }
 
-// createGroupWhereClause parses the filter and creates the WHERE clause for the DB query for groups.
-//
-//nolint:unparam
-func createGroupWhereClause(filter PluginGroupFilter) (string, error) {
-	var whereClause string
+// createGroupQuery parses the filter and creates the conditions of the DB query for groups.
+func createGroupQuery(filter PluginGroupFilter) *sqlQuery {
+	query := &sqlQuery{}
 
-	// If there is a filter, create a WHERE clause for the query.
+	// If there is a filter, add its conditions to the query.
 	if filter.Name != "" {
-		whereClause = fmt.Sprintf("%s GroupName='%s' AND", whereClause, filter.Name)
+		query.where("GroupName=?", filter.Name)
+	}
+	if filter.NameContains != "" {
+		query.where(`lower(GroupName) LIKE ? ESCAPE '\'`, "%"+escapeLikePattern(strings.ToLower(filter.NameContains))+"%")
 	}
 	if filter.Version != "" {
 		// We want a specific version or the version that matches vMAJOR or vMAJOR.MINOR pattern
-		// In following condition, "GroupVersion Like '%s.%%'" condition should handle the cases
+		// In following condition, "GroupVersion LIKE 'VERSION.%'" condition should handle the cases
 		// where only major or major.minor version is specified
 		// e.g. If specified version is `v1` it matches with all versions like v1.MINOR.PATCH
 		//      If specified version is `v1.2` it matches will all versions like v1.2.PATCH
-		// And "GroupVersion='%s'" condition will try to match with the exact same match for the version
-		whereClause = fmt.Sprintf("%[1]s ( GroupVersion Like '%[2]s.%%' OR GroupVersion='%[2]s' ) AND", whereClause, filter.Version)
+		// And "GroupVersion=VERSION" condition will try to match with the exact same match for the version
+		query.where(`( GroupVersion LIKE ? ESCAPE '\' OR GroupVersion=? )`, escapeLikePattern(filter.Version)+".%", filter.Version)
 	}
 	if !filter.IncludeHidden {
 		// Unless we want to also get the hidden plugins, we only request the ones that are not hidden
-		whereClause = fmt.Sprintf("%s Hidden='false' AND", whereClause)
+		query.where("Hidden='false'")
 	}
 	if filter.Publisher != "" {
-		whereClause = fmt.Sprintf("%s Publisher='%s' AND", whereClause, filter.Publisher)
+		query.where("Publisher=?", filter.Publisher)
 	}
 	if filter.Vendor != "" {
-		whereClause = fmt.Sprintf("%s Vendor='%s' AND", whereClause, filter.Vendor)
+		query.where("Vendor=?", filter.Vendor)
 	}
-
-	if whereClause != "" {
-		// Remove the last added "AND"
-		whereClause = strings.TrimSuffix(whereClause, "AND")
-		// Add the "WHERE" keyword
-		whereClause = fmt.Sprintf("WHERE %s", whereClause)
+	if filter.PluginName != "" || filter.PluginTarget != "" {
+		// Keep all the rows of the group versions which include the plugin, not only the row of the plugin
+		pluginQuery := &sqlQuery{}
+		if filter.PluginName != "" {
+			pluginQuery.where("lower(PluginName)=?", strings.ToLower(filter.PluginName))
+		}
+		if filter.PluginTarget != "" {
+			pluginQuery.where("Target=?", string(filter.PluginTarget))
+		}
+		query.where("(Vendor,Publisher,GroupName,GroupVersion) IN (SELECT Vendor,Publisher,GroupName,GroupVersion FROM PluginGroups "+pluginQuery.whereClause()+")", pluginQuery.args...)
 	}
-
-	return whereClause, nil
+	return query
 }
 
 // extractGroupsFromRows loops through all DB rows and builds an array
 // of PluginGroups based on the data extracted.
 func (b *SQLiteInventory) extractGroupsFromRows(rows *sql.Rows) ([]*PluginGroup, error) {
+	allGroups, err := extractGroupDBRows(func() (*groupDBRow, error) {
+		if !rows.Next() {
+			return nil, nil
+		}
+		return getGroupNextRow(rows)
+	})
+	if err != nil {
+		return allGroups, err
+	}
+	return allGroups, rows.Err()
+}
+
+// extractGroupDBRows loops through the rows returned by nextRow, until it returns no row, and builds
+// an array of PluginGroups based on the data extracted. The rows must be ordered as by groupOrderClause.
+func extractGroupDBRows(nextRow func() (*groupDBRow, error)) ([]*PluginGroup, error) {
 	currentGroupID := ""
 	currentVersion := ""
 	var currentGroup *PluginGroup
@@ -426,18 +952,25 @@ func (b *SQLiteInventory) extractGroupsFromRows(rows *sql.Rows) ([]*PluginGroup,
 	var versions map[string][]*PluginGroupPluginEntry
 	var pluginsOfGroup []*PluginGroupPluginEntry
 	var versionDescriptions map[string]string
+	var groupIDFromRow string
 
-	for rows.Next() {
-		row, err := getGroupNextRow(rows)
+	for {
+		row, err := nextRow()
 		if err != nil {
 			return allGroups, err
 		}
+		if row == nil {
+			break
+		}
 
 		mandatory, _ := strconv.ParseBool(row.mandatory)
-		groupIDFromRow := PluginGroupToID(&PluginGroup{
-			Vendor:    row.vendor,
-			Publisher: row.publisher,
-			Name:      row.groupName})
+		// The rows of a group are consecutive, so the group ID is only computed when the group changes
+		if currentGroup == nil || row.vendor != currentGroup.Vendor || row.publisher != currentGroup.Publisher || row.groupName != currentGroup.Name {
+			groupIDFromRow = PluginGroupToID(&PluginGroup{
+				Vendor:    row.vendor,
+				Publisher: row.publisher,
+				Name:      row.groupName})
+		}
 
 		if currentGroupID != groupIDFromRow {
 			// Found a new group.
@@ -492,7 +1025,7 @@ func (b *SQLiteInventory) extractGroupsFromRows(rows *sql.Rows) ([]*PluginGroup,
 		currentGroup.Versions = versions
 		allGroups = appendGroup(allGroups, currentGroup, versionDescriptions)
 	}
-	return allGroups, rows.Err()
+	return allGroups, nil
 }
 
 // getPluginNextRow simply extracts the next row of data from the DB.
@@ -513,6 +1046,12 @@ func getPluginNextRow(rows *sql.Rows) (*pluginDBRow, error) {
 		&row.arch,
 		&row.digest,
 		&row.uri,
+		&row.deprecated,
+		&row.deprecationMessage,
+		&row.replacedBy,
+		&row.size,
+		&row.signature,
+		&row.releasedAt,
 	)
 	return &row, err
 }
@@ -537,9 +1076,9 @@ func getGroupNextRow(rows *sql.Rows) (*groupDBRow, error) {
 	return &row, err
 }
 
-// appendPlugin appends a PluginInventoryEntry to the specified array.
-// This function needs to be used to do post-processing on the new plugin before storing it.
-func appendPlugin(allPlugins []*PluginInventoryEntry, plugin *PluginInventoryEntry) []*PluginInventoryEntry {
+// setRecommendedVersion sets the recommended version of a plugin once all its rows have been read,
+// when the database does not provide it.
+func setRecommendedVersion(plugin *PluginInventoryEntry) {
 	// Now that we are done gathering the information for the plugin
 	// we need to compute the recommendedVersion if it wasn't provided
 	// by the database
@@ -553,8 +1092,6 @@ func appendPlugin(allPlugins []*PluginInventoryEntry, plugin *PluginInventoryEnt
 		}
 		plugin.RecommendedVersion = versions[len(versions)-1]
 	}
-	allPlugins = append(allPlugins, plugin)
-	return allPlugins
 }
 
 // appendGroup appends a PluginGroup to the specified array.
@@ -581,14 +1118,16 @@ func appendGroup(allGroups []*PluginGroup, group *PluginGroup, versionDesc map[s
 
 // CreateSchema creates table schemas to the provided database.
 // returns error if table creation fails for any reason
-func (b *SQLiteInventory) CreateSchema() error {
-	db, err := sql.Open("sqlite", b.inventoryFile)
+func (b *SQLiteInventory) CreateSchema(ctx context.Context) error {
+	db, err := openInventoryDB(b.inventoryFile)
 	if err != nil {
 		return errors.Wrapf(err, "failed to open the DB at '%s'", b.inventoryFile)
 	}
-	defer db.Close()
+	defer closeInventoryDB(db)
 
-	_, err = db.Exec(CreateTablesSchema)
+	err = inTransaction(ctx, db, func(tx *sql.Tx) error {
+		return migrateSchema(ctx, tx)
+	})
 	if err != nil {
 		return errors.Wrap(err, "error while creating tables to the database")
 	}
@@ -597,13 +1136,41 @@ func (b *SQLiteInventory) CreateSchema() error {
 }
 
 // InsertPlugin inserts plugin to the inventory
-func (b *SQLiteInventory) InsertPlugin(pluginInventoryEntry *PluginInventoryEntry) error {
-	db, err := sql.Open("sqlite", b.inventoryFile)
+func (b *SQLiteInventory) InsertPlugin(ctx context.Context, pluginInventoryEntry *PluginInventoryEntry) error {
+	return b.InsertPlugins(ctx, []*PluginInventoryEntry{pluginInventoryEntry})
+}
+
+// InsertPlugins inserts plugins to the inventory in a single transaction:
+// either all the plugins are inserted or none of them are
+func (b *SQLiteInventory) InsertPlugins(ctx context.Context, pluginInventoryEntries []*PluginInventoryEntry) error {
+	db, err := openInventoryDB(b.inventoryFile)
 	if err != nil {
 		return errors.Wrapf(err, "failed to open the DB from '%s' file", b.inventoryFile)
 	}
-	defer db.Close()
+	defer closeInventoryDB(db)
+
+	return inTransaction(ctx, db, func(tx *sql.Tx) error {
+		// The inventories created before the last migration of the schema are missing some of the columns
+		if err := migrateSchema(ctx, tx); err != nil {
+			return err
+		}
+		stmt, err := tx.PrepareContext(ctx, "INSERT INTO PluginBinaries VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?);")
+		if err != nil {
+			return errors.Wrap(err, "unable to insert plugin rows")
+		}
+		defer stmt.Close()
+
+		for _, pluginInventoryEntry := range pluginInventoryEntries {
+			if err := insertPlugin(ctx, tx, stmt, pluginInventoryEntry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
 
+// insertPlugin inserts the rows of the plugin using the prepared insertion statement
+func insertPlugin(ctx context.Context, tx *sql.Tx, stmt *sql.Stmt, pluginInventoryEntry *PluginInventoryEntry) error {
 	for version, artifacts := range pluginInventoryEntry.Artifacts {
 		for _, a := range artifacts {
 			row := pluginDBRow{
@@ -619,51 +1186,156 @@ func (b *SQLiteInventory) InsertPlugin(pluginInventoryEntry *PluginInventoryEntr
 				arch:               a.Arch,
 				digest:             a.Digest,
 				uri:                a.Image,
+				deprecated:         strconv.FormatBool(pluginInventoryEntry.Deprecated),
+				deprecationMessage: pluginInventoryEntry.DeprecationMessage,
+				replacedBy:         pluginInventoryEntry.ReplacedBy,
+				size:               a.Size,
+				signature:          a.Signature,
+				releasedAt:         formatReleaseTime(pluginInventoryEntry.ReleasedAt[version]),
+			}
+			if row.uri == "" {
+				row.uri = a.URI
 			}
 
-			_, err = db.Exec("INSERT INTO PluginBinaries VALUES(?,?,?,?,?,?,?,?,?,?,?,?);", row.name, row.target, row.recommendedVersion, row.version, row.hidden, row.description, row.publisher, row.vendor, row.os, row.arch, row.digest, row.uri)
+			_, err := stmt.ExecContext(ctx, row.name, row.target, row.recommendedVersion, row.version, row.hidden, row.description, row.publisher, row.vendor, row.os, row.arch, row.digest, row.uri, row.deprecated, row.deprecationMessage, row.replacedBy, row.size, row.signature, row.releasedAt)
 			if err != nil {
 				return errors.Wrapf(err, "unable to insert plugin row %v", row)
 			}
 
 			// Write sql statement logs if required
-			writeSQLStatementLogs(fmt.Sprintf("INSERT INTO PluginBinaries VALUES(%v,%v,%v,%v,%v,%v,%v,%v,%v,%v,%v,%v);\n", row.name, row.target, row.recommendedVersion, row.version, row.hidden, row.description, row.publisher, row.vendor, row.os, row.arch, row.digest, row.uri))
+			writeSQLStatementLogs(fmt.Sprintf("INSERT INTO PluginBinaries VALUES(%v,%v,%v,%v,%v,%v,%v,%v,%v,%v,%v,%v,%v,%v,%v,%v,%v,%v);\n", row.name, row.target, row.recommendedVersion, row.version, row.hidden, row.description, row.publisher, row.vendor, row.os, row.arch, row.digest, row.uri, row.deprecated, row.deprecationMessage, row.replacedBy, row.size, row.signature, row.releasedAt))
 		}
 	}
+	return insertPluginVersionDetails(ctx, tx, pluginInventoryEntry)
+}
+
+// insertPluginVersionDetails inserts the release notes, the minimum CLI versions and the licenses of the plugin versions
+// to the inventory
+func insertPluginVersionDetails(ctx context.Context, tx *sql.Tx, pluginInventoryEntry *PluginInventoryEntry) error {
+	if err := insertPluginReleaseNotes(ctx, tx, pluginInventoryEntry); err != nil {
+		return err
+	}
+	if err := insertPluginMinCLIVersions(ctx, tx, pluginInventoryEntry); err != nil {
+		return err
+	}
+	return insertPluginLicenses(ctx, tx, pluginInventoryEntry)
+}
+
+// insertPluginReleaseNotes inserts the release notes of the plugin versions to the inventory
+// replacing the existing release notes of the same versions.
+// The Vendor and Publisher columns are added by the migration of the schema done before inserting plugins.
+func insertPluginReleaseNotes(ctx context.Context, tx *sql.Tx, pluginInventoryEntry *PluginInventoryEntry) error {
+	for version, releaseNotes := range pluginInventoryEntry.ReleaseNotes {
+		_, err := tx.ExecContext(ctx, "INSERT OR REPLACE INTO PluginReleaseNotes VALUES(?,?,?,?,?,?,?);", pluginInventoryEntry.Name, string(pluginInventoryEntry.Target), version, releaseNotes.ChangelogURL, releaseNotes.Notes, pluginInventoryEntry.Vendor, pluginInventoryEntry.Publisher)
+		if err != nil {
+			return errors.Wrapf(err, "unable to insert the release notes of plugin '%s' version '%s'", pluginInventoryEntry.Name, version)
+		}
+
+		// Write sql statement logs if required
+		writeSQLStatementLogs(fmt.Sprintf("INSERT OR REPLACE INTO PluginReleaseNotes VALUES(%v,%v,%v,%v,%v,%v,%v);\n", pluginInventoryEntry.Name, pluginInventoryEntry.Target, version, releaseNotes.ChangelogURL, releaseNotes.Notes, pluginInventoryEntry.Vendor, pluginInventoryEntry.Publisher))
+	}
+	return nil
+}
+
+// insertPluginMinCLIVersions inserts the minimum CLI versions required by the plugin versions to the inventory
+// replacing the existing minimum CLI versions of the same versions
+func insertPluginMinCLIVersions(ctx context.Context, tx *sql.Tx, pluginInventoryEntry *PluginInventoryEntry) error {
+	if len(pluginInventoryEntry.MinCLIVersions) == 0 {
+		return nil
+	}
+
+	// The inventories created before the minimum CLI versions were supported have no PluginCompatibility table
+	if _, err := tx.ExecContext(ctx, CreateTablesSchema); err != nil {
+		return errors.Wrap(err, "error while creating tables to the database")
+	}
+
+	for version, minCLIVersion := range pluginInventoryEntry.MinCLIVersions {
+		_, err := tx.ExecContext(ctx, "INSERT OR REPLACE INTO PluginCompatibility VALUES(?,?,?,?);", pluginInventoryEntry.Name, string(pluginInventoryEntry.Target), version, minCLIVersion)
+		if err != nil {
+			return errors.Wrapf(err, "unable to insert the minimum CLI version of plugin '%s' version '%s'", pluginInventoryEntry.Name, version)
+		}
+
+		// Write sql statement logs if required
+		writeSQLStatementLogs(fmt.Sprintf("INSERT OR REPLACE INTO PluginCompatibility VALUES(%v,%v,%v,%v);\n", pluginInventoryEntry.Name, pluginInventoryEntry.Target, version, minCLIVersion))
+	}
+	return nil
+}
+
+// insertPluginLicenses inserts the licenses of the plugin versions to the inventory
+// replacing the existing licenses of the same versions.
+// The PluginLicenses table is created by the migration of the schema done before inserting plugins.
+func insertPluginLicenses(ctx context.Context, tx *sql.Tx, pluginInventoryEntry *PluginInventoryEntry) error {
+	for version, license := range pluginInventoryEntry.Licenses {
+		_, err := tx.ExecContext(ctx, "INSERT OR REPLACE INTO PluginLicenses VALUES(?,?,?,?,?);", pluginInventoryEntry.Name, string(pluginInventoryEntry.Target), version, license.License, license.SBOMURI)
+		if err != nil {
+			return errors.Wrapf(err, "unable to insert the license of plugin '%s' version '%s'", pluginInventoryEntry.Name, version)
+		}
+
+		// Write sql statement logs if required
+		writeSQLStatementLogs(fmt.Sprintf("INSERT OR REPLACE INTO PluginLicenses VALUES(%v,%v,%v,%v,%v);\n", pluginInventoryEntry.Name, pluginInventoryEntry.Target, version, license.License, license.SBOMURI))
+	}
 	return nil
 }
 
 // InsertPluginGroup inserts plugin-group to the inventory
 // specifying override will delete the existing plugin-group and add new one
-func (b *SQLiteInventory) InsertPluginGroup(pg *PluginGroup, override bool) error {
-	db, err := sql.Open("sqlite", b.inventoryFile)
+func (b *SQLiteInventory) InsertPluginGroup(ctx context.Context, pg *PluginGroup, override bool) error {
+	db, err := openInventoryDB(b.inventoryFile)
 	if err != nil {
 		return errors.Wrapf(err, "failed to open the DB from '%s' file", b.inventoryFile)
 	}
-	defer db.Close()
+	defer closeInventoryDB(db)
 
+	rows, err := pluginGroupDBRows(ctx, b, pg)
+	if err != nil {
+		return err
+	}
+
+	return inTransaction(ctx, db, func(tx *sql.Tx) error {
+		if override {
+			for version := range pg.Versions {
+				_, err := tx.ExecContext(ctx, "DELETE FROM PluginGroups WHERE GroupName = ? AND Publisher = ? AND Vendor = ? AND GroupVersion = ?;", pg.Name, pg.Publisher, pg.Vendor, version)
+				if err != nil {
+					return errors.Wrapf(err, "unable to delete plugin-group version: '%s:%s'", PluginGroupToID(pg), version)
+				}
+				// Write sql statement logs if required
+				writeSQLStatementLogs(fmt.Sprintf("DELETE FROM PluginGroups WHERE GroupName = %s AND Publisher = %s AND Vendor = %s AND GroupVersion = %s;", pg.Name, pg.Publisher, pg.Vendor, version))
+			}
+		}
+
+		stmt, err := tx.PrepareContext(ctx, "INSERT INTO PluginGroups VALUES(?,?,?,?,?,?,?,?,?,?);")
+		if err != nil {
+			return errors.Wrap(err, "unable to insert plugin-group rows")
+		}
+		defer stmt.Close()
+
+		for _, row := range rows {
+			_, err = stmt.ExecContext(ctx, row.vendor, row.publisher, row.groupName, row.groupVersion, row.description, row.pluginName, row.target, row.pluginVersion, row.mandatory, row.hidden)
+			if err != nil {
+				return errors.Wrapf(err, "unable to insert plugin-group row %v", row)
+			}
+			// Write sql statement logs if required
+			writeSQLStatementLogs(fmt.Sprintf("INSERT INTO PluginGroups VALUES(%v,%v,%v,%v,%v,%v,%v,%v,%v,%v);", row.vendor, row.publisher, row.groupName, row.groupVersion, row.description, row.pluginName, row.target, row.pluginVersion, row.mandatory, row.hidden))
+		}
+		return nil
+	})
+}
+
+// pluginGroupDBRows returns the rows of the plugin-group to insert to the inventory, after verifying
+// that its plugins exist in the inventory, so that the plugin-group is either fully inserted or not at all
+func pluginGroupDBRows(ctx context.Context, inventory PluginInventory, pg *PluginGroup) ([]groupDBRow, error) {
 	description := pg.Description
 	if description == "" {
 		// A description is required unless the plugin already exists in the DB. Let's check.
-		existingGroup, err := b.GetPluginGroups(PluginGroupFilter{Vendor: pg.Vendor, Publisher: pg.Publisher, Name: pg.Name})
+		existingGroup, err := inventory.GetPluginGroups(ctx, PluginGroupFilter{Vendor: pg.Vendor, Publisher: pg.Publisher, Name: pg.Name})
 		if err != nil || len(existingGroup) == 0 {
-			return fmt.Errorf("a description is required when creating a brand new plugin group")
+			return nil, fmt.Errorf("a description is required when creating a brand new plugin group")
 		}
 		// Re-use the same description
 		description = existingGroup[0].Description
 	}
 
-	if override {
-		for version := range pg.Versions {
-			_, err = db.Exec("DELETE FROM PluginGroups WHERE GroupName = ? AND Publisher = ? AND Vendor = ? AND GroupVersion = ?;", pg.Name, pg.Publisher, pg.Vendor, version)
-			if err != nil {
-				return errors.Wrapf(err, "unable to delete plugin-group version: '%s:%s'", PluginGroupToID(pg), version)
-			}
-			// Write sql statement logs if required
-			writeSQLStatementLogs(fmt.Sprintf("DELETE FROM PluginGroups WHERE GroupName = %s AND Publisher = %s AND Vendor = %s AND GroupVersion = %s;", pg.Name, pg.Publisher, pg.Vendor, version))
-		}
-	}
-
+	var rows []groupDBRow
 	allowHiddenPlugins, _ := strconv.ParseBool(os.Getenv(constants.ConfigVariableIncludeDeactivatedPluginsForTesting))
 	for version, plugins := range pg.Versions {
 		for _, pi := range plugins {
@@ -674,15 +1346,15 @@ func (b *SQLiteInventory) InsertPluginGroup(pg *PluginGroup, override bool) erro
 			if !skipPGVerification {
 				// Verify that the plugin exists in the database before inserting it to the PluginGroup table.
 				// Allow including hidden plugins if the TANZU_CLI_INCLUDE_DEACTIVATED_PLUGINS_TEST_ONLY is properly set.
-				pie, err := b.GetPlugins(&PluginInventoryFilter{Name: pi.Name, Target: pi.Target, Version: pi.Version, IncludeHidden: allowHiddenPlugins})
+				pie, err := inventory.GetPlugins(ctx, &PluginInventoryFilter{Name: pi.Name, Target: pi.Target, Version: pi.Version, IncludeHidden: allowHiddenPlugins})
 				if err != nil {
-					return errors.Wrap(err, "error while verifying existence of the plugin in the database")
+					return nil, errors.Wrap(err, "error while verifying existence of the plugin in the database")
 				} else if len(pie) == 0 {
-					return errors.Errorf("specified plugin 'name:%s', 'target:%s', 'version:%s' is not present in the database", pi.Name, pi.Target, pi.Version)
+					return nil, errors.Errorf("specified plugin 'name:%s', 'target:%s', 'version:%s' is not present in the database", pi.Name, pi.Target, pi.Version)
 				}
 			}
 
-			row := groupDBRow{
+			rows = append(rows, groupDBRow{
 				vendor:        pg.Vendor,
 				publisher:     pg.Publisher,
 				groupName:     pg.Name,
@@ -693,28 +1365,22 @@ func (b *SQLiteInventory) InsertPluginGroup(pg *PluginGroup, override bool) erro
 				pluginVersion: pi.Version,
 				mandatory:     strconv.FormatBool(pi.Mandatory),
 				hidden:        strconv.FormatBool(pg.Hidden),
-			}
-			_, err = db.Exec("INSERT INTO PluginGroups VALUES(?,?,?,?,?,?,?,?,?,?);", row.vendor, row.publisher, row.groupName, row.groupVersion, row.description, row.pluginName, row.target, row.pluginVersion, row.mandatory, row.hidden)
-			if err != nil {
-				return errors.Wrapf(err, "unable to insert plugin-group row %v", row)
-			}
-			// Write sql statement logs if required
-			writeSQLStatementLogs(fmt.Sprintf("INSERT INTO PluginGroups VALUES(%v,%v,%v,%v,%v,%v,%v,%v,%v,%v);", row.vendor, row.publisher, row.groupName, row.groupVersion, row.description, row.pluginName, row.target, row.pluginVersion, row.mandatory, row.hidden))
+			})
 		}
 	}
-	return nil
+	return rows, nil
 }
 
 // UpdatePluginActivationState updates plugin metadata to activate or deactivate plugin
-func (b *SQLiteInventory) UpdatePluginActivationState(pluginInventoryEntry *PluginInventoryEntry) error {
-	db, err := sql.Open("sqlite", b.inventoryFile)
+func (b *SQLiteInventory) UpdatePluginActivationState(ctx context.Context, pluginInventoryEntry *PluginInventoryEntry) error {
+	db, err := openInventoryDB(b.inventoryFile)
 	if err != nil {
 		return errors.Wrapf(err, "failed to open the DB from '%s' file", b.inventoryFile)
 	}
-	defer db.Close()
+	defer closeInventoryDB(db)
 
 	for version := range pluginInventoryEntry.Artifacts {
-		result, err := db.Exec("UPDATE PluginBinaries SET hidden = ? WHERE PluginName = ? AND Target = ? AND Version = ? AND Publisher = ? AND Vendor = ? ;", strconv.FormatBool(pluginInventoryEntry.Hidden), pluginInventoryEntry.Name, string(pluginInventoryEntry.Target), version, pluginInventoryEntry.Publisher, pluginInventoryEntry.Vendor)
+		result, err := db.ExecContext(ctx, "UPDATE PluginBinaries SET hidden = ? WHERE PluginName = ? AND Target = ? AND Version = ? AND Publisher = ? AND Vendor = ? ;", strconv.FormatBool(pluginInventoryEntry.Hidden), pluginInventoryEntry.Name, string(pluginInventoryEntry.Target), version, pluginInventoryEntry.Publisher, pluginInventoryEntry.Vendor)
 		if err != nil {
 			return errors.Wrapf(err, "unable to update plugin %v_%v", pluginInventoryEntry.Name, string(pluginInventoryEntry.Target))
 		}
@@ -729,15 +1395,15 @@ func (b *SQLiteInventory) UpdatePluginActivationState(pluginInventoryEntry *Plug
 	return nil
 }
 
-func (b *SQLiteInventory) UpdatePluginGroupActivationState(pg *PluginGroup) error {
-	db, err := sql.Open("sqlite", b.inventoryFile)
+func (b *SQLiteInventory) UpdatePluginGroupActivationState(ctx context.Context, pg *PluginGroup) error {
+	db, err := openInventoryDB(b.inventoryFile)
 	if err != nil {
 		return errors.Wrapf(err, "failed to open the DB from '%s' file", b.inventoryFile)
 	}
-	defer db.Close()
+	defer closeInventoryDB(db)
 
 	for version := range pg.Versions {
-		result, err := db.Exec("UPDATE PluginGroups SET hidden = ? WHERE GroupName = ? AND Publisher = ? AND Vendor = ? AND GroupVersion = ? ;", strconv.FormatBool(pg.Hidden), pg.Name, pg.Publisher, pg.Vendor, version)
+		result, err := db.ExecContext(ctx, "UPDATE PluginGroups SET hidden = ? WHERE GroupName = ? AND Publisher = ? AND Vendor = ? AND GroupVersion = ? ;", strconv.FormatBool(pg.Hidden), pg.Name, pg.Publisher, pg.Vendor, version)
 		if err != nil {
 			return errors.Wrapf(err, "unable to update plugin-group '%s:%s'", PluginGroupToID(pg), version)
 		}
@@ -752,9 +1418,254 @@ func (b *SQLiteInventory) UpdatePluginGroupActivationState(pg *PluginGroup) erro
 	return nil
 }
 
+// DeletePluginVersion deletes a version of a plugin from the DB, along with its release notes
+// and minimum CLI version, in a single transaction
+func (b *SQLiteInventory) DeletePluginVersion(ctx context.Context, name string, target configtypes.Target, version string) error {
+	if version == "" {
+		return errors.Errorf("no version specified to delete for plugin '%s'", PluginToID(&PluginInventoryEntry{Name: name, Target: target}))
+	}
+	return b.deletePlugin(ctx, name, target, version)
+}
+
+// DeletePlugin deletes all the versions of a plugin from the DB, along with their release notes
+// and minimum CLI versions, in a single transaction
+func (b *SQLiteInventory) DeletePlugin(ctx context.Context, name string, target configtypes.Target) error {
+	return b.deletePlugin(ctx, name, target, "")
+}
+
+// Export writes all the plugins and plugin-groups of the inventory to the writer in the provided format.
+func (b *SQLiteInventory) Export(ctx context.Context, w io.Writer, format ExportFormat) error {
+	return exportInventory(ctx, b, w, format)
+}
+
+// Validate returns all the problems found in the plugins and plugin-groups of the inventory.
+func (b *SQLiteInventory) Validate(ctx context.Context) error {
+	return validateInventory(ctx, b)
+}
+
+// deletePlugin deletes the version of the plugin from the DB, or all its versions if the version is empty.
+// The plugins which are part of a plugin-group are not deleted so that the plugin-groups remain valid.
+func (b *SQLiteInventory) deletePlugin(ctx context.Context, name string, target configtypes.Target, version string) error {
+	pluginID := PluginToID(&PluginInventoryEntry{Name: name, Target: target})
+	if version != "" {
+		pluginID = fmt.Sprintf("%s:%s", pluginID, version)
+	}
+
+	db, err := openInventoryDB(b.inventoryFile)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open the DB from '%s' file", b.inventoryFile)
+	}
+	defer closeInventoryDB(db)
+
+	// The PluginBinaries, PluginReleaseNotes, PluginCompatibility and PluginLicenses tables share these columns
+	condition := "PluginName = ? AND Target = ?"
+	args := []interface{}{name, string(target)}
+	if version != "" {
+		condition += " AND Version = ?"
+		args = append(args, version)
+	}
+
+	return inTransaction(ctx, db, func(tx *sql.Tx) error {
+		// The inventories created before licenses were supported have no PluginLicenses table
+		if err := migrateSchema(ctx, tx); err != nil {
+			return err
+		}
+
+		groups, err := queryPluginGroupIDs(ctx, tx, strings.Replace(condition, "Version", "PluginVersion", 1), args...)
+		if err != nil {
+			return errors.Wrapf(err, "unable to delete plugin '%s'", pluginID)
+		}
+		if len(groups) > 0 {
+			return errors.Errorf("unable to delete plugin '%s' because it is part of the plugin-groups %v, remove it from the plugin-groups first", pluginID, groups)
+		}
+
+		result, err := tx.ExecContext(ctx, "DELETE FROM PluginBinaries WHERE "+condition+";", args...)
+		if err != nil {
+			return errors.Wrapf(err, "unable to delete plugin '%s'", pluginID)
+		}
+		if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+			return errors.Errorf("unable to delete plugin '%s' because it is not found in the inventory", pluginID)
+		}
+		for _, table := range []string{"PluginReleaseNotes", "PluginCompatibility", "PluginLicenses"} {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE "+condition+";", args...); err != nil {
+				return errors.Wrapf(err, "unable to delete plugin '%s' from %s", pluginID, table)
+			}
+		}
+		if version != "" {
+			// The recommended version is found again among the remaining versions if it was deleted
+			_, err = tx.ExecContext(ctx, "UPDATE PluginBinaries SET RecommendedVersion = '' WHERE PluginName = ? AND Target = ? AND RecommendedVersion = ?;", name, string(target), version)
+			if err != nil {
+				return errors.Wrapf(err, "unable to reset the recommended version of plugin '%s'", pluginID)
+			}
+		}
+
+		// Write sql statement logs if required
+		writeSQLStatementLogs(fmt.Sprintf("DELETE FROM PluginBinaries WHERE PluginName = %v AND Target = %v AND Version = %v ;\n", name, string(target), version))
+		return nil
+	})
+}
+
+// queryPluginGroupIDs returns the IDs of the plugin-group versions containing the plugins matching the condition
+func queryPluginGroupIDs(ctx context.Context, tx *sql.Tx, condition string, args ...interface{}) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT DISTINCT Vendor,Publisher,GroupName,GroupVersion FROM PluginGroups WHERE "+condition+" ORDER BY Vendor,Publisher,GroupName,GroupVersion;", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var groups []string
+	for rows.Next() {
+		var pg PluginGroup
+		var version string
+		if err := rows.Scan(&pg.Vendor, &pg.Publisher, &pg.Name, &version); err != nil {
+			return nil, err
+		}
+		groups = append(groups, fmt.Sprintf("%s:%s", PluginGroupToID(&pg), version))
+	}
+	return groups, rows.Err()
+}
+
+// inventoryDBBusyTimeoutMillis is how long, in milliseconds, an operation waits for the lock
+// of the inventory DB held by another connection or process before failing with "database is locked"
+const inventoryDBBusyTimeoutMillis = 5000
+
+// pooledDB is a DB shared by the operations of the process using the same inventory file
+type pooledDB struct {
+	db   *sql.DB
+	refs int
+	// statements are the statements prepared for the DB, by query
+	statements map[string]*sql.Stmt
+}
+
+var (
+	// pooledDBs are the DBs currently in use, by inventory file
+	pooledDBs     = map[string]*pooledDB{}
+	pooledDBsLock sync.Mutex
+)
+
+// openInventoryDB returns the DB of the inventory file, which is shared with the concurrent
+// operations of the process and must be released with closeInventoryDB().
+// The DB waits for the locks held by other processes. The DBs of the local cache use the
+// write-ahead log (WAL) journal mode, so that readers do not block the writer nor each other.
+// The other DBs, e.g. the ones built and published by the builder plugin, keep their journal
+// mode, as the WAL journal mode is persisted in the DB file and requires a writable directory
+// to read it.
+func openInventoryDB(inventoryFile string) (*sql.DB, error) {
+	pooledDBsLock.Lock()
+	defer pooledDBsLock.Unlock()
+
+	inventoryFile = filepath.Clean(inventoryFile)
+	if p, exists := pooledDBs[inventoryFile]; exists {
+		p.refs++
+		return p.db, nil
+	}
+	params := url.Values{}
+	params.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", inventoryDBBusyTimeoutMillis))
+	if isCachedInventoryFile(inventoryFile) {
+		params.Add("_pragma", "journal_mode(WAL)")
+	}
+	db, err := sql.Open("sqlite", inventoryFile+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	pooledDBs[inventoryFile] = &pooledDB{db: db, refs: 1}
+	return db, nil
+}
+
+// isCachedInventoryFile returns true if the inventory file is in the plugin inventory
+// directory of the local cache
+func isCachedInventoryFile(inventoryFile string) bool {
+	cacheDir := filepath.Clean(filepath.Join(common.DefaultCacheDir, common.PluginInventoryDirName))
+	return strings.HasPrefix(filepath.Clean(inventoryFile), cacheDir+string(filepath.Separator))
+}
+
+// closeInventoryDB releases a DB returned by openInventoryDB(). The DB is closed once
+// released by all the operations using it, which checkpoints its write-ahead log into
+// the inventory file, so that the file can be copied or published on its own.
+func closeInventoryDB(db *sql.DB) {
+	pooledDBsLock.Lock()
+	defer pooledDBsLock.Unlock()
+
+	for inventoryFile, p := range pooledDBs {
+		if p.db != db {
+			continue
+		}
+		p.refs--
+		if p.refs > 0 {
+			return
+		}
+		delete(pooledDBs, inventoryFile)
+		for _, stmt := range p.statements {
+			_ = stmt.Close()
+		}
+		break
+	}
+	_ = db.Close()
+}
+
+// findPooledDB returns the pooled DB of a DB returned by openInventoryDB(), or nil.
+// The caller must hold pooledDBsLock.
+func findPooledDB(db *sql.DB) *pooledDB {
+	for _, p := range pooledDBs {
+		if p.db == db {
+			return p
+		}
+	}
+	return nil
+}
+
+// inTransaction runs the function in a transaction of the database, which is committed
+// if the function succeeds and rolled back otherwise.  Besides making a series of
+// modifications atomic, this is much faster than letting SQLite commit each statement
+// on its own, as every commit syncs the database file to disk.
+func inTransaction(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "unable to start a database transaction")
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "unable to commit the database transaction")
+	}
+	return nil
+}
+
 func writeSQLStatementLogs(statements string) {
 	logFile := os.Getenv("SQL_STATEMENTS_LOG_FILE")
 	if logFile != "" {
 		_ = utils.AppendFile(logFile, []byte(statements))
 	}
 }
+
+// isArtifactURI returns true if the uri stored in the DB points to a local
+// file or an HTTP(S) URL instead of an OCI image
+func isArtifactURI(uri string) bool {
+	for _, scheme := range []string{"file://", "http://", "https://"} {
+		if strings.HasPrefix(uri, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatReleaseTime returns the value stored in the ReleasedAt column for the release time of a
+// plugin version, which is empty when the time is unknown
+func formatReleaseTime(releasedAt time.Time) string {
+	if releasedAt.IsZero() {
+		return ""
+	}
+	return releasedAt.UTC().Format(time.RFC3339)
+}
+
+// parseReleaseTime parses the value of the ReleasedAt column; the empty and invalid values
+// stand for an unknown release time
+func parseReleaseTime(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	releasedAt, err := time.Parse(time.RFC3339, value)
+	return releasedAt, err == nil
+}
@@ -0,0 +1,317 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugininventory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	// The large inventory has 1000 plugins of 25 versions published for 4 os-arch, i.e. 100k rows
+	largeInventoryPlugins  = 1000
+	largeInventoryVersions = 25
+	// largeInventoryGroups is the number of groups of the large inventory, each with 10 versions of 20 plugins
+	largeInventoryGroups = 50
+)
+
+var largeInventoryOSArch = [][2]string{{"darwin", "amd64"}, {"darwin", "arm64"}, {"linux", "amd64"}, {"windows", "amd64"}}
+
+// largeInventorySeed returns the content of an inventory with as many rows as the
+// large production inventories, to measure the queries used by plugin search and sync
+func largeInventorySeed() *InventorySeed {
+	seed := &InventorySeed{}
+	targets := []types.Target{types.TargetK8s, types.TargetTMC, types.TargetGlobal}
+	for i := 0; i < largeInventoryPlugins; i++ {
+		p := &PluginInventoryEntry{
+			Name:               fmt.Sprintf("plugin-%04d", i),
+			Target:             targets[i%len(targets)],
+			Description:        "Plugin of the large inventory",
+			Publisher:          "tkg",
+			Vendor:             "vmware",
+			RecommendedVersion: fmt.Sprintf("v0.%d.0", largeInventoryVersions-1),
+			Artifacts:          distribution.Artifacts{},
+			ReleaseNotes:       map[string]PluginReleaseNotes{},
+		}
+		for v := 0; v < largeInventoryVersions; v++ {
+			version := fmt.Sprintf("v0.%d.0", v)
+			for _, osArch := range largeInventoryOSArch {
+				p.Artifacts[version] = append(p.Artifacts[version], distribution.Artifact{
+					Image:  fmt.Sprintf("vmware/tkg/%s/%s/%s/%s:%s", osArch[0], osArch[1], p.Target, p.Name, version),
+					Digest: "0000000000",
+					OS:     osArch[0],
+					Arch:   osArch[1],
+				})
+			}
+			p.ReleaseNotes[version] = PluginReleaseNotes{Notes: "Release notes of " + version}
+		}
+		seed.Plugins = append(seed.Plugins, p)
+	}
+	for i := 0; i < largeInventoryGroups; i++ {
+		pg := &PluginGroup{
+			Vendor:      "vmware",
+			Publisher:   "tkg",
+			Name:        fmt.Sprintf("group-%02d", i),
+			Description: "Group of the large inventory",
+			Versions:    map[string][]*PluginGroupPluginEntry{},
+		}
+		for v := 0; v < 10; v++ {
+			version := fmt.Sprintf("v1.%d.0", v)
+			for j := 0; j < 20; j++ {
+				p := seed.Plugins[(i*20+j)%largeInventoryPlugins]
+				pg.Versions[version] = append(pg.Versions[version], &PluginGroupPluginEntry{
+					PluginIdentifier: PluginIdentifier{Name: p.Name, Target: p.Target, Version: p.RecommendedVersion},
+				})
+			}
+		}
+		seed.Groups = append(seed.Groups, pg)
+	}
+	return seed
+}
+
+// createLargeInventoryDB creates the large inventory in the directory and returns the path of its DB file
+func createLargeInventoryDB(dir string) (string, error) {
+	dbFile := filepath.Join(dir, SQliteDBFileName)
+	// The durability of the writes is not needed to seed a test DB, and makes it much faster
+	if err := CreateInventoryDB(dbFile+"?_pragma=synchronous(OFF)&_pragma=journal_mode(OFF)", largeInventorySeed()); err != nil {
+		return "", err
+	}
+	return dbFile, nil
+}
+
+func setupLargeInventory(b *testing.B) PluginInventory {
+	b.Helper()
+	dbFile, err := createLargeInventoryDB(b.TempDir())
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	return NewSQLiteInventory(dbFile, "localhost:9876/tanzu-cli/plugins")
+}
+
+// The benchmarks guard the performance of the inventory queries against large inventories, e.g.
+// go test ./pkg/plugininventory/ -run XXX -bench . -benchmem
+
+func BenchmarkGetAllPlugins(b *testing.B) {
+	inventory := setupLargeInventory(b)
+	for i := 0; i < b.N; i++ {
+		plugins, err := inventory.GetAllPlugins(context.Background())
+		if err != nil || len(plugins) != largeInventoryPlugins {
+			b.Fatalf("unexpected result: %d plugins, error %v", len(plugins), err)
+		}
+	}
+}
+
+func BenchmarkWalkAllPlugins(b *testing.B) {
+	inventory := setupLargeInventory(b)
+	for i := 0; i < b.N; i++ {
+		count := 0
+		err := inventory.WalkPlugins(context.Background(), &PluginInventoryFilter{}, func(_ *PluginInventoryEntry) error {
+			count++
+			return nil
+		})
+		if err != nil || count != largeInventoryPlugins {
+			b.Fatalf("unexpected result: %d plugins, error %v", count, err)
+		}
+	}
+}
+
+func BenchmarkGetAllPluginsByPage(b *testing.B) {
+	inventory := setupLargeInventory(b)
+	for i := 0; i < b.N; i++ {
+		count := 0
+		for {
+			plugins, err := inventory.GetPluginsPage(context.Background(), &PluginInventoryFilter{}, count, walkPluginsBatchSize)
+			if err != nil {
+				b.Fatal(err)
+			}
+			count += len(plugins)
+			if len(plugins) < walkPluginsBatchSize {
+				break
+			}
+		}
+		if count != largeInventoryPlugins {
+			b.Fatalf("unexpected result: %d plugins", count)
+		}
+	}
+}
+
+func BenchmarkGetPluginsForOSArch(b *testing.B) {
+	inventory := setupLargeInventory(b)
+	for i := 0; i < b.N; i++ {
+		plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{OS: "linux", Arch: "amd64"})
+		if err != nil || len(plugins) != largeInventoryPlugins {
+			b.Fatalf("unexpected result: %d plugins, error %v", len(plugins), err)
+		}
+	}
+}
+
+func BenchmarkGetPluginsByName(b *testing.B) {
+	inventory := setupLargeInventory(b)
+	for i := 0; i < b.N; i++ {
+		plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "plugin-0500", Version: "v0.24.0", OS: "linux", Arch: "amd64"})
+		if err != nil || len(plugins) != 1 {
+			b.Fatalf("unexpected result: %d plugins, error %v", len(plugins), err)
+		}
+	}
+}
+
+func BenchmarkGetPluginsByTarget(b *testing.B) {
+	inventory := setupLargeInventory(b)
+	for i := 0; i < b.N; i++ {
+		plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Target: types.TargetTMC})
+		if err != nil || len(plugins) == 0 {
+			b.Fatalf("unexpected result: %d plugins, error %v", len(plugins), err)
+		}
+	}
+}
+
+func BenchmarkGetPluginGroupByName(b *testing.B) {
+	inventory := setupLargeInventory(b)
+	for i := 0; i < b.N; i++ {
+		groups, err := inventory.GetPluginGroups(context.Background(), PluginGroupFilter{Vendor: "vmware", Publisher: "tkg", Name: "group-25"})
+		if err != nil || len(groups) != 1 {
+			b.Fatalf("unexpected result: %d groups, error %v", len(groups), err)
+		}
+	}
+}
+
+func BenchmarkInsertPlugins(b *testing.B) {
+	// 100 plugins of the large inventory, i.e. 10k rows
+	plugins := largeInventorySeed().Plugins[:100]
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		inventory := NewSQLiteInventory(filepath.Join(b.TempDir(), SQliteDBFileName), "")
+		if err := inventory.CreateSchema(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+		if err := inventory.InsertPlugins(context.Background(), plugins); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+var _ = Describe("Querying a large plugin inventory", func() {
+	var (
+		tmpDir    string
+		dbFile    string
+		inventory PluginInventory
+	)
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp(os.TempDir(), "")
+		Expect(err).To(BeNil())
+		dbFile, err = createLargeInventoryDB(tmpDir)
+		Expect(err).To(BeNil())
+		inventory = NewSQLiteInventory(dbFile, "localhost:9876/tanzu-cli/plugins")
+	})
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	// queryPlan returns the plan of the query as chosen by SQLite, e.g. "SEARCH PluginBinaries USING INDEX ..."
+	queryPlan := func(query string, args ...interface{}) string {
+		db, err := sql.Open("sqlite", dbFile)
+		Expect(err).To(BeNil())
+		defer db.Close()
+
+		rows, err := db.Query("EXPLAIN QUERY PLAN "+query, args...)
+		Expect(err).To(BeNil())
+		defer rows.Close()
+
+		var plan []string
+		for rows.Next() {
+			var id, parent, notUsed int
+			var detail string
+			Expect(rows.Scan(&id, &parent, &notUsed, &detail)).To(Succeed())
+			plan = append(plan, detail)
+		}
+		Expect(rows.Err()).To(BeNil())
+		return strings.Join(plan, "\n")
+	}
+
+	It("should use the indexes for the plugin and group filters", func() {
+		query, err := createPluginQuery(&PluginInventoryFilter{Name: "plugin-0502", OS: "linux", Arch: "amd64"})
+		Expect(err).To(BeNil())
+		Expect(queryPlan(query.query(pluginSelectClause, pluginOrderClause), query.args...)).To(ContainSubstring("SEARCH PluginBinaries USING INDEX"))
+
+		query, err = createPluginQuery(&PluginInventoryFilter{Target: types.TargetTMC})
+		Expect(err).To(BeNil())
+		Expect(queryPlan(query.query(pluginSelectClause, pluginOrderClause), query.args...)).To(ContainSubstring("USING INDEX PluginBinariesTargetIndex"))
+
+		query, err = createPluginQuery(&PluginInventoryFilter{Name: "Plugin-0502"})
+		Expect(err).To(BeNil())
+		Expect(queryPlan(query.query(pluginSelectClause, pluginOrderClause), query.args...)).To(ContainSubstring("USING INDEX PluginBinariesLowerNameIndex"))
+
+		query, err = createPluginQuery(&PluginInventoryFilter{Vendor: "vmware"})
+		Expect(err).To(BeNil())
+		Expect(queryPlan(query.query(pluginSelectClause, pluginOrderClause), query.args...)).To(ContainSubstring("USING INDEX PluginBinariesVendorIndex"))
+
+		query, err = createPluginQuery(&PluginInventoryFilter{Publisher: "tkg"})
+		Expect(err).To(BeNil())
+		Expect(queryPlan(query.query(pluginSelectClause, pluginOrderClause), query.args...)).To(ContainSubstring("USING INDEX PluginBinariesPublisherIndex"))
+
+		groupQuery := createGroupQuery(PluginGroupFilter{Name: "group-25"})
+		Expect(queryPlan(groupQuery.query(groupSelectClause, groupOrderClause), groupQuery.args...)).To(ContainSubstring("USING INDEX PluginGroupsGroupNameIndex"))
+
+		Expect(queryPlan("SELECT PluginName,Target,Version,ChangelogURL,Notes FROM PluginReleaseNotes WHERE PluginName IN ('plugin-0502','plugin-0503')")).To(ContainSubstring("SEARCH PluginReleaseNotes USING INDEX"))
+	})
+
+	It("should return the plugins with their artifacts and release notes", func() {
+		plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "plugin-0502", OS: "linux", Arch: "amd64"})
+		Expect(err).To(BeNil())
+		Expect(len(plugins)).To(Equal(1))
+		Expect(plugins[0].Target).To(Equal(types.TargetTMC))
+		Expect(len(plugins[0].Artifacts)).To(Equal(largeInventoryVersions))
+		Expect(len(plugins[0].ReleaseNotes)).To(Equal(largeInventoryVersions))
+		Expect(plugins[0].Artifacts["v0.3.0"][0].Image).To(Equal("localhost:9876/tanzu-cli/plugins/vmware/tkg/linux/amd64/mission-control/plugin-0502:v0.3.0"))
+
+		plugins, err = inventory.GetAllPlugins(context.Background())
+		Expect(err).To(BeNil())
+		Expect(len(plugins)).To(Equal(largeInventoryPlugins))
+		for _, p := range plugins {
+			Expect(len(p.Artifacts)).To(Equal(largeInventoryVersions))
+			Expect(len(p.ReleaseNotes)).To(Equal(largeInventoryVersions))
+		}
+	})
+
+	It("should walk the same plugins as the ones returned", func() {
+		filter := &PluginInventoryFilter{Target: types.TargetTMC, OS: "linux", Arch: "amd64"}
+		plugins, err := inventory.GetPlugins(context.Background(), filter)
+		Expect(err).To(BeNil())
+
+		var walked []*PluginInventoryEntry
+		err = inventory.WalkPlugins(context.Background(), filter, func(p *PluginInventoryEntry) error {
+			walked = append(walked, p)
+			return nil
+		})
+		Expect(err).To(BeNil())
+		Expect(walked).To(Equal(plugins))
+	})
+
+	It("should stop walking the plugins at the first error", func() {
+		count := 0
+		err := inventory.WalkPlugins(context.Background(), nil, func(p *PluginInventoryEntry) error {
+			count++
+			if p.Name == "plugin-0150" {
+				return fmt.Errorf("fake error")
+			}
+			return nil
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("fake error"))
+		Expect(count).To(Equal(151))
+	})
+})
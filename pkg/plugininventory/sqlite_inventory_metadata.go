@@ -4,7 +4,9 @@
 package plugininventory
 
 import (
+	"context"
 	"database/sql"
+	"strings"
 
 	// Import the sqlite3 driver
 	_ "modernc.org/sqlite"
@@ -52,15 +54,46 @@ func (b *SQLiteInventoryMetadata) CreateInventoryMetadataDBSchema() error {
 // InsertPluginIdentifier inserts the PluginIdentifier entry to the
 // AvailablePluginBinaries table
 func (b *SQLiteInventoryMetadata) InsertPluginIdentifier(pi *PluginIdentifier) error {
+	return b.InsertPluginIdentifiers([]*PluginIdentifier{pi})
+}
+
+// InsertPluginIdentifiers inserts the PluginIdentifier entries to the
+// AvailablePluginBinaries table in a single transaction
+func (b *SQLiteInventoryMetadata) InsertPluginIdentifiers(pis []*PluginIdentifier) error {
 	db, err := sql.Open("sqlite", b.inventoryMetadataDBFile)
 	if err != nil {
 		return errors.Wrapf(err, "failed to open the DB from '%s' file", b.inventoryMetadataDBFile)
 	}
 	defer db.Close()
 
-	_, err = db.Exec("INSERT INTO AvailablePluginBinaries VALUES(?,?,?);", pi.Name, pi.Target, pi.Version)
+	return inTransaction(context.Background(), db, func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare("INSERT INTO AvailablePluginBinaries VALUES(?,?,?);")
+		if err != nil {
+			return errors.Wrap(err, "unable to insert plugin identifiers")
+		}
+		defer stmt.Close()
+
+		for _, pi := range pis {
+			if _, err := stmt.Exec(pi.Name, pi.Target, pi.Version); err != nil {
+				return errors.Wrapf(err, "unable to insert plugin identifier %v", pi)
+			}
+		}
+		return nil
+	})
+}
+
+// DeletePluginIdentifier deletes the PluginIdentifier entry from the
+// AvailablePluginBinaries table. It is not an error if the entry does not exist.
+func (b *SQLiteInventoryMetadata) DeletePluginIdentifier(pi *PluginIdentifier) error {
+	db, err := sql.Open("sqlite", b.inventoryMetadataDBFile)
 	if err != nil {
-		return errors.Wrapf(err, "unable to insert plugin identifier %v", pi)
+		return errors.Wrapf(err, "failed to open the DB from '%s' file", b.inventoryMetadataDBFile)
+	}
+	defer db.Close()
+
+	_, err = db.Exec("DELETE FROM AvailablePluginBinaries WHERE PluginName = ? AND Target = ? AND Version = ?;", pi.Name, pi.Target, pi.Version)
+	if err != nil {
+		return errors.Wrapf(err, "unable to delete plugin identifier %v", pi)
 	}
 	return nil
 }
@@ -68,39 +101,111 @@ func (b *SQLiteInventoryMetadata) InsertPluginIdentifier(pi *PluginIdentifier) e
 // InsertPluginGroupIdentifier inserts the PluginGroupIdentifier entry to the
 // AvailablePluginGroups table
 func (b *SQLiteInventoryMetadata) InsertPluginGroupIdentifier(pgi *PluginGroupIdentifier) error {
+	return b.InsertPluginGroupIdentifiers([]*PluginGroupIdentifier{pgi})
+}
+
+// InsertPluginGroupIdentifiers inserts the PluginGroupIdentifier entries to the
+// AvailablePluginGroups table in a single transaction
+func (b *SQLiteInventoryMetadata) InsertPluginGroupIdentifiers(pgis []*PluginGroupIdentifier) error {
 	db, err := sql.Open("sqlite", b.inventoryMetadataDBFile)
 	if err != nil {
 		return errors.Wrapf(err, "failed to open the DB from '%s' file", b.inventoryMetadataDBFile)
 	}
 	defer db.Close()
 
-	_, err = db.Exec("INSERT INTO AvailablePluginGroups VALUES(?,?,?,?);", pgi.Vendor, pgi.Publisher, pgi.Name, pgi.Version)
-	if err != nil {
-		return errors.Wrapf(err, "unable to insert plugin group identifier %v", pgi)
-	}
-	return nil
+	return inTransaction(context.Background(), db, func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare("INSERT INTO AvailablePluginGroups VALUES(?,?,?,?);")
+		if err != nil {
+			return errors.Wrap(err, "unable to insert plugin group identifiers")
+		}
+		defer stmt.Close()
+
+		for _, pgi := range pgis {
+			if _, err := stmt.Exec(pgi.Vendor, pgi.Publisher, pgi.Name, pgi.Version); err != nil {
+				return errors.Wrapf(err, "unable to insert plugin group identifier %v", pgi)
+			}
+		}
+		return nil
+	})
 }
 
 // MergeInventoryMetadataDatabase merges two inventory metadata database by
 // merging the content of AvailablePluginBinaries and AvailablePluginGroups tables
-func (b *SQLiteInventoryMetadata) MergeInventoryMetadataDatabase(additionalMetadataDBFilePath string) error {
+// as specified by the policy
+func (b *SQLiteInventoryMetadata) MergeInventoryMetadataDatabase(additionalMetadataDBFilePath string, policy MetadataMergePolicy) error {
+	if err := ValidateMetadataMergePolicy(policy); err != nil {
+		return err
+	}
+	if policy == MetadataMergePolicyReplace {
+		// The content of the additional database is discarded
+		return nil
+	}
+
 	db, err := sql.Open("sqlite", b.inventoryMetadataDBFile)
 	if err != nil {
 		return errors.Wrapf(err, "failed to open the DB from '%s' file", b.inventoryMetadataDBFile)
 	}
 	defer db.Close()
 
-	mergeQuery := `ATTACH ? as additionalMetadataDB;
-	INSERT OR REPLACE INTO AvailablePluginGroups SELECT Vendor,Publisher,GroupName,GroupVersion FROM additionalMetadataDB.AvailablePluginGroups;
+	// The additional database is attached to a single connection, used by all the queries
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open the DB from '%s' file", b.inventoryMetadataDBFile)
+	}
+	defer conn.Close()
+	if _, err = conn.ExecContext(ctx, "ATTACH ? as additionalMetadataDB;", additionalMetadataDBFilePath); err != nil {
+		return errors.Wrapf(err, "failed to attach the DB from '%s' file", additionalMetadataDBFilePath)
+	}
+
+	if policy == MetadataMergePolicyFailOnConflict {
+		if err := checkInventoryMetadataConflicts(ctx, conn); err != nil {
+			return err
+		}
+	}
+
+	mergeQuery := `INSERT OR REPLACE INTO AvailablePluginGroups SELECT Vendor,Publisher,GroupName,GroupVersion FROM additionalMetadataDB.AvailablePluginGroups;
 	INSERT OR REPLACE INTO AvailablePluginBinaries SELECT PluginName,Target,Version FROM additionalMetadataDB.AvailablePluginBinaries;`
 
-	_, err = db.Exec(mergeQuery, additionalMetadataDBFilePath)
+	_, err = conn.ExecContext(ctx, mergeQuery)
 	if err != nil {
 		return errors.Wrapf(err, "unable to execute the query %v", mergeQuery)
 	}
 	return nil
 }
 
+// checkInventoryMetadataConflicts returns an error listing the plugins and plugin groups which are
+// both in the inventory metadata database and in the additional database attached to the connection
+func checkInventoryMetadataConflicts(ctx context.Context, conn *sql.Conn) error {
+	conflictsQuery := `SELECT 'plugin ' || a.PluginName || '_' || a.Target || ':' || a.Version FROM AvailablePluginBinaries a
+		JOIN additionalMetadataDB.AvailablePluginBinaries b ON b.PluginName = a.PluginName AND b.Target = a.Target AND b.Version = a.Version
+	UNION ALL
+	SELECT 'plugin group ' || a.Vendor || '-' || a.Publisher || '/' || a.GroupName || ':' || a.GroupVersion FROM AvailablePluginGroups a
+		JOIN additionalMetadataDB.AvailablePluginGroups b ON b.Vendor = a.Vendor AND b.Publisher = a.Publisher AND b.GroupName = a.GroupName AND b.GroupVersion = a.GroupVersion;`
+
+	rows, err := conn.QueryContext(ctx, conflictsQuery)
+	if err != nil {
+		return errors.Wrapf(err, "unable to execute the query %v", conflictsQuery)
+	}
+	defer rows.Close()
+
+	var conflicts []string
+	for rows.Next() {
+		var conflict string
+		if err := rows.Scan(&conflict); err != nil {
+			return errors.Wrap(err, "unable to read the conflicting entries")
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	if err := rows.Err(); err != nil {
+		return errors.Wrap(err, "unable to read the conflicting entries")
+	}
+	if len(conflicts) > 0 {
+		return errors.Errorf("%d entries are already in the inventory metadata: %s", len(conflicts), strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
 // UpdatePluginInventoryDatabase updates the plugin inventory database based
 // on the plugin inventory metadata database by deleting entries that don't
 // exists in plugin inventory metadata database
@@ -4,6 +4,7 @@
 package plugininventory
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 
@@ -164,7 +165,7 @@ var _ = Describe("Unit tests for plugin inventory metadata", func() {
 
 		inventory := NewSQLiteInventory(dbFile.Name(), tmpDir2)
 		if createSchema {
-			err = inventory.CreateSchema()
+			err = inventory.CreateSchema(context.Background())
 			Expect(err).To(BeNil(), "failed to create DB table for testing")
 		}
 		return inventory, dbFile.Name()
@@ -225,6 +226,60 @@ var _ = Describe("Unit tests for plugin inventory metadata", func() {
 		})
 	})
 
+	Describe("Insert plugin identifiers", func() {
+		BeforeEach(func() {
+			metadataInventory, _ = createInventoryMetadataDB(true)
+		})
+		AfterEach(func() {
+			os.RemoveAll(tmpDir1)
+		})
+		It("should insert all the plugin identifiers", func() {
+			err = metadataInventory.InsertPluginIdentifiers([]*PluginIdentifier{&pluginIdentifier1, &pluginIdentifier2})
+			Expect(err).NotTo(HaveOccurred())
+
+			// Both identifiers exist already
+			Expect(metadataInventory.InsertPluginIdentifier(&pluginIdentifier1)).To(HaveOccurred())
+			Expect(metadataInventory.InsertPluginIdentifier(&pluginIdentifier2)).To(HaveOccurred())
+		})
+		It("should insert none of the plugin identifiers if one of them cannot be inserted", func() {
+			err = metadataInventory.InsertPluginIdentifiers([]*PluginIdentifier{&pluginIdentifier1, &pluginIdentifier2, &pluginIdentifier1})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("UNIQUE constraint failed"))
+
+			// Neither identifier was inserted
+			Expect(metadataInventory.InsertPluginIdentifiers([]*PluginIdentifier{&pluginIdentifier1, &pluginIdentifier2})).To(Succeed())
+		})
+	})
+
+	Describe("Delete plugin identifier", func() {
+		BeforeEach(func() {
+			metadataInventory, _ = createInventoryMetadataDB(true)
+			err = metadataInventory.InsertPluginIdentifier(&pluginIdentifier1)
+			Expect(err).NotTo(HaveOccurred())
+			err = metadataInventory.InsertPluginIdentifier(&pluginIdentifier2)
+			Expect(err).NotTo(HaveOccurred())
+		})
+		AfterEach(func() {
+			os.RemoveAll(tmpDir1)
+			os.RemoveAll(tmpDir2)
+		})
+		It("should only delete the specified plugin identifier", func() {
+			err = metadataInventory.DeletePluginIdentifier(&pluginIdentifier1)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Deleting an identifier which does not exist is not an error
+			err = metadataInventory.DeletePluginIdentifier(&pluginIdentifier1)
+			Expect(err).NotTo(HaveOccurred())
+
+			// The deleted identifier can be inserted again, the other one still exists
+			err = metadataInventory.InsertPluginIdentifier(&pluginIdentifier1)
+			Expect(err).NotTo(HaveOccurred())
+			err = metadataInventory.InsertPluginIdentifier(&pluginIdentifier2)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("UNIQUE constraint failed"))
+		})
+	})
+
 	Describe("Insert plugin group identifier", func() {
 		Context("With an empty DB file", func() {
 			BeforeEach(func() {
@@ -298,7 +353,7 @@ var _ = Describe("Unit tests for plugin inventory metadata", func() {
 				os.RemoveAll(tmpDir2)
 			})
 			It("should return an error", func() {
-				err = metadataInventory.MergeInventoryMetadataDatabase(additionalMetadataInventoryFilePath)
+				err = metadataInventory.MergeInventoryMetadataDatabase(additionalMetadataInventoryFilePath, MetadataMergePolicyUnion)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("unable to execute the query"))
 			})
@@ -314,7 +369,7 @@ var _ = Describe("Unit tests for plugin inventory metadata", func() {
 				os.RemoveAll(tmpDir2)
 			})
 			It("should not return an error", func() {
-				err = metadataInventory.MergeInventoryMetadataDatabase(additionalMetadataInventoryFilePath)
+				err = metadataInventory.MergeInventoryMetadataDatabase(additionalMetadataInventoryFilePath, MetadataMergePolicyUnion)
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
@@ -340,9 +395,29 @@ var _ = Describe("Unit tests for plugin inventory metadata", func() {
 				os.RemoveAll(tmpDir2)
 			})
 			It("should not return an error", func() {
-				err = metadataInventory.MergeInventoryMetadataDatabase(additionalMetadataInventoryFilePath)
+				err = metadataInventory.MergeInventoryMetadataDatabase(additionalMetadataInventoryFilePath, MetadataMergePolicyUnion)
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should merge the entries with the fail-on-conflict policy", func() {
+				err = metadataInventory.MergeInventoryMetadataDatabase(additionalMetadataInventoryFilePath, MetadataMergePolicyFailOnConflict)
+				Expect(err).NotTo(HaveOccurred())
+				err = metadataInventory.InsertPluginIdentifier(&pluginIdentifier2)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("UNIQUE constraint failed"))
+			})
+			It("should not merge the entries with the replace policy", func() {
+				err = metadataInventory.MergeInventoryMetadataDatabase(additionalMetadataInventoryFilePath, MetadataMergePolicyReplace)
+				Expect(err).NotTo(HaveOccurred())
+				err = metadataInventory.InsertPluginIdentifier(&pluginIdentifier2)
+				Expect(err).NotTo(HaveOccurred())
+				err = metadataInventory.InsertPluginGroupIdentifier(&pluginGroupIdentifier2)
 				Expect(err).NotTo(HaveOccurred())
 			})
+			It("should return an error with an invalid policy", func() {
+				err = metadataInventory.MergeInventoryMetadataDatabase(additionalMetadataInventoryFilePath, "invalid")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid merge policy 'invalid', it must be one of [union replace fail-on-conflict]"))
+			})
 		})
 
 		Context("when both inventory metadata databases have some overlap of plugins and plugin groups", func() {
@@ -369,9 +444,40 @@ var _ = Describe("Unit tests for plugin inventory metadata", func() {
 				os.RemoveAll(tmpDir2)
 			})
 			It("should not return an error", func() {
-				err = metadataInventory.MergeInventoryMetadataDatabase(additionalMetadataInventoryFilePath)
+				err = metadataInventory.MergeInventoryMetadataDatabase(additionalMetadataInventoryFilePath, MetadataMergePolicyUnion)
 				Expect(err).NotTo(HaveOccurred())
 			})
+			It("should return the conflicting entries with the fail-on-conflict policy", func() {
+				err = metadataInventory.MergeInventoryMetadataDatabase(additionalMetadataInventoryFilePath, MetadataMergePolicyFailOnConflict)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("2 entries are already in the inventory metadata: plugin plugin2_kubernetes:v2.0.0, plugin group fakevendor-fakepublisher/fake2:" + pluginGroupIdentifier2.Version))
+			})
+		})
+	})
+
+	Describe("Create inventory metadata database with seed", func() {
+		AfterEach(func() {
+			os.RemoveAll(tmpDir1)
+		})
+		It("should create the tables and insert the plugin and plugin group identifiers", func() {
+			tmpDir1, err = os.MkdirTemp(os.TempDir(), "")
+			Expect(err).To(BeNil(), "unable to create temporary directory")
+			dbFile := filepath.Join(tmpDir1, SQliteInventoryMetadataDBFileName)
+
+			err = CreateInventoryMetadataDB(dbFile, &InventoryMetadataSeed{
+				Plugins: []*PluginIdentifier{&pluginIdentifier1, &pluginIdentifier2},
+				Groups:  []*PluginGroupIdentifier{&pluginGroupIdentifier1},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			// The seeded identifiers already exist
+			metadataInventory = NewSQLiteInventoryMetadata(dbFile)
+			err = metadataInventory.InsertPluginIdentifier(&pluginIdentifier2)
+			Expect(err).To(HaveOccurred())
+			err = metadataInventory.InsertPluginGroupIdentifier(&pluginGroupIdentifier1)
+			Expect(err).To(HaveOccurred())
+			err = metadataInventory.InsertPluginGroupIdentifier(&pluginGroupIdentifier2)
+			Expect(err).NotTo(HaveOccurred())
 		})
 	})
 
@@ -428,15 +534,15 @@ var _ = Describe("Unit tests for plugin inventory metadata", func() {
 				metadataInventory, _ = createInventoryMetadataDB(true)
 				pluginInventory, pluginInventoryFilePath = createInventoryDB(true)
 
-				err = pluginInventory.InsertPlugin(&pluginEntry1)
+				err = pluginInventory.InsertPlugin(context.Background(), &pluginEntry1)
 				Expect(err).NotTo(HaveOccurred())
-				err = pluginInventory.InsertPlugin(&pluginEntry2)
+				err = pluginInventory.InsertPlugin(context.Background(), &pluginEntry2)
 				Expect(err).NotTo(HaveOccurred())
-				err = pluginInventory.InsertPlugin(&pluginEntry3)
+				err = pluginInventory.InsertPlugin(context.Background(), &pluginEntry3)
 				Expect(err).NotTo(HaveOccurred())
-				err = pluginInventory.InsertPluginGroup(&pluginGroupEntry1, true)
+				err = pluginInventory.InsertPluginGroup(context.Background(), &pluginGroupEntry1, true)
 				Expect(err).NotTo(HaveOccurred())
-				err = pluginInventory.InsertPluginGroup(&pluginGroupEntry2, true)
+				err = pluginInventory.InsertPluginGroup(context.Background(), &pluginGroupEntry2, true)
 				Expect(err).NotTo(HaveOccurred())
 			})
 			AfterEach(func() {
@@ -447,11 +553,11 @@ var _ = Describe("Unit tests for plugin inventory metadata", func() {
 				err = metadataInventory.UpdatePluginInventoryDatabase(pluginInventoryFilePath)
 				Expect(err).NotTo(HaveOccurred())
 
-				pluginEntries, err := pluginInventory.GetAllPlugins()
+				pluginEntries, err := pluginInventory.GetAllPlugins(context.Background())
 				Expect(err).NotTo(HaveOccurred())
 				Expect(len(pluginEntries)).To(Equal(0))
 
-				pluginGroupEntries, err := pluginInventory.GetPluginGroups(PluginGroupFilter{})
+				pluginGroupEntries, err := pluginInventory.GetPluginGroups(context.Background(), PluginGroupFilter{})
 				Expect(err).NotTo(HaveOccurred())
 				Expect(len(pluginGroupEntries)).To(Equal(0))
 			})
@@ -465,11 +571,11 @@ var _ = Describe("Unit tests for plugin inventory metadata", func() {
 				err = metadataInventory.UpdatePluginInventoryDatabase(pluginInventoryFilePath)
 				Expect(err).NotTo(HaveOccurred())
 
-				pluginEntries, err := pluginInventory.GetAllPlugins()
+				pluginEntries, err := pluginInventory.GetAllPlugins(context.Background())
 				Expect(err).NotTo(HaveOccurred())
 				Expect(len(pluginEntries)).To(Equal(1))
 
-				pluginGroupEntries, err := pluginInventory.GetPluginGroups(PluginGroupFilter{})
+				pluginGroupEntries, err := pluginInventory.GetPluginGroups(context.Background(), PluginGroupFilter{})
 				Expect(err).NotTo(HaveOccurred())
 				Expect(len(pluginGroupEntries)).To(Equal(1))
 			})
@@ -487,11 +593,11 @@ var _ = Describe("Unit tests for plugin inventory metadata", func() {
 				err = metadataInventory.UpdatePluginInventoryDatabase(pluginInventoryFilePath)
 				Expect(err).NotTo(HaveOccurred())
 
-				pluginEntries, err := pluginInventory.GetAllPlugins()
+				pluginEntries, err := pluginInventory.GetAllPlugins(context.Background())
 				Expect(err).NotTo(HaveOccurred())
 				Expect(len(pluginEntries)).To(Equal(2))
 
-				pluginGroupEntries, err := pluginInventory.GetPluginGroups(PluginGroupFilter{})
+				pluginGroupEntries, err := pluginInventory.GetPluginGroups(context.Background(), PluginGroupFilter{})
 				Expect(err).NotTo(HaveOccurred())
 				Expect(len(pluginGroupEntries)).To(Equal(2))
 			})
@@ -0,0 +1,154 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugininventory
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+// schemaVersionTableSchema defines the table recording the migrations applied to the schema of the database
+const schemaVersionTableSchema = `CREATE TABLE IF NOT EXISTS "SchemaVersion" (
+		"Version"            INTEGER NOT NULL,
+		"Description"        TEXT NOT NULL,
+		PRIMARY KEY("Version")
+);`
+
+// schemaMigration changes the schema of the inventory database to its version
+type schemaMigration struct {
+	version     int
+	description string
+	statements  string
+}
+
+// schemaMigrations are the migrations of the schema of the inventory database, ordered by version.
+// To change the schema, append a migration with the next version; never modify an existing one.
+// A migration must only add tables, columns with a default value, or indexes, so that the CLIs
+// which do not know about the migration can still read the migrated databases.
+var schemaMigrations = []schemaMigration{
+	{
+		// The databases created before the schema was versioned are missing some of these tables,
+		// which are all created if they do not exist
+		version:     1,
+		description: "Create the plugin, plugin-group, release notes and compatibility tables",
+		statements:  CreateTablesSchema,
+	},
+	{
+		version:     2,
+		description: "Add the deprecation columns to the plugin table",
+		statements: `ALTER TABLE "PluginBinaries" ADD COLUMN "Deprecated" TEXT NOT NULL DEFAULT 'false';
+ALTER TABLE "PluginBinaries" ADD COLUMN "DeprecationMessage" TEXT NOT NULL DEFAULT '';
+ALTER TABLE "PluginBinaries" ADD COLUMN "ReplacedBy" TEXT NOT NULL DEFAULT '';`,
+	},
+	{
+		version:     3,
+		description: "Add the size of the artifacts to the plugin table",
+		statements:  `ALTER TABLE "PluginBinaries" ADD COLUMN "Size" INTEGER NOT NULL DEFAULT 0;`,
+	},
+	{
+		version:     4,
+		description: "Create the plugin license table",
+		statements: `CREATE TABLE IF NOT EXISTS "PluginLicenses" (
+		"PluginName"         TEXT NOT NULL,
+		"Target"             TEXT NOT NULL,
+		"Version"            TEXT NOT NULL,
+		"License"            TEXT NOT NULL,
+		"SBOMURI"            TEXT NOT NULL,
+		PRIMARY KEY("PluginName", "Target", "Version")
+);`,
+	},
+	{
+		version:     5,
+		description: "Add the signature of the artifacts to the plugin table",
+		statements:  `ALTER TABLE "PluginBinaries" ADD COLUMN "Signature" TEXT NOT NULL DEFAULT '';`,
+	},
+	{
+		version:     6,
+		description: "Add the release time of the plugin versions to the plugin table",
+		statements:  `ALTER TABLE "PluginBinaries" ADD COLUMN "ReleasedAt" TEXT NOT NULL DEFAULT '';`,
+	},
+	{
+		// The plugin names are matched case-insensitively, using the index of their lowercase value
+		version:     7,
+		description: "Create the indexes of the vendor, publisher and lowercase name of the plugins",
+		statements: `CREATE INDEX IF NOT EXISTS "PluginBinariesVendorIndex" ON "PluginBinaries" ("Vendor");
+CREATE INDEX IF NOT EXISTS "PluginBinariesPublisherIndex" ON "PluginBinaries" ("Publisher");
+CREATE INDEX IF NOT EXISTS "PluginBinariesLowerNameIndex" ON "PluginBinaries" (lower("PluginName"));`,
+	},
+	{
+		// The release notes are keyed by the vendor and publisher of their plugin, like the plugin-groups.
+		// The table is created again with the new key and the release notes already inserted get the
+		// vendor and publisher of their plugin version.
+		version:     8,
+		description: "Add the vendor and publisher of the plugins to the release notes table",
+		statements: `CREATE TABLE "PluginReleaseNotesWithVendor" (
+		"PluginName"         TEXT NOT NULL,
+		"Target"             TEXT NOT NULL,
+		"Version"            TEXT NOT NULL,
+		"ChangelogURL"       TEXT NOT NULL,
+		"Notes"              TEXT NOT NULL,
+		"Vendor"             TEXT NOT NULL,
+		"Publisher"          TEXT NOT NULL,
+		PRIMARY KEY("PluginName", "Target", "Version", "Vendor", "Publisher")
+);
+INSERT INTO "PluginReleaseNotesWithVendor" SELECT n."PluginName", n."Target", n."Version", n."ChangelogURL", n."Notes",
+	IFNULL((SELECT b."Vendor" FROM "PluginBinaries" b WHERE b."PluginName" = n."PluginName" AND b."Target" = n."Target" AND b."Version" = n."Version" LIMIT 1), ''),
+	IFNULL((SELECT b."Publisher" FROM "PluginBinaries" b WHERE b."PluginName" = n."PluginName" AND b."Target" = n."Target" AND b."Version" = n."Version" LIMIT 1), '')
+	FROM "PluginReleaseNotes" n;
+DROP TABLE "PluginReleaseNotes";
+ALTER TABLE "PluginReleaseNotesWithVendor" RENAME TO "PluginReleaseNotes";`,
+	},
+}
+
+// CurrentSchemaVersion is the version of the schema of the inventory databases created by this CLI
+var CurrentSchemaVersion = schemaMigrations[len(schemaMigrations)-1].version
+
+// MigrateSchema applies the migrations which have not been applied yet to the schema of the DB.
+// A DB with a schema more recent than the one of this CLI is left unchanged.
+func (b *SQLiteInventory) MigrateSchema(ctx context.Context) error {
+	db, err := openInventoryDB(b.inventoryFile)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open the DB at '%s'", b.inventoryFile)
+	}
+	defer closeInventoryDB(db)
+
+	return inTransaction(ctx, db, func(tx *sql.Tx) error {
+		return migrateSchema(ctx, tx)
+	})
+}
+
+// migrateSchema applies the pending migrations to the schema of the DB within the transaction
+func migrateSchema(ctx context.Context, tx *sql.Tx) error {
+	version, err := getSchemaVersion(ctx, tx)
+	if err != nil {
+		return err
+	}
+	for _, migration := range schemaMigrations {
+		if migration.version <= version {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, migration.statements); err != nil {
+			return errors.Wrapf(err, "error while migrating the database schema to version %d", migration.version)
+		}
+		if _, err := tx.ExecContext(ctx, "INSERT INTO SchemaVersion VALUES(?,?);", migration.version, migration.description); err != nil {
+			return errors.Wrapf(err, "unable to record the database schema version %d", migration.version)
+		}
+	}
+	return nil
+}
+
+// getSchemaVersion returns the version of the schema of the DB, which is 0 for the
+// databases created before the schema was versioned
+func getSchemaVersion(ctx context.Context, tx *sql.Tx) (int, error) {
+	if _, err := tx.ExecContext(ctx, schemaVersionTableSchema); err != nil {
+		return 0, errors.Wrap(err, "error while creating the schema version table")
+	}
+	var version sql.NullInt64
+	if err := tx.QueryRowContext(ctx, "SELECT MAX(Version) FROM SchemaVersion;").Scan(&version); err != nil {
+		return 0, errors.Wrap(err, "unable to read the database schema version")
+	}
+	return int(version.Int64), nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugininventory
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// InventorySeed is the content of a plugin inventory database to create from Go structs, mostly
+// for tests. Unlike InsertPlugin and InsertPluginGroup, the seeding stores the RecommendedVersion
+// of the plugins and does not verify that the plugins of the plugin-groups exist in the inventory,
+// so that any content, including inconsistent one, can be seeded.
+// A plugin whose versions are not all hidden, or a plugin-group whose versions have different
+// descriptions, is seeded with one entry per hidden state or description.
+type InventorySeed struct {
+	Plugins []*PluginInventoryEntry
+	Groups  []*PluginGroup
+}
+
+// InventoryMetadataSeed is the content of a plugin inventory metadata database to create from Go structs
+type InventoryMetadataSeed struct {
+	Plugins []*PluginIdentifier
+	Groups  []*PluginGroupIdentifier
+}
+
+// CreateInventoryDB creates the tables of the plugin inventory database 'dbFile' and seeds them
+// with the given plugins and plugin-groups
+func CreateInventoryDB(dbFile string, seed *InventorySeed) error {
+	db, err := sql.Open("sqlite", dbFile)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open the DB at '%s'", dbFile)
+	}
+	defer db.Close()
+
+	return inTransaction(context.Background(), db, func(tx *sql.Tx) error {
+		if err := migrateSchema(context.Background(), tx); err != nil {
+			return errors.Wrap(err, "error while creating tables to the database")
+		}
+		if seed == nil {
+			return nil
+		}
+		for _, p := range seed.Plugins {
+			if err := seedPlugin(tx, p); err != nil {
+				return err
+			}
+		}
+		for _, pg := range seed.Groups {
+			if err := seedPluginGroup(tx, pg); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CreateInventoryMetadataDB creates the tables of the plugin inventory metadata database 'dbFile'
+// and seeds them with the given plugin and plugin-group identifiers
+func CreateInventoryMetadataDB(dbFile string, seed *InventoryMetadataSeed) error {
+	metadata := NewSQLiteInventoryMetadata(dbFile)
+	if err := metadata.CreateInventoryMetadataDBSchema(); err != nil {
+		return err
+	}
+	if seed == nil {
+		return nil
+	}
+	if err := metadata.InsertPluginIdentifiers(seed.Plugins); err != nil {
+		return err
+	}
+	return metadata.InsertPluginGroupIdentifiers(seed.Groups)
+}
+
+func seedPlugin(tx *sql.Tx, p *PluginInventoryEntry) error {
+	for version, artifacts := range p.Artifacts {
+		for _, a := range artifacts {
+			uri := a.Image
+			if uri == "" {
+				uri = a.URI
+			}
+			_, err := tx.Exec("INSERT INTO PluginBinaries VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?);", p.Name, string(p.Target), p.RecommendedVersion, version, strconv.FormatBool(p.Hidden), p.Description, p.Publisher, p.Vendor, a.OS, a.Arch, a.Digest, uri, strconv.FormatBool(p.Deprecated), p.DeprecationMessage, p.ReplacedBy, a.Size, a.Signature, formatReleaseTime(p.ReleasedAt[version]))
+			if err != nil {
+				return errors.Wrapf(err, "unable to seed plugin '%s:%s' for %s/%s", PluginToID(p), version, a.OS, a.Arch)
+			}
+		}
+	}
+	return insertPluginVersionDetails(context.Background(), tx, p)
+}
+
+func seedPluginGroup(tx *sql.Tx, pg *PluginGroup) error {
+	for version, plugins := range pg.Versions {
+		for _, pi := range plugins {
+			_, err := tx.Exec("INSERT INTO PluginGroups VALUES(?,?,?,?,?,?,?,?,?,?);", pg.Vendor, pg.Publisher, pg.Name, version, pg.Description, pi.Name, string(pi.Target), pi.Version, strconv.FormatBool(pi.Mandatory), strconv.FormatBool(pg.Hidden))
+			if err != nil {
+				return errors.Wrapf(err, "unable to seed plugin-group '%s:%s' with plugin '%s'", PluginGroupToID(pg), version, pi.Name)
+			}
+		}
+	}
+	return nil
+}
@@ -4,16 +4,20 @@
 package plugininventory
 
 import (
+	"context"
 	"database/sql"
 	"os"
 	"path/filepath"
 	"sort"
+	"sync"
 	"testing"
+	"time"
 
 	// Import the sqlite driver
 	_ "modernc.org/sqlite"
 
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
@@ -22,6 +26,16 @@ import (
 	. "github.com/onsi/gomega"
 )
 
+// journalMode returns the journal mode persisted in the DB file
+func journalMode(dbFile string) string {
+	db, err := sql.Open("sqlite", dbFile)
+	Expect(err).To(BeNil())
+	defer db.Close()
+	var mode string
+	Expect(db.QueryRow("PRAGMA journal_mode;").Scan(&mode)).To(Succeed())
+	return mode
+}
+
 func TestClient(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Plugin Inventory Suite")
@@ -158,261 +172,215 @@ var groupWithHiddenPlugin = PluginGroup{
 	},
 }
 
-const createPluginsStmt = `
-INSERT INTO PluginBinaries VALUES(
-	'management-cluster',
-	'kubernetes',
-	'v0.28.0',
-	'v0.28.0',
-	'false',
-	'Kubernetes management cluster operations',
-	'tkg',
-	'vmware',
-	'linux',
-	'amd64',
-	'0000000000',
-	'vmware/tkg/linux/amd64/k8s/management-cluster:v0.28.0');
-INSERT INTO PluginBinaries VALUES(
-	'management-cluster',
-	'kubernetes',
-	'v0.28.0',
-	'v0.28.0',
-	'false',
-	'Kubernetes management cluster operations',
-	'tkg',
-	'vmware',
-	'darwin',
-	'amd64',
-	'1111111111',
-	'vmware/tkg/darwin/amd64/k8s/management-cluster:v0.28.0');
-INSERT INTO PluginBinaries VALUES(
-	'management-cluster',
-	'kubernetes',
-	'v0.28.0',
-	'v0.26.0',
-	'false',
-	'Kubernetes management cluster operations',
-	'tkg',
-	'vmware',
-	'windows',
-	'amd64',
-	'2222222222',
-	'vmware/tkg/windows/amd64/k8s/management-cluster:v0.26.0');
-INSERT INTO PluginBinaries VALUES(
-	'isolated-cluster',
-	'global',
-	'v1.2.3',
-	'v1.2.3',
-	'false',
-	'Isolated cluster plugin',
-	'otherpublisher',
-	'othervendor',
-	'linux',
-	'amd64',
-	'3333333333',
-	'othervendor/otherpublisher/linux/amd64/global/isolated-cluster:v1.2.3');
-INSERT INTO PluginBinaries VALUES(
-    'isolated-cluster',
-    'global',
-    'v1.2.3',
-    'v1.3.0',
-    'true',
-    'Isolated cluster plugin',
-    'otherpublisher',
-    'othervendor',
-    'linux',
-    'amd64',
-    '4444444444',
-    'othervendor/otherpublisher/linux/amd64/global/isolated-cluster:v1.3.0');
-INSERT INTO PluginBinaries VALUES(
-    'hidden-plugin',
-    'global',
-    'v1.0.0',
-    'v1.0.0',
-    'true',
-    'Hidden plugin',
-    'otherpublisher',
-    'othervendor',
-    'linux',
-    'amd64',
-    '5555555555',
-    'othervendor/otherpublisher/linux/amd64/global/hidden-plugin:v1.0.0');
-`
-const createPluginTMCNoRecommendedVersionStmt = `
-INSERT INTO PluginBinaries VALUES(
-	'management-cluster',
-	'mission-control',
-	'',
-	'v0.0.1',
-	'false',
-	'Mission-control management cluster operations',
-	'tmc',
-	'vmware',
-	'linux',
-	'amd64',
-	'0000000000',
-	'vmware/tmc/linux/amd64/tmc/management-cluster:v0.0.1');
-INSERT INTO PluginBinaries VALUES(
-	'management-cluster',
-	'mission-control',
-	'',
-	'v0.0.2',
-	'false',
-	'Mission-control management cluster operations',
-	'tmc',
-	'vmware',
-	'linux',
-	'amd64',
-	'1111111111',
-	'vmware/tmc/linux/amd64/tmc/management-cluster:v0.0.2');
-INSERT INTO PluginBinaries VALUES(
-    'management-cluster',
-    'mission-control',
-    '',
-    'v0.0.3',
-    'true',
-    'Mission-control management cluster operations',
-    'tmc',
-    'vmware',
-    'linux',
-    'amd64',
-    '2222222222',
-    'vmware/tmc/linux/amd64/tmc/management-cluster:v0.0.3');
-`
-const createGroupsStmt = `
-INSERT INTO PluginGroups VALUES(
-	'vmware',
-	'tkg',
-	'default',
-	'v2.1.0',
-	'Description for vmware-tkg/default:v2.1.0',
-	'management-cluster',
-	'kubernetes',
-	'v0.28.0',
-	'false',
-	'false');
-INSERT INTO PluginGroups VALUES(
-	'vmware',
-	'tkg',
-	'default',
-	'v2.1.0',
-	'Description for vmware-tkg/default:v2.1.0',
-	'package',
-	'kubernetes',
-	'v0.28.0',
-	'false',
-	'false');
-INSERT INTO PluginGroups VALUES(
-	'vmware',
-	'tkg',
-	'default',
-	'v2.1.0',
-	'Description for vmware-tkg/default:v2.1.0',
-	'feature',
-	'kubernetes',
-	'v0.28.0',
-	'false',
-	'false');
-INSERT INTO PluginGroups VALUES(
-	'vmware',
-	'tkg',
-	'default',
-	'v2.1.0',
-	'Description for vmware-tkg/default:v2.1.0',
-	'kubernetes-release',
-	'kubernetes',
-	'v0.28.0',
-	'false',
-	'false');
-INSERT INTO PluginGroups VALUES(
-	'vmware',
-	'tkg',
-	'default',
-	'v2.1.0',
-	'Description for vmware-tkg/default:v2.1.0',
-	'isolated-cluster',
-	'kubernetes',
-	'v0.28.0',
-	'false',
-	'false');
-INSERT INTO PluginGroups VALUES(
-	'vmware',
-	'tkg',
-	'default',
-	'v1.6.0',
-	'Description for vmware-tkg/default:v1.6.0',
-	'management-cluster',
-	'kubernetes',
-	'v0.26.0',
-	'false',
-	'false');
-INSERT INTO PluginGroups VALUES(
-	'vmware',
-	'tkg',
-	'default',
-	'v1.6.0',
-	'Description for vmware-tkg/default:v1.6.0',
-	'package',
-	'kubernetes',
-	'v0.26.0',
-	'false',
-	'false');
-INSERT INTO PluginGroups VALUES(
-	'vmware',
-	'tkg',
-	'default',
-	'v1.6.0',
-	'Description for vmware-tkg/default:v1.6.0',
-	'feature',
-	'kubernetes',
-	'v0.26.0',
-	'false',
-	'false');
-INSERT INTO PluginGroups VALUES(
-	'vmware',
-	'tkg',
-	'default',
-	'v1.6.0',
-	'Description for vmware-tkg/default:v1.6.0',
-	'kubernetes-release',
-	'kubernetes',
-	'v0.26.0',
-	'false',
-	'false');
-INSERT INTO PluginGroups VALUES(
-	'independent',
-	'other',
-	'mygroup',
-	'v1.0.0',
-	'Description for independent-other/mygroup:v1.0.0',
-	'plugin1',
-	'kubernetes',
-	'v0.1.0',
-	'false',
-	'false');
-INSERT INTO PluginGroups VALUES(
-	'independent',
-	'other',
-	'mygroup',
-	'v1.0.0',
-	'Description for independent-other/mygroup:v1.0.0',
-	'plugin2',
-	'mission-control',
-	'v0.2.0',
-	'false',
-	'false');
-INSERT INTO PluginGroups VALUES(
-    'independent',
-    'other',
-    'hidden',
-	'v2.0.0',
-	'Description for independent-other/hidden:v2.0.0',
-    'plugin2',
-    'mission-control',
-    'v0.3.0',
-    'false',
-    'true');
-`
+// pluginsSeed seeds the inventory with plugins of different targets, recommended versions and hidden states
+var pluginsSeed = &InventorySeed{
+	Plugins: []*PluginInventoryEntry{
+		{
+			Name:               "management-cluster",
+			Target:             types.TargetK8s,
+			Description:        "Kubernetes management cluster operations",
+			Publisher:          "tkg",
+			Vendor:             "vmware",
+			RecommendedVersion: "v0.28.0",
+			Hidden:             false,
+			Artifacts: distribution.Artifacts{
+				"v0.28.0": []distribution.Artifact{
+					{
+						OS:     "linux",
+						Arch:   "amd64",
+						Digest: "0000000000",
+						Image:  "vmware/tkg/linux/amd64/k8s/management-cluster:v0.28.0",
+					},
+					{
+						OS:     "darwin",
+						Arch:   "amd64",
+						Digest: "1111111111",
+						Image:  "vmware/tkg/darwin/amd64/k8s/management-cluster:v0.28.0",
+					},
+				},
+				"v0.26.0": []distribution.Artifact{
+					{
+						OS:     "windows",
+						Arch:   "amd64",
+						Digest: "2222222222",
+						Image:  "vmware/tkg/windows/amd64/k8s/management-cluster:v0.26.0",
+					},
+				},
+			},
+		},
+		{
+			Name:               "isolated-cluster",
+			Target:             types.TargetGlobal,
+			Description:        "Isolated cluster plugin",
+			Publisher:          "otherpublisher",
+			Vendor:             "othervendor",
+			RecommendedVersion: "v1.2.3",
+			Hidden:             false,
+			Artifacts: distribution.Artifacts{
+				"v1.2.3": []distribution.Artifact{
+					{
+						OS:     "linux",
+						Arch:   "amd64",
+						Digest: "3333333333",
+						Image:  "othervendor/otherpublisher/linux/amd64/global/isolated-cluster:v1.2.3",
+					},
+				},
+			},
+		},
+		{
+			Name:               "isolated-cluster",
+			Target:             types.TargetGlobal,
+			Description:        "Isolated cluster plugin",
+			Publisher:          "otherpublisher",
+			Vendor:             "othervendor",
+			RecommendedVersion: "v1.2.3",
+			Hidden:             true,
+			Artifacts: distribution.Artifacts{
+				"v1.3.0": []distribution.Artifact{
+					{
+						OS:     "linux",
+						Arch:   "amd64",
+						Digest: "4444444444",
+						Image:  "othervendor/otherpublisher/linux/amd64/global/isolated-cluster:v1.3.0",
+					},
+				},
+			},
+		},
+		{
+			Name:               "hidden-plugin",
+			Target:             types.TargetGlobal,
+			Description:        "Hidden plugin",
+			Publisher:          "otherpublisher",
+			Vendor:             "othervendor",
+			RecommendedVersion: "v1.0.0",
+			Hidden:             true,
+			Artifacts: distribution.Artifacts{
+				"v1.0.0": []distribution.Artifact{
+					{
+						OS:     "linux",
+						Arch:   "amd64",
+						Digest: "5555555555",
+						Image:  "othervendor/otherpublisher/linux/amd64/global/hidden-plugin:v1.0.0",
+					},
+				},
+			},
+		},
+	},
+}
+
+// pluginTMCNoRecommendedVersionSeed seeds the inventory with a plugin without recommended version
+var pluginTMCNoRecommendedVersionSeed = &InventorySeed{
+	Plugins: []*PluginInventoryEntry{
+		{
+			Name:               "management-cluster",
+			Target:             types.TargetTMC,
+			Description:        "Mission-control management cluster operations",
+			Publisher:          "tmc",
+			Vendor:             "vmware",
+			RecommendedVersion: "",
+			Hidden:             false,
+			Artifacts: distribution.Artifacts{
+				"v0.0.1": []distribution.Artifact{
+					{
+						OS:     "linux",
+						Arch:   "amd64",
+						Digest: "0000000000",
+						Image:  "vmware/tmc/linux/amd64/tmc/management-cluster:v0.0.1",
+					},
+				},
+				"v0.0.2": []distribution.Artifact{
+					{
+						OS:     "linux",
+						Arch:   "amd64",
+						Digest: "1111111111",
+						Image:  "vmware/tmc/linux/amd64/tmc/management-cluster:v0.0.2",
+					},
+				},
+			},
+		},
+		{
+			Name:               "management-cluster",
+			Target:             types.TargetTMC,
+			Description:        "Mission-control management cluster operations",
+			Publisher:          "tmc",
+			Vendor:             "vmware",
+			RecommendedVersion: "",
+			Hidden:             true,
+			Artifacts: distribution.Artifacts{
+				"v0.0.3": []distribution.Artifact{
+					{
+						OS:     "linux",
+						Arch:   "amd64",
+						Digest: "2222222222",
+						Image:  "vmware/tmc/linux/amd64/tmc/management-cluster:v0.0.3",
+					},
+				},
+			},
+		},
+	},
+}
+
+// groupsSeed seeds the inventory with plugin-groups of different versions and hidden states
+var groupsSeed = &InventorySeed{
+	Groups: []*PluginGroup{
+		{
+			Vendor:      "vmware",
+			Publisher:   "tkg",
+			Name:        "default",
+			Description: "Description for vmware-tkg/default:v2.1.0",
+			Hidden:      false,
+			Versions: map[string][]*PluginGroupPluginEntry{
+				"v2.1.0": {
+					{PluginIdentifier: PluginIdentifier{Name: "management-cluster", Target: types.TargetK8s, Version: "v0.28.0"}},
+					{PluginIdentifier: PluginIdentifier{Name: "package", Target: types.TargetK8s, Version: "v0.28.0"}},
+					{PluginIdentifier: PluginIdentifier{Name: "feature", Target: types.TargetK8s, Version: "v0.28.0"}},
+					{PluginIdentifier: PluginIdentifier{Name: "kubernetes-release", Target: types.TargetK8s, Version: "v0.28.0"}},
+					{PluginIdentifier: PluginIdentifier{Name: "isolated-cluster", Target: types.TargetK8s, Version: "v0.28.0"}},
+				},
+			},
+		},
+		{
+			Vendor:      "vmware",
+			Publisher:   "tkg",
+			Name:        "default",
+			Description: "Description for vmware-tkg/default:v1.6.0",
+			Hidden:      false,
+			Versions: map[string][]*PluginGroupPluginEntry{
+				"v1.6.0": {
+					{PluginIdentifier: PluginIdentifier{Name: "management-cluster", Target: types.TargetK8s, Version: "v0.26.0"}},
+					{PluginIdentifier: PluginIdentifier{Name: "package", Target: types.TargetK8s, Version: "v0.26.0"}},
+					{PluginIdentifier: PluginIdentifier{Name: "feature", Target: types.TargetK8s, Version: "v0.26.0"}},
+					{PluginIdentifier: PluginIdentifier{Name: "kubernetes-release", Target: types.TargetK8s, Version: "v0.26.0"}},
+				},
+			},
+		},
+		{
+			Vendor:      "independent",
+			Publisher:   "other",
+			Name:        "mygroup",
+			Description: "Description for independent-other/mygroup:v1.0.0",
+			Hidden:      false,
+			Versions: map[string][]*PluginGroupPluginEntry{
+				"v1.0.0": {
+					{PluginIdentifier: PluginIdentifier{Name: "plugin1", Target: types.TargetK8s, Version: "v0.1.0"}},
+					{PluginIdentifier: PluginIdentifier{Name: "plugin2", Target: types.TargetTMC, Version: "v0.2.0"}},
+				},
+			},
+		},
+		{
+			Vendor:      "independent",
+			Publisher:   "other",
+			Name:        "hidden",
+			Description: "Description for independent-other/hidden:v2.0.0",
+			Hidden:      true,
+			Versions: map[string][]*PluginGroupPluginEntry{
+				"v2.0.0": {
+					{PluginIdentifier: PluginIdentifier{Name: "plugin2", Target: types.TargetTMC, Version: "v0.3.0"}},
+				},
+			},
+		},
+	},
+}
 
 var _ = Describe("Unit tests for plugin inventory", func() {
 	var (
@@ -438,7 +406,7 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 				os.RemoveAll(tmpDir)
 			})
 			It("should return an error", func() {
-				_, err = inventory.GetAllPlugins()
+				_, err = inventory.GetAllPlugins(context.Background())
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("unable to setup DB"))
 			})
@@ -466,7 +434,7 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 				os.RemoveAll(tmpDir)
 			})
 			It("should return an empty list of plugins with no error", func() {
-				plugins, err := inventory.GetAllPlugins()
+				plugins, err := inventory.GetAllPlugins(context.Background())
 				Expect(err).ToNot(HaveOccurred())
 				Expect(len(plugins)).To(Equal(0))
 			})
@@ -479,17 +447,8 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 				// Create DB file
 				dbFile, err = os.Create(filepath.Join(tmpDir, SQliteDBFileName))
 				Expect(err).To(BeNil())
-				// Open DB with the sqlite driver
-				db, err := sql.Open("sqlite", dbFile.Name())
-				Expect(err).To(BeNil(), "failed to open the DB for testing")
-				defer db.Close()
-
-				// Create the table
-				_, err = db.Exec(CreateTablesSchema)
-				Expect(err).To(BeNil(), "failed to create DB table for testing")
-
-				// Add plugin entries to the DB
-				_, err = db.Exec(createPluginsStmt)
+				// Create the tables and add plugin entries to the DB
+				err = CreateInventoryDB(dbFile.Name(), pluginsSeed)
 				Expect(err).To(BeNil(), "failed to create plugin for testing")
 
 				inventory = NewSQLiteInventory(dbFile.Name(), tmpDir)
@@ -499,7 +458,7 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 			})
 			Context("When getting all plugins", func() {
 				It("should return a list of two plugins with no error", func() {
-					plugins, err := inventory.GetAllPlugins()
+					plugins, err := inventory.GetAllPlugins(context.Background())
 					Expect(err).ToNot(HaveOccurred())
 					Expect(len(plugins)).To(Equal(2))
 
@@ -557,7 +516,7 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 			})
 			Context("When getting a specific plugin version for k8s for an os/arch", func() {
 				It("should return a list of one plugin with no error", func() {
-					plugins, err := inventory.GetPlugins(&PluginInventoryFilter{
+					plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{
 						Name:    "management-cluster",
 						Target:  "kubernetes",
 						Version: "v0.26.0",
@@ -588,7 +547,7 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 			})
 			Context("When getting the recommended version of a plugin for an os/arch", func() {
 				It("should return a list of one plugin with no error", func() {
-					plugins, err := inventory.GetPlugins(&PluginInventoryFilter{
+					plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{
 						Name:    "management-cluster",
 						Target:  "kubernetes",
 						Version: cli.VersionLatest,
@@ -619,7 +578,7 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 			})
 			Context("When getting plugins by vendor", func() {
 				It("should return a list of one plugin with no error", func() {
-					plugins, err := inventory.GetPlugins(&PluginInventoryFilter{
+					plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{
 						Vendor: "vmware",
 					})
 					Expect(err).ToNot(HaveOccurred())
@@ -640,7 +599,7 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 			})
 			Context("When getting plugins by publisher", func() {
 				It("should return a list of one plugin with no error", func() {
-					plugins, err := inventory.GetPlugins(&PluginInventoryFilter{
+					plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{
 						Publisher: "otherpublisher",
 					})
 					Expect(err).ToNot(HaveOccurred())
@@ -660,7 +619,7 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 			})
 			Context("When getting all plugins including hidden ones", func() {
 				It("should return a list of three plugins with no error", func() {
-					plugins, err := inventory.GetPlugins(&PluginInventoryFilter{IncludeHidden: true})
+					plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{IncludeHidden: true})
 					Expect(err).ToNot(HaveOccurred())
 					Expect(len(plugins)).To(Equal(3))
 
@@ -740,6 +699,204 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 					}
 				})
 			})
+			Context("When getting plugins by a name of another case", func() {
+				It("should return the plugin whose name matches case-insensitively", func() {
+					plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "Management-CLUSTER"})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(plugins)).To(Equal(1))
+					Expect(plugins[0].Name).To(Equal("management-cluster"))
+				})
+			})
+			Context("When getting plugins by a name pattern", func() {
+				It("should return the plugins whose name matches the wildcards", func() {
+					plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "*-cluster"})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(plugins)).To(Equal(2))
+
+					plugins, err = inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "management-*"})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(plugins)).To(Equal(1))
+					Expect(plugins[0].Name).To(Equal("management-cluster"))
+
+					plugins, err = inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "Management-*"})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(plugins).To(BeEmpty())
+				})
+				It("should return the plugins whose name matches the regular expression", func() {
+					plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{NameRegex: "^(isolated|hidden)-", IncludeHidden: true})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(plugins)).To(Equal(2))
+					Expect(plugins[0].Name).To(Equal("hidden-plugin"))
+					Expect(plugins[1].Name).To(Equal("isolated-cluster"))
+
+					plugins, err = inventory.GetPlugins(context.Background(), &PluginInventoryFilter{NameRegex: "cluster$", Target: types.TargetGlobal})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(plugins)).To(Equal(1))
+					Expect(plugins[0].Name).To(Equal("isolated-cluster"))
+				})
+				It("should return an error for an invalid regular expression", func() {
+					_, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{NameRegex: "cluster("})
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("invalid regular expression 'cluster(' for the plugin name"))
+				})
+			})
+			Context("When getting plugins by values containing SQL syntax", func() {
+				It("should match the values literally", func() {
+					plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "x' OR '1'='1", IncludeHidden: true})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(plugins).To(BeEmpty())
+
+					plugins, err = inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Vendor: "vmware' OR Vendor!='", Version: "v0%"})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(plugins).To(BeEmpty())
+
+					groups, err := inventory.GetPluginGroups(context.Background(), PluginGroupFilter{Name: "x' OR '1'='1", IncludeHidden: true})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(groups).To(BeEmpty())
+				})
+			})
+			Context("When repeating a query on a DB in use", func() {
+				It("should reuse the statement prepared for the query", func() {
+					db, err := openInventoryDB(dbFile.Name())
+					Expect(err).ToNot(HaveOccurred())
+					defer closeInventoryDB(db)
+
+					_, err = inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "management-cluster"})
+					Expect(err).ToNot(HaveOccurred())
+					pooledDBsLock.Lock()
+					statements := len(findPooledDB(db).statements)
+					pooledDBsLock.Unlock()
+					Expect(statements).ToNot(BeZero())
+
+					plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "isolated-cluster"})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(plugins)).To(Equal(1))
+					Expect(plugins[0].Name).To(Equal("isolated-cluster"))
+					pooledDBsLock.Lock()
+					Expect(findPooledDB(db).statements).To(HaveLen(statements))
+					pooledDBsLock.Unlock()
+				})
+			})
+			Context("When getting plugins by several targets", func() {
+				It("should return the plugins of any of the targets", func() {
+					plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Targets: []types.Target{types.TargetK8s, types.TargetGlobal}, IncludeHidden: true})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(plugins)).To(Equal(3))
+					Expect(plugins[0].Name).To(Equal("hidden-plugin"))
+					Expect(plugins[1].Name).To(Equal("isolated-cluster"))
+					Expect(plugins[2].Name).To(Equal("management-cluster"))
+
+					plugins, err = inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Targets: []types.Target{types.TargetTMC, types.TargetGlobal}})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(plugins)).To(Equal(1))
+					Expect(plugins[0].Name).To(Equal("isolated-cluster"))
+
+					plugins, err = inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Targets: []types.Target{types.TargetK8s, types.TargetGlobal}, Target: types.TargetGlobal})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(plugins)).To(Equal(1))
+					Expect(plugins[0].Name).To(Equal("isolated-cluster"))
+				})
+			})
+			Context("When sorting the plugins", func() {
+				It("should return the plugins in the requested order", func() {
+					plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{IncludeHidden: true, SortBy: PluginSortByName, SortOrder: SortOrderDescending})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(plugins)).To(Equal(3))
+					Expect(plugins[0].Name).To(Equal("management-cluster"))
+					Expect(plugins[1].Name).To(Equal("isolated-cluster"))
+					Expect(plugins[2].Name).To(Equal("hidden-plugin"))
+
+					plugins, err = inventory.GetPlugins(context.Background(), &PluginInventoryFilter{IncludeHidden: true, SortBy: PluginSortByTarget, SortOrder: SortOrderDescending})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(plugins)).To(Equal(3))
+					Expect(plugins[0].Name).To(Equal("management-cluster"))
+					Expect(plugins[1].Name).To(Equal("hidden-plugin"))
+					Expect(plugins[2].Name).To(Equal("isolated-cluster"))
+				})
+				It("should return an error for an invalid sort", func() {
+					_, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{SortBy: "size"})
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("invalid field 'size' to sort the plugins by"))
+				})
+			})
+			Context("When searching plugins by keywords", func() {
+				It("should return the plugins whose name or description contains all the keywords", func() {
+					plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{SearchText: "CLUSTER  operations", IncludeHidden: true})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(plugins)).To(Equal(1))
+					Expect(plugins[0].Name).To(Equal("management-cluster"))
+
+					plugins, err = inventory.GetPlugins(context.Background(), &PluginInventoryFilter{SearchText: "isolated-clus"})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(plugins)).To(Equal(1))
+					Expect(plugins[0].Name).To(Equal("isolated-cluster"))
+
+					plugins, err = inventory.GetPlugins(context.Background(), &PluginInventoryFilter{SearchText: "cluster"})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(plugins)).To(Equal(2))
+				})
+				It("should match the special characters of the keywords literally", func() {
+					plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{SearchText: "clus_er"})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(plugins).To(BeEmpty())
+
+					plugins, err = inventory.GetPlugins(context.Background(), &PluginInventoryFilter{SearchText: "cluster's 100%"})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(plugins).To(BeEmpty())
+				})
+			})
+			Context("When getting the plugins by page", func() {
+				It("should return the plugins of the page with all their versions", func() {
+					plugins, err := inventory.GetPluginsPage(context.Background(), &PluginInventoryFilter{IncludeHidden: true}, 0, 2)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(plugins)).To(Equal(2))
+					Expect(plugins[0].Name).To(Equal("hidden-plugin"))
+					Expect(plugins[1].Name).To(Equal("isolated-cluster"))
+					Expect(len(plugins[1].Artifacts)).To(Equal(2))
+
+					plugins, err = inventory.GetPluginsPage(context.Background(), &PluginInventoryFilter{IncludeHidden: true}, 2, 2)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(plugins)).To(Equal(1))
+					Expect(plugins[0].Name).To(Equal("management-cluster"))
+					Expect(len(plugins[0].Artifacts)).To(Equal(2))
+					Expect(len(plugins[0].Artifacts["v0.28.0"])).To(Equal(2))
+
+					plugins, err = inventory.GetPluginsPage(context.Background(), &PluginInventoryFilter{IncludeHidden: true}, 3, 2)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(plugins).To(BeEmpty())
+				})
+				It("should only page through the plugins matching the filter", func() {
+					plugins, err := inventory.GetPluginsPage(context.Background(), nil, 1, 5)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(plugins)).To(Equal(1))
+					Expect(plugins[0].Name).To(Equal("management-cluster"))
+
+					plugins, err = inventory.GetPluginsPage(context.Background(), &PluginInventoryFilter{Name: "isolated-cluster", Version: cli.VersionLatest}, 0, 1)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(plugins)).To(Equal(1))
+					Expect(plugins[0].Artifacts).To(HaveKey("v1.2.3"))
+				})
+				It("should return an error for an invalid page", func() {
+					_, err := inventory.GetPluginsPage(context.Background(), nil, 0, 0)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("invalid page of plugins"))
+				})
+			})
+			Context("When the context is cancelled", func() {
+				It("should return the error of the context", func() {
+					ctx, cancel := context.WithCancel(context.Background())
+					cancel()
+
+					_, err := inventory.GetAllPlugins(ctx)
+					Expect(err).To(MatchError(context.Canceled))
+					_, err = inventory.GetPluginsPage(ctx, nil, 0, 1)
+					Expect(err).To(MatchError(context.Canceled))
+					err = inventory.WalkPlugins(ctx, nil, func(*PluginInventoryEntry) error { return nil })
+					Expect(err).To(MatchError(context.Canceled))
+					_, err = inventory.GetPluginGroups(ctx, PluginGroupFilter{})
+					Expect(err).To(MatchError(context.Canceled))
+				})
+			})
 		})
 		Describe("With a DB table with one plugin and no recommended version", func() {
 			BeforeEach(func() {
@@ -749,17 +906,8 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 				// Create DB file
 				dbFile, err = os.Create(filepath.Join(tmpDir, SQliteDBFileName))
 				Expect(err).To(BeNil())
-				// Open DB with the sqlite driver
-				db, err := sql.Open("sqlite", dbFile.Name())
-				Expect(err).To(BeNil(), "failed to open the DB for testing")
-				defer db.Close()
-
-				// Create the table
-				_, err = db.Exec(CreateTablesSchema)
-				Expect(err).To(BeNil(), "failed to create DB table for testing")
-
-				// Add plugin entries to the DB
-				_, err = db.Exec(createPluginTMCNoRecommendedVersionStmt)
+				// Create the tables and add plugin entries to the DB
+				err = CreateInventoryDB(dbFile.Name(), pluginTMCNoRecommendedVersionSeed)
 				Expect(err).To(BeNil(), "failed to create plugin for testing")
 
 				inventory = NewSQLiteInventory(dbFile.Name(), tmpDir)
@@ -769,7 +917,7 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 			})
 			Context("When getting all plugins", func() {
 				It("should return a list of one plugin with no error and recommended version set", func() {
-					plugins, err := inventory.GetAllPlugins()
+					plugins, err := inventory.GetAllPlugins(context.Background())
 					Expect(err).ToNot(HaveOccurred())
 					Expect(len(plugins)).To(Equal(1))
 
@@ -795,7 +943,7 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 			})
 			Context("When getting all plugins including hidden ones", func() {
 				It("should return a list of one plugin with no error and recommended version set to the hidden version", func() {
-					plugins, err := inventory.GetPlugins(&PluginInventoryFilter{IncludeHidden: true})
+					plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{IncludeHidden: true})
 					Expect(err).ToNot(HaveOccurred())
 					Expect(len(plugins)).To(Equal(1))
 
@@ -822,7 +970,7 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 			})
 			Context("When getting a non-existent plugin version for tmc for an os/arch", func() {
 				It("should return an empty list of plugins no error", func() {
-					plugins, err := inventory.GetPlugins(&PluginInventoryFilter{
+					plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{
 						Name:    "management-cluster",
 						Target:  "mission-control",
 						Version: "v1.2.3",
@@ -852,7 +1000,7 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 				os.RemoveAll(tmpDir)
 			})
 			It("should return an error", func() {
-				_, err = inventory.GetPluginGroups(PluginGroupFilter{})
+				_, err = inventory.GetPluginGroups(context.Background(), PluginGroupFilter{})
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("unable to setup DB"))
 			})
@@ -880,7 +1028,7 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 				os.RemoveAll(tmpDir)
 			})
 			It("should return an empty list of plugin groups with no error", func() {
-				groups, err := inventory.GetPluginGroups(PluginGroupFilter{})
+				groups, err := inventory.GetPluginGroups(context.Background(), PluginGroupFilter{})
 				Expect(err).ToNot(HaveOccurred())
 				Expect(len(groups)).To(Equal(0))
 			})
@@ -893,17 +1041,8 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 				// Create DB file
 				dbFile, err = os.Create(filepath.Join(tmpDir, SQliteDBFileName))
 				Expect(err).To(BeNil())
-				// Open DB with the sqlite driver
-				db, err := sql.Open("sqlite", dbFile.Name())
-				Expect(err).To(BeNil(), "failed to open the DB for testing")
-				defer db.Close()
-
-				// Create the table
-				_, err = db.Exec(CreateTablesSchema)
-				Expect(err).To(BeNil(), "failed to create DB table for testing")
-
-				// Add a plugin entry to the DB
-				_, err = db.Exec(createGroupsStmt)
+				// Create the tables and add plugin-group entries to the DB
+				err = CreateInventoryDB(dbFile.Name(), groupsSeed)
 				Expect(err).To(BeNil(), "failed to create groups for testing")
 
 				inventory = NewSQLiteInventory(dbFile.Name(), tmpDir)
@@ -913,7 +1052,7 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 			})
 			Context("When getting all groups", func() {
 				It("should return a list of two groups with no error", func() {
-					groups, err := inventory.GetPluginGroups(PluginGroupFilter{})
+					groups, err := inventory.GetPluginGroups(context.Background(), PluginGroupFilter{})
 					Expect(err).ToNot(HaveOccurred())
 					Expect(len(groups)).To(Equal(2))
 
@@ -994,7 +1133,7 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 			})
 			Context("When getting a group with a specific vendor-publisher/name", func() {
 				It("should return a list of one group with no error", func() {
-					groups, err := inventory.GetPluginGroups(PluginGroupFilter{
+					groups, err := inventory.GetPluginGroups(context.Background(), PluginGroupFilter{
 						Vendor:    "vmware",
 						Publisher: "tkg",
 						Name:      "default",
@@ -1036,7 +1175,7 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 			})
 			Context("When getting groups for a vendor", func() {
 				It("should return a list of three groups with no error", func() {
-					groups, err := inventory.GetPluginGroups(PluginGroupFilter{Vendor: "independent"})
+					groups, err := inventory.GetPluginGroups(context.Background(), PluginGroupFilter{Vendor: "independent"})
 					Expect(err).ToNot(HaveOccurred())
 					Expect(len(groups)).To(Equal(1))
 
@@ -1063,9 +1202,44 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 					Expect(plugins[j].Version).To(Equal("v0.2.0"))
 				})
 			})
+			Context("When getting groups with a name containing a substring", func() {
+				It("should return the groups whose name contains the substring, whatever its case", func() {
+					groups, err := inventory.GetPluginGroups(context.Background(), PluginGroupFilter{NameContains: "GROUP"})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(groups)).To(Equal(1))
+					Expect(groups[0].Name).To(Equal("mygroup"))
+
+					groups, err = inventory.GetPluginGroups(context.Background(), PluginGroupFilter{NameContains: "%"})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(groups).To(BeEmpty())
+				})
+			})
+			Context("When getting groups including a plugin", func() {
+				It("should return the versions of the groups which include the plugin", func() {
+					groups, err := inventory.GetPluginGroups(context.Background(), PluginGroupFilter{PluginName: "Isolated-Cluster"})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(groups)).To(Equal(1))
+					Expect(groups[0].Name).To(Equal("default"))
+					Expect(groups[0].RecommendedVersion).To(Equal("v2.1.0"))
+					Expect(len(groups[0].Versions)).To(Equal(1))
+					Expect(len(groups[0].Versions["v2.1.0"])).To(Equal(5))
+				})
+				It("should return the groups which include the plugin for the target", func() {
+					groups, err := inventory.GetPluginGroups(context.Background(), PluginGroupFilter{PluginName: "plugin2", PluginTarget: types.TargetTMC, IncludeHidden: true})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(groups)).To(Equal(2))
+					sort.Sort(pluginGroupSorter(groups))
+					Expect(groups[0].Name).To(Equal("hidden"))
+					Expect(groups[1].Name).To(Equal("mygroup"))
+
+					groups, err = inventory.GetPluginGroups(context.Background(), PluginGroupFilter{PluginName: "plugin2", PluginTarget: types.TargetK8s, IncludeHidden: true})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(groups).To(BeEmpty())
+				})
+			})
 			Context("When getting all groups including hidden ones", func() {
 				It("should return a list of three groups with no error", func() {
-					groups, err := inventory.GetPluginGroups(PluginGroupFilter{IncludeHidden: true})
+					groups, err := inventory.GetPluginGroups(context.Background(), PluginGroupFilter{IncludeHidden: true})
 					Expect(err).ToNot(HaveOccurred())
 					Expect(len(groups)).To(Equal(3))
 
@@ -1162,7 +1336,7 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 			})
 			Context("When getting groups for a publisher including hidden ones", func() {
 				It("should return a list of four groups with no error", func() {
-					groups, err := inventory.GetPluginGroups(PluginGroupFilter{
+					groups, err := inventory.GetPluginGroups(context.Background(), PluginGroupFilter{
 						Publisher:     "other",
 						IncludeHidden: true,
 					})
@@ -1221,23 +1395,36 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 			Expect(err).To(BeNil())
 
 			inventory = NewSQLiteInventory(dbFile.Name(), tmpDir)
-			err = inventory.CreateSchema()
+			err = inventory.CreateSchema(context.Background())
 			Expect(err).To(BeNil(), "failed to create DB schema for testing")
 		})
 		AfterEach(func() {
 			os.RemoveAll(tmpDir)
 		})
+		Context("When inserting plugins with a cancelled context", func() {
+			It("should return the error of the context and not insert the plugins", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				err = inventory.InsertPlugins(ctx, []*PluginInventoryEntry{&piEntry1, &piEntry2})
+				Expect(err).To(MatchError(context.Canceled))
+
+				plugins, err := inventory.GetAllPlugins(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(plugins).To(BeEmpty())
+			})
+		})
 		Context("When inserting plugins", func() {
 			It("operation should be successful and getplugins should return the correct result of the plugins with no error", func() {
-				err = inventory.InsertPlugin(&piEntry1)
+				err = inventory.InsertPlugin(context.Background(), &piEntry1)
 				Expect(err).To(BeNil(), "failed to insert plugin1")
-				err = inventory.InsertPlugin(&piEntry2)
+				err = inventory.InsertPlugin(context.Background(), &piEntry2)
 				Expect(err).To(BeNil(), "failed to insert plugin2")
-				err = inventory.InsertPlugin(&piEntry3)
+				err = inventory.InsertPlugin(context.Background(), &piEntry3)
 				Expect(err).To(BeNil(), "failed to insert plugin3")
 
 				// Verify that "management-cluster" plugin with "kubernetes" target can be retrieved and all configuration are correct
-				plugins, err := inventory.GetPlugins(&PluginInventoryFilter{Name: "management-cluster", Target: types.TargetK8s})
+				plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "management-cluster", Target: types.TargetK8s})
 				Expect(err).ToNot(HaveOccurred())
 				Expect(len(plugins)).To(Equal(1))
 				p := plugins[0]
@@ -1268,7 +1455,7 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 				}
 
 				// Verify that "isolated-cluster" plugin with "global" target can be retrieved and all configuration are correct
-				plugins, err = inventory.GetPlugins(&PluginInventoryFilter{Name: "isolated-cluster", Target: types.TargetGlobal})
+				plugins, err = inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "isolated-cluster", Target: types.TargetGlobal})
 				Expect(err).ToNot(HaveOccurred())
 				Expect(len(plugins)).To(Equal(1))
 				p = plugins[0]
@@ -1287,7 +1474,7 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 				Expect(a[0].Image).To(Equal(tmpDir + "/othervendor/otherpublisher/linux/amd64/global/isolated-cluster:v1.2.3"))
 
 				// Verify that "management-cluster" plugin with "mission-control" target can be retrieved and all configuration are correct
-				plugins, err = inventory.GetPlugins(&PluginInventoryFilter{Name: "management-cluster", Target: types.TargetTMC})
+				plugins, err = inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "management-cluster", Target: types.TargetTMC})
 				Expect(err).ToNot(HaveOccurred())
 				Expect(len(plugins)).To(Equal(1))
 				p = plugins[0]
@@ -1306,23 +1493,469 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 				Expect(a[0].Image).To(Equal(tmpDir + "/vmware/tmc/linux/amd64/tmc/management-cluster:v0.0.1"))
 
 				// Verify that retrieving any plugin that doesn't exist should return empty array
-				plugins, err = inventory.GetPlugins(&PluginInventoryFilter{Name: "unknown", Target: types.TargetTMC})
+				plugins, err = inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "unknown", Target: types.TargetTMC})
 				Expect(err).ToNot(HaveOccurred())
 				Expect(len(plugins)).To(Equal(0))
 			})
 		})
+		Context("When inserting plugins with local file and HTTPS artifact URIs", func() {
+			It("should return the URIs as is instead of building image references", func() {
+				entry := PluginInventoryEntry{
+					Name:        "uri-plugin",
+					Target:      types.TargetGlobal,
+					Description: "Plugin distributed with URIs",
+					Publisher:   "tkg",
+					Vendor:      "vmware",
+					Artifacts: distribution.Artifacts{
+						"v1.0.0": []distribution.Artifact{
+							{OS: "linux", Arch: "amd64", Digest: "0000000000", URI: "file:///tmp/plugins/uri-plugin"},
+							{OS: "darwin", Arch: "amd64", Digest: "1111111111", URI: "https://example.com/plugins/uri-plugin"},
+						},
+					},
+				}
+				err = inventory.InsertPlugin(context.Background(), &entry)
+				Expect(err).To(BeNil())
+
+				plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "uri-plugin", Target: types.TargetGlobal})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				for _, a := range plugins[0].Artifacts["v1.0.0"] {
+					Expect(a.Image).To(BeEmpty())
+					if a.OS == "linux" {
+						Expect(a.URI).To(Equal("file:///tmp/plugins/uri-plugin"))
+					} else {
+						Expect(a.URI).To(Equal("https://example.com/plugins/uri-plugin"))
+					}
+				}
+			})
+		})
+		Context("When inserting a plugin published as a multi-arch image index", func() {
+			It("should return the multi-arch artifact for any os/arch", func() {
+				entry := PluginInventoryEntry{
+					Name:        "multiarch-plugin",
+					Target:      types.TargetGlobal,
+					Description: "Plugin published as a multi-arch image index",
+					Publisher:   "tkg",
+					Vendor:      "vmware",
+					Artifacts: distribution.Artifacts{
+						"v1.0.0": []distribution.Artifact{
+							{
+								OS:    distribution.ArtifactPlatformMultiArch,
+								Arch:  distribution.ArtifactPlatformMultiArch,
+								Image: "vmware/tkg/global/multiarch-plugin:v1.0.0",
+							},
+						},
+					},
+				}
+				err = inventory.InsertPlugin(context.Background(), &entry)
+				Expect(err).To(BeNil())
+
+				plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "multiarch-plugin", OS: "darwin", Arch: "arm64"})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				a, err := plugins[0].Artifacts.GetArtifact("v1.0.0", "darwin", "arm64")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(a.IsMultiArch()).To(BeTrue())
+				Expect(a.Image).To(HaveSuffix("vmware/tkg/global/multiarch-plugin:v1.0.0"))
+			})
+		})
+		Context("When inserting a plugin with release notes", func() {
+			var entry PluginInventoryEntry
+			BeforeEach(func() {
+				entry = PluginInventoryEntry{
+					Name:        "notes-plugin",
+					Target:      types.TargetGlobal,
+					Description: "Plugin with release notes",
+					Publisher:   "tkg",
+					Vendor:      "vmware",
+					Artifacts: distribution.Artifacts{
+						"v1.0.0": []distribution.Artifact{{OS: "linux", Arch: "amd64", Digest: "0000000000", Image: "vmware/tkg/linux/amd64/global/notes-plugin:v1.0.0"}},
+						"v1.1.0": []distribution.Artifact{{OS: "linux", Arch: "amd64", Digest: "1111111111", Image: "vmware/tkg/linux/amd64/global/notes-plugin:v1.1.0"}},
+					},
+					ReleaseNotes: map[string]PluginReleaseNotes{
+						"v1.1.0": {ChangelogURL: "https://example.com/notes-plugin/v1.1.0", Notes: "Add the foo command"},
+					},
+				}
+			})
+			It("should return the release notes of the matching versions", func() {
+				err = inventory.InsertPlugin(context.Background(), &entry)
+				Expect(err).To(BeNil())
+
+				plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "notes-plugin", Target: types.TargetGlobal})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].ReleaseNotes).To(Equal(entry.ReleaseNotes))
+
+				plugins, err = inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "notes-plugin", Target: types.TargetGlobal, Version: "v1.0.0"})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].ReleaseNotes).To(BeEmpty())
+			})
+			It("should create the release notes table of an inventory which does not have it", func() {
+				db, err := sql.Open("sqlite", dbFile.Name())
+				Expect(err).To(BeNil())
+				_, err = db.Exec("DROP TABLE PluginBinaries; DROP TABLE PluginReleaseNotes; DROP TABLE SchemaVersion;")
+				Expect(err).To(BeNil())
+				db.Close()
+
+				err = inventory.InsertPlugin(context.Background(), &piEntry1)
+				Expect(err).To(BeNil())
+				plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: piEntry1.Name, Target: piEntry1.Target})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].ReleaseNotes).To(BeEmpty())
+
+				err = inventory.InsertPlugin(context.Background(), &entry)
+				Expect(err).To(BeNil())
+				plugins, err = inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "notes-plugin", Target: types.TargetGlobal})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(plugins[0].ReleaseNotes).To(Equal(entry.ReleaseNotes))
+			})
+			It("should only return the release notes of the vendor and publisher of the plugin", func() {
+				err = inventory.InsertPlugin(context.Background(), &entry)
+				Expect(err).To(BeNil())
+				db, err := sql.Open("sqlite", dbFile.Name())
+				Expect(err).To(BeNil())
+				_, err = db.Exec("INSERT INTO PluginReleaseNotes VALUES('notes-plugin','global','v1.0.0','https://example.com','Other notes','other','tkg');")
+				Expect(err).To(BeNil())
+				db.Close()
+
+				plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "notes-plugin", Target: types.TargetGlobal})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].ReleaseNotes).To(Equal(entry.ReleaseNotes))
+			})
+			It("should read and migrate the release notes of an inventory which does not have their vendor and publisher", func() {
+				err = inventory.InsertPlugin(context.Background(), &entry)
+				Expect(err).To(BeNil())
+				db, err := sql.Open("sqlite", dbFile.Name())
+				Expect(err).To(BeNil())
+				defer db.Close()
+				_, err = db.Exec(`DROP TABLE PluginReleaseNotes;
+CREATE TABLE PluginReleaseNotes (PluginName TEXT NOT NULL, Target TEXT NOT NULL, Version TEXT NOT NULL, ChangelogURL TEXT NOT NULL, Notes TEXT NOT NULL, PRIMARY KEY(PluginName, Target, Version));
+INSERT INTO PluginReleaseNotes VALUES('notes-plugin','global','v1.1.0','https://example.com/notes-plugin/v1.1.0','Add the foo command');
+DELETE FROM SchemaVersion WHERE Version = 8;`)
+				Expect(err).To(BeNil())
+
+				plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "notes-plugin", Target: types.TargetGlobal})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(plugins[0].ReleaseNotes).To(Equal(entry.ReleaseNotes))
+
+				Expect(inventory.MigrateSchema(context.Background())).To(Succeed())
+				var vendor, publisher string
+				Expect(db.QueryRow("SELECT Vendor, Publisher FROM PluginReleaseNotes WHERE PluginName = 'notes-plugin';").Scan(&vendor, &publisher)).To(Succeed())
+				Expect(vendor).To(Equal("vmware"))
+				Expect(publisher).To(Equal("tkg"))
+				plugins, err = inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "notes-plugin", Target: types.TargetGlobal})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(plugins[0].ReleaseNotes).To(Equal(entry.ReleaseNotes))
+			})
+		})
+		Context("When inserting a plugin with minimum CLI versions", func() {
+			var entry PluginInventoryEntry
+			BeforeEach(func() {
+				entry = PluginInventoryEntry{
+					Name:        "compat-plugin",
+					Target:      types.TargetK8s,
+					Description: "Plugin requiring a minimum CLI version",
+					Publisher:   "tkg",
+					Vendor:      "vmware",
+					Artifacts: distribution.Artifacts{
+						"v1.0.0": []distribution.Artifact{{OS: "linux", Arch: "amd64", Digest: "0000000000", Image: "vmware/tkg/linux/amd64/k8s/compat-plugin:v1.0.0"}},
+						"v2.0.0": []distribution.Artifact{{OS: "linux", Arch: "amd64", Digest: "2222222222", Image: "vmware/tkg/linux/amd64/k8s/compat-plugin:v2.0.0"}},
+					},
+					MinCLIVersions: map[string]string{"v2.0.0": "v1.2.0"},
+				}
+			})
+			It("should return the minimum CLI versions of the matching versions", func() {
+				err = inventory.InsertPlugin(context.Background(), &entry)
+				Expect(err).To(BeNil())
+
+				plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "compat-plugin", Target: types.TargetK8s})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].MinCLIVersions).To(Equal(entry.MinCLIVersions))
+				Expect(plugins[0].ReleaseNotes).To(BeEmpty())
+
+				plugins, err = inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "compat-plugin", Target: types.TargetK8s, Version: "v1.0.0"})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].MinCLIVersions).To(BeEmpty())
+			})
+			It("should create the compatibility table of an inventory which does not have it", func() {
+				db, err := sql.Open("sqlite", dbFile.Name())
+				Expect(err).To(BeNil())
+				_, err = db.Exec("DROP TABLE PluginCompatibility;")
+				Expect(err).To(BeNil())
+				db.Close()
+
+				err = inventory.InsertPlugin(context.Background(), &piEntry1)
+				Expect(err).To(BeNil())
+				plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: piEntry1.Name, Target: piEntry1.Target})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(plugins[0].MinCLIVersions).To(BeEmpty())
+
+				err = inventory.InsertPlugin(context.Background(), &entry)
+				Expect(err).To(BeNil())
+				plugins, err = inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "compat-plugin", Target: types.TargetK8s})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(plugins[0].MinCLIVersions).To(Equal(entry.MinCLIVersions))
+			})
+		})
+		Context("When inserting a plugin with licenses", func() {
+			var entry PluginInventoryEntry
+			BeforeEach(func() {
+				entry = PluginInventoryEntry{
+					Name:        "licensed-plugin",
+					Target:      types.TargetK8s,
+					Description: "Plugin with licenses",
+					Publisher:   "tkg",
+					Vendor:      "vmware",
+					Artifacts: distribution.Artifacts{
+						"v1.0.0": []distribution.Artifact{{OS: "linux", Arch: "amd64", Digest: "0000000000", Image: "vmware/tkg/linux/amd64/k8s/licensed-plugin:v1.0.0"}},
+						"v2.0.0": []distribution.Artifact{{OS: "linux", Arch: "amd64", Digest: "2222222222", Image: "vmware/tkg/linux/amd64/k8s/licensed-plugin:v2.0.0"}},
+					},
+					Licenses: map[string]PluginLicense{"v2.0.0": {License: "Apache-2.0", SBOMURI: "https://example.com/sbom/licensed-plugin-v2.0.0.spdx.json"}},
+				}
+			})
+			It("should return the licenses of the matching versions", func() {
+				err = inventory.InsertPlugin(context.Background(), &entry)
+				Expect(err).To(BeNil())
+
+				plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "licensed-plugin", Target: types.TargetK8s})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].Licenses).To(Equal(entry.Licenses))
+
+				plugins, err = inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "licensed-plugin", Target: types.TargetK8s, Version: "v1.0.0"})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].Licenses).To(BeEmpty())
+			})
+			It("should migrate an inventory which does not have the license table", func() {
+				db, err := sql.Open("sqlite", dbFile.Name())
+				Expect(err).To(BeNil())
+				_, err = db.Exec("DROP TABLE PluginBinaries; DROP TABLE PluginLicenses; DROP TABLE SchemaVersion;")
+				Expect(err).To(BeNil())
+				_, err = db.Exec(CreateTablesSchema)
+				Expect(err).To(BeNil())
+				db.Close()
+
+				plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{IncludeHidden: true})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(plugins).To(BeEmpty())
+
+				err = inventory.InsertPlugin(context.Background(), &entry)
+				Expect(err).To(BeNil())
+				plugins, err = inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "licensed-plugin", Target: types.TargetK8s})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(plugins[0].Licenses).To(Equal(entry.Licenses))
+			})
+		})
+		Context("When inserting a deprecated plugin", func() {
+			var entry PluginInventoryEntry
+			BeforeEach(func() {
+				entry = PluginInventoryEntry{
+					Name:        "old-plugin",
+					Target:      types.TargetK8s,
+					Description: "Deprecated plugin",
+					Publisher:   "tkg",
+					Vendor:      "vmware",
+					Artifacts: distribution.Artifacts{
+						"v1.0.0": []distribution.Artifact{{OS: "linux", Arch: "amd64", Digest: "0000000000", Image: "vmware/tkg/linux/amd64/k8s/old-plugin:v1.0.0"}},
+					},
+					Deprecated:         true,
+					DeprecationMessage: "The plugin is no longer maintained",
+					ReplacedBy:         "new-plugin",
+				}
+			})
+			It("should return the deprecation of the plugin", func() {
+				err = inventory.InsertPlugins(context.Background(), []*PluginInventoryEntry{&entry, &piEntry1})
+				Expect(err).To(BeNil())
+
+				plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "old-plugin", Target: types.TargetK8s})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].Deprecated).To(BeTrue())
+				Expect(plugins[0].DeprecationMessage).To(Equal(entry.DeprecationMessage))
+				Expect(plugins[0].ReplacedBy).To(Equal(entry.ReplacedBy))
+
+				plugins, err = inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: piEntry1.Name, Target: piEntry1.Target})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(plugins[0].Deprecated).To(BeFalse())
+				Expect(plugins[0].DeprecationMessage).To(BeEmpty())
+				Expect(plugins[0].ReplacedBy).To(BeEmpty())
+			})
+			It("should read and migrate an inventory which does not have the deprecation columns", func() {
+				db, err := sql.Open("sqlite", dbFile.Name())
+				Expect(err).To(BeNil())
+				_, err = db.Exec("DROP TABLE PluginBinaries; DROP TABLE SchemaVersion;")
+				Expect(err).To(BeNil())
+				_, err = db.Exec(CreateTablesSchema)
+				Expect(err).To(BeNil())
+				_, err = db.Exec(`INSERT INTO PluginBinaries VALUES ('management-cluster','kubernetes','','v0.28.0','false','Plugin management-cluster description','tkg','vmware','darwin','amd64','0000000000','vmware/tkg/darwin/amd64/kubernetes/management-cluster:v0.28.0');`)
+				Expect(err).To(BeNil())
+				db.Close()
+
+				plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "management-cluster"})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].Deprecated).To(BeFalse())
+
+				err = inventory.InsertPlugin(context.Background(), &entry)
+				Expect(err).To(BeNil())
+				plugins, err = inventory.GetPlugins(context.Background(), &PluginInventoryFilter{IncludeHidden: true})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(2))
+				Expect(plugins[0].Name).To(Equal("management-cluster"))
+				Expect(plugins[0].Deprecated).To(BeFalse())
+				Expect(plugins[1].Name).To(Equal("old-plugin"))
+				Expect(plugins[1].Deprecated).To(BeTrue())
+			})
+		})
+		Context("When inserting a plugin with the size and signature of its artifacts", func() {
+			It("should return the size and signature of the artifacts", func() {
+				entry := PluginInventoryEntry{
+					Name:        "sized-plugin",
+					Target:      types.TargetK8s,
+					Description: "Plugin with sized artifacts",
+					Publisher:   "tkg",
+					Vendor:      "vmware",
+					Artifacts: distribution.Artifacts{
+						"v1.0.0": []distribution.Artifact{
+							{OS: "darwin", Arch: "amd64", Digest: "0000000000", Image: "vmware/tkg/darwin/amd64/k8s/sized-plugin:v1.0.0", Size: 52428800, Signature: "vmware/tkg/darwin/amd64/k8s/sized-plugin:sha256-0000000000.sig"},
+							{OS: "linux", Arch: "amd64", Digest: "1111111111", Image: "vmware/tkg/linux/amd64/k8s/sized-plugin:v1.0.0"},
+						},
+					},
+				}
+				err = inventory.InsertPlugin(context.Background(), &entry)
+				Expect(err).To(BeNil())
+
+				plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "sized-plugin"})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				size, err := plugins[0].Artifacts.GetSize("v1.0.0", "darwin", "amd64")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(size).To(Equal(int64(52428800)))
+				size, err = plugins[0].Artifacts.GetSize("v1.0.0", "linux", "amd64")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(size).To(BeZero())
+
+				artifact, err := plugins[0].Artifacts.DescribeArtifact("v1.0.0", "darwin", "amd64")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(artifact.Signature).To(Equal("vmware/tkg/darwin/amd64/k8s/sized-plugin:sha256-0000000000.sig"))
+			})
+		})
+		Context("When inserting a plugin with the release times of its versions", func() {
+			It("should return the release times of the versions", func() {
+				releasedAt := time.Date(2023, 6, 1, 12, 30, 0, 0, time.UTC)
+				entry := PluginInventoryEntry{
+					Name:        "released-plugin",
+					Target:      types.TargetK8s,
+					Description: "Plugin with release times",
+					Publisher:   "tkg",
+					Vendor:      "vmware",
+					Artifacts: distribution.Artifacts{
+						"v1.0.0": []distribution.Artifact{
+							{OS: "darwin", Arch: "amd64", Digest: "0000000000", Image: "vmware/tkg/darwin/amd64/k8s/released-plugin:v1.0.0"},
+							{OS: "linux", Arch: "amd64", Digest: "1111111111", Image: "vmware/tkg/linux/amd64/k8s/released-plugin:v1.0.0"},
+						},
+						"v1.1.0": []distribution.Artifact{
+							{OS: "linux", Arch: "amd64", Digest: "2222222222", Image: "vmware/tkg/linux/amd64/k8s/released-plugin:v1.1.0"},
+						},
+					},
+					ReleasedAt: map[string]time.Time{"v1.0.0": releasedAt},
+				}
+				err = inventory.InsertPlugin(context.Background(), &entry)
+				Expect(err).To(BeNil())
+
+				plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "released-plugin"})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].ReleasedAt).To(HaveLen(1))
+				Expect(plugins[0].ReleasedAt["v1.0.0"].Equal(releasedAt)).To(BeTrue())
+			})
+		})
+		Context("When getting the plugins for an architecture without artifact for some versions", func() {
+			BeforeEach(func() {
+				entry := PluginInventoryEntry{
+					Name:        "intel-plugin",
+					Target:      types.TargetK8s,
+					Description: "Plugin without arm64 artifact for its latest version",
+					Publisher:   "tkg",
+					Vendor:      "vmware",
+					Artifacts: distribution.Artifacts{
+						"v1.0.0": []distribution.Artifact{
+							{OS: "darwin", Arch: "amd64", Digest: "0000000000", Image: "vmware/tkg/darwin/amd64/k8s/intel-plugin:v1.0.0"},
+							{OS: "darwin", Arch: "arm64", Digest: "1111111111", Image: "vmware/tkg/darwin/arm64/k8s/intel-plugin:v1.0.0"},
+						},
+						"v2.0.0": []distribution.Artifact{
+							{OS: "darwin", Arch: "amd64", Digest: "2222222222", Image: "vmware/tkg/darwin/amd64/k8s/intel-plugin:v2.0.0"},
+							{OS: "linux", Arch: "amd64", Digest: "3333333333", Image: "vmware/tkg/linux/amd64/k8s/intel-plugin:v2.0.0"},
+						},
+					},
+				}
+				err = inventory.InsertPlugin(context.Background(), &entry)
+				Expect(err).To(BeNil())
+			})
+			It("should only return the versions with an artifact for the architecture without fallback", func() {
+				plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "intel-plugin", OS: "darwin", Arch: "arm64"})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].Artifacts).To(HaveLen(1))
+				Expect(plugins[0].Artifacts["v1.0.0"]).To(HaveLen(1))
+				Expect(plugins[0].Artifacts["v1.0.0"][0].Arch).To(Equal("arm64"))
+				Expect(plugins[0].RecommendedVersion).To(Equal("v1.0.0"))
+			})
+			It("should return the amd64 artifacts of the versions without arm64 artifact with the emulation fallback", func() {
+				plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "intel-plugin", OS: "darwin", Arch: "arm64", ArchFallback: ArchFallbackEmulation})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].Artifacts).To(HaveLen(2))
+				Expect(plugins[0].Artifacts["v1.0.0"]).To(HaveLen(1))
+				Expect(plugins[0].Artifacts["v1.0.0"][0].Arch).To(Equal("arm64"))
+				Expect(plugins[0].Artifacts["v2.0.0"]).To(HaveLen(1))
+				Expect(plugins[0].Artifacts["v2.0.0"][0].Arch).To(Equal("amd64"))
+				Expect(plugins[0].Artifacts["v2.0.0"][0].OS).To(Equal("darwin"))
+				Expect(plugins[0].RecommendedVersion).To(Equal("v2.0.0"))
+			})
+			It("should not fall back for linux", func() {
+				plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "intel-plugin", OS: "linux", Arch: "arm64", ArchFallback: ArchFallbackEmulation})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(plugins).To(BeEmpty())
+			})
+		})
 		Context("When inserting a plugin which already exists in the database", func() {
 			BeforeEach(func() {
-				err = inventory.InsertPlugin(&piEntry1)
+				err = inventory.InsertPlugin(context.Background(), &piEntry1)
 				Expect(err).To(BeNil(), "failed to insert plugin1")
 			})
 			It("should return an error", func() {
-				err = inventory.InsertPlugin(&piEntry1)
+				err = inventory.InsertPlugin(context.Background(), &piEntry1)
 				Expect(err).NotTo(BeNil())
 				Expect(err.Error()).To(ContainSubstring("unable to insert plugin row"))
 				Expect(err.Error()).To(ContainSubstring("UNIQUE constraint failed"))
 			})
 		})
+		Context("When inserting several plugins at once", func() {
+			It("should insert all the plugins", func() {
+				err = inventory.InsertPlugins(context.Background(), []*PluginInventoryEntry{&piEntry1, &piEntry2, &piEntry3})
+				Expect(err).To(BeNil())
+
+				plugins, err := inventory.GetAllPlugins(context.Background())
+				Expect(err).To(BeNil())
+				Expect(len(plugins)).To(Equal(3))
+			})
+			It("should insert none of the plugins if one of them cannot be inserted", func() {
+				err = inventory.InsertPlugins(context.Background(), []*PluginInventoryEntry{&piEntry1, &piEntry2, &piEntry1})
+				Expect(err).NotTo(BeNil())
+				Expect(err.Error()).To(ContainSubstring("UNIQUE constraint failed"))
+
+				plugins, err := inventory.GetAllPlugins(context.Background())
+				Expect(err).To(BeNil())
+				Expect(plugins).To(BeEmpty())
+			})
+		})
 	})
 
 	Describe("Inserting plugin-groups to inventory and verifying it with GetPluginGroups", func() {
@@ -1335,15 +1968,15 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 			Expect(err).To(BeNil())
 
 			inventory = NewSQLiteInventory(dbFile.Name(), tmpDir)
-			err = inventory.CreateSchema()
+			err = inventory.CreateSchema(context.Background())
 			Expect(err).To(BeNil(), "failed to create DB schema for testing")
-			err = inventory.InsertPlugin(&piEntry1)
+			err = inventory.InsertPlugin(context.Background(), &piEntry1)
 			Expect(err).To(BeNil(), "failed to insert plugin1")
-			err = inventory.InsertPlugin(&piEntry2)
+			err = inventory.InsertPlugin(context.Background(), &piEntry2)
 			Expect(err).To(BeNil(), "failed to insert plugin2")
-			err = inventory.InsertPlugin(&piEntry3)
+			err = inventory.InsertPlugin(context.Background(), &piEntry3)
 			Expect(err).To(BeNil(), "failed to insert plugin3")
-			err = inventory.InsertPlugin(&hiddenPluginEntry)
+			err = inventory.InsertPlugin(context.Background(), &hiddenPluginEntry)
 			Expect(err).To(BeNil(), "failed to insert hidden-plugin")
 		})
 		AfterEach(func() {
@@ -1370,7 +2003,7 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 						},
 					},
 				}
-				err = inventory.InsertPluginGroup(pg, false)
+				err = inventory.InsertPluginGroup(context.Background(), pg, false)
 				Expect(err).NotTo(BeNil())
 				Expect(err.Error()).To(ContainSubstring("specified plugin 'name:fake-plugin', 'target:global', 'version:v1.0.0' is not present in the database"))
 			})
@@ -1396,17 +2029,17 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 						},
 					},
 				}
-				err = inventory.InsertPluginGroup(pg, false)
+				err = inventory.InsertPluginGroup(context.Background(), pg, false)
 				Expect(err).NotTo(BeNil())
 				Expect(err.Error()).To(ContainSubstring("specified plugin 'name:mission-control', 'target:kubernetes', 'version:v1.0.0' is not present in the database"))
 			})
 		})
 		Context("When inserting plugin-group with all specified plugins and their versions exist in the database", func() {
 			It("should not return error and GetPluginGroups should return correct result", func() {
-				err = inventory.InsertPluginGroup(&pluginGroup1, false)
+				err = inventory.InsertPluginGroup(context.Background(), &pluginGroup1, false)
 				Expect(err).To(BeNil())
 
-				groups, err := inventory.GetPluginGroups(PluginGroupFilter{})
+				groups, err := inventory.GetPluginGroups(context.Background(), PluginGroupFilter{})
 				Expect(err).ToNot(HaveOccurred())
 				Expect(len(groups)).To(Equal(1))
 				group := groups[0]
@@ -1445,7 +2078,7 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 		})
 		Context("When inserting a plugin-group containing hidden plugins that are present in the database", func() {
 			It("should return an error", func() {
-				err = inventory.InsertPluginGroup(&groupWithHiddenPlugin, false)
+				err = inventory.InsertPluginGroup(context.Background(), &groupWithHiddenPlugin, false)
 				Expect(err).NotTo(BeNil())
 				Expect(err.Error()).To(ContainSubstring("specified plugin 'name:hidden-plugin', 'target:kubernetes', 'version:v0.0.1' is not present in the database"))
 			})
@@ -1454,10 +2087,10 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 				defer os.Unsetenv(constants.ConfigVariableIncludeDeactivatedPluginsForTesting)
 				Expect(err).To(BeNil())
 
-				err = inventory.InsertPluginGroup(&groupWithHiddenPlugin, false)
+				err = inventory.InsertPluginGroup(context.Background(), &groupWithHiddenPlugin, false)
 				Expect(err).To(BeNil())
 
-				groups, err := inventory.GetPluginGroups(PluginGroupFilter{})
+				groups, err := inventory.GetPluginGroups(context.Background(), PluginGroupFilter{})
 				Expect(err).ToNot(HaveOccurred())
 				Expect(len(groups)).To(Equal(1))
 				Expect(groups[0].Name).To(Equal(groupWithHiddenPlugin.Name))
@@ -1479,11 +2112,11 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 		})
 		Context("When inserting a plugin-group which already exists in the database", func() {
 			BeforeEach(func() {
-				err = inventory.InsertPluginGroup(&pluginGroup1, false)
+				err = inventory.InsertPluginGroup(context.Background(), &pluginGroup1, false)
 				Expect(err).To(BeNil())
 			})
 			It("should return an error", func() {
-				err = inventory.InsertPluginGroup(&pluginGroup1, false)
+				err = inventory.InsertPluginGroup(context.Background(), &pluginGroup1, false)
 				Expect(err).NotTo(BeNil())
 				Expect(err.Error()).To(ContainSubstring("unable to insert plugin-group row"))
 				Expect(err.Error()).To(ContainSubstring("UNIQUE constraint failed"))
@@ -1491,7 +2124,7 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 		})
 		Context("When inserting a plugin-group which already exists in the database with override flag", func() {
 			BeforeEach(func() {
-				err = inventory.InsertPluginGroup(&pluginGroup1, false)
+				err = inventory.InsertPluginGroup(context.Background(), &pluginGroup1, false)
 				Expect(err).To(BeNil())
 			})
 			It("should not return error and GetPluginGroups should return the updated result", func() {
@@ -1521,11 +2154,11 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 					},
 				}
 
-				err = inventory.InsertPluginGroup(&pluginGroupUpdated, true)
+				err = inventory.InsertPluginGroup(context.Background(), &pluginGroupUpdated, true)
 				Expect(err).To(BeNil())
 
 				// Verify the result using GetPluginGroups
-				groups, err := inventory.GetPluginGroups(PluginGroupFilter{})
+				groups, err := inventory.GetPluginGroups(context.Background(), PluginGroupFilter{})
 				Expect(err).ToNot(HaveOccurred())
 				Expect(len(groups)).To(Equal(1))
 				Expect(groups[0].Name).To(Equal(pluginGroup1.Name))
@@ -1549,6 +2182,29 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 				Expect(plugins[1].Version).To(Equal(pluginGroupUpdated.Versions["v1.0.0"][1].Version))
 				Expect(plugins[1].Mandatory).To(Equal(pluginGroupUpdated.Versions["v1.0.0"][1].Mandatory))
 			})
+			It("should keep the existing plugin-group if a plugin of the updated plugin-group does not exist", func() {
+				pluginGroupUpdated := pluginGroup1
+				pluginGroupUpdated.Versions = map[string][]*PluginGroupPluginEntry{
+					"v1.0.0": {
+						{
+							PluginIdentifier: PluginIdentifier{
+								Name:    "fake-plugin",
+								Target:  types.TargetGlobal,
+								Version: "v1.0.0",
+							},
+						},
+					},
+				}
+
+				err = inventory.InsertPluginGroup(context.Background(), &pluginGroupUpdated, true)
+				Expect(err).NotTo(BeNil())
+				Expect(err.Error()).To(ContainSubstring("specified plugin 'name:fake-plugin', 'target:global', 'version:v1.0.0' is not present in the database"))
+
+				groups, err := inventory.GetPluginGroups(context.Background(), PluginGroupFilter{IncludeHidden: true})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(groups)).To(Equal(1))
+				Expect(len(groups[0].Versions["v1.0.0"])).To(Equal(len(pluginGroup1.Versions["v1.0.0"])))
+			})
 		})
 
 	})
@@ -1563,13 +2219,13 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 			Expect(err).To(BeNil())
 
 			inventory = NewSQLiteInventory(dbFile.Name(), tmpDir)
-			err = inventory.CreateSchema()
+			err = inventory.CreateSchema(context.Background())
 			Expect(err).To(BeNil(), "failed to create DB schema for testing")
-			err = inventory.InsertPlugin(&piEntry1)
+			err = inventory.InsertPlugin(context.Background(), &piEntry1)
 			Expect(err).To(BeNil(), "failed to insert plugin1")
-			err = inventory.InsertPlugin(&piEntry2)
+			err = inventory.InsertPlugin(context.Background(), &piEntry2)
 			Expect(err).To(BeNil(), "failed to insert plugin2")
-			err = inventory.InsertPlugin(&piEntry3)
+			err = inventory.InsertPlugin(context.Background(), &piEntry3)
 			Expect(err).To(BeNil(), "failed to insert plugin3")
 		})
 		AfterEach(func() {
@@ -1578,15 +2234,15 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 
 		Context("When updating the activation state of a plugin-group which already exists in the database", func() {
 			BeforeEach(func() {
-				err = inventory.InsertPluginGroup(&pluginGroup1, false)
+				err = inventory.InsertPluginGroup(context.Background(), &pluginGroup1, false)
 				Expect(err).To(BeNil())
 			})
 			It("should not return error when no change has been done to the activation state and the GetPluginGroups should reflect the same", func() {
-				err = inventory.UpdatePluginGroupActivationState(&pluginGroup1)
+				err = inventory.UpdatePluginGroupActivationState(context.Background(), &pluginGroup1)
 				Expect(err).To(BeNil())
 
 				// Verify the result using GetPluginGroups
-				groups, err := inventory.GetPluginGroups(PluginGroupFilter{})
+				groups, err := inventory.GetPluginGroups(context.Background(), PluginGroupFilter{})
 				Expect(err).ToNot(HaveOccurred())
 				Expect(len(groups)).To(Equal(1))
 				Expect(groups[0].Name).To(Equal(pluginGroup1.Name))
@@ -1601,11 +2257,11 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 			It("should not return error when the activation state has been updated and the GetPluginGroups should reflect the change", func() {
 				pluginGroupUpdated := pluginGroup1
 				pluginGroupUpdated.Hidden = false
-				err = inventory.UpdatePluginGroupActivationState(&pluginGroupUpdated)
+				err = inventory.UpdatePluginGroupActivationState(context.Background(), &pluginGroupUpdated)
 				Expect(err).To(BeNil())
 
 				// Verify the result using GetPluginGroups
-				groups, err := inventory.GetPluginGroups(PluginGroupFilter{})
+				groups, err := inventory.GetPluginGroups(context.Background(), PluginGroupFilter{})
 				Expect(err).ToNot(HaveOccurred())
 				Expect(len(groups)).To(Equal(1))
 				Expect(groups[0].Name).To(Equal(pluginGroupUpdated.Name))
@@ -1621,18 +2277,306 @@ var _ = Describe("Unit tests for plugin inventory", func() {
 
 		Context("When updating the activation state of a plugin-group which does not exist in the database", func() {
 			BeforeEach(func() {
-				err = inventory.InsertPluginGroup(&pluginGroup1, false)
+				err = inventory.InsertPluginGroup(context.Background(), &pluginGroup1, false)
 				Expect(err).To(BeNil())
 			})
 			It("should return error", func() {
 				pluginGroupUpdated := pluginGroup1
 				pluginGroupUpdated.Name = "unknown"
-				err = inventory.UpdatePluginGroupActivationState(&pluginGroupUpdated)
+				err = inventory.UpdatePluginGroupActivationState(context.Background(), &pluginGroupUpdated)
 				Expect(err).NotTo(BeNil())
 				Expect(err.Error()).To(ContainSubstring("unable to update plugin-group 'fakevendor-fakepublisher/unknown"))
 			})
 		})
 	})
+	Describe("Deleting plugins from inventory", func() {
+		BeforeEach(func() {
+			tmpDir, err = os.MkdirTemp(os.TempDir(), "")
+			Expect(err).To(BeNil(), "unable to create temporary directory")
+
+			// Create DB file
+			dbFile, err = os.Create(filepath.Join(tmpDir, SQliteDBFileName))
+			Expect(err).To(BeNil())
+
+			inventory = NewSQLiteInventory(dbFile.Name(), tmpDir)
+			err = inventory.CreateSchema(context.Background())
+			Expect(err).To(BeNil(), "failed to create DB schema for testing")
+
+			// Add a recommended version with release notes, a minimum CLI version and a license to plugin1
+			newVersion := piEntry1
+			newVersion.RecommendedVersion = "v0.29.0"
+			newVersion.Artifacts = distribution.Artifacts{"v0.29.0": piEntry1.Artifacts["v0.28.0"]}
+			newVersion.ReleaseNotes = map[string]PluginReleaseNotes{"v0.29.0": {Notes: "Broken release"}}
+			newVersion.MinCLIVersions = map[string]string{"v0.29.0": "v1.1.0"}
+			newVersion.Licenses = map[string]PluginLicense{"v0.29.0": {License: "Apache-2.0"}}
+			err = inventory.InsertPlugins(context.Background(), []*PluginInventoryEntry{&piEntry1, &newVersion, &piEntry2, &piEntry3})
+			Expect(err).To(BeNil(), "failed to insert the plugins")
+			err = inventory.InsertPluginGroup(context.Background(), &pluginGroup1, false)
+			Expect(err).To(BeNil(), "failed to insert the plugin-group")
+		})
+		AfterEach(func() {
+			os.RemoveAll(tmpDir)
+		})
+		Context("When deleting a version of a plugin", func() {
+			It("should delete the version with its details and find the recommended version again", func() {
+				err = inventory.DeletePluginVersion(context.Background(), "management-cluster", types.TargetK8s, "v0.29.0")
+				Expect(err).To(BeNil())
+
+				plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "management-cluster", Target: types.TargetK8s})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(1))
+				Expect(plugins[0].Artifacts).To(HaveLen(1))
+				Expect(plugins[0].Artifacts).To(HaveKey("v0.28.0"))
+				Expect(plugins[0].RecommendedVersion).To(Equal("v0.28.0"))
+				Expect(plugins[0].ReleaseNotes).To(BeEmpty())
+				Expect(plugins[0].MinCLIVersions).To(BeEmpty())
+				Expect(plugins[0].Licenses).To(BeEmpty())
+			})
+			It("should return an error if the version is part of a plugin-group", func() {
+				err = inventory.DeletePluginVersion(context.Background(), "management-cluster", types.TargetK8s, "v0.28.0")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("unable to delete plugin 'management-cluster@kubernetes:v0.28.0' because it is part of the plugin-groups [fakevendor-fakepublisher/default:v1.0.0 fakevendor-fakepublisher/default:v2.0.0]"))
+
+				plugins, err := inventory.GetPlugins(context.Background(), &PluginInventoryFilter{Name: "management-cluster", Target: types.TargetK8s})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(plugins[0].Artifacts).To(HaveLen(2))
+			})
+			It("should return an error if the version is not found", func() {
+				err = inventory.DeletePluginVersion(context.Background(), "management-cluster", types.TargetTMC, "v9.9.9")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("unable to delete plugin 'management-cluster@mission-control:v9.9.9' because it is not found in the inventory"))
+			})
+		})
+		Context("When deleting all the versions of a plugin", func() {
+			It("should delete the plugin if it is not part of a plugin-group", func() {
+				err = inventory.DeletePlugin(context.Background(), "management-cluster", types.TargetTMC)
+				Expect(err).To(BeNil())
+
+				plugins, err := inventory.GetAllPlugins(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(plugins)).To(Equal(2))
+				for _, p := range plugins {
+					Expect(p.Target).ToNot(Equal(types.TargetTMC))
+				}
+			})
+			It("should return an error if the plugin is part of a plugin-group", func() {
+				err = inventory.DeletePlugin(context.Background(), "isolated-cluster", types.TargetGlobal)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("unable to delete plugin 'isolated-cluster@global' because it is part of the plugin-groups [fakevendor-fakepublisher/default:v2.0.0]"))
+			})
+		})
+	})
+	Describe("Migrating the schema of the inventory", func() {
+		var db *sql.DB
+
+		BeforeEach(func() {
+			tmpDir, err = os.MkdirTemp(os.TempDir(), "")
+			Expect(err).To(BeNil(), "unable to create temporary directory")
+
+			dbFile, err = os.Create(filepath.Join(tmpDir, SQliteDBFileName))
+			Expect(err).To(BeNil())
+			db, err = sql.Open("sqlite", dbFile.Name())
+			Expect(err).To(BeNil(), "failed to open the DB for testing")
+
+			inventory = NewSQLiteInventory(dbFile.Name(), tmpDir)
+		})
+		AfterEach(func() {
+			db.Close()
+			os.RemoveAll(tmpDir)
+		})
+		// The versions of all the migrations, which are recorded when they are applied
+		migratedSchemaVersions := func() []int {
+			var versions []int
+			for _, migration := range schemaMigrations {
+				versions = append(versions, migration.version)
+			}
+			return versions
+		}
+		schemaVersions := func() []int {
+			rows, err := db.Query("SELECT Version FROM SchemaVersion ORDER BY Version;")
+			Expect(err).To(BeNil())
+			defer rows.Close()
+			var versions []int
+			for rows.Next() {
+				var version int
+				Expect(rows.Scan(&version)).To(Succeed())
+				versions = append(versions, version)
+			}
+			Expect(rows.Err()).To(BeNil())
+			return versions
+		}
+
+		Context("When creating the schema", func() {
+			It("should record the current schema version", func() {
+				err = inventory.CreateSchema(context.Background())
+				Expect(err).To(BeNil())
+				Expect(schemaVersions()).To(Equal(migratedSchemaVersions()))
+
+				// Migrating an up-to-date schema does nothing
+				err = inventory.MigrateSchema(context.Background())
+				Expect(err).To(BeNil())
+				Expect(schemaVersions()).To(Equal(migratedSchemaVersions()))
+			})
+		})
+		Context("With a DB created before the schema was versioned", func() {
+			BeforeEach(func() {
+				// Only the plugin table existed in the first inventories
+				_, err = db.Exec(`CREATE TABLE "PluginBinaries" (
+					"PluginName" TEXT NOT NULL, "Target" TEXT NOT NULL, "RecommendedVersion" TEXT NOT NULL,
+					"Version" TEXT NOT NULL, "Hidden" TEXT NOT NULL, "Description" TEXT NOT NULL,
+					"Publisher" TEXT NOT NULL, "Vendor" TEXT NOT NULL, "OS" TEXT NOT NULL,
+					"Architecture" TEXT NOT NULL, "Digest" TEXT NOT NULL, "URI" TEXT NOT NULL,
+					PRIMARY KEY("PluginName", "Target", "Version", "OS", "Architecture"));`)
+				Expect(err).To(BeNil(), "failed to create the legacy DB table")
+				_, err = db.Exec(`INSERT INTO PluginBinaries VALUES ('management-cluster','kubernetes','','v0.28.0','false','Plugin management-cluster description','tkg','vmware','darwin','amd64','0000000000','vmware/tkg/darwin/amd64/kubernetes/management-cluster:v0.28.0');`)
+				Expect(err).To(BeNil(), "failed to insert a plugin in the legacy DB")
+			})
+			It("should migrate the schema to the current version and keep the plugins", func() {
+				err = inventory.MigrateSchema(context.Background())
+				Expect(err).To(BeNil())
+				Expect(schemaVersions()).To(Equal(migratedSchemaVersions()))
+
+				plugins, err := inventory.GetAllPlugins(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(plugins).To(HaveLen(1))
+				Expect(plugins[0].Name).To(Equal("management-cluster"))
+				Expect(plugins[0].Artifacts).To(HaveKey("v0.28.0"))
+
+				groups, err := inventory.GetPluginGroups(context.Background(), PluginGroupFilter{IncludeHidden: true})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(groups).To(BeEmpty())
+			})
+		})
+		Context("With a DB whose schema is more recent than the CLI's", func() {
+			It("should leave the schema unchanged", func() {
+				err = inventory.CreateSchema(context.Background())
+				Expect(err).To(BeNil())
+				_, err = db.Exec("INSERT INTO SchemaVersion VALUES(?,?);", CurrentSchemaVersion+1, "A migration from a newer CLI")
+				Expect(err).To(BeNil())
+
+				err = inventory.MigrateSchema(context.Background())
+				Expect(err).To(BeNil())
+				Expect(schemaVersions()).To(Equal(append(migratedSchemaVersions(), CurrentSchemaVersion+1)))
+			})
+		})
+		Context("With a cancelled context", func() {
+			It("should not migrate the schema", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				err = inventory.MigrateSchema(ctx)
+				Expect(err).To(MatchError(context.Canceled))
+			})
+		})
+	})
+	Describe("Accessing the inventory concurrently", func() {
+		var savedCacheDir string
+
+		BeforeEach(func() {
+			tmpDir, err = os.MkdirTemp(os.TempDir(), "")
+			Expect(err).To(BeNil(), "unable to create temporary directory")
+
+			// The inventory is in the local cache
+			savedCacheDir = common.DefaultCacheDir
+			common.DefaultCacheDir = tmpDir
+			cachedInventoryDir := filepath.Join(tmpDir, common.PluginInventoryDirName, "default")
+			Expect(os.MkdirAll(cachedInventoryDir, 0o755)).To(Succeed())
+			dbFile, err = os.Create(filepath.Join(cachedInventoryDir, SQliteDBFileName))
+			Expect(err).To(BeNil())
+
+			inventory = NewSQLiteInventory(dbFile.Name(), tmpDir)
+			err = inventory.CreateSchema(context.Background())
+			Expect(err).To(BeNil(), "failed to create DB schema for testing")
+			err = inventory.InsertPlugins(context.Background(), []*PluginInventoryEntry{&piEntry1, &piEntry2})
+			Expect(err).To(BeNil(), "failed to insert the plugins")
+		})
+		AfterEach(func() {
+			common.DefaultCacheDir = savedCacheDir
+			os.RemoveAll(tmpDir)
+		})
+		It("should read the plugins while another inventory inserts a plugin", func() {
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					// Each reader uses its own inventory, as separate processes would
+					plugins, err := NewSQLiteInventory(dbFile.Name(), tmpDir).GetAllPlugins(context.Background())
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(plugins)).To(BeNumerically(">=", 2))
+				}()
+			}
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				err := NewSQLiteInventory(dbFile.Name(), tmpDir).InsertPlugin(context.Background(), &piEntry3)
+				Expect(err).ToNot(HaveOccurred())
+			}()
+			wg.Wait()
+
+			plugins, err := inventory.GetAllPlugins(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(plugins)).To(Equal(3))
+		})
+		It("should use the WAL journal mode and checkpoint it into the DB file once done", func() {
+			// The inserted plugins are in the DB file itself, which can be copied on its own
+			Expect(dbFile.Name() + "-wal").ToNot(BeAnExistingFile())
+			Expect(journalMode(dbFile.Name())).To(Equal("wal"))
+		})
+		It("should not use the WAL journal mode for an inventory which is not in the local cache", func() {
+			inventoryFile := filepath.Join(tmpDir, SQliteDBFileName)
+			Expect(NewSQLiteInventory(inventoryFile, tmpDir).CreateSchema(context.Background())).To(Succeed())
+			Expect(NewSQLiteInventory(inventoryFile, tmpDir).InsertPlugin(context.Background(), &piEntry3)).To(Succeed())
+
+			Expect(journalMode(inventoryFile)).To(Equal("delete"))
+		})
+	})
+	Describe("Checking the integrity of the inventory", func() {
+		BeforeEach(func() {
+			tmpDir, err = os.MkdirTemp(os.TempDir(), "")
+			Expect(err).To(BeNil(), "unable to create temporary directory")
+
+			err = CreateInventoryDB(filepath.Join(tmpDir, SQliteDBFileName), &InventorySeed{
+				Plugins: []*PluginInventoryEntry{&piEntry1, &piEntry2, &piEntry3},
+				Groups:  []*PluginGroup{&pluginGroup1},
+			})
+			Expect(err).To(BeNil(), "failed to create the DB for testing")
+		})
+		AfterEach(func() {
+			os.RemoveAll(tmpDir)
+		})
+		It("should accept a valid inventory", func() {
+			err = CheckSQLiteInventoryIntegrity(context.Background(), filepath.Join(tmpDir, SQliteDBFileName))
+			Expect(err).To(BeNil())
+		})
+		It("should reject a truncated inventory", func() {
+			inventoryFile := filepath.Join(tmpDir, SQliteDBFileName)
+			stat, err := os.Stat(inventoryFile)
+			Expect(err).To(BeNil())
+			Expect(os.Truncate(inventoryFile, stat.Size()/2)).To(Succeed())
+
+			err = CheckSQLiteInventoryIntegrity(context.Background(), inventoryFile)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("is corrupted"))
+		})
+		It("should reject a file which is not a database", func() {
+			inventoryFile := filepath.Join(tmpDir, "garbage.db")
+			Expect(os.WriteFile(inventoryFile, []byte("this is not an SQLite database, but it is long enough to have a header"), 0o600)).To(Succeed())
+
+			err = CheckSQLiteInventoryIntegrity(context.Background(), inventoryFile)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("is corrupted"))
+		})
+		It("should reject an empty file", func() {
+			inventoryFile := filepath.Join(tmpDir, "empty.db")
+			Expect(os.WriteFile(inventoryFile, nil, 0o600)).To(Succeed())
+
+			err = CheckSQLiteInventoryIntegrity(context.Background(), inventoryFile)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("is not a plugin inventory"))
+		})
+	})
 })
 
 type pluginGroupSorter []*PluginGroup
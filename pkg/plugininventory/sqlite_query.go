@@ -0,0 +1,86 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugininventory
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// sqlQuery builds the WHERE clause of a parameterized SQL query. The values of the
+// conditions are bound to '?' placeholders instead of being formatted into the SQL text,
+// so that they never need to be escaped and the queries of different values share the
+// same text, and therefore the same prepared statement.
+type sqlQuery struct {
+	conditions []string
+	args       []interface{}
+}
+
+// where adds a condition the rows must match, with a '?' placeholder for each of the args
+func (q *sqlQuery) where(condition string, args ...interface{}) {
+	q.conditions = append(q.conditions, condition)
+	q.args = append(q.args, args...)
+}
+
+// whereIn adds the condition that the column has one of the values
+func (q *sqlQuery) whereIn(column string, values []string) {
+	args := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		args = append(args, value)
+	}
+	q.where(column+" IN (?"+strings.Repeat(",?", len(values)-1)+")", args...)
+}
+
+// whereClause returns the WHERE section of the query, which is empty without condition
+func (q *sqlQuery) whereClause() string {
+	if len(q.conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(q.conditions, " AND ")
+}
+
+// query returns the SQL text of the query made of the SELECT section, the WHERE section
+// and the ORDER section
+func (q *sqlQuery) query(selectClause, orderClause string) string {
+	return strings.Join([]string{selectClause, q.whereClause(), orderClause}, " ")
+}
+
+// queryInventoryDB runs the query on a DB returned by openInventoryDB(). The query is prepared
+// once per DB, and the prepared statement reused by the next queries with the same text, e.g.
+// the queries of the details of the versions of each batch of plugins walked.
+func queryInventoryDB(ctx context.Context, db *sql.DB, query string, args ...interface{}) (*sql.Rows, error) {
+	stmt, err := prepareInventoryStatement(ctx, db, query)
+	if err != nil {
+		return nil, err
+	}
+	if stmt == nil {
+		return db.QueryContext(ctx, query, args...)
+	}
+	return stmt.QueryContext(ctx, args...)
+}
+
+// prepareInventoryStatement returns the prepared statement of the query for the DB, preparing it
+// if it is not cached yet. It returns nil if the DB was not opened by openInventoryDB().
+func prepareInventoryStatement(ctx context.Context, db *sql.DB, query string) (*sql.Stmt, error) {
+	pooledDBsLock.Lock()
+	defer pooledDBsLock.Unlock()
+
+	p := findPooledDB(db)
+	if p == nil {
+		return nil, nil
+	}
+	if stmt, exists := p.statements[query]; exists {
+		return stmt, nil
+	}
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if p.statements == nil {
+		p.statements = make(map[string]*sql.Stmt)
+	}
+	p.statements[query] = stmt
+	return stmt, nil
+}
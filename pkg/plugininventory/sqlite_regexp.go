@@ -0,0 +1,45 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugininventory
+
+import (
+	"database/sql/driver"
+	"regexp"
+	"sync"
+
+	"github.com/pkg/errors"
+	"modernc.org/sqlite"
+)
+
+// compiledRegexps caches the regular expressions used in the queries,
+// which are evaluated for every row of the table
+var compiledRegexps sync.Map
+
+func init() {
+	// SQLite provides the REGEXP operator but leaves its implementation to the application
+	sqlite.MustRegisterDeterministicScalarFunction("regexp", 2, sqliteRegexp)
+}
+
+// sqliteRegexp implements 'value REGEXP pattern', which SQLite calls as regexp(pattern, value)
+func sqliteRegexp(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	pattern, ok := args[0].(string)
+	if !ok {
+		return nil, errors.New("the pattern of the REGEXP operator must be a string")
+	}
+	value, ok := args[1].(string)
+	if !ok {
+		// NULL or non-text values never match
+		return false, nil
+	}
+
+	re, found := compiledRegexps.Load(pattern)
+	if !found {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		re, _ = compiledRegexps.LoadOrStore(pattern, compiled)
+	}
+	return re.(*regexp.Regexp).MatchString(value), nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+)
+
+var (
+	artifactVerifiersLock sync.RWMutex
+	// artifactVerifiers are invoked, in order, on each downloaded plugin binary before it is installed
+	artifactVerifiers []distribution.ArtifactVerifier
+)
+
+// RegisterArtifactVerifier adds a verifier invoked on every plugin binary downloaded by the
+// plugin installer, after its digest was verified and before it is installed. The programs
+// embedding the CLI use it to verify the signatures of the artifacts, e.g. with cosign.
+func RegisterArtifactVerifier(verifier distribution.ArtifactVerifier) {
+	artifactVerifiersLock.Lock()
+	defer artifactVerifiersLock.Unlock()
+	artifactVerifiers = append(artifactVerifiers, verifier)
+}
+
+// verifyPluginArtifact invokes the registered verifiers on the binary downloaded for the plugin version
+func verifyPluginArtifact(p *discovery.Discovered, version string, binary []byte) error {
+	artifactVerifiersLock.RLock()
+	verifiers := artifactVerifiers
+	artifactVerifiersLock.RUnlock()
+	if len(verifiers) == 0 {
+		return nil
+	}
+
+	a, err := p.Distribution.DescribeArtifact(version, cli.GOOS, cli.GOARCH)
+	if err != nil {
+		return err
+	}
+	for _, verifier := range verifiers {
+		if err := verifier.Verify(&a, binary); err != nil {
+			return errors.Wrapf(err, "verification of the artifact of plugin %q version %q failed", p.Name, version)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
+)
+
+func TestVerifyPluginArtifact(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer func() { artifactVerifiers = nil }()
+
+	p := &discovery.Discovered{
+		Name: "myplugin",
+		Distribution: distribution.Artifacts{
+			"v1.0.0": []distribution.Artifact{{Image: "localhost:9876/myplugin:v1.0.0", OS: cli.GOOS, Arch: cli.GOARCH, Signature: "localhost:9876/myplugin:v1.0.0.sig"}},
+		},
+	}
+
+	// Without verifier, the binaries are accepted
+	assertions.Nil(verifyPluginArtifact(p, "v1.0.0", []byte("binary")))
+
+	var verified []string
+	RegisterArtifactVerifier(distribution.ArtifactVerifierFunc(func(a *distribution.Artifact, binary []byte) error {
+		verified = append(verified, a.Signature+"="+string(binary))
+		return nil
+	}))
+	assertions.Nil(verifyPluginArtifact(p, "v1.0.0", []byte("binary")))
+	assertions.Equal([]string{"localhost:9876/myplugin:v1.0.0.sig=binary"}, verified)
+
+	RegisterArtifactVerifier(distribution.ArtifactVerifierFunc(func(_ *distribution.Artifact, _ []byte) error {
+		return errors.New("invalid signature")
+	}))
+	err := verifyPluginArtifact(p, "v1.0.0", []byte("binary"))
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), `verification of the artifact of plugin "myplugin" version "v1.0.0" failed: invalid signature`)
+}
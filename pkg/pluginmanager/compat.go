@@ -0,0 +1,203 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"sort"
+
+	"github.com/Masterminds/semver"
+
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/buildinfo"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginsupplier"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+)
+
+const (
+	// PluginCompatibilityStatusCompatible is the status of the plugins which are compatible with the CLI
+	PluginCompatibilityStatusCompatible = "compatible"
+	// PluginCompatibilityStatusIncompatible is the status of the plugins which are not compatible with the CLI
+	PluginCompatibilityStatusIncompatible = "incompatible"
+	// PluginCompatibilityStatusUnknown is the status of the plugins whose compatibility cannot be determined
+	PluginCompatibilityStatusUnknown = "unknown"
+
+	// MinSupportedPluginRuntimeVersion is the oldest version of the tanzu-plugin-runtime library
+	// the plugins supported by the CLI can be built with
+	MinSupportedPluginRuntimeVersion = "v0.11.0"
+
+	pluginRuntimeModulePath = "github.com/vmware-tanzu/tanzu-plugin-runtime"
+)
+
+// cliPluginRuntimeVersion returns the version of the tanzu-plugin-runtime library the CLI
+// is built with, which is the most recent version supported by the CLI
+var cliPluginRuntimeVersion = func() string {
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, dep := range buildInfo.Deps {
+		if dep.Path == pluginRuntimeModulePath {
+			return dep.Version
+		}
+	}
+	return ""
+}
+
+// PluginCompatibility describes the compatibility of an installed plugin with the CLI
+type PluginCompatibility struct {
+	Name                 string             `json:"name" yaml:"name"`
+	Target               configtypes.Target `json:"target" yaml:"target"`
+	Version              string             `json:"version" yaml:"version"`
+	PluginRuntimeVersion string             `json:"pluginRuntimeVersion" yaml:"pluginRuntimeVersion"`
+	MinCLIVersion        string             `json:"minCLIVersion" yaml:"minCLIVersion"`
+	Status               string             `json:"status" yaml:"status"`
+	Reason               string             `json:"reason" yaml:"reason"`
+	Action               string             `json:"action" yaml:"action"`
+}
+
+// SupportedPluginRuntimeVersions returns the range of the versions of the tanzu-plugin-runtime library
+// supported by the CLI. The maximum version is empty if it cannot be determined.
+func SupportedPluginRuntimeVersions() (minVersion, maxVersion string) {
+	return MinSupportedPluginRuntimeVersion, cliPluginRuntimeVersion()
+}
+
+// CheckInstalledPluginsCompatibility checks the compatibility of the installed plugins with the CLI.
+// The version of the tanzu-plugin-runtime library of each plugin is compared with the versions supported
+// by the CLI, and the minimum CLI version required by the installed version of the plugin, if declared in
+// the plugin inventory, is compared with the version of the CLI.
+func CheckInstalledPluginsCompatibility() ([]PluginCompatibility, error) {
+	installedPlugins, err := pluginsupplier.GetInstalledPlugins()
+	if err != nil {
+		return nil, err
+	}
+
+	// The compatibility of the plugins can still be checked using their runtime version
+	// if some discoveries cannot be reached
+	availablePlugins, err := DiscoverStandalonePlugins()
+	if err != nil {
+		log.Warningf("unable to read the minimum CLI versions from the plugin inventories: %v", err)
+	}
+
+	results := make([]PluginCompatibility, 0, len(installedPlugins))
+	for i := range installedPlugins {
+		var available *discovery.Discovered
+		for j := range availablePlugins {
+			if availablePlugins[j].Name == installedPlugins[i].Name && availablePlugins[j].Target == installedPlugins[i].Target {
+				available = &availablePlugins[j]
+				break
+			}
+		}
+		results = append(results, checkPluginCompatibility(&installedPlugins[i], available))
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Name != results[j].Name {
+			return results[i].Name < results[j].Name
+		}
+		return results[i].Target < results[j].Target
+	})
+	return results, nil
+}
+
+// checkPluginCompatibility checks the compatibility of an installed plugin with the CLI,
+// using the versions of the plugin found in the plugin inventories, if any
+func checkPluginCompatibility(plugin *cli.PluginInfo, available *discovery.Discovered) PluginCompatibility {
+	result := PluginCompatibility{
+		Name:                 plugin.Name,
+		Target:               plugin.Target,
+		Version:              plugin.Version,
+		PluginRuntimeVersion: getInstalledPluginRuntimeVersion(plugin),
+		Status:               PluginCompatibilityStatusCompatible,
+	}
+	if available != nil {
+		result.MinCLIVersion = available.MinCLIVersions[plugin.Version]
+	}
+
+	if result.MinCLIVersion != "" && utils.IsNewVersion(result.MinCLIVersion, buildinfo.Version) {
+		result.Status = PluginCompatibilityStatusIncompatible
+		result.Reason = fmt.Sprintf("the plugin requires the CLI version %s or later", result.MinCLIVersion)
+		if version := highestCompatiblePluginVersion(available); version != "" {
+			result.Action = fmt.Sprintf("tanzu plugin install %s --target %s --version %s", plugin.Name, plugin.Target, version)
+		} else {
+			result.Action = fmt.Sprintf("upgrade the Tanzu CLI to %s or later", result.MinCLIVersion)
+		}
+		return result
+	}
+
+	if result.PluginRuntimeVersion == "" {
+		result.Status = PluginCompatibilityStatusUnknown
+		result.Reason = "the plugin does not report the version of the plugin runtime it is built with"
+		return result
+	}
+	minVersion, maxVersion := SupportedPluginRuntimeVersions()
+	switch {
+	case utils.IsNewVersion(minVersion, result.PluginRuntimeVersion):
+		result.Status = PluginCompatibilityStatusIncompatible
+		result.Reason = fmt.Sprintf("the plugin runtime version is older than the oldest supported version %s", minVersion)
+		if available != nil && utils.IsNewVersion(available.RecommendedVersion, plugin.Version) {
+			result.Action = fmt.Sprintf("tanzu plugin upgrade %s --target %s", plugin.Name, plugin.Target)
+		} else {
+			result.Action = "contact the publisher of the plugin for a compatible version"
+		}
+	case isNewerMinorVersion(result.PluginRuntimeVersion, maxVersion):
+		result.Status = PluginCompatibilityStatusIncompatible
+		result.Reason = fmt.Sprintf("the plugin runtime version is newer than the most recent supported version %s", maxVersion)
+		result.Action = "upgrade the Tanzu CLI to use all the features of the plugin"
+	}
+	return result
+}
+
+// getInstalledPluginRuntimeVersion returns the version of the plugin runtime of the installed plugin.
+// The plugins installed before the version was recorded in the catalog are asked for it.
+func getInstalledPluginRuntimeVersion(plugin *cli.PluginInfo) string {
+	if plugin.PluginRuntimeVersion != "" {
+		return plugin.PluginRuntimeVersion
+	}
+	executable, args, err := cli.PluginCommandLine(plugin.InstallationPath, "info")
+	if err != nil {
+		return ""
+	}
+	bytesInfo, err := execCommand(executable, args...).Output()
+	if err != nil {
+		log.V(7).Infof("could not describe plugin %q: %v", plugin.Name, err)
+		return ""
+	}
+	var info cli.PluginInfo
+	if err := json.Unmarshal(bytesInfo, &info); err != nil {
+		return ""
+	}
+	return info.PluginRuntimeVersion
+}
+
+// highestCompatiblePluginVersion returns the highest version of the plugin whose minimum CLI version,
+// if any, is satisfied by the CLI, or an empty string if there is none
+func highestCompatiblePluginVersion(available *discovery.Discovered) string {
+	for i := len(available.SupportedVersions) - 1; i >= 0; i-- {
+		version := available.SupportedVersions[i]
+		if minCLIVersion := available.MinCLIVersions[version]; minCLIVersion == "" || !utils.IsNewVersion(minCLIVersion, buildinfo.Version) {
+			return version
+		}
+	}
+	return ""
+}
+
+// isNewerMinorVersion returns true if the first version has a higher major or minor version than the second one.
+// The patches of a minor version are compatible with each other.
+func isNewerMinorVersion(v1str, v2str string) bool {
+	v1, err := semver.NewVersion(v1str)
+	if err != nil {
+		return false
+	}
+	v2, err := semver.NewVersion(v2str)
+	if err != nil {
+		return false
+	}
+	return v1.Major() > v2.Major() || (v1.Major() == v2.Major() && v1.Minor() > v2.Minor())
+}
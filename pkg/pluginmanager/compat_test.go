@@ -0,0 +1,125 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"database/sql"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/buildinfo"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/catalog"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/config"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+)
+
+func TestCheckInstalledPluginsCompatibility(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	previousVersion := buildinfo.Version
+	buildinfo.Version = "v1.1.0"
+	defer func() { buildinfo.Version = previousVersion }()
+	previousRuntimeVersion := cliPluginRuntimeVersion
+	cliPluginRuntimeVersion = func() string { return "v1.1.2" }
+	defer func() { cliPluginRuntimeVersion = previousRuntimeVersion }()
+
+	// Declare minimum CLI versions for some versions of the login plugin
+	db, err := sql.Open("sqlite", filepath.Join(common.DefaultCacheDir, common.PluginInventoryDirName, config.DefaultStandaloneDiscoveryName, plugininventory.SQliteDBFileName))
+	assertions.Nil(err)
+	_, err = db.Exec(`INSERT INTO PluginCompatibility VALUES('login','global','v0.20.0','v1.2.0'),('login','global','v0.2.0','v1.0.0');`)
+	assertions.Nil(err)
+	db.Close()
+
+	cc, err := catalog.NewContextCatalogUpdater("")
+	assertions.Nil(err)
+	for _, plugin := range []cli.PluginInfo{
+		{Name: "login", Target: configtypes.TargetGlobal, Version: "v0.20.0", PluginRuntimeVersion: "v1.1.0"},
+		{Name: "isolated-cluster", Target: configtypes.TargetGlobal, Version: "v1.2.3", PluginRuntimeVersion: "v0.9.0"},
+		{Name: "myplugin", Target: configtypes.TargetTMC, Version: "v0.2.0", PluginRuntimeVersion: "v1.2.0-alpha.1"},
+		{Name: "myplugin", Target: configtypes.TargetK8s, Version: "v1.6.0", PluginRuntimeVersion: "v1.1.0"},
+		{Name: "cluster", Target: configtypes.TargetK8s, Version: "v1.6.0", InstallationPath: "/path/not/found"},
+	} {
+		plugin := plugin
+		if plugin.InstallationPath == "" {
+			plugin.InstallationPath = filepath.Join("/path", string(plugin.Target), plugin.Name)
+		}
+		assertions.Nil(cc.Upsert(&plugin))
+	}
+	cc.Unlock()
+
+	results, err := CheckInstalledPluginsCompatibility()
+	assertions.Nil(err)
+	assertions.Equal([]PluginCompatibility{
+		{
+			Name:    "cluster",
+			Target:  configtypes.TargetK8s,
+			Version: "v1.6.0",
+			Status:  PluginCompatibilityStatusUnknown,
+			Reason:  "the plugin does not report the version of the plugin runtime it is built with",
+		},
+		{
+			Name:                 "isolated-cluster",
+			Target:               configtypes.TargetGlobal,
+			Version:              "v1.2.3",
+			PluginRuntimeVersion: "v0.9.0",
+			Status:               PluginCompatibilityStatusIncompatible,
+			Reason:               "the plugin runtime version is older than the oldest supported version v0.11.0",
+			Action:               "tanzu plugin upgrade isolated-cluster --target global",
+		},
+		{
+			Name:                 "login",
+			Target:               configtypes.TargetGlobal,
+			Version:              "v0.20.0",
+			PluginRuntimeVersion: "v1.1.0",
+			MinCLIVersion:        "v1.2.0",
+			Status:               PluginCompatibilityStatusIncompatible,
+			Reason:               "the plugin requires the CLI version v1.2.0 or later",
+			Action:               "tanzu plugin install login --target global --version v0.2.0",
+		},
+		{
+			Name:                 "myplugin",
+			Target:               configtypes.TargetK8s,
+			Version:              "v1.6.0",
+			PluginRuntimeVersion: "v1.1.0",
+			Status:               PluginCompatibilityStatusCompatible,
+		},
+		{
+			Name:                 "myplugin",
+			Target:               configtypes.TargetTMC,
+			Version:              "v0.2.0",
+			PluginRuntimeVersion: "v1.2.0-alpha.1",
+			Status:               PluginCompatibilityStatusIncompatible,
+			Reason:               "the plugin runtime version is newer than the most recent supported version v1.1.2",
+			Action:               "upgrade the Tanzu CLI to use all the features of the plugin",
+		},
+	}, results)
+
+	// All the versions of the plugin requiring a more recent CLI are skipped
+	buildinfo.Version = "v0.9.0"
+	results, err = CheckInstalledPluginsCompatibility()
+	assertions.Nil(err)
+	assertions.Equal("login", results[2].Name)
+	assertions.Equal("tanzu plugin install login --target global --version v0.2.0-beta.1", results[2].Action)
+}
+
+func TestIsNewerMinorVersion(t *testing.T) {
+	assertions := assert.New(t)
+
+	assertions.True(isNewerMinorVersion("v1.2.0", "v1.1.5"))
+	assertions.True(isNewerMinorVersion("v2.0.0", "v1.9.0"))
+	assertions.False(isNewerMinorVersion("v1.1.9", "v1.1.0"))
+	assertions.False(isNewerMinorVersion("v1.1.0-alpha.2", "v1.1.0"))
+	assertions.False(isNewerMinorVersion("v1.2.0", ""))
+}
@@ -12,11 +12,14 @@ import (
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/Masterminds/semver"
 	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
 	"gopkg.in/yaml.v2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
@@ -31,6 +34,7 @@ import (
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/config"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/distribution"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/plugincmdtree"
@@ -56,6 +60,10 @@ const (
 	errorNoDiscoverySourcesFound = "there are no plugin discovery sources available. Please run 'tanzu plugin source init'"
 
 	errorNoActiveContexForGivenContextType = "there is no active context for the given context type `%v`"
+
+	// recommendedVersionResolutionConcurrency is the maximum number of recommended versions
+	// of the server plugins resolved concurrently from the plugin inventories
+	recommendedVersionResolutionConcurrency = 8
 )
 
 var execCommand = exec.Command
@@ -185,6 +193,8 @@ func DiscoverServerPluginsForGivenContexts(contexts []*configtypes.Context) ([]d
 	if len(contexts) == 0 {
 		return plugins, nil
 	}
+	// The standalone discoveries are read once to resolve the recommended versions of all the plugins
+	standaloneDiscoveries, _ := getPluginDiscoveries()
 	for _, context := range contexts {
 		var discoverySources []configtypes.PluginDiscovery
 		discoverySources = append(discoverySources, context.DiscoverySources...)
@@ -210,14 +220,8 @@ func DiscoverServerPluginsForGivenContexts(contexts []*configtypes.Context) ([]d
 				// All other context types are associated with the kubernetes target
 				discoveredPlugins[i].Target = configtypes.TargetK8s
 			}
-
-			// It is possible that server recommends shortened plugin version of format vMAJOR or vMAJOR.MINOR
-			// in that case, try to find the latest available version of the plugin that matches with the given recommended version
-			matchedRecommendedVersion := getMatchingRecommendedVersionOfPlugin(discoveredPlugins[i].Name, discoveredPlugins[i].Target, discoveredPlugins[i].RecommendedVersion)
-			if matchedRecommendedVersion != "" {
-				discoveredPlugins[i].RecommendedVersion = matchedRecommendedVersion
-			}
 		}
+		resolveRecommendedVersions(discoveredPlugins, standaloneDiscoveries)
 		// Remove older plugins from the discoveredPlugins list when there are duplicates
 		// this can be possible if a same plugin gets discovered from different kubernetes namespaces
 		discoveredPlugins = removeOldPluginsWhenDuplicates(discoveredPlugins)
@@ -226,7 +230,30 @@ func DiscoverServerPluginsForGivenContexts(contexts []*configtypes.Context) ([]d
 	return plugins, kerrors.NewAggregate(errList)
 }
 
-func getMatchingRecommendedVersionOfPlugin(pluginName string, pluginTarget configtypes.Target, version string) string {
+// resolveRecommendedVersions replaces the recommended versions of the server plugins with the
+// matching versions found in the standalone discoveries. The versions are resolved concurrently,
+// so that listing the plugins of a context does not take longer with the number of its plugins.
+func resolveRecommendedVersions(plugins []discovery.Discovered, standaloneDiscoveries []configtypes.PluginDiscovery) {
+	if len(standaloneDiscoveries) == 0 {
+		return
+	}
+	var eg errgroup.Group
+	eg.SetLimit(recommendedVersionResolutionConcurrency)
+	for i := range plugins {
+		p := &plugins[i]
+		eg.Go(func() error {
+			// It is possible that server recommends shortened plugin version of format vMAJOR or vMAJOR.MINOR
+			// in that case, try to find the latest available version of the plugin that matches with the given recommended version
+			if matchedRecommendedVersion := getMatchingRecommendedVersionOfPlugin(standaloneDiscoveries, p.Name, p.Target, p.RecommendedVersion); matchedRecommendedVersion != "" {
+				p.RecommendedVersion = matchedRecommendedVersion
+			}
+			return nil
+		})
+	}
+	_ = eg.Wait()
+}
+
+func getMatchingRecommendedVersionOfPlugin(standaloneDiscoveries []configtypes.PluginDiscovery, pluginName string, pluginTarget configtypes.Target, version string) string {
 	criteria := &discovery.PluginDiscoveryCriteria{
 		Name:    pluginName,
 		Target:  pluginTarget,
@@ -236,10 +263,11 @@ func getMatchingRecommendedVersionOfPlugin(pluginName string, pluginTarget confi
 	// here as we do not want to fetch the database (or even validate the digest) all the time.
 	// Using local cache should be fine as cache gets updated with any plugin installation or search commands
 	// Also we are planning to implement scheduling of auto sync of database cache in future releases
-	matchedPlugins, err := DiscoverStandalonePlugins(discovery.WithPluginDiscoveryCriteria(criteria), discovery.WithUseLocalCacheOnly())
+	matchedPlugins, err := discoverSpecificPlugins(standaloneDiscoveries, discovery.WithPluginDiscoveryCriteria(criteria), discovery.WithUseLocalCacheOnly())
 	if err != nil {
 		return ""
 	}
+	matchedPlugins = mergeDuplicatePlugins(matchedPlugins)
 	if len(matchedPlugins) != 1 {
 		return ""
 	}
@@ -294,6 +322,30 @@ func mergePluginEntries(plugin1, plugin2 *discovery.Discovered) *discovery.Disco
 		if !exists {
 			artifacts1[version] = artifacts2[version]
 			plugin1.SupportedVersions = append(plugin1.SupportedVersions, version)
+			if releaseNotes, found := plugin2.ReleaseNotes[version]; found {
+				if plugin1.ReleaseNotes == nil {
+					plugin1.ReleaseNotes = make(map[string]plugininventory.PluginReleaseNotes)
+				}
+				plugin1.ReleaseNotes[version] = releaseNotes
+			}
+			if minCLIVersion, found := plugin2.MinCLIVersions[version]; found {
+				if plugin1.MinCLIVersions == nil {
+					plugin1.MinCLIVersions = make(map[string]string)
+				}
+				plugin1.MinCLIVersions[version] = minCLIVersion
+			}
+			if license, found := plugin2.Licenses[version]; found {
+				if plugin1.Licenses == nil {
+					plugin1.Licenses = make(map[string]plugininventory.PluginLicense)
+				}
+				plugin1.Licenses[version] = license
+			}
+			if releasedAt, found := plugin2.ReleasedAt[version]; found {
+				if plugin1.ReleasedAt == nil {
+					plugin1.ReleasedAt = make(map[string]time.Time)
+				}
+				plugin1.ReleasedAt[version] = releasedAt
+			}
 		}
 	}
 	plugin1.Distribution = artifacts1
@@ -308,6 +360,7 @@ func mergePluginEntries(plugin1, plugin2 *discovery.Discovered) *discovery.Disco
 	// - Optional
 	// - ContextName
 	// - Scope
+	// - Deprecated, DeprecationMessage and ReplacedBy
 	return plugin1
 }
 
@@ -409,7 +462,8 @@ func setAvailablePluginsStatus(availablePlugins []discovery.Discovered, installe
 		for j := range availablePlugins {
 			if installedPlugins[i].Name == availablePlugins[j].Name && installedPlugins[i].Target == availablePlugins[j].Target {
 				// Match found, Check for update available and update status
-				if installedPlugins[i].DiscoveredRecommendedVersion == availablePlugins[j].RecommendedVersion {
+				if installedPlugins[i].DiscoveredRecommendedVersion == availablePlugins[j].RecommendedVersion ||
+					!IsPluginUpdateAvailable(&availablePlugins[j], installedPlugins[i].Version) {
 					availablePlugins[j].Status = common.PluginStatusInstalled
 				} else {
 					availablePlugins[j].Status = common.PluginStatusUpdateAvailable
@@ -420,6 +474,22 @@ func setAvailablePluginsStatus(availablePlugins []discovery.Discovered, installe
 	}
 }
 
+// IsPluginUpdateAvailable tells whether the recommended version of the discovered plugin is an update
+// of the installed version. When the inventory records the release time of both versions, the
+// recommended version must have been published after the installed one, so that the users who
+// installed a more recent build, e.g. a hotfix, are not asked to update; otherwise any other
+// recommended version is an update.
+func IsPluginUpdateAvailable(p *discovery.Discovered, installedVersion string) bool {
+	if p.RecommendedVersion == installedVersion {
+		return false
+	}
+	recommendedReleasedAt, installedReleasedAt := p.ReleasedAt[p.RecommendedVersion], p.ReleasedAt[installedVersion]
+	if recommendedReleasedAt.IsZero() || installedReleasedAt.IsZero() {
+		return true
+	}
+	return recommendedReleasedAt.After(installedReleasedAt)
+}
+
 // DescribePlugin describes a plugin.
 func DescribePlugin(pluginName string, target configtypes.Target) (info *cli.PluginInfo, err error) {
 	plugins, err := pluginsupplier.GetInstalledPlugins()
@@ -454,13 +524,68 @@ func DescribePlugin(pluginName string, target configtypes.Target) (info *cli.Plu
 	return nil, errors.Errorf(missingTargetStr, pluginName)
 }
 
+// GetPluginReleaseNotes returns the release notes of a version of a plugin found in the plugin
+// inventories already cached locally, or nil if the publisher did not provide release notes.
+func GetPluginReleaseNotes(pluginName string, target configtypes.Target, version string) *plugininventory.PluginReleaseNotes {
+	discoveries, err := getPluginDiscoveries()
+	if err != nil || len(discoveries) == 0 {
+		return nil
+	}
+	criteria := &discovery.PluginDiscoveryCriteria{
+		Name:    pluginName,
+		Target:  target,
+		Version: version,
+	}
+	// The release notes are informational so errors are ignored and the inventories are not refreshed
+	plugins, _ := discoverSpecificPlugins(discoveries, discovery.WithPluginDiscoveryCriteria(criteria), discovery.WithUseLocalCacheOnly())
+	for i := range plugins {
+		if plugins[i].Name != pluginName || plugins[i].Target != target {
+			continue
+		}
+		if releaseNotes, found := plugins[i].ReleaseNotes[version]; found {
+			return &releaseNotes
+		}
+	}
+	return nil
+}
+
+// GetPluginLicense returns the license of a version of a plugin found in the plugin
+// inventories already cached locally, or nil if the publisher did not provide a license.
+func GetPluginLicense(pluginName string, target configtypes.Target, version string) *plugininventory.PluginLicense {
+	discoveries, err := getPluginDiscoveries()
+	if err != nil || len(discoveries) == 0 {
+		return nil
+	}
+	criteria := &discovery.PluginDiscoveryCriteria{
+		Name:    pluginName,
+		Target:  target,
+		Version: version,
+	}
+	// The license is informational so errors are ignored and the inventories are not refreshed
+	plugins, _ := discoverSpecificPlugins(discoveries, discovery.WithPluginDiscoveryCriteria(criteria), discovery.WithUseLocalCacheOnly())
+	for i := range plugins {
+		if plugins[i].Name != pluginName || plugins[i].Target != target {
+			continue
+		}
+		if license, found := plugins[i].Licenses[version]; found {
+			return &license
+		}
+	}
+	return nil
+}
+
 // InitializePlugin initializes the plugin configuration
 func InitializePlugin(plugin *cli.PluginInfo) error {
 	if plugin == nil {
 		return fmt.Errorf("could not get plugin information")
 	}
 
-	b, err := execCommand(plugin.InstallationPath, "post-install").CombinedOutput()
+	executable, args, err := cli.PluginCommandLine(plugin.InstallationPath, "post-install")
+	if err != nil {
+		log.Warningf("Warning: Failed to initialize plugin '%q' after installation. %v", plugin.Name, err)
+		return nil
+	}
+	b, err := execCommand(executable, args...).CombinedOutput()
 
 	// Note: If user is installing old version of plugin than it is possible that
 	// the plugin does not implement post-install command. Ignoring the
@@ -499,11 +624,12 @@ func installPlugin(pluginName, version string, target configtypes.Target, contex
 		return errors.New(errorNoDiscoverySourcesFound)
 	}
 	criteria := &discovery.PluginDiscoveryCriteria{
-		Name:    pluginName,
-		Target:  target,
-		Version: version,
-		OS:      cli.GOOS,
-		Arch:    cli.GOARCH,
+		Name:         pluginName,
+		Target:       target,
+		Version:      version,
+		OS:           cli.GOOS,
+		Arch:         cli.GOARCH,
+		ArchFallback: plugininventory.ArchFallbackEmulation,
 	}
 	errorList := make([]error, 0)
 	availablePlugins, err := discoverSpecificPlugins(discoveries, discovery.WithPluginDiscoveryCriteria(criteria))
@@ -515,6 +641,8 @@ func installPlugin(pluginName, version string, target configtypes.Target, contex
 	// This leverages Apples Rosetta emulator and Windows 11 emulator until plugins
 	// are all available for ARM64.  Note that this approach cannot be used on Linux since there
 	// is no such emulator.
+	// The DB-backed discoveries already return the AMD64 artifacts of the versions without
+	// ARM64 ones, following the fallback policy of the criteria.
 	if len(availablePlugins) == 0 &&
 		(cli.BuildArch() == cli.DarwinARM64 || cli.BuildArch() == cli.WinARM64) {
 		// Pretend we are on a AMD64 machine so that we can find the plugin.
@@ -582,9 +710,38 @@ func installPlugin(pluginName, version string, target configtypes.Target, contex
 
 // UpgradePlugin upgrades a plugin from the given repository.
 func UpgradePlugin(pluginName, version string, target configtypes.Target) error {
+	previousPlugin, _ := DescribePlugin(pluginName, target)
+
 	// Upgrade is only triggered from a manual user operation.
 	// This means a plugin is installed manually, which means it is installed as a standalone plugin.
-	return InstallStandalonePlugin(pluginName, version, target)
+	if err := InstallStandalonePlugin(pluginName, version, target); err != nil {
+		return err
+	}
+
+	if previousPlugin != nil {
+		logReleaseNotesAfterUpgrade(pluginName, target, previousPlugin.Version)
+	}
+	return nil
+}
+
+// logReleaseNotesAfterUpgrade shows what changed in the newly installed version of the plugin
+// if its publisher provided release notes for that version
+func logReleaseNotesAfterUpgrade(pluginName string, target configtypes.Target, previousVersion string) {
+	plugin, err := DescribePlugin(pluginName, target)
+	if err != nil || plugin.Version == previousVersion {
+		return
+	}
+	releaseNotes := GetPluginReleaseNotes(plugin.Name, plugin.Target, plugin.Version)
+	if releaseNotes == nil {
+		return
+	}
+	log.Infof("What changed in plugin '%s:%s':", plugin.Name, plugin.Version)
+	if releaseNotes.Notes != "" {
+		log.Info(releaseNotes.Notes)
+	}
+	if releaseNotes.ChangelogURL != "" {
+		log.Infof("See the changelog at %s", releaseNotes.ChangelogURL)
+	}
 }
 
 // InstallPluginsFromGroup installs either the specified plugin or all plugins from the specified group version.
@@ -612,6 +769,16 @@ func InstallPluginsFromGivenPluginGroup(pluginName, groupIDAndVersion string, pg
 	numInstalled := 0
 	mandatoryPluginsExist := false
 	pluginExist := false
+
+	// Download the plugins to install concurrently before installing them one at a time
+	var pluginsToInstall []*plugininventory.PluginIdentifier
+	for _, plugin := range pg.Versions[pg.RecommendedVersion] {
+		if (pluginName == cli.AllPlugins || pluginName == plugin.Name) && plugin.Mandatory {
+			pluginsToInstall = append(pluginsToInstall, &plugin.PluginIdentifier)
+		}
+	}
+	prefetchPlugins(pluginsToInstall)
+
 	for _, plugin := range pg.Versions[pg.RecommendedVersion] {
 		if pluginName == cli.AllPlugins || pluginName == plugin.Name {
 			pluginExist = true
@@ -708,6 +875,10 @@ func getPluginInstallationMessage(p *discovery.Discovered, version string, isPlu
 	if p.Target != configtypes.TargetUnknown {
 		withTarget = fmt.Sprintf("with target '%v' ", p.Target)
 	}
+	downloadSize := ""
+	if size, err := p.Distribution.GetSize(version, cli.GOOS, cli.GOARCH); err == nil && size > 0 {
+		downloadSize = fmt.Sprintf("(%s) ", utils.FormatBytes(size))
+	}
 
 	if isPluginInCache {
 		if !isPluginAlreadyInstalled {
@@ -718,7 +889,7 @@ func getPluginInstallationMessage(p *discovery.Discovered, version string, isPlu
 			installedMsg = fmt.Sprintf("Reinitialized plugin '%v:%v' %v", p.Name, version, withTarget)
 		}
 	} else {
-		installingMsg = fmt.Sprintf("Installing plugin '%v:%v' %v", p.Name, version, withTarget)
+		installingMsg = fmt.Sprintf("Installing plugin '%v:%v' %v%v", p.Name, version, withTarget, downloadSize)
 		installedMsg = fmt.Sprintf("Installed plugin '%v:%v' %v", p.Name, version, withTarget)
 	}
 	errorMsg = fmt.Sprintf("Failed to install plugin '%v:%v' %v", p.Name, version, withTarget)
@@ -731,6 +902,14 @@ func installOrUpgradePlugin(p *discovery.Discovered, version string, installTest
 		version = p.RecommendedVersion
 	}
 
+	// Install the AMD64 binary of the versions without ARM64 binary for Darwin and Windows,
+	// which run it with emulation. Go back to ARM64 once the plugin is installed.
+	if emulatedArch := getPluginEmulatedArch(p, version); emulatedArch != "" {
+		currentArch := cli.BuildArch()
+		cli.SetArch(emulatedArch)
+		defer cli.SetArch(currentArch)
+	}
+
 	var isPluginAlreadyInstalled bool
 	var plugin *cli.PluginInfo
 	if !installTestPlugin {
@@ -743,8 +922,14 @@ func installOrUpgradePlugin(p *discovery.Discovered, version string, installTest
 		}
 	}
 
-	// Log message based on different installation conditions
-	installingMsg, installedMsg, errMsg := getPluginInstallationMessage(p, version, plugin != nil, isPluginAlreadyInstalled)
+	// Log message based on different installation conditions.
+	// The plugins prefetched by the current operation are reported as downloaded, not from the cache.
+	isPluginInCache := plugin != nil && !isPluginPrefetched(plugin.InstallationPath)
+	installingMsg, installedMsg, errMsg := getPluginInstallationMessage(p, version, isPluginInCache, isPluginAlreadyInstalled)
+
+	if p.Deprecated {
+		log.Warning(cli.PluginDeprecationWarning(p.Name, p.DeprecationMessage, p.ReplacedBy))
+	}
 
 	var spinner component.OutputWriterSpinner
 
@@ -787,18 +972,39 @@ func installOrUpgradePlugin(p *discovery.Discovered, version string, installTest
 		if spinner != nil {
 			spinner.SetFinalText(errMsg, log.LogTypeERROR)
 		}
+		return pluginErr
 	}
-	return pluginErr
+
+	// Now that a new version may have been installed, remove the
+	// binaries of the plugin that are beyond the retention policy
+	applyRetentionPolicy(p.Name)
+	return nil
+}
+
+// getPluginEmulatedArch returns the arch of the binary of the plugin version to install with emulation,
+// when the version has no binary for the current arch, or an empty arch
+func getPluginEmulatedArch(p *discovery.Discovered, version string) cli.Arch {
+	emulatedArch := cli.BuildArch().EmulatedArch()
+	if emulatedArch == "" || p.Distribution == nil {
+		return ""
+	}
+	if _, err := p.Distribution.DescribeArtifact(version, cli.GOOS, cli.GOARCH); err == nil {
+		return ""
+	}
+	if _, err := p.Distribution.DescribeArtifact(version, emulatedArch.OS(), emulatedArch.Arch()); err != nil {
+		return ""
+	}
+	return emulatedArch
 }
 
 func verifyInstallAndInitializePlugin(plugin *cli.PluginInfo, p *discovery.Discovered, version string, installTestPlugin bool) error {
 	if plugin == nil {
-		binary, err := fetchAndVerifyPlugin(p, version)
+		binary, digest, err := fetchAndVerifyPlugin(p, version)
 		if err != nil {
 			return err
 		}
 
-		plugin, err = installAndDescribePlugin(p, version, binary)
+		plugin, err = installAndDescribePlugin(p, version, binary, digest)
 		if err != nil {
 			return err
 		}
@@ -820,15 +1026,13 @@ func getPluginFromCache(p *discovery.Discovered, version string) *cli.PluginInfo
 	// TODO(khouzam): We should not be checking the presence of the binary directly here,
 	// as it bypasses the plugin catalog abstraction.  Instead, we should ask the plugin
 	// catalog to know if the plugin binary is present already.
-	pluginFileName := fmt.Sprintf("%s_%s_%s", version, pluginArtifact.Digest, p.Target)
-	pluginPath := filepath.Join(common.DefaultPluginRoot, p.Name, pluginFileName)
-
-	if cli.BuildArch().IsWindows() {
-		pluginPath += exe
-	}
+	pluginPath := getPluginBinaryPath(p, version, pluginArtifact.Digest)
 	if _, err = os.Stat(pluginPath); err != nil {
 		return nil
 	}
+	if !verifyPrefetchedPluginBinary(pluginPath, pluginArtifact.Digest) {
+		return nil
+	}
 
 	plugin, err := describePlugin(p, pluginPath)
 	if err != nil {
@@ -837,51 +1041,93 @@ func getPluginFromCache(p *discovery.Discovered, version string) *cli.PluginInfo
 	return plugin
 }
 
-func fetchAndVerifyPlugin(p *discovery.Discovered, version string) ([]byte, error) {
+// fetchAndVerifyPlugin downloads the binary of the plugin version and verifies it.
+// It returns the binary along with its SHA256 digest.
+func fetchAndVerifyPlugin(p *discovery.Discovered, version string) ([]byte, string, error) {
 	// verify plugin before download
 	err := verifyPluginPreDownload(p, version)
 	if err != nil {
-		return nil, errors.Wrapf(err, "%q plugin pre-download verification failed", p.Name)
+		return nil, "", errors.Wrapf(err, "%q plugin pre-download verification failed", p.Name)
 	}
 
 	b, err := p.Distribution.Fetch(version, cli.GOOS, cli.GOARCH)
 	if err != nil {
-		return nil, errors.Wrapf(err, "unable to fetch the plugin metadata for plugin %q", p.Name)
+		return nil, "", errors.Wrapf(err, "unable to fetch the plugin metadata for plugin %q", p.Name)
 	}
 
 	// verify plugin after download but before installation
 	d, err := p.Distribution.GetDigest(version, cli.GOOS, cli.GOARCH)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	err = verifyPluginPostDownload(p, d, b)
+	// The digest is computed only once, as it is also used to name the installed binary
+	actDigest := fmt.Sprintf("%x", sha256.Sum256(b))
+	err = verifyPluginDigest(p, d, actDigest)
 	if err != nil {
-		return nil, errors.Wrapf(err, "%q plugin post-download verification failed", p.Name)
+		return nil, "", errors.Wrapf(err, "%q plugin post-download verification failed", p.Name)
 	}
-	return b, nil
+	if err := cli.CheckPluginArtifactSupported(b); err != nil {
+		return nil, "", errors.Wrapf(err, "unable to install plugin %q", p.Name)
+	}
+	if err := verifyPluginArtifact(p, version, b); err != nil {
+		return nil, "", err
+	}
+	return b, actDigest, nil
 }
 
-func installAndDescribePlugin(p *discovery.Discovered, version string, binary []byte) (*cli.PluginInfo, error) {
-	pluginFileName := fmt.Sprintf("%s_%x_%s", version, sha256.Sum256(binary), p.Target)
-	pluginPath := filepath.Join(common.DefaultPluginRoot, p.Name, pluginFileName)
+func installAndDescribePlugin(p *discovery.Discovered, version string, binary []byte, digest string) (*cli.PluginInfo, error) {
+	pluginPath, err := writePluginBinary(p, version, binary, digest)
+	if err != nil {
+		return nil, err
+	}
+	return describePlugin(p, pluginPath)
+}
 
+// writePluginBinary writes the binary of the plugin version to the plugin root and returns its path
+func writePluginBinary(p *discovery.Discovered, version string, binary []byte, digest string) (string, error) {
+	pluginPath := getPluginBinaryPath(p, version, digest)
 	if err := os.MkdirAll(filepath.Dir(pluginPath), os.ModePerm); err != nil {
-		return nil, err
+		return "", err
+	}
+	// The binary is written to a temporary file which is then renamed, so that another CLI process,
+	// e.g. prefetching the plugins in the background, never finds a partially written binary in the cache
+	tmpFile, err := os.CreateTemp(filepath.Dir(pluginPath), filepath.Base(pluginPath)+catalog.TempPluginBinaryInfix+"*")
+	if err != nil {
+		return "", errors.Wrap(err, "could not write file")
+	}
+	defer os.Remove(tmpFile.Name())
+	_, err = tmpFile.Write(binary)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpFile.Name(), 0755)
+	}
+	if err == nil {
+		err = os.Rename(tmpFile.Name(), pluginPath)
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "could not write file")
 	}
+	return pluginPath, nil
+}
 
+// getPluginBinaryPath returns the path of the binary of the plugin version with the given digest
+func getPluginBinaryPath(p *discovery.Discovered, version, digest string) string {
+	pluginFileName := fmt.Sprintf("%s_%s_%s", version, digest, p.Target)
+	pluginPath := filepath.Join(common.DefaultPluginRoot, p.Name, pluginFileName)
 	if cli.BuildArch().IsWindows() {
 		pluginPath += exe
 	}
-
-	if err := os.WriteFile(pluginPath, binary, 0755); err != nil {
-		return nil, errors.Wrap(err, "could not write file")
-	}
-
-	return describePlugin(p, pluginPath)
+	return pluginPath
 }
 
 func describePlugin(p *discovery.Discovered, pluginPath string) (*cli.PluginInfo, error) {
-	bytesInfo, err := execCommand(pluginPath, "info").Output()
+	executable, args, err := cli.PluginCommandLine(pluginPath, "info")
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not describe plugin %q", p.Name)
+	}
+	bytesInfo, err := execCommand(executable, args...).Output()
 	if err != nil {
 		return nil, errors.Wrapf(err, "could not describe plugin %q", p.Name)
 	}
@@ -895,7 +1141,10 @@ func describePlugin(p *discovery.Discovered, pluginPath string) (*cli.PluginInfo
 	plugin.DiscoveredRecommendedVersion = p.RecommendedVersion
 	plugin.Target = p.Target
 	plugin.Scope = p.Scope
-	if plugin.Version == p.RecommendedVersion {
+	plugin.Deprecated = p.Deprecated
+	plugin.DeprecationMessage = p.DeprecationMessage
+	plugin.ReplacedBy = p.ReplacedBy
+	if !IsPluginUpdateAvailable(p, plugin.Version) {
 		plugin.Status = common.PluginStatusInstalled
 	} else {
 		plugin.Status = common.PluginStatusUpdateAvailable
@@ -1157,6 +1406,16 @@ func InstallDiscoveredContextPlugins(plugins []discovery.Discovered) error {
 	var err error
 	installed := false
 	UpdatePluginsInstallationStatus(plugins)
+
+	// Download the plugins to install concurrently before installing them one at a time
+	var pluginsToInstall []*plugininventory.PluginIdentifier
+	for idx := range plugins {
+		if plugins[idx].Status == common.PluginStatusNotInstalled || plugins[idx].Status == common.PluginStatusUpdateAvailable {
+			pluginsToInstall = append(pluginsToInstall, &plugininventory.PluginIdentifier{Name: plugins[idx].Name, Target: plugins[idx].Target, Version: plugins[idx].RecommendedVersion})
+		}
+	}
+	prefetchPlugins(pluginsToInstall)
+
 	for idx := range plugins {
 		if plugins[idx].Status == common.PluginStatusNotInstalled || plugins[idx].Status == common.PluginStatusUpdateAvailable {
 			installed = true
@@ -1328,6 +1587,53 @@ func Clean() error {
 	return kerrors.NewAggregate(errorList)
 }
 
+// GarbageCollectPluginBinaries removes the plugin binaries that are no longer referenced
+// by the catalog and that are not retained by the retention policy. It returns the removed
+// binaries along with the reclaimed disk space.
+// If dryRun is true, the binaries that would be removed are only reported.
+func GarbageCollectPluginBinaries(dryRun bool) (*catalog.GarbageCollectionResult, error) {
+	result, err := catalog.GarbageCollectPluginBinaries(catalog.GarbageCollectionOptions{
+		DryRun:           dryRun,
+		RetainedVersions: getPluginBinaryRetentionCount(),
+	})
+	if result != nil {
+		for _, path := range result.RemovedPaths {
+			log.V(6).Infof("Removed orphaned plugin binary %q", path)
+		}
+	}
+	return result, err
+}
+
+// applyRetentionPolicy removes the binaries of the specified plugin which are
+// older than the number of versions to retain in the plugin store
+func applyRetentionPolicy(pluginName string) {
+	result, err := catalog.GarbageCollectPluginBinaries(catalog.GarbageCollectionOptions{
+		RetainedVersions: getPluginBinaryRetentionCount(),
+		PluginName:       pluginName,
+		RetentionOnly:    true,
+	})
+	if err != nil {
+		log.V(6).Infof("could not apply the retention policy for plugin %q: %v", pluginName, err)
+		return
+	}
+	for _, path := range result.RemovedPaths {
+		log.V(6).Infof("Removed plugin binary %q beyond retention", path)
+	}
+}
+
+// getPluginBinaryRetentionCount returns the number of versions of each plugin kept in the plugin store
+func getPluginBinaryRetentionCount() int {
+	retentionCount := constants.DefaultPluginBinaryRetentionCount
+	retentionCountOverride := os.Getenv(constants.ConfigVariablePluginBinaryRetentionCount)
+	if retentionCountOverride != "" {
+		retentionCountOverrideValue, err := strconv.Atoi(retentionCountOverride)
+		if err == nil && retentionCountOverrideValue >= 0 {
+			retentionCount = retentionCountOverrideValue
+		}
+	}
+	return retentionCount
+}
+
 // getCLIPluginResourceWithLocalDistroFromPluginInfo return cliv1alpha1.CLIPlugin resource from the pluginInfo
 // Note: This function generates cliv1alpha1.CLIPlugin which contains only single local distribution type artifact for
 // OS-ARCH where user is running the cli
@@ -1520,21 +1826,16 @@ func verifyArtifactLocation(uri string) error {
 	}
 }
 
-// verifyPluginPostDownload compares the source digest of the plugin against the
-// SHA256 hash of the downloaded binary to ensure that the binary was not altered
-// during transit.
-func verifyPluginPostDownload(p *discovery.Discovered, srcDigest string, b []byte) error {
+// verifyPluginDigest compares the source digest of the plugin against the
+// SHA256 digest of the downloaded binary.
+func verifyPluginDigest(p *discovery.Discovered, srcDigest, actDigest string) error {
 	if srcDigest == "" {
 		// Skip if the Distribution repo does not have the source digest.
 		return nil
 	}
-
-	d := sha256.Sum256(b)
-	actDigest := fmt.Sprintf("%x", d)
 	if actDigest != srcDigest {
 		return errors.Errorf("plugin %q has been corrupted during download. source digest: %s, actual digest: %s", p.Name, srcDigest, actDigest)
 	}
-
 	return nil
 }
 
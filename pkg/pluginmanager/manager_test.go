@@ -4,6 +4,7 @@
 package pluginmanager
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"os"
 	"os/exec"
@@ -11,12 +12,16 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
 
+	cliv1alpha1 "github.com/vmware-tanzu/tanzu-cli/apis/cli/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/catalog"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
 	"github.com/vmware-tanzu/tanzu-cli/pkg/config"
@@ -566,6 +571,115 @@ func Test_InstallPluginsFromGroupErrors(t *testing.T) {
 	assertions.Contains(err.Error(), fmt.Sprintf("plugin 'cluster' from group '%s' is not mandatory to install", fullGroupID))
 }
 
+func Test_PrefetchPlugins(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	discoveries, err := getPluginDiscoveries()
+	assertions.Nil(err)
+
+	// A plugin which does not exist cannot be prefetched
+	assertions.Nil(findPluginToPrefetch(discoveries, &plugininventory.PluginIdentifier{Name: "not-a-plugin", Target: configtypes.TargetK8s, Version: "v1.0.0"}))
+
+	// The plugins of the test inventory are already in the plugin cache, so they are not prefetched
+	pi := &plugininventory.PluginIdentifier{Name: "management-cluster", Target: configtypes.TargetK8s, Version: "v1.6.0"}
+	assertions.Nil(findPluginToPrefetch(discoveries, pi))
+	prefetchPlugins([]*plugininventory.PluginIdentifier{pi, {Name: "isolated-cluster", Target: configtypes.TargetGlobal, Version: "v1.2.3"}})
+	assertions.Empty(prefetchedPluginPaths)
+
+	// Once removed from the plugin cache, the plugin must be prefetched
+	discovered, err := DiscoverStandalonePlugins(discovery.WithPluginDiscoveryCriteria(&discovery.PluginDiscoveryCriteria{
+		Name: pi.Name, Target: pi.Target, Version: pi.Version, OS: cli.GOOS, Arch: cli.GOARCH,
+	}))
+	assertions.Nil(err)
+	assertions.Equal(1, len(discovered))
+	plugin := getPluginFromCache(&discovered[0], pi.Version)
+	assertions.NotNil(plugin)
+	assertions.False(isPluginPrefetched(plugin.InstallationPath))
+	assertions.Nil(os.Remove(plugin.InstallationPath))
+
+	p := findPluginToPrefetch(discoveries, pi)
+	assertions.NotNil(p)
+	assertions.Equal(pi.Name, p.Name)
+	assertions.Equal(pi.Target, p.Target)
+	assertions.Equal(pi.Version, p.RecommendedVersion)
+}
+
+func Test_PrefetchDiscoveredContextPlugins(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	pi := &plugininventory.PluginIdentifier{Name: "management-cluster", Target: configtypes.TargetK8s, Version: "v1.6.0"}
+	discovered, err := DiscoverStandalonePlugins(discovery.WithPluginDiscoveryCriteria(&discovery.PluginDiscoveryCriteria{
+		Name: pi.Name, Target: pi.Target, Version: pi.Version, OS: cli.GOOS, Arch: cli.GOARCH,
+	}))
+	assertions.Nil(err)
+	assertions.Equal(1, len(discovered))
+	plugin := getPluginFromCache(&discovered[0], pi.Version)
+	assertions.NotNil(plugin)
+
+	// The plugin is already in the plugin cache, so it is not prefetched
+	PrefetchDiscoveredContextPlugins(discovered)
+	assertions.False(isPluginPrefetched(plugin.InstallationPath))
+
+	assertions.Equal(common.PluginStatusNotInstalled, discovered[0].Status)
+
+	// Once removed from the plugin cache, the plugin binary cannot be verified against the digest of the
+	// test inventory, so nothing is written to the plugin cache
+	assertions.Nil(os.Remove(plugin.InstallationPath))
+	PrefetchDiscoveredContextPlugins(discovered)
+	assertions.False(isPluginPrefetched(plugin.InstallationPath))
+	assertions.NoFileExists(plugin.InstallationPath)
+}
+
+func Test_WritePluginBinary(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+
+	p := &discovery.Discovered{Name: "written-plugin", Target: configtypes.TargetK8s}
+	pluginPath, err := writePluginBinary(p, "v1.0.0", []byte("binary"), "0123456789")
+	assertions.Nil(err)
+	assertions.Equal(getPluginBinaryPath(p, "v1.0.0", "0123456789"), pluginPath)
+
+	b, err := os.ReadFile(pluginPath)
+	assertions.Nil(err)
+	assertions.Equal("binary", string(b))
+
+	// The binary is written through a temporary file which must not be left behind
+	entries, err := os.ReadDir(filepath.Dir(pluginPath))
+	assertions.Nil(err)
+	assertions.Len(entries, 1)
+}
+
+func Test_VerifyPrefetchedPluginBinary(t *testing.T) {
+	assertions := assert.New(t)
+
+	dir := t.TempDir()
+	pluginPath := filepath.Join(dir, "v1.0.0_digest_kubernetes")
+	assertions.Nil(os.WriteFile(pluginPath, []byte("binary"), 0o755))
+	digest := fmt.Sprintf("%x", sha256.Sum256([]byte("binary")))
+
+	// The binaries which were not prefetched are not verified again
+	assertions.True(verifyPrefetchedPluginBinary(pluginPath, "other-digest"))
+
+	assertions.Nil(os.WriteFile(pluginPath+catalog.PrefetchedPluginMarkerSuffix, nil, 0o600))
+	assertions.True(verifyPrefetchedPluginBinary(pluginPath, digest))
+	assertions.FileExists(pluginPath)
+
+	// A truncated prefetched binary is removed from the plugin cache
+	assertions.Nil(os.WriteFile(pluginPath, []byte("bin"), 0o755))
+	assertions.False(verifyPrefetchedPluginBinary(pluginPath, digest))
+	assertions.NoFileExists(pluginPath)
+	assertions.NoFileExists(pluginPath + catalog.PrefetchedPluginMarkerSuffix)
+}
+
 func Test_InstallPlugin_InstalledPlugins_From_LocalSource(t *testing.T) {
 	assertions := assert.New(t)
 
@@ -846,6 +960,38 @@ func Test_setAvailablePluginsStatus(t *testing.T) {
 	assertions.Equal("v3.0.0", availablePlugins[0].RecommendedVersion)
 	assertions.Equal("v1.0.0", availablePlugins[0].InstalledVersion)
 	assertions.Equal(common.PluginStatusUpdateAvailable, availablePlugins[0].Status)
+
+	// If the installed version was published after the recommended version, e.g. a hotfix,
+	// then available plugin status should show 'installed'
+	availablePlugins[0].Status = common.PluginStatusNotInstalled
+	availablePlugins[0].ReleasedAt = map[string]time.Time{
+		"v1.0.0": time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC),
+		"v3.0.0": time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+	setAvailablePluginsStatus(availablePlugins, installedPlugin)
+	assertions.Equal("v1.0.0", availablePlugins[0].InstalledVersion)
+	assertions.Equal(common.PluginStatusInstalled, availablePlugins[0].Status)
+}
+
+func TestIsPluginUpdateAvailable(t *testing.T) {
+	assertions := assert.New(t)
+
+	p := &discovery.Discovered{Name: "myplugin", RecommendedVersion: "v1.2.0"}
+	assertions.False(IsPluginUpdateAvailable(p, "v1.2.0"))
+	// Without release times, any other recommended version is an update
+	assertions.True(IsPluginUpdateAvailable(p, "v1.1.0"))
+	assertions.True(IsPluginUpdateAvailable(p, "v1.3.0"))
+
+	p.ReleasedAt = map[string]time.Time{
+		"v1.1.5": time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC),
+		"v1.2.0": time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+		"v1.1.0": time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC),
+	}
+	assertions.True(IsPluginUpdateAvailable(p, "v1.1.0"))
+	// A version published after the recommended one is not updated
+	assertions.False(IsPluginUpdateAvailable(p, "v1.1.5"))
+	// The release time of the installed version is unknown
+	assertions.True(IsPluginUpdateAvailable(p, "v1.0.0"))
 }
 
 func Test_DiscoverPluginsFromLocalSourceBasedOnManifestFile(t *testing.T) {
@@ -1012,7 +1158,7 @@ func TestVerifyArtifactLocation(t *testing.T) {
 	}
 }
 
-func TestVerifyPluginPostDownload(t *testing.T) {
+func TestVerifyPluginDigest(t *testing.T) {
 	tcs := []struct {
 		name string
 		p    *discovery.Discovered
@@ -1045,7 +1191,7 @@ func TestVerifyPluginPostDownload(t *testing.T) {
 			b, err := os.ReadFile(tc.path)
 			assert.NoError(t, err)
 
-			err = verifyPluginPostDownload(tc.p, tc.d, b)
+			err = verifyPluginDigest(tc.p, tc.d, fmt.Sprintf("%x", sha256.Sum256(b)))
 			if tc.err != "" {
 				assert.EqualError(t, err, tc.err)
 			} else {
@@ -1519,6 +1665,117 @@ func TestMergeDuplicatePluginsWithReplacedVersion(t *testing.T) {
 	assertions.Equal(expectedPlugin, mergedPlugins[0])
 }
 
+func TestMergeDuplicatePluginsWithReleaseNotes(t *testing.T) {
+	assertions := assert.New(t)
+
+	preMergePlugins := []discovery.Discovered{
+		{
+			Name:              "myplugin",
+			Target:            configtypes.TargetK8s,
+			SupportedVersions: []string{"v1.1.1"},
+			Distribution: distribution.Artifacts{
+				"v1.1.1": []distribution.Artifact{{Image: "localhost:9876/my/discovery/linux_amd64:v1.1.1", OS: "linux", Arch: "amd64"}},
+			},
+			ReleaseNotes: map[string]plugininventory.PluginReleaseNotes{
+				"v1.1.1": {Notes: "First notes"},
+			},
+		},
+		{
+			Name:              "myplugin",
+			Target:            configtypes.TargetK8s,
+			SupportedVersions: []string{"v1.1.1", "v2.2.2"},
+			Distribution: distribution.Artifacts{
+				"v1.1.1": []distribution.Artifact{{Image: "localhost:9876/my/discovery2/linux_amd64:v1.1.1", OS: "linux", Arch: "amd64"}},
+				"v2.2.2": []distribution.Artifact{{Image: "localhost:9876/my/discovery2/linux_amd64:v2.2.2", OS: "linux", Arch: "amd64"}},
+			},
+			ReleaseNotes: map[string]plugininventory.PluginReleaseNotes{
+				"v1.1.1": {Notes: "Second notes"},
+				"v2.2.2": {ChangelogURL: "https://example.com/myplugin/v2.2.2"},
+			},
+		},
+	}
+
+	// The release notes of the versions found first are kept
+	mergedPlugins := mergeDuplicatePlugins(preMergePlugins)
+	assertions.Equal(1, len(mergedPlugins))
+	assertions.Equal(map[string]plugininventory.PluginReleaseNotes{
+		"v1.1.1": {Notes: "First notes"},
+		"v2.2.2": {ChangelogURL: "https://example.com/myplugin/v2.2.2"},
+	}, mergedPlugins[0].ReleaseNotes)
+}
+
+func TestMergeDuplicatePluginsWithLicenses(t *testing.T) {
+	assertions := assert.New(t)
+
+	preMergePlugins := []discovery.Discovered{
+		{
+			Name:              "myplugin",
+			Target:            configtypes.TargetK8s,
+			SupportedVersions: []string{"v1.1.1"},
+			Distribution: distribution.Artifacts{
+				"v1.1.1": []distribution.Artifact{{Image: "localhost:9876/my/discovery/linux_amd64:v1.1.1", OS: "linux", Arch: "amd64"}},
+			},
+			Licenses: map[string]plugininventory.PluginLicense{
+				"v1.1.1": {License: "Apache-2.0"},
+			},
+		},
+		{
+			Name:              "myplugin",
+			Target:            configtypes.TargetK8s,
+			SupportedVersions: []string{"v1.1.1", "v2.2.2"},
+			Distribution: distribution.Artifacts{
+				"v1.1.1": []distribution.Artifact{{Image: "localhost:9876/my/discovery2/linux_amd64:v1.1.1", OS: "linux", Arch: "amd64"}},
+				"v2.2.2": []distribution.Artifact{{Image: "localhost:9876/my/discovery2/linux_amd64:v2.2.2", OS: "linux", Arch: "amd64"}},
+			},
+			Licenses: map[string]plugininventory.PluginLicense{
+				"v1.1.1": {License: "MIT"},
+				"v2.2.2": {License: "Apache-2.0", SBOMURI: "https://example.com/myplugin/v2.2.2/sbom.json"},
+			},
+		},
+	}
+
+	// The licenses of the versions found first are kept
+	mergedPlugins := mergeDuplicatePlugins(preMergePlugins)
+	assertions.Equal(1, len(mergedPlugins))
+	assertions.Equal(map[string]plugininventory.PluginLicense{
+		"v1.1.1": {License: "Apache-2.0"},
+		"v2.2.2": {License: "Apache-2.0", SBOMURI: "https://example.com/myplugin/v2.2.2/sbom.json"},
+	}, mergedPlugins[0].Licenses)
+}
+
+func TestMergeDuplicatePluginsWithReleaseTimes(t *testing.T) {
+	assertions := assert.New(t)
+
+	preMergePlugins := []discovery.Discovered{
+		{
+			Name:              "myplugin",
+			Target:            configtypes.TargetK8s,
+			SupportedVersions: []string{"v1.1.1"},
+			Distribution: distribution.Artifacts{
+				"v1.1.1": []distribution.Artifact{{Image: "localhost:9876/my/discovery/linux_amd64:v1.1.1", OS: "linux", Arch: "amd64"}},
+			},
+		},
+		{
+			Name:              "myplugin",
+			Target:            configtypes.TargetK8s,
+			SupportedVersions: []string{"v1.1.1", "v2.2.2"},
+			Distribution: distribution.Artifacts{
+				"v1.1.1": []distribution.Artifact{{Image: "localhost:9876/my/discovery2/linux_amd64:v1.1.1", OS: "linux", Arch: "amd64"}},
+				"v2.2.2": []distribution.Artifact{{Image: "localhost:9876/my/discovery2/linux_amd64:v2.2.2", OS: "linux", Arch: "amd64"}},
+			},
+			ReleasedAt: map[string]time.Time{
+				"v1.1.1": time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC),
+				"v2.2.2": time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	// Only the release times of the versions added by the merge are kept
+	mergedPlugins := mergeDuplicatePlugins(preMergePlugins)
+	assertions.Equal(1, len(mergedPlugins))
+	assertions.Equal(map[string]time.Time{"v2.2.2": time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)}, mergedPlugins[0].ReleasedAt)
+}
+
 func TestMergeDuplicateGroups(t *testing.T) {
 	assertions := assert.New(t)
 
@@ -1826,3 +2083,112 @@ func TestRemoveOldPluginsWhenDuplicates(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPluginBinaryRetentionCount(t *testing.T) {
+	tests := []struct {
+		name     string
+		override string
+		expected int
+	}{
+		{name: "default", override: "", expected: constants.DefaultPluginBinaryRetentionCount},
+		{name: "valid override", override: "5", expected: 5},
+		{name: "zero override", override: "0", expected: 0},
+		{name: "negative override", override: "-1", expected: constants.DefaultPluginBinaryRetentionCount},
+		{name: "invalid override", override: "abc", expected: constants.DefaultPluginBinaryRetentionCount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(constants.ConfigVariablePluginBinaryRetentionCount, tt.override)
+			assert.Equal(t, tt.expected, getPluginBinaryRetentionCount())
+		})
+	}
+}
+
+func Test_SyncPluginSets(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer setupPluginSourceForTesting()()
+	execCommand = fakeInfoExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	pluginSet := func(name string, plugins ...cliv1alpha1.PluginSetEntry) cliv1alpha1.TanzuCLIPluginSet {
+		return cliv1alpha1.TanzuCLIPluginSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       cliv1alpha1.TanzuCLIPluginSetSpec{Plugins: plugins},
+		}
+	}
+
+	// Install the declared version of the plugin
+	err := syncPluginSets([]cliv1alpha1.TanzuCLIPluginSet{
+		pluginSet("set1", cliv1alpha1.PluginSetEntry{Name: "login", Version: "v0.2.0"}),
+		pluginSet("set2", cliv1alpha1.PluginSetEntry{Name: "login", Version: "v0.2.0"}),
+	})
+	assertions.Nil(err)
+	installedPlugins, err := pluginsupplier.GetInstalledPlugins()
+	assertions.Nil(err)
+	assertions.Equal(1, len(installedPlugins))
+	assertions.Equal("login", installedPlugins[0].Name)
+	assertions.Equal("v0.2.0", installedPlugins[0].Version)
+
+	// A plugin declared without a version is not updated when it is installed
+	err = syncPluginSets([]cliv1alpha1.TanzuCLIPluginSet{pluginSet("set1", cliv1alpha1.PluginSetEntry{Name: "login"})})
+	assertions.Nil(err)
+	installedPlugins, err = pluginsupplier.GetInstalledPlugins()
+	assertions.Nil(err)
+	assertions.Equal(1, len(installedPlugins))
+	assertions.Equal("v0.2.0", installedPlugins[0].Version)
+
+	// The plugin is updated when another version is declared
+	err = syncPluginSets([]cliv1alpha1.TanzuCLIPluginSet{pluginSet("set1", cliv1alpha1.PluginSetEntry{Name: "login", Version: "v0.20.0"})})
+	assertions.Nil(err)
+	installedPlugins, err = pluginsupplier.GetInstalledPlugins()
+	assertions.Nil(err)
+	assertions.Equal(1, len(installedPlugins))
+	assertions.Equal("v0.20.0", installedPlugins[0].Version)
+
+	// The installation errors are reported
+	err = syncPluginSets([]cliv1alpha1.TanzuCLIPluginSet{pluginSet("set1", cliv1alpha1.PluginSetEntry{Name: "not-exists"})})
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), "unable to find plugin 'not-exists'")
+
+	// Plugin sets cannot declare different versions of a plugin
+	err = syncPluginSets([]cliv1alpha1.TanzuCLIPluginSet{
+		pluginSet("set1", cliv1alpha1.PluginSetEntry{Name: "login", Version: "v0.2.0"}),
+		pluginSet("set2", cliv1alpha1.PluginSetEntry{Name: "login", Version: "v0.20.0"}),
+	})
+	assertions.NotNil(err)
+	assertions.Contains(err.Error(), `the TanzuCLIPluginSet resources "default/set1" and "default/set2" declare different versions of plugin 'login'`)
+}
+
+func TestGetPluginEmulatedArch(t *testing.T) {
+	assertions := assert.New(t)
+
+	defer cli.SetArch(cli.BuildArch())
+
+	p := &discovery.Discovered{
+		Name: "myplugin",
+		Distribution: distribution.Artifacts{
+			"v1.0.0": []distribution.Artifact{
+				{Image: "localhost:9876/darwin/amd64/myplugin:v1.0.0", OS: "darwin", Arch: "amd64"},
+				{Image: "localhost:9876/darwin/arm64/myplugin:v1.0.0", OS: "darwin", Arch: "arm64"},
+			},
+			"v2.0.0": []distribution.Artifact{
+				{Image: "localhost:9876/darwin/amd64/myplugin:v2.0.0", OS: "darwin", Arch: "amd64"},
+				{Image: "localhost:9876/linux/amd64/myplugin:v2.0.0", OS: "linux", Arch: "amd64"},
+			},
+		},
+	}
+
+	cli.SetArch(cli.DarwinARM64)
+	assertions.Equal(cli.Arch(""), getPluginEmulatedArch(p, "v1.0.0"))
+	assertions.Equal(cli.DarwinAMD64, getPluginEmulatedArch(p, "v2.0.0"))
+
+	// There is no emulation on linux
+	cli.SetArch(cli.LinuxARM64)
+	assertions.Equal(cli.Arch(""), getPluginEmulatedArch(p, "v2.0.0"))
+
+	// There is no amd64 artifact to fall back to for windows
+	cli.SetArch(cli.WinARM64)
+	assertions.Equal(cli.Arch(""), getPluginEmulatedArch(p, "v2.0.0"))
+}
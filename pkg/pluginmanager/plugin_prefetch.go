@@ -0,0 +1,182 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/catalog"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/discovery"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugininventory"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+)
+
+// pluginPrefetchConcurrency is the maximum number of plugin binaries downloaded and
+// verified concurrently when installing multiple plugins
+const pluginPrefetchConcurrency = 4
+
+var (
+	// prefetchedPluginPaths are the paths of the plugin binaries written by prefetchPlugins.
+	// They are in the plugin cache but are reported as downloaded when they get installed.
+	prefetchedPluginPaths      = map[string]bool{}
+	prefetchedPluginPathsMutex sync.Mutex
+)
+
+// PrefetchDiscoveredContextPlugins downloads the binaries of the discovered context plugins which are not
+// installed or can be updated to the plugin cache, without installing the plugins. The plugins can then be
+// installed from the plugin cache, without downloading them, by a later sync.
+// Any failure is ignored, as the installation of the plugin downloads it again and reports the error.
+func PrefetchDiscoveredContextPlugins(plugins []discovery.Discovered) {
+	UpdatePluginsInstallationStatus(plugins)
+
+	var pluginsToPrefetch []*plugininventory.PluginIdentifier
+	for idx := range plugins {
+		if plugins[idx].Status == common.PluginStatusNotInstalled || plugins[idx].Status == common.PluginStatusUpdateAvailable {
+			pluginsToPrefetch = append(pluginsToPrefetch, &plugininventory.PluginIdentifier{Name: plugins[idx].Name, Target: plugins[idx].Target, Version: plugins[idx].RecommendedVersion})
+		}
+	}
+	prefetchPluginBinaries(pluginsToPrefetch)
+}
+
+// prefetchPlugins downloads the binaries of the plugins about to be installed by an operation installing
+// multiple plugins, e.g. a plugin group installation or a sync, and verifies their digest, using concurrent
+// workers. The verified binaries are written to the plugin cache, from which the plugins are then installed
+// one at a time. Downloading and digesting large binaries one at a time otherwise dominates the installation.
+// Any failure is ignored, as the installation of the plugin downloads it again and reports the error.
+func prefetchPlugins(plugins []*plugininventory.PluginIdentifier) {
+	if len(plugins) < 2 {
+		return
+	}
+	prefetchPluginBinaries(plugins)
+}
+
+// prefetchPluginBinaries downloads and verifies the binaries of the plugins which are not in the
+// plugin cache, using concurrent workers, and writes them to the plugin cache
+func prefetchPluginBinaries(plugins []*plugininventory.PluginIdentifier) {
+	if len(plugins) == 0 {
+		return
+	}
+	discoveries, err := getPluginDiscoveries()
+	if err != nil || len(discoveries) == 0 {
+		return
+	}
+
+	var eg errgroup.Group
+	eg.SetLimit(pluginPrefetchConcurrency)
+	for _, pi := range plugins {
+		// The plugins are discovered from the inventory cache, which was just refreshed by
+		// the discovery of the plugins to install
+		p := findPluginToPrefetch(discoveries, pi)
+		if p == nil {
+			continue
+		}
+		eg.Go(func() error {
+			prefetchPlugin(p)
+			return nil
+		})
+	}
+	_ = eg.Wait()
+}
+
+// findPluginToPrefetch returns the plugin to install for the plugin identifier, or nil if the plugin
+// is ambiguous, already in the plugin cache, or cannot be prefetched
+func findPluginToPrefetch(discoveries []configtypes.PluginDiscovery, pi *plugininventory.PluginIdentifier) *discovery.Discovered {
+	criteria := &discovery.PluginDiscoveryCriteria{
+		Name:    pi.Name,
+		Target:  pi.Target,
+		Version: pi.Version,
+		OS:      cli.GOOS,
+		Arch:    cli.GOARCH,
+	}
+	availablePlugins, err := discoverSpecificPlugins(discoveries, discovery.WithPluginDiscoveryCriteria(criteria), discovery.WithUseLocalCacheOnly())
+	if err != nil {
+		return nil
+	}
+	availablePlugins = mergeDuplicatePlugins(availablePlugins)
+	if len(availablePlugins) != 1 || availablePlugins[0].Name != pi.Name {
+		return nil
+	}
+	p := &availablePlugins[0]
+
+	// Only the binaries with a digest can be found in the plugin cache by the installation
+	artifact, err := p.Distribution.DescribeArtifact(p.RecommendedVersion, cli.GOOS, cli.GOARCH)
+	if err != nil || artifact.Digest == "" {
+		return nil
+	}
+	if _, err := os.Stat(getPluginBinaryPath(p, p.RecommendedVersion, artifact.Digest)); err == nil {
+		return nil
+	}
+	return p
+}
+
+// prefetchPlugin downloads and verifies the binary of the plugin, then writes it to the plugin cache
+func prefetchPlugin(p *discovery.Discovered) {
+	binary, digest, err := fetchAndVerifyPlugin(p, p.RecommendedVersion)
+	if err != nil {
+		log.V(7).Infof("unable to prefetch plugin '%s:%s': %v", p.Name, p.RecommendedVersion, err)
+		return
+	}
+	pluginPath, err := writePluginBinary(p, p.RecommendedVersion, binary, digest)
+	if err != nil {
+		log.V(7).Infof("unable to prefetch plugin '%s:%s': %v", p.Name, p.RecommendedVersion, err)
+		return
+	}
+	// The marker keeps the garbage collection from removing the binary before the plugin is installed
+	if err := os.WriteFile(pluginPath+catalog.PrefetchedPluginMarkerSuffix, nil, 0o600); err != nil {
+		log.V(7).Infof("unable to mark plugin '%s:%s' as prefetched: %v", p.Name, p.RecommendedVersion, err)
+	}
+	prefetchedPluginPathsMutex.Lock()
+	defer prefetchedPluginPathsMutex.Unlock()
+	prefetchedPluginPaths[pluginPath] = true
+}
+
+// isPluginPrefetched returns true if the plugin binary was written by prefetchPlugins
+func isPluginPrefetched(pluginPath string) bool {
+	prefetchedPluginPathsMutex.Lock()
+	defer prefetchedPluginPathsMutex.Unlock()
+	return prefetchedPluginPaths[pluginPath]
+}
+
+// verifyPrefetchedPluginBinary returns false if the plugin binary was prefetched, possibly by another
+// CLI process such as a background prefetch, and its digest does not match the digest of the plugin.
+// Such a binary is removed from the plugin cache so that the plugin gets downloaded again.
+// The binaries which were not prefetched are not verified again, as they were verified when installed.
+func verifyPrefetchedPluginBinary(pluginPath, digest string) bool {
+	markerPath := pluginPath + catalog.PrefetchedPluginMarkerSuffix
+	if _, err := os.Stat(markerPath); err != nil {
+		return true
+	}
+	actDigest, err := fileSHA256(pluginPath)
+	if err == nil && actDigest == digest {
+		return true
+	}
+	log.V(7).Infof("removing the prefetched plugin binary %q which does not match its digest", pluginPath)
+	_ = os.Remove(pluginPath)
+	_ = os.Remove(markerPath)
+	return false
+}
+
+// fileSHA256 returns the hex encoded SHA256 digest of the file
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginmanager
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	configtypes "github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+
+	cliv1alpha1 "github.com/vmware-tanzu/tanzu-cli/apis/cli/v1alpha1"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cli"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/cluster"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/pluginsupplier"
+)
+
+// SyncPluginsFromCluster installs the standalone plugins declared by the TanzuCLIPluginSet resources
+// of the cluster of the kubeconfig and kubecontext which are not installed with the declared version.
+// The current context of the kubeconfig is used if the kubecontext is empty, and the default kubeconfig
+// is used if the kubeconfig is empty.
+func SyncPluginsFromCluster(kubeconfigPath, kubecontext string) error {
+	clusterClient, err := cluster.NewClient(kubeconfigPath, kubecontext, nil, cluster.Options{})
+	if err != nil {
+		return err
+	}
+	pluginSets, err := clusterClient.ListTanzuCLIPluginSetResources()
+	if err != nil {
+		return errors.Wrap(err, "unable to list the TanzuCLIPluginSet resources of the cluster")
+	}
+	return syncPluginSets(pluginSets)
+}
+
+// syncPluginSets installs the plugins of the plugin sets which are not installed with the declared version.
+// A plugin declared without a version is only installed, with its latest version, if it is not installed.
+// The installed plugins which are not declared by any plugin set are left installed.
+func syncPluginSets(pluginSets []cliv1alpha1.TanzuCLIPluginSet) error {
+	plugins, err := mergePluginSets(pluginSets)
+	if err != nil {
+		return err
+	}
+	installedPlugins, err := pluginsupplier.GetInstalledPlugins()
+	if err != nil {
+		return err
+	}
+
+	errList := make([]error, 0)
+	for _, p := range plugins {
+		if isPluginSetEntryInstalled(installedPlugins, p) {
+			continue
+		}
+		version := p.Version
+		if version == "" {
+			version = cli.VersionLatest
+		}
+		log.Infof("Installing plugin '%s:%s' with target '%s' declared by the cluster", p.Name, version, p.Target)
+		if err := InstallStandalonePlugin(p.Name, version, configtypes.StringToTarget(string(p.Target))); err != nil {
+			errList = append(errList, err)
+		}
+	}
+	return kerrors.NewAggregate(errList)
+}
+
+// mergePluginSets returns the plugins of all the plugin sets, in order and without duplicates.
+// It returns an error if plugin sets declare different versions of the same plugin.
+func mergePluginSets(pluginSets []cliv1alpha1.TanzuCLIPluginSet) ([]cliv1alpha1.PluginSetEntry, error) {
+	var plugins []cliv1alpha1.PluginSetEntry
+	declaredBy := map[string]string{}
+	versions := map[string]string{}
+	for i := range pluginSets {
+		setName := fmt.Sprintf("%s/%s", pluginSets[i].Namespace, pluginSets[i].Name)
+		for _, p := range pluginSets[i].Spec.Plugins {
+			p.Target = configtypes.StringToTarget(string(p.Target))
+			key := fmt.Sprintf("%s/%s", p.Name, p.Target)
+			if version, exists := versions[key]; exists {
+				if version != p.Version {
+					return nil, errors.Errorf("the TanzuCLIPluginSet resources %q and %q declare different versions of plugin '%s' with target '%s'", declaredBy[key], setName, p.Name, p.Target)
+				}
+				continue
+			}
+			versions[key] = p.Version
+			declaredBy[key] = setName
+			plugins = append(plugins, p)
+		}
+	}
+	return plugins, nil
+}
+
+// isPluginSetEntryInstalled returns true if the plugin is installed with the declared version,
+// or with any version if the plugin is declared without a version
+func isPluginSetEntryInstalled(installedPlugins []cli.PluginInfo, p cliv1alpha1.PluginSetEntry) bool {
+	for i := range installedPlugins {
+		if installedPlugins[i].Name != p.Name {
+			continue
+		}
+		if p.Target != configtypes.TargetUnknown && installedPlugins[i].Target != p.Target {
+			continue
+		}
+		if p.Version == "" || installedPlugins[i].Version == p.Version {
+			return true
+		}
+	}
+	return false
+}
@@ -7,15 +7,20 @@ import (
 	"archive/tar"
 	"bytes"
 	"io"
+	"strings"
 
 	"github.com/cppforlife/go-cli-ui/ui"
 	regname "github.com/google/go-containerregistry/pkg/name"
 	regv1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/vmware-tanzu/carvel-imgpkg/pkg/imgpkg/bundle"
 	"github.com/vmware-tanzu/carvel-imgpkg/pkg/imgpkg/cmd"
+	ctlimgset "github.com/vmware-tanzu/carvel-imgpkg/pkg/imgpkg/imageset"
+	"github.com/vmware-tanzu/carvel-imgpkg/pkg/imgpkg/imagetar"
 	ctlimg "github.com/vmware-tanzu/carvel-imgpkg/pkg/imgpkg/registry"
+	"github.com/vmware-tanzu/carvel-imgpkg/pkg/imgpkg/signature"
 )
 
 type registry struct {
@@ -140,12 +145,25 @@ func getAllFilesContentFromImage(image regv1.Image) (map[string][]byte, error) {
 		return nil, err
 	}
 
+	// Download the layers concurrently to speed up the download of large images
+	layerFiles := make([]map[string][]byte, len(layers))
+	var eg errgroup.Group
+	eg.SetLimit(GetDownloadConcurrency())
+	for i := range layers {
+		i := i
+		eg.Go(func() error {
+			var err error
+			layerFiles[i], err = getFilesFromLayer(layers[i])
+			return err
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
 	var files map[string][]byte
-	for _, imgLayer := range layers {
-		files, err = getFilesFromLayer(imgLayer)
-		if err != nil {
-			return nil, err
-		}
+	if len(layerFiles) != 0 {
+		files = layerFiles[len(layerFiles)-1]
 	}
 	if len(files) != 0 {
 		return files, nil
@@ -174,11 +192,23 @@ func (r *registry) DownloadImage(imageName, outputDir string) error {
 // CopyImageToTar downloads the image as tar file
 // This is equivalent to `imgpkg copy --image <image> --to-tar <tar-file-path>` command
 func (r *registry) CopyImageToTar(sourceImageName, destTarFile string) error {
+	// imgpkg only preserves the tag of the images copied by tag. The images pinned
+	// to a digest with a tag are thus copied by digest and saved with their tag.
+	if i := strings.Index(sourceImageName, "@"); i >= 0 {
+		if tag, err := regname.NewTag(sourceImageName[:i], regname.WeakValidation); err == nil {
+			digestRef := tag.Context().Digest(sourceImageName[i+1:]).String()
+			if isBundle, _ := bundle.NewBundle(digestRef, r.registry).IsBundle(); !isBundle {
+				return r.copyImageToTarWithTag(digestRef, tag.TagStr(), destTarFile)
+			}
+			sourceImageName = digestRef
+		}
+	}
+
 	// Creating a dummy writer to capture the logs
 	writerUI := ui.NewWriterUI(&writer{}, &writer{}, nil)
 
 	copyOptions := cmd.NewCopyOptions(ui.NewWrappingConfUI(writerUI, nil))
-	copyOptions.Concurrency = 3
+	copyOptions.Concurrency = GetDownloadConcurrency()
 	copyOptions.SignatureFlags = cmd.SignatureFlags{CopyCosignSignatures: true}
 	isBundle, _ := bundle.NewBundle(sourceImageName, r.registry).IsBundle()
 	if isBundle {
@@ -204,6 +234,29 @@ func (r *registry) CopyImageToTar(sourceImageName, destTarFile string) error {
 	return nil
 }
 
+// copyImageToTarWithTag downloads the image referred to by digest as tar file, along with its
+// cosign signatures as the `imgpkg copy` command does. The image is saved with the tag so that it
+// is tagged with it when published from the tar file.
+func (r *registry) copyImageToTarWithTag(digestRef, tag, destTarFile string) error {
+	logger := &writer{}
+	concurrency := GetDownloadConcurrency()
+
+	imageRefs := ctlimgset.NewUnprocessedImageRefs()
+	imageRefs.Add(ctlimgset.UnprocessedImageRef{DigestRef: digestRef, Tag: tag})
+	signatures, err := signature.NewSignatures(signature.NewCosign(r.registry), concurrency).Fetch(imageRefs)
+	if err != nil {
+		return err
+	}
+	for _, s := range signatures.All() {
+		imageRefs.Add(s)
+	}
+
+	// The tag generator is only used when importing images
+	tarImageSet := ctlimgset.NewTarImageSet(ctlimgset.NewImageSet(concurrency, logger, nil), concurrency, logger)
+	_, err = tarImageSet.Export(imageRefs, destTarFile, r.registry, imagetar.NewImageLayerWriterCheck(false), false)
+	return err
+}
+
 // CopyImageFromTar publishes the image to destination repository from specified tar file
 // This is equivalent to `imgpkg copy --tar <file> --to-repo <dest-repo>` command
 func (r *registry) CopyImageFromTar(sourceTarFile, destImageRepo string) error {
@@ -0,0 +1,295 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+)
+
+// RegistryVendor identifies the product serving a registry when it needs specific handling
+type RegistryVendor string
+
+const (
+	RegistryVendorUnknown     RegistryVendor = ""
+	RegistryVendorArtifactory RegistryVendor = "JFrog Artifactory"
+	RegistryVendorNexus       RegistryVendor = "Sonatype Nexus"
+)
+
+var (
+	// repositoryPathPrefixRegexp matches the requests to the repositories which Artifactory and Nexus
+	// serve under a path prefix, e.g. `/v2/artifactory/api/docker/<repo-key>/<image>/manifests/<tag>`
+	// for an image referenced as `<host>/artifactory/api/docker/<repo-key>/<image>:<tag>`.
+	repositoryPathPrefixRegexp = regexp.MustCompile(`^/v2/((?:artifactory/api/docker|repository)/[^/]+)/(.+)$`)
+	// tokenEndpointPathRegexp matches the token endpoints scoped to a path-prefixed repository
+	tokenEndpointPathRegexp = regexp.MustCompile(`^/((?:artifactory/api/docker|repository)/[^/]+)/v2/token$`)
+	// manifestPathRegexp matches the manifest requests
+	manifestPathRegexp = regexp.MustCompile(`/v2/.+/manifests/[^/]+$`)
+
+	// detectedVendors holds the vendor detected for each registry host
+	detectedVendors sync.Map
+)
+
+// DetectRegistryVendor returns the vendor of the registry based on the headers of one of its responses
+func DetectRegistryVendor(header http.Header) RegistryVendor {
+	server := strings.ToLower(header.Get("Server"))
+	switch {
+	case header.Get("X-Artifactory-Id") != "" || header.Get("X-JFrog-Version") != "" || strings.Contains(server, "artifactory"):
+		return RegistryVendorArtifactory
+	case header.Get("X-Nexus-UI") != "" || strings.Contains(server, "nexus"):
+		return RegistryVendorNexus
+	}
+	return RegistryVendorUnknown
+}
+
+// GetDetectedRegistryVendor returns the vendor detected for the registry host by a
+// transport created with NewCompatibilityTransport, if any
+func GetDetectedRegistryVendor(registryHost string) RegistryVendor {
+	if vendor, ok := detectedVendors.Load(strings.ToLower(registryHost)); ok {
+		return vendor.(RegistryVendor)
+	}
+	return RegistryVendorUnknown
+}
+
+// GetUploadChunkSize returns the maximum size of the requests uploading the image layers
+// configured through the environment variable, 0 meaning that each layer is uploaded at once
+func GetUploadChunkSize() int64 {
+	chunkSize, err := strconv.ParseInt(os.Getenv(constants.ConfigVariableRegistryUploadChunkSize), 10, 64)
+	if err != nil || chunkSize < 0 {
+		return 0
+	}
+	return chunkSize
+}
+
+// compatibilityTransport handles the deviations of JFrog Artifactory and Sonatype Nexus
+// from the OCI distribution specification
+type compatibilityTransport struct {
+	inner     http.RoundTripper
+	chunkSize int64
+}
+
+// NewCompatibilityTransport returns a transport working around the quirks of the registries
+// commonly used in enterprise environments:
+//   - the repositories served under a path prefix (`<host>/artifactory/api/docker/<repo-key>/<image>`
+//     or `<host>/repository/<repo-name>/<image>`) are accessed through their own API endpoint,
+//     and the scopes requested to their token endpoint are relative to the repository
+//   - token endpoints advertised with an `http` realm by registries behind TLS terminating
+//     proxies are accessed with `https`
+//   - the layers are uploaded in chunks of at most TANZU_CLI_REGISTRY_UPLOAD_CHUNK_SIZE bytes
+//   - the manifests are fetched with GET when the HEAD requests are rejected or incomplete
+//
+// The vendor of each registry is also detected to provide targeted diagnostics.
+func NewCompatibilityTransport(inner http.RoundTripper) http.RoundTripper {
+	return &compatibilityTransport{inner: inner, chunkSize: GetUploadChunkSize()}
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (t *compatibilityTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = rewriteRepositoryPathPrefix(req)
+
+	var resp *http.Response
+	var err error
+	// A zero content length with a body means that the length is unknown
+	if req.Method == http.MethodPatch && req.Body != nil && req.Body != http.NoBody && t.chunkSize > 0 &&
+		(req.ContentLength <= 0 || req.ContentLength > t.chunkSize) {
+		resp, err = t.uploadInChunks(req)
+	} else {
+		resp, err = t.inner.RoundTrip(req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	vendor := DetectRegistryVendor(resp.Header)
+	if vendor != RegistryVendorUnknown {
+		detectedVendors.Store(strings.ToLower(req.URL.Host), vendor)
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		fixTokenRealmScheme(req, resp)
+	}
+	if req.Method == http.MethodHead && manifestPathRegexp.MatchString(req.URL.Path) && needsManifestGetFallback(resp, vendor) {
+		return t.headWithGet(req, resp)
+	}
+	return resp, nil
+}
+
+// rewriteRepositoryPathPrefix returns the request to send to access a path-prefixed repository,
+// the API of these repositories being served under their prefix instead of the root of the host
+func rewriteRepositoryPathPrefix(req *http.Request) *http.Request {
+	if matches := repositoryPathPrefixRegexp.FindStringSubmatch(req.URL.Path); matches != nil {
+		req = req.Clone(req.Context())
+		req.URL.Path = fmt.Sprintf("/%s/v2/%s", matches[1], matches[2])
+		req.URL.RawPath = ""
+		return req
+	}
+
+	matches := tokenEndpointPathRegexp.FindStringSubmatch(req.URL.Path)
+	if matches == nil || req.Method != http.MethodGet {
+		return req
+	}
+	query := req.URL.Query()
+	scopes := query["scope"]
+	for i, scope := range scopes {
+		scopes[i] = strings.Replace(scope, "repository:"+matches[1]+"/", "repository:", 1)
+	}
+	req = req.Clone(req.Context())
+	req.URL.RawQuery = query.Encode()
+	return req
+}
+
+// fixTokenRealmScheme upgrades the scheme of the token endpoint advertised with `http` by a
+// registry accessed with `https`, which happens when the registry is behind a TLS terminating proxy
+func fixTokenRealmScheme(req *http.Request, resp *http.Response) {
+	if req.URL.Scheme != "https" {
+		return
+	}
+	challenges := resp.Header.Values("WWW-Authenticate")
+	for i, challenge := range challenges {
+		challenges[i] = strings.Replace(challenge, `realm="http://`+req.URL.Host+`/`, `realm="https://`+req.URL.Host+`/`, 1)
+	}
+}
+
+// needsManifestGetFallback returns true if the response to a HEAD request of a manifest cannot
+// be used: some registries reject these requests, omit the digest of the manifest, or only serve
+// the manifests of their remote repositories once they are cached, after a GET request
+func needsManifestGetFallback(resp *http.Response, vendor RegistryVendor) bool {
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Header.Get("Docker-Content-Digest") == "" || resp.ContentLength < 0
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented, http.StatusBadRequest:
+		return true
+	case http.StatusNotFound:
+		return vendor != RegistryVendorUnknown
+	}
+	return false
+}
+
+// headWithGet fetches the manifest with a GET request and returns the response the HEAD request
+// should have received. The response of the HEAD request is returned if the GET request fails.
+func (t *compatibilityTransport) headWithGet(headReq *http.Request, headResp *http.Response) (*http.Response, error) {
+	getReq := headReq.Clone(headReq.Context())
+	getReq.Method = http.MethodGet
+	getResp, err := t.inner.RoundTrip(getReq)
+	if err != nil || getResp.StatusCode != http.StatusOK {
+		if err == nil {
+			getResp.Body.Close()
+		}
+		return headResp, nil
+	}
+	defer getResp.Body.Close()
+	manifest, err := io.ReadAll(getResp.Body)
+	if err != nil {
+		return headResp, nil
+	}
+	headResp.Body.Close()
+
+	header := getResp.Header.Clone()
+	if header.Get("Docker-Content-Digest") == "" {
+		header.Set("Docker-Content-Digest", fmt.Sprintf("sha256:%x", sha256.Sum256(manifest)))
+	}
+	header.Set("Content-Length", strconv.Itoa(len(manifest)))
+	return &http.Response{
+		Status:        getResp.Status,
+		StatusCode:    http.StatusOK,
+		Proto:         getResp.Proto,
+		ProtoMajor:    getResp.ProtoMajor,
+		ProtoMinor:    getResp.ProtoMinor,
+		Header:        header,
+		Body:          http.NoBody,
+		ContentLength: int64(len(manifest)),
+		Request:       headReq,
+	}, nil
+}
+
+// uploadInChunks sends the content of the PATCH request in chunks of at most the configured
+// chunk size, following the location returned by the registry after each chunk. The response
+// to the last chunk is returned so that the upload can be committed.
+func (t *compatibilityTransport) uploadInChunks(req *http.Request) (*http.Response, error) {
+	defer req.Body.Close()
+	location := req.URL
+	var offset int64
+	var lastResp *http.Response
+	buf := make([]byte, t.chunkSize)
+	for {
+		n, readErr := io.ReadFull(req.Body, buf)
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			return nil, readErr
+		}
+		if n == 0 && lastResp != nil {
+			return lastResp, nil
+		}
+		if lastResp != nil {
+			lastResp.Body.Close()
+		}
+
+		chunkReq := req.Clone(req.Context())
+		chunkReq.URL = location
+		chunkReq.Body = io.NopCloser(bytes.NewReader(buf[:n]))
+		chunkReq.GetBody = nil
+		chunkReq.ContentLength = int64(n)
+		chunkReq.TransferEncoding = nil
+		if n > 0 {
+			chunkReq.Header.Set("Content-Range", fmt.Sprintf("%d-%d", offset, offset+int64(n)-1))
+		}
+		resp, err := t.inner.RoundTrip(chunkReq)
+		if err != nil {
+			return nil, err
+		}
+		accepted := resp.StatusCode == http.StatusAccepted || resp.StatusCode == http.StatusNoContent
+		if !accepted || int64(n) < t.chunkSize {
+			return resp, nil
+		}
+
+		next, err := location.Parse(resp.Header.Get("Location"))
+		if err != nil || resp.Header.Get("Location") == "" {
+			resp.Body.Close()
+			return nil, errors.Errorf("the registry did not return the location to upload the chunk at offset %d", offset+int64(n))
+		}
+		location = next
+		offset += int64(n)
+		lastResp = resp
+	}
+}
+
+// DiagnoseRegistryError adds hints about the likely cause of a registry error when the registry
+// is served by a product known to require a specific configuration
+func DiagnoseRegistryError(registryHost string, err error) error {
+	var transportErr *transport.Error
+	if err == nil || !errors.As(err, &transportErr) {
+		return err
+	}
+
+	vendor := GetDetectedRegistryVendor(registryHost)
+	var hint string
+	switch {
+	case transportErr.StatusCode == http.StatusRequestEntityTooLarge:
+		hint = fmt.Sprintf("the registry or its proxy rejected the size of the upload, set %s to upload the image layers in smaller chunks", constants.ConfigVariableRegistryUploadChunkSize)
+	case vendor == RegistryVendorArtifactory && (transportErr.StatusCode == http.StatusUnauthorized || transportErr.StatusCode == http.StatusForbidden):
+		hint = "JFrog Artifactory requires an identity token or an API key as password, and the user or the anonymous access must be granted the permissions on the repository"
+	case vendor == RegistryVendorArtifactory && transportErr.StatusCode == http.StatusNotFound:
+		hint = fmt.Sprintf("with JFrog Artifactory, the image must include the repository key as in %q or %q", registryHost+"/<repo-key>/<image>", registryHost+"/artifactory/api/docker/<repo-key>/<image>")
+	case vendor == RegistryVendorNexus && transportErr.StatusCode == http.StatusUnauthorized:
+		hint = "Sonatype Nexus requires the 'Docker Bearer Token Realm' to be active to allow token and anonymous access"
+	case vendor == RegistryVendorNexus && transportErr.StatusCode == http.StatusNotFound:
+		hint = fmt.Sprintf("with Sonatype Nexus, the image must be accessed through the connector port of the docker repository or as %q", registryHost+"/repository/<repo-name>/<image>")
+	case vendor == RegistryVendorNexus && transportErr.StatusCode == http.StatusMethodNotAllowed:
+		hint = "Sonatype Nexus group repositories do not accept pushes, the images must be published to a hosted repository"
+	default:
+		return err
+	}
+	return errors.Wrap(err, hint)
+}
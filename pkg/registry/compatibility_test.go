@@ -0,0 +1,221 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func newTestResponse(req *http.Request, status int, header http.Header, body string) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		StatusCode:    status,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+var _ = Describe("Registry compatibility transport", func() {
+	var (
+		requests []*http.Request
+		bodies   []string
+		respond  func(req *http.Request) *http.Response
+		client   *http.Client
+	)
+
+	BeforeEach(func() {
+		requests = nil
+		bodies = nil
+		respond = func(req *http.Request) *http.Response {
+			return newTestResponse(req, http.StatusOK, nil, "")
+		}
+		client = &http.Client{Transport: NewCompatibilityTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			body := ""
+			if req.Body != nil {
+				b, _ := io.ReadAll(req.Body)
+				body = string(b)
+			}
+			requests = append(requests, req)
+			bodies = append(bodies, body)
+			return respond(req), nil
+		}))}
+	})
+	AfterEach(func() {
+		os.Unsetenv(constants.ConfigVariableRegistryUploadChunkSize)
+	})
+
+	Context("with path-prefixed repositories", func() {
+		It("should send the requests to the API of the Artifactory repository", func() {
+			resp, err := client.Get("https://artifactory.example.com/v2/artifactory/api/docker/tanzu/plugins/central/manifests/v1")
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			Expect(requests[0].URL.Path).To(Equal("/artifactory/api/docker/tanzu/v2/plugins/central/manifests/v1"))
+		})
+		It("should send the requests to the API of the Nexus repository", func() {
+			resp, err := client.Get("https://nexus.example.com/v2/repository/tanzu/plugins/central/tags/list")
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			Expect(requests[0].URL.Path).To(Equal("/repository/tanzu/v2/plugins/central/tags/list"))
+		})
+		It("should request the scopes relative to the repository to its token endpoint", func() {
+			resp, err := client.Get("https://artifactory.example.com/artifactory/api/docker/tanzu/v2/token?scope=repository%3Aartifactory%2Fapi%2Fdocker%2Ftanzu%2Fplugins%2Fcentral%3Apull&service=artifactory")
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			Expect(requests[0].URL.Query().Get("scope")).To(Equal("repository:plugins/central:pull"))
+			Expect(requests[0].URL.Query().Get("service")).To(Equal("artifactory"))
+		})
+		It("should not modify the requests to the other repositories", func() {
+			resp, err := client.Get("https://registry.example.com/v2/tanzu/plugins/central/manifests/v1")
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			Expect(requests[0].URL.Path).To(Equal("/v2/tanzu/plugins/central/manifests/v1"))
+		})
+	})
+
+	Context("with a token endpoint advertised with http", func() {
+		It("should upgrade the scheme of the realm of the registry host", func() {
+			respond = func(req *http.Request) *http.Response {
+				return newTestResponse(req, http.StatusUnauthorized, http.Header{
+					"Www-Authenticate": {`Bearer realm="http://artifactory.example.com/artifactory/api/docker/null/v2/token",service="artifactory"`},
+				}, "")
+			}
+			resp, err := client.Get("https://artifactory.example.com/v2/")
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			Expect(resp.Header.Get("WWW-Authenticate")).To(Equal(`Bearer realm="https://artifactory.example.com/artifactory/api/docker/null/v2/token",service="artifactory"`))
+		})
+	})
+
+	Context("with manifest HEAD requests", func() {
+		const manifest = `{"schemaVersion":2}`
+
+		It("should fetch the manifest when the registry does not return its digest", func() {
+			respond = func(req *http.Request) *http.Response {
+				return newTestResponse(req, http.StatusOK, http.Header{"Content-Type": {"application/vnd.oci.image.manifest.v1+json"}}, manifest)
+			}
+			resp, err := client.Head("https://nexus.example.com/v2/tanzu/central/manifests/v1")
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			Expect(requests).To(HaveLen(2))
+			Expect(requests[1].Method).To(Equal(http.MethodGet))
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("Docker-Content-Digest")).To(Equal(fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(manifest)))))
+			Expect(resp.Header.Get("Content-Type")).To(Equal("application/vnd.oci.image.manifest.v1+json"))
+			Expect(resp.ContentLength).To(Equal(int64(len(manifest))))
+		})
+		It("should fetch the manifest when Artifactory does not find it", func() {
+			respond = func(req *http.Request) *http.Response {
+				if req.Method == http.MethodHead {
+					return newTestResponse(req, http.StatusNotFound, http.Header{"X-Artifactory-Id": {"id"}}, "")
+				}
+				return newTestResponse(req, http.StatusOK, http.Header{"Docker-Content-Digest": {"sha256:1234"}}, manifest)
+			}
+			resp, err := client.Head("https://artifactory.example.com/v2/tanzu/central/manifests/v1")
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("Docker-Content-Digest")).To(Equal("sha256:1234"))
+			Expect(GetDetectedRegistryVendor("artifactory.example.com")).To(Equal(RegistryVendorArtifactory))
+		})
+		It("should return the response of the HEAD request if the manifest cannot be fetched", func() {
+			respond = func(req *http.Request) *http.Response {
+				return newTestResponse(req, http.StatusMethodNotAllowed, nil, "")
+			}
+			resp, err := client.Head("https://registry.example.com/v2/tanzu/central/manifests/v1")
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			Expect(requests).To(HaveLen(2))
+			Expect(resp.StatusCode).To(Equal(http.StatusMethodNotAllowed))
+		})
+		It("should not fetch the manifest of other registries which do not find it", func() {
+			respond = func(req *http.Request) *http.Response {
+				return newTestResponse(req, http.StatusNotFound, nil, "")
+			}
+			resp, err := client.Head("https://registry.example.com/v2/tanzu/central/manifests/v1")
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			Expect(requests).To(HaveLen(1))
+		})
+	})
+
+	Context("with an upload chunk size", func() {
+		It("should upload the layer in chunks", func() {
+			os.Setenv(constants.ConfigVariableRegistryUploadChunkSize, "4")
+			client.Transport = NewCompatibilityTransport(client.Transport.(*compatibilityTransport).inner)
+			respond = func(req *http.Request) *http.Response {
+				return newTestResponse(req, http.StatusAccepted, http.Header{"Location": {fmt.Sprintf("/v2/tanzu/central/blobs/uploads/id?chunk=%d", len(requests))}}, "")
+			}
+			req, err := http.NewRequest(http.MethodPatch, "https://registry.example.com/v2/tanzu/central/blobs/uploads/id", io.NopCloser(strings.NewReader("0123456789")))
+			Expect(err).ToNot(HaveOccurred())
+			resp, err := client.Do(req)
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+
+			Expect(bodies).To(Equal([]string{"0123", "4567", "89"}))
+			Expect(requests[0].Header.Get("Content-Range")).To(Equal("0-3"))
+			Expect(requests[1].Header.Get("Content-Range")).To(Equal("4-7"))
+			Expect(requests[1].URL.String()).To(Equal("https://registry.example.com/v2/tanzu/central/blobs/uploads/id?chunk=1"))
+			Expect(requests[2].Header.Get("Content-Range")).To(Equal("8-9"))
+			Expect(requests[2].ContentLength).To(Equal(int64(2)))
+			Expect(resp.Header.Get("Location")).To(Equal("/v2/tanzu/central/blobs/uploads/id?chunk=3"))
+		})
+		It("should upload the layer at once if it is not larger than the chunk size", func() {
+			os.Setenv(constants.ConfigVariableRegistryUploadChunkSize, "16")
+			client.Transport = NewCompatibilityTransport(client.Transport.(*compatibilityTransport).inner)
+			req, err := http.NewRequest(http.MethodPatch, "https://registry.example.com/v2/tanzu/central/blobs/uploads/id", strings.NewReader("0123456789"))
+			Expect(err).ToNot(HaveOccurred())
+			resp, err := client.Do(req)
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			Expect(bodies).To(Equal([]string{"0123456789"}))
+			Expect(requests[0].Header.Get("Content-Range")).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("DiagnoseRegistryError", func() {
+	It("should add a hint for the errors of the detected registry vendor", func() {
+		detectedVendors.Store("nexus.example.com", RegistryVendorNexus)
+		err := DiagnoseRegistryError("nexus.example.com", errors.Wrap(&transport.Error{StatusCode: http.StatusUnauthorized}, "unable to fetch image"))
+		Expect(err.Error()).To(ContainSubstring("Docker Bearer Token Realm"))
+		var transportErr *transport.Error
+		Expect(errors.As(err, &transportErr)).To(BeTrue())
+	})
+	It("should suggest to configure the chunk size when the upload is too large", func() {
+		err := DiagnoseRegistryError("registry.example.com", &transport.Error{StatusCode: http.StatusRequestEntityTooLarge})
+		Expect(err.Error()).To(ContainSubstring(constants.ConfigVariableRegistryUploadChunkSize))
+	})
+	It("should not modify the errors of other registries", func() {
+		origErr := &transport.Error{StatusCode: http.StatusNotFound}
+		Expect(DiagnoseRegistryError("registry.example.com", origErr)).To(Equal(origErr))
+		Expect(DiagnoseRegistryError("registry.example.com", nil)).ToNot(HaveOccurred())
+	})
+	It("should detect the registry vendor from the response headers", func() {
+		Expect(DetectRegistryVendor(http.Header{"Server": {"Artifactory/7.55.10"}})).To(Equal(RegistryVendorArtifactory))
+		Expect(DetectRegistryVendor(http.Header{"Server": {"Nexus/3.58.1-02 (OSS)"}})).To(Equal(RegistryVendorNexus))
+		Expect(DetectRegistryVendor(http.Header{"Server": {"nginx"}})).To(Equal(RegistryVendorUnknown))
+	})
+})
@@ -0,0 +1,90 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// ConnectionOptions are the TLS and proxy settings of a single invocation used to access the
+// registries, e.g. the registries of an internet-restricted environment which use self-signed
+// certificates or are only reachable through a proxy. They are applied on top of the certificate
+// configuration of the registries set with `tanzu config cert`, and of the proxy environment variables.
+type ConnectionOptions struct {
+	// CACertPaths are the files holding the CA certificates trusted in addition to the system ones
+	CACertPaths []string
+	// SkipCertVerify skips the verification of the TLS certificates of the registries
+	SkipCertVerify bool
+	// HTTPProxy, HTTPSProxy and NoProxy override the HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	// environment variables when set
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
+}
+
+// Validate returns an error if a CA certificate file cannot be read or if a proxy is not a valid URL
+func (o *ConnectionOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+	for _, caCertPath := range o.CACertPaths {
+		if _, err := os.Stat(caCertPath); err != nil {
+			return errors.Wrapf(err, "unable to read the CA certificates file %q", caCertPath)
+		}
+	}
+	for _, proxy := range []string{o.HTTPProxy, o.HTTPSProxy} {
+		if proxy == "" {
+			continue
+		}
+		if u, err := url.Parse(proxy); err != nil || u.Host == "" {
+			return errors.Errorf("invalid proxy URL %q", proxy)
+		}
+	}
+	return nil
+}
+
+// ApplyToCertOptions adds the CA certificates and the verification setting of the connection
+// options to the certificate options of a registry
+func (o *ConnectionOptions) ApplyToCertOptions(certOptions *CertOptions) {
+	if o == nil {
+		return
+	}
+	certOptions.CACertPaths = append(certOptions.CACertPaths, o.CACertPaths...)
+	certOptions.SkipCertVerify = certOptions.SkipCertVerify || o.SkipCertVerify
+}
+
+// ProxyFunc returns the function selecting the proxy of the requests as the http.ProxyFromEnvironment
+// function does, the proxies set in the connection options taking precedence over the environment
+// variables. It returns nil when no proxy is set in the connection options.
+func (o *ConnectionOptions) ProxyFunc() func(*http.Request) (*url.URL, error) {
+	if o == nil || (o.HTTPProxy == "" && o.HTTPSProxy == "" && o.NoProxy == "") {
+		return nil
+	}
+	config := httpproxy.FromEnvironment()
+	if o.HTTPProxy != "" {
+		config.HTTPProxy = o.HTTPProxy
+	}
+	if o.HTTPSProxy != "" {
+		config.HTTPSProxy = o.HTTPSProxy
+	}
+	if o.NoProxy != "" {
+		config.NoProxy = o.NoProxy
+	}
+	proxyFunc := config.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+}
+
+// ApplyToTransport sets the proxy of the connection options to the transport, if any
+func (o *ConnectionOptions) ApplyToTransport(transport *http.Transport) {
+	if proxyFunc := o.ProxyFunc(); proxyFunc != nil {
+		transport.Proxy = proxyFunc
+	}
+}
@@ -5,6 +5,8 @@ package registry
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
 	"fmt"
 	"log"
@@ -12,6 +14,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	regname "github.com/google/go-containerregistry/pkg/name"
@@ -70,6 +73,48 @@ func GetRegistryCertOptions(registryHost string) (*CertOptions, error) {
 	return registryCertOpts, nil
 }
 
+// GetRegistryCertOptionsForImage returns the certificate configuration of the registry hosting the image.
+// All the code paths accessing images should use it so that the configuration set with
+// `tanzu config cert` is honored uniformly.
+func GetRegistryCertOptionsForImage(image string) (*CertOptions, error) {
+	registryName, err := GetRegistryName(image)
+	if err != nil {
+		return nil, err
+	}
+	certOptions, err := GetRegistryCertOptions(registryName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to get the registry certificate configuration")
+	}
+	return certOptions, nil
+}
+
+// NewHTTPTransport returns an http transport honoring the registry certificate options.
+// The CA certificates are added to the system cert pool.
+func NewHTTPTransport(certOptions *CertOptions) (*http.Transport, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	for _, caCertPath := range certOptions.CACertPaths {
+		caCert, err := os.ReadFile(caCertPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed reading CA certificates from '%s'", caCertPath)
+		}
+		if ok := pool.AppendCertsFromPEM(caCert); !ok {
+			return nil, errors.Errorf("failed adding CA certificates from '%s'", caCertPath)
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ForceAttemptHTTP2 = false
+	// #nosec G402
+	transport.TLSClientConfig = &tls.Config{
+		RootCAs:            pool,
+		InsecureSkipVerify: certOptions.SkipCertVerify,
+	}
+	return transport, nil
+}
+
 // updateRegistryCertOptions sets the registry options by taking the custom certificate data configured for registry as input
 func updateRegistryCertOptions(cert *configtypes.Cert, registryCertOpts *CertOptions) error {
 	if cert.SkipCertVerify != "" {
@@ -115,6 +160,49 @@ func GetRegistryName(imageName string) (string, error) {
 	return ref.Context().RegistryStr(), nil
 }
 
+// CheckRegistryAllowed returns an error if the registry host is not part of the registry
+// allowlist configured through the TANZU_CLI_REGISTRY_ALLOWLIST environment variable.
+// All the registries are allowed if no allowlist is configured.
+func CheckRegistryAllowed(registryHost string) error {
+	allowlist := strings.TrimSpace(os.Getenv(constants.ConfigVariableRegistryAllowlist))
+	if allowlist == "" {
+		return nil
+	}
+	host := strings.ToLower(registryHost)
+	for _, entry := range strings.Split(allowlist, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == host || (strings.HasPrefix(entry, "*.") && strings.HasSuffix(host, entry[1:])) {
+			return nil
+		}
+	}
+	return errors.Errorf("access to the registry %q is denied by policy: the registry is not part of the allowlist configured with %q", registryHost, constants.ConfigVariableRegistryAllowlist)
+}
+
+// CheckImageRegistryAllowed returns an error if the registry hosting the image is not allowed
+func CheckImageRegistryAllowed(image string) error {
+	registryHost, err := GetRegistryName(image)
+	if err != nil {
+		return err
+	}
+	return CheckRegistryAllowed(registryHost)
+}
+
+// GetDownloadConcurrency returns the number of image layers to download concurrently,
+// taking into account the value configured through the environment variable
+func GetDownloadConcurrency() int {
+	override := os.Getenv(constants.ConfigVariableRegistryDownloadConcurrency)
+	if override != "" {
+		concurrency, err := strconv.Atoi(override)
+		if err == nil && concurrency > 0 {
+			return concurrency
+		}
+	}
+	return constants.DefaultRegistryDownloadConcurrency
+}
+
 // checkForProxyConfigAndUpdateCert checks if user has configured proxy CA cert data using "PROXY_CA_CERT" environment variable
 // if configured, updates cert data in CertOptions
 func checkForProxyConfigAndUpdateCert(registryCertOpts *CertOptions) error {
@@ -4,6 +4,7 @@ package registry
 
 import (
 	"encoding/base64"
+	"net/http"
 	"os"
 	"testing"
 
@@ -256,3 +257,153 @@ var _ = Describe("GetRegistryName() tests", func() {
 		Expect(name).To(Equal(host))
 	})
 })
+
+var _ = Describe("GetRegistryCertOptionsForImage() tests", func() {
+	var (
+		tanzuConfigFile   *os.File
+		tanzuConfigFileNG *os.File
+		err               error
+	)
+	const testHost = "test.vmware.com:8443"
+
+	BeforeEach(func() {
+		tanzuConfigFile, err = os.CreateTemp("", "config")
+		Expect(err).To(BeNil())
+		os.Setenv("TANZU_CONFIG", tanzuConfigFile.Name())
+
+		tanzuConfigFileNG, err = os.CreateTemp("", "config_ng")
+		Expect(err).To(BeNil())
+		os.Setenv("TANZU_CONFIG_NEXT_GEN", tanzuConfigFileNG.Name())
+
+		err = configlib.SetCert(&configtypes.Cert{Host: testHost, SkipCertVerify: "true", Insecure: "true"})
+		Expect(err).To(BeNil())
+	})
+	AfterEach(func() {
+		os.Unsetenv("TANZU_CONFIG")
+		os.Unsetenv("TANZU_CONFIG_NEXT_GEN")
+		os.RemoveAll(tanzuConfigFile.Name())
+		os.RemoveAll(tanzuConfigFileNG.Name())
+	})
+
+	It("should return the cert options of the registry hosting the image", func() {
+		certOptions, err := GetRegistryCertOptionsForImage(testHost + "/tanzu-cli/plugins/plugin-inventory:latest")
+		Expect(err).To(BeNil())
+		Expect(certOptions.SkipCertVerify).To(BeTrue())
+		Expect(certOptions.Insecure).To(BeTrue())
+	})
+	It("should return the default cert options if the registry has no cert configuration", func() {
+		certOptions, err := GetRegistryCertOptionsForImage("other.vmware.com/tanzu-cli/plugins/plugin-inventory:latest")
+		Expect(err).To(BeNil())
+		Expect(certOptions.SkipCertVerify).To(BeFalse())
+		Expect(certOptions.Insecure).To(BeFalse())
+	})
+	It("should return an error for an invalid image", func() {
+		_, err := GetRegistryCertOptionsForImage("Invalid:Image:Name")
+		Expect(err).NotTo(BeNil())
+	})
+})
+
+var _ = Describe("NewHTTPTransport() tests", func() {
+	It("should honor the skip verify option", func() {
+		transport, err := NewHTTPTransport(&CertOptions{SkipCertVerify: true})
+		Expect(err).To(BeNil())
+		Expect(transport.TLSClientConfig.InsecureSkipVerify).To(BeTrue())
+		Expect(transport.TLSClientConfig.RootCAs).NotTo(BeNil())
+	})
+	It("should return an error if the CA cert cannot be read or parsed", func() {
+		_, err := NewHTTPTransport(&CertOptions{CACertPaths: []string{"/does/not/exist"}})
+		Expect(err).NotTo(BeNil())
+
+		caCertFile, err := os.CreateTemp("", "ca_cert")
+		Expect(err).To(BeNil())
+		defer os.Remove(caCertFile.Name())
+		Expect(os.WriteFile(caCertFile.Name(), []byte("not a certificate"), 0644)).To(Succeed())
+		_, err = NewHTTPTransport(&CertOptions{CACertPaths: []string{caCertFile.Name()}})
+		Expect(err).NotTo(BeNil())
+	})
+})
+
+var _ = Describe("GetDownloadConcurrency() tests", func() {
+	AfterEach(func() {
+		os.Unsetenv(constants.ConfigVariableRegistryDownloadConcurrency)
+	})
+	It("should return the default concurrency when not configured", func() {
+		Expect(GetDownloadConcurrency()).To(Equal(constants.DefaultRegistryDownloadConcurrency))
+	})
+	It("should return the configured concurrency", func() {
+		os.Setenv(constants.ConfigVariableRegistryDownloadConcurrency, "8")
+		Expect(GetDownloadConcurrency()).To(Equal(8))
+	})
+	It("should ignore invalid values", func() {
+		os.Setenv(constants.ConfigVariableRegistryDownloadConcurrency, "0")
+		Expect(GetDownloadConcurrency()).To(Equal(constants.DefaultRegistryDownloadConcurrency))
+		os.Setenv(constants.ConfigVariableRegistryDownloadConcurrency, "invalid")
+		Expect(GetDownloadConcurrency()).To(Equal(constants.DefaultRegistryDownloadConcurrency))
+	})
+})
+
+var _ = Describe("CheckRegistryAllowed() tests", func() {
+	AfterEach(func() {
+		os.Unsetenv(constants.ConfigVariableRegistryAllowlist)
+	})
+	It("should allow all the registries when no allowlist is configured", func() {
+		Expect(CheckRegistryAllowed("any.registry.io")).To(Succeed())
+	})
+	It("should only allow the registries of the allowlist", func() {
+		os.Setenv(constants.ConfigVariableRegistryAllowlist, "projects.registry.vmware.com, localhost:5000 ,*.example.com")
+		Expect(CheckRegistryAllowed("projects.registry.vmware.com")).To(Succeed())
+		Expect(CheckRegistryAllowed("Projects.Registry.VMware.com")).To(Succeed())
+		Expect(CheckRegistryAllowed("localhost:5000")).To(Succeed())
+		Expect(CheckRegistryAllowed("harbor.example.com")).To(Succeed())
+
+		err := CheckRegistryAllowed("localhost:6000")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("denied by policy"))
+		Expect(CheckRegistryAllowed("example.com")).NotTo(Succeed())
+		Expect(CheckRegistryAllowed("evil-example.com")).NotTo(Succeed())
+	})
+	It("should check the registry hosting the image", func() {
+		os.Setenv(constants.ConfigVariableRegistryAllowlist, "localhost:5000")
+		Expect(CheckImageRegistryAllowed("localhost:5000/tanzu-cli/plugins/plugin-inventory:latest")).To(Succeed())
+		Expect(CheckImageRegistryAllowed("other.registry.io/tanzu-cli/plugins/plugin-inventory:latest")).NotTo(Succeed())
+	})
+})
+
+var _ = Describe("ConnectionOptions tests", func() {
+	It("should add the CA certificates and skip verify option to the cert options", func() {
+		certOptions := &CertOptions{CACertPaths: []string{"/config/registry.crt"}}
+		(&ConnectionOptions{CACertPaths: []string{"/tmp/ca.crt"}, SkipCertVerify: true}).ApplyToCertOptions(certOptions)
+		Expect(certOptions.CACertPaths).To(Equal([]string{"/config/registry.crt", "/tmp/ca.crt"}))
+		Expect(certOptions.SkipCertVerify).To(BeTrue())
+
+		// The settings of the registry are kept without connection options
+		var noOptions *ConnectionOptions
+		noOptions.ApplyToCertOptions(certOptions)
+		Expect(certOptions.CACertPaths).To(HaveLen(2))
+		Expect(noOptions.ProxyFunc()).To(BeNil())
+	})
+	It("should select the proxy of the connection options, except for the hosts without proxy", func() {
+		options := &ConnectionOptions{HTTPSProxy: "http://proxy.example.com:3128", NoProxy: "registry.internal"}
+		Expect(options.Validate()).To(Succeed())
+		transport := &http.Transport{}
+		options.ApplyToTransport(transport)
+
+		req, err := http.NewRequest(http.MethodGet, "https://registry.example.com/v2/", http.NoBody)
+		Expect(err).NotTo(HaveOccurred())
+		proxyURL, err := transport.Proxy(req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(proxyURL.String()).To(Equal("http://proxy.example.com:3128"))
+
+		req, err = http.NewRequest(http.MethodGet, "https://registry.internal/v2/", http.NoBody)
+		Expect(err).NotTo(HaveOccurred())
+		proxyURL, err = transport.Proxy(req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(proxyURL).To(BeNil())
+	})
+	It("should return an error for invalid options", func() {
+		Expect((&ConnectionOptions{CACertPaths: []string{"/does/not/exist.crt"}}).Validate()).NotTo(Succeed())
+		err := (&ConnectionOptions{HTTPSProxy: "not a url"}).Validate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid proxy URL"))
+	})
+})
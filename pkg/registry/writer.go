@@ -12,3 +12,8 @@ func (w *writer) Write(p []byte) (n int, err error) {
 	log.Info(string(p))
 	return len(p), nil
 }
+
+// Logf passes the log received to the log.Infof
+func (w *writer) Logf(format string, args ...interface{}) {
+	log.Infof(format, args...)
+}
@@ -0,0 +1,64 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package supportbundle
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// RedactedValue is the value used to replace secrets in the support bundle
+const RedactedValue = "<REDACTED>"
+
+// sensitiveKeyMarkers are the (lower-case) substrings identifying keys holding secrets
+var sensitiveKeyMarkers = []string{
+	"token",
+	"password",
+	"secret",
+	"apikey",
+	"credential",
+	"privatekey",
+}
+
+// RedactSecrets replaces the values of all the keys of the provided YAML document
+// that may hold secrets (tokens, passwords, ...) with RedactedValue
+func RedactSecrets(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Wrap(err, "unable to parse the document to redact")
+	}
+	redactNode(&doc)
+	return yaml.Marshal(&doc)
+}
+
+func redactNode(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if isSensitiveKey(key.Value) && value.Kind == yaml.ScalarNode && value.Value != "" {
+				value.Value = RedactedValue
+				value.Tag = "!!str"
+				value.Style = 0
+				continue
+			}
+			redactNode(value)
+		}
+		return
+	}
+	for _, child := range node.Content {
+		redactNode(child)
+	}
+}
+
+func isSensitiveKey(key string) bool {
+	k := strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+	for _, marker := range sensitiveKeyMarkers {
+		if strings.Contains(k, marker) {
+			return true
+		}
+	}
+	return false
+}
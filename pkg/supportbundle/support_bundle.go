@@ -0,0 +1,204 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package supportbundle implements the collection of the local CLI state
+// (catalog, configuration, command tree cache, logs) into a single archive
+// that can be shared with support to troubleshoot plugin issues.
+package supportbundle
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rogpeppe/go-internal/lockedfile"
+	"github.com/verybluebot/tarinator-go"
+	"gopkg.in/yaml.v3"
+
+	configlib "github.com/vmware-tanzu/tanzu-plugin-runtime/config"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/buildinfo"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/catalog"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/common"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/plugincmdtree"
+	"github.com/vmware-tanzu/tanzu-cli/pkg/utils"
+)
+
+const (
+	// SupportBundleDirName is the name of the top-level directory within the support bundle archive
+	SupportBundleDirName = "tanzu_support_bundle"
+
+	bundleInfoFileName  = "bundle_info.yaml"
+	catalogFileName     = "catalog.yaml"
+	configFileName      = "config.yaml"
+	commandTreeFileName = "command_tree.yaml"
+	logsDirName         = "logs"
+	logFileExtension    = ".log"
+
+	// DefaultLogsSince is the default age of the log files included in the support bundle
+	DefaultLogsSince = 72 * time.Hour
+)
+
+// BundleInfo describes the environment the support bundle was collected from
+// and the files it contains
+type BundleInfo struct {
+	CLIVersion  string    `yaml:"cliVersion"`
+	CLISHA      string    `yaml:"cliSHA"`
+	OS          string    `yaml:"os"`
+	Arch        string    `yaml:"arch"`
+	CreatedAt   time.Time `yaml:"createdAt"`
+	Files       []string  `yaml:"files"`
+	Unavailable []string  `yaml:"unavailable,omitempty"`
+}
+
+// CreateSupportBundleOptions defines options for creating a support bundle
+type CreateSupportBundleOptions struct {
+	// ToTar is the path of the archive file to create
+	ToTar string
+	// LogsSince limits the log files included in the bundle to those
+	// modified within the specified duration
+	LogsSince time.Duration
+	// LogDirs are the directories searched for log files. Defaults to the CLI cache directory.
+	LogDirs []string
+}
+
+// CreateSupportBundle collects the plugin catalog, the sanitized CLI configuration,
+// the plugin command tree cache and recent log files and saves them as a tar file
+func (o *CreateSupportBundleOptions) CreateSupportBundle() error {
+	if o.ToTar == "" {
+		return errors.New("the path of the support bundle file must be specified")
+	}
+	if utils.PathExists(o.ToTar) {
+		return errors.Errorf("the file '%s' already exists", o.ToTar)
+	}
+
+	tempBaseDir, err := os.MkdirTemp("", "")
+	if err != nil {
+		return errors.Wrap(err, "unable to create temp directory")
+	}
+	defer os.RemoveAll(tempBaseDir)
+
+	bundleDir := filepath.Join(tempBaseDir, SupportBundleDirName)
+	if err := os.Mkdir(bundleDir, os.ModePerm); err != nil {
+		return errors.Wrap(err, "unable to create temp directory")
+	}
+
+	info := &BundleInfo{
+		CLIVersion: buildinfo.Version,
+		CLISHA:     buildinfo.SHA,
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	collectors := []struct {
+		fileName string
+		collect  func() ([]byte, error)
+	}{
+		{catalogFileName, collectCatalog},
+		{configFileName, collectConfig},
+		{commandTreeFileName, collectCommandTree},
+	}
+	for _, c := range collectors {
+		data, err := c.collect()
+		if err != nil {
+			info.Unavailable = append(info.Unavailable, fmt.Sprintf("%s: %v", c.fileName, err))
+			continue
+		}
+		if err := utils.SaveFile(filepath.Join(bundleDir, c.fileName), data); err != nil {
+			return err
+		}
+		info.Files = append(info.Files, c.fileName)
+	}
+
+	logFiles, err := o.collectLogs(filepath.Join(bundleDir, logsDirName))
+	if err != nil {
+		return err
+	}
+	info.Files = append(info.Files, logFiles...)
+
+	b, err := yaml.Marshal(info)
+	if err != nil {
+		return errors.Wrap(err, "error while marshaling the support bundle information")
+	}
+	if err := utils.SaveFile(filepath.Join(bundleDir, bundleInfoFileName), b); err != nil {
+		return err
+	}
+
+	if err := tarinator.Tarinate([]string{bundleDir}, o.ToTar); err != nil {
+		return errors.Wrap(err, "error while creating archive file")
+	}
+	return nil
+}
+
+// collectCatalog returns the content of the plugin catalog cache file
+func collectCatalog() ([]byte, error) {
+	return lockedfile.Read(catalog.GetCatalogCachePath())
+}
+
+// collectCommandTree returns the content of the plugin command tree cache file
+func collectCommandTree() ([]byte, error) {
+	return os.ReadFile(plugincmdtree.GetPluginsCommandTreeCachePath())
+}
+
+// collectConfig returns the CLI configuration with all secrets redacted
+func collectConfig() ([]byte, error) {
+	cfg, err := configlib.GetClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	b, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return RedactSecrets(b)
+}
+
+// collectLogs copies the recently modified log files into targetDir and
+// returns their paths relative to the bundle directory
+func (o *CreateSupportBundleOptions) collectLogs(targetDir string) ([]string, error) {
+	logDirs := o.LogDirs
+	if len(logDirs) == 0 {
+		logDirs = []string{common.DefaultCacheDir}
+	}
+	since := o.LogsSince
+	if since <= 0 {
+		since = DefaultLogsSince
+	}
+	cutoff := time.Now().Add(-since)
+
+	var collected []string
+	for _, logDir := range logDirs {
+		if !utils.PathExists(logDir) {
+			continue
+		}
+		err := filepath.WalkDir(logDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), logFileExtension) {
+				return nil
+			}
+			fi, err := d.Info()
+			if err != nil || fi.ModTime().Before(cutoff) {
+				return nil
+			}
+			relPath, err := filepath.Rel(logDir, path)
+			if err != nil {
+				return nil
+			}
+			destPath := filepath.Join(targetDir, filepath.Base(logDir), relPath)
+			if err := utils.CopyFile(path, destPath); err != nil {
+				return errors.Wrapf(err, "unable to copy log file %q", path)
+			}
+			collected = append(collected, filepath.Join(logsDirName, filepath.Base(logDir), relPath))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return collected, nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package supportbundle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/verybluebot/tarinator-go"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	assert := assert.New(t)
+
+	input := `
+contexts:
+- name: ctx1
+  globalOpts:
+    endpoint: https://example.com
+    auth:
+      accessToken: abc
+      refresh_token: def
+      type: id-token
+clientOptions:
+  env:
+    MY_PASSWORD: secret-value
+    PROXY_HOST: proxy.example.com
+`
+	out, err := RedactSecrets([]byte(input))
+	assert.Nil(err)
+
+	var result map[string]interface{}
+	assert.Nil(yaml.Unmarshal(out, &result))
+
+	auth := result["contexts"].([]interface{})[0].(map[string]interface{})["globalOpts"].(map[string]interface{})["auth"].(map[string]interface{})
+	assert.Equal(RedactedValue, auth["accessToken"])
+	assert.Equal(RedactedValue, auth["refresh_token"])
+	assert.Equal("id-token", auth["type"])
+
+	env := result["clientOptions"].(map[string]interface{})["env"].(map[string]interface{})
+	assert.Equal(RedactedValue, env["MY_PASSWORD"])
+	assert.Equal("proxy.example.com", env["PROXY_HOST"])
+}
+
+func TestCollectLogs(t *testing.T) {
+	assert := assert.New(t)
+
+	logDir, err := os.MkdirTemp("", "test-logs")
+	assert.Nil(err)
+	defer os.RemoveAll(logDir)
+
+	assert.Nil(os.WriteFile(filepath.Join(logDir, "recent.log"), []byte("recent"), 0600))
+	assert.Nil(os.WriteFile(filepath.Join(logDir, "other.txt"), []byte("other"), 0600))
+	oldLog := filepath.Join(logDir, "old.log")
+	assert.Nil(os.WriteFile(oldLog, []byte("old"), 0600))
+	oldTime := time.Now().Add(-10 * 24 * time.Hour)
+	assert.Nil(os.Chtimes(oldLog, oldTime, oldTime))
+
+	targetDir, err := os.MkdirTemp("", "test-logs-target")
+	assert.Nil(err)
+	defer os.RemoveAll(targetDir)
+
+	o := &CreateSupportBundleOptions{LogDirs: []string{logDir}}
+	files, err := o.collectLogs(targetDir)
+	assert.Nil(err)
+	assert.Equal([]string{filepath.Join(logsDirName, filepath.Base(logDir), "recent.log")}, files)
+	assert.FileExists(filepath.Join(targetDir, filepath.Base(logDir), "recent.log"))
+}
+
+func TestCreateSupportBundle(t *testing.T) {
+	assert := assert.New(t)
+
+	cacheDir, err := os.MkdirTemp("", "test-support-bundle-cache")
+	assert.Nil(err)
+	defer os.RemoveAll(cacheDir)
+	t.Setenv("TEST_CUSTOM_CATALOG_CACHE_DIR", cacheDir)
+	t.Setenv("TEST_CUSTOM_PLUGIN_COMMAND_TREE_CACHE_DIR", cacheDir)
+	assert.Nil(os.WriteFile(filepath.Join(cacheDir, "catalog.yaml"), []byte("indexByPath: {}\n"), 0600))
+
+	configFile, err := os.CreateTemp("", "config")
+	assert.Nil(err)
+	defer os.Remove(configFile.Name())
+	t.Setenv("TANZU_CONFIG", configFile.Name())
+	configFileNG, err := os.CreateTemp("", "config_ng")
+	assert.Nil(err)
+	defer os.Remove(configFileNG.Name())
+	t.Setenv("TANZU_CONFIG_NEXT_GEN", configFileNG.Name())
+
+	tmpDir, err := os.MkdirTemp("", "test-support-bundle")
+	assert.Nil(err)
+	defer os.RemoveAll(tmpDir)
+
+	o := &CreateSupportBundleOptions{
+		ToTar:   filepath.Join(tmpDir, "bundle.tar.gz"),
+		LogDirs: []string{cacheDir},
+	}
+	assert.Nil(o.CreateSupportBundle())
+	assert.NotNil(o.CreateSupportBundle(), "an existing bundle file should not be overwritten")
+
+	extractDir := filepath.Join(tmpDir, "extract")
+	assert.Nil(tarinator.UnTarinate(extractDir, o.ToTar))
+	assert.FileExists(filepath.Join(extractDir, SupportBundleDirName, catalogFileName))
+
+	b, err := os.ReadFile(filepath.Join(extractDir, SupportBundleDirName, bundleInfoFileName))
+	assert.Nil(err)
+	var info BundleInfo
+	assert.Nil(yaml.Unmarshal(b, &info))
+	assert.Contains(info.Files, catalogFileName)
+}
@@ -50,6 +50,8 @@ type MetricsHandler interface {
 	SaveMetrics() error
 	// SendMetrics sends the metrics to the destination(metrics data lake)
 	SendMetrics(ctx context.Context, timeoutInSecs int) error
+	// ExportMetrics exports the metrics of the command to the OTLP endpoint configured by the user, if any
+	ExportMetrics(ctx context.Context) error
 }
 
 type telemetryClient struct {
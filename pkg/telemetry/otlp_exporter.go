@@ -0,0 +1,338 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+)
+
+const (
+	otlpExportTimeout = 2 * time.Second
+	otlpTracesPath    = "/v1/traces"
+	otlpMetricsPath   = "/v1/metrics"
+	otlpServiceName   = "tanzu-cli"
+	otlpScopeName     = "github.com/vmware-tanzu/tanzu-cli/pkg/telemetry"
+
+	// Values of the OTLP enums, see https://github.com/open-telemetry/opentelemetry-proto
+	otlpSpanKindInternal             = 1
+	otlpStatusCodeOk                 = 1
+	otlpStatusCodeError              = 2
+	otlpAggregationTemporalityDelta  = 1
+	otlpCommandDurationMetricName    = "tanzu.cli.command.duration"
+	otlpCommandInvocationsMetricName = "tanzu.cli.command.invocations"
+)
+
+// The types below are the OTLP/HTTP JSON encoding of the subset of the OTLP trace and metric
+// messages exported by the CLI. The 64-bit integers are encoded as strings as required by the
+// JSON encoding of the protobuf messages.
+
+type otlpAnyValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpTracesRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpHistogramDataPoint struct {
+	Attributes        []otlpKeyValue `json:"attributes"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	TimeUnixNano      string         `json:"timeUnixNano"`
+	Count             string         `json:"count"`
+	Sum               float64        `json:"sum"`
+	BucketCounts      []string       `json:"bucketCounts"`
+	ExplicitBounds    []float64      `json:"explicitBounds"`
+}
+
+type otlpNumberDataPoint struct {
+	Attributes        []otlpKeyValue `json:"attributes"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	TimeUnixNano      string         `json:"timeUnixNano"`
+	AsInt             string         `json:"asInt"`
+}
+
+type otlpHistogram struct {
+	AggregationTemporality int                      `json:"aggregationTemporality"`
+	DataPoints             []otlpHistogramDataPoint `json:"dataPoints"`
+}
+
+type otlpSum struct {
+	AggregationTemporality int                   `json:"aggregationTemporality"`
+	IsMonotonic            bool                  `json:"isMonotonic"`
+	DataPoints             []otlpNumberDataPoint `json:"dataPoints"`
+}
+
+type otlpMetric struct {
+	Name      string         `json:"name"`
+	Unit      string         `json:"unit"`
+	Histogram *otlpHistogram `json:"histogram,omitempty"`
+	Sum       *otlpSum       `json:"sum,omitempty"`
+}
+
+type otlpScopeMetrics struct {
+	Scope   otlpScope    `json:"scope"`
+	Metrics []otlpMetric `json:"metrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource     otlpResource       `json:"resource"`
+	ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpMetricsRequest struct {
+	ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+// ExportMetrics exports the span and the metrics of the command execution to the OTLP endpoint configured
+// by the user, if any. The export is independent of the CEIP participation, as the data is only sent to the
+// endpoint chosen by the user. The arguments and the flags of the command are not exported.
+func (tc *telemetryClient) ExportMetrics(ctx context.Context) error {
+	endpoint := strings.TrimSuffix(strings.TrimSpace(os.Getenv(constants.TelemetryOTLPEndpoint)), "/")
+	if endpoint == "" || tc.currentOperationMetrics.StartTime.IsZero() || tc.currentOperationMetrics.EndTime.IsZero() {
+		return nil
+	}
+	headers, err := parseOTLPHeaders(os.Getenv(constants.TelemetryOTLPHeaders))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, otlpExportTimeout)
+	defer cancel()
+
+	traces, err := tc.otlpTracesRequest()
+	if err != nil {
+		return err
+	}
+	if err := postOTLPRequest(ctx, endpoint+otlpTracesPath, headers, traces); err != nil {
+		return errors.Wrap(err, "unable to export the command span")
+	}
+	if err := postOTLPRequest(ctx, endpoint+otlpMetricsPath, headers, tc.otlpMetricsRequest()); err != nil {
+		return errors.Wrap(err, "unable to export the command metrics")
+	}
+	return nil
+}
+
+// parseOTLPHeaders parses the headers as a comma separated list of key=value pairs
+// with URL encoded values, the format of the OTEL_EXPORTER_OTLP_HEADERS variable
+func parseOTLPHeaders(value string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		k, v, found := strings.Cut(pair, "=")
+		if !found || strings.TrimSpace(k) == "" {
+			return nil, errors.Errorf("invalid OTLP header %q, it must be specified as 'key=value'", pair)
+		}
+		v, err := url.QueryUnescape(strings.TrimSpace(v))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value of OTLP header %q", k)
+		}
+		headers[strings.TrimSpace(k)] = v
+	}
+	return headers, nil
+}
+
+func postOTLPRequest(ctx context.Context, endpointURL string, headers map[string]string, body interface{}) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpointURL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("%q responded with status %q", endpointURL, resp.Status)
+	}
+	return nil
+}
+
+// otlpResource returns the resource of the exported span and metrics, the CLI instance
+func (tc *telemetryClient) otlpResource() otlpResource {
+	m := tc.currentOperationMetrics
+	return otlpResource{Attributes: []otlpKeyValue{
+		otlpString("service.name", otlpServiceName),
+		otlpString("service.version", m.CliVersion),
+		otlpString("service.instance.id", m.CliID),
+	}}
+}
+
+// otlpCommandAttributes returns the attributes of the command execution
+func (tc *telemetryClient) otlpCommandAttributes() []otlpKeyValue {
+	m := tc.currentOperationMetrics
+	attributes := []otlpKeyValue{
+		otlpString("tanzu.cli.command", m.CommandName),
+		otlpInt("tanzu.cli.exit_code", int64(m.ExitStatus)),
+	}
+	if m.PluginName != "" {
+		attributes = append(attributes,
+			otlpString("tanzu.cli.plugin.name", m.PluginName),
+			otlpString("tanzu.cli.plugin.version", m.PluginVersion),
+			otlpString("tanzu.cli.plugin.target", m.Target))
+	}
+	return attributes
+}
+
+func (tc *telemetryClient) otlpTracesRequest() (*otlpTracesRequest, error) {
+	traceID, err := randomHexID(16)
+	if err != nil {
+		return nil, err
+	}
+	spanID, err := randomHexID(8)
+	if err != nil {
+		return nil, err
+	}
+
+	m := tc.currentOperationMetrics
+	status := otlpStatus{Code: otlpStatusCodeOk}
+	if m.ExitStatus != 0 {
+		status = otlpStatus{Code: otlpStatusCodeError, Message: fmt.Sprintf("exit code %d", m.ExitStatus)}
+	}
+	span := otlpSpan{
+		TraceID:           traceID,
+		SpanID:            spanID,
+		Name:              "tanzu " + m.CommandName,
+		Kind:              otlpSpanKindInternal,
+		StartTimeUnixNano: unixNano(m.StartTime),
+		EndTimeUnixNano:   unixNano(m.EndTime),
+		Attributes:        tc.otlpCommandAttributes(),
+		Status:            status,
+	}
+
+	return &otlpTracesRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: tc.otlpResource(),
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: otlpScopeName, Version: m.CliVersion},
+			Spans: []otlpSpan{span},
+		}},
+	}}}, nil
+}
+
+func (tc *telemetryClient) otlpMetricsRequest() *otlpMetricsRequest {
+	m := tc.currentOperationMetrics
+	attributes := tc.otlpCommandAttributes()
+	durationMs := float64(m.EndTime.Sub(m.StartTime)) / float64(time.Millisecond)
+
+	duration := otlpMetric{Name: otlpCommandDurationMetricName, Unit: "ms"}
+	duration.Histogram = &otlpHistogram{
+		AggregationTemporality: otlpAggregationTemporalityDelta,
+		DataPoints: []otlpHistogramDataPoint{{
+			Attributes:        attributes,
+			StartTimeUnixNano: unixNano(m.StartTime),
+			TimeUnixNano:      unixNano(m.EndTime),
+			Count:             "1",
+			Sum:               durationMs,
+			BucketCounts:      []string{"1"},
+			ExplicitBounds:    []float64{},
+		}},
+	}
+
+	invocations := otlpMetric{Name: otlpCommandInvocationsMetricName, Unit: "1"}
+	invocations.Sum = &otlpSum{
+		AggregationTemporality: otlpAggregationTemporalityDelta,
+		IsMonotonic:            true,
+		DataPoints: []otlpNumberDataPoint{{
+			Attributes:        attributes,
+			StartTimeUnixNano: unixNano(m.StartTime),
+			TimeUnixNano:      unixNano(m.EndTime),
+			AsInt:             "1",
+		}},
+	}
+
+	return &otlpMetricsRequest{ResourceMetrics: []otlpResourceMetrics{{
+		Resource: tc.otlpResource(),
+		ScopeMetrics: []otlpScopeMetrics{{
+			Scope:   otlpScope{Name: otlpScopeName, Version: m.CliVersion},
+			Metrics: []otlpMetric{duration, invocations},
+		}},
+	}}}
+}
+
+func otlpString(key, value string) otlpKeyValue {
+	return otlpKeyValue{Key: key, Value: otlpAnyValue{StringValue: &value}}
+}
+
+func otlpInt(key string, value int64) otlpKeyValue {
+	s := strconv.FormatInt(value, 10)
+	return otlpKeyValue{Key: key, Value: otlpAnyValue{IntValue: &s}}
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// randomHexID returns a random ID of n bytes encoded in hexadecimal, as the trace and span IDs
+func randomHexID(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-cli/pkg/constants"
+)
+
+var _ = Describe("Unit tests for ExportMetrics()", func() {
+	var (
+		tc        *telemetryClient
+		server    *httptest.Server
+		mutex     sync.Mutex
+		requests  map[string][]byte
+		headers   map[string]http.Header
+		status    int
+		startTime time.Time
+	)
+
+	BeforeEach(func() {
+		requests = map[string][]byte{}
+		headers = map[string]http.Header{}
+		status = http.StatusOK
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			mutex.Lock()
+			defer mutex.Unlock()
+			requests[r.URL.Path] = body
+			headers[r.URL.Path] = r.Header
+			w.WriteHeader(status)
+		}))
+		os.Setenv(constants.TelemetryOTLPEndpoint, server.URL+"/")
+
+		startTime = time.Unix(1700000000, 0)
+		tc = &telemetryClient{currentOperationMetrics: &OperationMetricsPayload{
+			CliID:         "fake-cli-id",
+			CliVersion:    "v1.1.0",
+			StartTime:     startTime,
+			EndTime:       startTime.Add(1500 * time.Millisecond),
+			CommandName:   "cluster list",
+			Args:          []string{"secret-arg"},
+			Flags:         `{"secret-flag":"value"}`,
+			ExitStatus:    1,
+			PluginName:    "cluster",
+			PluginVersion: "v0.2.0",
+			Target:        "kubernetes",
+		}}
+	})
+	AfterEach(func() {
+		server.Close()
+		os.Unsetenv(constants.TelemetryOTLPEndpoint)
+		os.Unsetenv(constants.TelemetryOTLPHeaders)
+	})
+
+	Context("when the OTLP endpoint is configured", func() {
+		It("should export the span and the metrics of the command", func() {
+			os.Setenv(constants.TelemetryOTLPHeaders, "Authorization=Bearer%20token, X-Org = my-org")
+
+			err := tc.ExportMetrics(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(headers[otlpTracesPath].Get("Content-Type")).To(Equal("application/json"))
+			Expect(headers[otlpTracesPath].Get("Authorization")).To(Equal("Bearer token"))
+			Expect(headers[otlpMetricsPath].Get("X-Org")).To(Equal("my-org"))
+
+			traces := &otlpTracesRequest{}
+			Expect(json.Unmarshal(requests[otlpTracesPath], traces)).To(Succeed())
+			Expect(traces.ResourceSpans).To(HaveLen(1))
+			Expect(traces.ResourceSpans[0].Resource.Attributes).To(ContainElement(otlpString("service.instance.id", "fake-cli-id")))
+			span := traces.ResourceSpans[0].ScopeSpans[0].Spans[0]
+			Expect(span.Name).To(Equal("tanzu cluster list"))
+			Expect(span.TraceID).To(HaveLen(32))
+			Expect(span.SpanID).To(HaveLen(16))
+			Expect(span.StartTimeUnixNano).To(Equal("1700000000000000000"))
+			Expect(span.EndTimeUnixNano).To(Equal("1700000001500000000"))
+			Expect(span.Status.Code).To(Equal(otlpStatusCodeError))
+			Expect(span.Attributes).To(ContainElements(
+				otlpInt("tanzu.cli.exit_code", 1),
+				otlpString("tanzu.cli.plugin.name", "cluster"),
+				otlpString("tanzu.cli.plugin.version", "v0.2.0"),
+				otlpString("tanzu.cli.plugin.target", "kubernetes"),
+			))
+
+			metrics := &otlpMetricsRequest{}
+			Expect(json.Unmarshal(requests[otlpMetricsPath], metrics)).To(Succeed())
+			exported := metrics.ResourceMetrics[0].ScopeMetrics[0].Metrics
+			Expect(exported).To(HaveLen(2))
+			Expect(exported[0].Name).To(Equal(otlpCommandDurationMetricName))
+			Expect(exported[0].Histogram.DataPoints[0].Sum).To(Equal(1500.0))
+			Expect(exported[0].Histogram.DataPoints[0].Count).To(Equal("1"))
+			Expect(exported[1].Name).To(Equal(otlpCommandInvocationsMetricName))
+			Expect(exported[1].Sum.DataPoints[0].AsInt).To(Equal("1"))
+			Expect(exported[1].Sum.DataPoints[0].Attributes).To(ContainElement(otlpString("tanzu.cli.command", "cluster list")))
+
+			// The arguments and the flags of the command must not be exported
+			Expect(string(requests[otlpTracesPath])).ToNot(ContainSubstring("secret"))
+			Expect(string(requests[otlpMetricsPath])).ToNot(ContainSubstring("secret"))
+		})
+		It("should return an error if the endpoint rejects the data", func() {
+			status = http.StatusUnauthorized
+			err := tc.ExportMetrics(context.Background())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("unable to export the command span"))
+			Expect(err.Error()).To(ContainSubstring("401 Unauthorized"))
+		})
+		It("should return an error if the headers are invalid", func() {
+			os.Setenv(constants.TelemetryOTLPHeaders, "Authorization")
+			err := tc.ExportMetrics(context.Background())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`invalid OTLP header "Authorization", it must be specified as 'key=value'`))
+			Expect(requests).To(BeEmpty())
+		})
+		It("should not export anything if the command metrics were not collected", func() {
+			tc.currentOperationMetrics = &OperationMetricsPayload{}
+			Expect(tc.ExportMetrics(context.Background())).To(Succeed())
+			Expect(requests).To(BeEmpty())
+		})
+	})
+	Context("when the OTLP endpoint is not configured", func() {
+		It("should not export anything", func() {
+			os.Unsetenv(constants.TelemetryOTLPEndpoint)
+			Expect(tc.ExportMetrics(context.Background())).To(Succeed())
+			Expect(requests).To(BeEmpty())
+		})
+	})
+})
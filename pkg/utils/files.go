@@ -4,6 +4,7 @@
 package utils
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -85,3 +86,18 @@ func AppendFile(filePath string, data []byte) error {
 	}
 	return nil
 }
+
+// FormatBytes returns a human-readable representation of the given number of bytes
+// using binary units, e.g. 1536 returns "1.5 KiB"
+func FormatBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
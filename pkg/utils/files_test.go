@@ -39,4 +39,13 @@ var _ = Describe("Unit tests for the files utils", func() {
 			Expect(err).To(BeNil())
 		})
 	})
+
+	Context("Unit tests for formatting bytes", func() {
+		It("formats sizes using binary units", func() {
+			Expect(FormatBytes(0)).To(Equal("0 B"))
+			Expect(FormatBytes(1023)).To(Equal("1023 B"))
+			Expect(FormatBytes(1536)).To(Equal("1.5 KiB"))
+			Expect(FormatBytes(5 * 1024 * 1024)).To(Equal("5.0 MiB"))
+		})
+	})
 })
@@ -0,0 +1,94 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package airgapped
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-cli/test/e2e/framework"
+)
+
+var _ = framework.CLICoreDescribe("[Tests:E2E][Feature:Airgapped-Plugin-Bundle-Local-Central-Repo]", func() {
+	var (
+		sourceRepo      *framework.LocalCentralRepo
+		airgappedRepo   *framework.LocalCentralRepo
+		groupBundleTar  string
+		pluginBundleTar string
+	)
+
+	fooV1 := &framework.PluginInfo{Name: "foo", Target: framework.KubernetesTarget, Version: "v0.0.1"}
+	fooV2 := &framework.PluginInfo{Name: "foo", Target: framework.KubernetesTarget, Version: "v0.0.2"}
+	bar := &framework.PluginInfo{Name: "bar", Target: framework.GlobalTarget, Version: "v0.0.1"}
+
+	Context("Create, inspect and upload plugin bundles with local central repositories", Ordered, func() {
+		BeforeAll(func() {
+			sourceRepo, err = framework.StartLocalCentralRepo(
+				framework.WithLocalCentralRepoTLS(),
+				framework.WithLocalCentralRepoPlugins(fooV1, fooV2, bar),
+				framework.WithLocalCentralRepoPluginGroup("default", "v0.0.1", fooV1, bar),
+			)
+			Expect(err).To(BeNil(), "should not get any error while starting the source central repository")
+			Expect(tf.PluginBundle.ConfigureLocalCentralRepo(sourceRepo)).To(Succeed())
+
+			airgappedRepo, err = framework.StartLocalCentralRepo(framework.WithLocalCentralRepoName("e2e-airgapped-repo"), framework.WithLocalCentralRepoPort("9878"))
+			Expect(err).To(BeNil(), "should not get any error while starting the airgapped repository")
+
+			groupBundleTar = filepath.Join(tempDir, "plugin_bundle_local_vmware-tkg-default-v0.0.1.tar.gz")
+			pluginBundleTar = filepath.Join(tempDir, "plugin_bundle_local_foo-v0.0.2.tar.gz")
+		})
+		AfterAll(func() {
+			if sourceRepo != nil {
+				Expect(sourceRepo.Stop()).To(Succeed())
+			}
+			if airgappedRepo != nil {
+				Expect(airgappedRepo.Stop()).To(Succeed())
+			}
+		})
+
+		// Test case: the plugin bundle of a plugin-group contains the plugin-group and its plugins only
+		It("create plugin bundle with plugin-group vmware-tkg/default:v0.0.1", func() {
+			bundle, err := tf.PluginBundle.CreatePluginBundle(sourceRepo, []string{"vmware-tkg/default:v0.0.1"}, []string{}, groupBundleTar)
+			Expect(err).To(BeNil(), "should not get any error while downloading plugin bundle with specific group")
+
+			Expect(bundle.Manifest.RelativeInventoryImagePathWithTag).To(Equal(framework.LocalCentralRepoInventoryImage))
+			Expect(bundle.Manifest.InventoryMetadataImage.RelativeImagePathWithTag).To(Equal("central-metadata:e2e"))
+			// the inventory image and the images of the 2 plugins for 4 os-arch
+			Expect(bundle.Manifest.ImagesToCopy).To(HaveLen(1 + 2*4))
+			Expect(bundle.InventoryMetadata.PluginGroups).To(ConsistOf("vmware-tkg/default:v0.0.1"))
+			Expect(bundle.InventoryMetadata.Plugins).To(ConsistOf(fooV1, bar))
+		})
+
+		// Test case: the plugin bundle of a plugin version does not contain any plugin-group
+		It("create plugin bundle with plugin foo:v0.0.2", func() {
+			bundle, err := tf.PluginBundle.CreatePluginBundle(sourceRepo, []string{}, []string{"foo@kubernetes:v0.0.2"}, pluginBundleTar)
+			Expect(err).To(BeNil(), "should not get any error while downloading plugin bundle with specific plugin")
+
+			Expect(bundle.Manifest.ImagesToCopy).To(HaveLen(1 + 4))
+			Expect(bundle.InventoryMetadata.PluginGroups).To(BeEmpty())
+			Expect(bundle.InventoryMetadata.Plugins).To(ConsistOf(fooV2))
+
+			inspected, err := tf.PluginBundle.InspectPluginBundle(pluginBundleTar)
+			Expect(err).To(BeNil())
+			Expect(inspected).To(Equal(bundle))
+		})
+
+		// Test case: uploading plugin bundles merges their plugin inventory metadata with the one of the repository
+		It("upload plugin bundles and merge their plugin inventory metadata", func() {
+			Expect(tf.PluginBundle.UploadPluginBundle(airgappedRepo, groupBundleTar)).To(Succeed())
+			metadata, err := tf.PluginBundle.GetInventoryMetadata(airgappedRepo, "central-metadata:e2e")
+			Expect(err).To(BeNil())
+			Expect(metadata.PluginGroups).To(ConsistOf("vmware-tkg/default:v0.0.1"))
+			Expect(metadata.Plugins).To(ConsistOf(fooV1, bar))
+
+			Expect(tf.PluginBundle.UploadPluginBundle(airgappedRepo, pluginBundleTar)).To(Succeed())
+			metadata, err = tf.PluginBundle.GetInventoryMetadata(airgappedRepo, "central-metadata:e2e")
+			Expect(err).To(BeNil())
+			Expect(metadata.PluginGroups).To(ConsistOf("vmware-tkg/default:v0.0.1"))
+			Expect(metadata.Plugins).To(ConsistOf(fooV1, fooV2, bar))
+		})
+	})
+})
@@ -0,0 +1,70 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// benchmark provides the performance benchmarks of the CLI commands
+package benchmark
+
+import (
+	"os"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-cli/test/e2e/framework"
+)
+
+func TestBenchmark(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Benchmark E2E Test Suite")
+}
+
+var (
+	tf            *framework.Framework
+	benchmark     *framework.Benchmark
+	centralRepo   *framework.LocalCentralRepo
+	mockEndpoints *framework.MockTanzuEndpoints
+)
+
+var (
+	fooPlugin = &framework.PluginInfo{Name: "foo", Target: framework.KubernetesTarget, Version: "v0.0.1"}
+	barPlugin = &framework.PluginInfo{Name: "bar", Target: framework.GlobalTarget, Version: "v0.0.1"}
+)
+
+// BeforeSuite starts a local central repository and mock TMC/CSP endpoints, so the benchmarks
+// do not depend on the latency of remote registries and SaaS endpoints
+var _ = BeforeSuite(func() {
+	framework.UseIsolatedHome("benchmark")
+	tf = framework.NewFramework()
+
+	var err error
+	benchmark, err = framework.NewBenchmark()
+	Expect(err).To(BeNil(), "should not get any error while configuring the benchmarks")
+
+	Expect(tf.Config.ConfigInit()).To(Succeed())
+
+	centralRepo, err = framework.StartLocalCentralRepo(
+		framework.WithLocalCentralRepoName("e2e-benchmark-repo"),
+		framework.WithLocalCentralRepoPort("9879"),
+		framework.WithLocalCentralRepoPlugins(fooPlugin, barPlugin),
+	)
+	Expect(err).To(BeNil(), "should not get any error while starting the local central repository")
+	Expect(tf.PluginBundle.ConfigureLocalCentralRepo(centralRepo)).To(Succeed())
+	Expect(framework.UpdatePluginDiscoverySource(tf, centralRepo.URL)).To(Succeed())
+
+	mockEndpoints = framework.StartMockTanzuEndpoints()
+	Expect(mockEndpoints.ConfigureCLI()).To(Succeed())
+})
+
+// AfterSuite writes the results of the benchmarks and stops the local central repository and mock endpoints
+var _ = AfterSuite(func() {
+	if resultsFile := os.Getenv(framework.TanzuCliE2ETestBenchmarkResultsFile); resultsFile != "" && benchmark != nil {
+		Expect(benchmark.WriteResults(resultsFile)).To(Succeed())
+	}
+	if mockEndpoints != nil {
+		mockEndpoints.Stop()
+	}
+	if centralRepo != nil {
+		Expect(centralRepo.Stop()).To(Succeed())
+	}
+})
@@ -0,0 +1,95 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package benchmark
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-cli/test/e2e/framework"
+)
+
+var _ = framework.CLICoreDescribe("[Tests:E2E][Feature:Benchmark]", func() {
+	Context("Benchmark the CLI commands against the local central repository", Ordered, func() {
+		// measure runs the benchmark case and fails if it regressed beyond the threshold
+		measure := func(c framework.BenchmarkCase) {
+			result, err := benchmark.Measure(c)
+			Expect(err).To(BeNil())
+			Expect(benchmark.CheckRegression(result)).To(Succeed())
+		}
+
+		// Test case: startup of the CLI without its cache, e.g. the first command after the installation
+		It("cold startup", func() {
+			measure(framework.BenchmarkCase{
+				Name: "cold startup",
+				Setup: func() error {
+					return os.RemoveAll(filepath.Join(framework.TestHomeDir, ".cache", framework.TanzuFolder))
+				},
+				Run: func() error {
+					_, err := tf.CliOps.CLIVersion()
+					return err
+				},
+			})
+		})
+
+		// Test case: startup of the CLI with its cache
+		It("warm startup", func() {
+			_, err := tf.CliOps.CLIVersion()
+			Expect(err).To(BeNil())
+			measure(framework.BenchmarkCase{
+				Name: "warm startup",
+				Run: func() error {
+					_, err := tf.CliOps.CLIVersion()
+					return err
+				},
+			})
+		})
+
+		// Test case: search the plugins of the central repository
+		It("plugin search", func() {
+			measure(framework.BenchmarkCase{
+				Name: "plugin search",
+				Run: func() error {
+					_, _, _, err := tf.PluginCmd.SearchPlugins("")
+					return err
+				},
+			})
+		})
+
+		// Test case: install a plugin from the central repository
+		It("plugin install", func() {
+			measure(framework.BenchmarkCase{
+				Name: "plugin install",
+				Setup: func() error {
+					return tf.PluginCmd.CleanPlugins()
+				},
+				Run: func() error {
+					_, _, err := tf.PluginCmd.InstallPlugin(fooPlugin.Name, fooPlugin.Target, fooPlugin.Version)
+					return err
+				},
+			})
+		})
+
+		// Test case: switch between two mission-control contexts, which syncs their plugins with the mock TMC endpoint
+		It("context switch", func() {
+			contexts := []string{"benchmark-ctx-1", "benchmark-ctx-2"}
+			for _, name := range contexts {
+				err := tf.ContextCmd.CreateContextWithEndPoint(name, mockEndpoints.URL, framework.AddAdditionalFlagAndValue("--type mission-control"))
+				Expect(err).To(BeNil(), "context should be created without any error")
+			}
+			i := 0
+			measure(framework.BenchmarkCase{
+				Name: "context switch",
+				Run: func() error {
+					i++
+					_, _, err := tf.ContextCmd.UseContext(contexts[i%len(contexts)])
+					return err
+				},
+			})
+		})
+	})
+})
@@ -21,10 +21,13 @@ func TestCatalog(t *testing.T) {
 
 var (
 	e2eTestLocalCentralRepoURL string
+	isolatedHome               *framework.IsolatedHome
 )
 
 // BeforeSuite initializes and set up the environment to execute the catalog updates end-to-end test cases
 var _ = BeforeSuite(func() {
+	// use a dedicated HOME per test node so the suite can run with ginkgo parallel processes
+	isolatedHome = framework.UseIsolatedHome("catalog")
 	tf = framework.NewFramework()
 
 	err := framework.CleanConfigFiles(tf)
@@ -55,3 +58,8 @@ var _ = BeforeSuite(func() {
 	Expect(err).To(BeNil(), "should not get any error for plugin source update")
 
 })
+
+// AfterEach collects the Tanzu CLI config and catalogs of the test node when the spec failed
+var _ = AfterEach(func() {
+	isolatedHome.CollectArtifactsOnFailure()
+})
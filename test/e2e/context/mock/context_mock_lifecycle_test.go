@@ -0,0 +1,65 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package mock
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-cli/test/e2e/framework"
+)
+
+var _ = framework.CLICoreDescribe("[Tests:E2E][Feature:Context-lifecycle-mock-endpoints]", func() {
+	Context("Context lifecycle with mission-control and tanzu types against the mock endpoints", Ordered, func() {
+		const tmcContextName = "ctx-mock-tmc"
+		const tanzuContextName = "ctx-mock-tanzu"
+
+		// Test case: create a mission-control context with the API token accepted by the mock CSP issuer
+		It("create mission-control context", func() {
+			err := tf.ContextCmd.CreateContextWithEndPoint(tmcContextName, mockEndpoints.URL, framework.AddAdditionalFlagAndValue("--type mission-control"))
+			Expect(err).To(BeNil(), "mission-control context should be created without any error")
+
+			ctx, err := tf.ContextCmd.GetContext(tmcContextName)
+			Expect(err).To(BeNil())
+			Expect(ctx.Name).To(Equal(tmcContextName))
+			Expect(mockEndpoints.Requests()).To(ContainElement("POST " + framework.MockCSPIssuerPath + "/auth/api-tokens/authorize"))
+		})
+
+		// Test case: create a tanzu context, which also fetches the name of the CSP organization
+		It("create tanzu context", func() {
+			err := tf.ContextCmd.CreateContextWithEndPoint(tanzuContextName, mockEndpoints.URL, framework.AddAdditionalFlagAndValue("--type tanzu"))
+			Expect(err).To(BeNil(), "tanzu context should be created without any error")
+
+			contexts, _, _, err := tf.ContextCmd.ListContext()
+			Expect(err).To(BeNil())
+			ctxs := framework.ContextInfoToMap(contexts)
+			Expect(ctxs).To(HaveKey(tanzuContextName))
+			Expect(ctxs[tanzuContextName].Additionalmetadata).To(ContainElement(framework.DefaultMockOrgName))
+			Expect(ctxs[tanzuContextName].Kubeconfigpath).NotTo(BeEmpty())
+		})
+
+		// Test case: the context creation fails with an API token not accepted by the mock CSP issuer
+		It("create context with invalid API token", func() {
+			apiToken := os.Getenv(framework.TanzuAPIToken)
+			Expect(os.Setenv(framework.TanzuAPIToken, framework.RandomString(4))).To(Succeed())
+			defer os.Setenv(framework.TanzuAPIToken, apiToken)
+
+			err := tf.ContextCmd.CreateContextWithEndPoint("ctx-mock-invalid-token", mockEndpoints.URL, framework.AddAdditionalFlagAndValue("--type mission-control"))
+			Expect(err).NotTo(BeNil(), "context creation should fail with an invalid API token")
+		})
+
+		// Test case: delete the contexts created against the mock endpoints
+		It("delete contexts", func() {
+			for _, name := range []string{tmcContextName, tanzuContextName} {
+				_, _, err := tf.ContextCmd.DeleteContext(name)
+				Expect(err).To(BeNil(), "context should be deleted without any error")
+			}
+			contexts, _, _, err := tf.ContextCmd.ListContext()
+			Expect(err).To(BeNil())
+			Expect(framework.ContextInfoToMap(contexts)).To(BeEmpty())
+		})
+	})
+})
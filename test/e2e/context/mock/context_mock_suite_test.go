@@ -0,0 +1,46 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// mock provides context command e2e test cases for tmc and tanzu targets against mock endpoints
+package mock
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-cli/test/e2e/framework"
+)
+
+func TestContextMock(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Context Mock Endpoints E2E Test Suite")
+}
+
+var (
+	tf            *framework.Framework
+	mockEndpoints *framework.MockTanzuEndpoints
+	err           error
+)
+
+// BeforeSuite starts the mock TMC/CSP endpoints and configures the CLI to authenticate with them,
+// so the context life cycle can be tested without real SaaS credentials
+var _ = BeforeSuite(func() {
+	tf = framework.NewFramework()
+
+	mockEndpoints = framework.StartMockTanzuEndpoints()
+	Expect(mockEndpoints.ConfigureCLI()).To(Succeed())
+
+	// delete config files
+	err = tf.Config.DeleteCLIConfigurationFiles()
+	Expect(err).To(BeNil())
+	// call init
+	err = tf.Config.ConfigInit()
+	Expect(err).To(BeNil())
+})
+
+// AfterSuite stops the mock endpoints started in BeforeSuite
+var _ = AfterSuite(func() {
+	mockEndpoints.Stop()
+})
@@ -0,0 +1,169 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package framework
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
+)
+
+const (
+	// TanzuCliE2ETestBenchmarkBaselineFile is the environment variable to set the baseline file of the benchmarks,
+	// a json object of the benchmark names and their baseline durations, e.g. {"plugin search": "1.5s"}
+	TanzuCliE2ETestBenchmarkBaselineFile = "TANZU_CLI_E2E_TEST_BENCHMARK_BASELINE_FILE"
+
+	// TanzuCliE2ETestBenchmarkThresholdPercentage is the environment variable to set the percentage by which a
+	// benchmark can exceed its baseline duration before it is reported as a regression; default is 20
+	TanzuCliE2ETestBenchmarkThresholdPercentage = "TANZU_CLI_E2E_TEST_BENCHMARK_THRESHOLD_PERCENTAGE"
+
+	// TanzuCliE2ETestBenchmarkSamples is the environment variable to set the number of times every benchmark
+	// is run; default is 5
+	TanzuCliE2ETestBenchmarkSamples = "TANZU_CLI_E2E_TEST_BENCHMARK_SAMPLES"
+
+	// TanzuCliE2ETestBenchmarkResultsFile is the environment variable to set the file where the results of the
+	// benchmarks are written, in the format of the baseline file
+	TanzuCliE2ETestBenchmarkResultsFile = "TANZU_CLI_E2E_TEST_BENCHMARK_RESULTS_FILE"
+
+	defaultBenchmarkThresholdPercentage = 20
+	defaultBenchmarkSamples             = 5
+)
+
+// BenchmarkCase is an operation to benchmark
+type BenchmarkCase struct {
+	Name  string
+	Setup func() error // Setup is run before every sample and is not measured, e.g. to clean the cache; optional
+	Run   func() error // Run is the measured operation
+}
+
+// BenchmarkResult holds the durations of the samples of a benchmark
+type BenchmarkResult struct {
+	Name    string
+	Samples []time.Duration
+}
+
+// Median returns the median duration of the samples, which is compared with the baseline
+// as it is less sensitive than the mean to the occasional slow sample
+func (r *BenchmarkResult) Median() time.Duration {
+	if len(r.Samples) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration{}, r.Samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2]
+}
+
+// Benchmark runs the benchmark cases and compares their results with the baseline
+type Benchmark struct {
+	// Samples is the number of times every benchmark case is run
+	Samples int
+	// ThresholdPercentage is the percentage by which a benchmark can exceed its baseline duration
+	ThresholdPercentage float64
+
+	baseline map[string]time.Duration
+	results  []*BenchmarkResult
+}
+
+// NewBenchmark returns the Benchmark configured with the TANZU_CLI_E2E_TEST_BENCHMARK_* environment variables
+func NewBenchmark() (*Benchmark, error) {
+	b := &Benchmark{
+		Samples:             defaultBenchmarkSamples,
+		ThresholdPercentage: defaultBenchmarkThresholdPercentage,
+		baseline:            make(map[string]time.Duration),
+	}
+	if samples := os.Getenv(TanzuCliE2ETestBenchmarkSamples); samples != "" {
+		n, err := strconv.Atoi(samples)
+		if err != nil || n <= 0 {
+			return nil, errors.Errorf("invalid value %q for %s, it must be a positive number", samples, TanzuCliE2ETestBenchmarkSamples)
+		}
+		b.Samples = n
+	}
+	if threshold := os.Getenv(TanzuCliE2ETestBenchmarkThresholdPercentage); threshold != "" {
+		pct, err := strconv.ParseFloat(threshold, 64)
+		if err != nil || pct < 0 {
+			return nil, errors.Errorf("invalid value %q for %s, it must be a positive percentage", threshold, TanzuCliE2ETestBenchmarkThresholdPercentage)
+		}
+		b.ThresholdPercentage = pct
+	}
+	if baselineFile := os.Getenv(TanzuCliE2ETestBenchmarkBaselineFile); baselineFile != "" {
+		if err := b.loadBaseline(baselineFile); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func (b *Benchmark) loadBaseline(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return errors.Wrapf(err, "unable to read the benchmark baseline file %q", file)
+	}
+	baseline := make(map[string]string)
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return errors.Wrapf(err, "unable to parse the benchmark baseline file %q", file)
+	}
+	for name, value := range baseline {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return errors.Wrapf(err, "invalid baseline duration of the benchmark %q", name)
+		}
+		b.baseline[name] = d
+	}
+	return nil
+}
+
+// Measure runs the benchmark case Samples times and returns the durations of the runs
+func (b *Benchmark) Measure(c BenchmarkCase) (*BenchmarkResult, error) {
+	result := &BenchmarkResult{Name: c.Name}
+	for i := 0; i < b.Samples; i++ {
+		if c.Setup != nil {
+			if err := c.Setup(); err != nil {
+				return nil, errors.Wrapf(err, "unable to set up the benchmark %q", c.Name)
+			}
+		}
+		start := time.Now()
+		if err := c.Run(); err != nil {
+			return nil, errors.Wrapf(err, "benchmark %q failed", c.Name)
+		}
+		result.Samples = append(result.Samples, time.Since(start))
+	}
+	log.Infof("benchmark %q: median %s, samples %v", c.Name, result.Median(), result.Samples)
+	b.results = append(b.results, result)
+	return result, nil
+}
+
+// CheckRegression returns an error if the median duration of the result exceeds its baseline duration by
+// more than the threshold percentage. The benchmarks without baseline are not checked
+func (b *Benchmark) CheckRegression(result *BenchmarkResult) error {
+	baseline, ok := b.baseline[result.Name]
+	if !ok {
+		log.Infof("benchmark %q has no baseline, skipping the regression check", result.Name)
+		return nil
+	}
+	limit := time.Duration(float64(baseline) * (1 + b.ThresholdPercentage/100))
+	if median := result.Median(); median > limit {
+		return errors.Errorf("benchmark %q regressed: median %s exceeds the baseline %s by more than %.0f%%", result.Name, median, baseline, b.ThresholdPercentage)
+	}
+	return nil
+}
+
+// WriteResults writes the median durations of the benchmarks run so far to the file, in the format of the
+// baseline file, so that it can be used as baseline of the next runs
+func (b *Benchmark) WriteResults(file string) error {
+	results := make(map[string]string)
+	for _, r := range b.results {
+		results[r.Name] = r.Median().Round(time.Millisecond).String()
+	}
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0644)
+}
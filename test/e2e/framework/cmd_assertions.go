@@ -0,0 +1,74 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package framework
+
+import (
+	"reflect"
+	"regexp"
+
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	"github.com/pkg/errors"
+)
+
+// AsCmdError returns the CmdError of the failed command the given error is, or wraps
+func AsCmdError(err error) (*CmdError, bool) {
+	var cmdErr *CmdError
+	if errors.As(err, &cmdErr) {
+		return cmdErr, true
+	}
+	return nil, false
+}
+
+// ExitCodeOf returns the exit code of the failed command the given error is, or wraps;
+// 0 if there is no error, -1 if the error is not a command error
+func ExitCodeOf(err error) int {
+	if err == nil {
+		return 0
+	}
+	if cmdErr, ok := AsCmdError(err); ok {
+		return cmdErr.ExitCode
+	}
+	return -1
+}
+
+// HaveExitCode succeeds if the actual error is returned by a command which exited with the given exit code, e.g.
+//
+//	_, _, err := tf.Exec.TanzuCmdExec("%s plugin install unknown")
+//	Expect(err).To(framework.HaveExitCode(1))
+func HaveExitCode(exitCode int) types.GomegaMatcher {
+	return HaveCmdErrorField("ExitCode", gomega.Equal(exitCode))
+}
+
+// HaveStdErrMatching succeeds if the actual error is returned by a command whose stdErr output matches
+// the given regular expression. Unlike the substring checks, the regular expression is compiled eagerly,
+// so an invalid expression fails the test instead of never matching
+func HaveStdErrMatching(pattern string) types.GomegaMatcher {
+	regexp.MustCompile(pattern)
+	return HaveCmdErrorField("StdErr", gomega.MatchRegexp(pattern))
+}
+
+// HaveCmdErrorField succeeds if the actual error is returned by a command whose CmdError field
+// (Command, Args, StdOut, StdErr, ExitCode or Err) satisfies the given matcher, e.g.
+//
+//	Expect(err).To(framework.HaveCmdErrorField("StdOut", BeEmpty()))
+func HaveCmdErrorField(field string, matcher types.GomegaMatcher) types.GomegaMatcher {
+	return gomega.WithTransform(func(err error) (interface{}, error) {
+		cmdErr, ok := AsCmdError(err)
+		if !ok {
+			return nil, errors.Errorf("expected an error returned by a failed command, got %v", err)
+		}
+		value := reflect.ValueOf(cmdErr).Elem().FieldByName(field)
+		if !value.IsValid() {
+			return nil, errors.Errorf("CmdError has no field %q", field)
+		}
+		return value.Interface(), nil
+	}, matcher)
+}
+
+// FailWith succeeds if the actual error is returned by a command which exited with the given exit code
+// and whose stdErr output matches the given regular expression
+func FailWith(exitCode int, stdErrPattern string) types.GomegaMatcher {
+	return gomega.SatisfyAll(HaveExitCode(exitCode), HaveStdErrMatching(stdErrPattern))
+}
@@ -9,6 +9,8 @@ import (
 	"os/exec"
 	"strings"
 
+	"github.com/pkg/errors"
+
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
 )
 
@@ -78,11 +80,39 @@ func (co *cmdOps) Exec(command string, opts ...E2EOption) (stdOut, stdErr *bytes
 	cmd.Stderr = &stderr
 	err = cmd.Run()
 	if err != nil {
-		return &stdout, &stderr, fmt.Errorf("error while running '%s' (detailed arguments: %q), stdOut: %s, stdErr: %s, err: %s", command, cmdArgs, stdout.String(), stderr.String(), err.Error())
+		return &stdout, &stderr, newCmdError(command, cmdArgs, &stdout, &stderr, err)
 	}
 	return &stdout, &stderr, err
 }
 
+// CmdError is the error returned when a command fails, it holds the exit code and the outputs of the command,
+// so that the tests can assert on them instead of on the error text
+type CmdError struct {
+	Command  string
+	Args     []string
+	StdOut   string
+	StdErr   string
+	ExitCode int // ExitCode of the command; -1 if the command could not be run or was terminated by a signal
+	Err      error
+}
+
+func newCmdError(command string, args []string, stdOut, stdErr *bytes.Buffer, err error) *CmdError {
+	exitCode := -1
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	}
+	return &CmdError{Command: command, Args: args, StdOut: stdOut.String(), StdErr: stdErr.String(), ExitCode: exitCode, Err: err}
+}
+
+func (e *CmdError) Error() string {
+	return fmt.Sprintf("error while running '%s' (detailed arguments: %q), stdOut: %s, stdErr: %s, err: %s", e.Command, e.Args, e.StdOut, e.StdErr, e.Err.Error())
+}
+
+func (e *CmdError) Unwrap() error {
+	return e.Err
+}
+
 // ExecContainsString checks that the given command output contains the string.
 func (co *cmdOps) ExecContainsString(command, contains string, opts ...E2EOption) error {
 	stdOut, _, err := co.Exec(command, opts...)
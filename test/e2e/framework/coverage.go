@@ -0,0 +1,66 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package framework
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// TanzuCliE2ETestCoverageDir is the environment variable to set the directory where the CLI built with
+	// coverage instrumentation writes the coverage data of its invocations during the e2e tests
+	TanzuCliE2ETestCoverageDir = "TANZU_CLI_E2E_TEST_COVERAGE_DIR"
+
+	// goCoverDir is the environment variable read by the binaries built with coverage instrumentation
+	goCoverDir = "GOCOVERDIR"
+)
+
+// CoverageDir returns the directory where the coverage data of the CLI invocations is collected,
+// or an empty string if the coverage is not collected
+func CoverageDir() string {
+	return os.Getenv(goCoverDir)
+}
+
+// enableCoverage sets GOCOVERDIR for all the CLI invocations when the coverage directory is set, so that the
+// CLI built with coverage instrumentation writes the coverage data of every invocation to it. The binaries
+// built without coverage instrumentation ignore it
+func enableCoverage() error {
+	dir := os.Getenv(TanzuCliE2ETestCoverageDir)
+	if dir == "" {
+		return nil
+	}
+	// The tests change the working directory and $HOME, so the directory must be absolute
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	if err := CreateDir(dir); err != nil {
+		return errors.Wrapf(err, "unable to create the coverage directory %q", dir)
+	}
+	return os.Setenv(goCoverDir, dir)
+}
+
+// MergeCoverage merges the coverage data of the CLI invocations collected so far to the given directory
+// and writes it in the text format to the given profile file, e.g. to be processed with 'go tool cover'
+func MergeCoverage(mergedDir, profileFile string) error {
+	dir := CoverageDir()
+	if dir == "" {
+		return errors.Errorf("the coverage is not collected, %s is not set", TanzuCliE2ETestCoverageDir)
+	}
+	if err := CreateDir(mergedDir); err != nil {
+		return err
+	}
+	cmdExe := NewCmdOps()
+	if _, _, err := cmdExe.Exec(fmt.Sprintf("go tool covdata merge -i=%s -o=%s", dir, mergedDir)); err != nil {
+		return errors.Wrap(err, "unable to merge the coverage data")
+	}
+	if _, _, err := cmdExe.Exec(fmt.Sprintf("go tool covdata textfmt -i=%s -o=%s", mergedDir, profileFile)); err != nil {
+		return errors.Wrap(err, "unable to convert the coverage data to the text format")
+	}
+	return nil
+}
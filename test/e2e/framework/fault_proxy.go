@@ -0,0 +1,268 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package framework
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// FaultType is the type of fault injected by the FaultProxy
+type FaultType string
+
+const (
+	// FaultLatency delays the request by the latency of the fault, then forwards it
+	FaultLatency FaultType = "latency"
+	// FaultTimeout holds the request without answering for the latency of the fault, or until the
+	// client gives up, then closes the connection, to simulate an unresponsive registry
+	FaultTimeout FaultType = "timeout"
+	// FaultStatus answers the request with the status code of the fault (default 429 Too Many Requests)
+	FaultStatus FaultType = "status"
+	// FaultDisconnect forwards the request but closes the connection after the first bytes of the
+	// response body, to simulate a download interrupted mid-stream
+	FaultDisconnect FaultType = "disconnect"
+
+	defaultFaultTimeout         = 5 * time.Minute
+	defaultFaultDisconnectBytes = 512
+)
+
+// Fault is a fault injected by the FaultProxy in the requests matching it
+type Fault struct {
+	Type FaultType
+	// PathRegexp selects the requests the fault applies to, e.g. "/blobs/" for the blob downloads; default is all the requests
+	PathRegexp string
+	// Latency of the FaultLatency faults, and duration the FaultTimeout faults hold the requests
+	Latency time.Duration
+	// StatusCode of the FaultStatus faults; default is 429 Too Many Requests
+	StatusCode int
+	// DisconnectAfterBytes is the number of bytes of the response body sent before the FaultDisconnect
+	// faults close the connection; default is 512
+	DisconnectAfterBytes int64
+	// Times is the number of matching requests the fault is injected in, e.g. 1 for a transient fault; 0 means every request
+	Times int
+
+	pathRegexp *regexp.Regexp
+	injected   int
+}
+
+// FaultProxy is a local http reverse proxy between the CLI and a test registry, which injects
+// faults in the requests: latency, timeouts, error status codes and mid-stream disconnects.
+// It is meant to test the retry, backoff and resume behavior of the registry operations
+type FaultProxy struct {
+	// Host of the proxy, e.g. localhost:41235, to use instead of the host of the registry
+	Host string
+
+	target   *url.URL
+	proxy    *httputil.ReverseProxy
+	listener net.Listener
+	server   *http.Server
+	mutex    sync.Mutex
+	faults   []*Fault
+	requests []string
+}
+
+// StartFaultProxy starts the fault proxy in front of the registry at the given host, e.g. localhost:9877,
+// with https when tls is true. The proxy itself serves http, which the CLI uses for localhost registries.
+// It must be stopped with Stop
+func StartFaultProxy(targetHost string, useTLS bool) (*FaultProxy, error) {
+	scheme := "http"
+	if useTLS {
+		scheme = "https"
+	}
+	target := &url.URL{Scheme: scheme, Host: targetHost}
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to listen for the fault proxy")
+	}
+	p := &FaultProxy{
+		Host:     fmt.Sprintf("localhost:%d", listener.Addr().(*net.TCPAddr).Port),
+		target:   target,
+		listener: listener,
+	}
+	p.proxy = httputil.NewSingleHostReverseProxy(target)
+	p.proxy.Transport = &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+	}
+	// The registry redirects and authentication challenges must point to the proxy
+	p.proxy.ModifyResponse = func(resp *http.Response) error {
+		for _, header := range []string{"Location", "Www-Authenticate"} {
+			if value := resp.Header.Get(header); value != "" {
+				resp.Header.Set(header, p.Rewrite(value))
+			}
+		}
+		return nil
+	}
+	p.server = &http.Server{Handler: http.HandlerFunc(p.serve), ReadHeaderTimeout: 30 * time.Second}
+	go func() { _ = p.server.Serve(listener) }()
+	return p, nil
+}
+
+// Stop stops the fault proxy
+func (p *FaultProxy) Stop() error {
+	return p.server.Close()
+}
+
+// Inject adds the fault to the faults injected in the requests. The faults are evaluated in the
+// order they are added, and the first one matching a request is injected
+func (p *FaultProxy) Inject(faults ...*Fault) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for _, f := range faults {
+		if f.PathRegexp != "" {
+			re, err := regexp.Compile(f.PathRegexp)
+			if err != nil {
+				return errors.Wrapf(err, "invalid path regexp of the %s fault", f.Type)
+			}
+			f.pathRegexp = re
+		}
+		p.faults = append(p.faults, f)
+	}
+	return nil
+}
+
+// Reset removes all the faults and the recorded requests
+func (p *FaultProxy) Reset() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.faults = nil
+	p.requests = nil
+}
+
+// Injected returns the number of requests the fault was injected in
+func (p *FaultProxy) Injected(f *Fault) int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return f.injected
+}
+
+// Requests returns the requests received by the proxy, in the "<method> <path>" format
+func (p *FaultProxy) Requests() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]string{}, p.requests...)
+}
+
+// Rewrite replaces the host of the registry with the host of the proxy in the given URL or image reference
+func (p *FaultProxy) Rewrite(s string) string {
+	s = strings.Replace(s, p.target.Scheme+"://"+p.target.Host, "http://"+p.Host, 1)
+	return strings.Replace(s, p.target.Host, p.Host, 1)
+}
+
+// Proxied returns a copy of the local central repository whose Host and URL point to the proxy, e.g. to
+// create plugin bundles through the proxy. The copy must not be stopped, the original repository must be
+func (p *FaultProxy) Proxied(repo *LocalCentralRepo) *LocalCentralRepo {
+	proxied := *repo
+	proxied.Host = p.Host
+	proxied.URL = p.Rewrite(repo.URL)
+	proxied.CACertPath = ""
+	return &proxied
+}
+
+// nextFault returns the fault to inject in the request, if any, and records the request
+func (p *FaultProxy) nextFault(r *http.Request) *Fault {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.requests = append(p.requests, r.Method+" "+r.URL.Path)
+	for _, f := range p.faults {
+		if f.pathRegexp != nil && !f.pathRegexp.MatchString(r.URL.Path) {
+			continue
+		}
+		if f.Times > 0 && f.injected >= f.Times {
+			continue
+		}
+		f.injected++
+		return f
+	}
+	return nil
+}
+
+func (p *FaultProxy) serve(w http.ResponseWriter, r *http.Request) {
+	f := p.nextFault(r)
+	if f == nil {
+		p.proxy.ServeHTTP(w, r)
+		return
+	}
+	switch f.Type {
+	case FaultLatency:
+		time.Sleep(f.Latency)
+		p.proxy.ServeHTTP(w, r)
+	case FaultTimeout:
+		latency := f.Latency
+		if latency == 0 {
+			latency = defaultFaultTimeout
+		}
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+		}
+		closeConnection(w)
+	case FaultStatus:
+		statusCode := f.StatusCode
+		if statusCode == 0 {
+			statusCode = http.StatusTooManyRequests
+		}
+		if statusCode == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "1")
+		}
+		http.Error(w, `{"errors":[{"code":"TOOMANYREQUESTS","message":"injected by the e2e fault proxy"}]}`, statusCode)
+	case FaultDisconnect:
+		limit := f.DisconnectAfterBytes
+		if limit == 0 {
+			limit = defaultFaultDisconnectBytes
+		}
+		p.proxy.ServeHTTP(&disconnectingResponseWriter{ResponseWriter: w, remaining: limit}, r)
+	default:
+		p.proxy.ServeHTTP(w, r)
+	}
+}
+
+// closeConnection closes the connection of the request without any response
+func closeConnection(w http.ResponseWriter) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return
+	}
+	conn, _, err := hijacker.Hijack()
+	if err == nil {
+		conn.Close()
+	}
+}
+
+// disconnectingResponseWriter forwards the response headers and the first bytes of the response body,
+// then closes the connection, so the client gets a truncated body
+type disconnectingResponseWriter struct {
+	http.ResponseWriter
+	remaining    int64
+	disconnected bool
+}
+
+func (w *disconnectingResponseWriter) Write(b []byte) (int, error) {
+	if w.disconnected {
+		return 0, io.ErrClosedPipe
+	}
+	if int64(len(b)) < w.remaining {
+		w.remaining -= int64(len(b))
+		return w.ResponseWriter.Write(b)
+	}
+	n, _ := w.ResponseWriter.Write(b[:w.remaining])
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	w.disconnected = true
+	closeConnection(w.ResponseWriter)
+	return n, io.ErrClosedPipe
+}
@@ -9,6 +9,8 @@ import (
 	"path/filepath"
 
 	"github.com/onsi/ginkgo/v2"
+
+	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
 )
 
 var (
@@ -47,6 +49,8 @@ type Framework struct {
 	PluginCmd    PluginCmdOps    // performs plugin command operations
 	PluginHelper PluginHelperOps // helper (pre-setup) for plugin cmd operations
 	ContextCmd   ContextCmdOps
+	PluginBundle PluginBundleOps // helper for airgapped plugin bundle operations
+	StubPlugins  StubPluginOps   // helper to compile stub plugins
 }
 
 // E2EOptions used to configure certain options to customize the E2E framework
@@ -85,28 +89,23 @@ func NewFramework() *Framework {
 		PluginCmd:    NewPluginLifecycleOps(),
 		PluginHelper: NewPluginOps(NewScriptBasedPlugins(), NewLocalOCIPluginOps(NewLocalOCIRegistry(DefaultRegistryName, DefaultRegistryPort))),
 		ContextCmd:   NewContextCmdOps(),
+		PluginBundle: NewPluginBundleOps(),
+		StubPlugins:  NewStubPluginOps(),
 	}
 }
 
 func init() {
 	OriginalHomeDir = GetHomeDir()
-	TestDirPath = filepath.Join(OriginalHomeDir, TestDir)
-	FullPathForTempDir = filepath.Join(TestDirPath, TempDirInTestDirPath)
 	// Update $HOME as $HOME/.tanzu-cli-e2e
-	os.Setenv("HOME", TestDirPath)
-	TestHomeDir = TestDirPath
-	TestPluginsDirPath = filepath.Join(TestDirPath, TestPluginsDir)
-	TanzuFolderPath = filepath.Join(TestDirPath, ConfigFolder, TanzuFolder)
-	ConfigFilePath = filepath.Join(TanzuFolderPath, ConfigFile)
-	ConfigNGFilePath = filepath.Join(TanzuFolderPath, ConfigNGFile)
+	os.Setenv("HOME", filepath.Join(OriginalHomeDir, TestDir))
+	setTestDirPaths(filepath.Join(OriginalHomeDir, TestDir))
 	TanzuBinary = os.Getenv(TanzuCLIE2ETestBinaryPath)
 	// Set `tanzu` as default binary if not specified tanzu cli binary path
 	if TanzuBinary == "" {
 		TanzuBinary = TanzuPrefix
 	}
-	// Create a directory (if not exists) $HOME/.tanzu-cli-e2e/.config/tanzu-plugins/discovery/standalone
-	TestStandalonePluginsPath = filepath.Join(TestDirPath, ConfigFolder, TanzuPluginsFolder, "discovery", "standalone")
-	_ = CreateDir(TestStandalonePluginsPath)
-	// Create a directory (if not exists) $HOME/.tanzu-cli-e2e/temp
-	_ = CreateDir(FullPathForTempDir)
+	// Collect the coverage data of the CLI invocations if TANZU_CLI_E2E_TEST_COVERAGE_DIR is set
+	if err := enableCoverage(); err != nil {
+		log.Errorf("unable to enable the coverage collection, error:%s", err.Error())
+	}
 }
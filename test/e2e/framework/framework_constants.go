@@ -68,6 +68,11 @@ const (
 	TanzuCliE2ETestAirgappedRepoWithAuthPassword                                    = "TANZU_CLI_E2E_AIRGAPPED_REPO_WITH_AUTH_PASSWORD"
 	TanzuCliPluginDiscoverySignatureVerificationSkipList                            = "TANZU_CLI_PLUGIN_DISCOVERY_IMAGE_SIGNATURE_VERIFICATION_SKIP_LIST"
 
+	// Registry operations
+	TanzuCliRegistryOperationTimeoutSeconds      = "TANZU_CLI_REGISTRY_OPERATION_TIMEOUT_SECONDS"
+	TanzuCliRegistryOperationRetryCount          = "TANZU_CLI_REGISTRY_OPERATION_RETRY_COUNT"
+	TanzuCliRegistryOperationRetryBackoffSeconds = "TANZU_CLI_REGISTRY_OPERATION_RETRY_BACKOFF_SECONDS"
+
 	// CLI Coexistence
 	CLICoexistenceLegacyTanzuCLIInstallationPath = "TANZU_CLI_COEXISTENCE_LEGACY_TANZU_CLI_DIR"
 	CLICoexistenceNewTanzuCLIInstallationPath    = "TANZU_CLI_COEXISTENCE_NEW_TANZU_CLI_DIR"
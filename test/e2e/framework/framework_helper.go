@@ -20,7 +20,6 @@ import (
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
 
-	"github.com/vmware-tanzu/tanzu-plugin-runtime/config/types"
 	"github.com/vmware-tanzu/tanzu-plugin-runtime/log"
 )
 
@@ -149,8 +148,16 @@ func GetHomeDir() string {
 }
 
 // ExecuteCmdAndBuildJSONOutput is generic function to execute given command and build JSON output and return
-// the result, stdOut, stdErr and error
-func ExecuteCmdAndBuildJSONOutput[T PluginInfo | PluginSearch | PluginGroup | PluginGroupGet | PluginSourceInfo | types.ClientConfig | Server | ContextListInfo | CertDetails | PluginDescribe](cmdExe CmdOps, cmd string, opts ...E2EOption) ([]*T, string, string, error) {
+// the result, stdOut, stdErr and error. The output is decoded in strict mode, see RunAndUnmarshal
+func ExecuteCmdAndBuildJSONOutput[T any](cmdExe CmdOps, cmd string, opts ...E2EOption) ([]*T, string, string, error) {
+	return RunAndUnmarshal[[]*T](cmdExe, cmd, opts...)
+}
+
+// RunAndUnmarshal is generic function to execute given command, decode its json or yaml output to the
+// type T and return the result, stdOut, stdErr and error. The output is decoded in strict mode, which
+// fails if the output has fields unknown to T, so any change of the output schema of the command is
+// caught by the tests
+func RunAndUnmarshal[T any](cmdExe CmdOps, cmd string, opts ...E2EOption) (T, string, string, error) {
 	out, stdErr, err := cmdExe.TanzuCmdExec(cmd, opts...)
 	outStr := ""
 	stdErrStr := ""
@@ -161,23 +168,41 @@ func ExecuteCmdAndBuildJSONOutput[T PluginInfo | PluginSearch | PluginGroup | Pl
 		stdErrStr = stdErr.String()
 	}
 
-	var list []*T
+	var result T
 	if outStr != "" {
-		unmarshalErr := json.Unmarshal([]byte(outStr), &list)
+		var unmarshalErr error
+		result, unmarshalErr = UnmarshalStrict[T](outStr)
 		if unmarshalErr != nil {
-			log.Errorf(FailedToConstructJSONNodeFromOutputAndErrInfo, outStr, unmarshalErr.Error())
-			log.Errorf("trying with yaml unmarshal")
-			// try with yaml format unmarshal
-			err2 := yaml.Unmarshal([]byte(outStr), &list)
-			if err2 != nil {
-				return nil, outStr, stdErrStr, errors.Wrapf(unmarshalErr, FailedToConstructJSONNodeFromOutput, outStr)
-			}
+			return result, outStr, stdErrStr, unmarshalErr
 		}
 	}
 	if err != nil {
-		log.Errorf(ErrorLogForCommandWithErrStdErrAndStdOut, cmd, err.Error(), stdErr.String(), out.String())
+		log.Errorf(ErrorLogForCommandWithErrStdErrAndStdOut, cmd, err.Error(), stdErrStr, outStr)
+	}
+	return result, outStr, stdErrStr, err
+}
+
+// UnmarshalStrict decodes the given json or yaml output to the type T, failing if the output
+// has fields unknown to T
+func UnmarshalStrict[T any](output string) (T, error) {
+	var result T
+	decoder := json.NewDecoder(strings.NewReader(output))
+	decoder.DisallowUnknownFields()
+	jsonErr := decoder.Decode(&result)
+	if jsonErr == nil {
+		return result, nil
+	}
+	log.Errorf(FailedToConstructJSONNodeFromOutputAndErrInfo, output, jsonErr.Error())
+	log.Errorf("trying with yaml unmarshal")
+
+	// try with yaml format unmarshal
+	result = *new(T)
+	yamlDecoder := yaml.NewDecoder(strings.NewReader(output))
+	yamlDecoder.KnownFields(true)
+	if yamlErr := yamlDecoder.Decode(&result); yamlErr != nil {
+		return result, errors.Wrapf(jsonErr, FailedToConstructJSONNodeFromOutput, output)
 	}
-	return list, outStr, stdErrStr, err
+	return result, nil
 }
 
 // GetMapKeys takes map[K]any and returns the slice of all map keys
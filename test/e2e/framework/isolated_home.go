@@ -0,0 +1,199 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package framework
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+const (
+	// IsolatedHomesDir is the directory under the original $HOME where the isolated HOME directories are created
+	IsolatedHomesDir = ".tanzu-cli-e2e-nodes"
+
+	// TanzuCLIE2ETestArtifactsDir is the environment variable to set the directory where the Tanzu CLI files
+	// of the isolated HOME directories are collected when a test fails; default is $HOME/.tanzu-cli-e2e-artifacts
+	TanzuCLIE2ETestArtifactsDir = "TANZU_CLI_E2E_TEST_ARTIFACTS_DIR"
+	defaultArtifactsDir         = ".tanzu-cli-e2e-artifacts"
+
+	cacheFolder = ".cache"
+	dataFolder  = ".local/share"
+)
+
+// isolatedHomeEnvVars are the environment variables used by the Tanzu CLI to locate its
+// config, cache and plugin installation directories
+var isolatedHomeEnvVars = []string{"HOME", "XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_DATA_HOME"}
+
+// IsolatedHome is a HOME directory dedicated to a Ginkgo parallel process (test node), which holds
+// its own Tanzu CLI config, catalog cache and plugin installation directories, so that the test
+// nodes running in parallel do not trample each other's config and catalogs
+type IsolatedHome struct {
+	// Dir is the HOME directory of the test node
+	Dir string
+
+	previousEnv  map[string]*string
+	previousPath string
+}
+
+// NewIsolatedHome returns the isolated HOME directory of the current Ginkgo parallel process for the given suite
+func NewIsolatedHome(suiteName string) *IsolatedHome {
+	node := fmt.Sprintf("%s-node-%d", suiteName, ginkgo.GinkgoParallelProcess())
+	return &IsolatedHome{Dir: filepath.Join(OriginalHomeDir, IsolatedHomesDir, node)}
+}
+
+// Activate creates the isolated HOME directory and points $HOME, the XDG directories and the framework
+// paths (config files, temp and test plugins directories) to it
+func (h *IsolatedHome) Activate() error {
+	if h.previousEnv != nil {
+		return nil
+	}
+	if err := os.RemoveAll(h.Dir); err != nil {
+		return errors.Wrapf(err, "unable to clean the isolated home directory %q", h.Dir)
+	}
+	if err := CreateDir(h.Dir); err != nil {
+		return err
+	}
+
+	h.previousEnv = make(map[string]*string)
+	for _, env := range isolatedHomeEnvVars {
+		if value, ok := os.LookupEnv(env); ok {
+			h.previousEnv[env] = &value
+		} else {
+			h.previousEnv[env] = nil
+		}
+	}
+	h.previousPath = TestDirPath
+
+	os.Setenv("HOME", h.Dir)
+	os.Setenv("XDG_CONFIG_HOME", filepath.Join(h.Dir, ConfigFolder))
+	os.Setenv("XDG_CACHE_HOME", filepath.Join(h.Dir, cacheFolder))
+	os.Setenv("XDG_DATA_HOME", filepath.Join(h.Dir, dataFolder))
+	setTestDirPaths(h.Dir)
+	return nil
+}
+
+// CollectArtifacts copies the Tanzu CLI config files and the plugin catalogs of the isolated HOME
+// directory to the given directory, to investigate a test failure
+func (h *IsolatedHome) CollectArtifacts(dir string) error {
+	for _, folder := range []string{ConfigFolder, cacheFolder} {
+		src := filepath.Join(h.Dir, folder)
+		if _, err := os.Stat(src); os.IsNotExist(err) {
+			continue
+		}
+		if err := copyYAMLFiles(src, filepath.Join(dir, folder)); err != nil {
+			return errors.Wrapf(err, "unable to collect the artifacts of %q", src)
+		}
+	}
+	return nil
+}
+
+// Cleanup restores $HOME, the XDG directories and the framework paths and deletes the isolated HOME directory
+func (h *IsolatedHome) Cleanup() error {
+	if h.previousEnv == nil {
+		return nil
+	}
+	for env, value := range h.previousEnv {
+		if value == nil {
+			os.Unsetenv(env)
+		} else {
+			os.Setenv(env, *value)
+		}
+	}
+	setTestDirPaths(h.previousPath)
+	h.previousEnv = nil
+	return os.RemoveAll(h.Dir)
+}
+
+// CollectArtifactsOnFailure collects the Tanzu CLI files of the isolated HOME directory to the artifacts
+// directory when the current spec failed. It is meant to be called from an AfterEach of the suite
+func (h *IsolatedHome) CollectArtifactsOnFailure() {
+	report := ginkgo.CurrentSpecReport()
+	if !report.Failed() {
+		return
+	}
+	dir := filepath.Join(ArtifactsDir(), filepath.Base(h.Dir), fmt.Sprintf("spec-%d", report.LeafNodeLocation.LineNumber))
+	if err := h.CollectArtifacts(dir); err != nil {
+		ginkgo.GinkgoWriter.Printf("unable to collect the artifacts of the isolated home directory: %v\n", err)
+		return
+	}
+	ginkgo.GinkgoWriter.Printf("collected the artifacts of the isolated home directory to %s\n", dir)
+}
+
+// UseIsolatedHome activates the isolated HOME directory of the current Ginkgo parallel process for
+// the suite and registers its cleanup at the end of the suite. It must be called at the beginning of
+// the BeforeSuite of the suite, before any Tanzu CLI file is created
+func UseIsolatedHome(suiteName string) *IsolatedHome {
+	h := NewIsolatedHome(suiteName)
+	gomega.Expect(h.Activate()).To(gomega.Succeed(), "should not get any error while activating the isolated home directory")
+	ginkgo.DeferCleanup(func() {
+		gomega.Expect(h.Cleanup()).To(gomega.Succeed(), "should not get any error while cleaning up the isolated home directory")
+	})
+	return h
+}
+
+// ArtifactsDir returns the directory where the artifacts of the failed tests are collected
+func ArtifactsDir() string {
+	if dir := os.Getenv(TanzuCLIE2ETestArtifactsDir); dir != "" {
+		return dir
+	}
+	return filepath.Join(OriginalHomeDir, defaultArtifactsDir)
+}
+
+// setTestDirPaths points the framework paths to the given test directory and creates its directories
+func setTestDirPaths(testDirPath string) {
+	TestDirPath = testDirPath
+	TestHomeDir = testDirPath
+	FullPathForTempDir = filepath.Join(TestDirPath, TempDirInTestDirPath)
+	TestPluginsDirPath = filepath.Join(TestDirPath, TestPluginsDir)
+	TanzuFolderPath = filepath.Join(TestDirPath, ConfigFolder, TanzuFolder)
+	ConfigFilePath = filepath.Join(TanzuFolderPath, ConfigFile)
+	ConfigNGFilePath = filepath.Join(TanzuFolderPath, ConfigNGFile)
+	// Create a directory (if not exists) $TestDir/.config/tanzu-plugins/discovery/standalone
+	TestStandalonePluginsPath = filepath.Join(TestDirPath, ConfigFolder, TanzuPluginsFolder, "discovery", "standalone")
+	_ = CreateDir(TestStandalonePluginsPath)
+	// Create a directory (if not exists) $TestDir/temp
+	_ = CreateDir(FullPathForTempDir)
+}
+
+// copyYAMLFiles copies the yaml files of the src directory tree to the dst directory
+func copyYAMLFiles(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || (filepath.Ext(path) != ".yaml" && filepath.Ext(path) != ".yml") {
+			return nil
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		return copyFile(path, filepath.Join(dst, rel))
+	})
+}
+
+func copyFile(src, dst string) error {
+	if err := CreateDir(filepath.Dir(dst)); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = io.Copy(out, in)
+	return err
+}
@@ -0,0 +1,502 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package framework
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultLocalCentralRepoName      = "e2e-central-repo"
+	DefaultLocalCentralRepoPort      = "9877"
+	LocalCentralRepoVendor           = "vmware"
+	LocalCentralRepoPublisher        = "tkg"
+	LocalCentralRepoPluginsPath      = "/tanzu-cli/plugins"
+	LocalCentralRepoInventoryImage   = "central:e2e"
+	localCentralRepoStartTimeout     = 30 * time.Second
+	localCentralRepoRegistryImage    = "library/registry:2"
+	localCentralRepoHtpasswdImage    = "httpd:2"
+	localCentralRepoInventoryDBFile  = "plugin_inventory.db"
+	localCentralRepoInventorySchema  = "create_tables.sql"
+	localCentralRepoCertFileName     = "localhost.crt"
+	localCentralRepoCertKeyFileName  = "localhost.key"
+	localCentralRepoHtpasswdFileName = "htpasswd"
+	localCentralRepoStopCmd          = "docker container stop %s"
+)
+
+// localCentralRepoSchema is the schema of the plugin inventory database,
+// it must be kept in sync with pkg/plugininventory/data/sqlite/create_tables.sql
+const localCentralRepoSchema = `CREATE TABLE IF NOT EXISTS "PluginBinaries" (
+		"PluginName"         TEXT NOT NULL,
+		"Target"             TEXT NOT NULL,
+		"RecommendedVersion" TEXT NOT NULL,
+		"Version"            TEXT NOT NULL,
+		"Hidden"             TEXT NOT NULL,
+		"Description"        TEXT NOT NULL,
+		"Publisher"          TEXT NOT NULL,
+		"Vendor"             TEXT NOT NULL,
+		"OS"                 TEXT NOT NULL,
+		"Architecture"       TEXT NOT NULL,
+		"Digest"             TEXT NOT NULL,
+		"URI"                TEXT NOT NULL,
+		PRIMARY KEY("PluginName", "Target", "Version", "OS", "Architecture")
+);
+
+CREATE TABLE IF NOT EXISTS "PluginGroups" (
+		"Vendor"             TEXT NOT NULL,
+		"Publisher"          TEXT NOT NULL,
+		"GroupName"          TEXT NOT NULL,
+		"GroupVersion"       TEXT NOT NULL,
+		"Description"        TEXT NOT NULL,
+		"PluginName"         TEXT NOT NULL,
+		"Target"             TEXT NOT NULL,
+		"PluginVersion"      TEXT NOT NULL,
+		"Mandatory"          TEXT NOT NULL,
+		"Hidden"             TEXT NOT NULL,
+		PRIMARY KEY("Vendor", "Publisher", "GroupName", "GroupVersion", "PluginName", "Target")
+);
+
+CREATE INDEX IF NOT EXISTS "PluginBinariesTargetIndex" ON "PluginBinaries" ("Target", "PluginName");
+
+CREATE INDEX IF NOT EXISTS "PluginGroupsGroupNameIndex" ON "PluginGroups" ("GroupName");
+
+CREATE TABLE IF NOT EXISTS "PluginReleaseNotes" (
+		"PluginName"         TEXT NOT NULL,
+		"Target"             TEXT NOT NULL,
+		"Version"            TEXT NOT NULL,
+		"ChangelogURL"       TEXT NOT NULL,
+		"Notes"              TEXT NOT NULL,
+		PRIMARY KEY("PluginName", "Target", "Version")
+);
+`
+
+// localCentralRepoOSArch are the os-arch for which the stub plugin binaries are published
+var localCentralRepoOSArch = [][2]string{{"darwin", "amd64"}, {"darwin", "arm64"}, {"linux", "amd64"}, {"windows", "amd64"}}
+
+// LocalCentralRepoOptions used to configure the local central repository
+type LocalCentralRepoOptions struct {
+	Name     string        // Name of the registry container; default is DefaultLocalCentralRepoName
+	Port     string        // Port of the registry on localhost; default is DefaultLocalCentralRepoPort
+	TLS      bool          // TLS enables https with a generated self-signed certificate
+	Username string        // Username enables the authentication to the registry when set
+	Password string        // Password of the user
+	Plugins  []*PluginInfo // Plugins to publish as stub plugins, one entry per plugin version
+	Groups   []*LocalCentralRepoPluginGroup
+}
+
+// LocalCentralRepoPluginGroup is a plugin-group version to add to the local central repository
+type LocalCentralRepoPluginGroup struct {
+	Name    string
+	Version string
+	Plugins []*PluginInfo // Plugins of the plugin-group, they must also be published in the repository
+}
+
+type LocalCentralRepoOption func(*LocalCentralRepoOptions)
+
+// WithLocalCentralRepoName is to set the name of the registry container, required to run several registries
+func WithLocalCentralRepoName(name string) LocalCentralRepoOption {
+	return func(opts *LocalCentralRepoOptions) {
+		opts.Name = name
+	}
+}
+
+// WithLocalCentralRepoPort is to set the port of the registry on localhost
+func WithLocalCentralRepoPort(port string) LocalCentralRepoOption {
+	return func(opts *LocalCentralRepoOptions) {
+		opts.Port = port
+	}
+}
+
+// WithLocalCentralRepoTLS is to serve the registry over https with a generated self-signed certificate
+func WithLocalCentralRepoTLS() LocalCentralRepoOption {
+	return func(opts *LocalCentralRepoOptions) {
+		opts.TLS = true
+	}
+}
+
+// WithLocalCentralRepoAuth is to require the authentication of the given user to access the registry
+func WithLocalCentralRepoAuth(username, password string) LocalCentralRepoOption {
+	return func(opts *LocalCentralRepoOptions) {
+		opts.Username = username
+		opts.Password = password
+	}
+}
+
+// WithLocalCentralRepoPlugins is to publish stub plugins for the given plugin versions
+func WithLocalCentralRepoPlugins(plugins ...*PluginInfo) LocalCentralRepoOption {
+	return func(opts *LocalCentralRepoOptions) {
+		opts.Plugins = append(opts.Plugins, plugins...)
+	}
+}
+
+// WithLocalCentralRepoPluginGroup is to add a plugin-group version with the given plugins to the inventory
+func WithLocalCentralRepoPluginGroup(name, version string, plugins ...*PluginInfo) LocalCentralRepoOption {
+	return func(opts *LocalCentralRepoOptions) {
+		opts.Groups = append(opts.Groups, &LocalCentralRepoPluginGroup{Name: name, Version: version, Plugins: plugins})
+	}
+}
+
+// LocalCentralRepo is a throwaway OCI registry running locally in docker which is
+// pre-populated with a plugin inventory and stub plugins to be used as central repository
+type LocalCentralRepo struct {
+	// Host of the registry, e.g. localhost:9877
+	Host string
+	// URL of the plugin inventory image to use as plugin discovery source
+	URL string
+	// CACertPath is the path of the CA certificate of the registry when TLS is enabled
+	CACertPath string
+
+	options *LocalCentralRepoOptions
+	cmdExe  CmdOps
+	dir     string
+}
+
+// StartLocalCentralRepo starts a throwaway local OCI registry, with optional TLS and authentication,
+// publishes the stub plugins and the generated plugin inventory to it and returns it. The plugin
+// inventory image is not signed, so its URL must be added to the signature verification skip list.
+// The registry must be stopped with Stop once the tests are done.
+func StartLocalCentralRepo(options ...LocalCentralRepoOption) (*LocalCentralRepo, error) {
+	opts := &LocalCentralRepoOptions{
+		Name: DefaultLocalCentralRepoName,
+		Port: DefaultLocalCentralRepoPort,
+	}
+	for _, option := range options {
+		option(opts)
+	}
+
+	if err := CreateDir(FullPathForTempDir); err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp(FullPathForTempDir, opts.Name+"-")
+	if err != nil {
+		return nil, err
+	}
+	host := "localhost:" + opts.Port
+	repo := &LocalCentralRepo{
+		Host:    host,
+		URL:     host + LocalCentralRepoPluginsPath + "/" + LocalCentralRepoInventoryImage,
+		options: opts,
+		cmdExe:  NewCmdOps(),
+		dir:     dir,
+	}
+
+	if err := repo.start(); err != nil {
+		_ = repo.Stop()
+		return nil, err
+	}
+	if err := repo.populate(); err != nil {
+		_ = repo.Stop()
+		return nil, err
+	}
+	return repo, nil
+}
+
+// PluginsRepository returns the repository of the plugins, e.g. localhost:9877/tanzu-cli/plugins
+func (r *LocalCentralRepo) PluginsRepository() string {
+	return r.Host + LocalCentralRepoPluginsPath
+}
+
+// Stop stops and removes the registry and its generated files
+func (r *LocalCentralRepo) Stop() error {
+	_, _, err := r.cmdExe.Exec(fmt.Sprintf(localCentralRepoStopCmd, r.options.Name))
+	if rmErr := os.RemoveAll(r.dir); rmErr != nil && err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+// start runs the registry container and waits for the registry to be ready
+func (r *LocalCentralRepo) start() error {
+	args := []string{"docker", "run", "--rm", "-d", "-p", r.options.Port + ":5000", "--name", r.options.Name}
+	if r.options.TLS {
+		certsDir := filepath.Join(r.dir, "certs")
+		if err := generateSelfSignedCert(certsDir); err != nil {
+			return errors.Wrap(err, "unable to generate the certificate of the registry")
+		}
+		r.CACertPath = filepath.Join(certsDir, localCentralRepoCertFileName)
+		args = append(args,
+			"-v", certsDir+":/certs",
+			"-e", "REGISTRY_HTTP_TLS_CERTIFICATE=/certs/"+localCentralRepoCertFileName,
+			"-e", "REGISTRY_HTTP_TLS_KEY=/certs/"+localCentralRepoCertKeyFileName)
+	}
+	if r.options.Username != "" {
+		authDir := filepath.Join(r.dir, "auth")
+		if err := r.generateHtpasswd(authDir); err != nil {
+			return err
+		}
+		args = append(args,
+			"-v", authDir+":/auth",
+			"-e", "REGISTRY_AUTH=htpasswd",
+			"-e", "REGISTRY_AUTH_HTPASSWD_REALM=registry-realm",
+			"-e", "REGISTRY_AUTH_HTPASSWD_PATH=/auth/"+localCentralRepoHtpasswdFileName)
+	}
+	args = append(args, localCentralRepoRegistryImage)
+
+	if _, _, err := r.cmdExe.Exec(strings.Join(args, " ")); err != nil {
+		return errors.Wrap(err, "unable to start the local central repository")
+	}
+	return r.waitUntilReady()
+}
+
+// generateHtpasswd generates the htpasswd file of the registry user in the given directory
+func (r *LocalCentralRepo) generateHtpasswd(dir string) error {
+	if err := CreateDir(dir); err != nil {
+		return err
+	}
+	cmd := fmt.Sprintf("docker run --rm --entrypoint htpasswd %s -Bbn %s %s", localCentralRepoHtpasswdImage, r.options.Username, r.options.Password)
+	stdOut, _, err := r.cmdExe.Exec(cmd)
+	if err != nil {
+		return errors.Wrap(err, "unable to generate the htpasswd file of the registry")
+	}
+	return os.WriteFile(filepath.Join(dir, localCentralRepoHtpasswdFileName), stdOut.Bytes(), 0644)
+}
+
+// waitUntilReady polls the registry API until the registry answers or the timeout expires
+func (r *LocalCentralRepo) waitUntilReady() error {
+	scheme := "http"
+	client := &http.Client{Timeout: defaultTimeout}
+	if r.options.TLS {
+		scheme = "https"
+		caCert, err := os.ReadFile(r.CACertPath)
+		if err != nil {
+			return err
+		}
+		pool := x509.NewCertPool()
+		pool.AppendCertsFromPEM(caCert)
+		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}}
+	}
+
+	url := fmt.Sprintf("%s://%s/v2/", scheme, r.Host)
+	deadline := time.Now().Add(localCentralRepoStartTimeout)
+	for {
+		resp, err := client.Get(url)
+		if err == nil {
+			resp.Body.Close()
+			// The registry answers 401 to anonymous requests when the authentication is enabled
+			if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusUnauthorized {
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return errors.Errorf("the local central repository %q is not ready after %v", r.Host, localCentralRepoStartTimeout)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// populate publishes the stub plugins and the plugin inventory listing them to the registry
+func (r *LocalCentralRepo) populate() error {
+	inventoryDir := filepath.Join(r.dir, "inventory")
+	if err := CreateDir(inventoryDir); err != nil {
+		return err
+	}
+	var statements []string
+	for _, p := range r.options.Plugins {
+		pluginStatements, err := r.publishStubPlugin(p)
+		if err != nil {
+			return err
+		}
+		statements = append(statements, pluginStatements...)
+	}
+	for _, g := range r.options.Groups {
+		statements = append(statements, pluginGroupStatements(g)...)
+	}
+
+	// The sqlite3 CLI initializes the database from the schema followed by the inserted entries
+	sqlFile := filepath.Join(r.dir, localCentralRepoInventorySchema)
+	if err := os.WriteFile(sqlFile, []byte(localCentralRepoSchema+strings.Join(statements, "\n")), 0644); err != nil {
+		return err
+	}
+	dbFile := filepath.Join(inventoryDir, localCentralRepoInventoryDBFile)
+	if _, _, err := r.cmdExe.Exec(fmt.Sprintf("sqlite3 -batch -init %s %s .exit", sqlFile, dbFile)); err != nil {
+		return errors.Wrap(err, "unable to generate the plugin inventory database")
+	}
+	return r.push(r.URL, inventoryDir)
+}
+
+// publishStubPlugin publishes the stub plugin binaries of the plugin version for all
+// the supported os-arch and returns the statements to add them to the plugin inventory
+func (r *LocalCentralRepo) publishStubPlugin(p *PluginInfo) ([]string, error) {
+	description := p.Description
+	if description == "" {
+		description = p.Name + " functionality"
+	}
+	pm := NewPluginMeta().SetName(p.Name).SetTarget(p.Target).SetVersion(p.Version).SetDescription(description).
+		SetSHA("01234567").SetGroup("System").SetAliases([]string{})
+	pm.pluginLocalPath = filepath.Join(r.dir, "plugins", p.Target+"_"+p.Name)
+	binary, err := (&scriptBasedPlugins{cmdExe: r.cmdExe}).generatePluginBinary(pm)
+	if err != nil {
+		return nil, err
+	}
+	var statements []string
+	for _, osArch := range localCentralRepoOSArch {
+		statement, err := r.publishPluginBinary(p.Name, p.Target, p.Version, description, osArch[0], osArch[1], binary)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, statement)
+	}
+	return statements, nil
+}
+
+// PublishStubPlugins compiles the stub plugins for all the supported os-arch, publishes them and
+// adds them to the plugin inventory. The CLI only sees the new plugins once its plugin inventory
+// cache is refreshed, e.g. with TANZU_CLI_PLUGIN_DB_CACHE_TTL_SECONDS or 'tanzu plugin source update'
+func (r *LocalCentralRepo) PublishStubPlugins(plugins ...*StubPlugin) error {
+	stubOps := &stubPluginOps{cmdExe: r.cmdExe, dir: filepath.Join(r.dir, "stubs")}
+	var statements []string
+	for _, p := range plugins {
+		for _, osArch := range localCentralRepoOSArch {
+			binary, err := stubOps.BuildStubPlugin(p, osArch[0], osArch[1])
+			if err != nil {
+				return err
+			}
+			statement, err := r.publishPluginBinary(p.Name, p.Target, p.Version, p.description(), osArch[0], osArch[1], binary)
+			if err != nil {
+				return err
+			}
+			statements = append(statements, statement)
+		}
+	}
+	return r.updateInventory(statements)
+}
+
+// PublishPluginGroup adds the plugin-group version with the given plugins to the plugin inventory,
+// the plugins must be published in the repository
+func (r *LocalCentralRepo) PublishPluginGroup(name, version string, plugins ...*PluginInfo) error {
+	return r.updateInventory(pluginGroupStatements(&LocalCentralRepoPluginGroup{Name: name, Version: version, Plugins: plugins}))
+}
+
+// publishPluginBinary pushes the plugin binary for the os-arch and returns the statement to add it to the plugin inventory
+func (r *LocalCentralRepo) publishPluginBinary(name, target, version, description, osName, arch, binary string) (string, error) {
+	content, err := os.ReadFile(binary)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	digest := hex.EncodeToString(sum[:])
+
+	imagePath := fmt.Sprintf("%s/%s/%s/%s/%s/%s:%s", LocalCentralRepoVendor, LocalCentralRepoPublisher, osName, arch, target, name, version)
+	if err := r.push(r.PluginsRepository()+"/"+imagePath, binary); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("INSERT INTO PluginBinaries (PluginName,Target,RecommendedVersion,Version,Hidden,Description,Publisher,Vendor,OS,Architecture,Digest,URI) VALUES('%s','%s','','%s','false','%s','%s','%s','%s','%s','%s','%s');",
+		name, target, version, description, LocalCentralRepoPublisher, LocalCentralRepoVendor, osName, arch, digest, imagePath), nil
+}
+
+// updateInventory runs the statements on the plugin inventory database and publishes it again
+func (r *LocalCentralRepo) updateInventory(statements []string) error {
+	inventoryDir := filepath.Join(r.dir, "inventory")
+	sqlFile := filepath.Join(r.dir, "update-"+RandomNumber(6)+".sql")
+	if err := os.WriteFile(sqlFile, []byte(strings.Join(statements, "\n")), 0644); err != nil {
+		return err
+	}
+	dbFile := filepath.Join(inventoryDir, localCentralRepoInventoryDBFile)
+	if _, _, err := r.cmdExe.Exec(fmt.Sprintf("sqlite3 -batch -init %s %s .exit", sqlFile, dbFile)); err != nil {
+		return errors.Wrap(err, "unable to update the plugin inventory database")
+	}
+	return r.push(r.URL, inventoryDir)
+}
+
+// pluginGroupStatements returns the statements to add the plugin-group version to the plugin inventory
+func pluginGroupStatements(g *LocalCentralRepoPluginGroup) []string {
+	var statements []string
+	for _, p := range g.Plugins {
+		statements = append(statements, fmt.Sprintf("INSERT INTO PluginGroups VALUES('%s','%s','%s','%s','Desc for %s-%s/%s:%s','%s','%s','%s','true','false');",
+			LocalCentralRepoVendor, LocalCentralRepoPublisher, g.Name, g.Version,
+			LocalCentralRepoVendor, LocalCentralRepoPublisher, g.Name, g.Version,
+			p.Name, p.Target, p.Version))
+	}
+	return statements
+}
+
+// push pushes the file or directory to the registry as the given image
+func (r *LocalCentralRepo) push(image, path string) error {
+	cmd := fmt.Sprintf("imgpkg push -i %s -f %s", image, path) + r.imgpkgRegistryFlags()
+	if _, _, err := r.cmdExe.Exec(cmd); err != nil {
+		return errors.Wrapf(err, "unable to push the image %q to the local central repository", image)
+	}
+	return nil
+}
+
+// pull pulls the given image of the registry to the directory
+func (r *LocalCentralRepo) pull(image, dir string) error {
+	cmd := fmt.Sprintf("imgpkg pull -i %s -o %s", image, dir) + r.imgpkgRegistryFlags()
+	if _, _, err := r.cmdExe.Exec(cmd); err != nil {
+		return errors.Wrapf(err, "unable to pull the image %q from the local central repository", image)
+	}
+	return nil
+}
+
+// imgpkgRegistryFlags returns the imgpkg flags to access the registry
+func (r *LocalCentralRepo) imgpkgRegistryFlags() string {
+	flags := ""
+	if r.options.TLS {
+		flags += " --registry-ca-cert-path " + r.CACertPath
+	}
+	if r.options.Username != "" {
+		flags += fmt.Sprintf(" --registry-username %s --registry-password %s", r.options.Username, r.options.Password)
+	}
+	return flags
+}
+
+// generateSelfSignedCert generates a self-signed certificate for localhost and its key in the given directory
+func generateSelfSignedCert(dir string) error {
+	if err := CreateDir(dir); err != nil {
+		return err
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, localCentralRepoCertFileName), certPEM, 0644); err != nil {
+		return err
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	return os.WriteFile(filepath.Join(dir, localCentralRepoCertKeyFileName), keyPEM, 0600)
+}
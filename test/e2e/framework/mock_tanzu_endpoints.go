@@ -0,0 +1,213 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package framework
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// MockCSPIssuerPath is the path of the mock CSP issuer on the mock Tanzu endpoints server
+	MockCSPIssuerPath = "/csp/gateway/am/api"
+
+	DefaultMockOrgID    = "e2e-org-id"
+	DefaultMockOrgName  = "e2e-org"
+	DefaultMockUsername = "e2e-user@example.com"
+	DefaultMockAPIToken = "e2e-api-token" //nolint:gosec
+
+	// TanzuCliE2ETestCSPIssuer is the environment variable to override the CSP issuer of the CLI in E2E test environment
+	TanzuCliE2ETestCSPIssuer = "TANZU_CLI_E2E_TEST_CSP_ISSUER"
+
+	mockAccessTokenExpiresIn = 1800
+)
+
+// MockTanzuEndpointsOptions used to configure the mock Tanzu endpoints server
+type MockTanzuEndpointsOptions struct {
+	OrgID      string        // ID of the CSP organization; default is DefaultMockOrgID
+	OrgName    string        // Name of the CSP organization; default is DefaultMockOrgName
+	Username   string        // Username in the issued access tokens; default is DefaultMockUsername
+	APIToken   string        // The only API token accepted by the mock CSP issuer; default is DefaultMockAPIToken
+	TMCPlugins []*PluginInfo // Plugins recommended by the mock TMC endpoint
+}
+
+type MockTanzuEndpointsOption func(*MockTanzuEndpointsOptions)
+
+// WithMockOrg is to set the CSP organization the API token belongs to
+func WithMockOrg(orgID, orgName string) MockTanzuEndpointsOption {
+	return func(opts *MockTanzuEndpointsOptions) {
+		opts.OrgID = orgID
+		opts.OrgName = orgName
+	}
+}
+
+// WithMockUsername is to set the username in the issued access tokens
+func WithMockUsername(username string) MockTanzuEndpointsOption {
+	return func(opts *MockTanzuEndpointsOptions) {
+		opts.Username = username
+	}
+}
+
+// WithMockAPIToken is to set the API token accepted by the mock CSP issuer
+func WithMockAPIToken(apiToken string) MockTanzuEndpointsOption {
+	return func(opts *MockTanzuEndpointsOptions) {
+		opts.APIToken = apiToken
+	}
+}
+
+// WithMockTMCPlugins is to set the plugins recommended by the mock TMC endpoint
+func WithMockTMCPlugins(plugins ...*PluginInfo) MockTanzuEndpointsOption {
+	return func(opts *MockTanzuEndpointsOptions) {
+		opts.TMCPlugins = append(opts.TMCPlugins, plugins...)
+	}
+}
+
+// MockTanzuEndpoints is a local http server implementing the minimal CSP authentication and TMC
+// endpoints needed to create mission-control and tanzu contexts without real SaaS credentials:
+//   - the API token exchange of the CSP issuer, which issues an access token for the organization
+//   - the CSP organization details, used to name the tanzu contexts
+//   - the TMC plugins endpoint, used to discover the plugins of the mission-control contexts
+type MockTanzuEndpoints struct {
+	// URL of the server, e.g. http://127.0.0.1:41235, to use as endpoint of the contexts
+	URL string
+
+	options     *MockTanzuEndpointsOptions
+	accessToken string
+	server      *httptest.Server
+	mutex       sync.Mutex
+	requests    []string
+}
+
+// StartMockTanzuEndpoints starts the mock Tanzu endpoints server, which must be stopped with Stop
+func StartMockTanzuEndpoints(options ...MockTanzuEndpointsOption) *MockTanzuEndpoints {
+	opts := &MockTanzuEndpointsOptions{
+		OrgID:    DefaultMockOrgID,
+		OrgName:  DefaultMockOrgName,
+		Username: DefaultMockUsername,
+		APIToken: DefaultMockAPIToken,
+	}
+	for _, option := range options {
+		option(opts)
+	}
+
+	m := &MockTanzuEndpoints{options: opts, accessToken: mockAccessToken(opts)}
+	mux := http.NewServeMux()
+	mux.HandleFunc(MockCSPIssuerPath+"/auth/api-tokens/authorize", m.handleAPITokenAuthorize)
+	mux.HandleFunc(MockCSPIssuerPath+"/orgs/", m.handleOrg)
+	mux.HandleFunc(TMCEndpointForPlugins, m.handleTMCPlugins)
+	m.server = httptest.NewServer(m.recordRequests(mux))
+	m.URL = m.server.URL
+	return m
+}
+
+// Stop stops the mock Tanzu endpoints server
+func (m *MockTanzuEndpoints) Stop() {
+	m.server.Close()
+}
+
+// Issuer returns the mock CSP issuer
+func (m *MockTanzuEndpoints) Issuer() string {
+	return m.URL + MockCSPIssuerPath
+}
+
+// Endpoint returns the endpoint to use to create the contexts, i.e. the URL without scheme
+func (m *MockTanzuEndpoints) Endpoint() string {
+	return strings.TrimPrefix(m.URL, "http://")
+}
+
+// ConfigureCLI configures the environment of the CLI to authenticate with the mock CSP issuer
+// using the API token accepted by it
+func (m *MockTanzuEndpoints) ConfigureCLI() error {
+	if err := os.Setenv(CLIE2ETestEnvironment, "true"); err != nil {
+		return err
+	}
+	if err := os.Setenv(TanzuCliE2ETestCSPIssuer, m.Issuer()); err != nil {
+		return err
+	}
+	return os.Setenv(TanzuAPIToken, m.options.APIToken)
+}
+
+// Requests returns the requests received by the server, in the "<method> <path>" format
+func (m *MockTanzuEndpoints) Requests() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]string{}, m.requests...)
+}
+
+func (m *MockTanzuEndpoints) recordRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mutex.Lock()
+		m.requests = append(m.requests, r.Method+" "+r.URL.Path)
+		m.mutex.Unlock()
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (m *MockTanzuEndpoints) handleAPITokenAuthorize(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("refresh_token") != m.options.APIToken {
+		http.Error(w, `{"message":"invalid_grant: Invalid refresh token"}`, http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"id_token":      "",
+		"token_type":    "bearer",
+		"expires_in":    mockAccessTokenExpiresIn,
+		"scope":         "openid",
+		"access_token":  m.accessToken,
+		"refresh_token": m.options.APIToken,
+	})
+}
+
+func (m *MockTanzuEndpoints) handleOrg(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+m.accessToken {
+		http.Error(w, `{"message":"unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+	if strings.TrimPrefix(r.URL.Path, MockCSPIssuerPath+"/orgs/") != m.options.OrgID {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, map[string]string{"id": m.options.OrgID, "displayName": m.options.OrgName})
+}
+
+func (m *MockTanzuEndpoints) handleTMCPlugins(w http.ResponseWriter, _ *http.Request) {
+	pluginsInfo := TMCPluginsInfo{Plugins: make([]TMCPlugin, 0)}
+	for _, p := range m.options.TMCPlugins {
+		pluginsInfo.Plugins = append(pluginsInfo.Plugins, TMCPlugin{Name: p.Name, Description: p.Description, RecommendedVersion: p.Version})
+	}
+	writeJSON(w, pluginsInfo)
+}
+
+// mockAccessToken returns the access token issued for the API token. The CLI does not verify
+// the signature of the access tokens, so the token is signed with a dummy key
+func mockAccessToken(opts *MockTanzuEndpointsOptions) string {
+	header, _ := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"username":     opts.Username,
+		"context_name": opts.OrgID,
+		"perms":        []string{"csp:org_member"},
+		"exp":          time.Now().Add(mockAccessTokenExpiresIn * time.Second).Unix(),
+	})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hm := hmac.New(sha256.New, []byte("e2e"))
+	_, _ = hm.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(hm.Sum(nil))
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", HTTPContentType)
+	_ = json.NewEncoder(w).Encode(v)
+}
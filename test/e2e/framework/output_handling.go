@@ -35,6 +35,7 @@ type PluginGroupGet struct {
 	PluginName    string `json:"pluginname"`
 	PluginTarget  string `json:"plugintarget"`
 	PluginVersion string `json:"pluginversion"`
+	ContextScoped bool   `json:"context-scoped"`
 }
 
 type PluginSourceInfo struct {
@@ -43,13 +44,14 @@ type PluginSourceInfo struct {
 }
 
 type ContextListInfo struct {
-	Endpoint            string `json:"endpoint"`
-	Iscurrent           string `json:"iscurrent"`
-	Ismanagementcluster string `json:"ismanagementcluster"`
-	Kubeconfigpath      string `json:"kubeconfigpath"`
-	Kubecontext         string `json:"kubecontext"`
-	Name                string `json:"name"`
-	Type                string `json:"type"`
+	Additionalmetadata  map[string]interface{} `json:"additionalmetadata"`
+	Endpoint            string                 `json:"endpoint"`
+	Iscurrent           string                 `json:"iscurrent"`
+	Ismanagementcluster string                 `json:"ismanagementcluster"`
+	Kubeconfigpath      string                 `json:"kubeconfigpath"`
+	Kubecontext         string                 `json:"kubecontext"`
+	Name                string                 `json:"name"`
+	Type                string                 `json:"type"`
 }
 
 type ContextInfo struct {
@@ -109,6 +111,7 @@ type CertDetails struct {
 
 type PluginDescribe struct {
 	Buildsha                     string `yaml:"buildsha"`
+	Changelog                    string `yaml:"changelog"`
 	Completiontype               string `yaml:"completiontype"`
 	Defaultfeatureflags          string `yaml:"defaultfeatureflags"`
 	Description                  string `yaml:"description"`
@@ -119,6 +122,7 @@ type PluginDescribe struct {
 	Group                        string `yaml:"group"`
 	Installationpath             string `yaml:"installationpath"`
 	Name                         string `yaml:"name"`
+	Releasenotes                 string `yaml:"releasenotes"`
 	Scope                        string `yaml:"scope"`
 	Status                       string `yaml:"status"`
 	Target                       string `yaml:"target"`
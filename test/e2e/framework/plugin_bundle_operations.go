@@ -0,0 +1,283 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package framework
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	PluginBundleDirName                   = "plugin_bundle"
+	PluginMigrationManifestFile           = "plugin_migration_manifest.yaml"
+	PluginInventoryMetadataDBFileName     = "plugin_inventory_metadata.db"
+	pluginInventoryMetadataPluginsQuery   = "SELECT PluginName, Target, Version FROM AvailablePluginBinaries ORDER BY PluginName, Target, Version;"
+	pluginInventoryMetadataGroupsQuery    = "SELECT Vendor, Publisher, GroupName, GroupVersion FROM AvailablePluginGroups ORDER BY Vendor, Publisher, GroupName, GroupVersion;"
+	pluginInventoryMetadataQueryFileName  = "query.sql"
+	pluginInventoryMetadataQuerySeparator = "|"
+)
+
+// PluginMigrationManifest is the manifest of a plugin bundle, it mirrors the manifest written by 'tanzu plugin download-bundle'
+type PluginMigrationManifest struct {
+	RelativeInventoryImagePathWithTag string            `yaml:"relativeInventoryImagePathWithTag"`
+	InventoryMetadataImage            *ImagePublishInfo `yaml:"inventoryMetadataImage"`
+	ImagesToCopy                      []*ImageCopyInfo  `yaml:"imagesToCopy"`
+}
+
+// ImageCopyInfo maps the relative image path and local relative file path
+type ImageCopyInfo struct {
+	SourceTarFilePath string `yaml:"sourceTarFilePath"`
+	RelativeImagePath string `yaml:"relativeImagePath"`
+}
+
+// ImagePublishInfo maps the relative image path and local relative file path
+type ImagePublishInfo struct {
+	SourceFilePath           string `yaml:"sourceFilePath"`
+	RelativeImagePathWithTag string `yaml:"relativeImagePathWithTag"`
+}
+
+// PluginInventoryMetadata lists the plugin versions and plugin-group versions of a plugin inventory metadata database
+type PluginInventoryMetadata struct {
+	// Plugins are the plugin versions, only the name, target and version are set
+	Plugins []*PluginInfo
+	// PluginGroups are the IDs of the plugin-group versions, e.g. vmware-tkg/default:v0.0.1
+	PluginGroups []string
+}
+
+// PluginBundle is the content of a plugin bundle created with 'tanzu plugin download-bundle'
+type PluginBundle struct {
+	Manifest *PluginMigrationManifest
+	// InventoryMetadata is the plugin inventory metadata bundled with the plugins
+	InventoryMetadata *PluginInventoryMetadata
+}
+
+// PluginBundleOps helps to create, inspect and upload plugin bundles against local central repositories
+// to cover the airgapped workflow end-to-end
+type PluginBundleOps interface {
+	// ConfigureLocalCentralRepo configures the CLI to access the local central repository: it adds the CA
+	// certificate of the repository when TLS is enabled, logs in the repository with docker when the
+	// authentication is enabled and skips the signature verification of its plugin inventory image.
+	// The docker login requires the actual HOME directory to be set.
+	ConfigureLocalCentralRepo(repo *LocalCentralRepo, opts ...E2EOption) error
+
+	// CreatePluginBundle downloads the plugin bundle of the given plugin-groups and plugins, or of all the
+	// plugins if none is specified, from the local central repository to the tar file and returns its content
+	CreatePluginBundle(repo *LocalCentralRepo, groups, plugins []string, toTar string, opts ...E2EOption) (*PluginBundle, error)
+
+	// InspectPluginBundle returns the manifest and the plugin inventory metadata of the plugin bundle tar file
+	InspectPluginBundle(tarFile string) (*PluginBundle, error)
+
+	// UploadPluginBundle uploads the plugin bundle tar file to the plugins repository of the local central repository
+	UploadPluginBundle(repo *LocalCentralRepo, tarFile string, opts ...E2EOption) error
+
+	// GetInventoryMetadata returns the plugin inventory metadata published in the plugins repository of the local
+	// central repository at the relative image path, e.g. the merged metadata once a plugin bundle is uploaded
+	GetInventoryMetadata(repo *LocalCentralRepo, relativeImagePathWithTag string) (*PluginInventoryMetadata, error)
+}
+
+// pluginBundleOps is the implementation of PluginBundleOps interface
+type pluginBundleOps struct {
+	cmdExe    CmdOps
+	config    ConfigCmdOps
+	pluginCmd PluginCmdOps
+}
+
+func NewPluginBundleOps() PluginBundleOps {
+	return &pluginBundleOps{
+		cmdExe:    NewCmdOps(),
+		config:    NewConfOps(),
+		pluginCmd: NewPluginLifecycleOps(),
+	}
+}
+
+func (pb *pluginBundleOps) ConfigureLocalCentralRepo(repo *LocalCentralRepo, opts ...E2EOption) error {
+	if repo.CACertPath != "" {
+		certAddOpts := &CertAddOptions{Host: repo.Host, CACertificatePath: repo.CACertPath, SkipCertVerify: "false", Insecure: "false"}
+		if _, err := pb.config.ConfigCertAdd(certAddOpts, opts...); err != nil {
+			return errors.Wrapf(err, "unable to add the CA certificate of %q", repo.Host)
+		}
+	}
+	if repo.options.Username != "" {
+		dockerLoginCmd := fmt.Sprintf("docker login %s --username %s --password %s", repo.Host, repo.options.Username, repo.options.Password)
+		if _, _, err := pb.cmdExe.Exec(dockerLoginCmd); err != nil {
+			return errors.Wrapf(err, "unable to login to %q", repo.Host)
+		}
+	}
+
+	return SkipPluginDiscoverySignatureVerification(repo.URL)
+}
+
+// SkipPluginDiscoverySignatureVerification adds the plugin inventory image URL to the signature verification
+// skip list of the CLI, e.g. for the unsigned plugin inventory images of the local central repositories
+func SkipPluginDiscoverySignatureVerification(imageURL string) error {
+	skipList := os.Getenv(TanzuCliPluginDiscoverySignatureVerificationSkipList)
+	if skipList != "" {
+		skipList += ","
+	}
+	return os.Setenv(TanzuCliPluginDiscoverySignatureVerificationSkipList, skipList+imageURL)
+}
+
+func (pb *pluginBundleOps) CreatePluginBundle(repo *LocalCentralRepo, groups, plugins []string, toTar string, opts ...E2EOption) (*PluginBundle, error) {
+	if err := pb.pluginCmd.DownloadPluginBundle(repo.URL, groups, plugins, toTar, opts...); err != nil {
+		return nil, err
+	}
+	return pb.InspectPluginBundle(toTar)
+}
+
+func (pb *pluginBundleOps) InspectPluginBundle(tarFile string) (*PluginBundle, error) {
+	dir, err := os.MkdirTemp(FullPathForTempDir, "plugin-bundle-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := extractTar(tarFile, dir); err != nil {
+		return nil, errors.Wrapf(err, "unable to extract the plugin bundle %q", tarFile)
+	}
+	pluginBundleDir := filepath.Join(dir, PluginBundleDirName)
+	b, err := os.ReadFile(filepath.Join(pluginBundleDir, PluginMigrationManifestFile))
+	if err != nil {
+		return nil, errors.Wrap(err, "error while reading plugin migration manifest")
+	}
+	manifest := &PluginMigrationManifest{}
+	if err := yaml.Unmarshal(b, manifest); err != nil {
+		return nil, errors.Wrap(err, "error while parsing plugin migration manifest")
+	}
+	if manifest.InventoryMetadataImage == nil {
+		return nil, errors.New("the plugin migration manifest has no plugin inventory metadata image")
+	}
+
+	metadata, err := pb.readInventoryMetadata(filepath.Join(pluginBundleDir, manifest.InventoryMetadataImage.SourceFilePath))
+	if err != nil {
+		return nil, err
+	}
+	return &PluginBundle{Manifest: manifest, InventoryMetadata: metadata}, nil
+}
+
+func (pb *pluginBundleOps) UploadPluginBundle(repo *LocalCentralRepo, tarFile string, opts ...E2EOption) error {
+	return pb.pluginCmd.UploadPluginBundle(repo.PluginsRepository(), tarFile, opts...)
+}
+
+func (pb *pluginBundleOps) GetInventoryMetadata(repo *LocalCentralRepo, relativeImagePathWithTag string) (*PluginInventoryMetadata, error) {
+	dir, err := os.MkdirTemp(FullPathForTempDir, "plugin-inventory-metadata-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := repo.pull(repo.PluginsRepository()+"/"+relativeImagePathWithTag, dir); err != nil {
+		return nil, err
+	}
+	return pb.readInventoryMetadata(filepath.Join(dir, PluginInventoryMetadataDBFileName))
+}
+
+// readInventoryMetadata reads the plugin versions and plugin-group versions of the plugin inventory metadata database
+func (pb *pluginBundleOps) readInventoryMetadata(dbFile string) (*PluginInventoryMetadata, error) {
+	metadata := &PluginInventoryMetadata{}
+	rows, err := pb.query(dbFile, pluginInventoryMetadataPluginsQuery)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if len(row) != 3 {
+			return nil, errors.Errorf("unexpected plugin entry %q in the plugin inventory metadata", strings.Join(row, pluginInventoryMetadataQuerySeparator))
+		}
+		metadata.Plugins = append(metadata.Plugins, &PluginInfo{Name: row[0], Target: row[1], Version: row[2]})
+	}
+
+	rows, err = pb.query(dbFile, pluginInventoryMetadataGroupsQuery)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if len(row) != 4 {
+			return nil, errors.Errorf("unexpected plugin-group entry %q in the plugin inventory metadata", strings.Join(row, pluginInventoryMetadataQuerySeparator))
+		}
+		metadata.PluginGroups = append(metadata.PluginGroups, fmt.Sprintf("%s-%s/%s:%s", row[0], row[1], row[2], row[3]))
+	}
+	return metadata, nil
+}
+
+// query runs the query on the sqlite database with the sqlite3 CLI and returns the rows of the result
+func (pb *pluginBundleOps) query(dbFile, query string) ([][]string, error) {
+	// The query is read from a file as the command arguments cannot contain spaces
+	queryFile := filepath.Join(filepath.Dir(dbFile), pluginInventoryMetadataQueryFileName)
+	content := fmt.Sprintf(".mode list\n.separator %s\n%s\n", pluginInventoryMetadataQuerySeparator, query)
+	if err := os.WriteFile(queryFile, []byte(content), 0644); err != nil {
+		return nil, err
+	}
+	defer os.Remove(queryFile)
+
+	stdOut, _, err := pb.cmdExe.Exec(fmt.Sprintf("sqlite3 -batch -init %s %s .exit", queryFile, dbFile))
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to query the database %q", dbFile)
+	}
+	var rows [][]string
+	scanner := bufio.NewScanner(stdOut)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			rows = append(rows, strings.Split(line, pluginInventoryMetadataQuerySeparator))
+		}
+	}
+	return rows, scanner.Err()
+}
+
+// extractTar extracts the tar file, gzipped or not, to the directory
+func extractTar(tarFile, dir string) error {
+	f, err := os.Open(tarFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var reader io.Reader = bufio.NewReader(f)
+	if magic, err := reader.(*bufio.Reader).Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, filepath.Clean("/"+header.Name))
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode))
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(out, tarReader); err != nil { //nolint:gosec
+				out.Close()
+				return err
+			}
+			out.Close()
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package framework
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// StubPluginSourceTemplate is the source of the stub plugins. The stub plugins implement the
+// info and version commands used by the CLI, while their other commands have the configured
+// behavior: sleep, print an output and exit with an exit code
+const StubPluginSourceTemplate = `package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	name     = %q
+	version  = %q
+	info     = %q
+	output   = %q
+	sleep    = time.Duration(%d)
+	exitCode = %d
+)
+
+func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "info":
+			fmt.Println(info)
+			return
+		case "version":
+			fmt.Println(version)
+			return
+		case "post-install":
+			return
+		}
+	}
+	time.Sleep(sleep)
+	if output != "" {
+		fmt.Println(output)
+	}
+	if exitCode != 0 {
+		fmt.Fprintf(os.Stderr, "%%s failed with exit code %%d\n", name, exitCode)
+		os.Exit(exitCode)
+	}
+}
+`
+
+const stubPluginInfoTemplate = `{"name":%q,"target":%q,"description":%q,"version":%q,"buildSHA":"01234567","group":"System","hidden":false,"aliases":[]}`
+
+// StubPlugin is a plugin version with a configurable behavior, compiled on the fly
+type StubPlugin struct {
+	Name        string
+	Target      string
+	Version     string
+	Description string // Description of the plugin; default is "<name> functionality"
+
+	// Behavior of the commands of the plugin, other than info and version
+	Sleep    time.Duration // Sleep is the time the commands take to complete
+	Output   string        // Output is printed by the commands
+	ExitCode int           // ExitCode of the commands; a non-zero exit code makes them fail
+}
+
+// PluginInfo returns the plugin info of the stub plugin
+func (p *StubPlugin) PluginInfo() *PluginInfo {
+	return &PluginInfo{Name: p.Name, Target: p.Target, Version: p.Version, Description: p.description()}
+}
+
+func (p *StubPlugin) description() string {
+	if p.Description != "" {
+		return p.Description
+	}
+	return p.Name + " functionality"
+}
+
+// StubPluginOps helps to compile stub plugins, so that the tests can exercise arbitrary plugins
+// and plugin behaviors without maintaining prebuilt plugin binaries. It requires the go toolchain
+type StubPluginOps interface {
+	// BuildStubPlugin compiles the stub plugin for the given os and arch and returns the path of the binary
+	BuildStubPlugin(plugin *StubPlugin, goos, goarch string) (string, error)
+}
+
+type stubPluginOps struct {
+	cmdExe CmdOps
+	dir    string
+}
+
+// NewStubPluginOps returns the StubPluginOps which compiles the stub plugins under the test plugins directory
+func NewStubPluginOps() StubPluginOps {
+	return &stubPluginOps{
+		cmdExe: NewCmdOps(),
+	}
+}
+
+func (so *stubPluginOps) BuildStubPlugin(plugin *StubPlugin, goos, goarch string) (string, error) {
+	dir := so.dir
+	if dir == "" {
+		dir = filepath.Join(TestPluginsDirPath, "stubs")
+	}
+	nameWithTarget := plugin.Target + "_" + plugin.Name
+	srcDir := filepath.Join(dir, "src", nameWithTarget+"-"+plugin.Version)
+	if err := CreateDir(srcDir); err != nil {
+		return "", err
+	}
+
+	info := fmt.Sprintf(stubPluginInfoTemplate, plugin.Name, plugin.Target, plugin.description(), plugin.Version)
+	source := fmt.Sprintf(StubPluginSourceTemplate, plugin.Name, plugin.Version, info, plugin.Output, int64(plugin.Sleep), plugin.ExitCode)
+	if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte(source), 0644); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "go.mod"), []byte("module stubplugin\n\ngo 1.19\n"), 0644); err != nil {
+		return "", err
+	}
+
+	binary := filepath.Join(dir, "bin", fmt.Sprintf("%s-%s-%s-%s", nameWithTarget, goos, goarch, plugin.Version))
+	if goos == "windows" {
+		binary += ".exe"
+	}
+	// The stub plugins have no dependency, they are built outside of any go workspace
+	cmd := fmt.Sprintf("env GOOS=%s GOARCH=%s CGO_ENABLED=0 GOWORK=off GOFLAGS= go build -C %s -o %s .", goos, goarch, srcDir, binary)
+	if _, _, err := so.cmdExe.Exec(cmd); err != nil {
+		return "", errors.Wrapf(err, "unable to build the stub plugin %s:%s for %s/%s", plugin.Name, plugin.Version, goos, goarch)
+	}
+	return binary, nil
+}
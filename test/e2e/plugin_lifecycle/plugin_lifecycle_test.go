@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
@@ -78,7 +79,7 @@ var _ = framework.CLICoreDescribe("[Tests:E2E][Feature:Plugin-lifecycle]", func(
 		It("negative test case: delete plugin source which does not exists", func() {
 			wrongName := framework.RandomString(5)
 			_, err := tf.PluginCmd.DeletePluginDiscoverySource(wrongName)
-			Expect(err.Error()).To(ContainSubstring(fmt.Sprintf(framework.DiscoverySourceNotFound, wrongName)))
+			Expect(err).To(framework.FailWith(1, regexp.QuoteMeta(fmt.Sprintf(framework.DiscoverySourceNotFound, wrongName))))
 		})
 		// Test case: delete plugin source which was created in previous test case
 		It("delete previously created plugin source and validate with plugin source list", func() {
@@ -0,0 +1,71 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// registryfaults provides the e2e test cases of the registry operations facing network faults
+package registryfaults
+
+import (
+	"os"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-cli/test/e2e/framework"
+)
+
+func TestRegistryFaults(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Registry Faults E2E Test Suite")
+}
+
+var (
+	tf          *framework.Framework
+	centralRepo *framework.LocalCentralRepo
+	faultProxy  *framework.FaultProxy
+)
+
+var (
+	fooPlugin = &framework.PluginInfo{Name: "foo", Target: framework.KubernetesTarget, Version: "v0.0.1"}
+	barPlugin = &framework.PluginInfo{Name: "bar", Target: framework.GlobalTarget, Version: "v0.0.1"}
+)
+
+// BeforeSuite starts a local central repository behind the fault proxy and configures the CLI
+// to access the repository through the proxy, with short timeouts and no backoff between retries
+var _ = BeforeSuite(func() {
+	framework.UseIsolatedHome("registry-faults")
+	tf = framework.NewFramework()
+	Expect(tf.Config.ConfigInit()).To(Succeed())
+
+	var err error
+	centralRepo, err = framework.StartLocalCentralRepo(
+		framework.WithLocalCentralRepoName("e2e-registry-faults-repo"),
+		framework.WithLocalCentralRepoPort("9880"),
+		framework.WithLocalCentralRepoPlugins(fooPlugin, barPlugin),
+	)
+	Expect(err).To(BeNil(), "should not get any error while starting the local central repository")
+
+	faultProxy, err = framework.StartFaultProxy(centralRepo.Host, false)
+	Expect(err).To(BeNil(), "should not get any error while starting the fault proxy")
+
+	proxiedURL := faultProxy.Rewrite(centralRepo.URL)
+	Expect(framework.SkipPluginDiscoverySignatureVerification(proxiedURL)).To(Succeed())
+	Expect(framework.UpdatePluginDiscoverySource(tf, proxiedURL)).To(Succeed())
+
+	Expect(os.Setenv(framework.TanzuCliRegistryOperationTimeoutSeconds, "3")).To(Succeed())
+	Expect(os.Setenv(framework.TanzuCliRegistryOperationRetryCount, "3")).To(Succeed())
+	Expect(os.Setenv(framework.TanzuCliRegistryOperationRetryBackoffSeconds, "0")).To(Succeed())
+})
+
+// AfterSuite stops the fault proxy and the local central repository
+var _ = AfterSuite(func() {
+	for _, env := range []string{framework.TanzuCliRegistryOperationTimeoutSeconds, framework.TanzuCliRegistryOperationRetryCount, framework.TanzuCliRegistryOperationRetryBackoffSeconds} {
+		os.Unsetenv(env)
+	}
+	if faultProxy != nil {
+		Expect(faultProxy.Stop()).To(Succeed())
+	}
+	if centralRepo != nil {
+		Expect(centralRepo.Stop()).To(Succeed())
+	}
+})
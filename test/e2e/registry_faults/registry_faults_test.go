@@ -0,0 +1,96 @@
+// Copyright 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package registryfaults
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/tanzu-cli/test/e2e/framework"
+)
+
+// pluginBlobs selects the blob downloads, i.e. the plugin binaries and the plugin inventory
+const pluginBlobs = "/blobs/"
+
+var _ = framework.CLICoreDescribe("[Tests:E2E][Feature:Registry-Faults]", func() {
+	AfterEach(func() {
+		faultProxy.Reset()
+		Expect(tf.PluginCmd.CleanPlugins()).To(Succeed())
+	})
+
+	Context("Install plugins through the fault proxy", func() {
+		// Test case: the plugin installation succeeds with a slow registry
+		It("install plugin with latency", func() {
+			latency := &framework.Fault{Type: framework.FaultLatency, Latency: 500 * time.Millisecond}
+			Expect(faultProxy.Inject(latency)).To(Succeed())
+
+			_, _, err := tf.PluginCmd.InstallPlugin(fooPlugin.Name, fooPlugin.Target, fooPlugin.Version)
+			Expect(err).To(BeNil(), "plugin should be installed with a slow registry")
+			Expect(faultProxy.Injected(latency)).To(BeNumerically(">", 0))
+		})
+
+		// Test case: the plugin download is retried when the registry is throttling the requests
+		It("install plugin with transient 429", func() {
+			throttling := &framework.Fault{Type: framework.FaultStatus, PathRegexp: pluginBlobs, Times: 2}
+			Expect(faultProxy.Inject(throttling)).To(Succeed())
+
+			_, _, err := tf.PluginCmd.InstallPlugin(fooPlugin.Name, fooPlugin.Target, fooPlugin.Version)
+			Expect(err).To(BeNil(), "plugin download should be retried after the 429 responses")
+			Expect(faultProxy.Injected(throttling)).To(Equal(2))
+		})
+
+		// Test case: the plugin installation fails once the retries are exhausted
+		It("install plugin with persistent 429", func() {
+			throttling := &framework.Fault{Type: framework.FaultStatus, PathRegexp: pluginBlobs}
+			Expect(faultProxy.Inject(throttling)).To(Succeed())
+
+			_, _, err := tf.PluginCmd.InstallPlugin(fooPlugin.Name, fooPlugin.Target, fooPlugin.Version)
+			Expect(err).To(framework.HaveExitCode(1), "plugin installation should fail once the retries are exhausted")
+			// the first attempt and the 3 retries
+			Expect(faultProxy.Injected(throttling)).To(BeNumerically(">=", 4))
+		})
+
+		// Test case: the plugin download is retried when the connection is closed mid-stream
+		It("install plugin with a mid-stream disconnect", func() {
+			disconnect := &framework.Fault{Type: framework.FaultDisconnect, PathRegexp: pluginBlobs, DisconnectAfterBytes: 64, Times: 1}
+			Expect(faultProxy.Inject(disconnect)).To(Succeed())
+
+			_, _, err := tf.PluginCmd.InstallPlugin(fooPlugin.Name, fooPlugin.Target, fooPlugin.Version)
+			Expect(err).To(BeNil(), "plugin download should be retried after the disconnect")
+			Expect(faultProxy.Injected(disconnect)).To(Equal(1))
+		})
+
+		// Test case: the plugin download is retried when the registry does not answer within the timeout
+		It("install plugin with an unresponsive registry", func() {
+			timeout := &framework.Fault{Type: framework.FaultTimeout, PathRegexp: pluginBlobs, Latency: 10 * time.Second, Times: 1}
+			Expect(faultProxy.Inject(timeout)).To(Succeed())
+
+			_, _, err := tf.PluginCmd.InstallPlugin(fooPlugin.Name, fooPlugin.Target, fooPlugin.Version)
+			Expect(err).To(BeNil(), "plugin download should be retried after the timeout")
+			Expect(faultProxy.Injected(timeout)).To(Equal(1))
+		})
+	})
+
+	Context("Download plugin bundles through the fault proxy", func() {
+		// Test case: the plugin bundle download is retried on transient faults
+		It("download plugin bundle with transient faults", func() {
+			Expect(faultProxy.Inject(
+				&framework.Fault{Type: framework.FaultStatus, StatusCode: 503, PathRegexp: pluginBlobs, Times: 1},
+				&framework.Fault{Type: framework.FaultDisconnect, PathRegexp: pluginBlobs, DisconnectAfterBytes: 64, Times: 1},
+			)).To(Succeed())
+
+			tempDir, err := os.MkdirTemp(framework.FullPathForTempDir, "registry-faults-")
+			Expect(err).To(BeNil())
+			defer os.RemoveAll(tempDir)
+
+			bundle, err := tf.PluginBundle.CreatePluginBundle(faultProxy.Proxied(centralRepo), []string{}, []string{"foo@kubernetes:v0.0.1"}, filepath.Join(tempDir, "plugin_bundle.tar.gz"))
+			Expect(err).To(BeNil(), "plugin bundle download should be retried after the transient faults")
+			Expect(bundle.InventoryMetadata.Plugins).To(ConsistOf(fooPlugin))
+		})
+	})
+})